@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tamcore/go-autobump/internal/alias"
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/output"
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+// aliasTimeout bounds the OSV lookups of the aliases of a scan's findings
+const aliasTimeout = 2 * time.Minute
+
+// resolveAliases looks up the aliases of the vulnerabilities in results on
+// OSV, if enabled, and records every alias known for a vulnerability with
+// it, so reports and VEX statements list them. A failed lookup is reported
+// and leaves the aliases the scanners reported.
+func resolveAliases(cfg *config.Config, results map[string]trivy.ScanResult) {
+	if cfg.Aliases.OSV {
+		var ids []string
+		for _, result := range results {
+			for _, vuln := range result.Vulnerabilities {
+				ids = append(ids, vuln.VulnerabilityID)
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), aliasTimeout)
+		defer cancel()
+		if err := alias.NewClient().Resolve(ctx, ids); err != nil {
+			output.Warnf("failed to look up vulnerability aliases on OSV: %v", err)
+		}
+	}
+
+	for goModFile, result := range results {
+		for i, vuln := range result.Vulnerabilities {
+			for _, id := range alias.IDs(vuln.VulnerabilityID) {
+				if !slices.ContainsFunc(vuln.IDs(), func(known string) bool { return strings.EqualFold(known, id) }) {
+					vuln.Aliases = append(vuln.Aliases, id)
+				}
+			}
+			result.Vulnerabilities[i] = vuln
+		}
+		results[goModFile] = result
+	}
+}
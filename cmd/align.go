@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"slices"
+
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/gomod"
+	"github.com/tamcore/go-autobump/internal/output"
+	"github.com/tamcore/go-autobump/internal/plugin"
+	"github.com/tamcore/go-autobump/internal/report"
+	"github.com/tamcore/go-autobump/internal/updater"
+)
+
+// alignVersions raises every module requiring a dependency updated during
+// the run to the highest version any module now requires, so that the
+// modules of a repository do not end up with different versions of it.
+// Versions are only ever raised, modules whose updates were rolled back are
+// left alone, and aligned modules are rescanned. A checksum mismatch is
+// recorded in incidents and halts the run, ending the alignment. It returns
+// the number of aligned requirements.
+func alignVersions(cfg *config.Config, goModFiles, failedModules []string, updates []plugin.Update, policy updatePolicy,
+	run *runControl, incidents *[]report.Incident) int {
+	var updated []string
+	for _, u := range updates {
+		if u.Error == "" && u.Failure == "" && !u.Eliminated && u.Replacement == "" && !slices.Contains(updated, u.Package) {
+			updated = append(updated, u.Package)
+		}
+	}
+	if len(updated) == 0 {
+		return 0
+	}
+
+	// The version to align to is the highest one required after the updates
+	targets := updater.AlignTargets(goModFiles, updated)
+
+	aligned := 0
+	for _, goModFile := range goModFiles {
+		if run.aborted() {
+			break
+		}
+		if slices.Contains(failedModules, goModFile) {
+			continue
+		}
+
+		sess := gomod.NewSession(goModFile)
+		sess.Policy = policy.forModule(goModFile)
+		sess.Lockstep = lockstepGroups(cfg)
+		sess.Compatibility = compatibilityRules(cfg)
+
+		alignments, err := updater.Alignments(sess, targets)
+		if err != nil {
+			output.Status(output.IconWarning, "  Failed to align %s: %v", goModFile, err)
+			continue
+		}
+
+		changed := false
+		for _, a := range alignments {
+			if err := sess.GoGet(a.Module, a.To); err != nil {
+				output.Status(output.IconWarning, "  Failed to align %s in %s to %s: %v", a.Module, goModFile, a.To, err)
+				if haltOnIncident(run, incidents, goModFile, "", err) {
+					return aligned
+				}
+				continue
+			}
+			output.Status(output.IconSuccess, "  Aligned %s in %s: %s -> %s", a.Module, goModFile, a.From, a.To)
+			aligned++
+			changed = true
+		}
+		if !changed {
+			continue
+		}
+
+		if !cfg.SkipTidy {
+			if err := sess.Tidy(); err != nil {
+				output.Status(output.IconWarning, "  go mod tidy failed in %s: %v", goModFile, err)
+				if haltOnIncident(run, incidents, goModFile, "", err) {
+					return aligned
+				}
+			}
+		}
+		if !run.aborted() {
+			if err := updater.Verify(sess, cfg, cvssThresholds(cfg)); err != nil {
+				output.Status(output.IconWarning, "  Verification warning: %v", err)
+			}
+		}
+	}
+	return aligned
+}
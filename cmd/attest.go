@@ -0,0 +1,245 @@
+package cmd
+
+import (
+	"crypto"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tamcore/go-autobump/internal/attest"
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/git"
+	"github.com/tamcore/go-autobump/internal/gomod"
+	"github.com/tamcore/go-autobump/internal/gorelease"
+	"github.com/tamcore/go-autobump/internal/output"
+	"github.com/tamcore/go-autobump/internal/plugin"
+	"github.com/tamcore/go-autobump/internal/purl"
+	"github.com/tamcore/go-autobump/internal/report"
+	"github.com/tamcore/go-autobump/internal/runner"
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+// attestation records the files an update run may change before it starts,
+// to attest what the run changed once it completes
+type attestation struct {
+	root    string
+	started time.Time
+	signer  crypto.Signer
+	// before holds the digests of the files, keyed by their path relative
+	// to root; files that do not exist yet have an empty digest
+	before map[string]string
+	// commit is the HEAD commit the run started from, if in a git repository
+	commit string
+}
+
+// startAttestation records the go.mod and go.sum files of goModFiles and,
+// with builder-images, the Dockerfiles, if an attestation is configured.
+// It returns nil for dry runs, which change nothing.
+func startAttestation(cfg *config.Config, goModFiles []string, started time.Time) (*attestation, error) {
+	if cfg.Attestation.File == "" || cfg.DryRun {
+		return nil, nil
+	}
+
+	root, err := filepath.Abs(cfg.Path)
+	if err != nil {
+		return nil, err
+	}
+	a := &attestation{root: root, started: started, before: make(map[string]string)}
+	if cfg.Attestation.SigningKey != "" {
+		data, err := os.ReadFile(cfg.Attestation.SigningKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the attestation signing key: %w", err)
+		}
+		if a.signer, err = attest.ParsePrivateKey(data); err != nil {
+			return nil, fmt.Errorf("attestation.signing-key: %w", err)
+		}
+	}
+	if commit, err := git.HeadCommit(root); err == nil {
+		a.commit = commit
+	}
+
+	var files []string
+	for _, goModFile := range goModFiles {
+		files = append(files, goModFile, filepath.Join(gomod.GetModuleDir(goModFile), "go.sum"))
+	}
+	for _, file := range files {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			continue
+		}
+		a.record(abs)
+	}
+	if cfg.BuilderImages {
+		if decls, err := gorelease.FindBuilderImages(root); err == nil {
+			for _, decl := range decls {
+				a.record(filepath.Join(root, decl.File))
+			}
+		}
+	}
+	return a, nil
+}
+
+// record remembers the digest of the file at path, empty if it does not exist
+func (a *attestation) record(path string) {
+	rel, err := filepath.Rel(a.root, path)
+	if err != nil {
+		return
+	}
+	digest, _ := attest.Digest(path)
+	a.before[filepath.ToSlash(rel)] = digest
+}
+
+// write attests the recorded files the run changed to attestation.file,
+// with the applied updates and bumped builder images as inputs
+func (a *attestation) write(cfg *config.Config, updates []plugin.Update, builders []report.BuilderImage) error {
+	var subjects, inputs []attest.Resource
+	for _, name := range slices.Sorted(maps.Keys(a.before)) {
+		digest, _ := attest.Digest(filepath.Join(a.root, filepath.FromSlash(name)))
+		if digest == "" || digest == a.before[name] {
+			continue
+		}
+		subjects = append(subjects, attest.Resource{Name: name, Digest: map[string]string{"sha256": digest}})
+		if a.before[name] != "" {
+			inputs = append(inputs, attest.Resource{Name: name, Digest: map[string]string{"sha256": a.before[name]}})
+		}
+	}
+	if len(subjects) == 0 {
+		output.Status(output.IconInfo, "No changes to attest")
+		return nil
+	}
+
+	if a.commit != "" {
+		inputs = append(inputs, attest.Resource{Name: "source", Digest: map[string]string{"gitCommit": a.commit}})
+	}
+	inputs = append(inputs, a.advisories(updates, builders)...)
+	inputs = append(inputs, toolVersions()...)
+
+	statement := attest.New(subjects, attest.BuildDefinition{
+		BuildType: attest.BuildType,
+		ExternalParameters: map[string]any{
+			"path":          cfg.Path,
+			"cvssThreshold": cfg.CVSSThreshold,
+			"strategy":      cfg.Strategy,
+			"fixVersion":    cfg.FixVersion,
+			"allowMajor":    cfg.AllowMajor,
+			"allPatch":      cfg.AllPatch,
+			"builderImages": cfg.BuilderImages,
+			"skipTidy":      cfg.SkipTidy,
+		},
+		ResolvedDependencies: inputs,
+	}, attest.RunDetails{
+		Builder:  attest.Builder{ID: attest.BuilderID, Version: builderVersion()},
+		Metadata: attest.Metadata{StartedOn: a.started.UTC(), FinishedOn: time.Now().UTC()},
+	})
+
+	var doc any = statement
+	if a.signer != nil {
+		envelope, err := attest.Sign(statement, a.signer)
+		if err != nil {
+			return err
+		}
+		doc = envelope
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(cfg.Attestation.File, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+
+	kind := "Attestation"
+	if a.signer != nil {
+		kind = "Signed attestation"
+	}
+	output.Status(output.IconDocument, "%s of %d changed file(s) written to %s", kind, len(subjects), cfg.Attestation.File)
+	return nil
+}
+
+// advisories returns the vulnerabilities the applied updates and bumped
+// builder images fixed, with what was changed to fix them
+func (a *attestation) advisories(updates []plugin.Update, builders []report.BuilderImage) []attest.Resource {
+	var advisories []attest.Resource
+	for _, u := range updates {
+		if u.VulnerabilityID == "" || u.Error != "" || u.Failure != "" {
+			continue
+		}
+		annotations := map[string]any{
+			"goMod":            a.relative(u.Module),
+			"package":          u.Package,
+			"installedVersion": u.InstalledVersion,
+		}
+		switch {
+		case u.Eliminated:
+			annotations["eliminated"] = true
+		case u.Replacement != "":
+			annotations["replacement"] = u.Replacement
+		default:
+			annotations["fixedVersion"] = u.FixedVersion
+		}
+		advisories = append(advisories, advisory(u.VulnerabilityID, annotations))
+	}
+	for _, image := range builders {
+		if !image.Bumped {
+			continue
+		}
+		for _, id := range image.Vulnerabilities {
+			advisories = append(advisories, advisory(id, map[string]any{
+				"file":  image.File,
+				"image": "golang:" + image.Tag,
+				"bump":  "golang:" + image.Target,
+			}))
+		}
+	}
+	return advisories
+}
+
+// relative returns path relative to the attested root, if below it
+func (a *attestation) relative(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(a.root, abs)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
+// advisory describes the vulnerability id by its OSV page, which resolves
+// CVE, GHSA and Go vulnerability IDs alike
+func advisory(id string, annotations map[string]any) attest.Resource {
+	return attest.Resource{Name: id, URI: "https://osv.dev/vulnerability/" + id, Annotations: annotations}
+}
+
+// toolVersions describes the installed Trivy and go the run scanned and
+// updated with; tools whose version cannot be determined, and Trivy when
+// the vulnerabilities were imported, are left out
+func toolVersions() []attest.Resource {
+	var tools []attest.Resource
+	if version, err := trivy.Version(); err == nil && !trivy.HasSource() {
+		tools = append(tools, attest.Resource{Name: "trivy", URI: purl.Golang("github.com/aquasecurity/trivy", "v"+strings.TrimPrefix(version, "v"))})
+	}
+	if stdout, _, err := runner.Run("", runner.Go, "env", "GOVERSION"); err == nil {
+		if version := strings.TrimSpace(string(stdout)); version != "" {
+			tools = append(tools, attest.Resource{Name: "go", URI: purl.Golang("stdlib", gorelease.Semver(version))})
+		}
+	}
+	return tools
+}
+
+// builderVersion returns the version go-autobump was built as, if known
+func builderVersion() map[string]string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok || info.Main.Version == "" || info.Main.Version == "(devel)" {
+		return nil
+	}
+	return map[string]string{"go-autobump": info.Main.Version}
+}
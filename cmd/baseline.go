@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/tamcore/go-autobump/internal/baseline"
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/output"
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+var baselineCmd = &cobra.Command{
+	Use:   "baseline",
+	Short: "Manage the vulnerability baseline",
+	Long: `A baseline records currently known vulnerabilities. When --baseline is set,
+scan only fails on vulnerabilities that are not in the baseline, and update
+only acts on them.`,
+}
+
+var baselineUpdateCmd = &cobra.Command{
+	Use:   "update [path]",
+	Short: "Record all current vulnerabilities in the baseline file",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runBaselineUpdate,
+}
+
+func init() {
+	rootCmd.AddCommand(baselineCmd)
+	baselineCmd.AddCommand(baselineUpdateCmd)
+}
+
+func runBaselineUpdate(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Override path if provided as argument
+	if len(args) > 0 {
+		cfg.Path = args[0]
+	}
+
+	if err := prepareScanner(cfg); err != nil {
+		return err
+	}
+
+	path := cfg.Baseline
+	if path == "" {
+		path = baseline.DefaultPath
+	}
+
+	results, _, _, err := collectScanResults(cfg)
+	if err != nil {
+		return err
+	}
+
+	b := baseline.FromResults(cfg.Path, results)
+	if err := b.Write(path); err != nil {
+		return err
+	}
+
+	output.Infof("Recorded %d vulnerabilities in %s", len(b.Vulnerabilities), path)
+	return nil
+}
+
+// applyBaseline removes baselined vulnerabilities from results and drops
+// modules left without findings. A nil baseline returns results unchanged.
+func applyBaseline(b *baseline.Baseline, root string, results []trivy.ScanResult) []trivy.ScanResult {
+	if b == nil {
+		return results
+	}
+
+	var filtered []trivy.ScanResult
+	for _, result := range results {
+		result = b.Filter(root, result)
+		if len(result.Vulnerabilities) > 0 {
+			filtered = append(filtered, result)
+		}
+	}
+	return filtered
+}
+
+// loadBaseline loads the configured baseline, or returns nil if none is configured
+func loadBaseline(cfg *config.Config) (*baseline.Baseline, error) {
+	if cfg.Baseline == "" {
+		return nil, nil
+	}
+	return baseline.Load(cfg.Baseline)
+}
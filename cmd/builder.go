@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/gorelease"
+	"github.com/tamcore/go-autobump/internal/output"
+	"github.com/tamcore/go-autobump/internal/report"
+)
+
+// checkBuilderImages checks the golang images the Dockerfiles below
+// cfg.Path build with against Go security releases, and bumps the
+// vulnerable or end-of-life ones if bump is set. It returns those images.
+// Failing to look up the Go releases only warns.
+func checkBuilderImages(cfg *config.Config, bump bool) []report.BuilderImage {
+	root, err := filepath.Abs(cfg.Path)
+	if err != nil {
+		output.Warnf("failed to check the golang builder images: %v", err)
+		return nil
+	}
+	decls, err := gorelease.FindBuilderImages(root)
+	if err != nil {
+		output.Warnf("failed to find the golang builder images: %v", err)
+		return nil
+	}
+	if len(decls) == 0 {
+		return nil
+	}
+
+	output.Status(output.IconModule, "\nChecking %d golang builder image(s)", len(decls))
+	tags := make([]string, 0, len(decls))
+	for _, decl := range decls {
+		tags = append(tags, decl.Version)
+	}
+	checker, err := goReleaseClient(cfg).Load(context.Background(), tags)
+	if err != nil {
+		output.Warnf("failed to check the golang builder images: %v", err)
+		return nil
+	}
+
+	var images []report.BuilderImage
+	for _, decl := range decls {
+		check := checker.CheckBuilder(decl.Version)
+		if len(check.Vulnerabilities) == 0 && !check.EOL {
+			continue
+		}
+
+		image := report.BuilderImage{
+			File:    decl.File,
+			Line:    decl.Line,
+			Tag:     decl.Version,
+			Version: check.Version,
+			EOL:     check.EOL,
+			Target:  check.Target,
+		}
+		for _, v := range check.Vulnerabilities {
+			image.Vulnerabilities = append(image.Vulnerabilities, v.ID)
+		}
+
+		location := fmt.Sprintf("%s:%d", decl.File, decl.Line)
+		switch {
+		case image.Target == "":
+			image.Error = "no supported Go release fixes it"
+			output.Status(output.IconWarning, "  %s: golang:%s is %s, and no supported Go release fixes it",
+				location, image.Tag, builderProblem(image))
+		case !bump && cfg.DryRun:
+			output.Status(output.IconDryRun, "  %s: golang:%s is %s, would bump it to golang:%s",
+				location, image.Tag, builderProblem(image), image.Target)
+		case !bump:
+			output.Status(output.IconWarning, "  %s: golang:%s is %s, bump it to golang:%s",
+				location, image.Tag, builderProblem(image), image.Target)
+		default:
+			if err := gorelease.Rewrite(root, decl, image.Target); err != nil {
+				image.Error = err.Error()
+				output.Status(output.IconFailure, "  Failed to bump golang:%s in %s: %v", image.Tag, location, err)
+			} else {
+				image.Bumped = true
+				output.Status(output.IconSuccess, "  Bumped golang:%s in %s to golang:%s (%s)",
+					image.Tag, location, image.Target, builderProblem(image))
+			}
+		}
+		images = append(images, image)
+	}
+	if len(images) == 0 {
+		output.Status(output.IconSuccess, "  No vulnerable or end-of-life golang builder images")
+	}
+	return images
+}
+
+// builderProblem describes what is wrong with image, e.g. "end of life and
+// affected by 2 Go vulnerabilities"
+func builderProblem(image report.BuilderImage) string {
+	var problems []string
+	if image.EOL {
+		problems = append(problems, "end of life")
+	}
+	if n := len(image.Vulnerabilities); n > 0 {
+		problems = append(problems, fmt.Sprintf("affected by %d Go vulnerabilities", n))
+	}
+	return strings.Join(problems, " and ")
+}
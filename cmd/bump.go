@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"slices"
+
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/gomod"
+	"github.com/tamcore/go-autobump/internal/output"
+	"github.com/tamcore/go-autobump/internal/plugin"
+	"github.com/tamcore/go-autobump/internal/report"
+	"github.com/tamcore/go-autobump/internal/runner"
+	"github.com/tamcore/go-autobump/internal/updater"
+)
+
+// bumpPatches bumps the direct dependencies of every module to their latest
+// patch release, whether or not they are vulnerable. Modules whose
+// vulnerability updates were rolled back are left alone. Each bump is applied
+// on its own and undone if it fails, so that one broken release does not hold
+// back the others. A checksum mismatch is recorded in incidents and halts the
+// run.
+func bumpPatches(cfg *config.Config, goModFiles, failedModules []string, policy updatePolicy,
+	run *runControl, summary *report.RunSummary, incidents *[]report.Incident) ([]plugin.Update, error) {
+	var updates []plugin.Update
+	for _, goModFile := range goModFiles {
+		if run.aborted() {
+			break
+		}
+		if slices.Contains(failedModules, goModFile) {
+			continue
+		}
+
+		sess := gomod.NewSession(goModFile)
+		sess.Policy = policy.forModule(goModFile)
+		sess.Lockstep = lockstepGroups(cfg)
+		sess.Compatibility = compatibilityRules(cfg)
+
+		bumps, err := updater.PatchBumps(sess)
+		if err != nil {
+			output.Status(output.IconWarning, "  Failed to look up the patch releases of %s: %v", goModFile, err)
+			continue
+		}
+		if len(bumps) == 0 {
+			continue
+		}
+		output.Status(output.IconModule, "\nBumping %d dependencies of %s", len(bumps), goModFile)
+
+		var moduleUpdates []plugin.Update
+		for _, b := range bumps {
+			if run.aborted() {
+				break
+			}
+
+			if cfg.DryRun {
+				output.Status(output.IconDryRun, "  Would bump %s: %s -> %s", b.Module, b.From, b.To)
+				if changes, err := sess.Preview(b.Module, b.To); err != nil {
+					output.Status(output.IconWarning, "    Cannot preview the module versions it changes: %v", err)
+				} else {
+					printModuleChanges(changes)
+				}
+				summary.Planned++
+				continue
+			}
+
+			u := plugin.Update{
+				Module:           goModFile,
+				Package:          b.Module,
+				InstalledVersion: b.From,
+				FixedVersion:     b.To,
+				Proactive:        true,
+			}
+			snap, err := sess.Snapshot()
+			if err != nil {
+				output.Status(output.IconFailure, "  Failed to snapshot module, skipping: %v", err)
+				break
+			}
+			before, _ := sess.Graph()
+			end := runner.Scope(cfg.UpdateTimeout)
+			bumped, err := updater.ApplyBump(sess, b, cfg)
+			end()
+			if err != nil {
+				output.Status(output.IconFailure, "  Failed to bump %s: %v", b.Module, err)
+				if restoreErr := sess.Restore(snap); restoreErr != nil {
+					output.Status(output.IconFailure, "  Failed to roll back %s: %v", goModFile, restoreErr)
+				}
+				u.Error = err.Error()
+				moduleUpdates = append(moduleUpdates, u)
+				haltOnIncident(run, incidents, goModFile, "", err)
+				continue
+			}
+			if !bumped {
+				continue
+			}
+
+			if after, err := sess.Graph(); before != nil && err == nil {
+				u.ModuleChanges = gomod.DiffSelected(before, after)
+			}
+			moduleUpdates = append(moduleUpdates, u)
+			output.Status(output.IconSuccess, "  Bumped %s: %s -> %s", b.Module, b.From, b.To)
+			printModuleChanges(u.ModuleChanges)
+		}
+
+		if len(moduleUpdates) == 0 {
+			continue
+		}
+		if !run.aborted() {
+			if err := updater.Verify(sess, cfg, cvssThresholds(cfg)); err != nil {
+				output.Status(output.IconWarning, "  Verification warning: %v", err)
+			}
+		}
+
+		updates = append(updates, moduleUpdates...)
+		postUpdate := pluginContext(cfg, plugin.HookPostUpdate)
+		postUpdate.Module = goModFile
+		postUpdate.Updates = moduleUpdates
+		if err := plugin.Dispatch(cfg.Plugins, postUpdate); err != nil {
+			return updates, err
+		}
+	}
+	return updates, nil
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/git"
+	"github.com/tamcore/go-autobump/internal/output"
+	"github.com/tamcore/go-autobump/internal/plugin"
+	"github.com/tamcore/go-autobump/internal/report"
+)
+
+// commitPathspecs match the files an update run changes
+var commitPathspecs = []string{
+	":(glob)**/go.mod",
+	":(glob)**/go.sum",
+	":(glob)**/go.work",
+	":(glob)**/go.work.sum",
+	":(glob)**/vendor/**",
+}
+
+// commitOptions returns the git options of the commit settings, with their
+// identities parsed
+func commitOptions(cfg *config.Config) (git.CommitOptions, error) {
+	c := cfg.Commit
+	if !config.ValidSigningFormat(c.SigningFormat) {
+		return git.CommitOptions{}, fmt.Errorf("invalid commit.signing-format %q (valid: openpgp, ssh, x509)", c.SigningFormat)
+	}
+	opts := git.CommitOptions{Message: c.Message, SigningKey: c.SigningKey, SigningFormat: c.SigningFormat}
+
+	var err error
+	if c.Author != "" {
+		if opts.Author, err = git.ParseIdentity(c.Author); err != nil {
+			return git.CommitOptions{}, fmt.Errorf("commit.author: %w", err)
+		}
+	}
+	if c.Committer != "" {
+		if opts.Committer, err = git.ParseIdentity(c.Committer); err != nil {
+			return git.CommitOptions{}, fmt.Errorf("commit.committer: %w", err)
+		}
+	}
+	return opts, nil
+}
+
+// commitChanges commits the module files changed by updates under cfg.Path,
+// and the Dockerfiles of bumped builder images, with a message listing the
+// applied fixes. It returns the hash of the commit, or empty if nothing
+// changed.
+func commitChanges(cfg *config.Config, updates []plugin.Update, builders []report.BuilderImage) (string, error) {
+	opts, err := commitOptions(cfg)
+	if err != nil {
+		return "", err
+	}
+	opts.Message = commitMessage(opts.Message, updates, builders)
+
+	pathspecs := slices.Clone(commitPathspecs)
+	for _, image := range builders {
+		if image.Bumped {
+			pathspecs = append(pathspecs, ":(literal)"+filepath.ToSlash(image.File))
+		}
+	}
+
+	hash, err := git.Commit(cfg.Path, pathspecs, opts)
+	if err != nil {
+		return "", err
+	}
+	if hash == "" {
+		output.Status(output.IconInfo, "No changes to commit")
+		return "", nil
+	}
+	output.Status(output.IconSuccess, "Committed the updates as %.12s", hash)
+	return hash, nil
+}
+
+// commitMessage returns subject followed by the list of applied fixes
+func commitMessage(subject string, updates []plugin.Update, builders []report.BuilderImage) string {
+	var b strings.Builder
+	b.WriteString(subject)
+	first := true
+	for _, u := range updates {
+		if u.Error != "" || u.Failure != "" {
+			continue
+		}
+		if first {
+			b.WriteString("\n\n")
+			first = false
+		}
+		switch {
+		case u.Proactive:
+			fmt.Fprintf(&b, "- update %s %s -> %s (patch release)\n", u.Package, u.InstalledVersion, u.FixedVersion)
+		case u.Eliminated:
+			fmt.Fprintf(&b, "- %s: remove %s %s\n", u.VulnerabilityID, u.Package, u.InstalledVersion)
+		case u.Replacement != "":
+			fmt.Fprintf(&b, "- %s: replace %s %s with %s\n", u.VulnerabilityID, u.Package, u.InstalledVersion, u.Replacement)
+		default:
+			fmt.Fprintf(&b, "- %s: update %s %s -> %s\n", u.VulnerabilityID, u.Package, u.InstalledVersion, u.FixedVersion)
+		}
+	}
+	for _, image := range builders {
+		if !image.Bumped {
+			continue
+		}
+		if first {
+			b.WriteString("\n\n")
+			first = false
+		}
+		fmt.Fprintf(&b, "- update golang:%s -> golang:%s in %s\n", image.Tag, image.Target, image.File)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tamcore/go-autobump/internal/config"
+	"go.yaml.in/yaml/v3"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the configuration",
+}
+
+var configDumpCmd = &cobra.Command{
+	Use:   "dump",
+	Short: "Print the configuration as YAML",
+	Long: `Print the settings set in the config file, via flag or AUTOBUMP_* variable,
+after applying the selected profile. With --resolved, print the effective
+value of every setting, including defaults. Secrets, env entries named like
+secrets (e.g. GITHUB_TOKEN) and credentials in URLs are masked.`,
+	Args: cobra.NoArgs,
+	RunE: runConfigDump,
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configDumpCmd)
+
+	configDumpCmd.Flags().Bool("resolved", false, "print every setting, including defaults")
+}
+
+func runConfigDump(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	resolved, _ := cmd.Flags().GetBool("resolved")
+
+	encoder := yaml.NewEncoder(os.Stdout)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(config.Settings(cfg, resolved)); err != nil {
+		return fmt.Errorf("failed to print config: %w", err)
+	}
+	return encoder.Close()
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <old-scan.json> [new-scan.json]",
+	Short: "Compare two scans",
+	Long: `Diff compares two scan results produced by "scan --json" and reports
+vulnerabilities that were newly introduced, fixed, or are still present.
+
+If only one file is given, it is treated as the baseline and compared
+against a fresh scan of --path. Use --fail-on-new to gate pull requests on
+"no new vulnerabilities" rather than on an absolute zero.
+
+Vulnerabilities are matched by go.mod path, vulnerability ID and package, so
+both scans should be taken from the same checkout location.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runDiff,
+}
+
+var (
+	diffOutputJSON bool
+	diffFailOnNew  bool
+)
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+	diffCmd.Flags().BoolVar(&diffOutputJSON, "json", false, "output the diff as JSON")
+	diffCmd.Flags().BoolVar(&diffFailOnNew, "fail-on-new", false, "exit with an error if new vulnerabilities were introduced")
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	oldResults, err := trivy.LoadResults(args[0])
+	if err != nil {
+		return err
+	}
+
+	var newResults []trivy.ScanResult
+	if len(args) > 1 {
+		newResults, err = trivy.LoadResults(args[1])
+	} else {
+		if err := prepareScanner(cfg); err != nil {
+			return err
+		}
+		newResults, _, _, err = collectScanResults(cfg)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Apply the configured threshold to both sides so they are comparable
+	diff := trivy.Diff(filterResults(oldResults, cvssThresholds(cfg)), filterResults(newResults, cvssThresholds(cfg)))
+
+	if diffOutputJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(diff); err != nil {
+			return err
+		}
+	} else {
+		printDiff(diff)
+	}
+
+	if diffFailOnNew && len(diff.New) > 0 {
+		return withExitCode(ExitFindings, fmt.Errorf("%d new vulnerabilities introduced", len(diff.New)))
+	}
+	return nil
+}
+
+// filterResults applies the CVSS thresholds to every scan result
+func filterResults(results []trivy.ScanResult, thresholds trivy.Thresholds) []trivy.ScanResult {
+	filtered := make([]trivy.ScanResult, 0, len(results))
+	for _, result := range results {
+		filtered = append(filtered, trivy.FilterByThresholds(result, thresholds))
+	}
+	return filtered
+}
+
+func printDiff(diff trivy.DiffResult) {
+	printFindings("New", diff.New)
+	printFindings("Fixed", diff.Fixed)
+	printFindings("Persisting", diff.Persisting)
+
+	fmt.Println(strings.Repeat("=", 100))
+	fmt.Printf("Total: %d new, %d fixed, %d persisting\n",
+		len(diff.New), len(diff.Fixed), len(diff.Persisting))
+}
+
+func printFindings(title string, findings []trivy.Finding) {
+	fmt.Printf("\n%s (%d):\n", title, len(findings))
+	if len(findings) == 0 {
+		return
+	}
+
+	fmt.Println(strings.Repeat("-", 100))
+	fmt.Printf("%-20s %-40s %-12s %-8s %s\n", "CVE", "Package", "Installed", "CVSS", "Module")
+	fmt.Println(strings.Repeat("-", 100))
+	for _, f := range findings {
+		fmt.Printf("%-20s %-40s %-12s %-8.1f %s\n",
+			truncate(f.VulnerabilityID, 20),
+			truncate(f.PkgName, 40),
+			truncate(f.InstalledVersion, 12),
+			f.CVSSScore,
+			f.Target,
+		)
+	}
+}
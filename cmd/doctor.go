@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tamcore/go-autobump/internal/ai"
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/health"
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Validate the environment and suggest fixes for what is missing",
+	Long: `Doctor validates everything a run depends on and prints a tip for every
+failed check:
+
+  - the go command and its version
+  - a supported trivy, access to the Trivy DB and how old it is
+  - reachability of the first module proxy of GOPROXY
+  - credentials for the private modules of GOPRIVATE
+  - git
+  - the AI endpoint, if an AI API key is configured
+  - access to the repository, if a forge provider is configured
+
+Checks of something not configured are skipped. Like ready, it exits with
+code 3 if any check fails.`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+	// Failures are reported through logs and exit codes, not usage help
+	SilenceUsage: true,
+}
+
+var doctorMaxDBAge time.Duration
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+	doctorCmd.Flags().DurationVar(&doctorMaxDBAge, "max-db-age", health.DefaultMaxDBAge, "age above which the Trivy DB counts as stale")
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	opts := health.DoctorOptions{
+		Options:  health.Options{Scan: trivy.NewScanOptions(cfg)},
+		MaxDBAge: doctorMaxDBAge,
+	}
+	if err := config.ResolveSecret("ai.api-key", &cfg.AI.APIKey); err != nil {
+		return err
+	}
+	if cfg.AI.APIKey != "" {
+		opts.AI = ai.NewClient(cfg.AI.APIKey, cfg.AI.Endpoint, cfg.AI.Model)
+	}
+	if opts.Forge, err = newForge(cfg); err != nil {
+		return err
+	}
+
+	if failed := reportHealth(health.Doctor(context.Background(), opts)); failed > 0 {
+		return withExitCode(ExitNotReady, fmt.Errorf("%d check(s) failed", failed))
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import "errors"
+
+// Process exit codes, so schedulers and CI can tell findings from failures
+const (
+	// ExitOK means the run completed and nothing needs attention
+	ExitOK = 0
+	// ExitError means the run failed, e.g. due to invalid configuration
+	ExitError = 1
+	// ExitFindings means the run completed but found new vulnerabilities or
+	// could not apply all updates
+	ExitFindings = 2
+	// ExitNotReady means a readiness check failed (e.g. no Trivy DB access)
+	ExitNotReady = 3
+	// ExitIncomplete means the run timed out or was interrupted; its reports
+	// cover only the modules processed until then
+	ExitIncomplete = 4
+)
+
+// exitError attaches an exit code to an error
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+// withExitCode wraps err so the process exits with code
+func withExitCode(code int, err error) error {
+	return &exitError{code: code, err: err}
+}
+
+// ExitCode returns the process exit code for an error returned by Execute
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	var e *exitError
+	if errors.As(err, &e) {
+		return e.code
+	}
+	return ExitError
+}
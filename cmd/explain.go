@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tamcore/go-autobump/internal/ai"
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/gomod"
+	"github.com/tamcore/go-autobump/internal/output"
+	"github.com/tamcore/go-autobump/internal/scanner"
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+var explainCmd = &cobra.Command{
+	Use:   "explain <vulnerability-id> [path]",
+	Short: "Explain why a vulnerability affects the modules",
+	Long: `Explain scans for the given vulnerability and prints, for every module it
+affects, the advisory details, the dependency chain that pulls the vulnerable
+package in, whether a fix is available, and whether the fix requires a major
+version bump.
+
+With --ai, a plain-English explanation is generated using the configured AI
+provider, which is useful when filing tickets.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runExplain,
+}
+
+var (
+	explainWithAI bool
+)
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+	explainCmd.Flags().BoolVar(&explainWithAI, "ai", false, "add an AI-written plain-English explanation")
+}
+
+func runExplain(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	vulnID := args[0]
+
+	// Override path if provided as argument
+	if len(args) > 1 {
+		cfg.Path = args[1]
+	}
+
+	if err := prepareScanner(cfg); err != nil {
+		return err
+	}
+
+	goModFiles, err := scanner.DiscoverGoModFiles(cfg.Path, cfg.Exclude...)
+	if err != nil {
+		return fmt.Errorf("failed to discover go.mod files: %w", err)
+	}
+
+	if len(goModFiles) == 0 {
+		fmt.Println("No go.mod files found")
+		return nil
+	}
+
+	scanOpts := trivy.NewScanOptions(cfg)
+	scanResults := scanModules(cfg.Path, goModFiles, scanOpts)
+	annotateExploits(cfg, scanResults)
+
+	var aiClient *ai.Client
+	if explainWithAI {
+		if err := config.ResolveSecret("ai.api-key", &cfg.AI.APIKey); err != nil {
+			return err
+		}
+		if cfg.AI.APIKey == "" && !cfg.AI.DryRun {
+			return fmt.Errorf("--ai requires an AI API key (--ai-api-key or AUTOBUMP_AI_API_KEY)")
+		}
+		aiClient = ai.NewClient(cfg.AI.APIKey, cfg.AI.Endpoint, cfg.AI.Model)
+		aiClient.Stream = cfg.AI.Stream
+		aiClient.DryRun = cfg.AI.DryRun
+	}
+
+	found := false
+	for _, goModFile := range goModFiles {
+		for _, vuln := range scanResults[goModFile].Vulnerabilities {
+			if !strings.EqualFold(vuln.VulnerabilityID, vulnID) {
+				continue
+			}
+			found = true
+			explainVulnerability(goModFile, vuln, aiClient)
+		}
+	}
+
+	if !found {
+		fmt.Printf("%s was not found in any scanned module\n", vulnID)
+	}
+
+	return nil
+}
+
+// explainVulnerability prints everything we know about a vulnerability in one module
+func explainVulnerability(goModFile string, vuln trivy.Vulnerability, aiClient *ai.Client) {
+	sess := gomod.NewSession(goModFile)
+
+	fmt.Printf("\n%s in %s\n", vuln.VulnerabilityID, goModFile)
+	fmt.Println(strings.Repeat("=", 100))
+
+	// Advisory details
+	if vuln.Title != "" {
+		fmt.Printf("Title:      %s\n", vuln.Title)
+	}
+	fmt.Printf("Severity:   %s (CVSS %.1f)\n", vuln.Severity, vuln.CVSSScore)
+	if vuln.PrimaryURL != "" {
+		fmt.Printf("Advisory:   %s\n", vuln.PrimaryURL)
+	}
+	if exploit := exploitDescription(vuln.Exploit); exploit != "" {
+		fmt.Printf("Exploit:    %s\n", exploit)
+	}
+	if vuln.PublishedDate != nil {
+		fmt.Printf("Published:  %s\n", publishedDate(vuln))
+	}
+	if len(vuln.CweIDs) > 0 {
+		fmt.Printf("CWE:        %s\n", strings.Join(vuln.CweIDs, ", "))
+	}
+	for i, ref := range vuln.References {
+		if i == 0 {
+			fmt.Printf("References: %s\n", ref)
+		} else {
+			fmt.Printf("            %s\n", ref)
+		}
+	}
+
+	dependencyType := "direct"
+	if vuln.Indirect {
+		dependencyType = "indirect"
+	}
+	fmt.Printf("Package:    %s@%s (%s dependency)\n", vuln.PkgName, vuln.InstalledVersion, dependencyType)
+
+	// Fix availability
+	switch {
+	case vuln.FixedVersion == "":
+		fmt.Println("Fix:        no fixed version published")
+	case gomod.IsMajorVersionBump(vuln.InstalledVersion, vuln.FixedVersion):
+		fmt.Printf("Fix:        %s (requires a major version bump)\n", vuln.FixedVersion)
+	default:
+		fmt.Printf("Fix:        %s\n", vuln.FixedVersion)
+	}
+
+	if vuln.Description != "" {
+		fmt.Printf("\nDescription:\n  %s\n", strings.ReplaceAll(strings.TrimSpace(vuln.Description), "\n", "\n  "))
+	}
+
+	// Dependency chain
+	whyOutput, err := sess.Why(vuln.PkgName)
+	if err != nil {
+		whyOutput = "Unable to determine dependency chain"
+	}
+	fmt.Printf("\nDependency chain (go mod why):\n  %s\n",
+		strings.ReplaceAll(strings.TrimSpace(whyOutput), "\n", "\n  "))
+
+	if importers, err := gomod.ImportingPackages(gomod.GetModuleDir(goModFile)); err != nil {
+		output.Warnf("failed to list importing packages: %v", err)
+	} else if vuln.ImportedBy = importers[vuln.PkgName]; len(vuln.ImportedBy) > 0 {
+		fmt.Printf("\nImported by:\n  %s\n", strings.Join(vuln.ImportedBy, "\n  "))
+	}
+
+	if vuln.Indirect {
+		graph, err := sess.Graph()
+		if err != nil {
+			output.Warnf("failed to walk module graph: %v", err)
+		} else if paths := graph.PathsTo(vuln.PkgName); len(paths) > 0 {
+			fmt.Println("\nRequired through (go mod graph):")
+			for _, path := range paths {
+				fmt.Printf("  %s\n", strings.Join(path, " -> "))
+			}
+			if versions := graph.VersionsOf(vuln.PkgName); len(versions) > 1 {
+				fmt.Printf("  Versions required in the graph: %s\n", strings.Join(versions, ", "))
+			}
+		}
+	}
+
+	if aiClient != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+		defer cancel()
+
+		progress := output.StartProgress("Asking AI for an explanation", 0)
+		aiClient.OnProgress = func(received int) {
+			progress.SetDetail(fmt.Sprintf("(%d characters received)", received))
+		}
+		explanation, err := aiClient.ExplainVulnerability(ctx, vuln.VulnerabilityID, vuln.PkgName,
+			vuln.InstalledVersion, vuln.FixedVersion, vuln.Description, whyOutput, vuln.ImportedBy)
+		progress.Done()
+		switch {
+		case errors.Is(err, ai.ErrDryRun):
+		case err != nil:
+			output.Warnf("AI explanation failed: %v", err)
+		default:
+			fmt.Printf("\nExplanation:\n  %s\n", strings.ReplaceAll(strings.TrimSpace(explanation), "\n", "\n  "))
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/exploit"
+	"github.com/tamcore/go-autobump/internal/output"
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+// exploitTimeout bounds the lookups of the exploit maturity of a scan's
+// findings, including downloading the Exploit-DB index
+const exploitTimeout = 3 * time.Minute
+
+// annotateExploits records the exploit maturity of the vulnerabilities in
+// results, if enabled, and lists those with the most mature exploit first,
+// so they are updated and reported first. Sources that cannot be consulted
+// are reported and skipped.
+func annotateExploits(cfg *config.Config, results map[string]trivy.ScanResult) {
+	if !cfg.Exploits.Enabled || len(results) == 0 {
+		return
+	}
+
+	if slices.Contains(cfg.Exploits.Sources, exploit.SourceVulnCheckKEV) {
+		if err := config.ResolveSecret("exploits.vulncheck-token", &cfg.Exploits.VulnCheckToken); err != nil {
+			output.Warnf("failed to look up exploit maturity: %v", err)
+			return
+		}
+	}
+	token := cfg.Exploits.VulnCheckToken
+	if token == "" {
+		token = os.Getenv("VULNCHECK_API_TOKEN")
+	}
+	client := exploit.NewClient(cfg.Exploits.Sources, token, cfg.Exploits.ExploitDBURL)
+
+	targets := make([]string, 0, len(results))
+	annotated := make([]trivy.ScanResult, 0, len(results))
+	for target, result := range results {
+		targets = append(targets, target)
+		annotated = append(annotated, result)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), exploitTimeout)
+	defer cancel()
+	if err := client.Annotate(ctx, annotated); err != nil {
+		output.Warnf("failed to look up exploit maturity: %v", err)
+	}
+	for i, target := range targets {
+		results[target] = annotated[i]
+	}
+}
+
+// exploitDescription explains an exploit maturity for issue bodies and
+// explain output, or returns "" if none is known
+func exploitDescription(maturity string) string {
+	switch maturity {
+	case trivy.ExploitWeaponized:
+		return "weaponized (exploited in the wild)"
+	case trivy.ExploitPublic:
+		return "public (exploit code is published)"
+	default:
+		return ""
+	}
+}
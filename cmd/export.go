@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/export"
+	"github.com/tamcore/go-autobump/internal/gomod"
+	"github.com/tamcore/go-autobump/internal/output"
+	"github.com/tamcore/go-autobump/internal/plugin"
+	"github.com/tamcore/go-autobump/internal/purl"
+	"github.com/tamcore/go-autobump/internal/scanner"
+	"github.com/tamcore/go-autobump/internal/trivy"
+	"github.com/tamcore/go-autobump/internal/vex"
+)
+
+// exportTimeout bounds all uploads to vulnerability management systems,
+// including waiting for Dependency-Track to process the BOM
+const exportTimeout = 5 * time.Minute
+
+// exportFindings uploads results and VEX statements to the configured
+// vulnerability management systems. A failing upload does not keep the
+// others from running.
+func exportFindings(cfg *config.Config, results []trivy.ScanResult, statements []vex.Statement) error {
+	if !exportConfigured(cfg) {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+	defer cancel()
+
+	var errs []error
+	if dd := cfg.DefectDojo; dd.URL != "" {
+		if err := uploadDefectDojo(ctx, dd, results, statements); err != nil {
+			errs = append(errs, fmt.Errorf("DefectDojo: %w", err))
+		} else {
+			output.Status(output.IconSuccess, "  Uploaded findings to DefectDojo product %s", dd.Product)
+		}
+	}
+	if dt := cfg.DependencyTrack; dt.URL != "" {
+		if err := uploadDependencyTrack(ctx, cfg, results, statements); err != nil {
+			errs = append(errs, fmt.Errorf("Dependency-Track: %w", err))
+		} else {
+			output.Status(output.IconSuccess, "  Uploaded BOM to Dependency-Track project %s %s", dt.Project, dt.Version)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// exportConfigured reports whether any vulnerability management system is configured
+func exportConfigured(cfg *config.Config) bool {
+	return cfg.DefectDojo.URL != "" || cfg.DependencyTrack.URL != ""
+}
+
+func uploadDefectDojo(ctx context.Context, dd config.DefectDojoConfig, results []trivy.ScanResult, statements []vex.Statement) error {
+	if dd.Product == "" {
+		return fmt.Errorf("defectdojo.product is required")
+	}
+	if err := config.ResolveSecret("defectdojo.token", &dd.Token); err != nil {
+		return err
+	}
+	token := dd.Token
+	if token == "" {
+		token = os.Getenv("DEFECTDOJO_API_KEY")
+	}
+	return export.NewDefectDojo(dd.URL, token, dd.ProductType, dd.Product, dd.Engagement).Upload(ctx, results, statements)
+}
+
+func uploadDependencyTrack(ctx context.Context, cfg *config.Config, results []trivy.ScanResult, statements []vex.Statement) error {
+	dt := cfg.DependencyTrack
+	if dt.Project == "" {
+		return fmt.Errorf("dependency-track.project is required")
+	}
+	if err := config.ResolveSecret("dependency-track.api-key", &dt.APIKey); err != nil {
+		return err
+	}
+	apiKey := dt.APIKey
+	if apiKey == "" {
+		apiKey = os.Getenv("DEPENDENCY_TRACK_API_KEY")
+	}
+	components, err := moduleComponents(cfg)
+	if err != nil {
+		return err
+	}
+	exporter := export.NewDependencyTrack(dt.URL, apiKey, dt.Project, dt.Version)
+	exporter.PURL = purl.Builder{PreserveCase: cfg.PURLPreserveCase}
+	return exporter.Upload(ctx, components, results, statements)
+}
+
+// moduleComponents lists the requirements of all go.mod files under the
+// configured path
+func moduleComponents(cfg *config.Config) ([]export.Component, error) {
+	goModFiles, err := scanner.DiscoverGoModFiles(cfg.Path, cfg.Exclude...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover go.mod files: %w", err)
+	}
+
+	var components []export.Component
+	for _, goModFile := range goModFiles {
+		parser, err := gomod.NewParser(goModFile)
+		if err != nil {
+			return nil, err
+		}
+		deps := append(parser.GetDirectDependencies(), parser.GetIndirectDependencies()...)
+		for _, dep := range deps {
+			components = append(components, export.Component{Name: dep.Path, Version: dep.Version})
+		}
+	}
+	return components, nil
+}
+
+// remainingResults drops the vulnerabilities that updates fixed from results
+func remainingResults(results []trivy.ScanResult, updates []plugin.Update) []trivy.ScanResult {
+	fixed := make(map[string]bool)
+	for _, u := range updates {
+		if u.Error == "" {
+			fixed[u.Module+"|"+u.VulnerabilityID+"|"+u.Package] = true
+		}
+	}
+
+	var remaining []trivy.ScanResult
+	for _, result := range results {
+		r := trivy.Filter(result, func(v trivy.Vulnerability) bool {
+			return !fixed[result.Target+"|"+v.VulnerabilityID+"|"+v.PkgName]
+		})
+		remaining = append(remaining, r)
+	}
+	return remaining
+}
@@ -0,0 +1,372 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/fleet"
+	"github.com/tamcore/go-autobump/internal/forge"
+	"github.com/tamcore/go-autobump/internal/git"
+	"github.com/tamcore/go-autobump/internal/output"
+	"github.com/tamcore/go-autobump/internal/report"
+)
+
+var fleetCmd = &cobra.Command{
+	Use:   "fleet",
+	Short: "Run go-autobump across many repositories",
+}
+
+var fleetDiscoverCmd = &cobra.Command{
+	Use:   "discover",
+	Short: "List the Go repositories of a GitHub organization",
+	Long: `Discover lists the repositories of --github-org that contain Go code, one
+clone URL per line, ready to be saved as a --repos-file for "fleet run". Forks
+and archived repositories are skipped unless included, and --topic keeps only
+repositories carrying all given topics.
+
+The organization is read with the forge credentials: forge.token (GITHUB_TOKEN)
+or the GitHub App of forge.app-id, installed on the organization.`,
+	Args: cobra.NoArgs,
+	RunE: runFleetDiscover,
+}
+
+var fleetRunCmd = &cobra.Command{
+	Use:   "run [repo...] [-- job flags]",
+	Short: "Run a job for each repository of a fleet",
+	Long: `Run runs "job" for each repository: the repositories given as arguments,
+those listed in --repos-file, and the Go repositories discovered in
+--github-org, each once. Each job runs in a process of its own, with the config file and
+environment of the fleet run, and the flags after "--" passed on to it:
+
+  go-autobump fleet run --github-org myorg --topic golang -- --cvss-threshold 9
+
+Remote repositories are cloned with the git credentials of the environment.
+
+Jobs run one at a time unless --concurrency is raised; each clones one
+repository, so it also bounds the concurrent clones and the disk and network
+they use. The output of concurrent jobs is prefixed with their repository.
+--start-interval spaces the starts of jobs, and --job-timeout interrupts a job
+that takes too long, which then counts as failed. Set --forge-rate-limit to
+pace the forge API requests of the run; the jobs running at the same time
+share it.
+
+With --report, the reports of the jobs are aggregated into one report of the
+run: the status of each repository, the vulnerabilities found and fixed, and
+the pull requests opened by complete plugins.
+
+Exit codes:
+  0  all jobs completed, nothing to report
+  1  a job failed
+  2  vulnerabilities found (scan) or updates failed (update) in a repository`,
+	RunE: runFleetRun,
+	// Failures are reported through logs and exit codes, not usage help
+	SilenceUsage: true,
+}
+
+var fleetDiscoverJSON bool
+
+func init() {
+	rootCmd.AddCommand(fleetCmd)
+	fleetCmd.AddCommand(fleetDiscoverCmd, fleetRunCmd)
+
+	fleetCmd.PersistentFlags().String("github-org", "", "GitHub organization whose Go repositories to discover")
+	fleetCmd.PersistentFlags().StringSlice("topic", []string{}, "only discover repositories with this topic (repeatable; all must match)")
+	fleetCmd.PersistentFlags().Bool("include-forks", false, "also discover forked repositories")
+	fleetCmd.PersistentFlags().Bool("include-archived", false, "also discover archived repositories")
+	_ = viper.BindPFlag("fleet.github-org", fleetCmd.PersistentFlags().Lookup("github-org"))
+	_ = viper.BindPFlag("fleet.topics", fleetCmd.PersistentFlags().Lookup("topic"))
+	_ = viper.BindPFlag("fleet.include-forks", fleetCmd.PersistentFlags().Lookup("include-forks"))
+	_ = viper.BindPFlag("fleet.include-archived", fleetCmd.PersistentFlags().Lookup("include-archived"))
+
+	fleetDiscoverCmd.Flags().BoolVar(&fleetDiscoverJSON, "json", false, "list the repositories with their metadata as JSON")
+
+	fleetRunCmd.Flags().String("repos-file", "", "file listing repositories to run on, one path or git URL per line")
+	fleetRunCmd.Flags().String("mode", config.JobModeUpdate, "what each job does: scan, update")
+	_ = viper.BindPFlag("fleet.repos-file", fleetRunCmd.Flags().Lookup("repos-file"))
+	fleetRunCmd.Flags().String("report", "", "file to write the aggregated report of the run to")
+	fleetRunCmd.Flags().String("report-format", report.FormatJSON, "format of the aggregated report: json, markdown, html")
+	fleetRunCmd.Flags().Int("concurrency", 1, "number of repositories to run on at the same time")
+	fleetRunCmd.Flags().Duration("start-interval", 0, "minimum time between the starts of two jobs")
+	fleetRunCmd.Flags().Duration("job-timeout", 0, "timeout of the job of each repository (0: no limit)")
+	_ = viper.BindPFlag("fleet.mode", fleetRunCmd.Flags().Lookup("mode"))
+	_ = viper.BindPFlag("fleet.concurrency", fleetRunCmd.Flags().Lookup("concurrency"))
+	_ = viper.BindPFlag("fleet.start-interval", fleetRunCmd.Flags().Lookup("start-interval"))
+	_ = viper.BindPFlag("fleet.job-timeout", fleetRunCmd.Flags().Lookup("job-timeout"))
+	_ = viper.BindPFlag("fleet.report", fleetRunCmd.Flags().Lookup("report"))
+	_ = viper.BindPFlag("fleet.report-format", fleetRunCmd.Flags().Lookup("report-format"))
+}
+
+func runFleetDiscover(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if cfg.Fleet.GitHubOrg == "" {
+		return fmt.Errorf("no organization to discover, set --github-org")
+	}
+
+	repos, err := discoverRepos(cfg)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if fleetDiscoverJSON {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(repos)
+	}
+	for _, repo := range repos {
+		_, _ = fmt.Fprintln(out, repo.CloneURL)
+	}
+	return nil
+}
+
+// discoverRepos returns the Go repositories of fleet.github-org
+func discoverRepos(cfg *config.Config) ([]forge.Repository, error) {
+	opts, err := forgeOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	org, err := forge.NewGitHubOrg(opts, cfg.Fleet.GitHubOrg)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), forgeTimeout)
+	defer cancel()
+
+	repos, err := fleet.Discover(ctx, org, fleet.DiscoverOptions{
+		Topics:          cfg.Fleet.Topics,
+		IncludeForks:    cfg.Fleet.IncludeForks,
+		IncludeArchived: cfg.Fleet.IncludeArchived,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover the repositories of %s: %w", cfg.Fleet.GitHubOrg, err)
+	}
+	return repos, nil
+}
+
+func runFleetRun(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if cfg.Fleet.Mode != config.JobModeScan && cfg.Fleet.Mode != config.JobModeUpdate {
+		return fmt.Errorf("invalid fleet mode %q (valid: scan, update)", cfg.Fleet.Mode)
+	}
+	if cfg.Fleet.Concurrency < 1 {
+		return fmt.Errorf("invalid fleet concurrency %d (must be at least 1)", cfg.Fleet.Concurrency)
+	}
+	if cfg.Fleet.StartInterval < 0 || cfg.Fleet.JobTimeout < 0 {
+		return fmt.Errorf("fleet start-interval and job-timeout must not be negative")
+	}
+	switch cfg.Fleet.ReportFormat {
+	case report.FormatJSON, report.FormatMarkdown, report.FormatHTML:
+	default:
+		return fmt.Errorf("invalid fleet report format %q (valid: json, markdown, html)", cfg.Fleet.ReportFormat)
+	}
+
+	repos, jobArgs := args, []string(nil)
+	if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+		repos, jobArgs = args[:dash], args[dash:]
+	}
+	repos = append([]string(nil), repos...)
+
+	if cfg.Fleet.ReposFile != "" {
+		f, err := os.Open(cfg.Fleet.ReposFile)
+		if err != nil {
+			return fmt.Errorf("failed to read repos file: %w", err)
+		}
+		listed, err := fleet.ReadRepos(f)
+		_ = f.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read repos file: %w", err)
+		}
+		repos = append(repos, listed...)
+	}
+	if cfg.Fleet.GitHubOrg != "" {
+		discovered, err := discoverRepos(cfg)
+		if err != nil {
+			return err
+		}
+		output.Infof("Discovered %d Go repositories in %s", len(discovered), cfg.Fleet.GitHubOrg)
+		for _, repo := range discovered {
+			repos = append(repos, repo.CloneURL)
+		}
+	}
+	if len(repos) == 0 {
+		return fmt.Errorf("no repositories to run on, pass them as arguments or set --repos-file or --github-org")
+	}
+	// A repository listed more than once, e.g. also discovered, runs once
+	unique := fleet.Dedupe(repos)
+	if skipped := len(repos) - len(unique); skipped > 0 {
+		output.Infof("Skipping %d repeated repositories", skipped)
+	}
+	repos = unique
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find the go-autobump executable: %w", err)
+	}
+	base := []string{"job", "--mode", cfg.Fleet.Mode}
+	if cfgFile != "" {
+		base = append(base, "--config", cfgFile)
+	}
+	if cfg.Forge.RateLimit > 0 {
+		// Jobs running at the same time share the rate limit of the run
+		perJob := cfg.Forge.RateLimit / float64(cfg.Fleet.Concurrency)
+		base = append(base, "--forge-rate-limit", strconv.FormatFloat(perJob, 'g', -1, 64))
+	}
+
+	// Each job writes its report to a file of its own, for the aggregated report
+	var reportFiles map[string]string
+	if cfg.Fleet.Report != "" {
+		dir, err := os.MkdirTemp("", "go-autobump-fleet-")
+		if err != nil {
+			return fmt.Errorf("failed to create report directory: %w", err)
+		}
+		defer func() { _ = os.RemoveAll(dir) }()
+		reportFiles = make(map[string]string, len(repos))
+		for i, repo := range repos {
+			reportFiles[repo] = filepath.Join(dir, fmt.Sprintf("%d.json", i))
+		}
+	}
+
+	// The output of jobs running at the same time is told apart by the
+	// repository starting each line
+	var prefix func(repo string) string
+	if cfg.Fleet.Concurrency > 1 {
+		prefix = func(repo string) string { return "[" + git.RedactURL(repo) + "] " }
+	}
+
+	// An interrupt starts no more jobs and interrupts the running ones once,
+	// like an interrupted update run
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	opts := fleet.RunOptions{
+		Concurrency: cfg.Fleet.Concurrency,
+		Interval:    cfg.Fleet.StartInterval,
+		Timeout:     cfg.Fleet.JobTimeout,
+	}
+	results := fleet.Run(ctx, repos, opts, func(ctx context.Context, repo string) (int, error) {
+		output.Status(output.IconModule, "\n%s", git.RedactURL(repo))
+		args := append(slices.Clone(base), jobArgs...)
+		if file, ok := reportFiles[repo]; ok {
+			args = append(args, "--report-file", file)
+		}
+		return fleet.ExecJob(executable, args, prefix)(ctx, repo)
+	})
+
+	if cfg.Fleet.Report != "" {
+		if err := writeFleetReport(cfg, results, reportFiles); err != nil {
+			output.Warnf("failed to write the fleet report: %v", err)
+		} else {
+			output.Status(output.IconDocument, "Fleet report written to %s", cfg.Fleet.Report)
+		}
+	}
+	return reportFleet(results)
+}
+
+// fleetStatus classifies the outcome of a job like the exit code of the run
+func fleetStatus(r fleet.Result) string {
+	switch {
+	case r.Err != nil:
+		return report.FleetFailed
+	case r.ExitCode == ExitOK:
+		return report.FleetOK
+	case r.ExitCode == ExitFindings:
+		return report.FleetFindings
+	default:
+		return report.FleetFailed
+	}
+}
+
+// writeFleetReport aggregates the reports the jobs wrote to reportFiles into
+// fleet.report. A job that failed before writing one is listed without
+// findings.
+func writeFleetReport(cfg *config.Config, results []fleet.Result, reportFiles map[string]string) error {
+	f := report.NewFleet(cfg.Fleet.Mode)
+	for _, r := range results {
+		repo := report.FleetRepository{
+			Repo:     git.RedactURL(r.Repo),
+			Status:   fleetStatus(r),
+			ExitCode: r.ExitCode,
+			Duration: r.Duration.Round(time.Second).String(),
+		}
+		switch {
+		case r.Err != nil:
+			repo.Error = r.Err.Error()
+		case repo.Status == report.FleetFailed:
+			repo.Error = fmt.Sprintf("exit code %d", r.ExitCode)
+		}
+
+		data, err := os.ReadFile(reportFiles[r.Repo])
+		switch {
+		case err == nil:
+			var jobReport report.Report
+			if err := json.Unmarshal(data, &jobReport); err != nil {
+				return fmt.Errorf("failed to parse the report of %s: %w", repo.Repo, err)
+			}
+			repo.Report = &jobReport
+		case !errors.Is(err, fs.ErrNotExist):
+			return fmt.Errorf("failed to read the report of %s: %w", repo.Repo, err)
+		}
+		f.Add(repo)
+	}
+
+	out, err := os.Create(cfg.Fleet.Report)
+	if err != nil {
+		return err
+	}
+	if err := f.Write(out, cfg.Fleet.ReportFormat); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// reportFleet prints the outcome of every job and returns the error the
+// fleet run exits with
+func reportFleet(results []fleet.Result) error {
+	output.Status(output.IconNone, "\nFleet: %d repositories", len(results))
+	failed, findings := 0, 0
+	for _, r := range results {
+		repo := git.RedactURL(r.Repo)
+		switch status := fleetStatus(r); {
+		case r.Err != nil:
+			failed++
+			output.Status(output.IconFailure, "  %s: %v", repo, r.Err)
+		case status == report.FleetOK:
+			output.Status(output.IconSuccess, "  %s", repo)
+		case status == report.FleetFindings:
+			findings++
+			output.Status(output.IconWarning, "  %s: findings (exit code %d)", repo, r.ExitCode)
+		default:
+			failed++
+			output.Status(output.IconFailure, "  %s: failed (exit code %d)", repo, r.ExitCode)
+		}
+	}
+
+	switch {
+	case failed > 0:
+		return withExitCode(ExitError, fmt.Errorf("%d of %d repositories failed", failed, len(results)))
+	case findings > 0:
+		return withExitCode(ExitFindings, fmt.Errorf("%d of %d repositories have findings", findings, len(results)))
+	}
+	return nil
+}
@@ -0,0 +1,267 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/forge"
+	"github.com/tamcore/go-autobump/internal/git"
+	"github.com/tamcore/go-autobump/internal/output"
+	"github.com/tamcore/go-autobump/internal/report"
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+// forgeTimeout bounds all forge requests of a single step
+const forgeTimeout = 2 * time.Minute
+
+// maxStatusDescription is the longest commit status description GitHub accepts
+const maxStatusDescription = 140
+
+// newForge returns the configured forge provider, or nil if none is configured
+func newForge(cfg *config.Config) (forge.Provider, error) {
+	if cfg.Forge.Provider == "" {
+		return nil, nil
+	}
+	opts, err := forgeOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return forge.New(opts)
+}
+
+// forgeOptions returns the forge settings of cfg, resolving the secret its
+// authentication uses: the GitHub App's private key if forge.app-id is set,
+// the token otherwise
+func forgeOptions(cfg *config.Config) (forge.Options, error) {
+	key, secret := "forge.token", &cfg.Forge.Token
+	if cfg.Forge.AppID != 0 {
+		key, secret = "forge.app-private-key", &cfg.Forge.AppPrivateKey
+	}
+	if err := config.ResolveSecret(key, secret); err != nil {
+		return forge.Options{}, err
+	}
+	return forge.Options{
+		Provider: cfg.Forge.Provider,
+		Repo:     cfg.Forge.Repo,
+		Token:    cfg.Forge.Token,
+		URL:      cfg.Forge.URL,
+
+		AppID:             cfg.Forge.AppID,
+		AppPrivateKey:     cfg.Forge.AppPrivateKey,
+		AppInstallationID: cfg.Forge.AppInstallationID,
+
+		RateLimit: cfg.Forge.RateLimit,
+	}, nil
+}
+
+// commentSummary keeps the findings and applied fixes of the run as a comment
+// on forge.pull-request, replacing the comment of an earlier run on the same
+// path
+func commentSummary(cfg *config.Config, r *report.Report) error {
+	if !cfg.Forge.SummaryComment {
+		return nil
+	}
+	if cfg.Forge.PullRequest == 0 {
+		return fmt.Errorf("forge.summary-comment requires forge.pull-request")
+	}
+	provider, err := newForge(cfg)
+	if err != nil {
+		return err
+	}
+	if provider == nil {
+		return fmt.Errorf("forge.summary-comment requires forge.provider")
+	}
+
+	var body bytes.Buffer
+	if err := r.Write(&body, report.FormatMarkdown); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), forgeTimeout)
+	defer cancel()
+
+	added, err := forge.UpsertPullRequestComment(ctx, provider, cfg.Forge.PullRequest, "summary:"+cfg.Path, body.String())
+	if err != nil {
+		return err
+	}
+	if added {
+		output.Status(output.IconDocument, "Posted the summary on #%d", cfg.Forge.PullRequest)
+	} else {
+		output.Status(output.IconDocument, "Updated the summary on #%d", cfg.Forge.PullRequest)
+	}
+	return nil
+}
+
+// publishStatus sets the forge.status-context commit status of sha to the
+// vulnerabilities of results, failed while any remain. Without sha, it is
+// set on forge.commit, the commit of the CI job or HEAD.
+func publishStatus(cfg *config.Config, results []trivy.ScanResult, sha string) error {
+	summary := report.New(cfg.Path, cfg.CVSSThreshold, results).Summary
+	status := forge.CommitStatus{
+		State:       forge.StatusSuccess,
+		Description: statusDescription(summary),
+	}
+	if summary.Vulnerabilities > 0 {
+		status.State = forge.StatusFailure
+	}
+	return setCommitStatus(cfg, sha, status)
+}
+
+// publishIncompleteStatus fails the forge.status-context commit status of
+// sha, like publishStatus, for a scan whose results are incomplete for
+// reason, e.g. because it timed out, so that it cannot pass the check
+func publishIncompleteStatus(cfg *config.Config, reason, sha string) error {
+	return setCommitStatus(cfg, sha, forge.CommitStatus{
+		State:       forge.StatusFailure,
+		Description: truncate("incomplete: "+reason, maxStatusDescription),
+	})
+}
+
+// setCommitStatus sets status as the forge.status-context commit status of
+// sha, or of forge.commit, the commit of the CI job or HEAD without sha
+func setCommitStatus(cfg *config.Config, sha string, status forge.CommitStatus) error {
+	if !cfg.Forge.StatusCheck {
+		return nil
+	}
+	provider, err := newForge(cfg)
+	if err != nil {
+		return err
+	}
+	if provider == nil {
+		return fmt.Errorf("forge.status-check requires forge.provider")
+	}
+
+	if sha == "" {
+		sha = cfg.Forge.Commit
+	}
+	if sha == "" {
+		sha = forge.CICommit()
+	}
+	if sha == "" {
+		if sha, err = git.HeadCommit(cfg.Path); err != nil {
+			return fmt.Errorf("no commit to publish the status on, set forge.commit: %w", err)
+		}
+	}
+	status.Context = cfg.Forge.StatusContext
+	status.TargetURL = forge.RunURL()
+
+	ctx, cancel := context.WithTimeout(context.Background(), forgeTimeout)
+	defer cancel()
+
+	if err := provider.SetCommitStatus(ctx, sha, status); err != nil {
+		return err
+	}
+	output.Status(output.IconInfo, "Set the %s status of %.12s: %s", status.Context, sha, status.Description)
+	return nil
+}
+
+// statusDescription summarizes the vulnerabilities of summary by severity,
+// e.g. "2 critical, 1 high unfixed"
+func statusDescription(summary report.Summary) string {
+	var counts []string
+	for _, s := range summary.BySeverity {
+		if s.Count > 0 {
+			counts = append(counts, fmt.Sprintf("%d %s", s.Count, strings.ToLower(s.Severity)))
+		}
+	}
+	if len(counts) == 0 {
+		return "no vulnerabilities"
+	}
+	return strings.Join(counts, ", ") + " unfixed"
+}
+
+// approvalGroup is one major version bump, possibly fixing several vulnerabilities
+type approvalGroup struct {
+	Module     string
+	Dependency string
+	From       string
+	To         string
+	Vulns      []report.Approval
+}
+
+// requestMajorApprovals asks for a human decision on the recorded major
+// version bumps: as a comment on forge.pull-request if set, otherwise as one
+// issue per bump. Bumps that already have an open issue are not reported again.
+func requestMajorApprovals(cfg *config.Config, approvals []report.Approval) error {
+	provider, err := newForge(cfg)
+	if err != nil || provider == nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), forgeTimeout)
+	defer cancel()
+
+	groups := groupApprovals(approvals)
+
+	if cfg.Forge.PullRequest > 0 {
+		var body strings.Builder
+		body.WriteString("### go-autobump: major version bumps need a human decision\n\n")
+		for _, g := range groups {
+			body.WriteString(approvalSummary(g))
+		}
+		body.WriteString("\nApply the bumps manually or rerun go-autobump with `--allow-major` once approved.\n")
+		if err := provider.CommentPullRequest(ctx, cfg.Forge.PullRequest, body.String()); err != nil {
+			return err
+		}
+		output.Status(output.IconDocument, "  Requested approval for %d major bump(s) on #%d", len(groups), cfg.Forge.PullRequest)
+		return nil
+	}
+
+	for _, g := range groups {
+		key := fmt.Sprintf("major:%s:%s@%s", g.Module, g.Dependency, g.To)
+		existing, err := forge.FindIssue(ctx, provider, forge.Label, key)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			output.Infof("  Approval for %s %s already requested in %s", g.Dependency, g.To, existing.URL)
+			continue
+		}
+
+		title := fmt.Sprintf("Major version bump needs approval: %s %s -> %s", g.Dependency, g.From, g.To)
+		body := approvalSummary(g) +
+			"\nThis bump changes the module's major version and may require code changes. " +
+			"Apply it manually or rerun go-autobump with `--allow-major` once approved.\n\n" +
+			forge.Marker(key) + "\n"
+
+		issue, err := provider.CreateIssue(ctx, title, body, []string{forge.Label})
+		if err != nil {
+			return err
+		}
+		output.Status(output.IconDocument, "  Opened %s", issue.URL)
+	}
+	return nil
+}
+
+// groupApprovals merges approvals needing the same bump in the same module
+func groupApprovals(approvals []report.Approval) []approvalGroup {
+	var groups []approvalGroup
+	index := make(map[string]int)
+	for _, a := range approvals {
+		key := a.Module + "|" + a.Dependency + "|" + a.To
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, approvalGroup{Module: a.Module, Dependency: a.Dependency, From: a.From, To: a.To})
+		}
+		groups[i].Vulns = append(groups[i].Vulns, a)
+	}
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].Module < groups[j].Module })
+	return groups
+}
+
+// approvalSummary renders a bump and the vulnerabilities it fixes as Markdown
+func approvalSummary(g approvalGroup) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**%s** `%s` -> `%s` in `%s` fixes:\n", g.Dependency, g.From, g.To, g.Module)
+	for _, v := range g.Vulns {
+		fmt.Fprintf(&b, "- %s in `%s`\n", v.VulnerabilityID, v.Package)
+	}
+	return b.String()
+}
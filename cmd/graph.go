@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/gomod"
+	"github.com/tamcore/go-autobump/internal/output"
+	"github.com/tamcore/go-autobump/internal/scanner"
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+var graphCmd = &cobra.Command{
+	Use:   "graph [path]",
+	Short: "Render the module dependency graph",
+	Long: `Graph renders the dependency graph of a module as DOT or Mermaid.
+
+Use --focus to only show the paths that lead to a specific module, or
+--vulnerable to only show paths reaching modules with vulnerabilities above
+the CVSS threshold. The focused and vulnerable modules are highlighted.
+
+The path must point at a single module (a directory containing go.mod).`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runGraph,
+}
+
+var (
+	graphFocus      []string
+	graphFormat     string
+	graphVulnerable bool
+)
+
+func init() {
+	rootCmd.AddCommand(graphCmd)
+	graphCmd.Flags().StringSliceVar(&graphFocus, "focus", nil, "only show paths reaching these modules")
+	graphCmd.Flags().StringVar(&graphFormat, "format", gomod.FormatDOT, "output format: dot, mermaid")
+	graphCmd.Flags().BoolVar(&graphVulnerable, "vulnerable", false, "only show paths reaching vulnerable modules")
+}
+
+func runGraph(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Override path if provided as argument
+	if len(args) > 0 {
+		cfg.Path = args[0]
+	}
+
+	goModFile, err := resolveSingleModule(cfg.Path, cfg.Exclude)
+	if err != nil {
+		return err
+	}
+
+	sess := gomod.NewSession(goModFile)
+	graph, err := sess.Graph()
+	if err != nil {
+		return err
+	}
+	edges := graph.Edges()
+
+	highlight := make(map[string]bool)
+	targets := append([]string(nil), graphFocus...)
+	for _, target := range graphFocus {
+		highlight[target] = true
+	}
+
+	if graphVulnerable {
+		if err := prepareScanner(cfg); err != nil {
+			return err
+		}
+
+		scanOpts := trivy.NewScanOptions(cfg)
+		result, err := trivy.Scan(goModFile, scanOpts)
+		if err != nil {
+			return err
+		}
+
+		filtered := trivy.FilterByThresholds(result, cvssThresholds(cfg))
+		for _, vuln := range filtered.Vulnerabilities {
+			if !highlight[vuln.PkgName] {
+				highlight[vuln.PkgName] = true
+				targets = append(targets, vuln.PkgName)
+			}
+		}
+
+		if len(targets) == 0 {
+			output.Infof("No vulnerabilities found above CVSS threshold %.1f", cfg.CVSSThreshold)
+			return nil
+		}
+	}
+
+	if len(targets) > 0 {
+		edges = gomod.FilterToTargets(edges, targets)
+	}
+
+	rendered, err := gomod.RenderGraph(edges, graphFormat, highlight)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(rendered)
+	return nil
+}
+
+// resolveSingleModule returns the go.mod file for path, which must either be a
+// go.mod file, a module directory, or a tree containing exactly one module
+func resolveSingleModule(path string, exclude []string) (string, error) {
+	if filepath.Base(path) == "go.mod" {
+		return path, nil
+	}
+
+	candidate := filepath.Join(path, "go.mod")
+	if _, err := os.Stat(candidate); err == nil {
+		return candidate, nil
+	}
+
+	goModFiles, err := scanner.DiscoverGoModFiles(path, exclude...)
+	if err != nil {
+		return "", fmt.Errorf("failed to discover go.mod files: %w", err)
+	}
+
+	switch len(goModFiles) {
+	case 0:
+		return "", fmt.Errorf("no go.mod file found in %s", path)
+	case 1:
+		return goModFiles[0], nil
+	default:
+		return "", fmt.Errorf("found %d modules in %s, point the path at a single module", len(goModFiles), path)
+	}
+}
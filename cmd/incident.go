@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/forge"
+	"github.com/tamcore/go-autobump/internal/gomod"
+	"github.com/tamcore/go-autobump/internal/output"
+	"github.com/tamcore/go-autobump/internal/redact"
+	"github.com/tamcore/go-autobump/internal/report"
+	"github.com/tamcore/go-autobump/internal/runner"
+)
+
+// checksumHaltReason is the stop reason of a run aborted by a checksum
+// mismatch
+const checksumHaltReason = "aborted on a checksum mismatch"
+
+// incidentKeyPrefix marks the issues opened for checksum mismatches
+const incidentKeyPrefix = "checksum:"
+
+// incidentEnv are the go environment variables deciding where modules and
+// their checksums are fetched from
+var incidentEnv = []string{"GOPROXY", "GONOPROXY", "GOSUMDB", "GONOSUMDB", "GOPRIVATE", "GOINSECURE", "GOFLAGS", "GOVERSION"}
+
+// checksumIncident returns the incident of the update of goModFile that
+// failed with err, or nil if err is not a checksum mismatch. vulnID is the
+// vulnerability being fixed, if known. Credentials in URLs of the go
+// command's output and environment, e.g. of GOPROXY, are masked, as the
+// incident is printed and posted.
+func checksumIncident(goModFile, vulnID string, err error) *report.Incident {
+	var mismatch *gomod.ChecksumMismatchError
+	if !errors.As(err, &mismatch) {
+		return nil
+	}
+
+	incident := &report.Incident{
+		Module:          goModFile,
+		VulnerabilityID: vulnID,
+		Dependency:      mismatch.Module,
+		Version:         mismatch.Version,
+		GoMod:           mismatch.GoMod,
+		Downloaded:      mismatch.Downloaded,
+		Expected:        mismatch.Expected,
+		Source:          mismatch.Source,
+		DetectedAt:      time.Now().UTC(),
+		Error:           redact.URLs(err.Error()),
+	}
+	args := append([]string{"env", "-json"}, incidentEnv...)
+	if stdout, _, err := runner.Run(gomod.GetModuleDir(goModFile), runner.Go, args...); err == nil {
+		_ = json.Unmarshal(stdout, &incident.Env)
+	}
+	for name, value := range incident.Env {
+		incident.Env[name] = redact.URLs(value)
+	}
+	return incident
+}
+
+// haltOnIncident announces the incident of the update of goModFile that
+// failed with err, if err is a checksum mismatch, records it in incidents
+// and halts the run. It reports whether err was one.
+func haltOnIncident(run *runControl, incidents *[]report.Incident, goModFile, vulnID string, err error) bool {
+	incident := checksumIncident(goModFile, vulnID, err)
+	if incident == nil {
+		return false
+	}
+	announceIncident(incident)
+	*incidents = append(*incidents, *incident)
+	run.halt(checksumHaltReason)
+	return true
+}
+
+// announceIncident prints the incident with its forensic details, so it
+// stands out from ordinary update failures
+func announceIncident(incident *report.Incident) {
+	output.Status(output.IconFailure, "  SECURITY: %s does not match the checksum %s records, aborting the run",
+		incidentDownload(incident), incident.Source)
+	output.Status(output.IconFailure, "    downloaded: %s", incident.Downloaded)
+	output.Status(output.IconFailure, "    %s: %s", incident.Source, incident.Expected)
+	if proxy := incident.Env["GOPROXY"]; proxy != "" {
+		output.Status(output.IconFailure, "    GOPROXY: %s", proxy)
+	}
+}
+
+// incidentDownload names the download an incident refused, e.g.
+// "example.com/dep@v1.0.1/go.mod"
+func incidentDownload(incident *report.Incident) string {
+	download := incident.Dependency + "@" + incident.Version
+	if incident.GoMod {
+		download += "/go.mod"
+	}
+	return download
+}
+
+// notifyIncidents opens a forge issue for each incident that does not have
+// an open one yet, if a forge is configured
+func notifyIncidents(cfg *config.Config, incidents []report.Incident) error {
+	provider, err := newForge(cfg)
+	if err != nil || provider == nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), forgeTimeout)
+	defer cancel()
+
+	issues, err := provider.Issues(ctx, forge.Label)
+	if err != nil {
+		return err
+	}
+	open := make(map[string]bool)
+	for _, issue := range issues {
+		if key, ok := forge.ParseMarker(issue.Body); ok && strings.HasPrefix(key, incidentKeyPrefix) {
+			open[key] = true
+		}
+	}
+
+	for _, incident := range incidents {
+		key := incidentKeyPrefix + incidentDownload(&incident) + ":" + incident.Downloaded
+		if open[key] {
+			continue
+		}
+		open[key] = true
+
+		title := fmt.Sprintf("Security: checksum mismatch for %s", incidentDownload(&incident))
+		body := incidentIssueBody(cfg, incident) + "\n" + forge.Marker(key) + "\n"
+		issue, err := provider.CreateIssue(ctx, title, body, []string{forge.Label})
+		if err != nil {
+			return err
+		}
+		output.Status(output.IconDocument, "Opened incident issue %s", issue.URL)
+	}
+	return nil
+}
+
+// incidentIssueBody renders the issue of an incident
+func incidentIssueBody(cfg *config.Config, incident report.Incident) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "The go command refused `%s` while go-autobump updated `%s`: the download does not match the checksum %s records.\n\n",
+		incidentDownload(&incident), relativeModulePath(cfg.Path, incident.Module), incident.Source)
+	fmt.Fprintf(&b, "- Downloaded: `%s`\n", incident.Downloaded)
+	fmt.Fprintf(&b, "- Expected (%s): `%s`\n", incident.Source, incident.Expected)
+	if incident.VulnerabilityID != "" {
+		fmt.Fprintf(&b, "- Fixing: %s\n", incident.VulnerabilityID)
+	}
+	fmt.Fprintf(&b, "- Detected: %s\n", incident.DetectedAt.Format(time.RFC3339))
+
+	if len(incident.Env) > 0 {
+		b.WriteString("\n### Go environment\n\n| Variable | Value |\n|----------|-------|\n")
+		for _, name := range slices.Sorted(maps.Keys(incident.Env)) {
+			fmt.Fprintf(&b, "| %s | `%s` |\n", name, incident.Env[name])
+		}
+	}
+
+	fmt.Fprintf(&b, "\n<details><summary>go command output</summary>\n\n```\n%s\n```\n\n</details>\n", strings.TrimSpace(incident.Error))
+	b.WriteString("\nThe run was aborted without committing its changes. Check the module proxy and whether the module's tag was moved " +
+		"before retrying, and do not bypass the checksum verification with GOSUMDB=off, GONOSUMDB or GOINSECURE.\n")
+	return b.String()
+}
+
+// incidentError is the error of a run aborted by incidents
+func incidentError(incidents []report.Incident) error {
+	var downloads []string
+	for _, incident := range incidents {
+		downloads = append(downloads, incidentDownload(&incident))
+	}
+	return fmt.Errorf("run %s of %s; see the report for the details", checksumHaltReason, strings.Join(downloads, ", "))
+}
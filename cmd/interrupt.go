@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/output"
+	"github.com/tamcore/go-autobump/internal/runner"
+)
+
+// runControl tracks whether a run should stop early, because its timeout
+// expired, it was interrupted or it was halted
+type runControl struct {
+	ctx         context.Context
+	cancel      context.CancelFunc
+	interrupted atomic.Bool
+	// halted is why the run was halted, e.g. after a security incident
+	halted  atomic.Pointer[string]
+	signals chan os.Signal
+	// onInterrupt, if set, is called on the first interrupt
+	onInterrupt atomic.Pointer[context.CancelFunc]
+}
+
+// startRun bounds the commands executed during the run by cfg.Timeout. Call
+// done when the run ends.
+func startRun(cfg *config.Config) *runControl {
+	r := &runControl{}
+	if cfg.Timeout > 0 {
+		r.ctx, r.cancel = context.WithTimeout(context.Background(), cfg.Timeout)
+	} else {
+		r.ctx, r.cancel = context.WithCancel(context.Background())
+	}
+	runner.SetContext(r.ctx)
+	return r
+}
+
+// handleInterrupts makes the first SIGINT or SIGTERM ask the run to stop
+// after the current module, and a second one kill the running commands
+func (r *runControl) handleInterrupts() {
+	r.signals = make(chan os.Signal, 2)
+	signal.Notify(r.signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		for range r.signals {
+			if r.interrupted.Swap(true) {
+				output.Warnf("interrupted again, aborting running commands")
+				r.cancel()
+				return
+			}
+			if cancel := r.onInterrupt.Load(); cancel != nil {
+				output.Warnf("interrupted, stopping; interrupt again to abort")
+				(*cancel)()
+				continue
+			}
+			output.Warnf("interrupted, finishing the current module; interrupt again to abort")
+		}
+	}()
+}
+
+// interruptible makes the commands and AI requests run until end is called
+// stop on the first interrupt, e.g. for work after all modules are processed
+// that has no module to finish
+func (r *runControl) interruptible() (end func()) {
+	ctx, cancel := context.WithCancel(r.ctx)
+	r.onInterrupt.Store(&cancel)
+	runner.SetContext(ctx)
+	return func() {
+		r.onInterrupt.Store(nil)
+		runner.SetContext(r.ctx)
+		cancel()
+	}
+}
+
+// halt stops the run after the current update for reason, e.g. "aborted on
+// a checksum mismatch". Unlike an interrupt, it does not stop the commands
+// that report on the run.
+func (r *runControl) halt(reason string) {
+	r.halted.CompareAndSwap(nil, &reason)
+}
+
+// stopReason returns why the run should not start another module, or empty
+// if it may continue
+func (r *runControl) stopReason() string {
+	if reason := r.halted.Load(); reason != nil {
+		return *reason
+	}
+	switch {
+	case errors.Is(r.ctx.Err(), context.DeadlineExceeded):
+		return "timed out"
+	case r.interrupted.Load() || r.ctx.Err() != nil:
+		return "interrupted"
+	}
+	return ""
+}
+
+// aborted reports whether running commands are being killed or the run was
+// halted, so no further work of the current module should be started
+func (r *runControl) aborted() bool {
+	return r.ctx.Err() != nil || r.halted.Load() != nil
+}
+
+func (r *runControl) done() {
+	if r.signals != nil {
+		signal.Stop(r.signals)
+		close(r.signals)
+	}
+	r.cancel()
+	runner.SetContext(context.Background())
+}
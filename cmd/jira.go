@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/gomod"
+	"github.com/tamcore/go-autobump/internal/jira"
+	"github.com/tamcore/go-autobump/internal/output"
+	"github.com/tamcore/go-autobump/internal/report"
+	"github.com/tamcore/go-autobump/internal/trivy"
+	"github.com/tamcore/go-autobump/internal/vex"
+)
+
+// jiraTicket is a vulnerability needing a remediation ticket
+type jiraTicket struct {
+	Key string
+	// GoModFile is the go.mod the vulnerability was found in, Module its
+	// path relative to the scanned root
+	GoModFile string
+	Module    string
+	Vuln      trivy.Vulnerability
+	// Approval is the major bump the fix is deferred on, nil if unfixed
+	Approval *report.Approval
+}
+
+// notifyJira creates a Jira ticket per unfixed vulnerability and per
+// vulnerability deferred on a major bump approval. Tickets that are still
+// unresolved are updated instead, so their CVSS score, VEX status and
+// dependency chain stay current.
+func notifyJira(cfg *config.Config, results []trivy.ScanResult, approvals []report.Approval, statements []vex.Statement) error {
+	if cfg.Jira.Project == "" {
+		return fmt.Errorf("jira.url is set but jira.project is empty")
+	}
+	if err := config.ResolveSecret("jira.token", &cfg.Jira.Token); err != nil {
+		return err
+	}
+	token := cfg.Jira.Token
+	if token == "" {
+		token = os.Getenv("JIRA_API_TOKEN")
+	}
+	client := jira.NewClient(cfg.Jira.URL, cfg.Jira.User, token)
+
+	tickets := jiraTickets(cfg.Path, results, approvals)
+	if len(tickets) == 0 {
+		return nil
+	}
+
+	if statements == nil {
+		var unfixed []trivy.Vulnerability
+		for _, t := range tickets {
+			if t.Approval == nil {
+				unfixed = append(unfixed, t.Vuln)
+			}
+		}
+		statements = vex.Statements(unfixed, cfg)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), forgeTimeout)
+	defer cancel()
+
+	issues, err := client.Issues(ctx, cfg.Jira.Project, jira.Label)
+	if err != nil {
+		return err
+	}
+
+	labels := append([]string{jira.Label}, cfg.Jira.Labels...)
+	created, updated := 0, 0
+	for _, t := range tickets {
+		var stmt *vex.Statement
+		if t.Approval == nil {
+			stmt = vex.FindStatement(statements, t.Vuln)
+		}
+		description := jiraDescription(t, stmt) + "\n\n" + jira.Marker(t.Key)
+
+		if existing := jira.FindIssue(issues, t.Key); existing != nil {
+			if existing.Description == description {
+				continue
+			}
+			if err := client.UpdateDescription(ctx, existing.Key, description); err != nil {
+				return err
+			}
+			updated++
+			continue
+		}
+
+		summary := fmt.Sprintf("Unfixed vulnerability %s in %s", t.Vuln.VulnerabilityID, t.Vuln.PkgName)
+		if t.Approval != nil {
+			summary = fmt.Sprintf("Deferred vulnerability %s in %s (needs %s %s)",
+				t.Vuln.VulnerabilityID, t.Vuln.PkgName, t.Approval.Dependency, t.Approval.To)
+		}
+		issue, err := client.CreateIssue(ctx, cfg.Jira.Project, cfg.Jira.IssueType, summary, description, labels)
+		if err != nil {
+			return err
+		}
+		created++
+		output.Status(output.IconDocument, "  Created Jira ticket %s for %s", issue.URL, t.Vuln.VulnerabilityID)
+	}
+
+	output.Infof("Jira tickets: %d created, %d updated", created, updated)
+	return nil
+}
+
+// jiraTickets returns the unfixed vulnerabilities of results and the
+// vulnerabilities deferred on approvals
+func jiraTickets(root string, results []trivy.ScanResult, approvals []report.Approval) []jiraTicket {
+	var tickets []jiraTicket
+	seen := make(map[string]bool)
+	add := func(t jiraTicket) {
+		if !seen[t.Key] {
+			seen[t.Key] = true
+			tickets = append(tickets, t)
+		}
+	}
+
+	for _, result := range results {
+		module := relativeModulePath(root, result.Target)
+		for _, vuln := range result.Vulnerabilities {
+			if !trivy.HasFixedVersion(vuln) {
+				add(jiraTicket{Key: unfixedKey(module, vuln), GoModFile: result.Target, Module: module, Vuln: vuln})
+			}
+		}
+	}
+
+	for i, a := range approvals {
+		for _, result := range results {
+			if result.Target != a.Module {
+				continue
+			}
+			for _, vuln := range result.Vulnerabilities {
+				if vuln.Is(a.VulnerabilityID) && vuln.PkgName == a.Package {
+					module := relativeModulePath(root, a.Module)
+					key := fmt.Sprintf("major:%s:%s@%s:%s", module, a.Dependency, a.To, vuln.VulnerabilityID)
+					add(jiraTicket{Key: key, GoModFile: a.Module, Module: module, Vuln: vuln, Approval: &approvals[i]})
+				}
+			}
+		}
+	}
+	return tickets
+}
+
+// jiraDescription renders a ticket description in Jira wiki markup
+func jiraDescription(t jiraTicket, stmt *vex.Statement) string {
+	var b strings.Builder
+	vuln := t.Vuln
+	fmt.Fprintf(&b, "*%s* in {{%s@%s}} (module {{%s}})\n\n", vuln.VulnerabilityID, vuln.PkgName, vuln.InstalledVersion, t.Module)
+	fmt.Fprintf(&b, "* Severity: %s (CVSS %.1f)\n", vuln.Severity, vuln.CVSSScore)
+	if vuln.Title != "" {
+		fmt.Fprintf(&b, "* Title: %s\n", vuln.Title)
+	}
+	if vuln.PrimaryURL != "" {
+		fmt.Fprintf(&b, "* Advisory: %s\n", vuln.PrimaryURL)
+	}
+	if exploit := exploitDescription(vuln.Exploit); exploit != "" {
+		fmt.Fprintf(&b, "* Exploit: %s\n", exploit)
+	}
+	if vuln.PublishedDate != nil {
+		fmt.Fprintf(&b, "* Published: %s\n", publishedDate(vuln))
+	}
+	if len(vuln.CweIDs) > 0 {
+		fmt.Fprintf(&b, "* CWE: %s\n", strings.Join(vuln.CweIDs, ", "))
+	}
+	if len(vuln.References) > 0 {
+		b.WriteString("* References:\n")
+		for _, ref := range vuln.References {
+			fmt.Fprintf(&b, "** %s\n", ref)
+		}
+	}
+
+	if t.Approval != nil {
+		fmt.Fprintf(&b, "* Fixed in: %s\n", vuln.FixedVersion)
+		fmt.Fprintf(&b, "\nThe fix is deferred: it needs a major version bump of {{%s}} from %s to %s, which awaits a human decision.\n",
+			t.Approval.Dependency, t.Approval.From, t.Approval.To)
+	} else {
+		b.WriteString("\nNo fixed version is available yet.\n")
+	}
+
+	if stmt != nil {
+		b.WriteString("\nh3. VEX statement\n\n")
+		fmt.Fprintf(&b, "* Status: {{%s}}\n", stmt.Status)
+		if stmt.Justification != "" {
+			fmt.Fprintf(&b, "* Justification: {{%s}}\n", stmt.Justification)
+		}
+		if stmt.ImpactStatement != "" {
+			fmt.Fprintf(&b, "* Impact: %s\n", stmt.ImpactStatement)
+		}
+	}
+
+	chain, err := gomod.ModWhy(filepath.Dir(t.GoModFile), vuln.PkgName)
+	if err != nil {
+		chain = "Unable to determine dependency chain"
+	}
+	fmt.Fprintf(&b, "\nh3. Dependency chain\n\n{noformat}\n%s\n{noformat}", strings.TrimSpace(chain))
+	return b.String()
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/git"
+	"github.com/tamcore/go-autobump/internal/output"
+)
+
+var jobCmd = &cobra.Command{
+	Use:   "job [repo]",
+	Short: "Run unattended, e.g. as a Kubernetes CronJob",
+	Long: `Job runs a scan or update without any interactive assumptions, for use as
+a Kubernetes CronJob or in other schedulers.
+
+The repository is a mounted path or a git URL, which is shallow-cloned into a
+temporary directory for the duration of the run. With --sparse, the clone
+checks out only go.mod, go.sum and go.work files and the directories of Go
+packages, plus the paths given with --sparse-path, and fetches no other file
+contents. Every setting can be given through AUTOBUMP_* environment variables
+(e.g. AUTOBUMP_JOB_REPO, AUTOBUMP_JOB_MODE, AUTOBUMP_LOG_FORMAT=json). Output
+never uses emoji, colors or a spinner.
+
+Before starting, a readiness check validates go, trivy and Trivy DB access.
+
+Exit codes:
+  0  completed, nothing to report
+  1  the run failed
+  2  vulnerabilities found (scan) or updates failed (update)
+  3  a readiness check failed`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runJob,
+	// Failures are reported through logs and exit codes, not usage help
+	SilenceUsage: true,
+}
+
+func init() {
+	rootCmd.AddCommand(jobCmd)
+
+	jobCmd.Flags().String("mode", config.JobModeUpdate, "what to do: scan, update")
+	jobCmd.Flags().String("ref", "", "branch or tag to clone when the repo is a git URL")
+	jobCmd.Flags().Bool("ready-check", true, "check go, trivy and Trivy DB access before starting")
+	jobCmd.Flags().Bool("sparse", false, "clone only the Go module files and package directories of a git URL")
+	jobCmd.Flags().StringSlice("sparse-path", []string{}, "also check out this file or directory in a sparse clone (.gitignore pattern, repeatable)")
+
+	_ = viper.BindPFlag("job.mode", jobCmd.Flags().Lookup("mode"))
+	_ = viper.BindPFlag("job.ref", jobCmd.Flags().Lookup("ref"))
+	_ = viper.BindPFlag("job.ready-check", jobCmd.Flags().Lookup("ready-check"))
+	_ = viper.BindPFlag("job.sparse", jobCmd.Flags().Lookup("sparse"))
+	_ = viper.BindPFlag("job.sparse-paths", jobCmd.Flags().Lookup("sparse-path"))
+}
+
+func runJob(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if cfg.Job.Mode != config.JobModeScan && cfg.Job.Mode != config.JobModeUpdate {
+		return fmt.Errorf("invalid job mode %q (valid: scan, update)", cfg.Job.Mode)
+	}
+
+	// Logs end up in a collector, not a terminal
+	output.Configure(output.Options{
+		Quiet:   cfg.Quiet,
+		NoEmoji: true,
+		NoColor: true,
+		JSON:    cfg.LogFormat == config.LogFormatJSON,
+	})
+
+	repo := cfg.Job.Repo
+	if len(args) > 0 {
+		repo = args[0]
+	}
+	if repo == "" {
+		repo = cfg.Path
+	}
+	remote := git.IsRemoteURL(repo)
+
+	if cfg.Job.ReadyCheck {
+		if err := checkReady(cfg, remote || cfg.Worktree); err != nil {
+			return err
+		}
+	}
+
+	// Clone URLs may carry a token
+	shown := git.RedactURL(repo)
+	path := repo
+	if remote {
+		output.Infof("Cloning %s...", shown)
+		dir, err := git.Clone(repo, git.CloneOptions{
+			Ref:         cfg.Job.Ref,
+			Sparse:      cfg.Job.Sparse,
+			SparsePaths: cfg.Job.SparsePaths,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to clone %s: %w", shown, err)
+		}
+		defer func() { _ = os.RemoveAll(dir) }()
+		path = dir
+
+		if cfg.Job.Mode == config.JobModeUpdate && len(cfg.Plugins) == 0 {
+			output.Warnf("updates to a cloned repository are discarded after the run; " +
+				"use a post-update or complete plugin to publish them")
+		}
+	}
+
+	output.Infof("Running %s job for %s", cfg.Job.Mode, shown)
+
+	if cfg.Job.Mode == config.JobModeScan {
+		scanFailOnFindings = true
+		return runScan(cmd, []string{path})
+	}
+	return runUpdate(cmd, []string{path})
+}
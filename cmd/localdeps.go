@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/gomod"
+	"github.com/tamcore/go-autobump/internal/output"
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+// propagateLocalUpdates brings goModFile in line with the sibling modules it
+// replaces with their directory and that were changed earlier in the run:
+// their raised requirements also apply to it, so "go mod tidy" records them.
+// It returns whether goModFile changed and the scan result to act on, which
+// is rescanned if it did.
+func propagateLocalUpdates(cfg *config.Config, goModFile string, deps []gomod.LocalDependency, changed map[string]bool, result trivy.ScanResult) (trivy.ScanResult, bool) {
+	var updated []string
+	for _, dep := range deps {
+		if dep.Replaced && changed[dep.GoModPath] {
+			updated = append(updated, dep.GoModPath)
+		}
+	}
+	if len(updated) == 0 || cfg.DryRun {
+		return result, false
+	}
+
+	if cfg.SkipTidy {
+		output.Status(output.IconWarning, "  Local dependencies %v changed; run 'go mod tidy' to pick up their requirements", updated)
+		return result, false
+	}
+
+	snap, err := gomod.TakeSnapshot(goModFile)
+	if err != nil {
+		output.Status(output.IconWarning, "  Failed to snapshot module: %v", err)
+		return result, false
+	}
+	if err := gomod.ModTidy(gomod.GetModuleDir(goModFile)); err != nil {
+		output.Status(output.IconWarning, "  Failed to pick up the updates of local dependencies: %v", err)
+		return result, false
+	}
+	if moduleChanged, err := snap.Changed(); err != nil || !moduleChanged {
+		return result, false
+	}
+	output.Status(output.IconInfo, "  Picked up the updates of local dependencies %v", updated)
+
+	rescanned, err := trivy.Scan(goModFile, trivy.NewScanOptions(cfg))
+	if err != nil {
+		output.Status(output.IconWarning, "  Failed to rescan: %v", err)
+		return result, true
+	}
+	return rescanned, true
+}
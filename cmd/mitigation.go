@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/gomod"
+	"github.com/tamcore/go-autobump/internal/output"
+	"github.com/tamcore/go-autobump/internal/plugin"
+	"github.com/tamcore/go-autobump/internal/report"
+	"github.com/tamcore/go-autobump/internal/state"
+	"github.com/tamcore/go-autobump/internal/trivy"
+	"github.com/tamcore/go-autobump/internal/updater"
+	"golang.org/x/mod/semver"
+)
+
+// mitigation returns the patched fork or commit to replace the module of
+// vuln with, if mitigations are enabled and one is configured
+func mitigation(cfg *config.Config, vuln trivy.Vulnerability) *config.ReplacementConfig {
+	if !cfg.Mitigations.Enabled {
+		return nil
+	}
+	return updater.FindReplacement(cfg, vuln)
+}
+
+// mitigate replaces the module of vuln, which has no fixed release, by the
+// patched fork or commit r and records the replacement in the state file.
+// It returns the update recording that, or false if the replacement failed.
+// A checksum mismatch is recorded in incidents and halts the run.
+func mitigate(cfg *config.Config, sess *gomod.Session, goModFile string, vuln trivy.Vulnerability, r *config.ReplacementConfig,
+	run *runControl, incidents *[]report.Incident) (plugin.Update, bool) {
+	replacement, err := updater.Mitigate(sess, vuln, r, cfg)
+	if err != nil {
+		output.Status(output.IconWarning, "  Failed to replace %s to mitigate %s: %v", vuln.PkgName, vuln.VulnerabilityID, err)
+		haltOnIncident(run, incidents, goModFile, vuln.VulnerabilityID, err)
+		return plugin.Update{}, false
+	}
+
+	m := state.Mitigation{
+		GoMod:           relativeModulePath(cfg.Path, goModFile),
+		Module:          vuln.PkgName,
+		Version:         vuln.InstalledVersion,
+		Replacement:     replacement,
+		Vulnerabilities: []string{vuln.VulnerabilityID},
+		Applied:         time.Now().UTC(),
+	}
+	if versions, err := gomod.ListVersions(sess.Dir, vuln.PkgName); err == nil && len(versions) > 0 {
+		m.LatestRelease = versions[len(versions)-1]
+	}
+	if err := recordMitigation(cfg.Mitigations.StateFile, m); err != nil {
+		output.Warnf("failed to record the mitigation of %s: %v", vuln.VulnerabilityID, err)
+	}
+
+	u := pluginUpdate(goModFile, vuln, nil)
+	u.Replacement = replacement
+	output.Status(output.IconSuccess, "  Replaced %s with %s to mitigate %s until a fixed release ships",
+		vuln.PkgName, replacement, vuln.VulnerabilityID)
+	return u, true
+}
+
+// recordMitigation adds m to the state file at path
+func recordMitigation(path string, m state.Mitigation) error {
+	s, err := state.Read(path)
+	if err != nil {
+		return err
+	}
+	s.AddMitigation(m)
+	return s.Write(path)
+}
+
+// remindMitigations warns about the recorded mitigations whose module has
+// released a version since it was replaced, which may fix the mitigated
+// vulnerabilities
+func remindMitigations(cfg *config.Config) {
+	followUpMitigations(cfg, func(_ string, m state.Mitigation, release string) bool {
+		warnStaleMitigation(m, release)
+		return false
+	})
+}
+
+// cleanupMitigations removes the recorded replace directives whose module
+// has released a version since, updating the module to that release, if
+// Trivy confirms it fixes the mitigated vulnerabilities. It returns an
+// update per vulnerability fixed that way. A checksum mismatch is recorded
+// in incidents and halts the run, ending the cleanup.
+func cleanupMitigations(cfg *config.Config, policy updatePolicy, run *runControl, incidents *[]report.Incident) []plugin.Update {
+	var updates []plugin.Update
+	followUpMitigations(cfg, func(goModFile string, m state.Mitigation, release string) bool {
+		if run.aborted() {
+			return false
+		}
+		if !cfg.Mitigations.Cleanup {
+			warnStaleMitigation(m, release)
+			return false
+		}
+		if cfg.DryRun {
+			output.Status(output.IconDryRun, "Would remove the replacement of %s in %s and update it to %s",
+				m.Module, m.GoMod, release)
+			return false
+		}
+
+		sess := gomod.NewSession(goModFile)
+		sess.Policy = policy.forModule(goModFile)
+		sess.Lockstep = lockstepGroups(cfg)
+		sess.Compatibility = compatibilityRules(cfg)
+		if err := updater.Unmitigate(sess, m.Module, release, m.Vulnerabilities, cfg); err != nil {
+			output.Status(output.IconWarning, "Keeping the replacement of %s in %s: %v", m.Module, m.GoMod, err)
+			haltOnIncident(run, incidents, goModFile, strings.Join(m.Vulnerabilities, ", "), err)
+			return false
+		}
+
+		output.Status(output.IconSuccess, "Removed the replacement of %s in %s: %s fixes %s",
+			m.Module, m.GoMod, release, strings.Join(m.Vulnerabilities, ", "))
+		for _, id := range m.Vulnerabilities {
+			updates = append(updates, plugin.Update{
+				Module:           goModFile,
+				VulnerabilityID:  id,
+				Package:          m.Module,
+				InstalledVersion: m.Version,
+				FixedVersion:     release,
+			})
+		}
+		return true
+	})
+	return updates
+}
+
+// followUpMitigations calls handle for every recorded mitigation whose
+// module has a release newer than the vulnerable version and the newest
+// release when it was replaced. Mitigations that handle resolves, and
+// those whose replace directive is gone, are removed from the state file.
+func followUpMitigations(cfg *config.Config, handle func(goModFile string, m state.Mitigation, release string) bool) {
+	path := cfg.Mitigations.StateFile
+	s, err := state.Read(path)
+	if err != nil {
+		output.Warnf("%v", err)
+		return
+	}
+	if len(s.Mitigations) == 0 {
+		return
+	}
+
+	removed := false
+	for _, m := range append([]state.Mitigation(nil), s.Mitigations...) {
+		goModFile := filepath.Join(cfg.Path, filepath.FromSlash(m.GoMod))
+		parser, err := gomod.NewParser(goModFile)
+		if err == nil {
+			if _, ok := parser.Replacement(m.Module); ok {
+				if release := newerRelease(goModFile, m); release != "" && handle(goModFile, m, release) {
+					s.RemoveMitigation(m.GoMod, m.Module)
+					removed = true
+				}
+				continue
+			}
+		}
+		output.Infof("Replacement of %s in %s was removed, forgetting its mitigation", m.Module, m.GoMod)
+		s.RemoveMitigation(m.GoMod, m.Module)
+		removed = true
+	}
+
+	if removed && !cfg.DryRun {
+		if err := s.Write(path); err != nil {
+			output.Warnf("%v", err)
+		}
+	}
+}
+
+// newerRelease returns the newest release of m's module, of the same major
+// version, if it is newer than the vulnerable version and the newest
+// release when the module was replaced
+func newerRelease(goModFile string, m state.Mitigation) string {
+	versions, err := gomod.ListVersions(gomod.GetModuleDir(goModFile), m.Module)
+	if err != nil {
+		output.Warnf("cannot check %s for releases fixing %s: %v", m.Module, strings.Join(m.Vulnerabilities, ", "), err)
+		return ""
+	}
+
+	since := m.Version
+	if semver.Compare(m.LatestRelease, since) > 0 {
+		since = m.LatestRelease
+	}
+	newer := gomod.VersionsAfter(versions, since, false)
+	if len(newer) == 0 {
+		return ""
+	}
+	return newer[len(newer)-1]
+}
+
+// warnStaleMitigation asks to remove the replacement of m, as release may
+// fix the mitigated vulnerabilities
+func warnStaleMitigation(m state.Mitigation, release string) {
+	output.Warnf("%s is still replaced by %s in %s to mitigate %s, but %s released %s since: remove the replace directive and update if it fixes them",
+		m.Module, m.Replacement, m.GoMod, strings.Join(m.Vulnerabilities, ", "), m.Module, release)
+}
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/gomod"
+	"github.com/tamcore/go-autobump/internal/output"
+	"github.com/tamcore/go-autobump/internal/scanner"
+	"github.com/tamcore/go-autobump/internal/trivy"
+	"golang.org/x/mod/semver"
+)
+
+var outdatedCmd = &cobra.Command{
+	Use:   "outdated [path]",
+	Short: "List direct dependencies behind their latest release",
+	Long: `Outdated lists the direct dependencies of every module that are behind
+their latest release of the same major version, as reported by
+"go list -m -u all", to schedule proactive maintenance rather than only
+reacting to vulnerabilities.
+
+Each dependency is annotated with the vulnerabilities above the CVSS
+threshold that updating it to its latest release would also fix. Only the
+dependency's own vulnerabilities are considered, not those of modules it
+requires. --skip-scan lists the dependencies without running Trivy.
+
+Dependencies replaced by a local directory are not listed.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runOutdated,
+}
+
+var (
+	outdatedJSON     bool
+	outdatedSkipScan bool
+)
+
+func init() {
+	rootCmd.AddCommand(outdatedCmd)
+	outdatedCmd.Flags().BoolVar(&outdatedJSON, "json", false, "output as JSON")
+	outdatedCmd.Flags().BoolVar(&outdatedSkipScan, "skip-scan", false, "do not annotate the vulnerabilities updates would fix")
+}
+
+// outdatedModule lists the outdated dependencies of one go.mod
+type outdatedModule struct {
+	Module       string               `json:"module"`
+	Dependencies []outdatedDependency `json:"dependencies"`
+}
+
+// outdatedDependency is an outdated dependency with the vulnerabilities
+// updating it to its latest release fixes
+type outdatedDependency struct {
+	gomod.OutdatedModule
+	Fixes []string `json:"fixes,omitempty"`
+}
+
+func runOutdated(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Override path if provided as argument
+	if len(args) > 0 {
+		cfg.Path = args[0]
+	}
+
+	goModFiles, err := scanner.DiscoverGoModFiles(cfg.Path, cfg.Exclude...)
+	if err != nil {
+		return fmt.Errorf("failed to discover go.mod files: %w", err)
+	}
+	if len(goModFiles) == 0 {
+		fmt.Println("No go.mod files found")
+		return nil
+	}
+
+	var scanResults map[string]trivy.ScanResult
+	if !outdatedSkipScan {
+		if err := prepareScanner(cfg); err != nil {
+			return err
+		}
+		scanResults = scanModules(cfg.Path, goModFiles, trivy.NewScanOptions(cfg))
+	}
+
+	modules := []outdatedModule{}
+	for _, goModFile := range goModFiles {
+		output.Infof("Checking %s for updates...", goModFile)
+		deps, err := gomod.Outdated(gomod.GetModuleDir(goModFile))
+		if err != nil {
+			output.Warnf("failed to list the updates of %s: %v", goModFile, err)
+			continue
+		}
+		if len(deps) == 0 {
+			continue
+		}
+
+		result := trivy.FilterByThresholds(scanResults[goModFile], cvssThresholds(cfg))
+		module := outdatedModule{Module: goModFile}
+		for _, dep := range deps {
+			module.Dependencies = append(module.Dependencies, outdatedDependency{
+				OutdatedModule: dep,
+				Fixes:          fixedByUpdate(result.Vulnerabilities, dep),
+			})
+		}
+		modules = append(modules, module)
+	}
+
+	if outdatedJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(modules)
+	}
+	printOutdated(modules, !outdatedSkipScan)
+	return nil
+}
+
+// fixedByUpdate returns the vulnerabilities of dep that its latest release
+// fixes
+func fixedByUpdate(vulns []trivy.Vulnerability, dep gomod.OutdatedModule) []string {
+	var fixes []string
+	for _, vuln := range vulns {
+		if vuln.PkgName != dep.Path || !trivy.HasFixedVersion(vuln) {
+			continue
+		}
+		fixed := gomod.NormalizeVersion(trivy.SelectFixedVersion(vuln.InstalledVersion, vuln.FixedVersion))
+		if semver.IsValid(fixed) && semver.Compare(fixed, dep.Latest) <= 0 {
+			fixes = append(fixes, vuln.VulnerabilityID)
+		}
+	}
+	return fixes
+}
+
+// printOutdated prints the outdated dependencies of each module as a table
+func printOutdated(modules []outdatedModule, scanned bool) {
+	if len(modules) == 0 {
+		fmt.Println("All direct dependencies are up to date")
+		return
+	}
+
+	total, fixing := 0, 0
+	for _, module := range modules {
+		pathWidth := len("Dependency")
+		for _, dep := range module.Dependencies {
+			pathWidth = max(pathWidth, len(dep.Path))
+		}
+
+		fmt.Println()
+		output.Status(output.IconModule, "%s", module.Module)
+		header := fmt.Sprintf("  %-*s %-24s %-24s %-10s", pathWidth, "Dependency", "Current", "Latest", "Released")
+		if scanned {
+			header += " Fixes"
+		}
+		fmt.Println(strings.TrimRight(header, " "))
+		for _, dep := range module.Dependencies {
+			released := ""
+			if dep.LatestTime != nil {
+				released = dep.LatestTime.Format("2006-01-02")
+			}
+			line := fmt.Sprintf("  %-*s %-24s %-24s %-10s %s", pathWidth, dep.Path,
+				truncate(dep.Version, 24), truncate(dep.Latest, 24), released, strings.Join(dep.Fixes, ", "))
+			fmt.Println(strings.TrimRight(line, " "))
+
+			total++
+			if len(dep.Fixes) > 0 {
+				fixing++
+			}
+		}
+	}
+
+	fmt.Printf("\nTotal: %d outdated dependencies", total)
+	if scanned {
+		fmt.Printf(", %d of which would also fix vulnerabilities", fixing)
+	}
+	fmt.Println()
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/plugin"
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+// commandName is the name of the running subcommand, passed to plugins
+var commandName string
+
+// pluginContext returns the plugin context shared by all hooks of this run
+func pluginContext(cfg *config.Config, hook string) plugin.Context {
+	return plugin.Context{
+		Hook:    hook,
+		Command: commandName,
+		Path:    cfg.Path,
+		DryRun:  cfg.DryRun,
+	}
+}
+
+// runScanPlugins adds the vulnerabilities reported by scan plugins to the
+// results of each module, merging the findings of the same vulnerability
+// into one
+func runScanPlugins(cfg *config.Config, goModFiles []string, results map[string]trivy.ScanResult) error {
+	if len(plugin.Subscribed(cfg.Plugins, plugin.HookScan)) == 0 {
+		return nil
+	}
+
+	for _, goModFile := range goModFiles {
+		result, ok := results[goModFile]
+		if !ok {
+			continue
+		}
+
+		ctx := pluginContext(cfg, plugin.HookScan)
+		ctx.Module = goModFile
+		ctx.Results = []trivy.ScanResult{result}
+
+		vulns, err := plugin.Scan(cfg.Plugins, ctx)
+		if err != nil {
+			return err
+		}
+		result.Vulnerabilities = trivy.Merge(append(result.Vulnerabilities, vulns...))
+		results[goModFile] = result
+	}
+	return nil
+}
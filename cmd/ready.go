@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/health"
+	"github.com/tamcore/go-autobump/internal/output"
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+var readyCmd = &cobra.Command{
+	Use:   "ready",
+	Short: "Check that go, trivy and the Trivy DB are usable",
+	Long: `Ready runs a self-check of the tools go-autobump depends on: the go command,
+a supported trivy version, and access to the Trivy vulnerability database
+(downloading it unless --skip-trivy-db-update is set).
+
+It exits with code 3 if any check fails, so it can be used as an init
+container or readiness probe.`,
+	Args: cobra.NoArgs,
+	RunE: runReady,
+	// Failures are reported through logs and exit codes, not usage help
+	SilenceUsage: true,
+}
+
+var readyGit bool
+
+func init() {
+	rootCmd.AddCommand(readyCmd)
+	readyCmd.Flags().BoolVar(&readyGit, "git", false, "also check that git is available")
+}
+
+func runReady(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	return checkReady(cfg, readyGit)
+}
+
+// checkReady runs the health checks and reports each result
+func checkReady(cfg *config.Config, git bool) error {
+	results := health.Check(health.Options{
+		Scan: trivy.NewScanOptions(cfg),
+		Git:  git,
+	})
+
+	if failed := reportHealth(results); failed > 0 {
+		return withExitCode(ExitNotReady, fmt.Errorf("%d readiness check(s) failed", failed))
+	}
+	return nil
+}
+
+// reportHealth prints each health check result, with the tip of failed ones,
+// and returns the number of failed checks
+func reportHealth(results []health.Result) int {
+	failed := 0
+	for _, r := range results {
+		switch {
+		case r.Err != nil && r.Tip != "":
+			output.Status(output.IconFailure, "%s: %v\n     Tip: %s", r.Name, r.Err, r.Tip)
+			failed++
+		case r.Err != nil:
+			output.Status(output.IconFailure, "%s: %v", r.Name, r.Err)
+			failed++
+		case r.Skipped:
+			output.Status(output.IconInfo, "%s: skipped, %s", r.Name, r.Detail)
+		default:
+			output.Status(output.IconSuccess, "%s: %s", r.Name, r.Detail)
+		}
+	}
+	return failed
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/output"
+	"github.com/tamcore/go-autobump/internal/report"
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report [path]",
+	Short: "Generate a vulnerability report",
+	Long: `Report scans for vulnerabilities and renders the findings as a shareable
+document. The HTML format is a single self-contained file with severity and
+fix status charts, per-module tables and links to the advisories, suitable
+for attaching to audit evidence.
+
+With --template, the report is rendered by a Go text/template instead. The
+template receives the same model as the JSON format.
+
+Use --input to build the report from a saved "scan --json" result instead of
+running a new scan.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runReport,
+}
+
+var (
+	reportFormat string
+	reportOutput string
+	reportInput  string
+
+	// outputTemplate is a user-supplied Go template replacing the built-in
+	// output of scan, update and report
+	outputTemplate string
+)
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+	reportCmd.Flags().StringVar(&reportFormat, "format", report.FormatHTML, "report format: html, json, markdown")
+	reportCmd.Flags().StringVarP(&reportOutput, "output", "o", "", "file to write the report to (default: stdout)")
+	reportCmd.Flags().StringVar(&reportInput, "input", "", "build the report from a saved scan --json result")
+	addTemplateFlag(reportCmd)
+}
+
+// addTemplateFlag registers --template on a command that renders a report
+func addTemplateFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&outputTemplate, "template", "", "render output with this Go template file instead of the built-in format")
+}
+
+func runReport(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Override path if provided as argument
+	if len(args) > 0 {
+		cfg.Path = args[0]
+	}
+
+	switch reportFormat {
+	case report.FormatHTML, report.FormatJSON, report.FormatMarkdown:
+	default:
+		return fmt.Errorf("invalid report format %q (valid: html, json, markdown)", reportFormat)
+	}
+
+	var results []trivy.ScanResult
+	if reportInput != "" {
+		results, err = trivy.LoadResults(reportInput)
+	} else {
+		if err := prepareScanner(cfg); err != nil {
+			return err
+		}
+		results, _, _, err = collectScanResults(cfg)
+	}
+	if err != nil {
+		return err
+	}
+
+	known, err := loadBaseline(cfg)
+	if err != nil {
+		return err
+	}
+	results = applyBaseline(known, cfg.Path, results)
+
+	r := report.New(cfg.Path, cfg.CVSSThreshold, results)
+
+	var w io.Writer = os.Stdout
+	if reportOutput != "" {
+		f, err := os.Create(reportOutput)
+		if err != nil {
+			return fmt.Errorf("failed to create report: %w", err)
+		}
+		defer func() { _ = f.Close() }()
+		w = f
+	}
+
+	if outputTemplate != "" {
+		err = r.WriteTemplate(w, outputTemplate)
+	} else {
+		err = r.Write(w, reportFormat)
+	}
+	if err != nil {
+		return err
+	}
+
+	if reportOutput != "" {
+		output.Status(output.IconDocument, "Report written to %s", reportOutput)
+	}
+	return nil
+}
+
+// saveReport writes the JSON report of a scan or update run to report-file,
+// e.g. for a fleet run to aggregate
+func saveReport(cfg *config.Config, r *report.Report) error {
+	if cfg.ReportFile == "" {
+		return nil
+	}
+	f, err := os.Create(cfg.ReportFile)
+	if err != nil {
+		return err
+	}
+	if err := r.Write(f, report.FormatJSON); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
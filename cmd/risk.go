@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/tamcore/go-autobump/internal/epss"
+	"github.com/tamcore/go-autobump/internal/kev"
+	"github.com/tamcore/go-autobump/internal/output"
+	"github.com/tamcore/go-autobump/internal/plugin"
+	"github.com/tamcore/go-autobump/internal/risk"
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+// riskDelta scores every scanned module before the run and without the
+// vulnerabilities the run fixed. EPSS scores and KEV entries are fetched
+// first; if that fails the scores are weighted by CVSS alone.
+func riskDelta(goModFiles []string, scanResults map[string]trivy.ScanResult, updates []plugin.Update) *risk.Delta {
+	var before []trivy.ScanResult
+	for _, goModFile := range goModFiles {
+		if result, ok := scanResults[goModFile]; ok {
+			before = append(before, result)
+		}
+	}
+
+	ctx := context.Background()
+	if err := epss.NewClient().Enrich(ctx, before); err != nil {
+		output.Warnf("failed to fetch EPSS scores for the risk score: %v", err)
+	}
+	if err := kev.NewClient().Enrich(ctx, before); err != nil {
+		output.Warnf("failed to fetch the KEV catalog for the risk score: %v", err)
+	}
+
+	return risk.Compare(before, remainingResults(before, updates))
+}
@@ -3,10 +3,20 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"github.com/tamcore/go-autobump/internal/ai"
+	"github.com/tamcore/go-autobump/internal/alias"
 	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/exploit"
+	"github.com/tamcore/go-autobump/internal/gomod"
+	"github.com/tamcore/go-autobump/internal/httpclient"
+	"github.com/tamcore/go-autobump/internal/output"
+	"github.com/tamcore/go-autobump/internal/plugin"
+	"github.com/tamcore/go-autobump/internal/runner"
+	"github.com/tamcore/go-autobump/internal/trivy"
 )
 
 var cfgFile string
@@ -23,6 +33,121 @@ It handles direct and indirect dependencies differently:
 - Direct dependencies are updated to their nearest fixed version
 - Indirect dependencies are traced back to their direct dependency
   and updated through the dependency chain`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.ApplyProfile(viper.GetString("profile")); err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		cfg, err := config.Get()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		if cfg.LogFormat != config.LogFormatText && cfg.LogFormat != config.LogFormatJSON {
+			return fmt.Errorf("invalid log format %q (valid: text, json)", cfg.LogFormat)
+		}
+
+		for _, age := range []string{cfg.MinAge, cfg.MaxAge} {
+			if _, err := config.ParseAge(age); err != nil {
+				return err
+			}
+		}
+
+		output.Configure(output.Options{
+			Quiet:   cfg.Quiet,
+			NoEmoji: cfg.NoEmoji,
+			NoColor: cfg.NoColor,
+			JSON:    cfg.LogFormat == config.LogFormatJSON,
+		})
+
+		if err := plugin.Validate(cfg.Plugins); err != nil {
+			return fmt.Errorf("invalid plugin configuration: %w", err)
+		}
+		commandName = cmd.Name()
+
+		memory, err := config.ParseSize(cfg.Limits.Memory)
+		if err != nil {
+			return fmt.Errorf("invalid limits.memory: %w", err)
+		}
+		if cfg.Limits.CPUs < 0 {
+			return fmt.Errorf("invalid limits.cpus %v (valid: 0 for no limit, or more)", cfg.Limits.CPUs)
+		}
+		runner.Configure(runner.Options{
+			GoBinary:    cfg.GoBinary,
+			TrivyBinary: cfg.TrivyBinary,
+			GoToolchain: cfg.GoToolchain,
+			GoModCache:  cfg.GoModCache,
+			Timeouts: map[string]time.Duration{
+				runner.Trivy: cfg.ScanTimeout,
+				runner.Go:    cfg.Limits.GoTimeout,
+			},
+			Limits: runner.Limits{
+				Memory: memory,
+				CPUs:   cfg.Limits.CPUs,
+				Cgroup: cfg.Limits.Cgroup,
+			},
+			Env: cfg.Env,
+		})
+
+		if err := httpclient.Configure(httpclient.Options{
+			CAFile:             cfg.TLS.CAFile,
+			InsecureSkipVerify: cfg.TLS.InsecureSkipVerify,
+		}); err != nil {
+			return fmt.Errorf("invalid tls configuration: %w", err)
+		}
+		if cfg.TLS.InsecureSkipVerify {
+			output.Warnf("TLS certificate verification is disabled (tls.insecure-skip-verify)")
+		}
+
+		for _, ids := range cfg.Aliases.Table {
+			if len(ids) > 0 {
+				alias.Add(ids[0], ids[1:]...)
+			}
+		}
+
+		if err := trivy.ValidateExtraArgs(cfg.Trivy.ExtraArgs); err != nil {
+			return fmt.Errorf("invalid trivy.extra-args: %w", err)
+		}
+		if cfg.Trivy.Parallel < 0 {
+			return fmt.Errorf("invalid trivy.parallel %d (valid: 0 or more)", cfg.Trivy.Parallel)
+		}
+		if check := cfg.TrivyVersionCheck; check != config.TrivyVersionCheckWarn &&
+			check != config.TrivyVersionCheckError && check != config.TrivyVersionCheckOff {
+			return fmt.Errorf("invalid trivy-version-check %q (valid: warn, error, off)", check)
+		}
+
+		if err := exploit.Validate(cfg.Exploits.Sources); err != nil {
+			return fmt.Errorf("invalid exploits.sources: %w", err)
+		}
+
+		trivy.SetRetryPolicy(trivy.RetryPolicy{
+			Retries: cfg.TrivyRetries,
+			Backoff: cfg.TrivyRetryBackoff,
+		})
+
+		if c := cfg.AI.MinConfidenceForNotAffected; c < 0 || c > 1 {
+			return fmt.Errorf("invalid ai.min-confidence-for-not-affected %v (valid: 0 to 1)", c)
+		}
+
+		ai.SetAuditLog(cfg.AI.AuditLog)
+		if _, err := ai.LoadPrompts(cfg.AI.Prompts.VEXSystem, cfg.AI.Prompts.VEXUser); err != nil {
+			return err
+		}
+
+		for _, rule := range cfg.CompatibilityRules {
+			if rule.Match != "" && rule.Match != gomod.MatchMinor && rule.Match != gomod.MatchExact {
+				return fmt.Errorf("invalid match %q of compatibility rule %q (valid: minor, exact)", rule.Match, rule.Name)
+			}
+		}
+
+		if used := viper.ConfigFileUsed(); used != "" {
+			output.Infof("Using config file: %s", used)
+		}
+		if cfg.Profile != "" {
+			output.Infof("Using profile: %s", cfg.Profile)
+		}
+		return nil
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -34,49 +159,220 @@ func init() {
 	cobra.OnInitialize(initConfig)
 
 	// Global flags
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is ./.autobump.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $AUTOBUMP_CONFIG or ./.autobump.yaml)")
+	rootCmd.PersistentFlags().String("profile", "", "apply this profile of the config file over its top-level settings")
 	rootCmd.PersistentFlags().String("path", ".", "target directory to scan")
 	rootCmd.PersistentFlags().StringSlice("exclude", []string{}, "glob patterns to exclude (e.g., 'examples/*/go.mod')")
 	rootCmd.PersistentFlags().Float64("cvss-threshold", 7.0, "minimum CVSS score to act on")
+	rootCmd.PersistentFlags().String("min-age", "", "skip vulnerabilities published less than this long ago (e.g. 7d)")
+	rootCmd.PersistentFlags().String("max-age", "", "skip vulnerabilities published more than this long ago (e.g. 90d)")
 	rootCmd.PersistentFlags().Bool("dry-run", false, "preview changes without applying them")
 	rootCmd.PersistentFlags().Bool("skip-tidy", false, "skip running 'go mod tidy' after updates")
 	rootCmd.PersistentFlags().Bool("allow-major", false, "allow major version bumps")
+	rootCmd.PersistentFlags().Bool("major-approval", false, "record required major version bumps for approval instead of failing, and request it via the forge")
+	rootCmd.PersistentFlags().Bool("atomic", false, "roll back all updates to a module if any of them fail")
+	rootCmd.PersistentFlags().Bool("worktree", false, "perform each update in a temporary git worktree and merge back only verified fixes")
+	rootCmd.PersistentFlags().Bool("commit-changes", false, "commit the go.mod, go.sum and vendor changes of an update run")
+	rootCmd.PersistentFlags().String("commit-author", "", "author of the commit as \"Name <email>\", e.g. a bot identity (default: git's user.name and user.email)")
+	rootCmd.PersistentFlags().String("commit-signing-key", "", "GPG key ID, or SSH key file with commit.signing-format ssh, to sign the commit with")
+	rootCmd.PersistentFlags().String("attestation-file", "", "write an in-toto SLSA provenance attestation of the changes of an update run to this file")
+	rootCmd.PersistentFlags().String("attestation-signing-key", "", "PEM private key file (ECDSA, Ed25519 or RSA) to sign the attestation with as a DSSE envelope")
+	rootCmd.PersistentFlags().Bool("batch", false, "apply all updates of a module at once and verify them with a single scan")
+	rootCmd.PersistentFlags().Bool("align-versions", false, "after updating, raise the updated dependencies to the same version in all modules")
+	rootCmd.PersistentFlags().Bool("all-patch", false, "also bump all direct dependencies to their latest patch release, vulnerable or not")
+	rootCmd.PersistentFlags().StringSlice("confirm-module", []string{}, "module path confirmed as intended although updates adding it look suspicious (repeatable)")
+	rootCmd.PersistentFlags().Float64("scorecard-min-score", 0, "OpenSSF Scorecard score (0-10) below which updates to a project are flagged, looked up on deps.dev (0: no check)")
+	rootCmd.PersistentFlags().String("scorecard-action", "warn", "action on updates to projects scoring below --scorecard-min-score: warn, block")
+	rootCmd.PersistentFlags().Bool("builder-images", false, "also check the golang images of Dockerfiles for vulnerable or end-of-life Go versions, bumping them during updates")
+	rootCmd.PersistentFlags().String("strategy", "latest", "version selection for indirect fixes: minimal, latest, patch-only")
+	rootCmd.PersistentFlags().String("fix-version", "fixed", "version selection for direct fixes: fixed, latest-patch, latest")
+	rootCmd.PersistentFlags().Bool("respect-bot-config", true, "honor ignore and allowed-version rules from Renovate and Dependabot configs")
+	rootCmd.PersistentFlags().Bool("mitigate", false, "replace vulnerable modules without a fixed release by the patched forks or commits in mitigations.replacements")
+	rootCmd.PersistentFlags().Bool("risk-score", false, "report the risk score (CVSS weighted by EPSS, CISA KEV and, with --exploits, exploit maturity) before and after an update run")
+	rootCmd.PersistentFlags().Bool("impact-analysis", false, "list the packages importing each vulnerable module in reports and AI prompts")
+
+	rootCmd.PersistentFlags().Duration("timeout", 0, "stop the run after this long, keeping the results so far (e.g. 30m; 0: no limit)")
+	rootCmd.PersistentFlags().Duration("scan-timeout", 0, "timeout of each Trivy scan (0: Trivy's default of 5m)")
+	rootCmd.PersistentFlags().Duration("go-timeout", 0, "timeout of each go command (0: no limit)")
+	rootCmd.PersistentFlags().String("memory-limit", "", "memory each go and trivy command may use, e.g. 2GiB (empty: no limit)")
+	rootCmd.PersistentFlags().Float64("cpu-limit", 0, "CPUs each go and trivy command may use, e.g. 1.5 (0: no limit)")
+	rootCmd.PersistentFlags().String("cgroup", "", "delegated cgroup v2 directory to enforce the memory and CPU limits in (Linux only)")
+	rootCmd.PersistentFlags().Duration("update-timeout", 0, "timeout of each vulnerability update (0: no limit)")
+
+	rootCmd.PersistentFlags().Bool("skip-empty-modules", true, "skip modules without packages, such as placeholder modules and tooling stubs")
+
+	rootCmd.PersistentFlags().String("baseline", "", "baseline file of known vulnerabilities; only act on vulnerabilities not in it")
+	rootCmd.PersistentFlags().String("from-snyk", "", "take the vulnerabilities from this \"snyk test --json\" output instead of scanning with Trivy")
+	rootCmd.PersistentFlags().Bool("osv-aliases", false, "look up the aliases of vulnerabilities (CVE, GHSA and GO IDs) on osv.dev")
+	rootCmd.PersistentFlags().Bool("exploits", false, "annotate vulnerabilities with their exploit maturity (public or weaponized) and act on the most mature first")
+	rootCmd.PersistentFlags().StringSlice("exploit-sources", []string{"cisa-kev", "exploit-db"}, "sources of exploit maturity (cisa-kev, vulncheck-kev, exploit-db)")
+	rootCmd.PersistentFlags().String("report-file", "", "write the JSON report of scan and update runs to this file")
+
+	// Output configuration
+	rootCmd.PersistentFlags().BoolP("quiet", "q", false, "only print warnings, errors and results")
+	rootCmd.PersistentFlags().Bool("no-emoji", false, "use plain-text markers instead of emoji")
+	rootCmd.PersistentFlags().Bool("no-color", false, "disable colored output (also honors NO_COLOR)")
+	rootCmd.PersistentFlags().String("log-format", "text", "status output format: text, json")
 
 	// Trivy configuration
 	rootCmd.PersistentFlags().Bool("skip-trivy-db-update", false, "skip downloading Trivy DB (use only if DB is pre-downloaded)")
+	rootCmd.PersistentFlags().Bool("ignore-unfixed", false, "drop vulnerabilities without a fixed version from Trivy scans")
+	rootCmd.PersistentFlags().StringSlice("trivy-db-repository", []string{}, "OCI repository to download the Trivy DB from, e.g. a mirror (repeatable)")
+	rootCmd.PersistentFlags().Int("trivy-parallel", 0, "number of files Trivy analyzes in parallel (0: Trivy's default)")
+	rootCmd.PersistentFlags().StringArray("trivy-extra-args", []string{}, "further allowed \"trivy fs\" flag, e.g. --skip-dirs=testdata (repeatable)")
+	rootCmd.PersistentFlags().Int("trivy-retries", 2, "retries of a Trivy run failing with a transient (e.g. network) error")
+	rootCmd.PersistentFlags().Duration("trivy-retry-backoff", 5*time.Second, "wait before the first Trivy retry, doubling with each retry")
+	rootCmd.PersistentFlags().String("trivy-version-check", "warn", "action when Trivy is older than the minimum supported version: warn, error, off")
+
+	// Tool configuration
+	rootCmd.PersistentFlags().String("go-binary", "go", "go command to execute")
+	rootCmd.PersistentFlags().String("trivy-binary", "trivy", "trivy command to execute")
+	rootCmd.PersistentFlags().String("tls-ca-file", "", "PEM bundle of additional CAs trusted for HTTPS requests (AI, forges, Jira, exporters)")
+	rootCmd.PersistentFlags().Bool("tls-insecure-skip-verify", false, "disable TLS certificate verification of HTTPS requests (lab use only)")
+	rootCmd.PersistentFlags().String("go-toolchain", "", `GOTOOLCHAIN for go commands ("module" uses each go.mod's toolchain directive)`)
+	rootCmd.PersistentFlags().String("go-mod-cache", "", "GOMODCACHE for go commands, e.g. a cache volume shared between runs")
+	rootCmd.PersistentFlags().Bool("warm-mod-cache", false, "download the dependencies of every module before updating")
 
 	// VEX generation flags
 	rootCmd.PersistentFlags().Bool("generate-vex", false, "generate VEX documents for unfixed CVEs")
 	rootCmd.PersistentFlags().String("vex-output", ".vex.openvex.json", "output path for VEX documents")
+	rootCmd.PersistentFlags().StringSlice("vex-product", []string{}, "application or image purl to make VEX statements for, with the vulnerable module as subcomponent (repeatable)")
+	rootCmd.PersistentFlags().Bool("vex-review", false, "queue AI-generated VEX statements for human review with 'vex review' before publishing them")
+	rootCmd.PersistentFlags().String("vex-review-queue", ".vex.pending.json", "file of AI-generated VEX statements pending review")
+	rootCmd.PersistentFlags().String("osv-scanner-config", "", "osv-scanner config file to add ignore rules for not_affected VEX statements to (e.g. osv-scanner.toml)")
+
+	// Forge configuration flags
+	rootCmd.PersistentFlags().String("forge", "", "code hosting provider for issues and comments: github, gitlab")
+	rootCmd.PersistentFlags().String("forge-repo", "", "repository as owner/name, or GitLab project path (default: from CI environment)")
+	rootCmd.PersistentFlags().String("forge-url", "", "forge API base URL for GitHub Enterprise or self-hosted GitLab")
+	rootCmd.PersistentFlags().Int64("github-app-id", 0, "authenticate with GitHub as an installation of this GitHub App instead of a token")
+	rootCmd.PersistentFlags().Int64("github-app-installation-id", 0, "installation of the GitHub App (default: its installation on --forge-repo)")
+	rootCmd.PersistentFlags().String("github-app-private-key-file", "", "PEM private key file of the GitHub App (or use AUTOBUMP_FORGE_APP_PRIVATE_KEY)")
+	rootCmd.PersistentFlags().Float64("forge-rate-limit", 0, "maximum forge API requests per second (0: no limit)")
+	rootCmd.PersistentFlags().Int("pull-request", 0, "pull/merge request number to comment on instead of opening issues")
+	rootCmd.PersistentFlags().Bool("summary-comment", false, "keep a comment with the run's findings and fixes on the --pull-request, updated by every run")
+	rootCmd.PersistentFlags().Bool("status-check", false, "publish the remaining vulnerabilities as a commit status for branch protection rules")
+	rootCmd.PersistentFlags().String("commit", "", "commit to publish the status on (default: the pull request head, GITHUB_SHA, CI_COMMIT_SHA or HEAD)")
+	rootCmd.PersistentFlags().String("jira-url", "", "Jira site to create remediation tickets in (token via AUTOBUMP_JIRA_TOKEN or JIRA_API_TOKEN)")
+	rootCmd.PersistentFlags().String("jira-project", "", "Jira project key for remediation tickets")
+	rootCmd.PersistentFlags().Bool("track-unfixed", false, "open a forge issue per unfixed vulnerability and close it once no longer reported")
 
 	// AI configuration flags
 	rootCmd.PersistentFlags().String("ai-api-key", "", "API key for AI provider (or use AUTOBUMP_AI_API_KEY)")
+	rootCmd.PersistentFlags().String("ai-api-key-file", "", "file to read the AI API key from")
 	rootCmd.PersistentFlags().String("ai-endpoint", "https://api.openai.com/v1", "AI API endpoint")
 	rootCmd.PersistentFlags().String("ai-model", "gpt-4o", "AI model to use")
+	rootCmd.PersistentFlags().Bool("ai-stream", false, "stream AI completions, showing progress and stopping them on interrupt")
+	rootCmd.PersistentFlags().String("ai-consensus-model", "", "second AI model that must agree with not_affected statements of CRITICAL vulnerabilities")
+	rootCmd.PersistentFlags().String("ai-audit-log", "", "append every AI prompt and response, with credentials redacted, to this file as JSON lines")
+	rootCmd.PersistentFlags().Bool("ai-triage", false, "ask the AI for a remediation of each failed update (requires an AI API key)")
+	rootCmd.PersistentFlags().Bool("ai-dry-run", false, "print the AI prompts instead of sending them (no API key needed)")
 
 	// Bind flags to Viper (errors are ignored as these are non-critical)
+	_ = viper.BindPFlag("profile", rootCmd.PersistentFlags().Lookup("profile"))
 	_ = viper.BindPFlag("path", rootCmd.PersistentFlags().Lookup("path"))
 	_ = viper.BindPFlag("exclude", rootCmd.PersistentFlags().Lookup("exclude"))
 	_ = viper.BindPFlag("cvss-threshold", rootCmd.PersistentFlags().Lookup("cvss-threshold"))
+	_ = viper.BindPFlag("min-age", rootCmd.PersistentFlags().Lookup("min-age"))
+	_ = viper.BindPFlag("max-age", rootCmd.PersistentFlags().Lookup("max-age"))
 	_ = viper.BindPFlag("dry-run", rootCmd.PersistentFlags().Lookup("dry-run"))
 	_ = viper.BindPFlag("skip-tidy", rootCmd.PersistentFlags().Lookup("skip-tidy"))
 	_ = viper.BindPFlag("allow-major", rootCmd.PersistentFlags().Lookup("allow-major"))
+	_ = viper.BindPFlag("major-approval", rootCmd.PersistentFlags().Lookup("major-approval"))
+	_ = viper.BindPFlag("atomic", rootCmd.PersistentFlags().Lookup("atomic"))
+	_ = viper.BindPFlag("worktree", rootCmd.PersistentFlags().Lookup("worktree"))
+	_ = viper.BindPFlag("commit.enabled", rootCmd.PersistentFlags().Lookup("commit-changes"))
+	_ = viper.BindPFlag("commit.author", rootCmd.PersistentFlags().Lookup("commit-author"))
+	_ = viper.BindPFlag("commit.signing-key", rootCmd.PersistentFlags().Lookup("commit-signing-key"))
+	_ = viper.BindPFlag("attestation.file", rootCmd.PersistentFlags().Lookup("attestation-file"))
+	_ = viper.BindPFlag("attestation.signing-key", rootCmd.PersistentFlags().Lookup("attestation-signing-key"))
+	_ = viper.BindPFlag("batch", rootCmd.PersistentFlags().Lookup("batch"))
+	_ = viper.BindPFlag("align-versions", rootCmd.PersistentFlags().Lookup("align-versions"))
+	_ = viper.BindPFlag("all-patch", rootCmd.PersistentFlags().Lookup("all-patch"))
+	_ = viper.BindPFlag("module-check.confirmed", rootCmd.PersistentFlags().Lookup("confirm-module"))
+	_ = viper.BindPFlag("scorecard.min-score", rootCmd.PersistentFlags().Lookup("scorecard-min-score"))
+	_ = viper.BindPFlag("scorecard.action", rootCmd.PersistentFlags().Lookup("scorecard-action"))
+	_ = viper.BindPFlag("builder-images", rootCmd.PersistentFlags().Lookup("builder-images"))
+	_ = viper.BindPFlag("strategy", rootCmd.PersistentFlags().Lookup("strategy"))
+	_ = viper.BindPFlag("fix-version", rootCmd.PersistentFlags().Lookup("fix-version"))
+	_ = viper.BindPFlag("respect-bot-config", rootCmd.PersistentFlags().Lookup("respect-bot-config"))
+	_ = viper.BindPFlag("mitigations.enabled", rootCmd.PersistentFlags().Lookup("mitigate"))
+	_ = viper.BindPFlag("risk-score", rootCmd.PersistentFlags().Lookup("risk-score"))
+	_ = viper.BindPFlag("impact-analysis", rootCmd.PersistentFlags().Lookup("impact-analysis"))
+	_ = viper.BindPFlag("timeout", rootCmd.PersistentFlags().Lookup("timeout"))
+	_ = viper.BindPFlag("scan-timeout", rootCmd.PersistentFlags().Lookup("scan-timeout"))
+	_ = viper.BindPFlag("limits.go-timeout", rootCmd.PersistentFlags().Lookup("go-timeout"))
+	_ = viper.BindPFlag("limits.memory", rootCmd.PersistentFlags().Lookup("memory-limit"))
+	_ = viper.BindPFlag("limits.cpus", rootCmd.PersistentFlags().Lookup("cpu-limit"))
+	_ = viper.BindPFlag("limits.cgroup", rootCmd.PersistentFlags().Lookup("cgroup"))
+	_ = viper.BindPFlag("update-timeout", rootCmd.PersistentFlags().Lookup("update-timeout"))
+	_ = viper.BindPFlag("skip-empty-modules", rootCmd.PersistentFlags().Lookup("skip-empty-modules"))
+	_ = viper.BindPFlag("baseline", rootCmd.PersistentFlags().Lookup("baseline"))
+	_ = viper.BindPFlag("from-snyk", rootCmd.PersistentFlags().Lookup("from-snyk"))
+	_ = viper.BindPFlag("aliases.osv", rootCmd.PersistentFlags().Lookup("osv-aliases"))
+	_ = viper.BindPFlag("exploits.enabled", rootCmd.PersistentFlags().Lookup("exploits"))
+	_ = viper.BindPFlag("exploits.sources", rootCmd.PersistentFlags().Lookup("exploit-sources"))
+	_ = viper.BindPFlag("report-file", rootCmd.PersistentFlags().Lookup("report-file"))
+	_ = viper.BindPFlag("quiet", rootCmd.PersistentFlags().Lookup("quiet"))
+	_ = viper.BindPFlag("no-emoji", rootCmd.PersistentFlags().Lookup("no-emoji"))
+	_ = viper.BindPFlag("no-color", rootCmd.PersistentFlags().Lookup("no-color"))
+	_ = viper.BindPFlag("log-format", rootCmd.PersistentFlags().Lookup("log-format"))
 	_ = viper.BindPFlag("skip-trivy-db-update", rootCmd.PersistentFlags().Lookup("skip-trivy-db-update"))
+	_ = viper.BindPFlag("trivy.ignore-unfixed", rootCmd.PersistentFlags().Lookup("ignore-unfixed"))
+	_ = viper.BindPFlag("trivy.db-repository", rootCmd.PersistentFlags().Lookup("trivy-db-repository"))
+	_ = viper.BindPFlag("trivy.parallel", rootCmd.PersistentFlags().Lookup("trivy-parallel"))
+	_ = viper.BindPFlag("trivy.extra-args", rootCmd.PersistentFlags().Lookup("trivy-extra-args"))
+	_ = viper.BindPFlag("trivy-retries", rootCmd.PersistentFlags().Lookup("trivy-retries"))
+	_ = viper.BindPFlag("trivy-retry-backoff", rootCmd.PersistentFlags().Lookup("trivy-retry-backoff"))
+	_ = viper.BindPFlag("trivy-version-check", rootCmd.PersistentFlags().Lookup("trivy-version-check"))
+	_ = viper.BindPFlag("go-binary", rootCmd.PersistentFlags().Lookup("go-binary"))
+	_ = viper.BindPFlag("trivy-binary", rootCmd.PersistentFlags().Lookup("trivy-binary"))
+	_ = viper.BindPFlag("tls.ca-file", rootCmd.PersistentFlags().Lookup("tls-ca-file"))
+	_ = viper.BindPFlag("tls.insecure-skip-verify", rootCmd.PersistentFlags().Lookup("tls-insecure-skip-verify"))
+	_ = viper.BindPFlag("go-toolchain", rootCmd.PersistentFlags().Lookup("go-toolchain"))
+	_ = viper.BindPFlag("go-mod-cache", rootCmd.PersistentFlags().Lookup("go-mod-cache"))
+	_ = viper.BindPFlag("warm-mod-cache", rootCmd.PersistentFlags().Lookup("warm-mod-cache"))
 	_ = viper.BindPFlag("generate-vex", rootCmd.PersistentFlags().Lookup("generate-vex"))
 	_ = viper.BindPFlag("vex-output", rootCmd.PersistentFlags().Lookup("vex-output"))
+	_ = viper.BindPFlag("vex-products", rootCmd.PersistentFlags().Lookup("vex-product"))
+	_ = viper.BindPFlag("vex-review", rootCmd.PersistentFlags().Lookup("vex-review"))
+	_ = viper.BindPFlag("vex-review-queue", rootCmd.PersistentFlags().Lookup("vex-review-queue"))
+	_ = viper.BindPFlag("osv-scanner-config", rootCmd.PersistentFlags().Lookup("osv-scanner-config"))
+	_ = viper.BindPFlag("forge.provider", rootCmd.PersistentFlags().Lookup("forge"))
+	_ = viper.BindPFlag("forge.repo", rootCmd.PersistentFlags().Lookup("forge-repo"))
+	_ = viper.BindPFlag("forge.url", rootCmd.PersistentFlags().Lookup("forge-url"))
+	_ = viper.BindPFlag("forge.app-id", rootCmd.PersistentFlags().Lookup("github-app-id"))
+	_ = viper.BindPFlag("forge.app-installation-id", rootCmd.PersistentFlags().Lookup("github-app-installation-id"))
+	_ = viper.BindPFlag("forge.app-private-key-file", rootCmd.PersistentFlags().Lookup("github-app-private-key-file"))
+	_ = viper.BindPFlag("forge.rate-limit", rootCmd.PersistentFlags().Lookup("forge-rate-limit"))
+	_ = viper.BindPFlag("forge.pull-request", rootCmd.PersistentFlags().Lookup("pull-request"))
+	_ = viper.BindPFlag("forge.summary-comment", rootCmd.PersistentFlags().Lookup("summary-comment"))
+	_ = viper.BindPFlag("forge.status-check", rootCmd.PersistentFlags().Lookup("status-check"))
+	_ = viper.BindPFlag("forge.commit", rootCmd.PersistentFlags().Lookup("commit"))
+	_ = viper.BindPFlag("jira.url", rootCmd.PersistentFlags().Lookup("jira-url"))
+	_ = viper.BindPFlag("jira.project", rootCmd.PersistentFlags().Lookup("jira-project"))
+	_ = viper.BindPFlag("track-unfixed", rootCmd.PersistentFlags().Lookup("track-unfixed"))
 	_ = viper.BindPFlag("ai.api-key", rootCmd.PersistentFlags().Lookup("ai-api-key"))
+	_ = viper.BindPFlag("ai.api-key-file", rootCmd.PersistentFlags().Lookup("ai-api-key-file"))
 	_ = viper.BindPFlag("ai.endpoint", rootCmd.PersistentFlags().Lookup("ai-endpoint"))
 	_ = viper.BindPFlag("ai.model", rootCmd.PersistentFlags().Lookup("ai-model"))
+	_ = viper.BindPFlag("ai.stream", rootCmd.PersistentFlags().Lookup("ai-stream"))
+	_ = viper.BindPFlag("ai.consensus-model", rootCmd.PersistentFlags().Lookup("ai-consensus-model"))
+	_ = viper.BindPFlag("ai.audit-log", rootCmd.PersistentFlags().Lookup("ai-audit-log"))
+	_ = viper.BindPFlag("ai.triage", rootCmd.PersistentFlags().Lookup("ai-triage"))
+	_ = viper.BindPFlag("ai.dry-run", rootCmd.PersistentFlags().Lookup("ai-dry-run"))
 }
 
 func initConfig() {
 	config.SetupViper()
 
+	if cfgFile == "" {
+		cfgFile = os.Getenv("AUTOBUMP_CONFIG")
+	}
 	if cfgFile != "" {
 		viper.SetConfigFile(cfgFile)
 	}
 
-	if err := viper.ReadInConfig(); err == nil {
-		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
-	}
+	// The config file in use is reported once output is configured
+	_ = viper.ReadInConfig()
 }
@@ -1,14 +1,24 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/epss"
+	"github.com/tamcore/go-autobump/internal/gomod"
+	"github.com/tamcore/go-autobump/internal/output"
+	"github.com/tamcore/go-autobump/internal/plugin"
+	"github.com/tamcore/go-autobump/internal/report"
+	"github.com/tamcore/go-autobump/internal/runner"
 	"github.com/tamcore/go-autobump/internal/scanner"
+	"github.com/tamcore/go-autobump/internal/snyk"
 	"github.com/tamcore/go-autobump/internal/trivy"
 )
 
@@ -24,12 +34,27 @@ Results are displayed in a table format by default, or as JSON with --json flag.
 }
 
 var (
-	scanOutputJSON bool
+	scanOutputJSON     bool
+	scanFailOnFindings bool
+	scanOnlyFixed      bool
+	scanOnlyUnfixed    bool
+	scanGroupBy        string
+	scanSort           string
+	scanWide           bool
 )
 
 func init() {
 	rootCmd.AddCommand(scanCmd)
 	scanCmd.Flags().BoolVar(&scanOutputJSON, "json", false, "output results as JSON")
+	scanCmd.Flags().BoolVar(&scanFailOnFindings, "fail-on-findings", false, "exit with code 2 if any vulnerabilities are found")
+	scanCmd.Flags().BoolVar(&scanOnlyFixed, "only-fixed", false, "only show vulnerabilities with a fixed version available")
+	scanCmd.Flags().BoolVar(&scanOnlyUnfixed, "only-unfixed", false, "only show vulnerabilities without a fixed version (candidates for VEX/triage)")
+	scanCmd.MarkFlagsMutuallyExclusive("only-fixed", "only-unfixed")
+	scanCmd.Flags().StringVar(&scanGroupBy, "group-by", groupByModule, "group the table by: module, package, severity")
+	scanCmd.Flags().StringVar(&scanSort, "sort", "", "sort vulnerabilities within a group by: cvss, epss, exploit, package (default: scanner order)")
+	scanCmd.Flags().BoolVar(&scanWide, "wide", false, "do not truncate package and module names in the table")
+	addTemplateFlag(scanCmd)
+	scanCmd.MarkFlagsMutuallyExclusive("json", "template")
 }
 
 func runScan(cmd *cobra.Command, args []string) error {
@@ -43,91 +68,417 @@ func runScan(cmd *cobra.Command, args []string) error {
 		cfg.Path = args[0]
 	}
 
-	// Discover all go.mod files
-	goModFiles, err := scanner.DiscoverGoModFiles(cfg.Path, cfg.Exclude...)
+	if err := validateTableOptions(scanGroupBy, scanSort); err != nil {
+		return err
+	}
+	// Sorting by exploit maturity looks it up, like --exploits
+	if scanSort == sortExploit {
+		cfg.Exploits.Enabled = true
+	}
+
+	run := startRun(cfg)
+	defer run.done()
+	run.handleInterrupts()
+
+	if err := prepareScanner(cfg); err != nil {
+		return err
+	}
+
+	known, err := loadBaseline(cfg)
 	if err != nil {
-		return fmt.Errorf("failed to discover go.mod files: %w", err)
+		return err
 	}
 
-	if len(goModFiles) == 0 {
+	// A scan has no module to finish, an interrupt stops it right away
+	endInterruptible := run.interruptible()
+	allResults, goModCount, failed, err := collectScanResults(cfg)
+	endInterruptible()
+	if err != nil {
+		return err
+	}
+	remindMitigations(cfg)
+
+	var builders []report.BuilderImage
+	if cfg.BuilderImages {
+		builders = checkBuilderImages(cfg, false)
+	}
+
+	// Export every finding: the baseline and --only-fixed/--only-unfixed
+	// only narrow what is reported, and exported findings missing from a
+	// later export are closed
+	found := allResults
+
+	// Only report vulnerabilities that are not already in the baseline
+	allResults = applyBaseline(known, cfg.Path, allResults)
+
+	switch {
+	case scanOnlyFixed:
+		allResults = filterVulnerabilities(allResults, trivy.HasFixedVersion)
+	case scanOnlyUnfixed:
+		allResults = filterVulnerabilities(allResults, func(v trivy.Vulnerability) bool { return !trivy.HasFixedVersion(v) })
+	}
+
+	if goModCount == 0 {
 		fmt.Println("No go.mod files found")
 		return nil
 	}
 
-	fmt.Fprintf(os.Stderr, "Found %d go.mod file(s)\n", len(goModFiles))
+	complete := pluginContext(cfg, plugin.HookComplete)
+	complete.Results = allResults
+	pullRequests, err := plugin.Complete(cfg.Plugins, complete)
+	if err != nil {
+		return err
+	}
+
+	// Findings of modules an incomplete scan missed would be closed as resolved
+	if reason := incompleteScan(run, failed); reason != "" && exportConfigured(cfg) {
+		output.Warnf("%s, not exporting findings", reason)
+	} else if err := exportFindings(cfg, found, nil); err != nil {
+		output.Warnf("failed to export findings: %v", err)
+	}
 
-	var allResults []trivy.ScanResult
+	// A stopped scan would report incomplete results as those of the commit
+	stopped := run.stopReason()
+	if stopped != "" {
+		output.Warnf("scan %s, not commenting the summary or writing the report file", stopped)
+	}
 
-	// Prepare trivy scan options
-	scanOpts := trivy.ScanOptions{SkipDBUpdate: cfg.SkipTrivyDBUpdate}
+	r := report.New(cfg.Path, cfg.CVSSThreshold, allResults)
+	r.PullRequests = pullRequests
+	r.BuilderImages = builders
+	if stopped == "" {
+		if err := commentSummary(cfg, r); err != nil {
+			output.Warnf("failed to comment the summary on the pull request: %v", err)
+		}
+	}
 
-	for _, goModFile := range goModFiles {
-		fmt.Fprintf(os.Stderr, "Scanning %s...\n", goModFile)
+	// Findings of modules an incomplete scan missed must not pass the check
+	if reason := incompleteScan(run, failed); reason != "" {
+		if err := publishIncompleteStatus(cfg, reason, ""); err != nil {
+			output.Warnf("failed to publish the commit status: %v", err)
+		}
+	} else if err := publishStatus(cfg, allResults, ""); err != nil {
+		output.Warnf("failed to publish the commit status: %v", err)
+	}
 
-		result, err := trivy.Scan(goModFile, scanOpts)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to scan %s: %v\n", goModFile, err)
-			continue
+	// EPSS scores are not part of the Trivy report and are only fetched on request
+	if scanSort == sortEPSS && len(allResults) > 0 {
+		if err := epss.NewClient().Enrich(context.Background(), allResults); err != nil {
+			output.Warnf("failed to fetch EPSS scores: %v", err)
 		}
+	}
 
-		// Filter by CVSS threshold
-		filtered := trivy.FilterByCVSS(result, cfg.CVSSThreshold)
-		if len(filtered.Vulnerabilities) > 0 {
-			allResults = append(allResults, filtered)
+	if stopped == "" {
+		if err := saveReport(cfg, r); err != nil {
+			output.Warnf("failed to write the report file: %v", err)
 		}
 	}
 
-	if len(allResults) == 0 {
+	if outputTemplate != "" {
+		if err := r.WriteTemplate(os.Stdout, outputTemplate); err != nil {
+			return err
+		}
+		return scanError(cfg, run, allResults)
+	}
+
+	if len(allResults) == 0 && !scanOutputJSON {
 		fmt.Println("No vulnerabilities found above CVSS threshold", cfg.CVSSThreshold)
-		return nil
+		return scanError(cfg, run, allResults)
 	}
 
 	if scanOutputJSON {
+		// Always emit a JSON array so the output can be fed to "diff"
+		if allResults == nil {
+			allResults = []trivy.ScanResult{}
+		}
 		enc := json.NewEncoder(os.Stdout)
 		enc.SetIndent("", "  ")
-		return enc.Encode(allResults)
+		if err := enc.Encode(allResults); err != nil {
+			return err
+		}
+		return scanError(cfg, run, allResults)
 	}
 
 	// Print table format
-	printScanResults(allResults, cfg.CVSSThreshold)
-	return nil
+	printScanResults(allResults, cfg.CVSSThreshold, tableOptions{
+		GroupBy:  scanGroupBy,
+		Sort:     scanSort,
+		Wide:     scanWide,
+		Exploits: cfg.Exploits.Enabled,
+	})
+
+	return scanError(cfg, run, allResults)
 }
 
-func printScanResults(results []trivy.ScanResult, threshold float64) {
-	fmt.Printf("\nVulnerabilities found (CVSS >= %.1f):\n", threshold)
-	fmt.Println(strings.Repeat("=", 100))
+// scanError returns the error a scan exits with: a scan stopped by its
+// timeout or an interrupt is incomplete, otherwise findingsError applies
+func scanError(cfg *config.Config, run *runControl, results []trivy.ScanResult) error {
+	if reason := run.stopReason(); reason != "" {
+		return withExitCode(ExitIncomplete, fmt.Errorf("scan %s, results are incomplete", reason))
+	}
+	return findingsError(cfg, results)
+}
+
+// incompleteScan returns why the results of a scan are incomplete, the run
+// having stopped or modules having failed to scan, or "" if they are complete
+func incompleteScan(run *runControl, failed []string) string {
+	if reason := run.stopReason(); reason != "" {
+		return "scan " + reason
+	}
+	if len(failed) > 0 {
+		return "failed to scan " + strings.Join(failed, ", ")
+	}
+	return ""
+}
+
+// findingsError returns the error a scan with findings exits with: findings
+// missing from the baseline always fail, others only with --fail-on-findings
+func findingsError(cfg *config.Config, results []trivy.ScanResult) error {
+	if len(results) == 0 {
+		return nil
+	}
+	if cfg.Baseline != "" {
+		return withExitCode(ExitFindings, fmt.Errorf("vulnerabilities found that are not in baseline %s", cfg.Baseline))
+	}
+	if scanFailOnFindings {
+		return withExitCode(ExitFindings, fmt.Errorf("vulnerabilities found in %d module(s)", len(results)))
+	}
+	return nil
+}
 
-	totalVulns := 0
+// filterVulnerabilities keeps the vulnerabilities for which keep returns true,
+// omitting modules left without findings
+func filterVulnerabilities(results []trivy.ScanResult, keep func(trivy.Vulnerability) bool) []trivy.ScanResult {
+	var filtered []trivy.ScanResult
 	for _, result := range results {
-		fmt.Printf("\n📁 %s\n", result.Target)
-		fmt.Println(strings.Repeat("-", 100))
-		fmt.Printf("%-20s %-40s %-12s %-12s %-8s %s\n",
-			"CVE", "Package", "Installed", "Fixed", "CVSS", "Direct")
-		fmt.Println(strings.Repeat("-", 100))
-
-		for _, vuln := range result.Vulnerabilities {
-			direct := "yes"
-			if vuln.Indirect {
-				direct = "no"
-			}
-			fixed := vuln.FixedVersion
-			if fixed == "" {
-				fixed = "(none)"
-			}
-			fmt.Printf("%-20s %-40s %-12s %-12s %-8.1f %s\n",
-				truncate(vuln.VulnerabilityID, 20),
-				truncate(vuln.PkgName, 40),
-				truncate(vuln.InstalledVersion, 12),
-				truncate(fixed, 12),
-				vuln.CVSSScore,
-				direct,
-			)
-			totalVulns++
+		if r := trivy.Filter(result, keep); len(r.Vulnerabilities) > 0 {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered
+}
+
+// prepareScanner sets up the source of the vulnerabilities of cfg.Path: the
+// imported Snyk results, if any, or Trivy, whose version it checks
+func prepareScanner(cfg *config.Config) error {
+	if cfg.FromSnyk == "" {
+		return checkTrivyVersion(cfg)
+	}
+
+	imported, err := snyk.Load(cfg.FromSnyk, cfg.Path)
+	if err != nil {
+		return err
+	}
+	targets := imported.Targets()
+	if len(targets) == 0 {
+		output.Warnf("%s contains no Go modules project", cfg.FromSnyk)
+	}
+	root := cfg.Path
+	if info, err := os.Stat(root); err == nil && !info.IsDir() {
+		root = filepath.Dir(root)
+	}
+	for _, target := range targets {
+		if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(target))); err != nil {
+			output.Warnf("%s lists %s, which is not below %s; was Snyk run from another directory?", cfg.FromSnyk, target, cfg.Path)
+		}
+	}
+	output.Infof("Using the Snyk results %s instead of Trivy", cfg.FromSnyk)
+	trivy.SetSource(imported)
+	return nil
+}
+
+// checkTrivyVersion verifies the installed Trivy meets the minimum supported
+// version. Depending on the trivy-version-check setting, an old or missing
+// Trivy is reported as a warning ("warn"), refused ("error"), or ignored ("off").
+func checkTrivyVersion(cfg *config.Config) error {
+	if cfg.TrivyVersionCheck == config.TrivyVersionCheckOff {
+		return nil
+	}
+
+	_, err := trivy.CheckVersion()
+	if err == nil {
+		return nil
+	}
+
+	if cfg.TrivyVersionCheck == config.TrivyVersionCheckError {
+		return err
+	}
+	output.Warnf("%v", err)
+	return nil
+}
+
+// collectScanResults discovers and scans all modules under cfg.Path and returns
+// the results filtered by the CVSS threshold, omitting modules without findings.
+// It also returns the number of discovered go.mod files and the go.mod files
+// that could not be scanned, whose findings are missing from the results.
+func collectScanResults(cfg *config.Config) ([]trivy.ScanResult, int, []string, error) {
+	// Discover all go.mod files
+	goModFiles, err := scanner.DiscoverGoModFiles(cfg.Path, cfg.Exclude...)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("failed to discover go.mod files: %w", err)
+	}
+
+	if len(goModFiles) == 0 {
+		return nil, 0, nil, nil
+	}
+
+	output.Infof("Found %d go.mod file(s)", len(goModFiles))
+
+	discovered := len(goModFiles)
+	goModFiles, _ = skipEmptyModules(cfg, goModFiles)
+	if len(goModFiles) == 0 {
+		return nil, discovered, nil, nil
+	}
+
+	// Prepare trivy scan options
+	scanOpts := trivy.NewScanOptions(cfg)
+
+	scanResults := scanModules(cfg.Path, goModFiles, scanOpts)
+	if err := runScanPlugins(cfg, goModFiles, scanResults); err != nil {
+		return nil, 0, nil, err
+	}
+	resolveAliases(cfg, scanResults)
+	annotateExploits(cfg, scanResults)
+
+	var allResults []trivy.ScanResult
+	var failed []string
+	for _, goModFile := range goModFiles {
+		result, ok := scanResults[goModFile]
+		if !ok {
+			failed = append(failed, goModFile)
+			continue
+		}
+
+		// Filter by CVSS threshold and advisory age
+		filtered := filterByAge(cfg, trivy.FilterByThresholds(result, cvssThresholds(cfg)))
+		filtered = filterDevDependencies(cfg, goModFile, filtered)
+		filtered = annotateImpact(cfg, goModFile, filtered)
+		if len(filtered.Vulnerabilities) > 0 {
+			allResults = append(allResults, filtered)
+		}
+	}
+
+	return allResults, discovered, failed, nil
+}
+
+// skipEmptyModules drops the modules without packages, such as placeholder
+// modules and tooling stubs, unless cfg.SkipEmptyModules is disabled
+func skipEmptyModules(cfg *config.Config, goModFiles []string) (modules, empty []string) {
+	if !cfg.SkipEmptyModules {
+		return goModFiles, nil
+	}
+
+	modules, empty = scanner.SplitEmpty(goModFiles)
+	if len(empty) > 0 {
+		output.Infof("Skipping %d module(s) without packages: %s", len(empty), strings.Join(empty, ", "))
+	}
+	return modules, empty
+}
+
+// cvssThresholds converts the configured CVSS threshold and its overrides
+func cvssThresholds(cfg *config.Config) trivy.Thresholds {
+	t := trivy.Thresholds{Default: cfg.CVSSThreshold}
+	for _, o := range cfg.CVSSOverrides {
+		t.Overrides = append(t.Overrides, trivy.ThresholdOverride{Patterns: o.Packages, Threshold: o.Threshold})
+	}
+	return t
+}
+
+// filterDevDependencies applies the dev-dependencies policy to the
+// vulnerabilities of modules only reachable from tests and tools of the
+// module of goModFile. If that cannot be determined, result is kept as is.
+func filterDevDependencies(cfg *config.Config, goModFile string, result trivy.ScanResult) trivy.ScanResult {
+	policy := cfg.DevDependencies
+	if !policy.Skip && policy.Threshold <= 0 {
+		return result
+	}
+
+	production, err := gomod.ProductionModules(gomod.GetModuleDir(goModFile))
+	if err != nil {
+		output.Warnf("cannot tell test-only dependencies of %s apart: %v", goModFile, err)
+		return result
+	}
+
+	filtered := trivy.Filter(result, func(vuln trivy.Vulnerability) bool {
+		if production[vuln.PkgName] {
+			return true
+		}
+		return !policy.Skip && vuln.CVSSScore >= policy.Threshold
+	})
+	if dropped := len(result.Vulnerabilities) - len(filtered.Vulnerabilities); dropped > 0 {
+		output.Infof("  Ignoring %d vulnerabilities of test-only dependencies in %s", dropped, goModFile)
+	}
+	return filtered
+}
+
+// annotateImpact records the packages of the module importing each
+// vulnerable module, with impact analysis enabled
+func annotateImpact(cfg *config.Config, goModFile string, result trivy.ScanResult) trivy.ScanResult {
+	if !cfg.ImpactAnalysis || len(result.Vulnerabilities) == 0 {
+		return result
+	}
+
+	importers, err := gomod.ImportingPackages(gomod.GetModuleDir(goModFile))
+	if err != nil {
+		output.Warnf("cannot tell which packages of %s import vulnerable modules: %v", goModFile, err)
+		return result
+	}
+
+	annotated := trivy.ScanResult{Target: result.Target}
+	for _, vuln := range result.Vulnerabilities {
+		vuln.ImportedBy = importers[vuln.PkgName]
+		annotated.Vulnerabilities = append(annotated.Vulnerabilities, vuln)
+	}
+	return annotated
+}
+
+// filterByAge drops vulnerabilities outside the configured min-age/max-age
+// window. The ages are validated when the config is loaded.
+func filterByAge(cfg *config.Config, result trivy.ScanResult) trivy.ScanResult {
+	minAge, _ := config.ParseAge(cfg.MinAge)
+	maxAge, _ := config.ParseAge(cfg.MaxAge)
+	if minAge == 0 && maxAge == 0 {
+		return result
+	}
+	return trivy.FilterByAge(result, minAge, maxAge, time.Now())
+}
+
+// scanModules scans all discovered modules with a single repo-wide Trivy run.
+// If the repo-wide scan fails, it falls back to scanning each go.mod individually.
+// Modules that could not be scanned are omitted from the returned map.
+func scanModules(root string, goModFiles []string, scanOpts trivy.ScanOptions) map[string]trivy.ScanResult {
+	output.Infof("Scanning %s...", root)
+
+	progress := output.StartProgress("Scanning "+root, 0)
+	results, err := trivy.ScanRepo(root, goModFiles, scanOpts)
+	progress.Done()
+	if err == nil {
+		return results
+	}
+
+	output.Warnf("repo-wide scan failed, scanning modules individually: %v", err)
+
+	progress = output.StartProgress("Scanning modules", len(goModFiles))
+	defer progress.Done()
+
+	results = make(map[string]trivy.ScanResult, len(goModFiles))
+	for _, goModFile := range goModFiles {
+		if runner.Context().Err() != nil {
+			output.Warnf("run stopped, not scanning the remaining modules")
+			break
+		}
+		progress.Step(goModFile)
+		output.Infof("Scanning %s...", goModFile)
+
+		result, err := trivy.Scan(goModFile, scanOpts)
+		if err != nil {
+			output.Warnf("failed to scan %s: %v", goModFile, err)
+			continue
 		}
+		results[goModFile] = result
 	}
 
-	fmt.Println(strings.Repeat("=", 100))
-	fmt.Printf("Total: %d vulnerabilities in %d module(s)\n", totalVulns, len(results))
+	return results
 }
 
 func truncate(s string, maxLen int) string {
@@ -0,0 +1,260 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tamcore/go-autobump/internal/output"
+	"github.com/tamcore/go-autobump/internal/report"
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+// Table grouping modes
+const (
+	groupByModule   = "module"
+	groupByPackage  = "package"
+	groupBySeverity = "severity"
+)
+
+// Table sort orders
+const (
+	sortCVSS    = "cvss"
+	sortEPSS    = "epss"
+	sortExploit = "exploit"
+	sortPackage = "package"
+)
+
+// tableOptions controls how scan results are rendered as a table
+type tableOptions struct {
+	// GroupBy is the section each vulnerability is listed under
+	GroupBy string
+	// Sort orders vulnerabilities within a section; empty keeps scanner order
+	Sort string
+	// Wide disables truncation of package and module names
+	Wide bool
+	// Exploits adds the exploit maturity column
+	Exploits bool
+}
+
+// tableRow is a vulnerability together with the module it was found in
+type tableRow struct {
+	Module string
+	Vuln   trivy.Vulnerability
+}
+
+// validateTableOptions checks the --group-by and --sort values
+func validateTableOptions(groupBy, sortBy string) error {
+	switch groupBy {
+	case groupByModule, groupByPackage, groupBySeverity:
+	default:
+		return fmt.Errorf("invalid group-by %q (valid: module, package, severity)", groupBy)
+	}
+	switch sortBy {
+	case "", sortCVSS, sortEPSS, sortExploit, sortPackage:
+	default:
+		return fmt.Errorf("invalid sort %q (valid: cvss, epss, exploit, package)", sortBy)
+	}
+	return nil
+}
+
+func printScanResults(results []trivy.ScanResult, threshold float64, opts tableOptions) {
+	if opts.GroupBy == "" {
+		opts.GroupBy = groupByModule
+	}
+
+	var rows []tableRow
+	for _, result := range results {
+		for _, vuln := range result.Vulnerabilities {
+			rows = append(rows, tableRow{Module: result.Target, Vuln: vuln})
+		}
+	}
+
+	pkgWidth, moduleWidth := 40, 30
+	if opts.Wide {
+		pkgWidth, moduleWidth = len("Package"), len("Module")
+		for _, row := range rows {
+			pkgWidth = max(pkgWidth, len(row.Vuln.PkgName))
+			moduleWidth = max(moduleWidth, len(row.Module))
+		}
+	}
+
+	showEPSS := opts.Sort == sortEPSS
+	showModule := opts.GroupBy != groupByModule
+
+	header := fmt.Sprintf("%-20s %-*s %-12s %-12s %-8s", "CVE", pkgWidth, "Package", "Installed", "Fixed", "CVSS")
+	if showEPSS {
+		header += fmt.Sprintf(" %-8s", "EPSS")
+	}
+	if opts.Exploits {
+		header += fmt.Sprintf(" %-10s", "Exploit")
+	}
+	header += fmt.Sprintf(" %-10s %-10s %-6s", "Published", "CWE", "Direct")
+	if showModule {
+		header += " Module"
+	}
+	width := max(100, len(header))
+
+	fmt.Printf("\nVulnerabilities found (CVSS >= %.1f):\n", threshold)
+	fmt.Println(strings.Repeat("=", width))
+
+	for _, group := range groupRows(rows, opts.GroupBy) {
+		sortRows(group.Rows, opts.Sort)
+
+		fmt.Printf("\n%s %s\n", groupIcon(opts.GroupBy), group.Name)
+		fmt.Println(strings.Repeat("-", width))
+		fmt.Println(strings.TrimRight(header, " "))
+		fmt.Println(strings.Repeat("-", width))
+
+		for _, row := range group.Rows {
+			vuln := row.Vuln
+			direct := "yes"
+			if vuln.Indirect {
+				direct = "no"
+			}
+			fixed := vuln.FixedVersion
+			if fixed == "" {
+				fixed = "(none)"
+			}
+
+			line := fmt.Sprintf("%-20s %-*s %-12s %-12s %-8.1f",
+				truncate(vuln.VulnerabilityID, 20),
+				pkgWidth, truncate(vuln.PkgName, pkgWidth),
+				truncate(vuln.InstalledVersion, 12),
+				truncate(fixed, 12),
+				vuln.CVSSScore,
+			)
+			if showEPSS {
+				line += fmt.Sprintf(" %-8.4f", vuln.EPSS)
+			}
+			if opts.Exploits {
+				exploit := vuln.Exploit
+				if exploit == "" {
+					exploit = "-"
+				}
+				line += fmt.Sprintf(" %-10s", exploit)
+			}
+			line += fmt.Sprintf(" %-10s %-10s %-6s", publishedDate(vuln), truncate(strings.Join(vuln.CweIDs, ","), 10), direct)
+			if showModule {
+				line += " " + truncate(row.Module, moduleWidth)
+			}
+			fmt.Println(strings.TrimRight(line, " "))
+			if advisory := advisoryLine(vuln); advisory != "" {
+				fmt.Println(advisory)
+			}
+		}
+	}
+
+	fmt.Println(strings.Repeat("=", width))
+	fmt.Printf("Total: %d vulnerabilities in %d module(s)\n", len(rows), len(results))
+}
+
+// publishedDate returns the day vuln was published, or "-" if unknown
+func publishedDate(vuln trivy.Vulnerability) string {
+	if vuln.PublishedDate == nil {
+		return "-"
+	}
+	return vuln.PublishedDate.Format(time.DateOnly)
+}
+
+// advisoryLine returns the indented link to the advisory of vuln, with the
+// number of further references, or "" if it has none
+func advisoryLine(vuln trivy.Vulnerability) string {
+	links := vuln.Links()
+	switch len(links) {
+	case 0:
+		return ""
+	case 1:
+		return "  " + links[0]
+	default:
+		return fmt.Sprintf("  %s (+%d more)", links[0], len(links)-1)
+	}
+}
+
+// rowGroup is a titled section of the table
+type rowGroup struct {
+	Name string
+	Rows []tableRow
+}
+
+// groupRows splits rows into sections. Modules keep their discovery order,
+// packages are listed alphabetically and severities from critical to unknown.
+func groupRows(rows []tableRow, groupBy string) []rowGroup {
+	key := func(row tableRow) string { return row.Module }
+	switch groupBy {
+	case groupByPackage:
+		key = func(row tableRow) string { return row.Vuln.PkgName }
+	case groupBySeverity:
+		key = func(row tableRow) string { return report.Severity(row.Vuln.Severity) }
+	}
+
+	var groups []rowGroup
+	index := make(map[string]int)
+	for _, row := range rows {
+		k := key(row)
+		i, ok := index[k]
+		if !ok {
+			i = len(groups)
+			index[k] = i
+			groups = append(groups, rowGroup{Name: k})
+		}
+		groups[i].Rows = append(groups[i].Rows, row)
+	}
+
+	switch groupBy {
+	case groupByPackage:
+		sort.SliceStable(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+	case groupBySeverity:
+		sort.SliceStable(groups, func(i, j int) bool {
+			return severityRank(groups[i].Name) < severityRank(groups[j].Name)
+		})
+	}
+	return groups
+}
+
+// sortRows orders rows in place; scores sort descending, packages ascending
+func sortRows(rows []tableRow, sortBy string) {
+	switch sortBy {
+	case sortCVSS:
+		sort.SliceStable(rows, func(i, j int) bool { return rows[i].Vuln.CVSSScore > rows[j].Vuln.CVSSScore })
+	case sortEPSS:
+		sort.SliceStable(rows, func(i, j int) bool { return rows[i].Vuln.EPSS > rows[j].Vuln.EPSS })
+	case sortExploit:
+		sort.SliceStable(rows, func(i, j int) bool {
+			ri, rj := trivy.ExploitRank(rows[i].Vuln.Exploit), trivy.ExploitRank(rows[j].Vuln.Exploit)
+			if ri != rj {
+				return ri > rj
+			}
+			return rows[i].Vuln.CVSSScore > rows[j].Vuln.CVSSScore
+		})
+	case sortPackage:
+		sort.SliceStable(rows, func(i, j int) bool {
+			if rows[i].Vuln.PkgName != rows[j].Vuln.PkgName {
+				return rows[i].Vuln.PkgName < rows[j].Vuln.PkgName
+			}
+			return rows[i].Vuln.VulnerabilityID < rows[j].Vuln.VulnerabilityID
+		})
+	}
+}
+
+// groupIcon returns the marker printed before a section title
+func groupIcon(groupBy string) string {
+	switch groupBy {
+	case groupByPackage:
+		return output.Symbol(output.IconPackage)
+	case groupBySeverity:
+		return output.Symbol(output.IconWarning)
+	}
+	return output.Symbol(output.IconModule)
+}
+
+// severityRank returns the position of severity in report.Severities
+func severityRank(severity string) int {
+	for i, s := range report.Severities {
+		if s == severity {
+			return i
+		}
+	}
+	return len(report.Severities)
+}
@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/gomod"
+	"github.com/tamcore/go-autobump/internal/gorelease"
+	"github.com/tamcore/go-autobump/internal/output"
+	"github.com/tamcore/go-autobump/internal/scanner"
+)
+
+var toolchainCmd = &cobra.Command{
+	Use:   "toolchain [path]",
+	Short: "Bump Go versions affected by Go security releases",
+	Long: `Toolchain checks the Go versions the repository declares against the
+vulnerabilities of the Go standard library and go command in the Go
+vulnerability database, and bumps the affected ones to the latest patch
+release of their minor line from the Go release feed, or of the next minor
+line if the latest patch release of theirs is still affected.
+
+The declared versions are the toolchain directive of every go.mod, or its go
+directive without one, and with go-releases.version-files the versions
+pinned in .go-version and .tool-versions files, setup-go steps of GitHub
+Actions workflows ("go-version: 1.22.3") and golang images of Dockerfiles
+and .gitlab-ci.yml ("golang:1.22.3-alpine"). Versions without a patch
+release, e.g. "1.22.x", are not pinned and not checked.
+
+go.mod files get a toolchain directive rather than a newer go directive,
+which would raise the Go version required of the module's users.
+
+--check reports the affected versions without bumping them and exits with
+code 2 if there are any; --dry-run shows the bumps.`,
+	Args:         cobra.MaximumNArgs(1),
+	RunE:         runToolchain,
+	SilenceUsage: true,
+}
+
+var (
+	toolchainCheck bool
+	toolchainJSON  bool
+)
+
+func init() {
+	rootCmd.AddCommand(toolchainCmd)
+	toolchainCmd.Flags().BoolVar(&toolchainCheck, "check", false, "only report affected Go versions, exiting with code 2 if there are any")
+	toolchainCmd.Flags().BoolVar(&toolchainJSON, "json", false, "output the declared Go versions as JSON")
+}
+
+// goVersionResult is a declared Go version, with the vulnerabilities
+// affecting it and the release it is bumped to
+type goVersionResult struct {
+	gorelease.Declaration
+	Vulnerabilities []gorelease.Vulnerability `json:"vulnerabilities,omitempty"`
+	Target          string                    `json:"target,omitempty"`
+	Bumped          bool                      `json:"bumped,omitempty"`
+	Error           string                    `json:"error,omitempty"`
+}
+
+func runToolchain(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Override path if provided as argument
+	if len(args) > 0 {
+		cfg.Path = args[0]
+	}
+	root, err := filepath.Abs(cfg.Path)
+	if err != nil {
+		return err
+	}
+
+	decls, err := goVersionDeclarations(cfg, root)
+	if err != nil {
+		return err
+	}
+	if len(decls) == 0 {
+		fmt.Println("No Go versions declared")
+		return nil
+	}
+
+	versions := make([]string, 0, len(decls))
+	for _, decl := range decls {
+		versions = append(versions, decl.Version)
+	}
+	output.Infof("Checking %d declared Go version(s) against Go security releases...", len(decls))
+	checker, err := goReleaseClient(cfg).Load(context.Background(), versions)
+	if err != nil {
+		return err
+	}
+
+	results := make([]goVersionResult, 0, len(decls))
+	affected, failed := 0, 0
+	for _, decl := range decls {
+		result := goVersionResult{Declaration: decl, Vulnerabilities: checker.Affecting(decl.Version)}
+		if len(result.Vulnerabilities) > 0 {
+			affected++
+			result.Target = checker.Target(decl.Version)
+			if result.Target == "" {
+				result.Error = "no Go release fixes its vulnerabilities"
+			} else if !toolchainCheck && !cfg.DryRun {
+				if err := bumpGoVersion(root, decl, result.Target); err != nil {
+					result.Error = err.Error()
+				} else {
+					result.Bumped = true
+				}
+			}
+			if result.Error != "" {
+				failed++
+			}
+		}
+		results = append(results, result)
+	}
+
+	if toolchainJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			return err
+		}
+	} else {
+		printGoVersions(results, cfg.DryRun || toolchainCheck)
+	}
+
+	if toolchainCheck && affected > 0 {
+		return withExitCode(ExitFindings, fmt.Errorf("%d declared Go version(s) affected by Go vulnerabilities", affected))
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to bump %d declared Go version(s)", failed)
+	}
+	return nil
+}
+
+// goReleaseClient returns a client of the configured Go release feed and
+// vulnerability database
+func goReleaseClient(cfg *config.Config) *gorelease.Client {
+	client := gorelease.NewClient()
+	client.ReleaseFeed = cfg.GoReleases.ReleaseFeed
+	client.VulnDB = cfg.GoReleases.VulnDB
+	return client
+}
+
+// goVersionDeclarations returns the Go versions declared below root: by each
+// go.mod and, if configured, by other version files
+func goVersionDeclarations(cfg *config.Config, root string) ([]gorelease.Declaration, error) {
+	goModFiles, err := scanner.DiscoverGoModFiles(root, cfg.Exclude...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover go.mod files: %w", err)
+	}
+
+	var decls []gorelease.Declaration
+	for _, goModFile := range goModFiles {
+		parser, err := gomod.NewParser(goModFile)
+		if err != nil {
+			output.Warnf("failed to parse %s: %v", goModFile, err)
+			continue
+		}
+		version := parser.Toolchain()
+		if version == "" {
+			continue
+		}
+		rel, err := filepath.Rel(root, goModFile)
+		if err != nil {
+			rel = goModFile
+		}
+		decl := gorelease.Declaration{File: rel, Kind: gorelease.KindGoMod, Version: version}
+		if t := parser.ModFile.Toolchain; t != nil && t.Syntax != nil {
+			decl.Line = t.Syntax.Start.Line
+		} else if g := parser.ModFile.Go; g != nil && g.Syntax != nil {
+			decl.Line = g.Syntax.Start.Line
+		}
+		decls = append(decls, decl)
+	}
+
+	if cfg.GoReleases.VersionFiles {
+		files, err := gorelease.FindDeclarations(root)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find Go version files: %w", err)
+		}
+		decls = append(decls, files...)
+	}
+	return decls, nil
+}
+
+// bumpGoVersion sets the Go version of decl to version
+func bumpGoVersion(root string, decl gorelease.Declaration, version string) error {
+	if decl.Kind == gorelease.KindGoMod {
+		return gomod.NewSession(filepath.Join(root, decl.File)).SetToolchain(version)
+	}
+	return gorelease.Rewrite(root, decl, version)
+}
+
+// printGoVersions prints each declared Go version and what became of it
+func printGoVersions(results []goVersionResult, preview bool) {
+	for _, r := range results {
+		location := fmt.Sprintf("%s:%d", r.File, r.Line)
+		if len(r.Vulnerabilities) == 0 {
+			output.Status(output.IconSuccess, "%s: Go %s is not affected", location, r.Version)
+			continue
+		}
+
+		ids := make([]string, 0, len(r.Vulnerabilities))
+		for _, v := range r.Vulnerabilities {
+			id := v.ID
+			if len(v.Aliases) > 0 {
+				id += " (" + strings.Join(v.Aliases, ", ") + ")"
+			}
+			ids = append(ids, id)
+		}
+		output.Status(output.IconWarning, "%s: Go %s is affected by %d vulnerabilities", location, r.Version, len(ids))
+		for _, id := range ids {
+			fmt.Printf("    %s\n", id)
+		}
+
+		switch {
+		case r.Error != "":
+			output.Status(output.IconFailure, "  Failed to bump Go %s: %s", r.Version, r.Error)
+		case r.Bumped:
+			output.Status(output.IconSuccess, "  Bumped Go %s -> %s", r.Version, r.Target)
+		case preview:
+			output.Status(output.IconDryRun, "  Would bump Go %s -> %s", r.Version, r.Target)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/forge"
+	"github.com/tamcore/go-autobump/internal/gomod"
+	"github.com/tamcore/go-autobump/internal/output"
+	"github.com/tamcore/go-autobump/internal/trivy"
+	"github.com/tamcore/go-autobump/internal/vex"
+)
+
+// unfixedKeyPrefix marks tracking issues of unfixed vulnerabilities
+const unfixedKeyPrefix = "unfixed:"
+
+// trackUnfixed opens one forge issue per unfixed vulnerability with its VEX
+// statement and dependency chain, and closes the tracking issues of
+// vulnerabilities that are no longer reported as unfixed. Only the issues of
+// scanned, the go.mod files scanned in this run, are closed, so a failed scan
+// does not close those of its module. statements are the VEX statements of
+// this run; missing ones are generated.
+func trackUnfixed(cfg *config.Config, scanned []string, unfixed []trivy.ScanResult, statements []vex.Statement) error {
+	provider, err := newForge(cfg)
+	if err != nil {
+		return err
+	}
+	if provider == nil {
+		return fmt.Errorf("--track-unfixed requires a forge (set forge.provider)")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), forgeTimeout)
+	defer cancel()
+
+	issues, err := provider.Issues(ctx, forge.Label)
+	if err != nil {
+		return err
+	}
+	open := make(map[string]forge.Issue)
+	for _, issue := range issues {
+		if key, ok := forge.ParseMarker(issue.Body); ok && strings.HasPrefix(key, unfixedKeyPrefix) {
+			open[key] = issue
+		}
+	}
+
+	if statements == nil {
+		var vulns []trivy.Vulnerability
+		for _, result := range unfixed {
+			vulns = append(vulns, result.Vulnerabilities...)
+		}
+		statements = vex.Statements(vulns, cfg)
+	}
+
+	reported := make(map[string]bool)
+	opened := 0
+	for _, result := range unfixed {
+		module := relativeModulePath(cfg.Path, result.Target)
+		for _, vuln := range result.Vulnerabilities {
+			key := unfixedKey(module, vuln)
+			if reported[key] {
+				continue
+			}
+			reported[key] = true
+			if _, ok := open[key]; ok {
+				continue
+			}
+
+			title := fmt.Sprintf("Unfixed vulnerability %s in %s (%s)", vuln.VulnerabilityID, vuln.PkgName, module)
+			body := unfixedIssueBody(result.Target, module, vuln, vex.FindStatement(statements, vuln)) + "\n" + forge.Marker(key) + "\n"
+			issue, err := provider.CreateIssue(ctx, title, body, []string{forge.Label})
+			if err != nil {
+				return err
+			}
+			opened++
+			output.Status(output.IconDocument, "  Opened tracking issue %s for %s", issue.URL, vuln.VulnerabilityID)
+		}
+	}
+
+	closed := 0
+	for key, issue := range open {
+		if reported[key] || !slices.ContainsFunc(scanned, func(goModFile string) bool {
+			return strings.HasPrefix(key, unfixedKeyPrefix+relativeModulePath(cfg.Path, goModFile)+":")
+		}) {
+			continue
+		}
+		comment := "go-autobump no longer reports this vulnerability as unfixed: a fix was applied, " +
+			"the dependency was removed, or a fixed version is now available."
+		if err := provider.CloseIssue(ctx, issue.Number, comment); err != nil {
+			return err
+		}
+		closed++
+		output.Status(output.IconSuccess, "  Closed tracking issue %s", issue.URL)
+	}
+
+	output.Infof("Tracking issues: %d opened, %d closed, %d still open", opened, closed, len(reported)-opened)
+	return nil
+}
+
+// unfixedKey identifies the tracking issue of an unfixed vulnerability
+func unfixedKey(module string, vuln trivy.Vulnerability) string {
+	return unfixedKeyPrefix + module + ":" + vuln.VulnerabilityID + ":" + vuln.PkgName
+}
+
+// unfixedIssueBody renders the tracking issue of an unfixed vulnerability
+func unfixedIssueBody(goModFile, module string, vuln trivy.Vulnerability, stmt *vex.Statement) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**%s** in `%s@%s` (module `%s`)\n\n", vuln.VulnerabilityID, vuln.PkgName, vuln.InstalledVersion, module)
+	fmt.Fprintf(&b, "- Severity: %s (CVSS %.1f)\n", vuln.Severity, vuln.CVSSScore)
+	if vuln.Title != "" {
+		fmt.Fprintf(&b, "- Title: %s\n", vuln.Title)
+	}
+	if vuln.PrimaryURL != "" {
+		fmt.Fprintf(&b, "- Advisory: %s\n", vuln.PrimaryURL)
+	}
+	if exploit := exploitDescription(vuln.Exploit); exploit != "" {
+		fmt.Fprintf(&b, "- Exploit: %s\n", exploit)
+	}
+	if vuln.PublishedDate != nil {
+		fmt.Fprintf(&b, "- Published: %s\n", publishedDate(vuln))
+	}
+	if len(vuln.CweIDs) > 0 {
+		fmt.Fprintf(&b, "- CWE: %s\n", strings.Join(vuln.CweIDs, ", "))
+	}
+	if len(vuln.References) > 0 {
+		b.WriteString("- References:\n")
+		for _, ref := range vuln.References {
+			fmt.Fprintf(&b, "  - %s\n", ref)
+		}
+	}
+	b.WriteString("\nNo fixed version is available yet. This issue is closed automatically once go-autobump no longer reports the vulnerability as unfixed.\n")
+
+	if stmt != nil {
+		b.WriteString("\n### VEX statement\n\n")
+		fmt.Fprintf(&b, "- Status: `%s`\n", stmt.Status)
+		if stmt.Justification != "" {
+			fmt.Fprintf(&b, "- Justification: `%s`\n", stmt.Justification)
+		}
+		if stmt.ImpactStatement != "" {
+			fmt.Fprintf(&b, "- Impact: %s\n", stmt.ImpactStatement)
+		}
+	}
+
+	chain, err := gomod.ModWhy(filepath.Dir(goModFile), vuln.PkgName)
+	if err != nil {
+		chain = "Unable to determine dependency chain"
+	}
+	fmt.Fprintf(&b, "\n### Dependency chain\n\n```\n%s\n```\n", strings.TrimSpace(chain))
+	return b.String()
+}
+
+// relativeModulePath returns goModFile relative to root in slash form, so
+// tracking keys do not depend on the checkout location
+func relativeModulePath(root, goModFile string) string {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return filepath.ToSlash(goModFile)
+	}
+	absPath, err := filepath.Abs(goModFile)
+	if err != nil {
+		return filepath.ToSlash(goModFile)
+	}
+	if rel, err := filepath.Rel(absRoot, absPath); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(goModFile)
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/tamcore/go-autobump/internal/ai"
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/output"
+	"github.com/tamcore/go-autobump/internal/plugin"
+	"github.com/tamcore/go-autobump/internal/runner"
+	"github.com/tamcore/go-autobump/internal/trivy"
+	"github.com/tamcore/go-autobump/internal/updater"
+)
+
+// maxTriageOutput bounds the error output sent for triage; go command
+// errors put the cause first
+const maxTriageOutput = 8000
+
+// untriagedFailures already come with a specific hint
+var untriagedFailures = map[string]bool{
+	updater.FailureMajorBump:    true,
+	updater.FailureMajorVariant: true,
+	updater.FailurePolicy:       true,
+	updater.FailureReplaced:     true,
+	updater.FailureSuspicious:   true,
+	updater.FailureChecksum:     true,
+}
+
+// triageClient returns the AI client for failure triage, or nil if triage is
+// disabled or no API key is configured
+func triageClient(cfg *config.Config) *ai.Client {
+	if !cfg.AI.Triage {
+		return nil
+	}
+	if err := config.ResolveSecret("ai.api-key", &cfg.AI.APIKey); err != nil {
+		output.Warnf("%v, not triaging failures", err)
+		return nil
+	}
+	if cfg.AI.APIKey == "" && !cfg.AI.DryRun {
+		output.Warnf("--ai-triage requires an AI API key (--ai-api-key or AUTOBUMP_AI_API_KEY), not triaging failures")
+		return nil
+	}
+	client := ai.NewClient(cfg.AI.APIKey, cfg.AI.Endpoint, cfg.AI.Model)
+	client.Stream = cfg.AI.Stream
+	client.DryRun = cfg.AI.DryRun
+	return client
+}
+
+// triageFailure records an AI-suggested remediation of the failed update u
+func triageFailure(client *ai.Client, u *plugin.Update, vuln trivy.Vulnerability, updateErr error) {
+	if client == nil || untriagedFailures[u.Failure] {
+		return
+	}
+
+	errorOutput := updateErr.Error()
+	if len(errorOutput) > maxTriageOutput {
+		errorOutput = errorOutput[:maxTriageOutput]
+	}
+	goMod, _ := os.ReadFile(u.Module)
+
+	ctx, cancel := context.WithTimeout(runner.Context(), 60*time.Second)
+	defer cancel()
+
+	suggestion, err := client.TriageFailure(ctx, vuln.VulnerabilityID, vuln.PkgName,
+		vuln.InstalledVersion, vuln.FixedVersion, errorOutput, string(goMod))
+	if errors.Is(err, ai.ErrDryRun) {
+		return
+	}
+	if err != nil {
+		output.Warnf("AI triage of %s failed: %v", vuln.VulnerabilityID, err)
+		return
+	}
+	u.Suggestion = suggestion
+	output.Status(output.IconInfo, "    AI suggestion: %s", suggestion)
+}
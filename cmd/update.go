@@ -1,13 +1,25 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/tamcore/go-autobump/internal/botconfig"
 	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/git"
 	"github.com/tamcore/go-autobump/internal/gomod"
+	"github.com/tamcore/go-autobump/internal/output"
+	"github.com/tamcore/go-autobump/internal/plugin"
+	"github.com/tamcore/go-autobump/internal/report"
+	"github.com/tamcore/go-autobump/internal/runner"
 	"github.com/tamcore/go-autobump/internal/scanner"
+	"github.com/tamcore/go-autobump/internal/scorecard"
 	"github.com/tamcore/go-autobump/internal/trivy"
 	"github.com/tamcore/go-autobump/internal/updater"
 	"github.com/tamcore/go-autobump/internal/vex"
@@ -26,11 +38,18 @@ and updated by modifying the appropriate direct dependency.`,
 	RunE: runUpdate,
 }
 
+var updateOutputJSON bool
+
 func init() {
 	rootCmd.AddCommand(updateCmd)
+	updateCmd.Flags().BoolVar(&updateOutputJSON, "json", false, "output the results and run summary as a JSON report")
+	addTemplateFlag(updateCmd)
+	updateCmd.MarkFlagsMutuallyExclusive("json", "template")
 }
 
 func runUpdate(cmd *cobra.Command, args []string) error {
+	start := time.Now()
+
 	cfg, err := config.Get()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
@@ -41,6 +60,29 @@ func runUpdate(cmd *cobra.Command, args []string) error {
 		cfg.Path = args[0]
 	}
 
+	if !config.ValidStrategy(cfg.Strategy) {
+		return fmt.Errorf("invalid strategy %q (valid: minimal, latest, patch-only)", cfg.Strategy)
+	}
+	if !config.ValidFixVersion(cfg.FixVersion) {
+		return fmt.Errorf("invalid fix version %q (valid: fixed, latest-patch, latest)", cfg.FixVersion)
+	}
+	if !config.ValidScorecardAction(cfg.Scorecard.Action) {
+		return fmt.Errorf("invalid scorecard.action %q (valid: warn, block)", cfg.Scorecard.Action)
+	}
+	if cfg.Commit.Enabled {
+		if _, err := commitOptions(cfg); err != nil {
+			return err
+		}
+	}
+
+	run := startRun(cfg)
+	defer run.done()
+	run.handleInterrupts()
+
+	if err := prepareScanner(cfg); err != nil {
+		return err
+	}
+
 	// Discover all go.mod files
 	goModFiles, err := scanner.DiscoverGoModFiles(cfg.Path, cfg.Exclude...)
 	if err != nil {
@@ -52,102 +94,724 @@ func runUpdate(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	fmt.Fprintf(os.Stderr, "Found %d go.mod file(s)\n", len(goModFiles))
+	output.Infof("Found %d go.mod file(s)", len(goModFiles))
+
+	goModFiles, empty := skipEmptyModules(cfg, goModFiles)
+	if len(goModFiles) == 0 {
+		fmt.Println("No go.mod files with packages found")
+		return nil
+	}
+
+	// Update sibling modules before the modules depending on them, so that
+	// their updates can be propagated
+	localDeps := gomod.LocalDependencies(goModFiles)
+	goModFiles = gomod.OrderByLocalDependencies(goModFiles, localDeps)
+	changedModules := make(map[string]bool)
+
+	known, err := loadBaseline(cfg)
+	if err != nil {
+		return err
+	}
+
+	attestation, err := startAttestation(cfg, goModFiles, start)
+	if err != nil {
+		return err
+	}
+
+	policy := loadUpdatePolicy(cfg)
+	triage := triageClient(cfg)
+	summary := report.RunSummary{Modules: len(goModFiles), ModulesEmpty: len(empty)}
 
 	var unfixedVulns []trivy.Vulnerability
+	var failedModules, unscanned []string
+	var found, actedOn []trivy.ScanResult
+	var updates []plugin.Update
+	var approvals []report.Approval
+	var incidents []report.Incident
+
+	if cfg.WarmModCache {
+		warmModCache(goModFiles)
+	}
+
+	// Drop the replacements mitigating vulnerabilities upstream has fixed since
+	updates = cleanupMitigations(cfg, policy, run, &incidents)
 
 	// Prepare trivy scan options
-	scanOpts := trivy.ScanOptions{SkipDBUpdate: cfg.SkipTrivyDBUpdate}
+	scanOpts := trivy.NewScanOptions(cfg)
 
-	for _, goModFile := range goModFiles {
-		fmt.Fprintf(os.Stderr, "\n📁 Processing %s\n", goModFile)
+	// Initial scan of all modules in one pass
+	scanResults := scanModules(cfg.Path, goModFiles, scanOpts)
+	if err := runScanPlugins(cfg, goModFiles, scanResults); err != nil {
+		return err
+	}
+	resolveAliases(cfg, scanResults)
+	annotateExploits(cfg, scanResults)
 
-		// Initial scan
-		result, err := trivy.Scan(goModFile, scanOpts)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to scan %s: %v\n", goModFile, err)
+	progress := output.StartProgress("Updating modules", len(goModFiles))
+	defer progress.Done()
+
+	for i, goModFile := range goModFiles {
+		if reason := run.stopReason(); reason != "" {
+			summary.Stopped = reason
+			summary.ModulesSkipped = len(goModFiles) - i
+			output.Warnf("run %s, skipping %d remaining module(s)", reason, summary.ModulesSkipped)
+			break
+		}
+
+		progress.Step(goModFile)
+		output.Status(output.IconModule, "\nProcessing %s", goModFile)
+
+		result, ok := scanResults[goModFile]
+		if !ok {
+			output.Status(output.IconWarning, "  Not scanned, skipping")
+			unscanned = append(unscanned, goModFile)
 			continue
 		}
+		result, changedModules[goModFile] = propagateLocalUpdates(cfg, goModFile, localDeps[goModFile], changedModules, result)
+
+		// Filter by CVSS threshold and advisory age
+		filtered := filterByAge(cfg, trivy.FilterByThresholds(result, cvssThresholds(cfg)))
+		filtered = filterDevDependencies(cfg, goModFile, filtered)
+		filtered = annotateImpact(cfg, goModFile, filtered)
+		if len(filtered.Vulnerabilities) > 0 {
+			found = append(found, filtered)
+		}
+
+		// Only act on vulnerabilities that are not already in the baseline
+		if known != nil {
+			filtered = known.Filter(cfg.Path, filtered)
+		}
 
-		// Filter by CVSS threshold
-		filtered := trivy.FilterByCVSS(result, cfg.CVSSThreshold)
 		if len(filtered.Vulnerabilities) == 0 {
-			fmt.Fprintf(os.Stderr, "  ✅ No vulnerabilities above CVSS %.1f\n", cfg.CVSSThreshold)
+			output.Status(output.IconSuccess, "  No vulnerabilities above CVSS %.1f", cfg.CVSSThreshold)
 			continue
 		}
 
-		fmt.Fprintf(os.Stderr, "  Found %d vulnerabilities above CVSS %.1f\n",
+		output.Infof("  Found %d vulnerabilities above CVSS %.1f",
 			len(filtered.Vulnerabilities), cfg.CVSSThreshold)
+		actedOn = append(actedOn, filtered)
 
 		// Parse go.mod to check for existing major version modules
-		parser, parseErr := gomod.NewParser(goModFile)
-		if parseErr != nil {
-			fmt.Fprintf(os.Stderr, "  Warning: failed to parse go.mod: %v\n", parseErr)
+		sess := gomod.NewSession(goModFile)
+		sess.Policy = policy.forModule(goModFile)
+		sess.Lockstep = lockstepGroups(cfg)
+		sess.Compatibility = compatibilityRules(cfg)
+		if _, parseErr := sess.Parser(); parseErr != nil {
+			output.Status(output.IconWarning, "  Failed to parse go.mod: %v", parseErr)
+		}
+
+		// Snapshot go.mod/go.sum so a failed update can roll back the whole module
+		var snap *gomod.Snapshot
+		if cfg.Atomic && !cfg.DryRun {
+			snap, err = sess.Snapshot()
+			if err != nil {
+				output.Status(output.IconFailure, "  Failed to snapshot module, skipping: %v", err)
+				failedModules = append(failedModules, goModFile)
+				continue
+			}
 		}
+		moduleFailed := false
+		var moduleUpdates []plugin.Update
+
+		// Decide which vulnerabilities need an update
+		var pending []trivy.Vulnerability
+		for i, vuln := range filtered.Vulnerabilities {
+			progress.SetDetail(fmt.Sprintf("%s: %s (%d/%d)",
+				goModFile, vuln.VulnerabilityID, i+1, len(filtered.Vulnerabilities)))
 
-		// Process each vulnerability
-		for _, vuln := range filtered.Vulnerabilities {
 			if vuln.FixedVersion == "" {
-				fmt.Fprintf(os.Stderr, "  ⚠️  %s in %s: no fix available\n",
+				if r := mitigation(cfg, vuln); r != nil {
+					if cfg.DryRun {
+						output.Status(output.IconDryRun, "  Would replace %s with %s@%s to mitigate %s",
+							vuln.PkgName, updater.ReplacementPath(r), r.Version, vuln.VulnerabilityID)
+						summary.Planned++
+						continue
+					}
+					if u, ok := mitigate(cfg, sess, goModFile, vuln, r, run, &incidents); ok {
+						moduleUpdates = append(moduleUpdates, u)
+						continue
+					}
+				}
+				output.Status(output.IconWarning, "  %s in %s: no fix available",
 					vuln.VulnerabilityID, vuln.PkgName)
 				unfixedVulns = append(unfixedVulns, vuln)
 				continue
 			}
 
-			// Check if the fixed major version module already exists in go.mod
-			// AND the vulnerable v1 module is no longer present
-			// This handles cases where e.g. github.com/foo/bar v1.x is vulnerable,
-			// fixed in v2.x, and github.com/foo/bar/v2 is already present
-			if parser != nil {
-				if hasMajor, existingVer, vulnStillPresent := parser.HasMajorVersionModule(vuln.PkgName, vuln.FixedVersion); hasMajor && !vulnStillPresent {
-					fmt.Fprintf(os.Stderr, "  ✅ %s in %s: already using major version module at %s\n",
-						vuln.VulnerabilityID, vuln.PkgName, existingVer)
-					continue
-				}
+			if usesMajorVersionModule(sess, vuln) {
+				continue
 			}
 
 			if cfg.DryRun {
-				fmt.Fprintf(os.Stderr, "  🔍 [dry-run] Would update %s: %s -> %s\n",
+				if cfg.MajorApproval && !cfg.AllowMajor && gomod.IsMajorVersionBump(vuln.InstalledVersion, vuln.FixedVersion) {
+					approvals = append(approvals, majorApproval(goModFile, vuln, &updater.MajorBumpError{
+						Module: vuln.PkgName, From: vuln.InstalledVersion, To: vuln.FixedVersion,
+					}))
+					output.Status(output.IconDryRun, "  %s would need approval: major version bump %s -> %s",
+						vuln.VulnerabilityID, vuln.InstalledVersion, vuln.FixedVersion)
+					continue
+				}
+				if !cfg.SkipTidy && sess.Unneeded(vuln.PkgName) {
+					output.Status(output.IconDryRun, "  Would eliminate %s: no longer needed", vuln.PkgName)
+					summary.Planned++
+					continue
+				}
+				if err := sess.CheckPolicy(vuln.PkgName, vuln.FixedVersion); err != nil {
+					output.Status(output.IconWarning, "  Would not update %s: %v", vuln.PkgName, err)
+					continue
+				}
+				if err := updater.CheckAdvisoryPath(vuln, cfg); err != nil {
+					output.Status(output.IconWarning, "  Would not update %s: %v", vuln.PkgName, err)
+					continue
+				}
+				output.Status(output.IconDryRun, "  Would update %s: %s -> %s",
 					vuln.PkgName, vuln.InstalledVersion, vuln.FixedVersion)
+				if changes, err := sess.Preview(vuln.PkgName, vuln.FixedVersion); err != nil {
+					output.Status(output.IconWarning, "    Cannot preview the module versions it changes: %v", err)
+				} else {
+					printModuleChanges(changes)
+				}
+				summary.Planned++
+				continue
+			}
+
+			if u, ok := eliminate(sess, goModFile, vuln, cfg); ok {
+				moduleUpdates = append(moduleUpdates, u)
 				continue
 			}
 
-			var updateErr error
-			if vuln.Indirect {
-				updateErr = updater.UpdateIndirect(goModFile, vuln, cfg)
+			pending = append(pending, vuln)
+		}
+
+		// Apply the updates together and verify them with a single scan;
+		// only the vulnerabilities the batch did not fix are retried one by one
+		if cfg.Batch && !cfg.Worktree && len(pending) > 1 {
+			progress.SetDetail(fmt.Sprintf("%s: batch of %d updates", goModFile, len(pending)))
+
+			end := runner.Scope(cfg.UpdateTimeout)
+			remaining, err := updater.UpdateBatch(sess, pending, cfg)
+			end()
+			var mismatch *gomod.ChecksumMismatchError
+			if errors.As(err, &mismatch) {
+				output.Status(output.IconFailure, "  Batch update failed: %v", err)
+				haltOnIncident(run, &incidents, goModFile, "", err)
+				moduleFailed = true
+			} else if err != nil {
+				output.Status(output.IconWarning, "  Batch update failed, updating individually: %v", err)
 			} else {
-				updateErr = updater.UpdateDirect(goModFile, vuln, cfg)
+				for _, vuln := range pending {
+					// Match the vulnerabilities like the batch does, by ID or alias
+					retried := slices.ContainsFunc(remaining, func(v trivy.Vulnerability) bool {
+						return trivy.SameVulnerability(v, vuln)
+					})
+					if retried {
+						continue
+					}
+					moduleUpdates = append(moduleUpdates, pluginUpdate(goModFile, vuln, nil))
+					output.Status(output.IconSuccess, "  Updated %s: %s -> %s",
+						vuln.PkgName, vuln.InstalledVersion, vuln.FixedVersion)
+				}
+				pending = remaining
+			}
+		}
+
+		// Update each remaining vulnerability individually
+		for i, vuln := range pending {
+			if run.aborted() {
+				moduleFailed = true
+				break
+			}
+			progress.SetDetail(fmt.Sprintf("%s: %s (%d/%d)",
+				goModFile, vuln.VulnerabilityID, i+1, len(pending)))
+
+			// An earlier update may have switched to the fixed major version module
+			if usesMajorVersionModule(sess, vuln) {
+				continue
 			}
 
+			// or made the vulnerable module unnecessary
+			if u, ok := eliminate(sess, goModFile, vuln, cfg); ok {
+				moduleUpdates = append(moduleUpdates, u)
+				continue
+			}
+
+			before, _ := sess.Graph()
+			end := runner.Scope(cfg.UpdateTimeout)
+			updateErr := updater.Update(sess, vuln, cfg)
+			end()
+			u := pluginUpdate(goModFile, vuln, updateErr)
 			if updateErr != nil {
-				fmt.Fprintf(os.Stderr, "  ❌ Failed to update %s: %v\n",
+				if errors.Is(updateErr, updater.ErrMajorBumpRequired) {
+					summary.MajorsSkipped++
+				}
+
+				// Record the major bump for a human decision instead of failing
+				var majorErr *updater.MajorBumpError
+				if cfg.MajorApproval && errors.As(updateErr, &majorErr) {
+					approvals = append(approvals, majorApproval(goModFile, vuln, majorErr))
+					u.Failure = updater.FailureMajorBump
+					u.Hint = "recorded for approval; rerun with --allow-major once approved"
+					moduleUpdates = append(moduleUpdates, u)
+					output.Status(output.IconWarning, "  %s needs approval: major version bump of %s (%s -> %s)",
+						vuln.VulnerabilityID, majorErr.Module, majorErr.From, majorErr.To)
+					continue
+				}
+
+				failure := updater.Classify(sess, vuln, updateErr)
+				u.Failure, u.Hint = failure.Kind, failure.Hint
+
+				output.Status(output.IconFailure, "  Failed to update %s: %v",
 					vuln.PkgName, updateErr)
+				if failure.Hint != "" {
+					output.Status(output.IconInfo, "    Hint: %s", failure.Hint)
+				}
+				haltOnIncident(run, &incidents, goModFile, vuln.VulnerabilityID, updateErr)
+				triageFailure(triage, &u, vuln, updateErr)
+				moduleUpdates = append(moduleUpdates, u)
+				moduleFailed = true
+				if cfg.Atomic {
+					break
+				}
 				continue
 			}
 
-			fmt.Fprintf(os.Stderr, "  ✅ Updated %s: %s -> %s\n",
+			if after, err := sess.Graph(); before != nil && err == nil {
+				u.ModuleChanges = gomod.DiffSelected(before, after)
+			}
+			moduleUpdates = append(moduleUpdates, u)
+			output.Status(output.IconSuccess, "  Updated %s: %s -> %s",
 				vuln.PkgName, vuln.InstalledVersion, vuln.FixedVersion)
+			printModuleChanges(u.ModuleChanges)
+		}
+
+		// Roll back every change to this module if any update failed
+		if snap != nil && moduleFailed {
+			if err := sess.Restore(snap); err != nil {
+				output.Status(output.IconFailure, "  Failed to roll back %s: %v", goModFile, err)
+			} else {
+				output.Status(output.IconRollback, "  Rolled back all updates to %s", goModFile)
+				for i := range moduleUpdates {
+					if moduleUpdates[i].Error == "" {
+						moduleUpdates[i].Error = "rolled back"
+					}
+				}
+			}
+			failedModules = append(failedModules, goModFile)
+		} else if !cfg.DryRun && !run.aborted() {
+			// Verify updates
+			if err := updater.Verify(sess, cfg, cvssThresholds(cfg)); err != nil {
+				output.Status(output.IconWarning, "  Verification warning: %v", err)
+			}
+		}
+
+		for _, u := range moduleUpdates {
+			if u.Error == "" && u.Failure == "" {
+				changedModules[goModFile] = true
+			}
 		}
 
-		// Verify updates
-		if !cfg.DryRun {
-			if err := updater.Verify(goModFile, cfg); err != nil {
-				fmt.Fprintf(os.Stderr, "  ⚠️  Verification warning: %v\n", err)
+		updates = append(updates, moduleUpdates...)
+		if len(moduleUpdates) > 0 {
+			postUpdate := pluginContext(cfg, plugin.HookPostUpdate)
+			postUpdate.Module = goModFile
+			postUpdate.Results = []trivy.ScanResult{filtered}
+			postUpdate.Updates = moduleUpdates
+			if err := plugin.Dispatch(cfg.Plugins, postUpdate); err != nil {
+				return err
 			}
 		}
 	}
 
+	progress.Done()
+
+	// A run aborted within its last module did not finish it either
+	if summary.Stopped == "" && run.aborted() {
+		summary.Stopped = run.stopReason()
+	}
+
+	if cfg.AllPatch && summary.Stopped == "" {
+		bumped, err := bumpPatches(cfg, goModFiles, failedModules, policy, run, &summary, &incidents)
+		updates = append(updates, bumped...)
+		if err != nil {
+			return err
+		}
+		if run.aborted() {
+			summary.Stopped = run.stopReason()
+		}
+	}
+
+	if cfg.AlignVersions && !cfg.DryRun && summary.Stopped == "" && len(goModFiles) > 1 {
+		output.Status(output.IconModule, "\nAligning updated dependencies across modules")
+		summary.Aligned = alignVersions(cfg, goModFiles, failedModules, updates, policy, run, &incidents)
+		if run.aborted() {
+			summary.Stopped = run.stopReason()
+		}
+	}
+
+	var builders []report.BuilderImage
+	if cfg.BuilderImages && summary.Stopped == "" {
+		builders = checkBuilderImages(cfg, !cfg.DryRun)
+	}
+
+	if len(incidents) > 0 {
+		if err := notifyIncidents(cfg, incidents); err != nil {
+			output.Warnf("failed to open issues for the checksum mismatches: %v", err)
+		}
+	}
+
 	// Generate VEX for unfixed vulnerabilities
+	var statements []vex.Statement
 	if cfg.GenerateVEX && len(unfixedVulns) > 0 {
-		fmt.Fprintf(os.Stderr, "\n📝 Generating VEX document for %d unfixed vulnerabilities...\n",
-			len(unfixedVulns))
+		if statements, err = generateVEX(cfg, run, unfixedVulns); err != nil {
+			output.Warnf("%v", err)
+		}
+	}
+
+	// Issues and findings of modules a stopped run did not process would be
+	// closed as resolved
+	if summary.Stopped != "" && (cfg.TrackUnfixed || exportConfigured(cfg)) {
+		output.Warnf("run %s, not closing tracking issues or exporting findings", summary.Stopped)
+	}
+
+	if cfg.TrackUnfixed && !cfg.DryRun && summary.Stopped == "" {
+		unfixed := filterVulnerabilities(actedOn, func(v trivy.Vulnerability) bool { return !trivy.HasFixedVersion(v) })
+		var scanned []string
+		for _, goModFile := range goModFiles {
+			if _, ok := scanResults[goModFile]; ok {
+				scanned = append(scanned, goModFile)
+			}
+		}
+		if err := trackUnfixed(cfg, scanned, unfixed, statements); err != nil {
+			output.Warnf("failed to update tracking issues: %v", err)
+		}
+	}
+
+	if len(approvals) > 0 && !cfg.DryRun {
+		if err := requestMajorApprovals(cfg, approvals); err != nil {
+			output.Warnf("failed to request approval for major version bumps: %v", err)
+		}
+	}
+
+	if cfg.Jira.URL != "" && !cfg.DryRun {
+		if err := notifyJira(cfg, actedOn, approvals, statements); err != nil {
+			output.Warnf("failed to update Jira tickets: %v", err)
+		}
+	}
+
+	// Export every finding left, including those in the baseline; findings
+	// of modules that failed to scan would be closed as resolved
+	if !cfg.DryRun && summary.Stopped == "" && exportConfigured(cfg) {
+		if len(unscanned) > 0 {
+			output.Warnf("failed to scan %s, not exporting findings", strings.Join(unscanned, ", "))
+		} else if err := exportFindings(cfg, remainingResults(found, updates), statements); err != nil {
+			output.Warnf("failed to export findings: %v", err)
+		}
+	}
+
+	if cfg.RiskScore {
+		summary.Risk = riskDelta(goModFiles, scanResults, updates)
+	}
+
+	var committed string
+	if cfg.Commit.Enabled && !cfg.DryRun && summary.Stopped == "" {
+		if committed, err = commitChanges(cfg, updates, builders); err != nil {
+			output.Warnf("failed to commit the updates: %v", err)
+		}
+	}
+
+	if attestation != nil {
+		if err := attestation.write(cfg, updates, builders); err != nil {
+			output.Warnf("failed to write the attestation: %v", err)
+		}
+	}
+
+	complete := pluginContext(cfg, plugin.HookComplete)
+	complete.Results = actedOn
+	complete.Updates = updates
+	complete.Risk = summary.Risk
+	pullRequests, err := plugin.Complete(cfg.Plugins, complete)
+	if err != nil {
+		return err
+	}
+
+	r := report.New(cfg.Path, cfg.CVSSThreshold, actedOn)
+	r.PullRequests = pullRequests
+	r.BuilderImages = builders
+	r.Incidents = incidents
+	summary.Incidents = len(incidents)
+	for _, u := range updates {
+		r.Updates = append(r.Updates, report.Update(u))
+		switch {
+		case u.Error != "":
+			summary.Failed++
+		case u.Proactive:
+			summary.Bumped++
+		case u.Eliminated:
+			summary.Eliminated++
+		case u.Replacement != "":
+			summary.Mitigated++
+		default:
+			summary.Fixed++
+		}
+	}
+	// Major bumps are reported separately from other failures
+	summary.Failed -= summary.MajorsSkipped
+	summary.Unfixed = len(unfixedVulns)
+	summary.ApprovalsRequired = len(approvals)
+	r.Approvals = approvals
+	summary.Duration = time.Since(start).Round(time.Second).String()
+	r.Run = &summary
+
+	if err := commentSummary(cfg, r); err != nil {
+		output.Warnf("failed to comment the summary on the pull request: %v", err)
+	}
+
+	// The vulnerabilities left belong to the commit of the updates; without
+	// one, the scanned commit keeps the vulnerabilities found. A stopped run
+	// did not process all modules, and the vulnerabilities of modules that
+	// failed to scan are unknown.
+	if summary.Stopped == "" && len(unscanned) > 0 {
+		if err := publishIncompleteStatus(cfg, "failed to scan "+strings.Join(unscanned, ", "), committed); err != nil {
+			output.Warnf("failed to publish the commit status: %v", err)
+		}
+	} else if summary.Stopped == "" {
+		remaining := actedOn
+		if committed != "" {
+			remaining = remainingResults(actedOn, updates)
+		}
+		if err := publishStatus(cfg, remaining, committed); err != nil {
+			output.Warnf("failed to publish the commit status: %v", err)
+		}
+	}
+
+	if err := saveReport(cfg, r); err != nil {
+		output.Warnf("failed to write the report file: %v", err)
+	}
 
-		if err := vex.Generate(unfixedVulns, cfg); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to generate VEX: %v\n", err)
-		} else {
-			fmt.Fprintf(os.Stderr, "  ✅ VEX document written to %s\n", cfg.VEXOutput)
+	switch {
+	case outputTemplate != "":
+		if err := r.WriteTemplate(os.Stdout, outputTemplate); err != nil {
+			return err
 		}
+	case updateOutputJSON:
+		if err := r.Write(os.Stdout, report.FormatJSON); err != nil {
+			return err
+		}
+	default:
+		summary.Print(os.Stdout)
+	}
+
+	if len(incidents) > 0 {
+		return withExitCode(ExitError, incidentError(incidents))
+	}
+
+	if summary.Stopped != "" {
+		return withExitCode(ExitIncomplete, fmt.Errorf("run %s, %d of %d module(s) not processed",
+			summary.Stopped, summary.ModulesSkipped, len(goModFiles)))
+	}
+
+	if len(failedModules) > 0 {
+		return withExitCode(ExitFindings, fmt.Errorf("atomic update failed for %d module(s): %s",
+			len(failedModules), strings.Join(failedModules, ", ")))
 	}
 
 	return nil
 }
+
+// warmModCache downloads the dependencies of the modules up front, so that
+// the updates only fetch the new versions. A failure only means the module
+// downloads its dependencies while being updated.
+func warmModCache(goModFiles []string) {
+	progress := output.StartProgress("Downloading dependencies", len(goModFiles))
+	defer progress.Done()
+
+	for _, goModFile := range goModFiles {
+		if runner.Context().Err() != nil {
+			return
+		}
+		progress.Step(goModFile)
+		if err := gomod.Download(gomod.GetModuleDir(goModFile)); err != nil {
+			output.Warnf("failed to download the dependencies of %s: %v", goModFile, err)
+		}
+	}
+}
+
+// loadBotPolicy reads the Renovate and Dependabot rules of the repository
+// containing cfg.Path and returns them with the directory they are relative
+// to. Unreadable bot configuration is reported and ignored.
+func loadBotPolicy(cfg *config.Config) (*botconfig.Policy, string) {
+	if !cfg.RespectBotConfig {
+		return nil, ""
+	}
+
+	root := cfg.Path
+	if info, err := os.Stat(root); err == nil && !info.IsDir() {
+		root = filepath.Dir(root)
+	}
+	if repoRoot, err := git.RepoRoot(root); err == nil {
+		root = repoRoot
+	}
+
+	policy, err := botconfig.Load(root)
+	if err != nil {
+		output.Warnf("ignoring update bot configuration: %v", err)
+		return nil, ""
+	}
+	if len(policy.Files) > 0 {
+		output.Infof("Respecting update rules from %s", strings.Join(policy.Files, ", "))
+	}
+	return policy, root
+}
+
+// updatePolicy decides which versions updates may move to
+type updatePolicy struct {
+	// bot holds the rules of the repository's update bots, relative to root
+	bot  *botconfig.Policy
+	root string
+	// scorecard holds updates to projects scoring below the Scorecard floor
+	scorecard *scorecard.Gate
+}
+
+// loadUpdatePolicy loads the update bot rules of the repository and sets up
+// the Scorecard floor, as configured
+func loadUpdatePolicy(cfg *config.Config) updatePolicy {
+	var p updatePolicy
+	p.bot, p.root = loadBotPolicy(cfg)
+	if cfg.Scorecard.MinScore > 0 {
+		client := scorecard.NewClient()
+		client.Endpoint = cfg.Scorecard.Endpoint
+		p.scorecard = &scorecard.Gate{
+			Client:   client,
+			MinScore: cfg.Scorecard.MinScore,
+			Block:    cfg.Scorecard.Action == config.ScorecardActionBlock,
+		}
+	}
+	return p
+}
+
+// forModule returns the version policy of the module whose go.mod is at
+// goModFile, nil if nothing restricts its updates
+func (p updatePolicy) forModule(goModFile string) gomod.VersionPolicy {
+	var policies gomod.Policies
+	if bot := p.bot.ForModule(p.root, goModFile); bot != nil {
+		policies = append(policies, bot)
+	}
+	if p.scorecard != nil {
+		policies = append(policies, p.scorecard)
+	}
+	switch len(policies) {
+	case 0:
+		return nil
+	case 1:
+		return policies[0]
+	}
+	return policies
+}
+
+// lockstepGroups converts the configured lockstep groups
+func lockstepGroups(cfg *config.Config) []gomod.LockstepGroup {
+	groups := make([]gomod.LockstepGroup, 0, len(cfg.LockstepGroups))
+	for _, g := range cfg.LockstepGroups {
+		groups = append(groups, gomod.LockstepGroup{Name: g.Name, Patterns: g.Modules})
+	}
+	return groups
+}
+
+// compatibilityRules returns the built-in rules, unless disabled, followed
+// by the configured ones
+func compatibilityRules(cfg *config.Config) []gomod.CompatibilityRule {
+	var rules []gomod.CompatibilityRule
+	if cfg.BuiltinCompatibilityRules {
+		rules = append(rules, gomod.BuiltinCompatibilityRules...)
+	}
+	for _, r := range cfg.CompatibilityRules {
+		match := r.Match
+		if match == "" {
+			match = gomod.MatchMinor
+		}
+		rules = append(rules, gomod.CompatibilityRule{Name: r.Name, Patterns: r.Modules, Match: match})
+	}
+	return rules
+}
+
+// printModuleChanges lists the module versions an update changes, if it
+// moves more than the updated module
+func printModuleChanges(changes []gomod.VersionChange) {
+	if len(changes) <= 1 {
+		return
+	}
+	output.Infof("    Changes %d module versions:", len(changes))
+	for _, c := range changes {
+		output.Infof("      %s", c)
+	}
+}
+
+// pluginUpdate records an update attempt for plugins
+func pluginUpdate(goModFile string, vuln trivy.Vulnerability, err error) plugin.Update {
+	u := plugin.Update{
+		Module:           goModFile,
+		VulnerabilityID:  vuln.VulnerabilityID,
+		Package:          vuln.PkgName,
+		InstalledVersion: vuln.InstalledVersion,
+		FixedVersion:     vuln.FixedVersion,
+	}
+	if links := vuln.Links(); len(links) > 0 {
+		u.Advisory = links[0]
+	}
+	if err != nil {
+		u.Error = err.Error()
+	}
+	return u
+}
+
+// eliminate removes vuln's module if the module no longer needs it and
+// returns the update recording that
+func eliminate(sess *gomod.Session, goModFile string, vuln trivy.Vulnerability, cfg *config.Config) (plugin.Update, bool) {
+	removed, err := updater.Eliminate(sess, vuln, cfg)
+	if err != nil {
+		output.Status(output.IconWarning, "  Failed to remove unneeded %s, updating it instead: %v", vuln.PkgName, err)
+		return plugin.Update{}, false
+	}
+	if !removed {
+		return plugin.Update{}, false
+	}
+
+	u := pluginUpdate(goModFile, vuln, nil)
+	u.FixedVersion = ""
+	u.Eliminated = true
+	output.Status(output.IconSuccess, "  Eliminated %s: no longer needed", vuln.PkgName)
+	return u, true
+}
+
+// majorApproval records the major version bump needed to fix vuln
+func majorApproval(goModFile string, vuln trivy.Vulnerability, bump *updater.MajorBumpError) report.Approval {
+	return report.Approval{
+		Module:          goModFile,
+		VulnerabilityID: vuln.VulnerabilityID,
+		Package:         vuln.PkgName,
+		Dependency:      bump.Module,
+		From:            bump.From,
+		To:              bump.To,
+	}
+}
+
+// usesMajorVersionModule reports whether the fixed major version module of
+// vuln already exists in go.mod AND the vulnerable v1 module is no longer
+// present. This handles cases where e.g. github.com/foo/bar v1.x is
+// vulnerable, fixed in v2.x, and github.com/foo/bar/v2 is already present.
+// The session re-parses go.mod after earlier updates modified it.
+func usesMajorVersionModule(sess *gomod.Session, vuln trivy.Vulnerability) bool {
+	parser, err := sess.Parser()
+	if err != nil {
+		return false
+	}
+
+	hasMajor, existingVer, vulnStillPresent := parser.HasMajorVersionModule(vuln.PkgName, vuln.FixedVersion)
+	if !hasMajor || vulnStillPresent {
+		return false
+	}
+
+	output.Status(output.IconSuccess, "  %s in %s: already using major version module at %s",
+		vuln.VulnerabilityID, vuln.PkgName, existingVer)
+	return true
+}
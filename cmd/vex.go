@@ -0,0 +1,353 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/git"
+	"github.com/tamcore/go-autobump/internal/gomod"
+	"github.com/tamcore/go-autobump/internal/output"
+	"github.com/tamcore/go-autobump/internal/trivy"
+	"github.com/tamcore/go-autobump/internal/vex"
+)
+
+var vexCmd = &cobra.Command{
+	Use:   "vex",
+	Short: "Manage VEX documents",
+}
+
+var vexGenerateCmd = &cobra.Command{
+	Use:   "generate [path]",
+	Short: "Write a VEX document for the vulnerabilities updates cannot fix, without updating",
+	Long: `Scan and write a VEX document (--vex-output) for the vulnerabilities above the
+thresholds that updates leave unfixed, without attempting any updates, so VEX
+can be adopted independently of automated bumping: those updates ignore, being
+in the --baseline or of test-only dependencies the dev-dependencies policy
+drops, those without a fix, and those whose fix the Renovate, Dependabot or
+Scorecard policies rule out. With --dry-run, a draft is written to the
+temporary directory instead.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runVEXGenerate,
+}
+
+var vexReviewCmd = &cobra.Command{
+	Use:   "review",
+	Short: "Approve, edit or reject AI-generated VEX statements pending review",
+	Long: `With --vex-review, AI-generated VEX statements are not published directly but
+queued in --vex-review-queue, and the VEX document states their vulnerabilities
+as under investigation. review shows each pending statement and asks whether to
+approve, edit or reject it. Approved and edited statements are written to the
+VEX document (--vex-output) with the reviewer recorded; rejected ones are
+dropped. Progress is saved after each decision.`,
+	Args: cobra.NoArgs,
+	RunE: runVEXReview,
+}
+
+func init() {
+	rootCmd.AddCommand(vexCmd)
+	vexCmd.AddCommand(vexGenerateCmd)
+	vexCmd.AddCommand(vexReviewCmd)
+
+	vexReviewCmd.Flags().String("reviewer", "", "name recorded on approved statements (default: git user.name or $USER)")
+	vexReviewCmd.Flags().Bool("list", false, "only list the pending statements")
+}
+
+func runVEXGenerate(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Override path if provided as argument
+	if len(args) > 0 {
+		cfg.Path = args[0]
+	}
+
+	run := startRun(cfg)
+	defer run.done()
+	run.handleInterrupts()
+
+	if err := prepareScanner(cfg); err != nil {
+		return err
+	}
+
+	// Scan test-only dependencies too: an update ignores their
+	// vulnerabilities, so the VEX document has to cover them
+	scanCfg := *cfg
+	scanCfg.DevDependencies = config.DevDependenciesConfig{}
+	results, _, _, err := collectScanResults(&scanCfg)
+	if err != nil {
+		return err
+	}
+	vulns, err := unremediable(cfg, results)
+	if err != nil {
+		return err
+	}
+	if len(vulns) == 0 {
+		output.Status(output.IconSuccess, "No vulnerabilities updates leave unfixed above CVSS %.1f", cfg.CVSSThreshold)
+		return nil
+	}
+
+	_, err = generateVEX(cfg, run, vulns)
+	return err
+}
+
+// generateVEX writes the VEX document for the unfixed vulnerabilities, or a
+// draft in the temporary directory on dry runs, and returns its statements
+func generateVEX(cfg *config.Config, run *runControl, vulns []trivy.Vulnerability) ([]vex.Statement, error) {
+	defer run.interruptible()()
+
+	if cfg.DryRun {
+		output.Status(output.IconDryRun, "\nGenerating draft VEX document for %d unfixed vulnerabilities...", len(vulns))
+		draft, statements, err := vex.GenerateDraft(vulns, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate draft VEX: %w", err)
+		}
+		printStatements(statements)
+		output.Status(output.IconDryRun, "  Draft VEX document written to %s (%s left unchanged)", draft, cfg.VEXOutput)
+		return statements, nil
+	}
+
+	output.Status(output.IconDocument, "\nGenerating VEX document for %d unfixed vulnerabilities...", len(vulns))
+	statements, err := vex.Generate(vulns, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate VEX: %w", err)
+	}
+	output.Status(output.IconSuccess, "  VEX document written to %s", cfg.VEXOutput)
+	return statements, nil
+}
+
+// printStatements shows the status of each VEX statement of a draft
+func printStatements(statements []vex.Statement) {
+	for _, stmt := range statements {
+		status := stmt.Status
+		if stmt.Justification != "" {
+			status += " (" + stmt.Justification + ")"
+		}
+		output.Infof("    %s: %s", stmt.VulnerabilityID, status)
+		if stmt.ImpactStatement != "" {
+			output.Infof("      %s", stmt.ImpactStatement)
+		}
+	}
+}
+
+// unremediable returns the vulnerabilities of results that an update would
+// leave unfixed: those it ignores, being in the baseline or of test-only
+// dependencies the dev-dependencies policy drops, those without a fixed
+// version and those whose fix the update policies of their module rule out
+func unremediable(cfg *config.Config, results []trivy.ScanResult) ([]trivy.Vulnerability, error) {
+	known, err := loadBaseline(cfg)
+	if err != nil {
+		return nil, err
+	}
+	policy := loadUpdatePolicy(cfg)
+
+	var vulns []trivy.Vulnerability
+	for _, result := range results {
+		actedOn := filterDevDependencies(cfg, result.Target, result)
+		if known != nil {
+			actedOn = known.Filter(cfg.Path, actedOn)
+		}
+
+		sess := gomod.NewSession(result.Target)
+		sess.Policy = policy.forModule(result.Target)
+		vulns = append(vulns, vex.Unfixed(result, actedOn, func(vuln trivy.Vulnerability) error {
+			err := sess.CheckPolicy(vuln.PkgName, vuln.FixedVersion)
+			if err != nil {
+				output.Status(output.IconWarning, "  %s in %s: fix ruled out: %v", vuln.VulnerabilityID, vuln.PkgName, err)
+			}
+			return err
+		})...)
+	}
+	return vulns, nil
+}
+
+func runVEXReview(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	q, err := vex.ReadReviewQueue(cfg.VEXReviewQueue)
+	if err != nil {
+		return err
+	}
+	if len(q.Statements) == 0 {
+		output.Infof("No VEX statements pending review in %s", cfg.VEXReviewQueue)
+		return nil
+	}
+
+	out := cmd.OutOrStdout()
+	if list, _ := cmd.Flags().GetBool("list"); list {
+		for _, stmt := range q.Statements {
+			printPendingStatement(out, stmt)
+		}
+		return nil
+	}
+
+	reviewer, _ := cmd.Flags().GetString("reviewer")
+	if reviewer == "" {
+		reviewer = defaultReviewer(cfg.Path)
+	}
+	if reviewer == "" {
+		return fmt.Errorf("no reviewer name, set --reviewer")
+	}
+
+	in := bufio.NewReader(cmd.InOrStdin())
+	pending := append([]vex.Statement(nil), q.Statements...)
+	approved, rejected := 0, 0
+review:
+	for i, stmt := range pending {
+		fmt.Fprintf(out, "\n[%d/%d]\n", i+1, len(pending))
+		printPendingStatement(out, stmt)
+
+		answer, err := prompt(in, out, "[a]pprove, [e]dit, [r]eject, [s]kip, [q]uit? ")
+		if err != nil {
+			return err
+		}
+		switch strings.ToLower(answer) {
+		case "a", "approve":
+			if err := vex.Approve(cfg, q, stmt, reviewer); err != nil {
+				return err
+			}
+			approved++
+		case "e", "edit":
+			if stmt, err = editStatement(in, out, stmt); err != nil {
+				return err
+			}
+			if err := vex.Approve(cfg, q, stmt, reviewer); err != nil {
+				return err
+			}
+			approved++
+		case "r", "reject":
+			if err := vex.Reject(cfg, q, stmt.Key()); err != nil {
+				return err
+			}
+			rejected++
+		case "q", "quit":
+			break review
+		}
+	}
+
+	output.Infof("Approved %d, rejected %d, %d still pending", approved, rejected, len(q.Statements))
+	return nil
+}
+
+// defaultReviewer returns the git user name of the repository at path, or
+// the login name
+func defaultReviewer(path string) string {
+	if name := git.UserName(path); name != "" {
+		return name
+	}
+	return os.Getenv("USER")
+}
+
+// printPendingStatement shows what a pending statement claims
+func printPendingStatement(w io.Writer, stmt vex.Statement) {
+	modules := make([]string, 0, len(stmt.Products))
+	for _, module := range stmt.Modules() {
+		modules = append(modules, module.ID)
+	}
+	fmt.Fprintf(w, "%s in %s\n", stmt.VulnerabilityID, strings.Join(modules, ", "))
+	fmt.Fprintf(w, "  Status:        %s\n", stmt.Status)
+	if stmt.Justification != "" {
+		fmt.Fprintf(w, "  Justification: %s\n", stmt.Justification)
+	}
+	if stmt.ImpactStatement != "" {
+		fmt.Fprintf(w, "  Impact:        %s\n", stmt.ImpactStatement)
+	}
+}
+
+// editStatement lets the reviewer change the status, justification and
+// impact statement; an empty answer keeps the current value. A not_affected
+// statement is re-prompted until it has an OpenVEX justification or an impact
+// statement.
+func editStatement(in *bufio.Reader, out io.Writer, stmt vex.Statement) (vex.Statement, error) {
+	for {
+		status, err := prompt(in, out, fmt.Sprintf("Status [%s]: ", stmt.Status))
+		if err != nil {
+			return stmt, err
+		}
+		if status == "" {
+			break
+		}
+		if validVEXStatuses[status] {
+			stmt.Status = status
+			break
+		}
+		fmt.Fprintln(out, "Status must be one of not_affected, affected, fixed, under_investigation")
+	}
+
+	if stmt.Status != vex.StatusNotAffected {
+		stmt.Justification = ""
+	} else if stmt.Justification != "" && !validVEXJustifications[stmt.Justification] {
+		fmt.Fprintf(out, "Dropping %s, which is not an OpenVEX justification\n", stmt.Justification)
+		stmt.Justification = ""
+	}
+
+	for {
+		if stmt.Status == vex.StatusNotAffected {
+			for {
+				justification, err := prompt(in, out, fmt.Sprintf("Justification [%s]: ", stmt.Justification))
+				if err != nil {
+					return stmt, err
+				}
+				if justification == "" {
+					break
+				}
+				if validVEXJustifications[justification] {
+					stmt.Justification = justification
+					break
+				}
+				fmt.Fprintln(out, "Justification must be one of component_not_present, vulnerable_code_not_present, "+
+					"vulnerable_code_not_in_execute_path, vulnerable_code_cannot_be_controlled_by_adversary, "+
+					"inline_mitigations_already_exist")
+			}
+		}
+
+		impact, err := prompt(in, out, "Impact statement [keep]: ")
+		if err != nil {
+			return stmt, err
+		}
+		if impact != "" {
+			stmt.ImpactStatement = impact
+		}
+
+		// OpenVEX requires not_affected statements to say why
+		if stmt.Status != vex.StatusNotAffected || stmt.Justification != "" || stmt.ImpactStatement != "" {
+			return stmt, nil
+		}
+		fmt.Fprintln(out, "A not_affected statement needs a justification or an impact statement")
+	}
+}
+
+var validVEXStatuses = map[string]bool{
+	"not_affected":        true,
+	"affected":            true,
+	"fixed":               true,
+	"under_investigation": true,
+}
+
+var validVEXJustifications = map[string]bool{
+	"component_not_present":                             true,
+	"vulnerable_code_not_present":                       true,
+	"vulnerable_code_not_in_execute_path":               true,
+	"vulnerable_code_cannot_be_controlled_by_adversary": true,
+	"inline_mitigations_already_exist":                  true,
+}
+
+// prompt writes question and returns the trimmed answer. The end of input
+// without an answer is an error, so review stops instead of looping.
+func prompt(in *bufio.Reader, out io.Writer, question string) (string, error) {
+	fmt.Fprint(out, question)
+	line, err := in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", fmt.Errorf("no answer: %w", err)
+	}
+	return strings.TrimSpace(line), nil
+}
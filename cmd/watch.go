@@ -0,0 +1,247 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tamcore/go-autobump/internal/baseline"
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/output"
+	"github.com/tamcore/go-autobump/internal/plugin"
+	"github.com/tamcore/go-autobump/internal/runner"
+	"github.com/tamcore/go-autobump/internal/trivy"
+	"github.com/tamcore/go-autobump/internal/webhook"
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch [path]",
+	Short: "Rescan on a schedule and alert on new vulnerabilities",
+	Long: `Watch scans for vulnerable dependencies like scan, compares the findings to
+those of the previous scan recorded in --state-file, and alerts when new
+vulnerabilities appear: they are printed, posted to --webhook-url and passed
+to drift plugins. It never updates anything.
+
+The first scan only records the findings. With --interval, watch keeps
+rescanning until interrupted; without, it scans once, e.g. from a CronJob,
+and exits with code 2 if new vulnerabilities appeared.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runWatch,
+}
+
+// maxAlertLines bounds the vulnerabilities listed in the text of an alert
+const maxAlertLines = 20
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+
+	watchCmd.Flags().Duration("interval", 0, "time between scans (0: scan once and exit)")
+	watchCmd.Flags().String("state-file", ".autobump.watch.json", "file recording the vulnerabilities of the last scan")
+	watchCmd.Flags().String("webhook-url", "", "URL to post a JSON alert to when new vulnerabilities appear, e.g. a Slack incoming webhook")
+
+	_ = viper.BindPFlag("watch.interval", watchCmd.Flags().Lookup("interval"))
+	_ = viper.BindPFlag("watch.state-file", watchCmd.Flags().Lookup("state-file"))
+	_ = viper.BindPFlag("watch.webhook-url", watchCmd.Flags().Lookup("webhook-url"))
+}
+
+// driftAlert is the JSON body posted to watch.webhook-url
+type driftAlert struct {
+	// Text summarizes the alert for chat webhooks
+	Text      string    `json:"text"`
+	Path      string    `json:"path"`
+	ScannedAt time.Time `json:"scanned_at"`
+	// Since is when the scan compared to was recorded
+	Since    string             `json:"since"`
+	New      []trivy.ScanResult `json:"new"`
+	Resolved []baseline.Entry   `json:"resolved,omitempty"`
+}
+
+func runWatch(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Override path if provided as argument
+	if len(args) > 0 {
+		cfg.Path = args[0]
+	}
+
+	if cfg.Watch.Interval < 0 {
+		return fmt.Errorf("invalid watch.interval %s", cfg.Watch.Interval)
+	}
+	if cfg.Watch.StateFile == "" {
+		return fmt.Errorf("watch requires watch.state-file")
+	}
+
+	if err := prepareScanner(cfg); err != nil {
+		return err
+	}
+
+	known, err := loadBaseline(cfg)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	for {
+		drift, err := watchScan(ctx, cfg, known)
+		if cfg.Watch.Interval == 0 {
+			if err != nil {
+				return err
+			}
+			if n := countVulnerabilities(drift); n > 0 {
+				return withExitCode(ExitFindings, fmt.Errorf("%d new vulnerabilities found", n))
+			}
+			return nil
+		}
+		if err != nil {
+			output.Warnf("%v", err)
+		}
+
+		output.Infof("Next scan at %s", time.Now().Add(cfg.Watch.Interval).Format(time.RFC3339))
+		select {
+		case <-ctx.Done():
+			output.Infof("Stopped watching %s", cfg.Path)
+			return nil
+		case <-time.After(cfg.Watch.Interval):
+		}
+	}
+}
+
+// watchScan scans cfg.Path, alerts on the vulnerabilities the previous scan
+// did not find and records the findings for the next scan. It returns the
+// new vulnerabilities. The state is only replaced once the alerts are out,
+// so that a failed alert is repeated by the next scan.
+func watchScan(ctx context.Context, cfg *config.Config, known *baseline.Baseline) ([]trivy.ScanResult, error) {
+	scanCtx, cancel := ctx, context.CancelFunc(func() {})
+	if cfg.Timeout > 0 {
+		scanCtx, cancel = context.WithTimeout(ctx, cfg.Timeout)
+	}
+	defer cancel()
+	runner.SetContext(scanCtx)
+	defer runner.SetContext(context.Background())
+
+	started := time.Now().UTC()
+	results, _, failed, err := collectScanResults(cfg)
+	if err != nil {
+		return nil, err
+	}
+	// The findings of modules that could not be scanned would count as
+	// resolved, and be alerted on again as new by the next scan
+	if len(failed) > 0 {
+		return nil, fmt.Errorf("failed to scan %s, not comparing incomplete results", strings.Join(failed, ", "))
+	}
+	// Modules a stopped scan missed would count as resolved
+	if scanCtx.Err() != nil {
+		return nil, fmt.Errorf("scan stopped, not comparing incomplete results: %w", scanCtx.Err())
+	}
+	results = applyBaseline(known, cfg.Path, results)
+	current := baseline.FromResults(cfg.Path, results)
+
+	previous, err := baseline.Load(cfg.Watch.StateFile)
+	if errors.Is(err, os.ErrNotExist) {
+		if err := current.Write(cfg.Watch.StateFile); err != nil {
+			return nil, err
+		}
+		output.Infof("Recorded %d vulnerabilities in %s; later scans alert on new ones", len(current.Vulnerabilities), cfg.Watch.StateFile)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	drift := applyBaseline(previous, cfg.Path, results)
+	var resolved []baseline.Entry
+	for _, entry := range previous.Vulnerabilities {
+		if !current.Contains(entry) {
+			resolved = append(resolved, entry)
+		}
+	}
+
+	if len(resolved) > 0 {
+		output.Status(output.IconSuccess, "%d vulnerabilities resolved since %s", len(resolved), previous.Generated)
+	}
+	if len(drift) == 0 {
+		output.Status(output.IconSuccess, "No new vulnerabilities since %s", previous.Generated)
+	} else {
+		output.Status(output.IconWarning, "%d new vulnerabilities since %s", countVulnerabilities(drift), previous.Generated)
+		printScanResults(drift, cfg.CVSSThreshold, tableOptions{GroupBy: groupByModule, Exploits: cfg.Exploits.Enabled})
+
+		alert := driftAlert{
+			Text:      driftText(cfg, drift),
+			Path:      cfg.Path,
+			ScannedAt: started,
+			Since:     previous.Generated,
+			New:       drift,
+			Resolved:  resolved,
+		}
+		if err := sendDriftAlert(ctx, cfg, alert); err != nil {
+			return drift, err
+		}
+	}
+
+	if err := current.Write(cfg.Watch.StateFile); err != nil {
+		return drift, err
+	}
+	return drift, nil
+}
+
+// sendDriftAlert posts alert to the webhook and passes the new
+// vulnerabilities to the drift plugins
+func sendDriftAlert(ctx context.Context, cfg *config.Config, alert driftAlert) error {
+	if err := config.ResolveSecret("watch.webhook-url", &cfg.Watch.WebhookURL); err != nil {
+		return err
+	}
+	if cfg.Watch.WebhookURL != "" {
+		if err := webhook.NewClient(cfg.Watch.WebhookURL).Post(ctx, alert); err != nil {
+			return fmt.Errorf("failed to send the alert: %w", err)
+		}
+		output.Status(output.IconDocument, "Alert sent to the webhook")
+	}
+
+	drift := pluginContext(cfg, plugin.HookDrift)
+	drift.Results = alert.New
+	return plugin.Dispatch(cfg.Plugins, drift)
+}
+
+// driftText summarizes new vulnerabilities for chat webhooks
+func driftText(cfg *config.Config, drift []trivy.ScanResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "go-autobump found %d new vulnerabilities in %s", countVulnerabilities(drift), cfg.Path)
+
+	listed := 0
+	for _, result := range drift {
+		module := relativeModulePath(cfg.Path, result.Target)
+		for _, vuln := range result.Vulnerabilities {
+			if listed == maxAlertLines {
+				fmt.Fprintf(&b, "\n… and %d more", countVulnerabilities(drift)-listed)
+				return b.String()
+			}
+			listed++
+			fmt.Fprintf(&b, "\n• %s (%s) in %s@%s (%s)", vuln.VulnerabilityID, vuln.Severity, vuln.PkgName, vuln.InstalledVersion, module)
+			if vuln.FixedVersion != "" {
+				fmt.Fprintf(&b, ", fixed in %s", vuln.FixedVersion)
+			}
+		}
+	}
+	return b.String()
+}
+
+// countVulnerabilities returns the number of vulnerabilities in results
+func countVulnerabilities(results []trivy.ScanResult) int {
+	n := 0
+	for _, result := range results {
+		n += len(result.Vulnerabilities)
+	}
+	return n
+}
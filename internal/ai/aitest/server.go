@@ -0,0 +1,157 @@
+// Package aitest provides an OpenAI-compatible chat completions server, so
+// that the AI flows can be exercised in tests and demos without credentials
+package aitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/tamcore/go-autobump/internal/ai"
+)
+
+// Server answers chat completion requests, streamed or not, with the
+// completion its Respond function returns, and lists a single model
+type Server struct {
+	*httptest.Server
+
+	// Respond returns the completion for the messages of a request
+	// (default: DefaultRespond)
+	Respond func(messages []ai.ChatMessage) string
+
+	mu       sync.Mutex
+	requests []ai.ChatCompletionRequest
+}
+
+// NewServer starts a server; the caller must Close it
+func NewServer() *Server {
+	s := &Server{}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// AIClient returns a client of the server
+func (s *Server) AIClient() *ai.Client {
+	return ai.NewClient("test", s.URL, "test-model")
+}
+
+// Requests returns the requests received so far
+func (s *Server) Requests() []ai.ChatCompletionRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]ai.ChatCompletionRequest(nil), s.requests...)
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/models" {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"object": "list",
+			"data":   []map[string]string{{"id": "test-model", "object": "model"}},
+		})
+		return
+	}
+	if r.URL.Path != "/chat/completions" {
+		http.NotFound(w, r)
+		return
+	}
+
+	var req ai.ChatCompletionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = fmt.Fprintf(w, `{"error":{"message":%q}}`, err.Error())
+		return
+	}
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	s.mu.Unlock()
+
+	respond := s.Respond
+	if respond == nil {
+		respond = DefaultRespond
+	}
+	content := respond(req.Messages)
+
+	if !req.Stream {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"model": req.Model,
+			"choices": []map[string]any{{
+				"message":       ai.ChatMessage{Role: "assistant", Content: content},
+				"finish_reason": "stop",
+			}},
+		})
+		return
+	}
+
+	// Stream the completion in two chunks, exercising their reassembly
+	w.Header().Set("Content-Type", "text/event-stream")
+	half := len(content) / 2
+	for _, part := range []string{content[:half], content[half:]} {
+		data, _ := json.Marshal(map[string]any{
+			"choices": []map[string]any{{"delta": map[string]string{"content": part}}},
+		})
+		_, _ = fmt.Fprintf(w, "data: %s\n\n", data)
+	}
+	_, _ = fmt.Fprint(w, "data: [DONE]\n\n")
+}
+
+// DefaultRespond returns the default completion: requests for VEX justifications get an
+// under_investigation justification per vulnerability, in the requested
+// single or batch format; others get a short canned text
+func DefaultRespond(messages []ai.ChatMessage) string {
+	var system, user string
+	for _, m := range messages {
+		switch m.Role {
+		case "system":
+			system = m.Content
+		case "user":
+			user = m.Content
+		}
+	}
+	if !strings.Contains(system, "VEX") {
+		return "This is a canned response of the test AI server."
+	}
+
+	type justification struct {
+		Vulnerability   string  `json:"vulnerability,omitempty"`
+		Package         string  `json:"package,omitempty"`
+		Status          string  `json:"status"`
+		ImpactStatement string  `json:"impact_statement"`
+		Confidence      float64 `json:"confidence"`
+	}
+	answer := func(prompt string) justification {
+		return justification{
+			Vulnerability:   field(prompt, "Vulnerability ID:"),
+			Package:         field(prompt, "Package:"),
+			Status:          "under_investigation",
+			ImpactStatement: "Canned analysis of the test AI server.",
+			Confidence:      0.5,
+		}
+	}
+
+	if !strings.Contains(system, "JSON array") {
+		single := answer(user)
+		single.Vulnerability, single.Package = "", ""
+		data, _ := json.Marshal(single)
+		return string(data)
+	}
+
+	var batch []justification
+	for _, prompt := range strings.Split(user, "\n---\n") {
+		batch = append(batch, answer(prompt))
+	}
+	data, _ := json.Marshal(batch)
+	return string(data)
+}
+
+// field returns the value of the first "name value" line of prompt
+func field(prompt, name string) string {
+	for _, line := range strings.Split(prompt, "\n") {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(line), name); ok {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,111 @@
+package ai
+
+import (
+	"encoding/json"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AuditEntry records one AI request and its response
+type AuditEntry struct {
+	Time     time.Time     `json:"time"`
+	Endpoint string        `json:"endpoint"`
+	Model    string        `json:"model"`
+	Messages []ChatMessage `json:"messages"`
+	Response string        `json:"response,omitempty"`
+	Error    string        `json:"error,omitempty"`
+	Duration string        `json:"duration"`
+}
+
+// audit appends an AuditEntry per AI request as a JSON line to a file,
+// opened on the first request
+var audit = struct {
+	sync.Mutex
+	path string
+	file *os.File
+}{}
+
+// SetAuditLog makes every AI request and response be recorded in the file at
+// path; an empty path disables the audit log
+func SetAuditLog(path string) {
+	audit.Lock()
+	defer audit.Unlock()
+
+	if audit.file != nil {
+		_ = audit.file.Close()
+		audit.file = nil
+	}
+	audit.path = path
+}
+
+// secretPatterns match credentials that may end up in prompts or responses,
+// e.g. from go.mod files or error output
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{16,}`),
+	regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{20,}`),
+	regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20,}`),
+	regexp.MustCompile(`(?i)(bearer|token|api[_-]?key|password)(["']?\s*[:=]\s*["']?|\s+)[A-Za-z0-9._~+/-]{8,}`),
+	regexp.MustCompile(`://[^/\s:@]+:[^/\s@]+@`),
+}
+
+// redact removes apiKey and anything that looks like a credential from s
+func redact(s, apiKey string) string {
+	if apiKey != "" {
+		s = strings.ReplaceAll(s, apiKey, "[REDACTED]")
+	}
+	for _, pattern := range secretPatterns {
+		s = pattern.ReplaceAllStringFunc(s, func(match string) string {
+			if strings.HasPrefix(match, "://") {
+				return "://[REDACTED]@"
+			}
+			if sub := pattern.FindStringSubmatch(match); len(sub) == 3 {
+				return sub[1] + sub[2] + "[REDACTED]"
+			}
+			return "[REDACTED]"
+		})
+	}
+	return s
+}
+
+// record appends the exchange of c to the audit log, if enabled. Failures
+// to write the log are returned, since an exchange that must be traceable
+// but was not recorded must not be used.
+func (c *Client) record(messages []ChatMessage, response string, reqErr error, start time.Time) error {
+	audit.Lock()
+	defer audit.Unlock()
+
+	if audit.path == "" {
+		return nil
+	}
+	if audit.file == nil {
+		f, err := os.OpenFile(audit.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return err
+		}
+		audit.file = f
+	}
+
+	entry := AuditEntry{
+		Time:     start.UTC(),
+		Endpoint: redact(c.Endpoint, c.APIKey),
+		Model:    c.Model,
+		Response: redact(response, c.APIKey),
+		Duration: time.Since(start).Round(time.Millisecond).String(),
+	}
+	for _, m := range messages {
+		entry.Messages = append(entry.Messages, ChatMessage{Role: m.Role, Content: redact(m.Content, c.APIKey)})
+	}
+	if reqErr != nil {
+		entry.Error = redact(reqErr.Error(), c.APIKey)
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	_, err = audit.file.Write(append(line, '\n'))
+	return err
+}
@@ -1,13 +1,19 @@
 package ai
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
+
+	"github.com/tamcore/go-autobump/internal/httpclient"
+	"github.com/tamcore/go-autobump/internal/output"
 )
 
 // Client is an OpenAI-compatible API client
@@ -16,17 +22,33 @@ type Client struct {
 	Endpoint   string
 	Model      string
 	HTTPClient *http.Client
+
+	// Prompts are the templates of the prompts sent for VEX justifications
+	Prompts Prompts
+
+	// Stream requests the completion as a stream of server-sent events, so
+	// progress can be reported and a cancelled context stops the generation
+	Stream bool
+	// OnProgress, if set, is called with the number of characters received
+	// so far as a streamed completion arrives
+	OnProgress func(received int)
+
+	// DryRun prints the requests instead of sending them, which then fail
+	// with ErrDryRun
+	DryRun bool
 }
 
+// ErrDryRun is returned for requests not sent in dry-run mode
+var ErrDryRun = errors.New("AI dry run, request not sent")
+
 // NewClient creates a new AI client
 func NewClient(apiKey, endpoint, model string) *Client {
 	return &Client{
-		APIKey:   apiKey,
-		Endpoint: endpoint,
-		Model:    model,
-		HTTPClient: &http.Client{
-			Timeout: 120 * time.Second,
-		},
+		APIKey:     apiKey,
+		Endpoint:   endpoint,
+		Model:      model,
+		HTTPClient: httpclient.New(120 * time.Second),
+		Prompts:    DefaultPrompts(),
 	}
 }
 
@@ -42,6 +64,7 @@ type ChatCompletionRequest struct {
 	Messages    []ChatMessage `json:"messages"`
 	Temperature float64       `json:"temperature,omitempty"`
 	MaxTokens   int           `json:"max_tokens,omitempty"`
+	Stream      bool          `json:"stream,omitempty"`
 }
 
 // ChatCompletionResponse represents the response from chat completions
@@ -63,6 +86,19 @@ type ChatCompletionResponse struct {
 	Error *APIError `json:"error,omitempty"`
 }
 
+// ChatCompletionChunk represents one server-sent event of a streamed
+// chat completion
+type ChatCompletionChunk struct {
+	Choices []struct {
+		Index int `json:"index"`
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Error *APIError `json:"error,omitempty"`
+}
+
 // APIError represents an error from the API
 type APIError struct {
 	Message string `json:"message"`
@@ -70,8 +106,24 @@ type APIError struct {
 	Code    string `json:"code"`
 }
 
-// Complete sends a chat completion request and returns the response text
+// Complete sends a chat completion request and returns the response text.
+// With an audit log set, the exchange is recorded; a response that cannot be
+// recorded is not returned.
 func (c *Client) Complete(ctx context.Context, messages []ChatMessage) (string, error) {
+	if c.DryRun {
+		c.printRequest(messages)
+		return "", ErrDryRun
+	}
+
+	start := time.Now()
+	response, err := c.complete(ctx, messages)
+	if auditErr := c.record(messages, response, err, start); auditErr != nil {
+		return "", fmt.Errorf("failed to write AI audit log: %w", auditErr)
+	}
+	return response, err
+}
+
+func (c *Client) complete(ctx context.Context, messages []ChatMessage) (string, error) {
 	if c.APIKey == "" {
 		return "", fmt.Errorf("AI API key not configured")
 	}
@@ -81,6 +133,7 @@ func (c *Client) Complete(ctx context.Context, messages []ChatMessage) (string,
 		Messages:    messages,
 		Temperature: 0.3,
 		MaxTokens:   2000,
+		Stream:      c.Stream,
 	}
 
 	jsonBody, err := json.Marshal(reqBody)
@@ -88,11 +141,7 @@ func (c *Client) Complete(ctx context.Context, messages []ChatMessage) (string,
 		return "", fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	endpoint := c.Endpoint
-	if endpoint == "" {
-		endpoint = "https://api.openai.com/v1"
-	}
-	url := endpoint + "/chat/completions"
+	url := c.endpoint() + "/chat/completions"
 
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(jsonBody))
 	if err != nil {
@@ -108,6 +157,10 @@ func (c *Client) Complete(ctx context.Context, messages []ChatMessage) (string,
 	}
 	defer func() { _ = resp.Body.Close() }()
 
+	if c.Stream && resp.StatusCode == http.StatusOK {
+		return c.readStream(resp.Body)
+	}
+
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return "", fmt.Errorf("failed to read response: %w", err)
@@ -133,29 +186,208 @@ func (c *Client) Complete(ctx context.Context, messages []ChatMessage) (string,
 	return result.Choices[0].Message.Content, nil
 }
 
+// Ping checks that the endpoint is reachable and accepts the API key, by
+// listing the models it serves. No completion is requested.
+func (c *Client) Ping(ctx context.Context) error {
+	if c.APIKey == "" {
+		return fmt.Errorf("AI API key not configured")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", c.endpoint()+"/models", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.APIKey)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		var errResp ChatCompletionResponse
+		if json.Unmarshal(body, &errResp) == nil && errResp.Error != nil {
+			return fmt.Errorf("API error: %s", errResp.Error.Message)
+		}
+		return fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// endpoint returns the API endpoint, OpenAI's by default
+func (c *Client) endpoint() string {
+	if c.Endpoint == "" {
+		return "https://api.openai.com/v1"
+	}
+	return c.Endpoint
+}
+
+// printRequest prints the messages of a request not sent in dry-run mode
+func (c *Client) printRequest(messages []ChatMessage) {
+	var b strings.Builder
+	for _, m := range messages {
+		fmt.Fprintf(&b, "\n--- %s ---\n%s\n", m.Role, strings.TrimSpace(m.Content))
+	}
+	output.Status(output.IconDryRun, "Would send to %s (model %s):%s", c.endpoint(), c.Model, b.String())
+}
+
+// readStream collects the content of a streamed chat completion, reporting
+// progress as it arrives
+func (c *Client) readStream(body io.Reader) (string, error) {
+	var content strings.Builder
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk ChatCompletionChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return "", fmt.Errorf("failed to parse response: %w", err)
+		}
+		if chunk.Error != nil {
+			return "", fmt.Errorf("API error: %s", chunk.Error.Message)
+		}
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+		content.WriteString(chunk.Choices[0].Delta.Content)
+		if c.OnProgress != nil {
+			c.OnProgress(content.Len())
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if content.Len() == 0 {
+		return "", fmt.Errorf("no completion choices returned")
+	}
+	return content.String(), nil
+}
+
 // GenerateVEXJustification generates a VEX justification for a vulnerability
-func (c *Client) GenerateVEXJustification(ctx context.Context, vulnID, pkgName, description, modWhyOutput string) (string, error) {
-	systemPrompt := `You are a security expert helping to create VEX (Vulnerability Exploitability eXchange) documents.
-Your task is to analyze vulnerabilities and determine if they are exploitable in the context of how the package is used.
+// from the client's prompt templates
+func (c *Client) GenerateVEXJustification(ctx context.Context, data VEXPromptData) (string, error) {
+	systemPrompt, err := render(c.Prompts.VEXSystem, data)
+	if err != nil {
+		return "", err
+	}
+	userPrompt, err := render(c.Prompts.VEXUser, data)
+	if err != nil {
+		return "", err
+	}
+
+	messages := []ChatMessage{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: userPrompt},
+	}
 
-Respond with a JSON object in OpenVEX format containing:
-- "status": one of "not_affected", "affected", "fixed", or "under_investigation"
-- "justification": if status is "not_affected", one of: "component_not_present", "vulnerable_code_not_reachable", "vulnerable_code_cannot_be_controlled_by_adversary", "inline_mitigations_already_exist"
-- "impact_statement": a brief explanation of why this status was chosen
+	return c.Complete(ctx, messages)
+}
+
+// batchVEXInstructions are appended to the VEX system prompt for requests
+// about several vulnerabilities
+const batchVEXInstructions = `
 
-Only respond with the JSON object, no additional text.`
+You will be asked about several vulnerabilities at once, separated by "---".
+Respond with a JSON array containing one such object per vulnerability, in the given order,
+each with two additional fields: "vulnerability" (the vulnerability ID) and "package" (the package).
+Only respond with the JSON array, no additional text.`
 
-	userPrompt := fmt.Sprintf(`Analyze this vulnerability:
+// GenerateVEXJustifications generates the VEX justifications of several
+// vulnerabilities in one request; the response is a JSON array of
+// justifications with their "vulnerability" and "package"
+func (c *Client) GenerateVEXJustifications(ctx context.Context, vulns []VEXPromptData) (string, error) {
+	if len(vulns) == 0 {
+		return "[]", nil
+	}
+
+	systemPrompt, err := render(c.Prompts.VEXSystem, vulns[0])
+	if err != nil {
+		return "", err
+	}
+
+	userPrompts := make([]string, 0, len(vulns))
+	for _, data := range vulns {
+		userPrompt, err := render(c.Prompts.VEXUser, data)
+		if err != nil {
+			return "", err
+		}
+		userPrompts = append(userPrompts, userPrompt)
+	}
+
+	messages := []ChatMessage{
+		{Role: "system", Content: systemPrompt + batchVEXInstructions},
+		{Role: "user", Content: strings.Join(userPrompts, "\n\n---\n\n")},
+	}
+
+	return c.Complete(ctx, messages)
+}
+
+// ExplainVulnerability generates a plain-English explanation of a vulnerability
+// and how it reaches the module, suitable for pasting into a ticket
+func (c *Client) ExplainVulnerability(ctx context.Context, vulnID, pkgName, installedVersion, fixedVersion, description, modWhyOutput string, importedBy []string) (string, error) {
+	systemPrompt := `You are a security expert explaining vulnerabilities to software engineers.
+Write a short, plain-English explanation (at most three paragraphs) covering what the vulnerability is,
+how the affected package reaches the project according to the dependency chain, and what needs to happen to fix it.
+Do not use Markdown headings.`
+
+	fix := fixedVersion
+	if fix == "" {
+		fix = "no fixed version published"
+	}
+
+	userPrompt := fmt.Sprintf(`Explain this vulnerability:
 
 Vulnerability ID: %s
 Package: %s
+Installed version: %s
+Fixed version: %s
 Description: %s
 
 Dependency chain (from 'go mod why'):
+%s`, vulnID, pkgName, installedVersion, fix, description, modWhyOutput)
+	if len(importedBy) > 0 {
+		userPrompt += "\n\nPackages of the project importing it: " + strings.Join(importedBy, ", ")
+	}
+
+	messages := []ChatMessage{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: userPrompt},
+	}
+
+	return c.Complete(ctx, messages)
+}
+
+// TriageFailure suggests how to resolve a failed dependency update, given
+// the error output of the go command and the module's go.mod
+func (c *Client) TriageFailure(ctx context.Context, vulnID, pkgName, installedVersion, fixedVersion, errorOutput, goMod string) (string, error) {
+	systemPrompt := `You are a Go build engineer helping to fix failed dependency updates.
+Given the error output of an update and the module's go.mod, identify the most likely cause and
+suggest a concrete remediation in at most three sentences, e.g. which other modules must be bumped
+together or which code change the new version requires. Do not use Markdown.`
+
+	userPrompt := fmt.Sprintf(`Updating a dependency to fix a vulnerability failed:
+
+Vulnerability ID: %s
+Package: %s
+Installed version: %s
+Fixed version: %s
+
+Error output:
 %s
 
-Based on how this dependency is used (as shown in the dependency chain), determine if the vulnerability is likely exploitable.
-If you cannot determine exploitability, use "under_investigation" status.`, vulnID, pkgName, description, modWhyOutput)
+go.mod:
+%s`, vulnID, pkgName, installedVersion, fixedVersion, errorOutput, goMod)
 
 	messages := []ChatMessage{
 		{Role: "system", Content: systemPrompt},
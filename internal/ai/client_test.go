@@ -0,0 +1,148 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCompleteStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ChatCompletionRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if !req.Stream {
+			t.Error("request does not ask for a stream")
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, part := range []string{`{\"status\":`, `\"affected\"}`} {
+			_, _ = fmt.Fprintf(w, "data: {\"choices\":[{\"delta\":{\"content\":\"%s\"}}]}\n\n", part)
+		}
+		_, _ = fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	client := NewClient("test", server.URL, "test-model")
+	client.Stream = true
+	var progress []int
+	client.OnProgress = func(received int) { progress = append(progress, received) }
+
+	response, err := client.Complete(context.Background(), []ChatMessage{{Role: "user", Content: "hi"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response != `{"status":"affected"}` {
+		t.Errorf("response = %q", response)
+	}
+	if len(progress) != 2 || progress[1] != len(response) {
+		t.Errorf("progress = %v", progress)
+	}
+}
+
+func TestCompleteStreamCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"partial\"}}]}\n\n")
+		w.(http.Flusher).Flush()
+		cancel()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := NewClient("test", server.URL, "test-model")
+	client.Stream = true
+	if _, err := client.Complete(ctx, []ChatMessage{{Role: "user", Content: "hi"}}); err == nil {
+		t.Error("cancelled stream returned no error")
+	}
+}
+
+func TestPing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models" {
+			t.Errorf("path = %q, want /models", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer valid" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = fmt.Fprint(w, `{"error":{"message":"invalid api key"}}`)
+			return
+		}
+		_, _ = fmt.Fprint(w, `{"object":"list","data":[]}`)
+	}))
+	defer server.Close()
+
+	if err := NewClient("valid", server.URL, "test-model").Ping(context.Background()); err != nil {
+		t.Errorf("Ping() error = %v", err)
+	}
+	err := NewClient("invalid", server.URL, "test-model").Ping(context.Background())
+	if err == nil || err.Error() != "API error: invalid api key" {
+		t.Errorf("Ping(invalid key) error = %v", err)
+	}
+}
+
+func TestExplainVulnerability(t *testing.T) {
+	var prompt string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ChatCompletionRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		prompt = req.Messages[len(req.Messages)-1].Content
+		_, _ = fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"An explanation."}}]}`)
+	}))
+	defer server.Close()
+
+	client := NewClient("test", server.URL, "test-model")
+	explanation, err := client.ExplainVulnerability(context.Background(),
+		"CVE-2024-0001", "example.com/vuln", "v1.0.0", "", "A flaw.", "# example.com/vuln\nexample.com/app\nexample.com/vuln", []string{"example.com/app/internal/parse"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if explanation != "An explanation." {
+		t.Errorf("explanation = %q", explanation)
+	}
+	for _, want := range []string{"Vulnerability ID: CVE-2024-0001", "Installed version: v1.0.0", "Fixed version: no fixed version published", "example.com/app\nexample.com/vuln", "importing it: example.com/app/internal/parse"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt lacks %q:\n%s", want, prompt)
+		}
+	}
+}
+
+func TestTriageFailure(t *testing.T) {
+	var requests []ChatCompletionRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ChatCompletionRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		requests = append(requests, req)
+		_, _ = fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"Bump example.com/b together."}}]}`)
+	}))
+	defer server.Close()
+
+	const goMod = "module example.com/app\n\nrequire example.com/a v1.0.0\n"
+	client := NewClient("test", server.URL, "test-model")
+	suggestion, err := client.TriageFailure(context.Background(),
+		"CVE-2024-0001", "example.com/a", "v1.0.0", "1.0.1", "example.com/b@v1.0.0 requires example.com/a@v1.0.0", goMod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suggestion != "Bump example.com/b together." {
+		t.Errorf("suggestion = %q", suggestion)
+	}
+	prompt := requests[0].Messages[len(requests[0].Messages)-1].Content
+	for _, want := range []string{"Vulnerability ID: CVE-2024-0001", "Fixed version: 1.0.1", "Error output:\nexample.com/b@v1.0.0 requires", "go.mod:\n" + goMod} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt lacks %q:\n%s", want, prompt)
+		}
+	}
+
+	// A dry run sends nothing
+	client.DryRun = true
+	if _, err := client.TriageFailure(context.Background(), "CVE-2024-0001", "example.com/a", "v1.0.0", "1.0.1", "failed", goMod); !errors.Is(err, ErrDryRun) {
+		t.Errorf("TriageFailure() in a dry run = %v, want ErrDryRun", err)
+	}
+	if len(requests) != 1 {
+		t.Errorf("dry run sent a request, %d in total", len(requests))
+	}
+}
@@ -0,0 +1,112 @@
+package ai
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"text/template"
+)
+
+// VEXPromptData holds the variables available to the VEX prompt templates
+type VEXPromptData struct {
+	VulnerabilityID string
+	Package         string
+	Description     string
+	// DependencyChain is the "go mod why" output for the package
+	DependencyChain string
+	// ImportedBy lists the packages of the project importing the package,
+	// with impact analysis enabled
+	ImportedBy []string
+	// Exploit is the exploit maturity, "public" or "weaponized", if known
+	Exploit string
+}
+
+// Prompts holds the templates of the prompts sent for VEX justifications
+type Prompts struct {
+	VEXSystem *template.Template
+	VEXUser   *template.Template
+}
+
+const defaultVEXSystemPrompt = `You are a security expert helping to create VEX (Vulnerability Exploitability eXchange) documents.
+Your task is to analyze vulnerabilities and determine if they are exploitable in the context of how the package is used.
+
+Respond with a JSON object in OpenVEX format containing:
+- "status": one of "not_affected", "affected", "fixed", or "under_investigation"
+- "justification": if status is "not_affected", one of: "component_not_present", "vulnerable_code_not_reachable", "vulnerable_code_cannot_be_controlled_by_adversary", "inline_mitigations_already_exist"
+- "impact_statement": a brief explanation of why this status was chosen
+- "confidence": a number between 0 and 1 stating how certain you are of the status
+
+Only respond with the JSON object, no additional text.`
+
+const defaultVEXUserPrompt = `Analyze this vulnerability:
+
+Vulnerability ID: {{.VulnerabilityID}}
+Package: {{.Package}}
+Description: {{.Description}}
+
+Dependency chain (from 'go mod why'):
+{{.DependencyChain}}
+{{- if .ImportedBy}}
+
+Packages of the project importing it: {{range $i, $pkg := .ImportedBy}}{{if $i}}, {{end}}{{$pkg}}{{end}}
+{{- end}}
+{{- if eq .Exploit "weaponized"}}
+
+This vulnerability is known to be exploited in the wild. Only mark it as not affected if the dependency chain shows the vulnerable code cannot be reached.
+{{- else if eq .Exploit "public"}}
+
+Exploit code for this vulnerability is publicly available.
+{{- end}}
+
+Based on how this dependency is used (as shown in the dependency chain), determine if the vulnerability is likely exploitable.
+If you cannot determine exploitability, use "under_investigation" status.`
+
+// DefaultPrompts returns the built-in prompt templates
+func DefaultPrompts() Prompts {
+	return Prompts{
+		VEXSystem: template.Must(template.New("vex-system").Parse(defaultVEXSystemPrompt)),
+		VEXUser:   template.Must(template.New("vex-user").Parse(defaultVEXUserPrompt)),
+	}
+}
+
+// LoadPrompts returns the built-in prompt templates, with those given as
+// template files replacing them. Empty paths keep the built-in template.
+func LoadPrompts(vexSystemFile, vexUserFile string) (Prompts, error) {
+	prompts := DefaultPrompts()
+
+	for _, file := range []struct {
+		path string
+		tmpl **template.Template
+	}{
+		{vexSystemFile, &prompts.VEXSystem},
+		{vexUserFile, &prompts.VEXUser},
+	} {
+		if file.path == "" {
+			continue
+		}
+		data, err := os.ReadFile(file.path)
+		if err != nil {
+			return Prompts{}, fmt.Errorf("failed to read prompt template: %w", err)
+		}
+		tmpl, err := template.New(file.path).Option("missingkey=error").Parse(string(data))
+		if err != nil {
+			return Prompts{}, fmt.Errorf("invalid prompt template %s: %w", file.path, err)
+		}
+		// Catch references to unknown variables before any request is made
+		if err := tmpl.Execute(&bytes.Buffer{}, VEXPromptData{}); err != nil {
+			return Prompts{}, fmt.Errorf("invalid prompt template %s: %w", file.path, err)
+		}
+		*file.tmpl = tmpl
+	}
+
+	return prompts, nil
+}
+
+// render executes tmpl with data
+func render(tmpl *template.Template, data any) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt %s: %w", tmpl.Name(), err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,81 @@
+package ai
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadPrompts(t *testing.T) {
+	dir := t.TempDir()
+	userFile := filepath.Join(dir, "vex-user.tmpl")
+	if err := os.WriteFile(userFile, []byte("Only call {{.Package}} not_affected for {{.VulnerabilityID}} with proof."), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	prompts, err := LoadPrompts("", userFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := render(prompts.VEXUser, VEXPromptData{VulnerabilityID: "CVE-1", Package: "example.com/dep"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Only call example.com/dep not_affected for CVE-1 with proof."; got != want {
+		t.Errorf("user prompt = %q, want %q", got, want)
+	}
+
+	system, err := render(prompts.VEXSystem, VEXPromptData{})
+	if err != nil || !strings.Contains(system, "OpenVEX") {
+		t.Errorf("system prompt = %q, %v, want the built-in prompt", system, err)
+	}
+}
+
+func TestLoadPromptsUnknownVariable(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "vex-user.tmpl")
+	if err := os.WriteFile(file, []byte("{{.Module}}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPrompts("", file); err == nil {
+		t.Error("LoadPrompts() accepted a template with an unknown variable")
+	}
+}
+
+func TestDefaultPromptsImportedBy(t *testing.T) {
+	prompts := DefaultPrompts()
+
+	got, err := render(prompts.VEXUser, VEXPromptData{VulnerabilityID: "CVE-1", ImportedBy: []string{"cmd/api", "internal/auth"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, "Packages of the project importing it: cmd/api, internal/auth\n") {
+		t.Errorf("user prompt does not list the importing packages:\n%s", got)
+	}
+
+	if got, _ = render(prompts.VEXUser, VEXPromptData{VulnerabilityID: "CVE-1"}); strings.Contains(got, "importing it") {
+		t.Errorf("user prompt mentions importing packages without any:\n%s", got)
+	}
+}
+
+func TestDefaultPromptsExploit(t *testing.T) {
+	prompts := DefaultPrompts()
+
+	for exploit, want := range map[string]string{
+		"weaponized": "known to be exploited in the wild",
+		"public":     "Exploit code for this vulnerability is publicly available",
+	} {
+		got, err := render(prompts.VEXUser, VEXPromptData{VulnerabilityID: "CVE-1", Exploit: exploit})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(got, want) {
+			t.Errorf("user prompt of a %s exploit does not contain %q:\n%s", exploit, want, got)
+		}
+	}
+
+	if got, _ := render(prompts.VEXUser, VEXPromptData{VulnerabilityID: "CVE-1"}); strings.Contains(got, "xploited in the wild") || strings.Contains(got, "Exploit code") {
+		t.Errorf("user prompt mentions exploits without any known:\n%s", got)
+	}
+}
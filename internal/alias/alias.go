@@ -0,0 +1,115 @@
+// Package alias keeps the table of identifiers the same vulnerability is
+// known by, e.g. its CVE, GHSA and Go vulnerability database IDs, so that
+// baselines, VEX statements and verification scans match a vulnerability
+// whichever of its IDs a scanner reports
+package alias
+
+import (
+	"slices"
+	"strings"
+	"sync"
+)
+
+var (
+	mu sync.Mutex
+	// aliases holds the known aliases of each advisory, and the advisories
+	// each alias is one of, by ID in upper case
+	aliases = make(map[string][]string)
+)
+
+// Add records that the advisory id is also known by others, e.g. that a
+// GHSA advisory is CVE-2024-1 and CVE-2024-2. The relation is not
+// transitive: the aliases of one advisory are not aliases of each other,
+// as an advisory may cover several vulnerabilities.
+func Add(id string, others ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	id = normalize(id)
+	if id == "" {
+		return
+	}
+	for _, other := range others {
+		other = normalize(other)
+		if other == "" || strings.EqualFold(other, id) {
+			continue
+		}
+		relate(id, other)
+		relate(other, id)
+	}
+}
+
+// relate adds other to the aliases of id
+func relate(id, other string) {
+	key := strings.ToUpper(id)
+	if !slices.ContainsFunc(aliases[key], func(known string) bool { return strings.EqualFold(known, other) }) {
+		aliases[key] = append(aliases[key], other)
+	}
+}
+
+// Same reports whether a and b identify the same vulnerability: they are
+// equal, or one is an alias of the other
+func Same(a, b string) bool {
+	if strings.EqualFold(a, b) {
+		return true
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	return slices.ContainsFunc(aliases[strings.ToUpper(a)], func(known string) bool { return strings.EqualFold(known, b) })
+}
+
+// IDs returns id and its known aliases, preferred ID first (see Canonical)
+func IDs(id string) []string {
+	mu.Lock()
+	defer mu.Unlock()
+	ids := append([]string{normalize(id)}, aliases[strings.ToUpper(id)]...)
+	slices.SortFunc(ids, func(a, b string) int {
+		if ra, rb := rank(a), rank(b); ra != rb {
+			return ra - rb
+		}
+		return strings.Compare(a, b)
+	})
+	return ids
+}
+
+// Canonical returns the preferred of id and its aliases, so that it can be
+// used as a key: a CVE ID, else a GHSA ID, else a Go vulnerability database
+// ID, the lowest one if there are several
+func Canonical(id string) string {
+	return IDs(id)[0]
+}
+
+// Reset forgets every alias
+func Reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	aliases = make(map[string][]string)
+}
+
+// prefixes are the ID schemes in the order they are preferred in
+var prefixes = []string{"CVE-", "GHSA-", "GO-"}
+
+// rank orders ids by the preference of their scheme
+func rank(id string) int {
+	for i, prefix := range prefixes {
+		if strings.HasPrefix(id, prefix) {
+			return i
+		}
+	}
+	return len(prefixes)
+}
+
+// normalize spells the scheme of id in upper case, e.g. "cve-2024-1" as
+// "CVE-2024-1", and the rest of a GHSA ID in lower case like GitHub does
+func normalize(id string) string {
+	id = strings.TrimSpace(id)
+	for _, prefix := range prefixes {
+		if len(id) > len(prefix) && strings.EqualFold(id[:len(prefix)], prefix) {
+			if prefix == "GHSA-" {
+				return prefix + strings.ToLower(id[len(prefix):])
+			}
+			return strings.ToUpper(id)
+		}
+	}
+	return id
+}
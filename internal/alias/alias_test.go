@@ -0,0 +1,88 @@
+package alias
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestTable(t *testing.T) {
+	defer Reset()
+	Reset()
+
+	Add("GO-2024-0001", "ghsa-ABCD-efgh-ijkl", "CVE-2024-1")
+	Add("CVE-2024-2")
+	if Same("GO-2024-0001", "CVE-2024-2") {
+		t.Errorf("unrelated IDs are the same")
+	}
+
+	Add("cve-2024-1", "GHSA-abcd-efgh-ijkl", "SNYK-GOLANG-X-1")
+	for _, id := range []string{"GO-2024-0001", "ghsa-abcd-efgh-ijkl", "SNYK-GOLANG-X-1", "cve-2024-1"} {
+		if !Same("CVE-2024-1", id) {
+			t.Errorf("Same(CVE-2024-1, %s) = false, want true", id)
+		}
+	}
+
+	want := []string{"CVE-2024-1", "GHSA-abcd-efgh-ijkl", "GO-2024-0001", "SNYK-GOLANG-X-1"}
+	if got := IDs("cve-2024-1"); !slices.Equal(got, want) {
+		t.Errorf("IDs() = %v, want %v", got, want)
+	}
+	if got := Canonical("SNYK-GOLANG-X-1"); got != "CVE-2024-1" {
+		t.Errorf("Canonical() = %s, want the CVE ID", got)
+	}
+	if got := Canonical("cve-2099-9"); got != "CVE-2099-9" {
+		t.Errorf("Canonical() of an unknown ID = %s, want it normalized", got)
+	}
+}
+
+func TestAdvisoryOfSeveralCVEs(t *testing.T) {
+	defer Reset()
+	Reset()
+
+	// One advisory covering two vulnerabilities does not make them one
+	Add("GHSA-wxyz-wxyz-wxyz", "CVE-2024-10", "CVE-2024-11")
+	for _, cve := range []string{"CVE-2024-10", "CVE-2024-11"} {
+		if !Same("GHSA-wxyz-wxyz-wxyz", cve) || !Same(cve, "GHSA-wxyz-wxyz-wxyz") {
+			t.Errorf("Same(GHSA-wxyz-wxyz-wxyz, %s) = false, want true", cve)
+		}
+	}
+	if Same("CVE-2024-10", "CVE-2024-11") {
+		t.Error("the CVEs of one advisory are the same vulnerability")
+	}
+	if got, want := IDs("CVE-2024-11"), []string{"CVE-2024-11", "GHSA-wxyz-wxyz-wxyz"}; !slices.Equal(got, want) {
+		t.Errorf("IDs(CVE-2024-11) = %v, want %v", got, want)
+	}
+	if got := Canonical("CVE-2024-11"); got != "CVE-2024-11" {
+		t.Errorf("Canonical(CVE-2024-11) = %s, want itself", got)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	defer Reset()
+	Reset()
+
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		switch r.URL.Path {
+		case "/vulns/GHSA-abcd-efgh-ijkl":
+			_, _ = w.Write([]byte(`{"id": "GHSA-abcd-efgh-ijkl", "aliases": ["CVE-2024-1", "GO-2024-0001"]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	c := &Client{Endpoint: server.URL, HTTPClient: server.Client()}
+	if err := c.Resolve(context.Background(), []string{"GHSA-abcd-efgh-ijkl", "CVE-2024-1", "SNYK-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if !Same("GO-2024-0001", "GHSA-abcd-efgh-ijkl") {
+		t.Errorf("aliases from OSV not added")
+	}
+	if !slices.Equal(requests, []string{"/vulns/GHSA-abcd-efgh-ijkl", "/vulns/SNYK-1"}) {
+		t.Errorf("requests = %v, want each vulnerability looked up once", requests)
+	}
+}
@@ -0,0 +1,88 @@
+package alias
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/tamcore/go-autobump/internal/httpclient"
+)
+
+// DefaultEndpoint is the OSV API
+const DefaultEndpoint = "https://api.osv.dev/v1"
+
+// Client looks up the aliases of vulnerabilities on OSV
+type Client struct {
+	Endpoint   string
+	HTTPClient *http.Client
+}
+
+// NewClient creates a client of the OSV API
+func NewClient() *Client {
+	return &Client{
+		Endpoint:   DefaultEndpoint,
+		HTTPClient: httpclient.New(30 * time.Second),
+	}
+}
+
+// Lookup returns the aliases OSV records for the vulnerability id, nil if
+// OSV does not know it
+func (c *Client) Lookup(ctx context.Context, id string) ([]string, error) {
+	endpoint := strings.TrimSuffix(c.Endpoint, "/") + "/vulns/" + url.PathEscape(id)
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OSV API returned status %d for %s", resp.StatusCode, id)
+	}
+
+	var vuln struct {
+		ID      string   `json:"id"`
+		Aliases []string `json:"aliases"`
+	}
+	if err := json.Unmarshal(body, &vuln); err != nil {
+		return nil, fmt.Errorf("failed to parse OSV response: %w", err)
+	}
+	return vuln.Aliases, nil
+}
+
+// Resolve looks up the aliases of ids on OSV and adds them to the table.
+// IDs already known as aliases of a looked up one are not looked up again.
+func (c *Client) Resolve(ctx context.Context, ids []string) error {
+	looked := make(map[string]bool)
+	for _, id := range ids {
+		if looked[strings.ToUpper(id)] {
+			continue
+		}
+		aliases, err := c.Lookup(ctx, id)
+		if err != nil {
+			return err
+		}
+		Add(id, aliases...)
+		for _, known := range IDs(id) {
+			looked[strings.ToUpper(known)] = true
+		}
+	}
+	return nil
+}
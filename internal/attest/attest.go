@@ -0,0 +1,195 @@
+// Package attest describes the changes of update runs as in-toto
+// attestations with a SLSA provenance predicate, optionally signed as DSSE
+// envelopes, so that consumers can verify where dependency changes came from
+package attest
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	// StatementType is the type of in-toto v1 statements
+	StatementType = "https://in-toto.io/Statement/v1"
+	// PredicateType is the type of SLSA v1 provenance predicates
+	PredicateType = "https://slsa.dev/provenance/v1"
+	// PayloadType is the DSSE payload type of in-toto statements
+	PayloadType = "application/vnd.in-toto+json"
+
+	// BuilderID identifies go-autobump as the builder of the changes
+	BuilderID = "https://github.com/tamcore/go-autobump"
+	// BuildType describes the external parameters of an update run
+	BuildType = "https://github.com/tamcore/go-autobump/update/v1"
+)
+
+// Statement is an in-toto statement about the files an update run changed
+type Statement struct {
+	Type          string     `json:"_type"`
+	Subject       []Resource `json:"subject"`
+	PredicateType string     `json:"predicateType"`
+	Predicate     Provenance `json:"predicate"`
+}
+
+// Resource is an in-toto resource descriptor: a file, advisory or tool,
+// identified by its URI or digest
+type Resource struct {
+	Name        string            `json:"name,omitempty"`
+	URI         string            `json:"uri,omitempty"`
+	Digest      map[string]string `json:"digest,omitempty"`
+	Annotations map[string]any    `json:"annotations,omitempty"`
+}
+
+// Provenance is a SLSA v1 provenance predicate
+type Provenance struct {
+	BuildDefinition BuildDefinition `json:"buildDefinition"`
+	RunDetails      RunDetails      `json:"runDetails"`
+}
+
+// BuildDefinition describes the inputs of an update run
+type BuildDefinition struct {
+	BuildType          string         `json:"buildType"`
+	ExternalParameters map[string]any `json:"externalParameters"`
+	// ResolvedDependencies are the source, the advisories acted on and the
+	// tools the run used
+	ResolvedDependencies []Resource `json:"resolvedDependencies,omitempty"`
+}
+
+// RunDetails describes the update run itself
+type RunDetails struct {
+	Builder  Builder  `json:"builder"`
+	Metadata Metadata `json:"metadata"`
+}
+
+// Builder identifies the tool making the changes
+type Builder struct {
+	ID      string            `json:"id"`
+	Version map[string]string `json:"version,omitempty"`
+}
+
+// Metadata holds when the update run started and finished
+type Metadata struct {
+	StartedOn  time.Time `json:"startedOn"`
+	FinishedOn time.Time `json:"finishedOn"`
+}
+
+// New returns a statement about subject with the provenance predicate
+func New(subject []Resource, definition BuildDefinition, details RunDetails) *Statement {
+	return &Statement{
+		Type:          StatementType,
+		Subject:       subject,
+		PredicateType: PredicateType,
+		Predicate:     Provenance{BuildDefinition: definition, RunDetails: details},
+	}
+}
+
+// Digest returns the hex-encoded SHA-256 digest of the file at path
+func Digest(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Envelope is a DSSE envelope carrying a signed statement
+type Envelope struct {
+	PayloadType string      `json:"payloadType"`
+	Payload     string      `json:"payload"`
+	Signatures  []Signature `json:"signatures"`
+}
+
+// Signature is a DSSE signature, with the ID of the key that made it
+type Signature struct {
+	KeyID string `json:"keyid,omitempty"`
+	Sig   string `json:"sig"`
+}
+
+// Sign signs the statement with key as a DSSE envelope. ECDSA and RSA keys
+// sign the SHA-256 digest of the pre-authentication encoding, Ed25519 keys
+// the encoding itself.
+func Sign(s *Statement, key crypto.Signer) (*Envelope, error) {
+	payload, err := json.Marshal(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the statement: %w", err)
+	}
+
+	message := pae(PayloadType, payload)
+	var sig []byte
+	if _, ok := key.(ed25519.PrivateKey); ok {
+		sig, err = key.Sign(rand.Reader, message, crypto.Hash(0))
+	} else {
+		digest := sha256.Sum256(message)
+		sig, err = key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign the statement: %w", err)
+	}
+
+	keyID, err := KeyID(key.Public())
+	if err != nil {
+		return nil, err
+	}
+	return &Envelope{
+		PayloadType: PayloadType,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+		Signatures:  []Signature{{KeyID: keyID, Sig: base64.StdEncoding.EncodeToString(sig)}},
+	}, nil
+}
+
+// pae is the DSSE pre-authentication encoding of payload, which is what
+// gets signed
+func pae(payloadType string, payload []byte) []byte {
+	return fmt.Appendf(nil, "DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload)
+}
+
+// KeyID identifies a public key by the hex-encoded SHA-256 digest of its
+// PKIX encoding
+func KeyID(pub crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode the public key: %w", err)
+	}
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// ParsePrivateKey parses an unencrypted ECDSA, Ed25519 or RSA private key in
+// PEM form: PKCS#8, SEC 1 ("EC PRIVATE KEY") or PKCS#1 ("RSA PRIVATE KEY")
+func ParsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM private key found")
+	}
+
+	var key any
+	var err error
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q (encrypted keys are not supported)", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the private key: %w", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+	return signer, nil
+}
@@ -0,0 +1,150 @@
+package baseline
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/tamcore/go-autobump/internal/alias"
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+// DefaultPath is the conventional location of the baseline file
+const DefaultPath = ".autobump.baseline.json"
+
+// Baseline records vulnerabilities that are already known, so that scans and
+// updates only act on vulnerabilities introduced afterwards
+type Baseline struct {
+	Generated       string  `json:"generated"`
+	Vulnerabilities []Entry `json:"vulnerabilities"`
+
+	index map[Entry]bool
+}
+
+// Entry identifies a known vulnerability in a module. Module is the go.mod
+// path relative to the scan root, so baselines are portable between checkouts.
+type Entry struct {
+	Module          string `json:"module"`
+	VulnerabilityID string `json:"vulnerability"`
+	PkgName         string `json:"package"`
+}
+
+// Load reads a baseline file
+func Load(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+
+	var b Baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline %s: %w", path, err)
+	}
+	b.buildIndex()
+	return &b, nil
+}
+
+// FromResults creates a baseline containing every vulnerability in results
+func FromResults(root string, results []trivy.ScanResult) *Baseline {
+	b := &Baseline{Generated: time.Now().UTC().Format(time.RFC3339)}
+	for _, result := range results {
+		module := relativeModule(root, result.Target)
+		for _, vuln := range result.Vulnerabilities {
+			b.Vulnerabilities = append(b.Vulnerabilities, Entry{
+				Module:          module,
+				VulnerabilityID: vuln.VulnerabilityID,
+				PkgName:         vuln.PkgName,
+			})
+		}
+	}
+
+	sort.Slice(b.Vulnerabilities, func(i, j int) bool {
+		a, c := b.Vulnerabilities[i], b.Vulnerabilities[j]
+		if a.Module != c.Module {
+			return a.Module < c.Module
+		}
+		if a.PkgName != c.PkgName {
+			return a.PkgName < c.PkgName
+		}
+		return a.VulnerabilityID < c.VulnerabilityID
+	})
+	b.buildIndex()
+	return b
+}
+
+// Write saves the baseline to path
+func (b *Baseline) Write(path string) error {
+	output, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal baseline: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(output, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	return nil
+}
+
+// Filter returns the scan result with all baselined vulnerabilities removed,
+// whichever of their IDs they were recorded and reported by
+func (b *Baseline) Filter(root string, result trivy.ScanResult) trivy.ScanResult {
+	module := relativeModule(root, result.Target)
+	filtered := trivy.ScanResult{Target: result.Target}
+
+	for _, vuln := range result.Vulnerabilities {
+		known := slices.ContainsFunc(vuln.IDs(), func(id string) bool {
+			return b.Contains(Entry{Module: module, VulnerabilityID: id, PkgName: vuln.PkgName})
+		})
+		if !known {
+			filtered.Vulnerabilities = append(filtered.Vulnerabilities, vuln)
+		}
+	}
+	return filtered
+}
+
+// Contains reports whether entry is in the baseline, under its ID or an
+// alias of it
+func (b *Baseline) Contains(entry Entry) bool {
+	if b.index[entry] {
+		return true
+	}
+	for _, known := range b.Vulnerabilities {
+		if known.Module == entry.Module && known.PkgName == entry.PkgName && alias.Same(known.VulnerabilityID, entry.VulnerabilityID) {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *Baseline) buildIndex() {
+	b.index = make(map[Entry]bool, len(b.Vulnerabilities))
+	for _, entry := range b.Vulnerabilities {
+		b.index[entry] = true
+	}
+}
+
+// relativeModule returns the go.mod path relative to root, using forward slashes
+func relativeModule(root, goModPath string) string {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return filepath.ToSlash(goModPath)
+	}
+	if info, err := os.Stat(absRoot); err == nil && !info.IsDir() {
+		absRoot = filepath.Dir(absRoot)
+	}
+
+	absPath, err := filepath.Abs(goModPath)
+	if err != nil {
+		return filepath.ToSlash(goModPath)
+	}
+
+	rel, err := filepath.Rel(absRoot, absPath)
+	if err != nil {
+		return filepath.ToSlash(goModPath)
+	}
+	return filepath.ToSlash(rel)
+}
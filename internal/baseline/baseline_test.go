@@ -0,0 +1,88 @@
+package baseline
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/tamcore/go-autobump/internal/alias"
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+func TestFilterAliases(t *testing.T) {
+	defer alias.Reset()
+	alias.Reset()
+
+	root := t.TempDir()
+	goMod := filepath.Join(root, "go.mod")
+	b := FromResults(root, []trivy.ScanResult{{Target: goMod, Vulnerabilities: []trivy.Vulnerability{
+		{VulnerabilityID: "GHSA-abcd-efgh-ijkl", PkgName: "example.com/a"},
+		{VulnerabilityID: "CVE-2024-2", PkgName: "example.com/b"},
+	}}})
+
+	alias.Add("CVE-2024-1", "GHSA-abcd-efgh-ijkl")
+	result := trivy.ScanResult{Target: goMod, Vulnerabilities: []trivy.Vulnerability{
+		// Known under the GHSA ID, now reported under the CVE
+		{VulnerabilityID: "CVE-2024-1", PkgName: "example.com/a"},
+		// Known under the CVE, reported with it as alias
+		{VulnerabilityID: "GO-2024-0002", Aliases: []string{"CVE-2024-2"}, PkgName: "example.com/b"},
+		// Known vulnerability of another package
+		{VulnerabilityID: "CVE-2024-1", PkgName: "example.com/c"},
+	}}
+
+	filtered := b.Filter(root, result)
+	if len(filtered.Vulnerabilities) != 1 || filtered.Vulnerabilities[0].PkgName != "example.com/c" {
+		t.Errorf("Filter() = %+v, want only the vulnerability of example.com/c", filtered.Vulnerabilities)
+	}
+	if !b.Contains(Entry{Module: "go.mod", VulnerabilityID: "cve-2024-1", PkgName: "example.com/a"}) {
+		t.Errorf("Contains() = false for an alias of a baselined vulnerability")
+	}
+}
+
+func TestBaseline(t *testing.T) {
+	root := t.TempDir()
+	goMod := filepath.Join(root, "go.mod")
+	subGoMod := filepath.Join(root, "sub", "go.mod")
+	b := FromResults(root, []trivy.ScanResult{
+		{Target: subGoMod, Vulnerabilities: []trivy.Vulnerability{{VulnerabilityID: "CVE-2024-2", PkgName: "example.com/b"}}},
+		{Target: goMod, Vulnerabilities: []trivy.Vulnerability{{VulnerabilityID: "CVE-2024-1", PkgName: "example.com/a"}}},
+	})
+	want := []Entry{
+		{Module: "go.mod", VulnerabilityID: "CVE-2024-1", PkgName: "example.com/a"},
+		{Module: "sub/go.mod", VulnerabilityID: "CVE-2024-2", PkgName: "example.com/b"},
+	}
+	if !reflect.DeepEqual(b.Vulnerabilities, want) {
+		t.Errorf("FromResults() = %+v, want %+v", b.Vulnerabilities, want)
+	}
+
+	path := filepath.Join(root, DefaultPath)
+	if err := b.Write(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Baselined vulnerabilities are known per module, only new ones remain
+	result := trivy.ScanResult{Target: goMod, Vulnerabilities: []trivy.Vulnerability{
+		{VulnerabilityID: "CVE-2024-1", PkgName: "example.com/a"},
+		{VulnerabilityID: "CVE-2024-2", PkgName: "example.com/b"},
+		{VulnerabilityID: "CVE-2024-3", PkgName: "example.com/a"},
+	}}
+	var ids []string
+	for _, vuln := range loaded.Filter(root, result).Vulnerabilities {
+		ids = append(ids, vuln.VulnerabilityID)
+	}
+	if want := []string{"CVE-2024-2", "CVE-2024-3"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("Filter() kept %v, want %v", ids, want)
+	}
+
+	if err := os.WriteFile(path, []byte("not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("Load() of an invalid baseline succeeded")
+	}
+}
@@ -0,0 +1,214 @@
+// Package botconfig reads the ignore and version rules of dependency update
+// bots (Renovate, Dependabot) configured in a repository, so that updates
+// planned by go-autobump do not contradict them.
+package botconfig
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/tamcore/go-autobump/internal/gomod"
+	"golang.org/x/mod/semver"
+)
+
+// Update types, as used by Renovate's matchUpdateTypes and Dependabot's update-types
+const (
+	UpdateMajor = "major"
+	UpdateMinor = "minor"
+	UpdatePatch = "patch"
+)
+
+// Rule restricts which versions of matching modules may be used
+type Rule struct {
+	// Source describes where the rule was defined, e.g. "renovate.json: packageRules[2]"
+	Source string
+
+	// Directory limits the rule to the module in this directory, relative to
+	// the repository root ("" applies to every module)
+	Directory string
+
+	// Patterns are module path globs ("*" matches any characters)
+	Patterns []string
+	// Regexps are Renovate matchPackagePatterns
+	Regexps []string
+	// Prefixes are Renovate matchPackagePrefixes
+	Prefixes []string
+
+	// Ignore blocks every update of matching modules
+	Ignore bool
+	// Allowed, if set, is the range target versions must satisfy
+	Allowed *Constraint
+	// Ignored lists ranges target versions must not satisfy
+	Ignored []*Constraint
+	// UpdateTypes lists update types (major, minor, patch) that are blocked
+	UpdateTypes []string
+}
+
+// Policy is the combined set of rules found in a repository
+type Policy struct {
+	Rules []Rule
+	// Files lists the bot configuration files the rules were read from
+	Files []string
+}
+
+// Load reads every supported bot configuration file below root. A repository
+// without any bot configuration yields an empty policy.
+func Load(root string) (*Policy, error) {
+	p := &Policy{}
+
+	if err := p.loadRenovate(root); err != nil {
+		return nil, err
+	}
+	if err := p.loadDependabot(root); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Check returns an error describing the first rule that forbids updating
+// module from current to version in the module whose go.mod lives in dir
+// (relative to the repository root). Non-semver targets such as "latest"
+// cannot be judged and are allowed.
+func (p *Policy) Check(dir, module, current, version string) error {
+	if p == nil {
+		return nil
+	}
+
+	version = gomod.NormalizeVersion(version)
+	if !semver.IsValid(version) {
+		return nil
+	}
+
+	for _, rule := range p.Rules {
+		if !rule.appliesTo(dir, module) {
+			continue
+		}
+		if reason := rule.blocks(current, version); reason != "" {
+			return fmt.Errorf("%s@%s is not allowed by %s (%s)", module, version, rule.Source, reason)
+		}
+	}
+	return nil
+}
+
+// ForModule returns a gomod.VersionPolicy for the module whose go.mod is at
+// goModPath, resolving its directory relative to root
+func (p *Policy) ForModule(root, goModPath string) gomod.VersionPolicy {
+	if p == nil || len(p.Rules) == 0 {
+		return nil
+	}
+	return modulePolicy{policy: p, dir: relativeDir(root, goModPath)}
+}
+
+type modulePolicy struct {
+	policy *Policy
+	dir    string
+}
+
+func (m modulePolicy) Check(module, current, version string) error {
+	return m.policy.Check(m.dir, module, current, version)
+}
+
+// appliesTo reports whether the rule covers module in dir
+func (r Rule) appliesTo(dir, module string) bool {
+	if r.Directory != "" && r.Directory != dir {
+		return false
+	}
+
+	for _, pattern := range r.Patterns {
+		if matchGlob(pattern, module) {
+			return true
+		}
+	}
+	for _, prefix := range r.Prefixes {
+		if strings.HasPrefix(module, prefix) {
+			return true
+		}
+	}
+	for _, expr := range r.Regexps {
+		if matchRegexp(expr, module) {
+			return true
+		}
+	}
+	return false
+}
+
+// blocks returns why the rule forbids the update, or "" if it is allowed
+func (r Rule) blocks(current, version string) string {
+	if r.Ignore {
+		return "updates ignored"
+	}
+	if r.Allowed != nil && !r.Allowed.Matches(version) {
+		return fmt.Sprintf("allowed versions %q", r.Allowed)
+	}
+	for _, c := range r.Ignored {
+		if c.Matches(version) {
+			return fmt.Sprintf("ignored versions %q", c)
+		}
+	}
+	if len(r.UpdateTypes) > 0 && semver.IsValid(gomod.NormalizeVersion(current)) {
+		updateType := UpdateType(current, version)
+		for _, t := range r.UpdateTypes {
+			if t == updateType {
+				return fmt.Sprintf("%s updates ignored", t)
+			}
+		}
+	}
+	return ""
+}
+
+// UpdateType classifies an update from current to version as major, minor or patch
+func UpdateType(current, version string) string {
+	current = gomod.NormalizeVersion(current)
+	version = gomod.NormalizeVersion(version)
+
+	switch {
+	case semver.Major(current) != semver.Major(version):
+		return UpdateMajor
+	case semver.MajorMinor(current) != semver.MajorMinor(version):
+		return UpdateMinor
+	default:
+		return UpdatePatch
+	}
+}
+
+// matchGlob matches a module path against a pattern where "*" matches any
+// sequence of characters, including "/"
+func matchGlob(pattern, module string) bool {
+	if !strings.Contains(pattern, "*") {
+		return pattern == module
+	}
+
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(module, parts[0]) {
+		return false
+	}
+	rest := module[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		idx := strings.Index(rest, part)
+		if idx < 0 {
+			return false
+		}
+		rest = rest[idx+len(part):]
+	}
+	return strings.HasSuffix(rest, parts[len(parts)-1])
+}
+
+// relativeDir returns the slash-separated directory of goModPath relative to
+// root, or "" for the root module
+func relativeDir(root, goModPath string) string {
+	dir := filepath.Dir(goModPath)
+	absRoot, rootErr := filepath.Abs(root)
+	absDir, dirErr := filepath.Abs(dir)
+	if rootErr == nil && dirErr == nil {
+		if rel, err := filepath.Rel(absRoot, absDir); err == nil {
+			dir = rel
+		}
+	}
+	dir = path.Clean(filepath.ToSlash(dir))
+	if dir == "." {
+		return ""
+	}
+	return dir
+}
@@ -0,0 +1,110 @@
+package botconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConstraintMatches(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		want       bool
+	}{
+		{"<2.0.0", "v1.9.3", true},
+		{"<2.0.0", "v2.0.0", false},
+		{">=1.2.0 <1.5.0", "v1.4.9", true},
+		{">=1.2.0 <1.5.0", "v1.5.0", false},
+		{">= 2.0, < 3", "v2.5.0", true},
+		{">= 2.0, < 3", "v3.0.0", false},
+		{"1.x", "v1.20.0", true},
+		{"1.x", "v2.0.0", false},
+		{"1.2.*", "v1.3.0", false},
+		{"^1.2.3", "v1.9.0", true},
+		{"^1.2.3", "v2.0.0", false},
+		{"^0.2.3", "v0.3.0", false},
+		{"~1.2.3", "v1.2.9", true},
+		{"~1.2.3", "v1.3.0", false},
+		{"~> 1.2", "v1.9.0", true},
+		{"~> 1.2", "v2.0.0", false},
+		{"1.2.3", "v1.2.3", true},
+		{"1.2.3", "v1.2.4", false},
+		{"<1.0.0 || >=2.0.0", "v1.5.0", false},
+		{"<1.0.0 || >=2.0.0", "v2.1.0", true},
+		{"/^1\\./", "v1.4.0", true},
+		{"/^1\\./", "v2.0.0", false},
+		{"!/-rc/", "v1.0.0-rc.1", false},
+		{"*", "v9.9.9", true},
+	}
+
+	for _, tt := range tests {
+		c, err := ParseConstraint(tt.constraint)
+		if err != nil {
+			t.Errorf("ParseConstraint(%q) error: %v", tt.constraint, err)
+			continue
+		}
+		if got := c.Matches(tt.version); got != tt.want {
+			t.Errorf("%q.Matches(%q) = %v, want %v", tt.constraint, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "renovate.json"), `{
+  // JSON5-style comments are accepted
+  "ignoreDeps": ["github.com/ignored/mod"],
+  "packageRules": [
+    {"matchPackageNames": ["github.com/pinned/mod"], "allowedVersions": "<2.0.0"},
+    {"matchManagers": ["npm"], "enabled": false},
+    {"matchPackagePrefixes": ["golang.org/x/"], "matchUpdateTypes": ["minor"], "enabled": false},
+  ]
+}`)
+	writeFile(t, filepath.Join(root, ".github", "dependabot.yml"), `version: 2
+updates:
+  - package-ecosystem: gomod
+    directory: /tools
+    ignore:
+      - dependency-name: "github.com/tools/*"
+        versions: [">= 1.5"]
+`)
+
+	p, err := Load(root)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	tests := []struct {
+		dir, module, current, version string
+		allowed                       bool
+	}{
+		{"", "github.com/ignored/mod", "v1.0.0", "v1.0.1", false},
+		{"", "github.com/pinned/mod", "v1.0.0", "v1.9.0", true},
+		{"", "github.com/pinned/mod", "v1.0.0", "v2.0.0", false},
+		{"", "golang.org/x/net", "v0.20.0", "v0.20.1", true},
+		{"", "golang.org/x/net", "v0.20.0", "v0.21.0", false},
+		{"tools", "github.com/tools/lint", "v1.4.0", "v1.5.0", false},
+		{"", "github.com/tools/lint", "v1.4.0", "v1.5.0", true},
+		{"", "github.com/other/mod", "v1.0.0", "v3.0.0", true},
+		{"", "github.com/ignored/mod", "v1.0.0", "latest", true},
+	}
+
+	for _, tt := range tests {
+		err := p.Check(tt.dir, tt.module, tt.current, tt.version)
+		if (err == nil) != tt.allowed {
+			t.Errorf("Check(%q, %q, %q, %q) = %v, want allowed=%v",
+				tt.dir, tt.module, tt.current, tt.version, err, tt.allowed)
+		}
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
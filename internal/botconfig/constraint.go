@@ -0,0 +1,245 @@
+package botconfig
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/tamcore/go-autobump/internal/gomod"
+	"golang.org/x/mod/semver"
+)
+
+// Constraint is a version range as written in Renovate or Dependabot configs.
+// It is either a regular expression ("/^v1\./", "!/beta/") or a set of
+// alternatives separated by "||", each a list of comparators that must all
+// hold (">=1.2.0 <2.0.0", "^1.2", "~1.4", "1.x", ">= 2.0, < 3").
+type Constraint struct {
+	raw          string
+	re           *regexp.Regexp
+	negate       bool
+	alternatives [][]comparator
+}
+
+type comparator struct {
+	op      string
+	version string
+}
+
+// ParseConstraint parses a version range
+func ParseConstraint(s string) (*Constraint, error) {
+	raw := strings.TrimSpace(s)
+	c := &Constraint{raw: raw}
+
+	// Regular expression form: /regex/ or !/regex/
+	expr := raw
+	if strings.HasPrefix(expr, "!/") {
+		c.negate = true
+		expr = expr[1:]
+	}
+	if len(expr) >= 2 && strings.HasPrefix(expr, "/") && strings.HasSuffix(expr, "/") {
+		re, err := regexp.Compile(expr[1 : len(expr)-1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid version pattern %q: %w", raw, err)
+		}
+		c.re = re
+		return c, nil
+	}
+	c.negate = false
+
+	for _, alt := range strings.Split(raw, "||") {
+		comparators, err := parseComparators(alt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version range %q: %w", raw, err)
+		}
+		c.alternatives = append(c.alternatives, comparators)
+	}
+	return c, nil
+}
+
+// String returns the constraint as written
+func (c *Constraint) String() string {
+	return c.raw
+}
+
+// Matches reports whether version satisfies the constraint
+func (c *Constraint) Matches(version string) bool {
+	if c.re != nil {
+		// Renovate matches patterns against the version without the Go "v" prefix
+		v := strings.TrimPrefix(version, "v")
+		return c.re.MatchString(v) != c.negate
+	}
+
+	version = gomod.NormalizeVersion(version)
+	if !semver.IsValid(version) {
+		return false
+	}
+
+	for _, alt := range c.alternatives {
+		ok := true
+		for _, cmp := range alt {
+			if !cmp.matches(version) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (cmp comparator) matches(version string) bool {
+	d := semver.Compare(version, cmp.version)
+	switch cmp.op {
+	case "<":
+		return d < 0
+	case "<=":
+		return d <= 0
+	case ">":
+		return d > 0
+	case ">=":
+		return d >= 0
+	case "!=":
+		return d != 0
+	default:
+		return d == 0
+	}
+}
+
+// parseComparators parses a whitespace or comma separated list of comparators.
+// An operator may be separated from its version by whitespace (">= 2.0").
+func parseComparators(s string) ([]comparator, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ' ' || r == ',' || r == '\t' })
+
+	var tokens []string
+	for i := 0; i < len(fields); i++ {
+		tok := fields[i]
+		if isOperator(tok) && i+1 < len(fields) {
+			tok += fields[i+1]
+			i++
+		}
+		tokens = append(tokens, tok)
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty range")
+	}
+
+	var comparators []comparator
+	for _, tok := range tokens {
+		cmps, err := parseComparator(tok)
+		if err != nil {
+			return nil, err
+		}
+		comparators = append(comparators, cmps...)
+	}
+	return comparators, nil
+}
+
+func isOperator(s string) bool {
+	switch s {
+	case "<", "<=", ">", ">=", "=", "==", "!=", "^", "~", "~>":
+		return true
+	}
+	return false
+}
+
+// parseComparator expands a single token into one or more primitive comparators
+func parseComparator(tok string) ([]comparator, error) {
+	var op string
+	for _, prefix := range []string{"<=", ">=", "!=", "==", "~>", "<", ">", "=", "^", "~"} {
+		if strings.HasPrefix(tok, prefix) {
+			op = prefix
+			tok = tok[len(prefix):]
+			break
+		}
+	}
+
+	parts, wildcard, err := splitVersion(tok)
+	if err != nil {
+		return nil, err
+	}
+	if len(parts) == 0 {
+		// "*" and "x" match any version
+		return nil, nil
+	}
+	lower := canonical(parts)
+
+	switch op {
+	case "^":
+		// Allow changes that do not modify the left-most non-zero component
+		upper := bumpCaret(parts)
+		return []comparator{{">=", lower}, {"<", upper}}, nil
+	case "~":
+		// "~1.2.3" and "~1.2" allow patch updates, "~1" allows minor updates
+		return []comparator{{">=", lower}, {"<", bump(parts, min(len(parts), 2))}}, nil
+	case "~>":
+		// Ruby's pessimistic operator: "~> 1.2.3" is below 1.3.0, "~> 1.2" below 2.0.0
+		return []comparator{{">=", lower}, {"<", bump(parts, max(len(parts)-1, 1))}}, nil
+	case "", "=", "==":
+		if wildcard || len(parts) < 3 {
+			// "1.x", "1.2.*" and bare "1.2" describe a whole release line
+			return []comparator{{">=", lower}, {"<", bump(parts, len(parts))}}, nil
+		}
+		return []comparator{{"=", lower}}, nil
+	default:
+		return []comparator{{op, lower}}, nil
+	}
+}
+
+// splitVersion splits "1.2.x" into its numeric parts, dropping wildcards
+func splitVersion(s string) ([]string, bool, error) {
+	s = strings.TrimPrefix(s, "v")
+	if s == "" || s == "*" || s == "x" || s == "X" {
+		return nil, true, nil
+	}
+
+	// Any pre-release suffix stays attached to the patch component
+	var parts []string
+	wildcard := false
+	for _, p := range strings.SplitN(s, ".", 3) {
+		if p == "x" || p == "X" || p == "*" {
+			wildcard = true
+			break
+		}
+		parts = append(parts, p)
+	}
+
+	if !semver.IsValid(canonical(parts)) {
+		return nil, false, fmt.Errorf("invalid version %q", s)
+	}
+	return parts, wildcard, nil
+}
+
+// canonical pads parts to a full "vMAJOR.MINOR.PATCH" version
+func canonical(parts []string) string {
+	full := append([]string{}, parts...)
+	for len(full) < 3 {
+		full = append(full, "0")
+	}
+	return "v" + strings.Join(full, ".")
+}
+
+// bump increments the component at index n-1 and zeroes the rest,
+// e.g. bump([1 2 3], 2) = v1.3.0
+func bump(parts []string, n int) string {
+	var nums [3]int
+	for i := 0; i < len(parts) && i < 3; i++ {
+		_, _ = fmt.Sscanf(parts[i], "%d", &nums[i])
+	}
+	nums[n-1]++
+	for i := n; i < 3; i++ {
+		nums[i] = 0
+	}
+	return fmt.Sprintf("v%d.%d.%d", nums[0], nums[1], nums[2])
+}
+
+// bumpCaret returns the exclusive upper bound of a caret range
+func bumpCaret(parts []string) string {
+	for i := 0; i < len(parts) && i < 2; i++ {
+		if parts[i] != "0" {
+			return bump(parts, i+1)
+		}
+	}
+	return bump(parts, len(parts))
+}
@@ -0,0 +1,84 @@
+package botconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// dependabotFiles are the locations Dependabot reads its configuration from
+var dependabotFiles = []string{
+	".github/dependabot.yml",
+	".github/dependabot.yaml",
+}
+
+type dependabotConfig struct {
+	Updates []struct {
+		PackageEcosystem string   `yaml:"package-ecosystem"`
+		Directory        string   `yaml:"directory"`
+		Directories      []string `yaml:"directories"`
+		Ignore           []struct {
+			DependencyName string   `yaml:"dependency-name"`
+			Versions       []string `yaml:"versions"`
+			UpdateTypes    []string `yaml:"update-types"`
+		} `yaml:"ignore"`
+	} `yaml:"updates"`
+}
+
+// loadDependabot reads the ignore rules of gomod entries in the Dependabot configuration
+func (p *Policy) loadDependabot(root string) error {
+	for _, name := range dependabotFiles {
+		data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(name)))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", name, err)
+		}
+
+		var cfg dependabotConfig
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+
+		for i, update := range cfg.Updates {
+			if update.PackageEcosystem != "gomod" {
+				continue
+			}
+
+			dirs := update.Directories
+			if update.Directory != "" {
+				dirs = append(dirs, update.Directory)
+			}
+
+			for j, ignore := range update.Ignore {
+				rule := Rule{
+					Source:   fmt.Sprintf("%s: updates[%d].ignore[%d]", name, i, j),
+					Patterns: []string{ignore.DependencyName},
+				}
+
+				for _, v := range ignore.Versions {
+					c, err := ParseConstraint(v)
+					if err != nil {
+						return fmt.Errorf("%s: %w", rule.Source, err)
+					}
+					rule.Ignored = append(rule.Ignored, c)
+				}
+				for _, t := range ignore.UpdateTypes {
+					// e.g. "version-update:semver-major" -> "major"
+					rule.UpdateTypes = append(rule.UpdateTypes, strings.TrimPrefix(t, "version-update:semver-"))
+				}
+				rule.Ignore = len(rule.Ignored) == 0 && len(rule.UpdateTypes) == 0
+
+				p.Rules = append(p.Rules, scopeToPaths(rule, dirs)...)
+			}
+		}
+
+		p.Files = append(p.Files, name)
+		return nil
+	}
+	return nil
+}
@@ -0,0 +1,251 @@
+package botconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// renovateFiles are the locations Renovate reads its configuration from, in
+// the order Renovate checks them. Only the first existing file is used.
+var renovateFiles = []string{
+	"renovate.json",
+	"renovate.json5",
+	".github/renovate.json",
+	".github/renovate.json5",
+	".gitlab/renovate.json",
+	".gitlab/renovate.json5",
+	".renovaterc",
+	".renovaterc.json",
+	".renovaterc.json5",
+}
+
+type renovateConfig struct {
+	IgnoreDeps   []string              `json:"ignoreDeps"`
+	PackageRules []renovatePackageRule `json:"packageRules"`
+	GoMod        *struct {
+		Enabled *bool `json:"enabled"`
+	} `json:"gomod"`
+}
+
+type renovatePackageRule struct {
+	MatchPackageNames    []string `json:"matchPackageNames"`
+	MatchDepNames        []string `json:"matchDepNames"`
+	MatchPackagePatterns []string `json:"matchPackagePatterns"`
+	MatchPackagePrefixes []string `json:"matchPackagePrefixes"`
+	MatchManagers        []string `json:"matchManagers"`
+	MatchDatasources     []string `json:"matchDatasources"`
+	MatchFileNames       []string `json:"matchFileNames"`
+	MatchPaths           []string `json:"matchPaths"`
+	MatchUpdateTypes     []string `json:"matchUpdateTypes"`
+	Enabled              *bool    `json:"enabled"`
+	AllowedVersions      string   `json:"allowedVersions"`
+}
+
+// loadRenovate reads the first Renovate configuration file found below root
+func (p *Policy) loadRenovate(root string) error {
+	for _, name := range renovateFiles {
+		data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(name)))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", name, err)
+		}
+
+		var cfg renovateConfig
+		if err := json.Unmarshal(stripJSONComments(data), &cfg); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+
+		rules, err := renovateRules(name, cfg)
+		if err != nil {
+			return err
+		}
+		p.Rules = append(p.Rules, rules...)
+		p.Files = append(p.Files, name)
+		return nil
+	}
+	return nil
+}
+
+// renovateRules converts a Renovate configuration into rules for Go modules
+func renovateRules(file string, cfg renovateConfig) ([]Rule, error) {
+	var rules []Rule
+
+	if cfg.GoMod != nil && cfg.GoMod.Enabled != nil && !*cfg.GoMod.Enabled {
+		rules = append(rules, Rule{
+			Source:   file + ": gomod.enabled",
+			Patterns: []string{"*"},
+			Ignore:   true,
+		})
+	}
+
+	if len(cfg.IgnoreDeps) > 0 {
+		rules = append(rules, Rule{
+			Source:   file + ": ignoreDeps",
+			Patterns: cfg.IgnoreDeps,
+			Ignore:   true,
+		})
+	}
+
+	for i, pr := range cfg.PackageRules {
+		if !appliesToGo(pr) {
+			continue
+		}
+
+		rule := Rule{
+			Source:   fmt.Sprintf("%s: packageRules[%d]", file, i),
+			Prefixes: pr.MatchPackagePrefixes,
+			Regexps:  pr.MatchPackagePatterns,
+		}
+		for _, name := range append(pr.MatchPackageNames, pr.MatchDepNames...) {
+			if isRegexp(name) {
+				rule.Regexps = append(rule.Regexps, name[1:len(name)-1])
+			} else {
+				rule.Patterns = append(rule.Patterns, strings.ReplaceAll(name, "**", "*"))
+			}
+		}
+		if len(rule.Patterns)+len(rule.Prefixes)+len(rule.Regexps) == 0 {
+			// A rule without package selectors applies to every dependency
+			rule.Patterns = []string{"*"}
+		}
+
+		blocking := false
+		if pr.Enabled != nil && !*pr.Enabled {
+			if len(pr.MatchUpdateTypes) > 0 {
+				rule.UpdateTypes = pr.MatchUpdateTypes
+			} else {
+				rule.Ignore = true
+			}
+			blocking = true
+		}
+		if pr.AllowedVersions != "" {
+			c, err := ParseConstraint(pr.AllowedVersions)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", rule.Source, err)
+			}
+			rule.Allowed = c
+			blocking = true
+		}
+		if !blocking {
+			continue
+		}
+
+		rules = append(rules, scopeToPaths(rule, append(pr.MatchFileNames, pr.MatchPaths...))...)
+	}
+
+	return rules, nil
+}
+
+// appliesToGo reports whether a package rule can match Go module dependencies
+func appliesToGo(pr renovatePackageRule) bool {
+	if len(pr.MatchManagers) > 0 && !contains(pr.MatchManagers, "gomod") {
+		return false
+	}
+	if len(pr.MatchDatasources) > 0 && !contains(pr.MatchDatasources, "go") {
+		return false
+	}
+	return true
+}
+
+// scopeToPaths restricts a rule to the modules in the given go.mod files or
+// directories, relative to the repository root (a leading "/" is allowed).
+// Glob patterns cannot be mapped to a single module, so such rules are
+// applied to every module.
+func scopeToPaths(rule Rule, paths []string) []Rule {
+	if len(paths) == 0 {
+		return []Rule{rule}
+	}
+
+	var rules []Rule
+	for _, p := range paths {
+		if strings.ContainsAny(p, "*?[") {
+			return []Rule{rule}
+		}
+		dir := strings.TrimSuffix(strings.TrimSuffix(p, "go.mod"), "/")
+		scoped := rule
+		scoped.Directory = strings.TrimPrefix(path.Clean("/"+dir), "/")
+		rules = append(rules, scoped)
+	}
+	return rules
+}
+
+func isRegexp(s string) bool {
+	return len(s) >= 2 && strings.HasPrefix(s, "/") && strings.HasSuffix(s, "/")
+}
+
+func matchRegexp(expr, module string) bool {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return false
+	}
+	return re.MatchString(module)
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// stripJSONComments removes // and /* */ comments and trailing commas, which
+// Renovate accepts in its JSON5 configuration files
+func stripJSONComments(data []byte) []byte {
+	var out []byte
+	inString := false
+
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+
+		if inString {
+			out = append(out, c)
+			if c == '\\' && i+1 < len(data) {
+				i++
+				out = append(out, data[i])
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+
+		switch {
+		case c == '"':
+			inString = true
+			out = append(out, c)
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			if i < len(data) {
+				out = append(out, '\n')
+			}
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			i += 2
+			for i+1 < len(data) && (data[i] != '*' || data[i+1] != '/') {
+				i++
+			}
+			i++
+		case c == '}' || c == ']':
+			// Drop a trailing comma before the closing bracket
+			j := len(out) - 1
+			for j >= 0 && (out[j] == ' ' || out[j] == '\t' || out[j] == '\n' || out[j] == '\r') {
+				j--
+			}
+			if j >= 0 && out[j] == ',' {
+				out = append(out[:j], out[j+1:]...)
+			}
+			out = append(out, c)
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
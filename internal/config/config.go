@@ -1,13 +1,23 @@
 package config
 
 import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/go-viper/mapstructure/v2"
 	"github.com/spf13/viper"
 )
 
 // Config holds all configuration options for go-autobump
 type Config struct {
+	// Profile is the name of the profile, under "profiles" in the config
+	// file, whose settings apply over the top-level ones
+	Profile string `mapstructure:"profile"`
+
 	// Path is the target directory to scan (default: ".")
 	Path string `mapstructure:"path"`
 
@@ -17,6 +27,21 @@ type Config struct {
 	// CVSSThreshold is the minimum CVSS score to act on (e.g., 7.0)
 	CVSSThreshold float64 `mapstructure:"cvss-threshold"`
 
+	// CVSSOverrides set other thresholds for particular packages or
+	// namespaces
+	CVSSOverrides []CVSSOverrideConfig `mapstructure:"cvss-overrides"`
+
+	// DevDependencies is the policy for dependencies only reachable from
+	// tests and tools
+	DevDependencies DevDependenciesConfig `mapstructure:"dev-dependencies"`
+
+	// MinAge skips vulnerabilities published more recently than this (e.g. "7d"),
+	// as day-zero advisories are often revised
+	MinAge string `mapstructure:"min-age"`
+
+	// MaxAge skips vulnerabilities published longer ago than this (e.g. "90d")
+	MaxAge string `mapstructure:"max-age"`
+
 	// SkipTidy disables running "go mod tidy" after updates
 	SkipTidy bool `mapstructure:"skip-tidy"`
 
@@ -26,18 +51,408 @@ type Config struct {
 	// AllowMajor permits major version bumps (e.g., v1 -> v2)
 	AllowMajor bool `mapstructure:"allow-major"`
 
+	// MajorApproval records required major version bumps for a human decision
+	// instead of treating them as failures, and requests approval via the forge
+	MajorApproval bool `mapstructure:"major-approval"`
+
+	// Atomic restores a module's go.mod and go.sum if any of its updates fail
+	Atomic bool `mapstructure:"atomic"`
+
+	// LockstepGroups are sets of modules that move together: when one member
+	// is updated, the members required at the same version are updated with it
+	LockstepGroups []LockstepGroupConfig `mapstructure:"lockstep-groups"`
+
+	// CompatibilityRules are checked after every update, in addition to the
+	// built-in rules unless BuiltinCompatibilityRules is disabled. Updates
+	// that would break a rule are undone.
+	CompatibilityRules        []CompatibilityRuleConfig `mapstructure:"compatibility-rules"`
+	BuiltinCompatibilityRules bool                      `mapstructure:"builtin-compatibility-rules"`
+
+	// Mitigations replace vulnerable modules without a fixed release by a
+	// patched fork or commit until upstream ships a fix
+	Mitigations MitigationsConfig `mapstructure:"mitigations"`
+
+	// AlignVersions raises the dependencies updated in one module to the
+	// same version in all other modules of the repository
+	AlignVersions bool `mapstructure:"align-versions"`
+
+	// ModuleCheck refuses updates that would add a module whose path looks
+	// like a mistake, e.g. a lookalike of the advisory's module, until a
+	// human confirms it
+	ModuleCheck ModuleCheckConfig `mapstructure:"module-check"`
+
+	// Scorecard holds or flags updates to versions whose source repository
+	// has a low OpenSSF Scorecard score
+	Scorecard ScorecardConfig `mapstructure:"scorecard"`
+
+	// AllPatch also bumps every direct dependency to its latest patch
+	// release, whether or not it has a known vulnerability
+	AllPatch bool `mapstructure:"all-patch"`
+
+	// Worktree performs each update in a temporary git worktree and only
+	// merges go.mod/go.sum back once the vulnerability is confirmed fixed
+	Worktree bool `mapstructure:"worktree"`
+
+	// Commit commits the changes of update runs with a configurable identity
+	Commit CommitConfig `mapstructure:"commit"`
+
+	// Attestation writes a SLSA provenance attestation of the changes of
+	// update runs
+	Attestation AttestationConfig `mapstructure:"attestation"`
+
+	// Batch applies all updates of a module together and verifies them with a
+	// single scan, retrying individually only those that remain unfixed
+	Batch bool `mapstructure:"batch"`
+
+	// Strategy controls which version a direct dependency is bumped to when
+	// fixing an indirect vulnerability (minimal, latest, patch-only)
+	Strategy string `mapstructure:"strategy"`
+
+	// FixVersion controls which version a vulnerable direct dependency is
+	// updated to (fixed, latest-patch, latest)
+	FixVersion string `mapstructure:"fix-version"`
+
+	// RespectBotConfig honors ignore and allowed-version rules from the
+	// repository's Renovate and Dependabot configuration when updating
+	RespectBotConfig bool `mapstructure:"respect-bot-config"`
+
+	// RiskScore reports the risk score of every module before and after an
+	// update run, weighted by CVSS, EPSS and the CISA KEV catalog
+	RiskScore bool `mapstructure:"risk-score"`
+
+	// ImpactAnalysis lists, for each vulnerability, the packages of the
+	// module importing the vulnerable module, in reports and AI prompts
+	ImpactAnalysis bool `mapstructure:"impact-analysis"`
+
 	// GenerateVEX enables VEX document generation for unfixed CVEs
 	GenerateVEX bool `mapstructure:"generate-vex"`
 
 	// VEXOutput is the output path for VEX documents
 	VEXOutput string `mapstructure:"vex-output"`
 
+	// VEXProducts are the application or image product IDs (preferably
+	// purls) VEX statements are made for, with the vulnerable module as
+	// subcomponent; empty makes the module itself the product
+	VEXProducts []string `mapstructure:"vex-products"`
+
+	// VEXMetadata identifies the issuer of VEX documents
+	VEXMetadata VEXMetadataConfig `mapstructure:"vex-metadata"`
+
+	// VEXReview holds AI-generated VEX statements in VEXReviewQueue until a
+	// human approves them with "vex review"
+	VEXReview bool `mapstructure:"vex-review"`
+
+	// VEXReviewQueue is the file of AI-generated statements pending review
+	VEXReviewQueue string `mapstructure:"vex-review-queue"`
+
+	// PURLPreserveCase keeps the case of module paths in package URLs
+	// instead of lowercasing them as the purl spec and Trivy do
+	PURLPreserveCase bool `mapstructure:"purl-preserve-case"`
+
+	// OSVScannerConfig, if set, is an osv-scanner config file that receives an
+	// ignore entry for every vulnerability marked not_affected in VEX
+	OSVScannerConfig string `mapstructure:"osv-scanner-config"`
+
+	// Baseline is the path to a baseline file of known vulnerabilities;
+	// when set, only vulnerabilities not in the baseline are acted on
+	Baseline string `mapstructure:"baseline"`
+
+	// FromSnyk, if set, is the "snyk test --json" output to take the
+	// vulnerabilities from instead of scanning with Trivy
+	FromSnyk string `mapstructure:"from-snyk"`
+
+	// Aliases relates the IDs of the same vulnerability, so that baselines,
+	// VEX statements and verification scans match whichever one a scanner
+	// reports
+	Aliases AliasesConfig `mapstructure:"aliases"`
+
+	// Exploits annotates findings with their exploit maturity, which
+	// orders them for updates and reports and is passed to the AI
+	Exploits ExploitsConfig `mapstructure:"exploits"`
+
+	// ReportFile, if set, receives the JSON report of scan and update runs
+	ReportFile string `mapstructure:"report-file"`
+
+	// Quiet only prints warnings, errors and results
+	Quiet bool `mapstructure:"quiet"`
+
+	// NoEmoji replaces emoji in status output with plain-text markers
+	NoEmoji bool `mapstructure:"no-emoji"`
+
+	// NoColor disables ANSI colors in status output
+	NoColor bool `mapstructure:"no-color"`
+
+	// LogFormat selects the status output format: text or json
+	LogFormat string `mapstructure:"log-format"`
+
+	// Job configures unattended runs with the job command
+	Job JobConfig `mapstructure:"job"`
+
+	// Fleet configures runs across many repositories with the fleet command
+	Fleet FleetConfig `mapstructure:"fleet"`
+
+	// Watch configures scan-only runs of the watch command that alert on
+	// new vulnerabilities
+	Watch WatchConfig `mapstructure:"watch"`
+
 	// AI configuration for VEX generation
 	AI AIConfig `mapstructure:"ai"`
 
+	// Forge configures the code hosting provider used for issues and comments
+	Forge ForgeConfig `mapstructure:"forge"`
+
+	// Jira configures remediation tickets for unfixed and deferred
+	// vulnerabilities
+	Jira JiraConfig `mapstructure:"jira"`
+
+	// DefectDojo configures the upload of findings to DefectDojo
+	DefectDojo DefectDojoConfig `mapstructure:"defectdojo"`
+
+	// DependencyTrack configures the upload of a BOM and VEX to Dependency-Track
+	DependencyTrack DependencyTrackConfig `mapstructure:"dependency-track"`
+
+	// TrackUnfixed opens a forge issue per vulnerability without a fixed
+	// version and closes it once the vulnerability is no longer reported
+	TrackUnfixed bool `mapstructure:"track-unfixed"`
+
+	// Plugins are external commands run at hook points (scan, post-update,
+	// complete) that receive the run context as JSON on stdin
+	Plugins []PluginConfig `mapstructure:"plugins"`
+
 	// SkipTrivyDBUpdate skips downloading the Trivy vulnerability database
 	// Only use this if you've pre-downloaded the DB or for repeated local scans
 	SkipTrivyDBUpdate bool `mapstructure:"skip-trivy-db-update"`
+
+	// Trivy holds further options of the Trivy scans
+	Trivy TrivyConfig `mapstructure:"trivy"`
+
+	// SkipEmptyModules skips modules without packages, such as placeholder
+	// modules and tooling stubs
+	SkipEmptyModules bool `mapstructure:"skip-empty-modules"`
+
+	// TrivyRetries is how often a Trivy invocation failing with a transient
+	// error (e.g. a network error downloading the DB) is retried
+	TrivyRetries int `mapstructure:"trivy-retries"`
+
+	// TrivyRetryBackoff is the wait before the first retry, doubling with
+	// each further retry
+	TrivyRetryBackoff time.Duration `mapstructure:"trivy-retry-backoff"`
+
+	// TrivyVersionCheck controls what happens when the installed Trivy is older
+	// than the minimum supported version: warn, error, or off
+	TrivyVersionCheck string `mapstructure:"trivy-version-check"`
+
+	// GoBinary is the go command to execute (default: "go" from PATH)
+	GoBinary string `mapstructure:"go-binary"`
+
+	// TrivyBinary is the trivy command to execute (default: "trivy" from PATH)
+	TrivyBinary string `mapstructure:"trivy-binary"`
+
+	// GoToolchain sets GOTOOLCHAIN for go commands: "module" uses each
+	// module's toolchain directive, other values (e.g. "go1.22.5") are
+	// used as is. Empty leaves GOTOOLCHAIN to the environment.
+	GoToolchain string `mapstructure:"go-toolchain"`
+
+	// GoReleases configures checking the Go versions the repository declares
+	// against Go security releases (toolchain command)
+	GoReleases GoReleasesConfig `mapstructure:"go-releases"`
+
+	// BuilderImages also checks the golang images Dockerfiles build with
+	// during scan and update runs, bumping vulnerable or end-of-life tags
+	// during updates
+	BuilderImages bool `mapstructure:"builder-images"`
+
+	// GoModCache sets GOMODCACHE for go commands, e.g. to a cache volume
+	// shared between runs. Empty leaves GOMODCACHE to the environment.
+	GoModCache string `mapstructure:"go-mod-cache"`
+
+	// WarmModCache downloads the dependencies of every module with
+	// "go mod download" before updating, instead of during the first update
+	WarmModCache bool `mapstructure:"warm-mod-cache"`
+
+	// Env holds extra environment variables passed to every executed command
+	// (e.g. GOMODCACHE, GOFLAGS, HTTPS_PROXY)
+	Env map[string]string `mapstructure:"env"`
+
+	// SecretCommand is run with the config key of each secret that is not
+	// set otherwise (e.g. "ai.api-key") as last argument and prints the
+	// secret, e.g. a wrapper around a vault CLI. It runs when an
+	// integration first uses the secret.
+	SecretCommand string `mapstructure:"secret-command"`
+
+	// TLS configures outbound HTTPS connections of go-autobump itself (AI,
+	// forges, Jira, exporters, EPSS and KEV); proxies are taken from
+	// HTTPS_PROXY, HTTP_PROXY and NO_PROXY
+	TLS TLSConfig `mapstructure:"tls"`
+
+	// Timeout bounds a whole scan or update run; 0 means no limit
+	Timeout time.Duration `mapstructure:"timeout"`
+
+	// ScanTimeout bounds each Trivy scan; 0 means Trivy's own default (5m)
+	ScanTimeout time.Duration `mapstructure:"scan-timeout"`
+
+	// UpdateTimeout bounds the go commands of each vulnerability update;
+	// 0 means no limit
+	UpdateTimeout time.Duration `mapstructure:"update-timeout"`
+
+	// Limits bounds the resources of each command but git
+	Limits LimitsConfig `mapstructure:"limits"`
+}
+
+// LimitsConfig bounds the resources of each go, trivy and plugin command, so
+// that a pathological module graph cannot exhaust the machine running
+// go-autobump
+type LimitsConfig struct {
+	// Memory is the memory each command may use, e.g. "2GiB"; empty means
+	// no limit
+	Memory string `mapstructure:"memory"`
+
+	// CPUs is the number of CPUs each command may use, e.g. 1.5; 0 means
+	// no limit
+	CPUs float64 `mapstructure:"cpus"`
+
+	// GoTimeout bounds each go command; 0 means no limit
+	GoTimeout time.Duration `mapstructure:"go-timeout"`
+
+	// Cgroup is a cgroup v2 directory delegated to go-autobump, under which
+	// each command runs in its own cgroup enforcing Memory and CPUs (Linux
+	// only). Without it, the limits are passed to the commands as
+	// GOMEMLIMIT and GOMAXPROCS, which go and trivy only try to stay within.
+	Cgroup string `mapstructure:"cgroup"`
+}
+
+// TLSConfig holds the trust settings of outbound HTTPS connections
+type TLSConfig struct {
+	// CAFile is a PEM bundle of certificate authorities trusted in addition
+	// to the system roots
+	CAFile string `mapstructure:"ca-file"`
+
+	// InsecureSkipVerify disables certificate verification, for lab use only
+	InsecureSkipVerify bool `mapstructure:"insecure-skip-verify"`
+}
+
+// Log formats
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// Job modes
+const (
+	JobModeScan   = "scan"
+	JobModeUpdate = "update"
+)
+
+// TrivyConfig holds further options of the Trivy scans
+type TrivyConfig struct {
+	// IgnoreUnfixed drops vulnerabilities without a fixed version from the
+	// scans, and so from reports and VEX documents
+	IgnoreUnfixed bool `mapstructure:"ignore-unfixed"`
+
+	// DBRepositories are the OCI repositories to download the vulnerability
+	// database from, e.g. an internal mirror (default: Trivy's)
+	DBRepositories []string `mapstructure:"db-repository"`
+
+	// Parallel is the number of files Trivy analyzes in parallel (0: Trivy's
+	// default)
+	Parallel int `mapstructure:"parallel"`
+
+	// ExtraArgs are further "trivy fs" flags, limited to an allowlist of
+	// flags that keep the JSON report intact, e.g. ["--skip-dirs", "testdata"]
+	ExtraArgs []string `mapstructure:"extra-args"`
+}
+
+// Trivy version check modes
+const (
+	TrivyVersionCheckWarn  = "warn"
+	TrivyVersionCheckError = "error"
+	TrivyVersionCheckOff   = "off"
+)
+
+// Update strategies for fixing indirect vulnerabilities through a direct dependency
+const (
+	// StrategyLatest updates the direct dependency to its latest release
+	StrategyLatest = "latest"
+	// StrategyMinimal updates to the smallest version that pulls in the fix
+	StrategyMinimal = "minimal"
+	// StrategyPatchOnly only considers patch releases of the current minor line
+	StrategyPatchOnly = "patch-only"
+)
+
+// ValidStrategy reports whether s is a known update strategy
+func ValidStrategy(s string) bool {
+	switch s {
+	case StrategyLatest, StrategyMinimal, StrategyPatchOnly:
+		return true
+	}
+	return false
+}
+
+// Fix version selections for vulnerable direct dependencies
+const (
+	// FixVersionFixed updates to the fixed version reported by Trivy
+	FixVersionFixed = "fixed"
+	// FixVersionLatestPatch updates to the newest patch release of the
+	// installed minor line, if it contains the fix
+	FixVersionLatestPatch = "latest-patch"
+	// FixVersionLatest updates to the newest release containing the fix
+	FixVersionLatest = "latest"
+)
+
+// ValidFixVersion reports whether s is a known fix version selection
+func ValidFixVersion(s string) bool {
+	switch s {
+	case FixVersionFixed, FixVersionLatestPatch, FixVersionLatest:
+		return true
+	}
+	return false
+}
+
+// ParseAge parses a vulnerability age such as "7d", "2w" or any duration
+// accepted by time.ParseDuration. An empty string is a zero age.
+func ParseAge(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+
+	var unit time.Duration
+	switch s[len(s)-1] {
+	case 'd':
+		unit = 24 * time.Hour
+	case 'w':
+		unit = 7 * 24 * time.Hour
+	default:
+		d, err := time.ParseDuration(s)
+		if err != nil || d < 0 {
+			return 0, fmt.Errorf("invalid age %q (e.g. 7d, 2w, 36h)", s)
+		}
+		return d, nil
+	}
+
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid age %q (e.g. 7d, 2w, 36h)", s)
+	}
+	return time.Duration(n) * unit, nil
+}
+
+// ParseSize parses a memory size such as "512MiB", "2G" or a number of
+// bytes. Units are powers of 1024. An empty string is a zero size.
+func ParseSize(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+
+	number := strings.TrimRight(s, "KMGTiBkmgtib")
+	unit := strings.ToUpper(strings.TrimSuffix(strings.TrimSuffix(s[len(number):], "B"), "b"))
+	unit = strings.TrimSuffix(unit, "I")
+	shifts := map[string]uint{"": 0, "K": 10, "M": 20, "G": 30, "T": 40}
+	shift, ok := shifts[unit]
+	n, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+	if !ok || err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q (e.g. 512MiB, 2GiB)", s)
+	}
+	return int64(n * float64(uint64(1)<<shift)), nil
 }
 
 // AIConfig holds configuration for the AI provider used for VEX generation
@@ -45,25 +460,600 @@ type AIConfig struct {
 	// APIKey is the API key for the AI provider
 	APIKey string `mapstructure:"api-key"`
 
+	// APIKeyFile is a file APIKey is read from if not set
+	APIKeyFile string `mapstructure:"api-key-file"`
+
 	// Endpoint is the API endpoint (OpenAI-compatible)
 	Endpoint string `mapstructure:"endpoint"`
 
 	// Model is the model identifier to use
 	Model string `mapstructure:"model"`
+
+	// MinConfidenceForNotAffected is the confidence (0 to 1) the AI must
+	// state for a not_affected status; below it, the statement is
+	// downgraded to under_investigation. 0 accepts any confidence.
+	MinConfidenceForNotAffected float64 `mapstructure:"min-confidence-for-not-affected"`
+
+	// ConsensusModel, if set, is asked as well about CRITICAL
+	// vulnerabilities the model answers not_affected for; without its
+	// agreement the statement is downgraded to under_investigation
+	ConsensusModel string `mapstructure:"consensus-model"`
+
+	// ConsensusEndpoint is the endpoint of ConsensusModel, using the same
+	// API key; empty uses Endpoint
+	ConsensusEndpoint string `mapstructure:"consensus-endpoint"`
+
+	// Stream requests completions as streams, showing progress while
+	// justifications are generated and stopping them on interrupt
+	Stream bool `mapstructure:"stream"`
+
+	// BatchSize is the number of vulnerabilities sent per VEX
+	// justification request; 0 or 1 sends one request per vulnerability
+	BatchSize int `mapstructure:"batch-size"`
+
+	// Triage asks the AI for a remediation of each failed update
+	Triage bool `mapstructure:"triage"`
+
+	// DryRun prints the AI requests instead of sending them, so no API key
+	// is needed; the results are those of failed requests
+	DryRun bool `mapstructure:"dry-run"`
+
+	// Prompts replaces the built-in prompts with template files
+	Prompts AIPromptsConfig `mapstructure:"prompts"`
+
+	// AuditLog is a file every AI prompt and response is appended to as a
+	// JSON line, with credentials redacted; empty disables the audit log
+	AuditLog string `mapstructure:"audit-log"`
+}
+
+// AIPromptsConfig holds the paths of prompt template files (text/template)
+type AIPromptsConfig struct {
+	// VEXSystem is the system prompt of VEX justifications
+	VEXSystem string `mapstructure:"vex-system"`
+
+	// VEXUser is the user prompt of VEX justifications, with the variables
+	// .VulnerabilityID, .Package, .Description and .DependencyChain
+	VEXUser string `mapstructure:"vex-user"`
+}
+
+// ForgeConfig holds settings for the code hosting provider (GitHub, GitLab)
+type ForgeConfig struct {
+	// Provider is github or gitlab; empty disables forge integration
+	Provider string `mapstructure:"provider"`
+
+	// Repo is "owner/name" on GitHub or the project path on GitLab
+	// (default: GITHUB_REPOSITORY or CI_PROJECT_PATH)
+	Repo string `mapstructure:"repo"`
+
+	// Token authenticates API requests (default: GITHUB_TOKEN or GITLAB_TOKEN)
+	Token string `mapstructure:"token"`
+
+	// TokenFile is a file Token is read from if not set
+	TokenFile string `mapstructure:"token-file"`
+
+	// URL is the API base URL for GitHub Enterprise or self-hosted GitLab
+	URL string `mapstructure:"url"`
+
+	// AppID, if set, authenticates with GitHub as an installation of this
+	// GitHub App instead of with Token
+	AppID int64 `mapstructure:"app-id"`
+
+	// AppInstallationID is the installation of the app (default: its
+	// installation on Repo)
+	AppInstallationID int64 `mapstructure:"app-installation-id"`
+
+	// AppPrivateKey is the PEM-encoded private key of the app
+	AppPrivateKey string `mapstructure:"app-private-key"`
+
+	// AppPrivateKeyFile is a file AppPrivateKey is read from if not set
+	AppPrivateKeyFile string `mapstructure:"app-private-key-file"`
+
+	// RateLimit caps the forge API requests per second of a run (0: no
+	// limit), e.g. to stay clear of abuse detection in fleet runs
+	RateLimit float64 `mapstructure:"rate-limit"`
+
+	// PullRequest is the pull/merge request the run belongs to; when set,
+	// comments go there instead of new issues
+	PullRequest int `mapstructure:"pull-request"`
+
+	// SummaryComment keeps a comment with the findings and applied fixes of
+	// scan and update runs on PullRequest, updated by every run
+	SummaryComment bool `mapstructure:"summary-comment"`
+
+	// StatusCheck publishes the vulnerabilities left after scan and update
+	// runs as a commit status, failed while any remain, so that branch
+	// protection rules can require it
+	StatusCheck bool `mapstructure:"status-check"`
+
+	// StatusContext names the commit status
+	StatusContext string `mapstructure:"status-context"`
+
+	// Commit is the commit the status is set on (default: the head of the
+	// pull request of the CI job, GITHUB_SHA, CI_COMMIT_SHA or HEAD of Path)
+	Commit string `mapstructure:"commit"`
+}
+
+// JiraConfig holds settings for Jira remediation tickets
+type JiraConfig struct {
+	// URL is the Jira site; empty disables the integration
+	URL string `mapstructure:"url"`
+
+	// Project is the key of the project tickets are created in
+	Project string `mapstructure:"project"`
+
+	// IssueType is the type of created tickets
+	IssueType string `mapstructure:"issue-type"`
+
+	// User is the account email for Jira Cloud API tokens; leave empty to
+	// use Token as a Data Center personal access token
+	User string `mapstructure:"user"`
+
+	// Token authenticates API requests (default: JIRA_API_TOKEN)
+	Token string `mapstructure:"token"`
+
+	// TokenFile is a file Token is read from if not set
+	TokenFile string `mapstructure:"token-file"`
+
+	// Labels are added to created tickets, next to go-autobump
+	Labels []string `mapstructure:"labels"`
+}
+
+// VEXMetadataConfig holds the authorship metadata of VEX documents
+type VEXMetadataConfig struct {
+	// Author is the organization or person issuing the document
+	Author string `mapstructure:"author"`
+
+	// Role is the author's role, e.g. "Document Creator" or "Vendor"
+	Role string `mapstructure:"role"`
+
+	// Tooling names the tool that produced the document
+	Tooling string `mapstructure:"tooling"`
+
+	// IDPrefix is prepended to the generation timestamp to form the
+	// document ID, an IRI under the author's control
+	IDPrefix string `mapstructure:"id-prefix"`
+
+	// Supplier is the supplier of the products named in statements
+	Supplier string `mapstructure:"supplier"`
+}
+
+// DefectDojoConfig holds settings for uploading findings to DefectDojo
+type DefectDojoConfig struct {
+	// URL is the DefectDojo instance; empty disables the upload
+	URL string `mapstructure:"url"`
+
+	// Token is the API v2 key (default: DEFECTDOJO_API_KEY)
+	Token string `mapstructure:"token"`
+
+	// TokenFile is a file Token is read from if not set
+	TokenFile string `mapstructure:"token-file"`
+
+	// ProductType, Product and Engagement receive the findings and are
+	// created if they do not exist
+	ProductType string `mapstructure:"product-type"`
+	Product     string `mapstructure:"product"`
+	Engagement  string `mapstructure:"engagement"`
+}
+
+// DependencyTrackConfig holds settings for uploading to Dependency-Track
+type DependencyTrackConfig struct {
+	// URL is the Dependency-Track API server; empty disables the upload
+	URL string `mapstructure:"url"`
+
+	// APIKey authenticates uploads (default: DEPENDENCY_TRACK_API_KEY)
+	APIKey string `mapstructure:"api-key"`
+
+	// APIKeyFile is a file APIKey is read from if not set
+	APIKeyFile string `mapstructure:"api-key-file"`
+
+	// Project and Version identify the project, created if it does not exist
+	Project string `mapstructure:"project"`
+	Version string `mapstructure:"version"`
+}
+
+// JobConfig holds settings for unattended runs, e.g. as a Kubernetes CronJob
+type JobConfig struct {
+	// Repo is a local path or a git URL to clone (default: path)
+	Repo string `mapstructure:"repo"`
+
+	// Ref is the branch or tag to clone (default: the remote's default branch)
+	Ref string `mapstructure:"ref"`
+
+	// Mode is what the job does: scan or update
+	Mode string `mapstructure:"mode"`
+
+	// ReadyCheck validates go, trivy and Trivy DB access before starting
+	ReadyCheck bool `mapstructure:"ready-check"`
+
+	// Sparse clones only the Go module files and package directories of
+	// Repo, and the contents of SparsePaths
+	Sparse bool `mapstructure:"sparse"`
+
+	// SparsePaths are further files or directories a sparse clone checks
+	// out, as .gitignore patterns
+	SparsePaths []string `mapstructure:"sparse-paths"`
+}
+
+// FleetConfig selects the repositories of a fleet run and what it does
+type FleetConfig struct {
+	// GitHubOrg is a GitHub organization whose Go repositories are discovered
+	GitHubOrg string `mapstructure:"github-org"`
+
+	// Topics keeps only discovered repositories carrying all of them
+	Topics []string `mapstructure:"topics"`
+
+	// IncludeForks keeps forked repositories in discovery
+	IncludeForks bool `mapstructure:"include-forks"`
+
+	// IncludeArchived keeps archived repositories in discovery
+	IncludeArchived bool `mapstructure:"include-archived"`
+
+	// ReposFile lists repositories to run on, one path or git URL per line
+	ReposFile string `mapstructure:"repos-file"`
+
+	// Mode is what the job of each repository does: scan or update
+	Mode string `mapstructure:"mode"`
+
+	// Report, if set, receives the report aggregating the jobs of a run
+	Report string `mapstructure:"report"`
+
+	// ReportFormat is the format of Report: json, markdown or html
+	ReportFormat string `mapstructure:"report-format"`
+
+	// Concurrency is the number of jobs run at the same time, bounding the
+	// concurrent clones and the disk and network they use
+	Concurrency int `mapstructure:"concurrency"`
+
+	// StartInterval is the minimum time between the starts of two jobs
+	StartInterval time.Duration `mapstructure:"start-interval"`
+
+	// JobTimeout bounds the time of the job of each repository (0: no limit)
+	JobTimeout time.Duration `mapstructure:"job-timeout"`
+}
+
+// WatchConfig configures the watch command, which rescans on a schedule and
+// alerts on vulnerabilities the previous scan did not find, without updating
+type WatchConfig struct {
+	// Interval is the time between scans; 0 scans once, e.g. from a CronJob
+	Interval time.Duration `mapstructure:"interval"`
+
+	// StateFile records the vulnerabilities of the last scan, which the
+	// next scan is compared to
+	StateFile string `mapstructure:"state-file"`
+
+	// WebhookURL, if set, receives a JSON alert when new vulnerabilities
+	// appear, e.g. a Slack or Mattermost incoming webhook
+	WebhookURL string `mapstructure:"webhook-url"`
+
+	// WebhookURLFile is a file WebhookURL is read from if not set
+	WebhookURLFile string `mapstructure:"webhook-url-file"`
+}
+
+// AliasesConfig adds to the aliases the scanners report with their findings
+type AliasesConfig struct {
+	// OSV looks up the aliases of the vulnerabilities found on osv.dev
+	OSV bool `mapstructure:"osv"`
+
+	// Table lists advisories followed by their aliases, e.g.
+	// [GHSA-xxxx-xxxx-xxxx, CVE-2024-1234], for advisories neither the
+	// scanners nor OSV relate
+	Table [][]string `mapstructure:"table"`
+}
+
+// ExploitsConfig selects the sources of exploit maturity
+type ExploitsConfig struct {
+	// Enabled looks up the exploit maturity of the findings
+	Enabled bool `mapstructure:"enabled"`
+
+	// Sources are the catalogs consulted: cisa-kev, vulncheck-kev and
+	// exploit-db
+	Sources []string `mapstructure:"sources"`
+
+	// VulnCheckToken authenticates to the VulnCheck API, needed for
+	// vulncheck-kev (default: VULNCHECK_API_TOKEN)
+	VulnCheckToken string `mapstructure:"vulncheck-token"`
+
+	// VulnCheckTokenFile is a file VulnCheckToken is read from if not set
+	VulnCheckTokenFile string `mapstructure:"vulncheck-token-file"`
+
+	// ExploitDBURL is the CSV index of Exploit-DB, e.g. of a mirror
+	ExploitDBURL string `mapstructure:"exploit-db-url"`
+}
+
+// CVSSOverrideConfig sets the CVSS threshold of matching packages
+type CVSSOverrideConfig struct {
+	// Packages are module path patterns like those of lockstep groups; of
+	// several matching overrides, the longest matching pattern wins
+	Packages []string `mapstructure:"packages"`
+
+	// Threshold is the minimum CVSS score to act on for these packages
+	Threshold float64 `mapstructure:"threshold"`
+}
+
+// DevDependenciesConfig de-prioritizes vulnerabilities of modules that no
+// non-test package imports, such as test frameworks and tools.go tools
+type DevDependenciesConfig struct {
+	// Threshold is the minimum CVSS score to act on for such modules; 0
+	// treats them like other dependencies
+	Threshold float64 `mapstructure:"threshold"`
+
+	// Skip ignores their vulnerabilities altogether
+	Skip bool `mapstructure:"skip"`
+}
+
+// LockstepGroupConfig names modules that must be updated together
+type LockstepGroupConfig struct {
+	// Name identifies the group in log output
+	Name string `mapstructure:"name"`
+
+	// Modules are module path patterns, e.g. "k8s.io/*" or
+	// "google.golang.org/genproto*"; a trailing "*" also matches nested paths
+	Modules []string `mapstructure:"modules"`
+}
+
+// CommitConfig commits the go.mod, go.sum and vendor changes of update runs
+type CommitConfig struct {
+	// Enabled commits the changes once an update run completes
+	Enabled bool `mapstructure:"enabled"`
+
+	// Message is the subject of the commit; its body lists the fixes
+	Message string `mapstructure:"message"`
+
+	// Author is "Name <email>", e.g. a bot identity (default: git's
+	// user.name and user.email)
+	Author string `mapstructure:"author"`
+
+	// Committer is "Name <email>" (default: Author)
+	Committer string `mapstructure:"committer"`
+
+	// SigningKey, if set, signs the commit: a GPG key ID, or with
+	// SigningFormat ssh an SSH key file
+	SigningKey string `mapstructure:"signing-key"`
+
+	// SigningFormat is openpgp, ssh or x509
+	SigningFormat string `mapstructure:"signing-format"`
+}
+
+// Commit signing formats, as of git's gpg.format
+const (
+	SigningFormatOpenPGP = "openpgp"
+	SigningFormatSSH     = "ssh"
+	SigningFormatX509    = "x509"
+)
+
+// ValidSigningFormat reports whether format is a known signing format
+func ValidSigningFormat(format string) bool {
+	switch format {
+	case SigningFormatOpenPGP, SigningFormatSSH, SigningFormatX509:
+		return true
+	}
+	return false
+}
+
+// ModuleCheckConfig configures the check of the module paths updates add
+type ModuleCheckConfig struct {
+	// Enabled refuses to add a module that is neither required yet nor the
+	// advisory's module or one of its major version lines, or that differs
+	// from a known module only in case
+	Enabled bool `mapstructure:"enabled"`
+
+	// Confirmed lists module paths a human checked and confirmed as intended
+	Confirmed []string `mapstructure:"confirmed"`
+}
+
+// ScorecardConfig sets a floor for the OpenSSF Scorecard score, looked up on
+// deps.dev, of the source repositories of the versions updates move to
+type ScorecardConfig struct {
+	// MinScore is the floor from 0 to 10; 0 disables the check
+	MinScore float64 `mapstructure:"min-score"`
+
+	// Action is what happens below the floor: warn, or block the update
+	Action string `mapstructure:"action"`
+
+	// Endpoint is the deps.dev API
+	Endpoint string `mapstructure:"endpoint"`
+}
+
+// Actions on versions scoring below the Scorecard floor
+const (
+	ScorecardActionWarn  = "warn"
+	ScorecardActionBlock = "block"
+)
+
+// ValidScorecardAction reports whether action is a known Scorecard action
+func ValidScorecardAction(action string) bool {
+	switch action {
+	case ScorecardActionWarn, ScorecardActionBlock:
+		return true
+	}
+	return false
+}
+
+// AttestationConfig configures the in-toto attestation describing what an
+// update run changed: the advisories acted on and the tools used as inputs,
+// the digests of the changed go.mod and go.sum files as subjects
+type AttestationConfig struct {
+	// File, if set, receives the attestation of each update run that
+	// changed files
+	File string `mapstructure:"file"`
+
+	// SigningKey, if set, is a PEM private key file (ECDSA, Ed25519 or RSA)
+	// signing the attestation, which is then written as a DSSE envelope
+	SigningKey string `mapstructure:"signing-key"`
+}
+
+// MitigationsConfig configures temporary replace directives for
+// vulnerabilities without a fixed release
+type MitigationsConfig struct {
+	// Enabled applies the replacements during updates
+	Enabled bool `mapstructure:"enabled"`
+
+	// StateFile records the replacements applied, so that later runs can
+	// remind to remove them once upstream releases a new version
+	StateFile string `mapstructure:"state-file"`
+
+	// Cleanup removes recorded replacements during updates once upstream
+	// releases a version that Trivy confirms fixes their vulnerabilities
+	Cleanup bool `mapstructure:"cleanup"`
+
+	// Replacements are the patched forks or commits available
+	Replacements []ReplacementConfig `mapstructure:"replacements"`
+}
+
+// ReplacementConfig is a patched fork or commit of a vulnerable module
+type ReplacementConfig struct {
+	// Module is the path of the vulnerable module
+	Module string `mapstructure:"module"`
+
+	// Vulnerabilities the replacement fixes; empty means any
+	Vulnerabilities []string `mapstructure:"vulnerabilities"`
+
+	// With is the module path of the fork (default: Module itself, to pin
+	// an unreleased commit)
+	With string `mapstructure:"with"`
+
+	// Version is a version, pseudo-version, commit or branch of With,
+	// resolved to a version when applied
+	Version string `mapstructure:"version"`
+}
+
+// GoReleasesConfig configures where Go releases and their vulnerabilities
+// are looked up, and which declared Go versions are bumped
+type GoReleasesConfig struct {
+	// ReleaseFeed is the JSON feed of Go releases
+	ReleaseFeed string `mapstructure:"release-feed"`
+
+	// VulnDB is the Go vulnerability database
+	VulnDB string `mapstructure:"vuln-db"`
+
+	// VersionFiles also checks the Go versions pinned outside go.mod:
+	// .go-version, .tool-versions, GitHub Actions workflows and golang images
+	VersionFiles bool `mapstructure:"version-files"`
+}
+
+// CompatibilityRuleConfig names modules whose versions must stay coherent
+type CompatibilityRuleConfig struct {
+	// Name identifies the rule in error messages
+	Name string `mapstructure:"name"`
+
+	// Modules are module path patterns, like those of lockstep groups
+	Modules []string `mapstructure:"modules"`
+
+	// Match is what the members must share: "minor" (major and minor
+	// version, the default) or "exact"
+	Match string `mapstructure:"match"`
+}
+
+// PluginConfig configures an exec-based plugin
+type PluginConfig struct {
+	// Name identifies the plugin in log output (default: the command)
+	Name string `mapstructure:"name"`
+
+	// Command is the executable to run, with optional arguments
+	Command string   `mapstructure:"command"`
+	Args    []string `mapstructure:"args"`
+
+	// Hooks lists the hook points the plugin is invoked at
+	Hooks []string `mapstructure:"hooks"`
+
+	// Timeout bounds a single invocation (default: 5m)
+	Timeout time.Duration `mapstructure:"timeout"`
+
+	// Required makes a plugin failure abort the run instead of warning
+	Required bool `mapstructure:"required"`
 }
 
 // Default returns a Config with default values
 func Default() *Config {
 	return &Config{
-		Path:              ".",
-		Exclude:           []string{},
-		CVSSThreshold:     7.0,
-		SkipTidy:          false,
-		DryRun:            false,
-		AllowMajor:        false,
-		GenerateVEX:       false,
-		SkipTrivyDBUpdate: false,
-		VEXOutput:         ".vex.openvex.json",
+		Path:                      ".",
+		Exclude:                   []string{},
+		CVSSThreshold:             7.0,
+		SkipTidy:                  false,
+		DryRun:                    false,
+		AllowMajor:                false,
+		MajorApproval:             false,
+		Atomic:                    false,
+		Worktree:                  false,
+		AlignVersions:             false,
+		AllPatch:                  false,
+		BuilderImages:             false,
+		BuiltinCompatibilityRules: true,
+		Batch:                     false,
+		Strategy:                  StrategyLatest,
+		FixVersion:                FixVersionFixed,
+		RespectBotConfig:          true,
+		RiskScore:                 false,
+		ImpactAnalysis:            false,
+		GenerateVEX:               false,
+		SkipTrivyDBUpdate:         false,
+		SkipEmptyModules:          true,
+		TrivyRetries:              2,
+		TrivyRetryBackoff:         5 * time.Second,
+		TrivyVersionCheck:         TrivyVersionCheckWarn,
+		GoBinary:                  "go",
+		TrivyBinary:               "trivy",
+		WarmModCache:              false,
+		VEXOutput:                 ".vex.openvex.json",
+		VEXReviewQueue:            ".vex.pending.json",
+		LogFormat:                 LogFormatText,
+		GoReleases: GoReleasesConfig{
+			ReleaseFeed:  "https://go.dev/dl/?mode=json&include=all",
+			VulnDB:       "https://vuln.go.dev",
+			VersionFiles: true,
+		},
+		Mitigations: MitigationsConfig{
+			StateFile: ".autobump-state.json",
+			Cleanup:   true,
+		},
+		Job: JobConfig{
+			Mode:        JobModeUpdate,
+			ReadyCheck:  true,
+			SparsePaths: []string{},
+		},
+		Fleet: FleetConfig{
+			Topics:       []string{},
+			Mode:         JobModeUpdate,
+			ReportFormat: "json",
+			Concurrency:  1,
+		},
+		Watch: WatchConfig{
+			StateFile: ".autobump.watch.json",
+		},
+		VEXMetadata: VEXMetadataConfig{
+			Author:   "go-autobump",
+			Tooling:  "go-autobump",
+			IDPrefix: "https://go-autobump/vex/",
+		},
+		ModuleCheck: ModuleCheckConfig{
+			Enabled:   true,
+			Confirmed: []string{},
+		},
+		Scorecard: ScorecardConfig{
+			Action:   ScorecardActionWarn,
+			Endpoint: "https://api.deps.dev/v3",
+		},
+		Commit: CommitConfig{
+			Message:       "fix(deps): update vulnerable dependencies",
+			SigningFormat: SigningFormatOpenPGP,
+		},
+		Forge: ForgeConfig{
+			StatusContext: "go-autobump",
+		},
+		Jira: JiraConfig{
+			IssueType: "Bug",
+		},
+		DefectDojo: DefectDojoConfig{
+			ProductType: "go-autobump",
+			Engagement:  "go-autobump",
+		},
+		DependencyTrack: DependencyTrackConfig{
+			Version: "latest",
+		},
+		Exploits: ExploitsConfig{
+			Sources:      []string{"cisa-kev", "exploit-db"},
+			ExploitDBURL: "https://gitlab.com/exploit-database/exploitdb/-/raw/main/files_exploits.csv",
+		},
 		AI: AIConfig{
 			Endpoint: "https://api.openai.com/v1",
 			Model:    "gpt-4o",
@@ -75,16 +1065,174 @@ func Default() *Config {
 func SetupViper() {
 	// Set default values
 	defaults := Default()
+	viper.SetDefault("profile", defaults.Profile)
 	viper.SetDefault("path", defaults.Path)
 	viper.SetDefault("exclude", defaults.Exclude)
 	viper.SetDefault("cvss-threshold", defaults.CVSSThreshold)
+	viper.SetDefault("cvss-overrides", defaults.CVSSOverrides)
+	viper.SetDefault("dev-dependencies.threshold", defaults.DevDependencies.Threshold)
+	viper.SetDefault("dev-dependencies.skip", defaults.DevDependencies.Skip)
+	viper.SetDefault("min-age", defaults.MinAge)
+	viper.SetDefault("max-age", defaults.MaxAge)
 	viper.SetDefault("skip-tidy", defaults.SkipTidy)
 	viper.SetDefault("dry-run", defaults.DryRun)
 	viper.SetDefault("allow-major", defaults.AllowMajor)
+	viper.SetDefault("atomic", defaults.Atomic)
+	viper.SetDefault("lockstep-groups", defaults.LockstepGroups)
+	viper.SetDefault("compatibility-rules", defaults.CompatibilityRules)
+	viper.SetDefault("worktree", defaults.Worktree)
+	viper.SetDefault("batch", defaults.Batch)
+	viper.SetDefault("align-versions", defaults.AlignVersions)
+	viper.SetDefault("all-patch", defaults.AllPatch)
+	viper.SetDefault("builtin-compatibility-rules", defaults.BuiltinCompatibilityRules)
+	viper.SetDefault("commit.enabled", defaults.Commit.Enabled)
+	viper.SetDefault("commit.message", defaults.Commit.Message)
+	viper.SetDefault("commit.author", defaults.Commit.Author)
+	viper.SetDefault("commit.committer", defaults.Commit.Committer)
+	viper.SetDefault("commit.signing-key", defaults.Commit.SigningKey)
+	viper.SetDefault("commit.signing-format", defaults.Commit.SigningFormat)
+	viper.SetDefault("module-check.enabled", defaults.ModuleCheck.Enabled)
+	viper.SetDefault("module-check.confirmed", defaults.ModuleCheck.Confirmed)
+	viper.SetDefault("scorecard.min-score", defaults.Scorecard.MinScore)
+	viper.SetDefault("scorecard.action", defaults.Scorecard.Action)
+	viper.SetDefault("scorecard.endpoint", defaults.Scorecard.Endpoint)
+	viper.SetDefault("attestation.file", defaults.Attestation.File)
+	viper.SetDefault("attestation.signing-key", defaults.Attestation.SigningKey)
+	viper.SetDefault("builder-images", defaults.BuilderImages)
+	viper.SetDefault("go-releases.release-feed", defaults.GoReleases.ReleaseFeed)
+	viper.SetDefault("go-releases.vuln-db", defaults.GoReleases.VulnDB)
+	viper.SetDefault("go-releases.version-files", defaults.GoReleases.VersionFiles)
+	viper.SetDefault("mitigations.enabled", defaults.Mitigations.Enabled)
+	viper.SetDefault("mitigations.state-file", defaults.Mitigations.StateFile)
+	viper.SetDefault("mitigations.cleanup", defaults.Mitigations.Cleanup)
+	viper.SetDefault("mitigations.replacements", defaults.Mitigations.Replacements)
+	viper.SetDefault("strategy", defaults.Strategy)
+	viper.SetDefault("fix-version", defaults.FixVersion)
+	viper.SetDefault("respect-bot-config", defaults.RespectBotConfig)
+	viper.SetDefault("risk-score", defaults.RiskScore)
+	viper.SetDefault("impact-analysis", defaults.ImpactAnalysis)
+	viper.SetDefault("timeout", defaults.Timeout)
+	viper.SetDefault("scan-timeout", defaults.ScanTimeout)
+	viper.SetDefault("update-timeout", defaults.UpdateTimeout)
+	viper.SetDefault("limits.memory", defaults.Limits.Memory)
+	viper.SetDefault("limits.cpus", defaults.Limits.CPUs)
+	viper.SetDefault("limits.go-timeout", defaults.Limits.GoTimeout)
+	viper.SetDefault("limits.cgroup", defaults.Limits.Cgroup)
 	viper.SetDefault("generate-vex", defaults.GenerateVEX)
 	viper.SetDefault("vex-output", defaults.VEXOutput)
+	viper.SetDefault("vex-products", defaults.VEXProducts)
+	viper.SetDefault("vex-review", defaults.VEXReview)
+	viper.SetDefault("vex-review-queue", defaults.VEXReviewQueue)
+	viper.SetDefault("vex-metadata.author", defaults.VEXMetadata.Author)
+	viper.SetDefault("vex-metadata.role", defaults.VEXMetadata.Role)
+	viper.SetDefault("vex-metadata.tooling", defaults.VEXMetadata.Tooling)
+	viper.SetDefault("vex-metadata.id-prefix", defaults.VEXMetadata.IDPrefix)
+	viper.SetDefault("vex-metadata.supplier", defaults.VEXMetadata.Supplier)
+	viper.SetDefault("purl-preserve-case", defaults.PURLPreserveCase)
+	viper.SetDefault("osv-scanner-config", defaults.OSVScannerConfig)
+	viper.SetDefault("baseline", defaults.Baseline)
+	viper.SetDefault("from-snyk", defaults.FromSnyk)
+	viper.SetDefault("report-file", defaults.ReportFile)
+	viper.SetDefault("quiet", defaults.Quiet)
+	viper.SetDefault("no-emoji", defaults.NoEmoji)
+	viper.SetDefault("no-color", defaults.NoColor)
+	viper.SetDefault("plugins", defaults.Plugins)
+	viper.SetDefault("skip-empty-modules", defaults.SkipEmptyModules)
+	viper.SetDefault("skip-trivy-db-update", defaults.SkipTrivyDBUpdate)
+	viper.SetDefault("trivy.ignore-unfixed", defaults.Trivy.IgnoreUnfixed)
+	viper.SetDefault("trivy.db-repository", defaults.Trivy.DBRepositories)
+	viper.SetDefault("trivy.parallel", defaults.Trivy.Parallel)
+	viper.SetDefault("trivy.extra-args", defaults.Trivy.ExtraArgs)
+	viper.SetDefault("trivy-retries", defaults.TrivyRetries)
+	viper.SetDefault("trivy-retry-backoff", defaults.TrivyRetryBackoff)
+	viper.SetDefault("trivy-version-check", defaults.TrivyVersionCheck)
+	viper.SetDefault("go-binary", defaults.GoBinary)
+	viper.SetDefault("go-toolchain", defaults.GoToolchain)
+	viper.SetDefault("go-mod-cache", defaults.GoModCache)
+	viper.SetDefault("env", defaults.Env)
+	viper.SetDefault("trivy-binary", defaults.TrivyBinary)
+	viper.SetDefault("warm-mod-cache", defaults.WarmModCache)
+	viper.SetDefault("log-format", defaults.LogFormat)
+	viper.SetDefault("job.repo", defaults.Job.Repo)
+	viper.SetDefault("job.ref", defaults.Job.Ref)
+	viper.SetDefault("job.mode", defaults.Job.Mode)
+	viper.SetDefault("job.ready-check", defaults.Job.ReadyCheck)
+	viper.SetDefault("job.sparse", defaults.Job.Sparse)
+	viper.SetDefault("job.sparse-paths", defaults.Job.SparsePaths)
+	viper.SetDefault("fleet.github-org", defaults.Fleet.GitHubOrg)
+	viper.SetDefault("fleet.topics", defaults.Fleet.Topics)
+	viper.SetDefault("fleet.include-forks", defaults.Fleet.IncludeForks)
+	viper.SetDefault("fleet.include-archived", defaults.Fleet.IncludeArchived)
+	viper.SetDefault("fleet.repos-file", defaults.Fleet.ReposFile)
+	viper.SetDefault("fleet.mode", defaults.Fleet.Mode)
+	viper.SetDefault("fleet.report", defaults.Fleet.Report)
+	viper.SetDefault("fleet.report-format", defaults.Fleet.ReportFormat)
+	viper.SetDefault("fleet.concurrency", defaults.Fleet.Concurrency)
+	viper.SetDefault("fleet.start-interval", defaults.Fleet.StartInterval)
+	viper.SetDefault("fleet.job-timeout", defaults.Fleet.JobTimeout)
+	viper.SetDefault("aliases.osv", defaults.Aliases.OSV)
+	viper.SetDefault("aliases.table", defaults.Aliases.Table)
+	viper.SetDefault("exploits.enabled", defaults.Exploits.Enabled)
+	viper.SetDefault("exploits.sources", defaults.Exploits.Sources)
+	viper.SetDefault("exploits.vulncheck-token", defaults.Exploits.VulnCheckToken)
+	viper.SetDefault("exploits.vulncheck-token-file", defaults.Exploits.VulnCheckTokenFile)
+	viper.SetDefault("exploits.exploit-db-url", defaults.Exploits.ExploitDBURL)
+	viper.SetDefault("watch.interval", defaults.Watch.Interval)
+	viper.SetDefault("watch.state-file", defaults.Watch.StateFile)
+	viper.SetDefault("watch.webhook-url", defaults.Watch.WebhookURL)
+	viper.SetDefault("watch.webhook-url-file", defaults.Watch.WebhookURLFile)
+	viper.SetDefault("major-approval", defaults.MajorApproval)
+	viper.SetDefault("track-unfixed", defaults.TrackUnfixed)
+	viper.SetDefault("forge.provider", defaults.Forge.Provider)
+	viper.SetDefault("forge.repo", defaults.Forge.Repo)
+	viper.SetDefault("forge.token", defaults.Forge.Token)
+	viper.SetDefault("forge.token-file", defaults.Forge.TokenFile)
+	viper.SetDefault("forge.url", defaults.Forge.URL)
+	viper.SetDefault("forge.app-id", defaults.Forge.AppID)
+	viper.SetDefault("forge.app-installation-id", defaults.Forge.AppInstallationID)
+	viper.SetDefault("forge.app-private-key", defaults.Forge.AppPrivateKey)
+	viper.SetDefault("forge.app-private-key-file", defaults.Forge.AppPrivateKeyFile)
+	viper.SetDefault("forge.rate-limit", defaults.Forge.RateLimit)
+	viper.SetDefault("forge.pull-request", defaults.Forge.PullRequest)
+	viper.SetDefault("forge.summary-comment", defaults.Forge.SummaryComment)
+	viper.SetDefault("forge.status-check", defaults.Forge.StatusCheck)
+	viper.SetDefault("forge.status-context", defaults.Forge.StatusContext)
+	viper.SetDefault("forge.commit", defaults.Forge.Commit)
+	viper.SetDefault("jira.url", defaults.Jira.URL)
+	viper.SetDefault("jira.project", defaults.Jira.Project)
+	viper.SetDefault("jira.issue-type", defaults.Jira.IssueType)
+	viper.SetDefault("jira.user", defaults.Jira.User)
+	viper.SetDefault("jira.token", defaults.Jira.Token)
+	viper.SetDefault("jira.token-file", defaults.Jira.TokenFile)
+	viper.SetDefault("jira.labels", defaults.Jira.Labels)
+	viper.SetDefault("defectdojo.url", defaults.DefectDojo.URL)
+	viper.SetDefault("defectdojo.token", defaults.DefectDojo.Token)
+	viper.SetDefault("defectdojo.token-file", defaults.DefectDojo.TokenFile)
+	viper.SetDefault("defectdojo.product-type", defaults.DefectDojo.ProductType)
+	viper.SetDefault("defectdojo.product", defaults.DefectDojo.Product)
+	viper.SetDefault("defectdojo.engagement", defaults.DefectDojo.Engagement)
+	viper.SetDefault("dependency-track.url", defaults.DependencyTrack.URL)
+	viper.SetDefault("dependency-track.api-key", defaults.DependencyTrack.APIKey)
+	viper.SetDefault("dependency-track.api-key-file", defaults.DependencyTrack.APIKeyFile)
+	viper.SetDefault("dependency-track.project", defaults.DependencyTrack.Project)
+	viper.SetDefault("dependency-track.version", defaults.DependencyTrack.Version)
+	viper.SetDefault("secret-command", defaults.SecretCommand)
+	viper.SetDefault("tls.ca-file", defaults.TLS.CAFile)
+	viper.SetDefault("tls.insecure-skip-verify", defaults.TLS.InsecureSkipVerify)
+	viper.SetDefault("ai.api-key", defaults.AI.APIKey)
+	viper.SetDefault("ai.api-key-file", defaults.AI.APIKeyFile)
 	viper.SetDefault("ai.endpoint", defaults.AI.Endpoint)
 	viper.SetDefault("ai.model", defaults.AI.Model)
+	viper.SetDefault("ai.triage", defaults.AI.Triage)
+	viper.SetDefault("ai.dry-run", defaults.AI.DryRun)
+	viper.SetDefault("ai.batch-size", defaults.AI.BatchSize)
+	viper.SetDefault("ai.stream", defaults.AI.Stream)
+	viper.SetDefault("ai.consensus-model", defaults.AI.ConsensusModel)
+	viper.SetDefault("ai.consensus-endpoint", defaults.AI.ConsensusEndpoint)
+	viper.SetDefault("ai.min-confidence-for-not-affected", defaults.AI.MinConfidenceForNotAffected)
+	viper.SetDefault("ai.audit-log", defaults.AI.AuditLog)
+	viper.SetDefault("ai.prompts.vex-system", defaults.AI.Prompts.VEXSystem)
+	viper.SetDefault("ai.prompts.vex-user", defaults.AI.Prompts.VEXUser)
 
 	// Config file settings
 	viper.SetConfigName(".autobump")
@@ -92,7 +1240,8 @@ func SetupViper() {
 	viper.AddConfigPath(".")
 	viper.AddConfigPath("$HOME")
 
-	// Environment variable settings
+	// Environment variable settings. Every setting needs a default above to
+	// be read from its AUTOBUMP_* variable.
 	viper.SetEnvPrefix("AUTOBUMP")
 	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_", ".", "_"))
 	viper.AutomaticEnv()
@@ -106,17 +1255,44 @@ func Load() (*Config, error) {
 	_ = viper.ReadInConfig()
 
 	var cfg Config
-	if err := viper.Unmarshal(&cfg); err != nil {
+	if err := viper.Unmarshal(&cfg, viper.DecodeHook(decodeHook)); err != nil {
 		return nil, err
 	}
 
 	return &cfg, nil
 }
 
+// decodeHook decodes settings like Viper does by default, and in addition
+// lists of objects, nested lists and maps given as JSON strings, as
+// AUTOBUMP_* variables have to, e.g. AUTOBUMP_PLUGINS='[{"name": "notify",
+// "command": "./notify.sh"}]'
+var decodeHook = mapstructure.ComposeDecodeHookFunc(
+	jsonStringHook,
+	mapstructure.StringToTimeDurationHookFunc(),
+	mapstructure.StringToSliceHookFunc(","),
+)
+
+// jsonStringHook decodes a JSON array or object given as string for a list
+// or map. Strings that are not valid JSON are left to the other hooks.
+func jsonStringHook(from, to reflect.Type, data any) (any, error) {
+	if from.Kind() != reflect.String || (to.Kind() != reflect.Slice && to.Kind() != reflect.Map) {
+		return data, nil
+	}
+	s := strings.TrimSpace(data.(string))
+	if !strings.HasPrefix(s, "[") && !strings.HasPrefix(s, "{") {
+		return data, nil
+	}
+	var value any
+	if err := json.Unmarshal([]byte(s), &value); err != nil {
+		return data, nil
+	}
+	return value, nil
+}
+
 // Get returns a Config populated from Viper's current state
 func Get() (*Config, error) {
 	var cfg Config
-	if err := viper.Unmarshal(&cfg); err != nil {
+	if err := viper.Unmarshal(&cfg, viper.DecodeHook(decodeHook)); err != nil {
 		return nil, err
 	}
 	return &cfg, nil
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tamcore/go-autobump/internal/redact"
+)
+
+// Mask replaces secrets in Settings
+const Mask = redact.Mask
+
+// secretNames are the parts of the names of map entries, e.g. environment
+// variables, whose values are masked in Settings
+var secretNames = []string{"TOKEN", "KEY", "SECRET", "PASSWORD", "PASSWD", "AUTH", "CREDENTIAL"}
+
+// Settings returns cfg as nested maps keyed like the config file, with
+// secrets, map entries named like secrets (e.g. GITHUB_TOKEN in env) and
+// the userinfo of URLs masked. Unless all is set, only the settings that
+// differ from their defaults are included.
+func Settings(cfg *Config, all bool) map[string]any {
+	settings := settingsOf(reflect.ValueOf(*cfg), "")
+	if !all {
+		pruneDefaults(settings, settingsOf(reflect.ValueOf(*Default()), ""))
+	}
+	return settings
+}
+
+// pruneDefaults removes the settings equal to defaults, and sections left
+// empty, from settings
+func pruneDefaults(settings, defaults map[string]any) {
+	for name, value := range settings {
+		section, ok := value.(map[string]any)
+		if defaultSection, isSection := defaults[name].(map[string]any); ok && isSection {
+			pruneDefaults(section, defaultSection)
+			if len(section) == 0 {
+				delete(settings, name)
+			}
+			continue
+		}
+		if reflect.DeepEqual(value, defaults[name]) {
+			delete(settings, name)
+		}
+	}
+}
+
+func settingsOf(v reflect.Value, prefix string) map[string]any {
+	settings := map[string]any{}
+	for i := 0; i < v.NumField(); i++ {
+		name := v.Type().Field(i).Tag.Get("mapstructure")
+		if name == "" || name == "-" {
+			continue
+		}
+		key := prefix + name
+
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct {
+			settings[name] = settingsOf(field, key+".")
+			continue
+		}
+		if slices.Contains(SecretKeys, key) {
+			if field.String() != "" {
+				settings[name] = Mask
+			} else {
+				settings[name] = ""
+			}
+			continue
+		}
+		settings[name] = valueOf(field)
+	}
+	return settings
+}
+
+// valueOf returns v with structs converted to maps, durations to strings,
+// and secrets masked
+func valueOf(v reflect.Value) any {
+	switch v.Kind() {
+	case reflect.Struct:
+		return settingsOf(v, "")
+	case reflect.Slice:
+		if v.IsNil() {
+			return []any{}
+		}
+		values := make([]any, v.Len())
+		for i := range values {
+			values[i] = valueOf(v.Index(i))
+		}
+		return values
+	case reflect.Map:
+		values := map[string]any{}
+		for _, key := range v.MapKeys() {
+			value := valueOf(v.MapIndex(key))
+			if s, ok := value.(string); ok && s != "" && secretName(key.String()) {
+				value = Mask
+			}
+			values[key.String()] = value
+		}
+		return values
+	case reflect.String:
+		return redact.URLs(v.String())
+	default:
+		if d, ok := v.Interface().(time.Duration); ok {
+			return d.String()
+		}
+		return v.Interface()
+	}
+}
+
+// secretName reports whether name looks like that of a secret, e.g.
+// GITHUB_TOKEN or NPM_AUTH
+func secretName(name string) bool {
+	name = strings.ToUpper(name)
+	return slices.ContainsFunc(secretNames, func(part string) bool { return strings.Contains(name, part) })
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// ApplyProfile merges the settings of the named profile of the config file,
+// under "profiles", over its top-level settings. Nested settings are merged
+// key by key; lists are replaced. Flags and AUTOBUMP_* variables still take
+// precedence. An empty name applies no profile. Call it once before Get.
+func ApplyProfile(name string) error {
+	if name == "" {
+		return nil
+	}
+
+	// Viper lowercases keys, including the profile names
+	profiles := viper.GetStringMap("profiles")
+	settings, ok := profiles[strings.ToLower(name)]
+	if !ok {
+		defined := "none"
+		if len(profiles) > 0 {
+			defined = strings.Join(slices.Sorted(maps.Keys(profiles)), ", ")
+		}
+		return fmt.Errorf("unknown profile %q (defined: %s)", name, defined)
+	}
+
+	values, ok := settings.(map[string]any)
+	if !ok {
+		if settings == nil {
+			return nil
+		}
+		return fmt.Errorf("invalid profile %q: expected a mapping of settings", name)
+	}
+	if _, ok := values["profiles"]; ok {
+		return fmt.Errorf("invalid profile %q: profiles cannot be nested", name)
+	}
+	return viper.MergeConfigMap(values)
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestApplyProfile(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	file := filepath.Join(t.TempDir(), ".autobump.yaml")
+	content := `cvss-threshold: 9.0
+exclude: [examples/**]
+commit:
+  enabled: true
+  message: "fix: {{.Summary}}"
+profiles:
+  nightly:
+    cvss-threshold: 4.0
+    allow-major: true
+    exclude: []
+    commit:
+      message: "chore: nightly"
+  CI:
+    dry-run: true
+`
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	SetupViper()
+	viper.SetConfigFile(file)
+	if err := viper.ReadInConfig(); err != nil {
+		t.Fatal(err)
+	}
+	viper.Set("allow-major", false)
+
+	if err := ApplyProfile("nightly"); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.CVSSThreshold != 4.0 || len(cfg.Exclude) != 0 {
+		t.Errorf("cvss-threshold %v, exclude %v, want the profile's 4.0 and none", cfg.CVSSThreshold, cfg.Exclude)
+	}
+	if !cfg.Commit.Enabled || cfg.Commit.Message != "chore: nightly" {
+		t.Errorf("commit = %+v, want the top-level enabled and the profile's message", cfg.Commit)
+	}
+	if cfg.AllowMajor {
+		t.Errorf("allow-major = true, want the explicitly set false to take precedence")
+	}
+
+	if err := ApplyProfile("ci"); err != nil {
+		t.Errorf("ApplyProfile() of a profile defined in another case: %v", err)
+	}
+	if err := ApplyProfile("weekly"); err == nil || !strings.Contains(err.Error(), "defined: ci, nightly") {
+		t.Errorf("ApplyProfile() of an unknown profile = %v, want the defined ones listed", err)
+	}
+}
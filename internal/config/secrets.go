@@ -0,0 +1,86 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+	"github.com/tamcore/go-autobump/internal/runner"
+)
+
+// SecretKeys are the config keys of secrets. Each can also be read from the
+// file named by its "-file" key, or be asked from the secret command.
+var SecretKeys = []string{
+	"ai.api-key",
+	"forge.token",
+	"forge.app-private-key",
+	"jira.token",
+	"defectdojo.token",
+	"dependency-track.api-key",
+	"watch.webhook-url",
+	"exploits.vulncheck-token",
+}
+
+var (
+	resolvedMu sync.Mutex
+	// resolved holds the secrets resolved so far by key, so the secret
+	// command runs at most once per key
+	resolved = map[string]string{}
+)
+
+// ResolveSecret fills *value, the setting of the secret key, if it is not
+// set directly (in the config file, via flag or AUTOBUMP_* variable): from
+// the secret file, then from the secret command. Secrets are resolved when
+// an integration first uses them, so disabled integrations never run the
+// secret command.
+func ResolveSecret(key string, value *string) error {
+	if *value != "" {
+		return nil
+	}
+
+	resolvedMu.Lock()
+	defer resolvedMu.Unlock()
+	if secret, ok := resolved[key]; ok {
+		*value = secret
+		return nil
+	}
+
+	secret, err := readSecretFile(viper.GetString(key + "-file"))
+	if err != nil {
+		return fmt.Errorf("failed to read %s-file: %w", key, err)
+	}
+	if command := strings.Fields(viper.GetString("secret-command")); secret == "" && len(command) > 0 {
+		if secret, err = runSecretCommand(command, key); err != nil {
+			return err
+		}
+	}
+	resolved[key] = secret
+	*value = secret
+	return nil
+}
+
+// readSecretFile returns the content of path without surrounding whitespace;
+// an empty path has no secret
+func readSecretFile(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// runSecretCommand asks the secret command for the secret of key, passed as
+// its last argument. Empty output means the command has no such secret.
+func runSecretCommand(command []string, key string) (string, error) {
+	args := append(append([]string(nil), command[1:]...), key)
+	stdout, stderr, err := runner.Run("", command[0], args...)
+	if err != nil {
+		return "", fmt.Errorf("secret-command failed for %s: %v\nstderr: %s", key, err, stderr)
+	}
+	return strings.TrimSpace(string(stdout)), nil
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/tamcore/go-autobump/internal/runner"
+)
+
+// secretRunner answers the secret command with secrets by key and records
+// the keys it was asked for
+type secretRunner struct {
+	secrets map[string]string
+	asked   []string
+}
+
+func (r *secretRunner) Run(_ context.Context, _, _ string, args ...string) ([]byte, []byte, error) {
+	key := args[len(args)-1]
+	r.asked = append(r.asked, key)
+	return []byte(r.secrets[key] + "\n"), nil, nil
+}
+
+func TestResolveSecret(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+	resolved = map[string]string{}
+	defer func() { resolved = map[string]string{} }()
+	defer runner.Set(runner.Default())
+	r := &secretRunner{secrets: map[string]string{"forge.token": "from-command", "jira.token": "unused"}}
+	runner.Set(r)
+
+	keyFile := filepath.Join(t.TempDir(), "ai-key")
+	if err := os.WriteFile(keyFile, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	viper.Set("ai.api-key-file", keyFile)
+	viper.Set("secret-command", "vault-helper get")
+
+	for _, tt := range []struct {
+		key, value, want string
+	}{
+		{"ai.api-key", "", "from-file"},
+		{"forge.token", "", "from-command"},
+		{"forge.token", "", "from-command"},
+		{"jira.token", "from-config", "from-config"},
+		{"defectdojo.token", "", ""},
+	} {
+		value := tt.value
+		if err := ResolveSecret(tt.key, &value); err != nil {
+			t.Fatal(err)
+		}
+		if value != tt.want {
+			t.Errorf("ResolveSecret(%s) = %q, want %q", tt.key, value, tt.want)
+		}
+	}
+
+	// Secrets set directly and those not used are never asked for, and
+	// each secret only once
+	if want := []string{"forge.token", "defectdojo.token"}; !slices.Equal(r.asked, want) {
+		t.Errorf("secret command asked for %v, want %v", r.asked, want)
+	}
+}
+
+func TestResolveSecretMissingFile(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+	resolved = map[string]string{}
+	defer func() { resolved = map[string]string{} }()
+
+	viper.Set("forge.token-file", filepath.Join(t.TempDir(), "missing"))
+	var token string
+	if err := ResolveSecret("forge.token", &token); err == nil {
+		t.Error("ResolveSecret() ignored a missing token file")
+	}
+}
@@ -0,0 +1,54 @@
+package e2e_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/e2e"
+	"github.com/tamcore/go-autobump/internal/gomod"
+	"github.com/tamcore/go-autobump/internal/updater"
+)
+
+// TestPatchBumps covers --all-patch: direct dependencies are bumped to their
+// latest patch release, and a bump raising another direct dependency that
+// far makes its own bump a no-op
+func TestPatchBumps(t *testing.T) {
+	h := e2e.New(t,
+		vulnModule("v1.0.0"), vulnModule("v1.0.1"), vulnModule("v1.1.0"),
+		libModule("v1.0.0", "v1.0.0"), libModule("v1.0.3", "v1.0.1"), libModule("v1.1.0", "v1.1.0"))
+	goModPath := h.Write(app(map[string]string{"example.com/lib": "v1.0.0", "example.com/vuln": "v1.0.0"}))
+
+	sess := gomod.NewSession(goModPath)
+	bumps, err := updater.PatchBumps(sess)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []updater.Bump{
+		{Module: "example.com/lib", From: "v1.0.0", To: "v1.0.3"},
+		{Module: "example.com/vuln", From: "v1.0.0", To: "v1.0.1"},
+	}
+	if !reflect.DeepEqual(bumps, want) {
+		t.Fatalf("PatchBumps() = %+v, want %+v", bumps, want)
+	}
+
+	var applied []string
+	for _, b := range bumps {
+		bumped, err := updater.ApplyBump(sess, b, &config.Config{})
+		if err != nil {
+			t.Fatalf("ApplyBump(%s) error = %v", b.Module, err)
+		}
+		if bumped {
+			applied = append(applied, b.Module)
+		}
+	}
+
+	if want := []string{"example.com/lib"}; !reflect.DeepEqual(applied, want) {
+		t.Errorf("applied bumps = %v, want %v (lib raises vuln itself)", applied, want)
+	}
+	for module, version := range map[string]string{"example.com/lib": "v1.0.3", "example.com/vuln": "v1.0.1"} {
+		if got := h.Require(goModPath, module); got != version {
+			t.Errorf("%s = %s, want %s", module, got, version)
+		}
+	}
+}
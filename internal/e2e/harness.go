@@ -0,0 +1,223 @@
+package e2e
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/tamcore/go-autobump/internal/runner"
+	"github.com/tamcore/go-autobump/internal/trivy"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+)
+
+// Advisory is a vulnerability the stubbed Trivy reports for every go.mod
+// requiring Module below Fixed, unless a backport fixed its minor line
+type Advisory struct {
+	ID     string
+	Module string
+	// Fixed is the first fixed version; empty means no fix is released
+	Fixed string
+	// Backports are the first fixed versions of older minor lines, which
+	// Trivy lists along with Fixed, e.g. "1.1.3, 1.2.1"
+	Backports []string
+	CVSS      float64
+}
+
+// Harness runs go against a Proxy, in a module cache of its own, and
+// answers Trivy scans from its advisories. It is the default runner of the
+// test that created it.
+type Harness struct {
+	Proxy      *Proxy
+	Advisories []Advisory
+
+	t    testing.TB
+	exec *runner.Exec
+}
+
+// New returns a harness serving modules from its proxy and makes it the
+// default runner until the test ends
+func New(t testing.TB, modules ...Module) *Harness {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("end-to-end test runs the go command")
+	}
+
+	h := &Harness{Proxy: NewProxy(t, modules...), t: t}
+	h.exec = runner.NewExec(runner.Options{
+		Env: map[string]string{
+			"GOPROXY":     h.Proxy.URL,
+			"GOSUMDB":     "off",
+			"GOFLAGS":     "-mod=mod -modcacherw",
+			"GOMODCACHE":  filepath.Join(t.TempDir(), "mod"),
+			"GOTOOLCHAIN": "local",
+			"GOWORK":      "off",
+		},
+	})
+
+	previous := runner.Default()
+	runner.Set(h)
+	t.Cleanup(func() { runner.Set(previous) })
+	return h
+}
+
+// Run executes go and git, and answers trivy scans from the advisories
+func (h *Harness) Run(ctx context.Context, dir, name string, args ...string) ([]byte, []byte, error) {
+	if name != runner.Trivy {
+		return h.exec.Run(ctx, dir, name, args...)
+	}
+	if len(args) == 0 {
+		return nil, []byte("no scan target"), os.ErrInvalid
+	}
+	if args[0] == "--version" {
+		return []byte("Version: 0.99.0\n"), nil, nil
+	}
+
+	target := args[len(args)-1]
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(dir, target)
+	}
+	output, err := h.scan(target)
+	if err != nil {
+		return nil, []byte(err.Error()), err
+	}
+	stdout, err := json.Marshal(output)
+	return stdout, nil, err
+}
+
+// Write writes the fixture module m to a temporary directory, tidies it
+// against the proxy and returns the path of its go.mod
+func (h *Harness) Write(m Module) string {
+	h.t.Helper()
+
+	dir := h.t.TempDir()
+	files := map[string]string{"go.mod": m.GoMod()}
+	for name, content := range m.Files {
+		files[name] = content
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			h.t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			h.t.Fatal(err)
+		}
+	}
+
+	if _, stderr, err := h.Run(context.Background(), dir, runner.Go, "mod", "tidy"); err != nil {
+		h.t.Fatalf("go mod tidy failed: %v\n%s", err, stderr)
+	}
+	return filepath.Join(dir, "go.mod")
+}
+
+// Require returns the version go.mod at goModPath requires of modulePath,
+// or empty if it does not require it
+func (h *Harness) Require(goModPath, modulePath string) string {
+	h.t.Helper()
+
+	f, err := parseGoMod(goModPath)
+	if err != nil {
+		h.t.Fatal(err)
+	}
+	for _, r := range f.Require {
+		if r.Mod.Path == modulePath {
+			return r.Mod.Version
+		}
+	}
+	return ""
+}
+
+// scan reports the advisories affecting target, a go.mod file or a
+// directory whose go.mod files are scanned, the way Trivy's JSON output does
+func (h *Harness) scan(target string) (trivy.TrivyOutput, error) {
+	root := target
+	var goMods []string
+	if filepath.Base(target) == "go.mod" {
+		root = filepath.Dir(target)
+		goMods = []string{target}
+	} else {
+		err := filepath.WalkDir(target, func(path string, d os.DirEntry, err error) error {
+			if err == nil && !d.IsDir() && d.Name() == "go.mod" {
+				goMods = append(goMods, path)
+			}
+			return err
+		})
+		if err != nil {
+			return trivy.TrivyOutput{}, err
+		}
+	}
+
+	var output trivy.TrivyOutput
+	for _, goMod := range goMods {
+		f, err := parseGoMod(goMod)
+		if err != nil {
+			return trivy.TrivyOutput{}, err
+		}
+		rel, err := filepath.Rel(root, goMod)
+		if err != nil {
+			return trivy.TrivyOutput{}, err
+		}
+
+		result := trivy.TrivyResult{Target: filepath.ToSlash(rel), Class: "lang-pkgs", Type: "gomod"}
+		for _, r := range f.Require {
+			relationship := "direct"
+			if r.Indirect {
+				relationship = "indirect"
+			}
+			result.Packages = append(result.Packages, trivy.TrivyPackage{
+				Name: r.Mod.Path, Version: r.Mod.Version, Relationship: relationship,
+			})
+			for _, a := range h.Advisories {
+				if a.Module == r.Mod.Path && a.affects(r.Mod.Version) {
+					result.Vulnerabilities = append(result.Vulnerabilities, a.vulnerability(r.Mod.Version))
+				}
+			}
+		}
+		output.Results = append(output.Results, result)
+	}
+	return output, nil
+}
+
+// affects reports whether version is vulnerable to a
+func (a Advisory) affects(version string) bool {
+	for _, backport := range a.Backports {
+		if semver.MajorMinor(version) == semver.MajorMinor(backport) {
+			return semver.Compare(version, backport) < 0
+		}
+	}
+	return a.Fixed == "" || semver.Compare(version, a.Fixed) < 0
+}
+
+// vulnerability returns the Trivy finding of a at the installed version.
+// Like Trivy, it reports the fixed versions without the "v" prefix.
+func (a Advisory) vulnerability(installed string) trivy.TrivyVulnerability {
+	var fixed []string
+	for _, version := range append(slices.Clone(a.Backports), a.Fixed) {
+		if version != "" {
+			fixed = append(fixed, strings.TrimPrefix(version, "v"))
+		}
+	}
+	return trivy.TrivyVulnerability{
+		VulnerabilityID:  a.ID,
+		PkgName:          a.Module,
+		InstalledVersion: installed,
+		FixedVersion:     strings.Join(fixed, ", "),
+		Severity:         "HIGH",
+		Title:            a.ID + " in " + a.Module,
+		CVSS:             map[string]trivy.CVSS{"nvd": {V3Score: a.CVSS}},
+	}
+}
+
+// parseGoMod parses the go.mod file at path
+func parseGoMod(path string) (*modfile.File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return modfile.Parse(path, data, nil)
+}
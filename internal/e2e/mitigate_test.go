@@ -0,0 +1,118 @@
+package e2e_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/e2e"
+	"github.com/tamcore/go-autobump/internal/gomod"
+	"github.com/tamcore/go-autobump/internal/updater"
+)
+
+// forkModule returns a version of example.com/fork/vuln, a patched fork of
+// example.com/vuln keeping its module path
+func forkModule(version string) e2e.Module {
+	m := vulnModule(version)
+	m.Path, m.Declares = "example.com/fork/vuln", "example.com/vuln"
+	return m
+}
+
+// replacement returns the replace directive of modulePath in goModPath as
+// "path version", or empty if there is none
+func replacement(t *testing.T, goModPath, modulePath string) string {
+	t.Helper()
+	parser, err := gomod.NewParser(goModPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replaced, _ := parser.Replacement(modulePath)
+	return replaced
+}
+
+func TestMitigateWithFork(t *testing.T) {
+	h := e2e.New(t, vulnModule("v1.0.0"), libModule("v1.0.0", "v1.0.0"), forkModule("v1.0.1"))
+	h.Advisories = []e2e.Advisory{{ID: "CVE-2024-0008", Module: "example.com/vuln", CVSS: 9.1}}
+	goModPath := h.Write(app(map[string]string{"example.com/lib": "v1.0.0"}))
+
+	vuln := scanOne(t, goModPath)
+	cfg := &config.Config{Mitigations: config.MitigationsConfig{Replacements: []config.ReplacementConfig{
+		{Module: "example.com/vuln", With: "example.com/fork/vuln", Version: "v1.0.1"},
+	}}}
+	r := updater.FindReplacement(cfg, vuln)
+	if r == nil {
+		t.Fatal("FindReplacement() found no replacement")
+	}
+	applied, err := updater.Mitigate(gomod.NewSession(goModPath), vuln, r, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const want = "example.com/fork/vuln v1.0.1"
+	if applied != want {
+		t.Errorf("Mitigate() = %q, want %q", applied, want)
+	}
+	if got := replacement(t, goModPath, "example.com/vuln"); got != want {
+		t.Errorf("replacement of example.com/vuln = %q, want %q", got, want)
+	}
+	if got := h.Require(goModPath, "example.com/vuln"); got != "v1.0.0" {
+		t.Errorf("example.com/vuln = %s, want the requirement kept at v1.0.0", got)
+	}
+}
+
+// TestUnmitigate covers removing the replacement of a mitigated module once
+// upstream releases a version expected to fix it
+func TestUnmitigate(t *testing.T) {
+	tests := []struct {
+		name    string
+		release string
+		fixed   bool
+	}{
+		{"release fixes", "v1.0.2", true},
+		{"release still vulnerable", "v1.0.1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := e2e.New(t, vulnModule("v1.0.0"), libModule("v1.0.0", "v1.0.0"), forkModule("v1.0.1"))
+			h.Advisories = []e2e.Advisory{{ID: "CVE-2024-0009", Module: "example.com/vuln", CVSS: 9.1}}
+			goModPath := h.Write(app(map[string]string{"example.com/lib": "v1.0.0"}))
+
+			vuln := scanOne(t, goModPath)
+			r := &config.ReplacementConfig{Module: "example.com/vuln", With: "example.com/fork/vuln", Version: "v1.0.1"}
+			mitigated, err := updater.Mitigate(gomod.NewSession(goModPath), vuln, r, &config.Config{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Upstream releases v1.0.1 and v1.0.2, of which only v1.0.2 fixes
+			h.Proxy.Add(vulnModule("v1.0.1"), vulnModule("v1.0.2"))
+			h.Advisories[0].Fixed = "v1.0.2"
+
+			err = updater.Unmitigate(gomod.NewSession(goModPath), "example.com/vuln", tt.release, []string{vuln.VulnerabilityID}, &config.Config{})
+			if !tt.fixed {
+				if !errors.Is(err, updater.ErrStillVulnerable) {
+					t.Fatalf("Unmitigate() = %v, want ErrStillVulnerable", err)
+				}
+				if got := replacement(t, goModPath, "example.com/vuln"); got != mitigated {
+					t.Errorf("replacement of example.com/vuln = %q, want %q restored", got, mitigated)
+				}
+				if got := h.Require(goModPath, "example.com/vuln"); got != "v1.0.0" {
+					t.Errorf("example.com/vuln = %s, want v1.0.0 restored", got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := replacement(t, goModPath, "example.com/vuln"); got != "" {
+				t.Errorf("replacement of example.com/vuln = %q, want it removed", got)
+			}
+			if got := h.Require(goModPath, "example.com/vuln"); got != tt.release {
+				t.Errorf("example.com/vuln = %s, want %s", got, tt.release)
+			}
+			assertFixed(t, goModPath)
+		})
+	}
+}
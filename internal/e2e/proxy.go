@@ -0,0 +1,196 @@
+// Package e2e provides the fixtures of end-to-end tests: a module proxy
+// serving in-memory module versions, and a runner that executes the real go
+// command against it while answering Trivy scans from a list of advisories.
+// Together they let the update flows run without network access.
+package e2e
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// goVersion is the go directive of the generated go.mod files
+const goVersion = "1.21"
+
+// Module is a module version served by a Proxy, or a fixture module written
+// to disk, for which Version is ignored
+type Module struct {
+	Path    string
+	Version string
+	// Declares is the module path go.mod declares if it differs from Path,
+	// like a fork keeping the path of its upstream
+	Declares string
+	// Require maps the modules required by go.mod to their version
+	Require map[string]string
+	// Files holds the other files of the module by slash-separated path,
+	// e.g. "lib.go"
+	Files map[string]string
+}
+
+// GoMod returns the go.mod file of the module
+func (m Module) GoMod() string {
+	path := m.Path
+	if m.Declares != "" {
+		path = m.Declares
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "module %s\n\ngo %s\n", path, goVersion)
+	if len(m.Require) > 0 {
+		b.WriteString("\nrequire (\n")
+		for _, path := range sortedKeys(m.Require) {
+			fmt.Fprintf(&b, "\t%s %s\n", path, m.Require[path])
+		}
+		b.WriteString(")\n")
+	}
+	return b.String()
+}
+
+// Proxy is a GOPROXY serving in-memory module versions
+type Proxy struct {
+	URL string
+
+	mu       sync.Mutex
+	versions map[string]map[string]Module
+}
+
+// NewProxy starts a proxy serving modules, which is stopped when the test ends
+func NewProxy(t testing.TB, modules ...Module) *Proxy {
+	p := &Proxy{versions: make(map[string]map[string]Module)}
+	p.Add(modules...)
+
+	server := httptest.NewServer(p)
+	t.Cleanup(server.Close)
+	p.URL = server.URL
+	return p
+}
+
+// Add publishes module versions, e.g. a fix released during the test
+func (p *Proxy) Add(modules ...Module) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, m := range modules {
+		if p.versions[m.Path] == nil {
+			p.versions[m.Path] = make(map[string]Module)
+		}
+		p.versions[m.Path][m.Version] = m
+	}
+}
+
+// ServeHTTP implements the GOPROXY protocol: $module/@v/list,
+// $module/@v/$version.{info,mod,zip} and $module/@latest
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	escaped, file, ok := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/@")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	path, err := module.UnescapePath(escaped)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	p.mu.Lock()
+	versions := p.versions[path]
+	p.mu.Unlock()
+
+	if file == "latest" {
+		list := sortedVersions(versions)
+		if len(list) == 0 {
+			http.NotFound(w, r)
+			return
+		}
+		writeInfo(w, list[len(list)-1])
+		return
+	}
+
+	file, ok = strings.CutPrefix(file, "v/")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if file == "list" {
+		_, _ = fmt.Fprint(w, strings.Join(sortedVersions(versions), "\n"))
+		return
+	}
+
+	ext := file[strings.LastIndex(file, "."):]
+	version, err := module.UnescapeVersion(strings.TrimSuffix(file, ext))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	m, ok := versions[version]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch ext {
+	case ".info":
+		writeInfo(w, version)
+	case ".mod":
+		_, _ = fmt.Fprint(w, m.GoMod())
+	case ".zip":
+		if err := writeZip(w, m); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// writeInfo writes the .info document of version
+func writeInfo(w http.ResponseWriter, version string) {
+	_ = json.NewEncoder(w).Encode(map[string]string{"Version": version, "Time": "2024-01-01T00:00:00Z"})
+}
+
+// writeZip writes the module zip of m, holding its go.mod and files
+func writeZip(w http.ResponseWriter, m Module) error {
+	prefix := m.Path + "@" + m.Version + "/"
+	zw := zip.NewWriter(w)
+	files := map[string]string{"go.mod": m.GoMod()}
+	for name, content := range m.Files {
+		files[name] = content
+	}
+	for _, name := range sortedKeys(files) {
+		f, err := zw.Create(prefix + name)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write([]byte(files[name])); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// sortedVersions returns the versions in semver order
+func sortedVersions(versions map[string]Module) []string {
+	list := make([]string, 0, len(versions))
+	for version := range versions {
+		list = append(list, version)
+	}
+	semver.Sort(list)
+	return list
+}
+
+// sortedKeys returns the keys of m in order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
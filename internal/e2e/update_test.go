@@ -0,0 +1,352 @@
+package e2e_test
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/e2e"
+	"github.com/tamcore/go-autobump/internal/gomod"
+	"github.com/tamcore/go-autobump/internal/runner"
+	"github.com/tamcore/go-autobump/internal/trivy"
+	"github.com/tamcore/go-autobump/internal/updater"
+)
+
+// vulnModule returns a version of example.com/vuln, the vulnerable module
+func vulnModule(version string) e2e.Module {
+	return e2e.Module{
+		Path:    "example.com/vuln",
+		Version: version,
+		Files:   map[string]string{"vuln.go": "package vuln\n\nfunc Parse() {}\n"},
+	}
+}
+
+// libModule returns a version of example.com/lib, which imports
+// example.com/vuln at vulnVersion
+func libModule(version, vulnVersion string) e2e.Module {
+	return e2e.Module{
+		Path:    "example.com/lib",
+		Version: version,
+		Require: map[string]string{"example.com/vuln": vulnVersion},
+		Files:   map[string]string{"lib.go": "package lib\n\nimport \"example.com/vuln\"\n\nfunc Do() { vuln.Parse() }\n"},
+	}
+}
+
+// app returns the fixture module, importing the packages of require
+func app(require map[string]string) e2e.Module {
+	source := "package main\n\nimport (\n"
+	for path := range require {
+		source += "\t_ \"" + path + "\"\n"
+	}
+	source += ")\n\nfunc main() {}\n"
+	return e2e.Module{
+		Path:    "example.com/app",
+		Require: require,
+		Files:   map[string]string{"main.go": source},
+	}
+}
+
+// scanOne scans goModPath and returns its single vulnerability
+func scanOne(t *testing.T, goModPath string) trivy.Vulnerability {
+	t.Helper()
+	result, err := trivy.Scan(goModPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Vulnerabilities) != 1 {
+		t.Fatalf("scan found %d vulnerabilities, want 1: %+v", len(result.Vulnerabilities), result.Vulnerabilities)
+	}
+	return result.Vulnerabilities[0]
+}
+
+// assertFixed fails the test if goModPath is still vulnerable
+func assertFixed(t *testing.T, goModPath string) {
+	t.Helper()
+	result, err := trivy.Scan(goModPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Vulnerabilities) > 0 {
+		t.Errorf("still vulnerable: %+v", result.Vulnerabilities)
+	}
+}
+
+func TestUpdateDirect(t *testing.T) {
+	h := e2e.New(t, vulnModule("v1.0.0"), vulnModule("v1.0.1"))
+	h.Advisories = []e2e.Advisory{{ID: "CVE-2024-0001", Module: "example.com/vuln", Fixed: "v1.0.1", CVSS: 7.5}}
+	goModPath := h.Write(app(map[string]string{"example.com/vuln": "v1.0.0"}))
+
+	vuln := scanOne(t, goModPath)
+	if vuln.Indirect {
+		t.Fatal("direct dependency reported as indirect")
+	}
+	if err := updater.Update(gomod.NewSession(goModPath), vuln, &config.Config{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := h.Require(goModPath, "example.com/vuln"); got != "v1.0.1" {
+		t.Errorf("example.com/vuln = %s, want v1.0.1", got)
+	}
+	assertFixed(t, goModPath)
+}
+
+func TestUpdateDirectFixVersion(t *testing.T) {
+	tests := []struct {
+		fixVersion string
+		want       string
+	}{
+		{config.FixVersionFixed, "v1.0.1"},
+		{config.FixVersionLatestPatch, "v1.0.2"},
+		{config.FixVersionLatest, "v1.1.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixVersion, func(t *testing.T) {
+			h := e2e.New(t, vulnModule("v1.0.0"), vulnModule("v1.0.1"), vulnModule("v1.0.2"), vulnModule("v1.1.0"), vulnModule("v1.2.0-rc.1"))
+			h.Advisories = []e2e.Advisory{{ID: "CVE-2024-0005", Module: "example.com/vuln", Fixed: "v1.0.1", CVSS: 7.5}}
+			goModPath := h.Write(app(map[string]string{"example.com/vuln": "v1.0.0"}))
+
+			vuln := scanOne(t, goModPath)
+			if err := updater.Update(gomod.NewSession(goModPath), vuln, &config.Config{FixVersion: tt.fixVersion}); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := h.Require(goModPath, "example.com/vuln"); got != tt.want {
+				t.Errorf("example.com/vuln = %s, want %s", got, tt.want)
+			}
+			assertFixed(t, goModPath)
+		})
+	}
+}
+
+// TestUpdateBackport covers an advisory fixed in several minor lines, which
+// Trivy reports as a list of fixed versions
+func TestUpdateBackport(t *testing.T) {
+	h := e2e.New(t, vulnModule("v1.1.0"), vulnModule("v1.1.3"), vulnModule("v1.2.1"))
+	h.Advisories = []e2e.Advisory{{ID: "CVE-2024-0007", Module: "example.com/vuln", Fixed: "v1.2.1", Backports: []string{"v1.1.3"}, CVSS: 7.5}}
+	goModPath := h.Write(app(map[string]string{"example.com/vuln": "v1.1.0"}))
+
+	vuln := scanOne(t, goModPath)
+	if vuln.FixedVersion != "1.1.3" {
+		t.Errorf("FixedVersion = %q, want the fix of the installed minor line, 1.1.3", vuln.FixedVersion)
+	}
+	if err := updater.Update(gomod.NewSession(goModPath), vuln, &config.Config{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := h.Require(goModPath, "example.com/vuln"); got != "v1.1.3" {
+		t.Errorf("example.com/vuln = %s, want v1.1.3", got)
+	}
+	assertFixed(t, goModPath)
+}
+
+// TestUpdateIncompatible covers a v2+ module without semantic import
+// versioning, whose fixed version Trivy reports without +incompatible
+func TestUpdateIncompatible(t *testing.T) {
+	legacy := func(version string) e2e.Module {
+		return e2e.Module{
+			Path:    "example.com/legacy",
+			Version: version,
+			Files:   map[string]string{"legacy.go": "package legacy\n"},
+		}
+	}
+	h := e2e.New(t, legacy("v2.0.0+incompatible"), legacy("v2.1.0+incompatible"))
+	h.Advisories = []e2e.Advisory{{ID: "CVE-2024-0006", Module: "example.com/legacy", Fixed: "v2.1.0", CVSS: 7.5}}
+	goModPath := h.Write(app(map[string]string{"example.com/legacy": "v2.0.0+incompatible"}))
+
+	vuln := scanOne(t, goModPath)
+	if err := updater.Update(gomod.NewSession(goModPath), vuln, &config.Config{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := h.Require(goModPath, "example.com/legacy"); got != "v2.1.0+incompatible" {
+		t.Errorf("example.com/legacy = %s, want v2.1.0+incompatible", got)
+	}
+	assertFixed(t, goModPath)
+}
+
+func TestUpdateIndirect(t *testing.T) {
+	h := e2e.New(t, vulnModule("v1.0.0"), vulnModule("v1.1.0"), libModule("v1.0.0", "v1.0.0"))
+	h.Advisories = []e2e.Advisory{{ID: "CVE-2024-0002", Module: "example.com/vuln", Fixed: "v1.1.0", CVSS: 8.1}}
+	goModPath := h.Write(app(map[string]string{"example.com/lib": "v1.0.0"}))
+
+	vuln := scanOne(t, goModPath)
+	if !vuln.Indirect {
+		t.Fatal("indirect dependency reported as direct")
+	}
+	if err := updater.Update(gomod.NewSession(goModPath), vuln, &config.Config{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := h.Require(goModPath, "example.com/vuln"); got != "v1.1.0" {
+		t.Errorf("example.com/vuln = %s, want v1.1.0", got)
+	}
+	if got := h.Require(goModPath, "example.com/lib"); got != "v1.0.0" {
+		t.Errorf("example.com/lib = %s, want v1.0.0 (untouched)", got)
+	}
+	assertFixed(t, goModPath)
+}
+
+// TestUpdateThroughDirectDependency covers an advisory whose fixed version
+// is not published, so that only a release of the direct dependency
+// requiring a later version fixes it
+func TestUpdateThroughDirectDependency(t *testing.T) {
+	for _, strategy := range []string{config.StrategyLatest, config.StrategyMinimal} {
+		t.Run(strategy, func(t *testing.T) {
+			h := e2e.New(t,
+				vulnModule("v1.0.0"), vulnModule("v1.2.0"),
+				libModule("v1.0.0", "v1.0.0"), libModule("v1.1.0", "v1.2.0"))
+			h.Advisories = []e2e.Advisory{{ID: "CVE-2024-0003", Module: "example.com/vuln", Fixed: "v1.1.0", CVSS: 9.8}}
+			goModPath := h.Write(app(map[string]string{"example.com/lib": "v1.0.0"}))
+
+			vuln := scanOne(t, goModPath)
+			if err := updater.Update(gomod.NewSession(goModPath), vuln, &config.Config{Strategy: strategy}); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := h.Require(goModPath, "example.com/lib"); got != "v1.1.0" {
+				t.Errorf("example.com/lib = %s, want v1.1.0", got)
+			}
+			assertFixed(t, goModPath)
+		})
+	}
+}
+
+func TestUpdateWithoutUpgradePath(t *testing.T) {
+	h := e2e.New(t, vulnModule("v1.0.0"), libModule("v1.0.0", "v1.0.0"), libModule("v1.1.0", "v1.0.0"))
+	h.Advisories = []e2e.Advisory{{ID: "CVE-2024-0004", Module: "example.com/vuln", Fixed: "v1.1.0", CVSS: 7.0}}
+	goModPath := h.Write(app(map[string]string{"example.com/lib": "v1.0.0"}))
+
+	vuln := scanOne(t, goModPath)
+	sess := gomod.NewSession(goModPath)
+	err := updater.Update(sess, vuln, &config.Config{Strategy: config.StrategyMinimal})
+	if !errors.Is(err, updater.ErrNoUpgradePath) {
+		t.Fatalf("Update() = %v, want ErrNoUpgradePath", err)
+	}
+	if got := updater.Classify(sess, vuln, err).Kind; got != updater.FailureNoUpgradePath {
+		t.Errorf("Classify() = %s, want %s", got, updater.FailureNoUpgradePath)
+	}
+}
+
+// TestUpdateAtomic covers --atomic: once an update of a module fails, the
+// updates already applied to it are rolled back too
+func TestUpdateAtomic(t *testing.T) {
+	other := e2e.Module{
+		Path:    "example.com/other",
+		Version: "v1.0.0",
+		Files:   map[string]string{"other.go": "package other\n"},
+	}
+	h := e2e.New(t, vulnModule("v1.0.0"), vulnModule("v1.0.1"), other)
+	h.Advisories = []e2e.Advisory{
+		{ID: "CVE-2024-0001", Module: "example.com/vuln", Fixed: "v1.0.1", CVSS: 9.8},
+		// Never published, so its update fails
+		{ID: "CVE-2024-0008", Module: "example.com/other", Fixed: "v1.0.1", CVSS: 7.5},
+	}
+	goModPath := h.Write(app(map[string]string{"example.com/vuln": "v1.0.0", "example.com/other": "v1.0.0"}))
+	goSumPath := filepath.Join(filepath.Dir(goModPath), "go.sum")
+	goMod, _ := os.ReadFile(goModPath)
+	goSum, _ := os.ReadFile(goSumPath)
+
+	result, err := trivy.Scan(goModPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vulns := result.Vulnerabilities
+	slices.SortFunc(vulns, func(a, b trivy.Vulnerability) int { return strings.Compare(a.VulnerabilityID, b.VulnerabilityID) })
+	if len(vulns) != 2 {
+		t.Fatalf("scan found %d vulnerabilities, want 2: %+v", len(vulns), vulns)
+	}
+
+	sess := gomod.NewSession(goModPath)
+	snap, err := sess.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := updater.Update(sess, vulns[0], &config.Config{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := h.Require(goModPath, "example.com/vuln"); got != "v1.0.1" {
+		t.Fatalf("example.com/vuln = %s, want v1.0.1 before the failing update", got)
+	}
+	if err := updater.Update(sess, vulns[1], &config.Config{}); err == nil {
+		t.Fatal("Update() to an unpublished version succeeded")
+	}
+	if err := sess.Restore(snap); err != nil {
+		t.Fatalf("Restore() error: %v", err)
+	}
+
+	if data, _ := os.ReadFile(goModPath); string(data) != string(goMod) {
+		t.Errorf("go.mod after rollback =\n%s\nwant\n%s", data, goMod)
+	}
+	if data, _ := os.ReadFile(goSumPath); string(data) != string(goSum) {
+		t.Errorf("go.sum after rollback =\n%s\nwant\n%s", data, goSum)
+	}
+	if got := h.Require(goModPath, "example.com/vuln"); got != "v1.0.0" {
+		t.Errorf("example.com/vuln after rollback = %s, want v1.0.0", got)
+	}
+}
+
+// TestUpdateWorktree covers isolating updates in a git worktree: a verified
+// update is copied back, a failed one leaves the checkout untouched, and
+// neither leaves a worktree behind
+func TestUpdateWorktree(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	h := e2e.New(t, vulnModule("v1.0.0"), vulnModule("v1.0.1"))
+	h.Advisories = []e2e.Advisory{
+		{ID: "CVE-2024-0001", Module: "example.com/vuln", Fixed: "v1.0.1", CVSS: 7.5},
+		// Never published, so its update fails
+		{ID: "CVE-2024-0009", Module: "example.com/vuln", Fixed: "v1.0.5", CVSS: 7.5},
+	}
+	goModPath := h.Write(app(map[string]string{"example.com/vuln": "v1.0.0"}))
+	dir := filepath.Dir(goModPath)
+	gitCmd := func(args ...string) string {
+		t.Helper()
+		args = append([]string{"-c", "user.name=Test", "-c", "user.email=test@example.com"}, args...)
+		stdout, stderr, err := runner.Run(dir, runner.Git, args...)
+		if err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, stderr)
+		}
+		return string(stdout)
+	}
+	gitCmd("init", "--quiet")
+	gitCmd("add", "-A")
+	gitCmd("commit", "--quiet", "-m", "initial")
+
+	result, err := trivy.Scan(goModPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vulns := result.Vulnerabilities
+	slices.SortFunc(vulns, func(a, b trivy.Vulnerability) int { return strings.Compare(a.VulnerabilityID, b.VulnerabilityID) })
+	if len(vulns) != 2 {
+		t.Fatalf("scan found %d vulnerabilities, want 2: %+v", len(vulns), vulns)
+	}
+	cfg := &config.Config{Worktree: true}
+	sess := gomod.NewSession(goModPath)
+
+	goMod, _ := os.ReadFile(goModPath)
+	if err := updater.Update(sess, vulns[1], cfg); err == nil {
+		t.Fatal("Update() to an unpublished version succeeded")
+	}
+	if data, _ := os.ReadFile(goModPath); string(data) != string(goMod) {
+		t.Errorf("go.mod after a failed update =\n%s\nwant it unchanged", data)
+	}
+
+	if err := updater.Update(sess, vulns[0], cfg); err != nil {
+		t.Fatal(err)
+	}
+	if got := h.Require(goModPath, "example.com/vuln"); got != "v1.0.1" {
+		t.Errorf("example.com/vuln = %s, want v1.0.1", got)
+	}
+
+	if worktrees := strings.Count(gitCmd("worktree", "list", "--porcelain"), "worktree "); worktrees != 1 {
+		t.Errorf("%d worktrees after the updates, want only the main checkout", worktrees)
+	}
+}
@@ -0,0 +1,74 @@
+package e2e_test
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/e2e"
+	"github.com/tamcore/go-autobump/internal/gomod"
+	"github.com/tamcore/go-autobump/internal/trivy"
+	"github.com/tamcore/go-autobump/internal/vex"
+)
+
+func TestVEXGenerate(t *testing.T) {
+	testDep := e2e.Module{
+		Path:    "example.com/testdep",
+		Version: "v1.0.0",
+		Files:   map[string]string{"testdep.go": "package testdep\n\nfunc Assert() {}\n"},
+	}
+	policyDep := e2e.Module{
+		Path:    "example.com/pinned",
+		Version: "v1.0.0",
+		Files:   map[string]string{"pinned.go": "package pinned\n"},
+	}
+	h := e2e.New(t, vulnModule("v1.0.0"), vulnModule("v1.0.1"), testDep, policyDep)
+	h.Advisories = []e2e.Advisory{
+		{ID: "CVE-2024-0001", Module: "example.com/vuln", Fixed: "v1.0.1", CVSS: 7.5},
+		{ID: "CVE-2024-0002", Module: "example.com/vuln", CVSS: 7.5},
+		{ID: "CVE-2024-0003", Module: "example.com/testdep", Fixed: "v1.0.1", CVSS: 7.5},
+		{ID: "CVE-2024-0004", Module: "example.com/pinned", Fixed: "v1.0.1", CVSS: 7.5},
+	}
+	fixture := app(map[string]string{"example.com/vuln": "v1.0.0", "example.com/pinned": "v1.0.0"})
+	fixture.Require["example.com/testdep"] = "v1.0.0"
+	fixture.Files["main_test.go"] = "package main\n\nimport (\n\t\"testing\"\n\n\t\"example.com/testdep\"\n)\n\nfunc TestMain(t *testing.T) { testdep.Assert() }\n"
+	goModPath := h.Write(fixture)
+
+	result, err := trivy.Scan(goModPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// An update ignores test-only dependencies and cannot fix the pinned module
+	production, err := gomod.ProductionModules(gomod.GetModuleDir(goModPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	actedOn := trivy.Filter(result, func(vuln trivy.Vulnerability) bool { return production[vuln.PkgName] })
+	vulns := vex.Unfixed(result, actedOn, func(vuln trivy.Vulnerability) error {
+		if vuln.PkgName == "example.com/pinned" {
+			return errors.New("updates ignored")
+		}
+		return nil
+	})
+
+	cfg := config.Default()
+	cfg.VEXOutput = filepath.Join(t.TempDir(), "vex.json")
+	if _, err := vex.Generate(vulns, cfg); err != nil {
+		t.Fatal(err)
+	}
+	doc, err := vex.ReadDocument(cfg.VEXOutput)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []string
+	for _, stmt := range doc.Statements {
+		ids = append(ids, stmt.VulnerabilityID)
+	}
+	slices.Sort(ids)
+	if want := []string{"CVE-2024-0002", "CVE-2024-0003", "CVE-2024-0004"}; !slices.Equal(ids, want) {
+		t.Errorf("VEX statements for %v, want %v", ids, want)
+	}
+}
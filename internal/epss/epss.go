@@ -0,0 +1,134 @@
+// Package epss fetches Exploit Prediction Scoring System scores from FIRST
+package epss
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tamcore/go-autobump/internal/alias"
+	"github.com/tamcore/go-autobump/internal/httpclient"
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+// DefaultEndpoint is the FIRST EPSS API
+const DefaultEndpoint = "https://api.first.org/data/v1/epss"
+
+// batchSize bounds the number of CVEs per request to keep URLs short
+const batchSize = 100
+
+// Client queries an EPSS API
+type Client struct {
+	Endpoint   string
+	HTTPClient *http.Client
+}
+
+// NewClient creates a client for the FIRST EPSS API
+func NewClient() *Client {
+	return &Client{
+		Endpoint:   DefaultEndpoint,
+		HTTPClient: httpclient.New(30 * time.Second),
+	}
+}
+
+// response is the FIRST EPSS API response; scores are encoded as strings
+type response struct {
+	Data []struct {
+		CVE  string `json:"cve"`
+		EPSS string `json:"epss"`
+	} `json:"data"`
+}
+
+// Scores returns the EPSS probability (0-1) of each CVE in ids.
+// Non-CVE identifiers are skipped, and CVEs without a score are omitted.
+func (c *Client) Scores(ctx context.Context, ids []string) (map[string]float64, error) {
+	var cves []string
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		if strings.HasPrefix(id, "CVE-") && !seen[id] {
+			seen[id] = true
+			cves = append(cves, id)
+		}
+	}
+
+	scores := make(map[string]float64, len(cves))
+	for start := 0; start < len(cves); start += batchSize {
+		end := min(start+batchSize, len(cves))
+		if err := c.fetch(ctx, cves[start:end], scores); err != nil {
+			return nil, err
+		}
+	}
+	return scores, nil
+}
+
+// fetch requests the scores of one batch of CVEs and adds them to scores
+func (c *Client) fetch(ctx context.Context, cves []string, scores map[string]float64) error {
+	endpoint := c.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+	reqURL := endpoint + "?cve=" + url.QueryEscape(strings.Join(cves, ","))
+
+	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("EPSS request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read EPSS response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("EPSS API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result response
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("failed to parse EPSS response: %w", err)
+	}
+
+	for _, entry := range result.Data {
+		score, err := strconv.ParseFloat(entry.EPSS, 64)
+		if err != nil {
+			continue
+		}
+		scores[entry.CVE] = score
+	}
+	return nil
+}
+
+// Enrich sets the EPSS score of every vulnerability in results, looked up
+// by its CVE ID if a known alias is one
+func (c *Client) Enrich(ctx context.Context, results []trivy.ScanResult) error {
+	var ids []string
+	for _, result := range results {
+		for _, vuln := range result.Vulnerabilities {
+			ids = append(ids, alias.Canonical(vuln.VulnerabilityID))
+		}
+	}
+
+	scores, err := c.Scores(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	for i := range results {
+		for j := range results[i].Vulnerabilities {
+			vuln := &results[i].Vulnerabilities[j]
+			vuln.EPSS = scores[alias.Canonical(vuln.VulnerabilityID)]
+		}
+	}
+	return nil
+}
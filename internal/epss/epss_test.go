@@ -0,0 +1,47 @@
+package epss
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+func TestEnrich(t *testing.T) {
+	var gotQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.Query().Get("cve")
+		_, _ = w.Write([]byte(`{"status":"OK","data":[
+			{"cve":"CVE-2024-0001","epss":"0.91234","percentile":"0.99"},
+			{"cve":"CVE-2024-0002","epss":"0.00043","percentile":"0.08"}
+		]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.Endpoint = server.URL
+
+	results := []trivy.ScanResult{{Target: "go.mod", Vulnerabilities: []trivy.Vulnerability{
+		{VulnerabilityID: "CVE-2024-0001"},
+		{VulnerabilityID: "GHSA-xxxx-yyyy-zzzz"},
+		{VulnerabilityID: "CVE-2024-0002"},
+		{VulnerabilityID: "CVE-2024-0001"},
+	}}}
+
+	if err := client.Enrich(context.Background(), results); err != nil {
+		t.Fatalf("Enrich() error = %v", err)
+	}
+
+	if gotQuery != "CVE-2024-0001,CVE-2024-0002" {
+		t.Errorf("queried %q, want only deduplicated CVEs", gotQuery)
+	}
+
+	want := []float64{0.91234, 0, 0.00043, 0.91234}
+	for i, vuln := range results[0].Vulnerabilities {
+		if vuln.EPSS != want[i] {
+			t.Errorf("%s EPSS = %v, want %v", vuln.VulnerabilityID, vuln.EPSS, want[i])
+		}
+	}
+}
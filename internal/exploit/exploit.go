@@ -0,0 +1,172 @@
+// Package exploit annotates vulnerabilities with their exploit maturity,
+// looked up in catalogs of exploited vulnerabilities and published exploits
+package exploit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/tamcore/go-autobump/internal/alias"
+	"github.com/tamcore/go-autobump/internal/kev"
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+// Sources of exploit maturity
+const (
+	// SourceCISAKEV is the CISA Known Exploited Vulnerabilities catalog
+	SourceCISAKEV = "cisa-kev"
+	// SourceVulnCheckKEV is the VulnCheck KEV index, which also lists
+	// exploitation CISA has not confirmed
+	SourceVulnCheckKEV = "vulncheck-kev"
+	// SourceExploitDB is the index of the exploits published on Exploit-DB
+	SourceExploitDB = "exploit-db"
+)
+
+// Sources lists the valid sources
+var Sources = []string{SourceCISAKEV, SourceVulnCheckKEV, SourceExploitDB}
+
+// Validate checks that every source in sources is known
+func Validate(sources []string) error {
+	for _, source := range sources {
+		if !slices.Contains(Sources, source) {
+			return fmt.Errorf("unknown source %q (valid: %s)", source, strings.Join(Sources, ", "))
+		}
+	}
+	return nil
+}
+
+// Catalog maps CVE IDs to their exploit maturity
+type Catalog map[string]string
+
+// add records maturity for cve unless a higher one is known
+func (c Catalog) add(cve, maturity string) {
+	if trivy.ExploitRank(maturity) > trivy.ExploitRank(c[cve]) {
+		c[cve] = maturity
+	}
+}
+
+// merge adds the maturities of other to c
+func (c Catalog) merge(other Catalog) {
+	for cve, maturity := range other {
+		c.add(cve, maturity)
+	}
+}
+
+// Maturity returns the highest maturity of the IDs of vuln and their known
+// aliases, as catalogs list CVE IDs only
+func (c Catalog) Maturity(vuln trivy.Vulnerability) string {
+	var maturity string
+	for _, id := range ids(vuln) {
+		if m := c[strings.ToUpper(id)]; trivy.ExploitRank(m) > trivy.ExploitRank(maturity) {
+			maturity = m
+		}
+	}
+	return maturity
+}
+
+// Client looks up exploit maturity in the configured sources
+type Client struct {
+	Sources   []string
+	KEV       *kev.Client
+	VulnCheck *VulnCheck
+	ExploitDB *ExploitDB
+}
+
+// NewClient creates a client for sources. The VulnCheck KEV index is only
+// queried with a token.
+func NewClient(sources []string, vulnCheckToken, exploitDBURL string) *Client {
+	return &Client{
+		Sources:   sources,
+		KEV:       kev.NewClient(),
+		VulnCheck: NewVulnCheck(vulnCheckToken),
+		ExploitDB: NewExploitDB(exploitDBURL),
+	}
+}
+
+// Lookup returns the exploit maturity of the CVEs of vulns known to the
+// sources. A failing source does not keep the others from being consulted;
+// its error is returned along with what the others know.
+func (c *Client) Lookup(ctx context.Context, vulns []trivy.Vulnerability) (Catalog, error) {
+	catalog := Catalog{}
+	var errs []error
+	for _, source := range c.Sources {
+		var (
+			known Catalog
+			err   error
+		)
+		switch source {
+		case SourceCISAKEV:
+			known, err = c.cisaKEV(ctx)
+		case SourceVulnCheckKEV:
+			known, err = c.VulnCheck.Lookup(ctx, cveIDs(vulns))
+		case SourceExploitDB:
+			known, err = c.ExploitDB.Catalog(ctx)
+		default:
+			err = fmt.Errorf("unknown source")
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", source, err))
+			continue
+		}
+		catalog.merge(known)
+	}
+	return catalog, errors.Join(errs...)
+}
+
+// cisaKEV returns the CVEs of the CISA KEV catalog as weaponized
+func (c *Client) cisaKEV(ctx context.Context) (Catalog, error) {
+	known, err := c.KEV.Catalog(ctx)
+	if err != nil {
+		return nil, err
+	}
+	catalog := make(Catalog, len(known))
+	for cve := range known {
+		catalog[strings.ToUpper(cve)] = trivy.ExploitWeaponized
+	}
+	return catalog, nil
+}
+
+// Annotate sets the exploit maturity of every vulnerability in results and
+// orders the vulnerabilities of each result from the most mature exploit,
+// keeping the order of those of equal maturity. Vulnerabilities are
+// annotated with what the sources that could be consulted know.
+func (c *Client) Annotate(ctx context.Context, results []trivy.ScanResult) error {
+	var vulns []trivy.Vulnerability
+	for _, result := range results {
+		vulns = append(vulns, result.Vulnerabilities...)
+	}
+
+	catalog, err := c.Lookup(ctx, vulns)
+	for i := range results {
+		for j := range results[i].Vulnerabilities {
+			vuln := &results[i].Vulnerabilities[j]
+			vuln.Exploit = catalog.Maturity(*vuln)
+		}
+		slices.SortStableFunc(results[i].Vulnerabilities, func(a, b trivy.Vulnerability) int {
+			return trivy.ExploitRank(b.Exploit) - trivy.ExploitRank(a.Exploit)
+		})
+	}
+	return err
+}
+
+// ids returns the IDs of vuln and the aliases of the alias table
+func ids(vuln trivy.Vulnerability) []string {
+	return append(vuln.IDs(), alias.IDs(vuln.VulnerabilityID)...)
+}
+
+// cveIDs returns the distinct CVE IDs of vulns
+func cveIDs(vulns []trivy.Vulnerability) []string {
+	var cves []string
+	for _, vuln := range vulns {
+		for _, id := range ids(vuln) {
+			id = strings.ToUpper(id)
+			if strings.HasPrefix(id, "CVE-") && !slices.Contains(cves, id) {
+				cves = append(cves, id)
+			}
+		}
+	}
+	return cves
+}
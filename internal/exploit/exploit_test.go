@@ -0,0 +1,85 @@
+package exploit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+const exploitDBIndex = `id,file,description,date_published,author,type,platform,port,date_added,date_updated,verified,codes,tags
+50592,exploits/java/remote/50592.py,"Apache Log4j2 2.14.1 - Information Disclosure",2021-12-14,leonjza,remote,java,,2021-12-14,2021-12-14,0,CVE-2021-44228;OSVDB-1,
+1,exploits/linux/local/1.c,"Example, with a comma",2020-01-01,someone,local,linux,,2020-01-01,2020-01-01,1,cve-2024-0002,
+2,exploits/linux/local/2.c,Short row
+`
+
+func TestAnnotate(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/kev.json", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"vulnerabilities":[{"cveID":"CVE-2024-0001"}]}`))
+	})
+	mux.HandleFunc("/exploitdb.csv", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(exploitDBIndex))
+	})
+	mux.HandleFunc("/vulncheck", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if cve := r.URL.Query().Get("cve"); cve == "CVE-2024-0002" {
+			_, _ = w.Write([]byte(`{"data":[{"cve":["CVE-2024-0002"],"vulncheck_xdb":[]}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := NewClient(Sources, "token", server.URL+"/exploitdb.csv")
+	client.KEV.Endpoint = server.URL + "/kev.json"
+	client.VulnCheck.Endpoint = server.URL + "/vulncheck"
+
+	results := []trivy.ScanResult{{Target: "go.mod", Vulnerabilities: []trivy.Vulnerability{
+		{VulnerabilityID: "GHSA-xxxx-yyyy-zzzz"},
+		{VulnerabilityID: "GHSA-jfh8-c2jp-5v3q", Aliases: []string{"CVE-2021-44228"}},
+		{VulnerabilityID: "CVE-2024-0001"},
+		{VulnerabilityID: "CVE-2024-0002"},
+	}}}
+	if err := client.Annotate(context.Background(), results); err != nil {
+		t.Fatalf("Annotate() error = %v", err)
+	}
+
+	want := []struct{ id, exploit string }{
+		{"CVE-2024-0001", trivy.ExploitWeaponized},
+		{"CVE-2024-0002", trivy.ExploitWeaponized},
+		{"GHSA-jfh8-c2jp-5v3q", trivy.ExploitPublic},
+		{"GHSA-xxxx-yyyy-zzzz", ""},
+	}
+	for i, vuln := range results[0].Vulnerabilities {
+		if vuln.VulnerabilityID != want[i].id || vuln.Exploit != want[i].exploit {
+			t.Errorf("vulnerability %d = %s %q, want %s %q", i, vuln.VulnerabilityID, vuln.Exploit, want[i].id, want[i].exploit)
+		}
+	}
+
+	// A failing source is reported, the others still annotate
+	client.VulnCheck.Token = ""
+	err := client.Annotate(context.Background(), results)
+	if err == nil || !strings.Contains(err.Error(), "vulncheck-kev") {
+		t.Errorf("Annotate() without a VulnCheck token = %v, want its error", err)
+	}
+	if results[0].Vulnerabilities[0].Exploit != trivy.ExploitWeaponized || results[0].Vulnerabilities[1].Exploit != trivy.ExploitPublic {
+		t.Errorf("Annotate() with a failing source = %+v, want the other sources' maturities", results[0].Vulnerabilities)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate([]string{SourceCISAKEV, SourceExploitDB}); err != nil {
+		t.Errorf("Validate() of known sources = %v", err)
+	}
+	if err := Validate([]string{"metasploit"}); err == nil {
+		t.Error("Validate() of an unknown source succeeded")
+	}
+}
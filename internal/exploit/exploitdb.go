@@ -0,0 +1,94 @@
+package exploit
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tamcore/go-autobump/internal/httpclient"
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+// DefaultExploitDBURL is the CSV index of the Exploit-DB repository
+const DefaultExploitDBURL = "https://gitlab.com/exploit-database/exploitdb/-/raw/main/files_exploits.csv"
+
+// ExploitDB reads the CSV index of Exploit-DB, which lists the CVEs each
+// published exploit is for
+type ExploitDB struct {
+	URL        string
+	HTTPClient *http.Client
+}
+
+// NewExploitDB creates a client for the Exploit-DB index at url, or the
+// upstream one if url is empty
+func NewExploitDB(url string) *ExploitDB {
+	if url == "" {
+		url = DefaultExploitDBURL
+	}
+	return &ExploitDB{
+		URL:        url,
+		HTTPClient: httpclient.New(2 * time.Minute),
+	}
+}
+
+// Catalog returns the CVEs with an exploit on Exploit-DB as public
+func (e *ExploitDB) Catalog(ctx context.Context) (Catalog, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", e.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := e.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Exploit-DB request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Exploit-DB index returned status %d", resp.StatusCode)
+	}
+	return parseExploitDB(resp.Body)
+}
+
+// parseExploitDB reads the "codes" column of the index, a semicolon-separated
+// list of CVE, OSVDB and other IDs
+func parseExploitDB(r io.Reader) (Catalog, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Exploit-DB index: %w", err)
+	}
+	codes := slices.Index(header, "codes")
+	if codes < 0 {
+		return nil, errors.New("Exploit-DB index has no codes column")
+	}
+
+	catalog := Catalog{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse Exploit-DB index: %w", err)
+		}
+		if codes >= len(record) {
+			continue
+		}
+		for _, code := range strings.Split(record[codes], ";") {
+			if code = strings.ToUpper(strings.TrimSpace(code)); strings.HasPrefix(code, "CVE-") {
+				catalog.add(code, trivy.ExploitPublic)
+			}
+		}
+	}
+	return catalog, nil
+}
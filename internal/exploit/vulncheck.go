@@ -0,0 +1,98 @@
+package exploit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/tamcore/go-autobump/internal/httpclient"
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+// DefaultVulnCheckEndpoint is the VulnCheck KEV index
+const DefaultVulnCheckEndpoint = "https://api.vulncheck.com/v3/index/vulncheck-kev"
+
+// VulnCheck queries the VulnCheck KEV index, one CVE per request
+type VulnCheck struct {
+	Endpoint   string
+	Token      string
+	HTTPClient *http.Client
+}
+
+// NewVulnCheck creates a client for the VulnCheck KEV index
+func NewVulnCheck(token string) *VulnCheck {
+	return &VulnCheck{
+		Endpoint:   DefaultVulnCheckEndpoint,
+		Token:      token,
+		HTTPClient: httpclient.New(30 * time.Second),
+	}
+}
+
+// vulnCheckResponse is the part of a VulnCheck index response go-autobump
+// uses; every entry of the KEV index was exploited in the wild
+type vulnCheckResponse struct {
+	Data []struct {
+		CVE []string `json:"cve"`
+	} `json:"data"`
+}
+
+// Lookup returns the CVEs of cves in the VulnCheck KEV index as weaponized
+func (v *VulnCheck) Lookup(ctx context.Context, cves []string) (Catalog, error) {
+	if v.Token == "" {
+		return nil, errors.New("no VulnCheck API token (exploits.vulncheck-token or VULNCHECK_API_TOKEN)")
+	}
+
+	catalog := Catalog{}
+	for _, cve := range cves {
+		if err := v.fetch(ctx, cve, catalog); err != nil {
+			return nil, err
+		}
+	}
+	return catalog, nil
+}
+
+// fetch looks up cve and adds the CVEs of the entries found to catalog
+func (v *VulnCheck) fetch(ctx context.Context, cve string, catalog Catalog) error {
+	endpoint := v.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultVulnCheckEndpoint
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint+"?cve="+url.QueryEscape(cve), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "Bearer "+v.Token)
+
+	resp, err := v.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("VulnCheck request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read VulnCheck response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("VulnCheck API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result vulnCheckResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("failed to parse VulnCheck response: %w", err)
+	}
+	for _, entry := range result.Data {
+		for _, id := range entry.CVE {
+			catalog.add(strings.ToUpper(id), trivy.ExploitWeaponized)
+		}
+	}
+	return nil
+}
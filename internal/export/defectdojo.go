@@ -0,0 +1,171 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/tamcore/go-autobump/internal/trivy"
+	"github.com/tamcore/go-autobump/internal/vex"
+)
+
+// DefectDojo uploads findings to DefectDojo with the Generic Findings Import
+// parser. Uploads reimport into the same test, so findings that are gone are
+// closed.
+type DefectDojo struct {
+	// URL is the DefectDojo instance, e.g. https://defectdojo.example.com
+	URL   string
+	Token string
+	// ProductType, Product and Engagement are created if they do not exist
+	ProductType string
+	Product     string
+	Engagement  string
+
+	HTTPClient *http.Client
+}
+
+// NewDefectDojo creates a DefectDojo exporter
+func NewDefectDojo(url, token, productType, product, engagement string) *DefectDojo {
+	return &DefectDojo{
+		URL:         strings.TrimSuffix(url, "/"),
+		Token:       token,
+		ProductType: productType,
+		Product:     product,
+		Engagement:  engagement,
+		HTTPClient:  newHTTPClient(),
+	}
+}
+
+// genericFinding is a finding in DefectDojo's Generic Findings Import format
+type genericFinding struct {
+	Title            string   `json:"title"`
+	Description      string   `json:"description"`
+	Severity         string   `json:"severity"`
+	Mitigation       string   `json:"mitigation"`
+	References       string   `json:"references,omitempty"`
+	VulnerabilityIDs []string `json:"vulnerability_ids"`
+	CVSSv3Score      float64  `json:"cvssv3_score,omitempty"`
+	CWE              int      `json:"cwe,omitempty"`
+	ComponentName    string   `json:"component_name"`
+	ComponentVersion string   `json:"component_version"`
+	FilePath         string   `json:"file_path"`
+	UniqueID         string   `json:"unique_id_from_tool"`
+	VulnIDFromTool   string   `json:"vuln_id_from_tool"`
+	Active           bool     `json:"active"`
+	Verified         bool     `json:"verified"`
+}
+
+// Upload reimports the findings of results. Vulnerabilities with a
+// not_affected VEX statement are uploaded as inactive.
+func (d *DefectDojo) Upload(ctx context.Context, results []trivy.ScanResult, statements []vex.Statement) error {
+	findings := defectDojoFindings(results, statements)
+	report, err := json.Marshal(map[string]any{"findings": findings})
+	if err != nil {
+		return fmt.Errorf("failed to marshal findings: %w", err)
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	fields := map[string]string{
+		"scan_type":           "Generic Findings Import",
+		"test_title":          "go-autobump",
+		"product_type_name":   d.ProductType,
+		"product_name":        d.Product,
+		"engagement_name":     d.Engagement,
+		"auto_create_context": "true",
+		"close_old_findings":  "true",
+		"minimum_severity":    "Info",
+	}
+	for name, value := range fields {
+		if err := form.WriteField(name, value); err != nil {
+			return fmt.Errorf("failed to build upload: %w", err)
+		}
+	}
+	file, err := form.CreateFormFile("file", "go-autobump.json")
+	if err != nil {
+		return fmt.Errorf("failed to build upload: %w", err)
+	}
+	if _, err := file.Write(report); err != nil {
+		return fmt.Errorf("failed to build upload: %w", err)
+	}
+	if err := form.Close(); err != nil {
+		return fmt.Errorf("failed to build upload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", d.URL+"/api/v2/reimport-scan/", &body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "Token "+d.Token)
+	return send(d.HTTPClient, req, nil)
+}
+
+// defectDojoFindings converts results to generic findings
+func defectDojoFindings(results []trivy.ScanResult, statements []vex.Statement) []genericFinding {
+	findings := []genericFinding{}
+	for _, result := range results {
+		for _, vuln := range result.Vulnerabilities {
+			f := genericFinding{
+				Title:            fmt.Sprintf("%s in %s", vuln.VulnerabilityID, vuln.PkgName),
+				Description:      vuln.Description,
+				Severity:         defectDojoSeverity(vuln.Severity),
+				References:       strings.Join(vuln.Links(), "\n"),
+				VulnerabilityIDs: []string{vuln.VulnerabilityID},
+				CVSSv3Score:      vuln.CVSSScore,
+				ComponentName:    vuln.PkgName,
+				ComponentVersion: vuln.InstalledVersion,
+				FilePath:         result.Target,
+				UniqueID:         result.Target + ":" + vuln.VulnerabilityID + ":" + vuln.PkgName,
+				VulnIDFromTool:   vuln.VulnerabilityID,
+				Active:           true,
+			}
+			if len(vuln.CweIDs) > 0 {
+				// DefectDojo records a single CWE per finding
+				f.CWE, _ = strconv.Atoi(strings.TrimPrefix(strings.ToUpper(vuln.CweIDs[0]), "CWE-"))
+			}
+			if vuln.Title != "" {
+				f.Description = vuln.Title + "\n\n" + vuln.Description
+			}
+			if trivy.HasFixedVersion(vuln) {
+				f.Mitigation = fmt.Sprintf("Upgrade %s to %s", vuln.PkgName, vuln.FixedVersion)
+			} else {
+				f.Mitigation = "No fixed version is available yet."
+			}
+			if stmt := vex.FindStatement(statements, vuln); stmt != nil {
+				f.Description += fmt.Sprintf("\n\nVEX status: %s", stmt.Status)
+				if stmt.Justification != "" {
+					f.Description += fmt.Sprintf(" (%s)", stmt.Justification)
+				}
+				if stmt.ImpactStatement != "" {
+					f.Description += "\n" + stmt.ImpactStatement
+				}
+				f.Active = stmt.Status != "not_affected"
+			}
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// defectDojoSeverity maps a Trivy severity to a DefectDojo severity
+func defectDojoSeverity(severity string) string {
+	switch strings.ToUpper(severity) {
+	case "CRITICAL":
+		return "Critical"
+	case "HIGH":
+		return "High"
+	case "MEDIUM":
+		return "Medium"
+	case "LOW":
+		return "Low"
+	default:
+		return "Info"
+	}
+}
@@ -0,0 +1,73 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tamcore/go-autobump/internal/trivy"
+	"github.com/tamcore/go-autobump/internal/vex"
+)
+
+func TestDefectDojoFindings(t *testing.T) {
+	results := []trivy.ScanResult{{
+		Target: "go.mod",
+		Vulnerabilities: []trivy.Vulnerability{
+			{VulnerabilityID: "CVE-1", PkgName: "example.com/a", InstalledVersion: "v1.0.0", FixedVersion: "v1.0.1", Severity: "CRITICAL", CVSSScore: 9.8,
+				PrimaryURL: "https://avd.aquasec.com/nvd/cve-1", References: []string{"https://go.dev/issue/1"}, CweIDs: []string{"CWE-400"}},
+			{VulnerabilityID: "CVE-2", PkgName: "example.com/b", InstalledVersion: "v2.0.0", Severity: "UNKNOWN"},
+		},
+	}}
+	statements := []vex.Statement{{
+		VulnerabilityID: "CVE-2",
+		Products:        []vex.Product{{ID: "example.com/b"}},
+		Status:          "not_affected",
+		Justification:   "vulnerable_code_not_in_execute_path",
+	}}
+
+	findings := defectDojoFindings(results, statements)
+	if len(findings) != 2 {
+		t.Fatalf("got %d findings, want 2", len(findings))
+	}
+
+	if f := findings[0]; f.Severity != "Critical" || !f.Active || f.Mitigation != "Upgrade example.com/a to v1.0.1" ||
+		f.References != "https://avd.aquasec.com/nvd/cve-1\nhttps://go.dev/issue/1" || f.CWE != 400 {
+		t.Errorf("finding 0 = %+v", f)
+	}
+	if f := findings[1]; f.Severity != "Info" || f.Active || f.UniqueID != "go.mod:CVE-2:example.com/b" {
+		t.Errorf("finding 1 = %+v", f)
+	}
+}
+
+func TestDefectDojoUpload(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/reimport-scan/" || r.Header.Get("Authorization") != "Token secret" {
+			t.Errorf("request = %s %s", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		if got := r.FormValue("product_name"); got != "shop" {
+			t.Errorf("product_name = %q", got)
+		}
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			t.Errorf("missing file: %v", err)
+			http.Error(w, "missing file", http.StatusBadRequest)
+			return
+		}
+		var report struct {
+			Findings []genericFinding `json:"findings"`
+		}
+		if err := json.NewDecoder(file).Decode(&report); err != nil || len(report.Findings) != 1 {
+			t.Errorf("report = %+v, %v", report, err)
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	results := []trivy.ScanResult{{Target: "go.mod", Vulnerabilities: []trivy.Vulnerability{{VulnerabilityID: "CVE-1", PkgName: "example.com/a"}}}}
+	dd := NewDefectDojo(server.URL, "secret", "type", "shop", "go-autobump")
+	if err := dd.Upload(context.Background(), results, nil); err != nil {
+		t.Fatal(err)
+	}
+}
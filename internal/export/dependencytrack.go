@@ -0,0 +1,260 @@
+package export
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tamcore/go-autobump/internal/purl"
+	"github.com/tamcore/go-autobump/internal/trivy"
+	"github.com/tamcore/go-autobump/internal/vex"
+)
+
+// DependencyTrack uploads a CycloneDX BOM of the scanned modules to
+// Dependency-Track, followed by a CycloneDX VEX carrying the VEX statements
+type DependencyTrack struct {
+	// URL is the Dependency-Track API server, e.g. https://dtrack.example.com
+	URL    string
+	APIKey string
+	// Project and Version identify the project, which is created if missing
+	Project string
+	Version string
+	// PURL builds the package URLs of BOM components
+	PURL purl.Builder
+
+	HTTPClient *http.Client
+	// pollInterval is the delay between checks whether the BOM is processed
+	pollInterval time.Duration
+}
+
+// NewDependencyTrack creates a Dependency-Track exporter
+func NewDependencyTrack(url, apiKey, project, version string) *DependencyTrack {
+	return &DependencyTrack{
+		URL:          strings.TrimSuffix(url, "/"),
+		APIKey:       apiKey,
+		Project:      project,
+		Version:      version,
+		HTTPClient:   newHTTPClient(),
+		pollInterval: 2 * time.Second,
+	}
+}
+
+// cyclonedx is a CycloneDX 1.5 document, used both as BOM and as VEX
+type cyclonedx struct {
+	BOMFormat       string               `json:"bomFormat"`
+	SpecVersion     string               `json:"specVersion"`
+	Version         int                  `json:"version"`
+	Metadata        *cyclonedxMetadata   `json:"metadata,omitempty"`
+	Components      []cyclonedxComponent `json:"components"`
+	Vulnerabilities []cyclonedxVuln      `json:"vulnerabilities,omitempty"`
+}
+
+type cyclonedxMetadata struct {
+	Timestamp string             `json:"timestamp"`
+	Component cyclonedxComponent `json:"component"`
+}
+
+type cyclonedxComponent struct {
+	Type    string `json:"type"`
+	BOMRef  string `json:"bom-ref,omitempty"`
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	PURL    string `json:"purl,omitempty"`
+}
+
+type cyclonedxVuln struct {
+	ID       string              `json:"id"`
+	Analysis cyclonedxAnalysis   `json:"analysis"`
+	Affects  []cyclonedxAffected `json:"affects"`
+}
+
+type cyclonedxAnalysis struct {
+	State         string `json:"state"`
+	Justification string `json:"justification,omitempty"`
+	Detail        string `json:"detail,omitempty"`
+}
+
+type cyclonedxAffected struct {
+	Ref string `json:"ref"`
+}
+
+// Upload uploads the BOM of components and the vulnerable packages of
+// results, waits until Dependency-Track has processed it, and then uploads
+// statements as VEX, if any
+func (d *DependencyTrack) Upload(ctx context.Context, components []Component, results []trivy.ScanResult, statements []vex.Statement) error {
+	bom := d.bom(components, results)
+	data, err := json.Marshal(bom)
+	if err != nil {
+		return fmt.Errorf("failed to marshal BOM: %w", err)
+	}
+
+	in := map[string]any{
+		"projectName":    d.Project,
+		"projectVersion": d.Version,
+		"autoCreate":     true,
+		"bom":            base64.StdEncoding.EncodeToString(data),
+	}
+	var uploaded struct {
+		Token string `json:"token"`
+	}
+	if err := d.do(ctx, "PUT", "/api/v1/bom", in, &uploaded); err != nil {
+		return err
+	}
+
+	if len(statements) == 0 {
+		return nil
+	}
+
+	// VEX statements only apply to components of a processed BOM
+	if err := d.waitForProcessing(ctx, uploaded.Token); err != nil {
+		return err
+	}
+
+	doc := cyclonedx{
+		BOMFormat:       "CycloneDX",
+		SpecVersion:     "1.5",
+		Version:         1,
+		Components:      bom.Components,
+		Vulnerabilities: cyclonedxVulns(statements),
+	}
+	data, err = json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to marshal VEX: %w", err)
+	}
+	in = map[string]any{
+		"projectName":    d.Project,
+		"projectVersion": d.Version,
+		"vex":            base64.StdEncoding.EncodeToString(data),
+	}
+	return d.do(ctx, "PUT", "/api/v1/vex", in, nil)
+}
+
+// bom builds the CycloneDX BOM of components and vulnerable packages
+func (d *DependencyTrack) bom(components []Component, results []trivy.ScanResult) cyclonedx {
+	seen := make(map[string]bool)
+	var all []cyclonedxComponent
+	add := func(name, version string) {
+		ref := d.PURL.Golang(name, version)
+		if seen[ref] {
+			return
+		}
+		seen[ref] = true
+		all = append(all, cyclonedxComponent{Type: "library", BOMRef: ref, Name: name, Version: version, PURL: ref})
+	}
+	for _, c := range components {
+		add(c.Name, c.Version)
+	}
+	for _, result := range results {
+		for _, vuln := range result.Vulnerabilities {
+			add(vuln.PkgName, vuln.InstalledVersion)
+		}
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].BOMRef < all[j].BOMRef })
+
+	return cyclonedx{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: "1.5",
+		Version:     1,
+		Metadata: &cyclonedxMetadata{
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+			Component: cyclonedxComponent{Type: "application", Name: d.Project, Version: d.Version},
+		},
+		Components: all,
+	}
+}
+
+// waitForProcessing polls the upload token until Dependency-Track finished
+// processing the BOM
+func (d *DependencyTrack) waitForProcessing(ctx context.Context, token string) error {
+	if token == "" {
+		return nil
+	}
+	for {
+		var status struct {
+			Processing bool `json:"processing"`
+		}
+		if err := d.do(ctx, "GET", "/api/v1/event/token/"+token, nil, &status); err != nil {
+			return err
+		}
+		if !status.Processing {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("BOM was not processed in time: %w", ctx.Err())
+		case <-time.After(d.pollInterval):
+		}
+	}
+}
+
+// do sends an authenticated JSON request
+func (d *DependencyTrack) do(ctx context.Context, method, path string, in, out any) error {
+	var req *http.Request
+	var err error
+	if in != nil {
+		req, err = jsonRequest(ctx, method, d.URL+path, in)
+	} else {
+		req, err = http.NewRequestWithContext(ctx, method, d.URL+path, nil)
+	}
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Api-Key", d.APIKey)
+	return send(d.HTTPClient, req, out)
+}
+
+// cyclonedxVulns converts OpenVEX statements to CycloneDX vulnerabilities
+func cyclonedxVulns(statements []vex.Statement) []cyclonedxVuln {
+	var vulns []cyclonedxVuln
+	for _, stmt := range statements {
+		v := cyclonedxVuln{
+			ID: stmt.VulnerabilityID,
+			Analysis: cyclonedxAnalysis{
+				State:         cyclonedxState(stmt.Status),
+				Justification: cyclonedxJustification(stmt.Justification),
+				Detail:        stmt.ImpactStatement,
+			},
+		}
+		for _, module := range stmt.Modules() {
+			v.Affects = append(v.Affects, cyclonedxAffected{Ref: module.Identifiers.PURL})
+		}
+		vulns = append(vulns, v)
+	}
+	return vulns
+}
+
+// cyclonedxState maps an OpenVEX status to a CycloneDX analysis state
+func cyclonedxState(status string) string {
+	switch status {
+	case "not_affected":
+		return "not_affected"
+	case "affected":
+		return "exploitable"
+	case "fixed":
+		return "resolved"
+	default:
+		return "in_triage"
+	}
+}
+
+// cyclonedxJustification maps an OpenVEX justification to its closest
+// CycloneDX counterpart
+func cyclonedxJustification(justification string) string {
+	switch justification {
+	case "component_not_present", "vulnerable_code_not_present":
+		return "code_not_present"
+	case "vulnerable_code_not_in_execute_path":
+		return "code_not_reachable"
+	case "vulnerable_code_cannot_be_controlled_by_adversary":
+		return "requires_environment"
+	case "inline_mitigations_already_exist":
+		return "protected_by_mitigating_control"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,78 @@
+package export
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tamcore/go-autobump/internal/trivy"
+	"github.com/tamcore/go-autobump/internal/vex"
+)
+
+func TestDependencyTrackUpload(t *testing.T) {
+	var requests []string
+	var bom, vexDoc cyclonedx
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if r.Header.Get("X-Api-Key") != "key" {
+			t.Errorf("X-Api-Key = %q", r.Header.Get("X-Api-Key"))
+		}
+		decode := func(field string, out *cyclonedx) {
+			var in map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&in)
+			data, _ := base64.StdEncoding.DecodeString(in[field].(string))
+			_ = json.Unmarshal(data, out)
+		}
+		switch r.URL.Path {
+		case "/api/v1/bom":
+			decode("bom", &bom)
+			_, _ = w.Write([]byte(`{"token": "abc"}`))
+		case "/api/v1/event/token/abc":
+			polls++
+			_, _ = fmt.Fprintf(w, `{"processing": %t}`, polls < 2)
+		case "/api/v1/vex":
+			decode("vex", &vexDoc)
+		}
+	}))
+	defer server.Close()
+
+	dt := NewDependencyTrack(server.URL, "key", "shop", "main")
+	dt.pollInterval = 0
+
+	components := []Component{{Name: "example.com/a", Version: "v1.0.0"}, {Name: "example.com/b", Version: "v2.0.0"}}
+	results := []trivy.ScanResult{{Target: "go.mod", Vulnerabilities: []trivy.Vulnerability{
+		{VulnerabilityID: "CVE-1", PkgName: "example.com/a", InstalledVersion: "v1.0.0"},
+	}}}
+	statements := []vex.Statement{{
+		VulnerabilityID: "CVE-1",
+		Products:        []vex.Product{{ID: "example.com/a", Identifiers: vex.Identifiers{PURL: "pkg:golang/example.com/a@v1.0.0"}}},
+		Status:          "affected",
+	}}
+
+	if err := dt.Upload(context.Background(), components, results, statements); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"PUT /api/v1/bom", "GET /api/v1/event/token/abc", "GET /api/v1/event/token/abc", "PUT /api/v1/vex"}
+	if len(requests) != len(want) {
+		t.Fatalf("requests = %v, want %v", requests, want)
+	}
+	for i := range want {
+		if requests[i] != want[i] {
+			t.Errorf("request %d = %q, want %q", i, requests[i], want[i])
+		}
+	}
+
+	if len(bom.Components) != 2 || bom.Metadata.Component.Name != "shop" {
+		t.Errorf("bom = %+v", bom)
+	}
+	if len(vexDoc.Vulnerabilities) != 1 || vexDoc.Vulnerabilities[0].Analysis.State != "exploitable" ||
+		vexDoc.Vulnerabilities[0].Affects[0].Ref != "pkg:golang/example.com/a@v1.0.0" {
+		t.Errorf("vex = %+v", vexDoc)
+	}
+}
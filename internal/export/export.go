@@ -0,0 +1,67 @@
+// Package export pushes scan results and VEX statements to vulnerability
+// management systems (DefectDojo, Dependency-Track)
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tamcore/go-autobump/internal/httpclient"
+)
+
+// Component is a Go module version in the scanned tree
+type Component struct {
+	Name    string
+	Version string
+}
+
+// newHTTPClient returns the HTTP client used for uploads
+func newHTTPClient() *http.Client {
+	return httpclient.New(2 * time.Minute)
+}
+
+// send performs req and decodes the JSON response into out, if out is not nil
+func send(client *http.Client, req *http.Request, out any) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s returned status %d: %s", req.Method, req.URL.Path, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+	}
+	return nil
+}
+
+// jsonRequest creates a request with in encoded as JSON body
+func jsonRequest(ctx context.Context, method, url string, in any) (*http.Request, error) {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	return req, nil
+}
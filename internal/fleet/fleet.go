@@ -0,0 +1,280 @@
+// Package fleet runs go-autobump across many repositories: it discovers the
+// Go repositories of a GitHub organization and runs a job for each of them.
+package fleet
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tamcore/go-autobump/internal/forge"
+)
+
+// Org is an organization whose repositories can be discovered
+type Org interface {
+	Repositories(ctx context.Context) ([]forge.Repository, error)
+	Languages(ctx context.Context, fullName string) (map[string]int64, error)
+}
+
+// DiscoverOptions filters the repositories of Discover
+type DiscoverOptions struct {
+	// Topics keeps only repositories carrying all of them
+	Topics []string
+	// IncludeForks keeps forked repositories
+	IncludeForks bool
+	// IncludeArchived keeps archived repositories, which cannot take updates
+	IncludeArchived bool
+}
+
+// Discover returns the repositories of org that contain Go code and match
+// opts, in the order the forge lists them
+func Discover(ctx context.Context, org Org, opts DiscoverOptions) ([]forge.Repository, error) {
+	repos, err := org.Repositories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []forge.Repository
+	for _, repo := range repos {
+		if (repo.Fork && !opts.IncludeForks) || (repo.Archived && !opts.IncludeArchived) || !hasTopics(repo, opts.Topics) {
+			continue
+		}
+		// The primary language only names the largest one, so Go modules
+		// of repositories mostly written in another language are found by
+		// their language breakdown
+		if repo.Language != "Go" {
+			languages, err := org.Languages(ctx, repo.FullName)
+			if err != nil {
+				return nil, err
+			}
+			if languages["Go"] == 0 {
+				continue
+			}
+		}
+		matched = append(matched, repo)
+	}
+	return matched, nil
+}
+
+// hasTopics reports whether repo carries all topics
+func hasTopics(repo forge.Repository, topics []string) bool {
+	for _, topic := range topics {
+		if !slices.Contains(repo.Topics, strings.ToLower(topic)) {
+			return false
+		}
+	}
+	return true
+}
+
+// ReadRepos reads a list of repositories, one per line; blank lines and
+// lines starting with # are skipped
+func ReadRepos(r io.Reader) ([]string, error) {
+	var repos []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			repos = append(repos, line)
+		}
+	}
+	return repos, scanner.Err()
+}
+
+// Dedupe returns repos without the repeated repositories, keeping the first
+// of each. Clone URLs with and without a trailing .git or slash name the same
+// repository.
+func Dedupe(repos []string) []string {
+	seen := make(map[string]bool, len(repos))
+	var unique []string
+	for _, repo := range repos {
+		key := strings.TrimSuffix(strings.TrimSuffix(repo, "/"), ".git")
+		if !seen[key] {
+			seen[key] = true
+			unique = append(unique, repo)
+		}
+	}
+	return unique
+}
+
+// Job runs go-autobump on one repository and returns its exit code; err is
+// set if the job could not be run at all
+type Job func(ctx context.Context, repo string) (exitCode int, err error)
+
+// Result is the outcome of the job of one repository
+type Result struct {
+	Repo     string
+	ExitCode int
+	Err      error
+	Duration time.Duration
+}
+
+// RunOptions paces the jobs of Run
+type RunOptions struct {
+	// Concurrency is the number of jobs run at the same time (default: 1)
+	Concurrency int
+	// Interval is the minimum time between the starts of two jobs
+	Interval time.Duration
+	// Timeout bounds the time of each job (0: no limit)
+	Timeout time.Duration
+}
+
+// Run runs job on each repository, up to opts.Concurrency at a time, and
+// returns the results in the order of repos. Repositories not started when
+// ctx ends are reported with its error.
+func Run(ctx context.Context, repos []string, opts RunOptions, job Job) []Result {
+	results := make([]Result, len(repos))
+	slots := make(chan struct{}, max(opts.Concurrency, 1))
+	var wg sync.WaitGroup
+
+	next := time.Now()
+	for i, repo := range repos {
+		err := sleepUntil(ctx, next)
+		if err == nil {
+			select {
+			case slots <- struct{}{}:
+				if err = ctx.Err(); err != nil {
+					<-slots
+				}
+			case <-ctx.Done():
+				err = ctx.Err()
+			}
+		}
+		if err != nil {
+			results[i] = Result{Repo: repo, ExitCode: 1, Err: err}
+			continue
+		}
+
+		next = time.Now().Add(opts.Interval)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			results[i] = runJob(ctx, repo, opts.Timeout, job)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// runJob runs the job of one repository, cancelling it after timeout
+func runJob(ctx context.Context, repo string, timeout time.Duration, job Job) Result {
+	var timedOut error
+	if timeout > 0 {
+		timedOut = fmt.Errorf("timed out after %s", timeout)
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, timeout, timedOut)
+		defer cancel()
+	}
+
+	start := time.Now()
+	code, err := job(ctx, repo)
+	if (err != nil || code != 0) && timedOut != nil && context.Cause(ctx) == timedOut {
+		err = timedOut
+	}
+	if err != nil && code == 0 {
+		code = 1
+	}
+	return Result{Repo: repo, ExitCode: code, Err: err, Duration: time.Since(start)}
+}
+
+// sleepUntil waits until t or until ctx ends
+func sleepUntil(ctx context.Context, t time.Time) error {
+	wait := time.Until(t)
+	if wait <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// jobStopGrace is how long a cancelled job may take to stop cleanly, e.g.
+// to roll back its modules, before it is killed
+const jobStopGrace = 30 * time.Second
+
+// ExecJob returns a Job that runs executable with args followed by the
+// repository, passing its output through. If prefix is set, each line of the
+// output starts with the prefix it returns for the repository, so the output
+// of jobs running at the same time can be told apart. A cancelled job is
+// interrupted, and killed if it has not stopped after a grace period.
+func ExecJob(executable string, args []string, prefix func(repo string) string) Job {
+	return func(ctx context.Context, repo string) (int, error) {
+		cmd := exec.CommandContext(ctx, executable, append(slices.Clone(args), repo)...)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if prefix != nil {
+			stdout := &prefixWriter{w: os.Stdout, prefix: prefix(repo)}
+			stderr := &prefixWriter{w: os.Stderr, prefix: prefix(repo)}
+			defer stdout.Flush()
+			defer stderr.Flush()
+			cmd.Stdout, cmd.Stderr = stdout, stderr
+		}
+		cmd.Cancel = func() error { return cmd.Process.Signal(os.Interrupt) }
+		cmd.WaitDelay = jobStopGrace
+		// The job runs in a process group of its own, so an interrupt of the
+		// terminal only reaches it through the cancelled context, once
+		setProcessGroup(cmd)
+
+		err := cmd.Run()
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && ctx.Err() == nil {
+			return exitErr.ExitCode(), nil
+		}
+		return 0, err
+	}
+}
+
+// outputMu keeps the lines of jobs running at the same time from
+// interleaving
+var outputMu sync.Mutex
+
+// prefixWriter writes each line written to it to w, starting with prefix.
+// A last line without newline is held back until Flush.
+type prefixWriter struct {
+	w      io.Writer
+	prefix string
+	buf    []byte
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.buf = append(p.buf, b...)
+	for {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i < 0 {
+			return len(b), nil
+		}
+		if err := p.writeLine(p.buf[:i+1]); err != nil {
+			return len(b), err
+		}
+		p.buf = p.buf[i+1:]
+	}
+}
+
+// Flush writes the held back last line, if any
+func (p *prefixWriter) Flush() {
+	if len(p.buf) > 0 {
+		_ = p.writeLine(append(p.buf, '\n'))
+		p.buf = nil
+	}
+}
+
+func (p *prefixWriter) writeLine(line []byte) error {
+	outputMu.Lock()
+	defer outputMu.Unlock()
+	_, err := io.WriteString(p.w, p.prefix+string(line))
+	return err
+}
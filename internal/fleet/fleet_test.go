@@ -0,0 +1,179 @@
+package fleet
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tamcore/go-autobump/internal/forge"
+)
+
+// fakeOrg serves repositories and their language breakdown
+type fakeOrg struct {
+	repos     []forge.Repository
+	languages map[string]map[string]int64
+	looked    []string
+}
+
+func (o *fakeOrg) Repositories(context.Context) ([]forge.Repository, error) {
+	return o.repos, nil
+}
+
+func (o *fakeOrg) Languages(_ context.Context, fullName string) (map[string]int64, error) {
+	o.looked = append(o.looked, fullName)
+	return o.languages[fullName], nil
+}
+
+func TestDiscover(t *testing.T) {
+	org := &fakeOrg{
+		repos: []forge.Repository{
+			{FullName: "org/api", Language: "Go", Topics: []string{"golang", "backend"}},
+			{FullName: "org/web", Language: "TypeScript", Topics: []string{"golang"}},
+			{FullName: "org/docs", Language: "Markdown", Topics: []string{"golang"}},
+			{FullName: "org/fork", Language: "Go", Topics: []string{"golang"}, Fork: true},
+			{FullName: "org/old", Language: "Go", Topics: []string{"golang"}, Archived: true},
+			{FullName: "org/untagged", Language: "Go"},
+		},
+		languages: map[string]map[string]int64{
+			"org/web":  {"TypeScript": 9000, "Go": 120},
+			"org/docs": {"Markdown": 500},
+		},
+	}
+
+	repos, err := Discover(context.Background(), org, DiscoverOptions{Topics: []string{"golang"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, r := range repos {
+		names = append(names, r.FullName)
+	}
+	if want := []string{"org/api", "org/web"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Discover() = %v, want %v", names, want)
+	}
+	if want := []string{"org/web", "org/docs"}; !reflect.DeepEqual(org.looked, want) {
+		t.Errorf("languages looked up for %v, want only %v", org.looked, want)
+	}
+
+	repos, err = Discover(context.Background(), org, DiscoverOptions{IncludeForks: true, IncludeArchived: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(repos) != 5 {
+		t.Errorf("Discover() without filters = %d repositories, want 5", len(repos))
+	}
+}
+
+func TestReadRepos(t *testing.T) {
+	repos, err := ReadRepos(strings.NewReader("# services\nhttps://github.com/org/api.git\n\n  ./local/module  \n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"https://github.com/org/api.git", "./local/module"}; !reflect.DeepEqual(repos, want) {
+		t.Errorf("ReadRepos() = %v, want %v", repos, want)
+	}
+}
+
+func TestDedupe(t *testing.T) {
+	repos := Dedupe([]string{
+		"https://github.com/org/api.git", "./local/module", "https://github.com/org/api",
+		"https://github.com/org/web", "./local/module/", "https://github.com/org/api.git",
+	})
+	if want := []string{"https://github.com/org/api.git", "./local/module", "https://github.com/org/web"}; !reflect.DeepEqual(repos, want) {
+		t.Errorf("Dedupe() = %v, want %v", repos, want)
+	}
+}
+
+func TestRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	results := Run(ctx, []string{"a", "b", "c", "d"}, RunOptions{}, func(_ context.Context, repo string) (int, error) {
+		switch repo {
+		case "b":
+			return 2, nil
+		case "c":
+			cancel()
+			return 0, errors.New("interrupted")
+		}
+		return 0, nil
+	})
+
+	want := []struct {
+		code   int
+		failed bool
+	}{{0, false}, {2, false}, {1, true}, {1, true}}
+	for i, r := range results {
+		if r.ExitCode != want[i].code || (r.Err != nil) != want[i].failed {
+			t.Errorf("result %s = exit %d, error %v, want exit %d", r.Repo, r.ExitCode, r.Err, want[i].code)
+		}
+	}
+}
+
+func TestRunPacing(t *testing.T) {
+	var running, peak atomic.Int32
+	job := func(ctx context.Context, repo string) (int, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			if p := peak.Load(); n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		if repo == "slow" {
+			<-ctx.Done()
+			return 4, errors.New("signal: interrupt")
+		}
+		time.Sleep(50 * time.Millisecond)
+		return 0, nil
+	}
+
+	repos := []string{"a", "slow", "b", "c"}
+	results := Run(context.Background(), repos, RunOptions{Concurrency: 2, Timeout: 200 * time.Millisecond}, job)
+
+	if p := peak.Load(); p != 2 {
+		t.Errorf("%d jobs ran at the same time, want 2", p)
+	}
+	for i, r := range results {
+		if r.Repo != repos[i] {
+			t.Errorf("result %d is of %s, want %s", i, r.Repo, repos[i])
+		}
+	}
+	if r := results[1]; r.ExitCode != 4 || r.Err == nil || r.Err.Error() != "timed out after 200ms" {
+		t.Errorf("slow job = exit %d, error %v, want timed out", r.ExitCode, r.Err)
+	}
+	if r := results[3]; r.ExitCode != 0 || r.Err != nil {
+		t.Errorf("job c = exit %d, error %v", r.ExitCode, r.Err)
+	}
+
+	// Jobs start at least an interval apart, however many may run
+	start := time.Now()
+	Run(context.Background(), repos, RunOptions{Concurrency: 4, Interval: 20 * time.Millisecond}, func(context.Context, string) (int, error) {
+		return 0, nil
+	})
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+		t.Errorf("4 jobs 20ms apart ran in %s", elapsed)
+	}
+}
+
+func TestPrefixWriter(t *testing.T) {
+	var out bytes.Buffer
+	w := &prefixWriter{w: &out, prefix: "[repo] "}
+	for _, chunk := range []string{"first line\nsec", "ond line\n", "unterminated"} {
+		if _, err := w.Write([]byte(chunk)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want := "[repo] first line\n[repo] second line\n"; out.String() != want {
+		t.Errorf("output before Flush = %q, want %q", out.String(), want)
+	}
+	w.Flush()
+	if want := "[repo] first line\n[repo] second line\n[repo] unterminated\n"; out.String() != want {
+		t.Errorf("output after Flush = %q, want %q", out.String(), want)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package fleet
+
+import "os/exec"
+
+// setProcessGroup is a no-op where process groups are unsupported
+func setProcessGroup(*exec.Cmd) {}
@@ -0,0 +1,336 @@
+// Package forge talks to code hosting providers (GitHub, GitLab) to open
+// issues and comment on pull requests on behalf of a run
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/tamcore/go-autobump/internal/httpclient"
+)
+
+// Providers
+const (
+	ProviderGitHub = "github"
+	ProviderGitLab = "gitlab"
+)
+
+// Label is attached to every issue go-autobump opens, to find them again
+const Label = "go-autobump"
+
+// Issue is an issue on the forge
+type Issue struct {
+	Number int
+	Title  string
+	Body   string
+	URL    string
+	Open   bool
+}
+
+// Comment is a comment on a pull/merge request
+type Comment struct {
+	ID   int64
+	Body string
+	// Author is the login of the user who wrote the comment
+	Author string
+}
+
+// Commit status states
+const (
+	StatusSuccess = "success"
+	StatusFailure = "failure"
+)
+
+// CommitStatus is the outcome of a check on a commit, which branch
+// protection rules can require
+type CommitStatus struct {
+	// State is StatusSuccess or StatusFailure
+	State string
+	// Context names the check, e.g. "go-autobump"
+	Context string
+	// Description is a one-line summary of the outcome
+	Description string
+	// TargetURL, if set, links the details, e.g. the CI job
+	TargetURL string
+}
+
+// Provider is a code hosting provider
+type Provider interface {
+	// Issues lists the open issues carrying label
+	Issues(ctx context.Context, label string) ([]Issue, error)
+	// CreateIssue opens a new issue
+	CreateIssue(ctx context.Context, title, body string, labels []string) (*Issue, error)
+	// CommentIssue adds a comment to an issue
+	CommentIssue(ctx context.Context, number int, body string) error
+	// CloseIssue closes an issue, adding comment first if it is not empty
+	CloseIssue(ctx context.Context, number int, comment string) error
+	// CommentPullRequest adds a comment to a pull/merge request
+	CommentPullRequest(ctx context.Context, number int, body string) error
+	// PullRequestComments lists the comments of a pull/merge request
+	PullRequestComments(ctx context.Context, number int) ([]Comment, error)
+	// EditPullRequestComment replaces the body of a comment of a pull/merge request
+	EditPullRequestComment(ctx context.Context, number int, id int64, body string) error
+	// SetCommitStatus sets the status of a check on commit sha, replacing an
+	// earlier status of the same context
+	SetCommitStatus(ctx context.Context, sha string, status CommitStatus) error
+	// User returns the login of the authenticated user, which authors the
+	// comments the Provider writes
+	User(ctx context.Context) (string, error)
+	// CheckAccess verifies that the repository can be read with the
+	// configured credentials
+	CheckAccess(ctx context.Context) error
+}
+
+// Options configures a Provider
+type Options struct {
+	// Provider is github or gitlab
+	Provider string
+	// Repo is "owner/name" on GitHub or the project path on GitLab
+	Repo string
+	// Token authenticates API requests
+	Token string
+	// URL is the API base URL, for GitHub Enterprise or self-hosted GitLab
+	URL string
+
+	// AppID, if set, authenticates as an installation of this GitHub App
+	// instead of with Token
+	AppID int64
+	// AppPrivateKey is the PEM-encoded private key of the GitHub App
+	AppPrivateKey string
+	// AppInstallationID is the installation of the GitHub App (default: its
+	// installation on Repo)
+	AppInstallationID int64
+
+	// RateLimit caps the API requests per second to the forge, shared by
+	// all clients of the process (0: no limit)
+	RateLimit float64
+}
+
+// New creates the Provider configured by opts. Missing settings fall back to
+// the environment of GitHub Actions and GitLab CI.
+func New(opts Options) (Provider, error) {
+	switch opts.Provider {
+	case ProviderGitHub:
+		repo := firstNonEmpty(opts.Repo, os.Getenv("GITHUB_REPOSITORY"))
+		c, app, err := newGitHubClient(opts, "/repos/"+repo+"/installation")
+		if err != nil {
+			return nil, err
+		}
+		return &github{client: c, repo: repo, app: app}, nil
+	case ProviderGitLab:
+		token := firstNonEmpty(opts.Token, os.Getenv("GITLAB_TOKEN"))
+		header := "PRIVATE-TOKEN"
+		if token == "" {
+			// The CI job token is accepted under its own header
+			token, header = os.Getenv("CI_JOB_TOKEN"), "JOB-TOKEN"
+		}
+		c := newClient(firstNonEmpty(opts.URL, os.Getenv("CI_API_V4_URL"), "https://gitlab.com/api/v4"),
+			headerAuth(header, "", token))
+		c.throttle = throttleFor(c.baseURL, opts.RateLimit)
+		return &gitlab{client: c, project: firstNonEmpty(opts.Repo, os.Getenv("CI_PROJECT_PATH"))}, nil
+	case "":
+		return nil, fmt.Errorf("no forge provider configured (set forge.provider to github or gitlab)")
+	default:
+		return nil, fmt.Errorf("unknown forge provider %q (valid: github, gitlab)", opts.Provider)
+	}
+}
+
+// newGitHubClient returns a client of the GitHub API authenticated by opts,
+// as a GitHub App looking up its installation at installationPath if
+// opts.AppID is set, and the app authentication or nil
+func newGitHubClient(opts Options, installationPath string) (*client, *appAuth, error) {
+	token := firstNonEmpty(opts.Token, os.Getenv("GITHUB_TOKEN"))
+	c := newClient(firstNonEmpty(opts.URL, os.Getenv("GITHUB_API_URL"), "https://api.github.com"),
+		headerAuth("Authorization", "Bearer ", token))
+	c.throttle = throttleFor(c.baseURL, opts.RateLimit)
+	if opts.AppID != 0 {
+		app, err := newAppAuth(c, opts.AppID, opts.AppPrivateKey, opts.AppInstallationID, installationPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		c.auth = app.authenticate
+		return c, app, nil
+	}
+	return c, nil, nil
+}
+
+// Marker returns a hidden HTML comment identifying the subject of an issue
+// or comment, so later runs can find it again
+func Marker(key string) string {
+	return "<!-- go-autobump:" + key + " -->"
+}
+
+// ParseMarker returns the key of the first marker in body
+func ParseMarker(body string) (string, bool) {
+	const prefix = "<!-- go-autobump:"
+	start := strings.Index(body, prefix)
+	if start < 0 {
+		return "", false
+	}
+	rest := body[start+len(prefix):]
+	end := strings.Index(rest, " -->")
+	if end < 0 {
+		return "", false
+	}
+	return rest[:end], true
+}
+
+// FindIssue returns the open issue with label whose body contains the marker
+// for key, or nil if there is none
+func FindIssue(ctx context.Context, p Provider, label, key string) (*Issue, error) {
+	issues, err := p.Issues(ctx, label)
+	if err != nil {
+		return nil, err
+	}
+	marker := Marker(key)
+	for i := range issues {
+		if strings.Contains(issues[i].Body, marker) {
+			return &issues[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// UpsertPullRequestComment keeps a single comment for key on a pull/merge
+// request: it replaces the body of the comment the authenticated user wrote
+// starting with the marker for key, or adds the comment if there is none
+// yet. Comments of others quoting the marker are left alone. It reports
+// whether it added one.
+func UpsertPullRequestComment(ctx context.Context, p Provider, number int, key, body string) (bool, error) {
+	comments, err := p.PullRequestComments(ctx, number)
+	if err != nil {
+		return false, err
+	}
+
+	marker := Marker(key)
+	body = marker + "\n" + body
+	var user string
+	for _, c := range comments {
+		if !strings.HasPrefix(c.Body, marker) {
+			continue
+		}
+		if user == "" {
+			if user, err = p.User(ctx); err != nil {
+				return false, fmt.Errorf("failed to look up the authenticated user: %w", err)
+			}
+		}
+		if c.Author == user {
+			return false, p.EditPullRequestComment(ctx, number, c.ID, body)
+		}
+	}
+	return true, p.CommentPullRequest(ctx, number, body)
+}
+
+// RunURL returns the URL of the current GitHub Actions run or GitLab CI job,
+// or empty outside of them
+func RunURL() string {
+	if id := os.Getenv("GITHUB_RUN_ID"); id != "" && os.Getenv("GITHUB_REPOSITORY") != "" {
+		server := firstNonEmpty(os.Getenv("GITHUB_SERVER_URL"), "https://github.com")
+		return server + "/" + os.Getenv("GITHUB_REPOSITORY") + "/actions/runs/" + id
+	}
+	return os.Getenv("CI_JOB_URL")
+}
+
+// CICommit returns the commit the current GitHub Actions or GitLab CI job
+// runs for, or empty outside of them. For pull and merge requests, that is
+// their head commit rather than the merge commit the job checks out.
+func CICommit() string {
+	if path := os.Getenv("GITHUB_EVENT_PATH"); path != "" {
+		if data, err := os.ReadFile(path); err == nil {
+			var event struct {
+				PullRequest struct {
+					Head struct {
+						SHA string `json:"sha"`
+					} `json:"head"`
+				} `json:"pull_request"`
+			}
+			if json.Unmarshal(data, &event) == nil && event.PullRequest.Head.SHA != "" {
+				return event.PullRequest.Head.SHA
+			}
+		}
+	}
+	return firstNonEmpty(os.Getenv("CI_MERGE_REQUEST_SOURCE_BRANCH_SHA"), os.Getenv("GITHUB_SHA"), os.Getenv("CI_COMMIT_SHA"))
+}
+
+// truncate shortens s to at most n bytes, marking the cut with an ellipsis
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-3] + "..."
+}
+
+// client performs authenticated JSON requests against a REST API
+type client struct {
+	baseURL    string
+	auth       func(req *http.Request) error
+	httpClient *http.Client
+	// throttle, if set, paces the requests
+	throttle *throttle
+}
+
+func newClient(baseURL string, auth func(req *http.Request) error) *client {
+	return &client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		auth:       auth,
+		httpClient: httpclient.New(30 * time.Second),
+	}
+}
+
+// headerAuth authenticates requests by setting header to prefix+token.
+// Requests are sent unauthenticated if token is empty.
+func headerAuth(header, prefix, token string) func(req *http.Request) error {
+	return func(req *http.Request) error {
+		if token != "" {
+			req.Header.Set(header, prefix+token)
+		}
+		return nil
+	}
+}
+
+// do sends a request with an optional JSON body and decodes the JSON
+// response into out, if out is not nil. A rate-limited request is retried
+// once after the limit resets, if that is soon enough.
+func (c *client) do(ctx context.Context, method, path string, in, out any) error {
+	for attempt := 0; ; attempt++ {
+		if err := c.throttle.wait(ctx); err != nil {
+			return err
+		}
+
+		req, err := httpclient.NewJSONRequest(ctx, method, c.baseURL+path, in)
+		if err != nil {
+			return err
+		}
+		if err := c.auth(req); err != nil {
+			return fmt.Errorf("failed to authenticate: %w", err)
+		}
+
+		resp, err := httpclient.DoJSON(c.httpClient, req, out)
+		if resp != nil && attempt == 0 {
+			if wait, limited := rateLimitWait(resp, time.Now()); limited && wait <= maxRateLimitWait {
+				select {
+				case <-time.After(wait):
+					continue
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+		}
+		return err
+	}
+}
+
+// firstNonEmpty returns the first non-empty value
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
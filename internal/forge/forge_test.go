@@ -0,0 +1,255 @@
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGitHub(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+strings.TrimSpace(string(body)))
+
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Authorization = %q", got)
+		}
+
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/repos/org/repo/issues":
+			if r.URL.Query().Get("labels") != Label {
+				t.Errorf("labels = %q", r.URL.Query().Get("labels"))
+			}
+			_ = json.NewEncoder(w).Encode([]map[string]any{
+				{"number": 1, "title": "PR", "body": Marker("a"), "state": "open", "pull_request": map[string]any{}},
+				{"number": 2, "title": "other", "body": Marker("b"), "state": "open"},
+				{"number": 3, "title": "wanted", "body": "text\n" + Marker("a"), "state": "open"},
+			})
+		case r.Method == "POST" && r.URL.Path == "/repos/org/repo/issues":
+			_, _ = w.Write([]byte(`{"number": 4, "html_url": "https://github.com/org/repo/issues/4", "state": "open"}`))
+		default:
+			_, _ = w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	p, err := New(Options{Provider: ProviderGitHub, Repo: "org/repo", Token: "secret", URL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	issue, err := FindIssue(ctx, p, Label, "a")
+	if err != nil {
+		t.Fatalf("FindIssue() error = %v", err)
+	}
+	if issue == nil || issue.Number != 3 {
+		t.Fatalf("FindIssue() = %+v, want issue 3 (pull requests are skipped)", issue)
+	}
+
+	created, err := p.CreateIssue(ctx, "title", "body", []string{Label})
+	if err != nil {
+		t.Fatalf("CreateIssue() error = %v", err)
+	}
+	if created.Number != 4 || created.URL == "" {
+		t.Errorf("CreateIssue() = %+v", created)
+	}
+
+	if err := p.CloseIssue(ctx, 3, "fixed"); err != nil {
+		t.Fatalf("CloseIssue() error = %v", err)
+	}
+
+	want := []string{
+		"POST /repos/org/repo/issues/3/comments {\"body\":\"fixed\"}",
+		"PATCH /repos/org/repo/issues/3 {\"state\":\"closed\",\"state_reason\":\"completed\"}",
+	}
+	got := requests[len(requests)-2:]
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("request %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	if err := p.CheckAccess(ctx); err != nil {
+		t.Fatalf("CheckAccess() error = %v", err)
+	}
+	if got := requests[len(requests)-1]; got != "GET /repos/org/repo " {
+		t.Errorf("CheckAccess() request = %q", got)
+	}
+}
+
+func TestGitLabProjectPath(t *testing.T) {
+	var gotPath, gotToken string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.EscapedPath()
+		gotToken = r.Header.Get("PRIVATE-TOKEN")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	p, err := New(Options{Provider: ProviderGitLab, Repo: "group/sub/project", Token: "secret", URL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.CommentPullRequest(context.Background(), 7, "hello"); err != nil {
+		t.Fatalf("CommentPullRequest() error = %v", err)
+	}
+	if gotPath != "/projects/group%2Fsub%2Fproject/merge_requests/7/notes" {
+		t.Errorf("path = %s", gotPath)
+	}
+	if gotToken != "secret" {
+		t.Errorf("PRIVATE-TOKEN = %q", gotToken)
+	}
+}
+
+func TestUpsertPullRequestComment(t *testing.T) {
+	var comments []map[string]any
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.Path)
+
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/user":
+			_, _ = w.Write([]byte(`{"login":"autobump-bot"}`))
+		case r.Method == "GET" && r.URL.Path == "/repos/org/repo/issues/5/comments":
+			_ = json.NewEncoder(w).Encode(comments)
+		case r.Method == "POST" && r.URL.Path == "/repos/org/repo/issues/5/comments":
+			var in map[string]any
+			_ = json.Unmarshal(body, &in)
+			comments = append(comments, map[string]any{"id": 100 + len(comments), "body": in["body"], "user": map[string]any{"login": "autobump-bot"}})
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			_, _ = w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	p, err := New(Options{Provider: ProviderGitHub, Repo: "org/repo", Token: "secret", URL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	// Neither an unrelated comment nor another user's comment starting with
+	// or quoting the marker is taken over
+	comments = append(comments,
+		map[string]any{"id": 1, "body": "unrelated", "user": map[string]any{"login": "autobump-bot"}},
+		map[string]any{"id": 2, "body": Marker("summary") + "\nforged", "user": map[string]any{"login": "someone"}},
+		map[string]any{"id": 3, "body": "> " + Marker("summary"), "user": map[string]any{"login": "autobump-bot"}},
+	)
+	added, err := UpsertPullRequestComment(ctx, p, 5, "summary", "first")
+	if err != nil || !added {
+		t.Fatalf("UpsertPullRequestComment() = %v, %v, want added", added, err)
+	}
+	if !strings.HasPrefix(comments[3]["body"].(string), Marker("summary")) {
+		t.Errorf("added comment %q does not start with the marker", comments[3]["body"])
+	}
+
+	added, err = UpsertPullRequestComment(ctx, p, 5, "summary", "second")
+	if err != nil || added {
+		t.Fatalf("UpsertPullRequestComment() = %v, %v, want edited", added, err)
+	}
+	if got := requests[len(requests)-1]; got != "PATCH /repos/org/repo/issues/comments/103" {
+		t.Errorf("request = %q, want the own marked comment edited", got)
+	}
+}
+
+func TestSetCommitStatus(t *testing.T) {
+	tests := []struct {
+		provider string
+		repo     string
+		wantPath string
+		wantBody string
+	}{
+		{ProviderGitHub, "org/repo", "/repos/org/repo/statuses/abc123",
+			`{"context":"go-autobump","description":"2 critical unfixed","state":"failure","target_url":"https://ci/1"}`},
+		{ProviderGitLab, "group/project", "/projects/group%2Fproject/statuses/abc123",
+			`{"description":"2 critical unfixed","name":"go-autobump","state":"failed","target_url":"https://ci/1"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			var gotPath, gotBody string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				gotPath, gotBody = r.URL.EscapedPath(), strings.TrimSpace(string(body))
+				_, _ = w.Write([]byte(`{}`))
+			}))
+			defer server.Close()
+
+			p, err := New(Options{Provider: tt.provider, Repo: tt.repo, Token: "secret", URL: server.URL})
+			if err != nil {
+				t.Fatal(err)
+			}
+			status := CommitStatus{State: StatusFailure, Context: "go-autobump", Description: "2 critical unfixed", TargetURL: "https://ci/1"}
+			if err := p.SetCommitStatus(context.Background(), "abc123", status); err != nil {
+				t.Fatalf("SetCommitStatus() error = %v", err)
+			}
+			if gotPath != tt.wantPath {
+				t.Errorf("path = %s, want %s", gotPath, tt.wantPath)
+			}
+			if gotBody != tt.wantBody {
+				t.Errorf("body = %s, want %s", gotBody, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestCICommit(t *testing.T) {
+	for _, name := range []string{"GITHUB_EVENT_PATH", "GITHUB_SHA", "CI_MERGE_REQUEST_SOURCE_BRANCH_SHA", "CI_COMMIT_SHA"} {
+		t.Setenv(name, "")
+	}
+	if got := CICommit(); got != "" {
+		t.Errorf("CICommit() outside of CI = %q, want empty", got)
+	}
+
+	t.Setenv("GITHUB_SHA", "merge")
+	if got := CICommit(); got != "merge" {
+		t.Errorf("CICommit() on a push = %q, want GITHUB_SHA", got)
+	}
+
+	event := filepath.Join(t.TempDir(), "event.json")
+	if err := os.WriteFile(event, []byte(`{"pull_request":{"head":{"sha":"head"}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GITHUB_EVENT_PATH", event)
+	if got := CICommit(); got != "head" {
+		t.Errorf("CICommit() on a pull request = %q, want its head", got)
+	}
+
+	t.Setenv("GITHUB_EVENT_PATH", "")
+	t.Setenv("GITHUB_SHA", "")
+	t.Setenv("CI_COMMIT_SHA", "merged-result")
+	t.Setenv("CI_MERGE_REQUEST_SOURCE_BRANCH_SHA", "source")
+	if got := CICommit(); got != "source" {
+		t.Errorf("CICommit() in a merged results pipeline = %q, want the source branch head", got)
+	}
+}
+
+func TestParseMarker(t *testing.T) {
+	tests := []struct {
+		body   string
+		want   string
+		wantOK bool
+	}{
+		{"text\n" + Marker("unfixed:go.mod:CVE-1:pkg") + "\n", "unfixed:go.mod:CVE-1:pkg", true},
+		{"no marker", "", false},
+		{"<!-- go-autobump:unterminated", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParseMarker(tt.body)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("ParseMarker(%q) = %q, %v, want %q, %v", tt.body, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+)
+
+// github implements Provider with the GitHub REST API
+type github struct {
+	*client
+	repo string
+	// app is the GitHub App authentication, or nil with a token
+	app *appAuth
+}
+
+// githubIssue is an issue as returned by the GitHub API
+type githubIssue struct {
+	Number      int       `json:"number"`
+	Title       string    `json:"title"`
+	Body        string    `json:"body"`
+	HTMLURL     string    `json:"html_url"`
+	State       string    `json:"state"`
+	PullRequest *struct{} `json:"pull_request"`
+}
+
+func (i githubIssue) issue() Issue {
+	return Issue{Number: i.Number, Title: i.Title, Body: i.Body, URL: i.HTMLURL, Open: i.State == "open"}
+}
+
+func (g *github) Issues(ctx context.Context, label string) ([]Issue, error) {
+	var issues []Issue
+	for page := 1; ; page++ {
+		query := url.Values{"state": {"open"}, "labels": {label}, "per_page": {"100"}, "page": {fmt.Sprint(page)}}
+		var batch []githubIssue
+		if err := g.do(ctx, "GET", g.path("/issues?"+query.Encode()), nil, &batch); err != nil {
+			return nil, err
+		}
+		for _, i := range batch {
+			// The issues API also lists pull requests
+			if i.PullRequest == nil {
+				issues = append(issues, i.issue())
+			}
+		}
+		if len(batch) < 100 {
+			return issues, nil
+		}
+	}
+}
+
+func (g *github) CreateIssue(ctx context.Context, title, body string, labels []string) (*Issue, error) {
+	in := map[string]any{"title": title, "body": body, "labels": labels}
+	var created githubIssue
+	if err := g.do(ctx, "POST", g.path("/issues"), in, &created); err != nil {
+		return nil, err
+	}
+	issue := created.issue()
+	return &issue, nil
+}
+
+func (g *github) CommentIssue(ctx context.Context, number int, body string) error {
+	return g.do(ctx, "POST", g.path(fmt.Sprintf("/issues/%d/comments", number)), map[string]string{"body": body}, nil)
+}
+
+func (g *github) CloseIssue(ctx context.Context, number int, comment string) error {
+	if comment != "" {
+		if err := g.CommentIssue(ctx, number, comment); err != nil {
+			return err
+		}
+	}
+	in := map[string]string{"state": "closed", "state_reason": "completed"}
+	return g.do(ctx, "PATCH", g.path(fmt.Sprintf("/issues/%d", number)), in, nil)
+}
+
+// CommentPullRequest comments through the issues API, which covers pull requests
+func (g *github) CommentPullRequest(ctx context.Context, number int, body string) error {
+	return g.CommentIssue(ctx, number, body)
+}
+
+func (g *github) PullRequestComments(ctx context.Context, number int) ([]Comment, error) {
+	var comments []Comment
+	for page := 1; ; page++ {
+		query := url.Values{"per_page": {"100"}, "page": {fmt.Sprint(page)}}
+		var batch []struct {
+			ID   int64  `json:"id"`
+			Body string `json:"body"`
+			User struct {
+				Login string `json:"login"`
+			} `json:"user"`
+		}
+		if err := g.do(ctx, "GET", g.path(fmt.Sprintf("/issues/%d/comments?%s", number, query.Encode())), nil, &batch); err != nil {
+			return nil, err
+		}
+		for _, c := range batch {
+			comments = append(comments, Comment{ID: c.ID, Body: c.Body, Author: c.User.Login})
+		}
+		if len(batch) < 100 {
+			return comments, nil
+		}
+	}
+}
+
+// EditPullRequestComment edits through the issues API, whose comment IDs are
+// unique in the repository
+func (g *github) EditPullRequestComment(ctx context.Context, number int, id int64, body string) error {
+	return g.do(ctx, "PATCH", g.path(fmt.Sprintf("/issues/comments/%d", id)), map[string]string{"body": body}, nil)
+}
+
+func (g *github) SetCommitStatus(ctx context.Context, sha string, status CommitStatus) error {
+	in := map[string]string{
+		"state":       status.State,
+		"context":     status.Context,
+		"description": truncate(status.Description, 140),
+	}
+	if status.TargetURL != "" {
+		in["target_url"] = status.TargetURL
+	}
+	return g.do(ctx, "POST", g.path("/statuses/"+sha), in, nil)
+}
+
+// User returns the bot user of the GitHub App, or the user of the token.
+// The Actions GITHUB_TOKEN cannot look itself up, so inside GitHub Actions a
+// failed lookup means its github-actions[bot].
+func (g *github) User(ctx context.Context) (string, error) {
+	if g.app != nil {
+		var app struct {
+			Slug string `json:"slug"`
+		}
+		if err := g.app.appRequest(ctx, "GET", "/app", &app); err != nil {
+			return "", err
+		}
+		return app.Slug + "[bot]", nil
+	}
+
+	var user struct {
+		Login string `json:"login"`
+	}
+	if err := g.do(ctx, "GET", "/user", nil, &user); err != nil {
+		if os.Getenv("GITHUB_ACTIONS") == "true" {
+			return "github-actions[bot]", nil
+		}
+		return "", err
+	}
+	return user.Login, nil
+}
+
+func (g *github) CheckAccess(ctx context.Context) error {
+	if g.repo == "" {
+		return fmt.Errorf("no repository configured (set forge.repo or GITHUB_REPOSITORY)")
+	}
+	return g.do(ctx, "GET", g.path(""), nil, nil)
+}
+
+// path returns the API path of a repository resource
+func (g *github) path(resource string) string {
+	return "/repos/" + g.repo + resource
+}
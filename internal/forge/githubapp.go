@@ -0,0 +1,214 @@
+package forge
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// appTokenMargin is how long before its expiry an installation token is
+	// replaced, so that no request is sent with a token about to expire
+	appTokenMargin = 5 * time.Minute
+	// appJWTLifetime is the lifetime of app JWTs; GitHub accepts at most 10m
+	appJWTLifetime = 9 * time.Minute
+)
+
+// appAuth authenticates requests as an installation of a GitHub App. It
+// exchanges a JWT signed with the app's private key for an installation
+// token, and refreshes the token shortly before it expires.
+type appAuth struct {
+	baseURL        string
+	appID          int64
+	key            *rsa.PrivateKey
+	installationID int64
+	// installationPath is the API path looking up the installation
+	installationPath string
+	httpClient       *http.Client
+	now              func() time.Time
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// newAppAuth returns the authentication of app appID with the PEM-encoded
+// privateKey. A zero installationID is looked up at installationPath, e.g.
+// "/repos/{owner}/{repo}/installation".
+func newAppAuth(c *client, appID int64, privateKey string, installationID int64, installationPath string) (*appAuth, error) {
+	key, err := parsePrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return &appAuth{
+		baseURL:          c.baseURL,
+		appID:            appID,
+		key:              key,
+		installationID:   installationID,
+		installationPath: installationPath,
+		httpClient:       c.httpClient,
+		now:              time.Now,
+	}, nil
+}
+
+// parsePrivateKey parses a PKCS#1 or PKCS#8 RSA private key in PEM form, as
+// GitHub generates for apps
+func parsePrivateKey(data string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, fmt.Errorf("no PEM private key found in the GitHub App private key")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the GitHub App private key: %w", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("the GitHub App private key is not an RSA key")
+	}
+	return rsaKey, nil
+}
+
+// authenticate sets the installation token on req, exchanging a new one if
+// there is none yet or it is about to expire
+func (a *appAuth) authenticate(req *http.Request) error {
+	token, err := a.installationToken(req.Context())
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// installationToken returns a valid installation token. A failed refresh
+// keeps using the current token while it has not expired, so that a
+// rate-limited exchange does not fail requests.
+func (a *appAuth) installationToken(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.now()
+	if a.token != "" && now.Add(appTokenMargin).Before(a.expires) {
+		return a.token, nil
+	}
+
+	token, expires, err := a.exchange(ctx)
+	if err != nil {
+		if a.token != "" && now.Before(a.expires) {
+			return a.token, nil
+		}
+		return "", fmt.Errorf("failed to get a GitHub App installation token: %w", err)
+	}
+	a.token, a.expires = token, expires
+	return token, nil
+}
+
+// exchange creates a new installation token, looking up the installation
+// first if needed
+func (a *appAuth) exchange(ctx context.Context) (string, time.Time, error) {
+	if a.installationID == 0 {
+		var installation struct {
+			ID int64 `json:"id"`
+		}
+		if err := a.appRequest(ctx, "GET", a.installationPath, &installation); err != nil {
+			return "", time.Time{}, fmt.Errorf("failed to find the installation of app %d: %w", a.appID, err)
+		}
+		a.installationID = installation.ID
+	}
+
+	var out struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	path := fmt.Sprintf("/app/installations/%d/access_tokens", a.installationID)
+	if err := a.appRequest(ctx, "POST", path, &out); err != nil {
+		return "", time.Time{}, err
+	}
+	if out.Token == "" {
+		return "", time.Time{}, fmt.Errorf("POST %s returned no token", path)
+	}
+	return out.Token, out.ExpiresAt, nil
+}
+
+// appRequest sends a request authenticated as the app itself and decodes the
+// JSON response into out. A rate-limited request is retried once after the
+// limit resets, if that is soon enough.
+func (a *appAuth) appRequest(ctx context.Context, method, path string, out any) error {
+	for attempt := 0; ; attempt++ {
+		jwt, err := a.jwt()
+		if err != nil {
+			return err
+		}
+		req, err := http.NewRequestWithContext(ctx, method, a.baseURL+path, nil)
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Accept", "application/vnd.github+json")
+		req.Header.Set("Authorization", "Bearer "+jwt)
+
+		resp, err := a.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("request failed: %w", err)
+		}
+		data, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+
+		if wait, limited := rateLimitWait(resp, a.now()); limited && attempt == 0 && wait <= maxRateLimitWait {
+			select {
+			case <-time.After(wait):
+				continue
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return fmt.Errorf("%s %s returned status %d: %s", method, path, resp.StatusCode, strings.TrimSpace(string(data)))
+		}
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+		return nil
+	}
+}
+
+// jwt returns a JSON Web Token identifying the app, signed with its key
+func (a *appAuth) jwt() (string, error) {
+	now := a.now()
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	claims, err := json.Marshal(map[string]any{
+		// Backdated against clock drift, as GitHub recommends
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(appJWTLifetime).Unix(),
+		"iss": strconv.FormatInt(a.appID, 10),
+	})
+	if err != nil {
+		return "", err
+	}
+	unsigned := header + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, a.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign the GitHub App JWT: %w", err)
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
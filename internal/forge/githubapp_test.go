@@ -0,0 +1,126 @@
+package forge
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGitHubAppAuth(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM := string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	var exchanges int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		switch {
+		case r.URL.Path == "/repos/org/repo/installation", r.URL.Path == "/app", strings.HasPrefix(r.URL.Path, "/app/"):
+			if err := verifyJWT(auth, &key.PublicKey, "7"); err != nil {
+				t.Errorf("%s %s: %v", r.Method, r.URL.Path, err)
+			}
+			if r.URL.Path == "/app" {
+				_, _ = w.Write([]byte(`{"slug": "autobump"}`))
+				return
+			}
+			if r.URL.Path == "/repos/org/repo/installation" {
+				_, _ = w.Write([]byte(`{"id": 42}`))
+				return
+			}
+			if r.Method != "POST" || r.URL.Path != "/app/installations/42/access_tokens" {
+				t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			}
+			exchanges++
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"token":      fmt.Sprintf("installation-%d", exchanges),
+				"expires_at": now.Add(time.Hour),
+			})
+		default:
+			if want := fmt.Sprintf("installation-%d", exchanges); auth != want {
+				t.Errorf("%s authenticated with %q, want %q", r.URL.Path, auth, want)
+			}
+			_, _ = w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	c := newClient(server.URL, nil)
+	app, err := newAppAuth(c, 7, keyPEM, 0, "/repos/org/repo/installation")
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.now = func() time.Time { return now }
+	c.auth = app.authenticate
+	g := &github{client: c, repo: "org/repo", app: app}
+	ctx := context.Background()
+
+	if user, err := g.User(ctx); err != nil || user != "autobump[bot]" {
+		t.Errorf("User() = %q, %v, want the app's bot user", user, err)
+	}
+
+	for range 2 {
+		if err := g.CheckAccess(ctx); err != nil {
+			t.Fatalf("CheckAccess() error = %v", err)
+		}
+	}
+	if exchanges != 1 {
+		t.Errorf("%d token exchanges, want the token reused", exchanges)
+	}
+
+	// Shortly before the token expires, it is replaced
+	now = now.Add(time.Hour - time.Minute)
+	if err := g.CheckAccess(ctx); err != nil {
+		t.Fatalf("CheckAccess() error = %v", err)
+	}
+	if exchanges != 2 {
+		t.Errorf("%d token exchanges, want the expiring token refreshed", exchanges)
+	}
+}
+
+// verifyJWT checks that token is an RS256 JWT signed by key and issued by iss
+func verifyJWT(token string, key *rsa.PublicKey, iss string) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("not a JWT: %q", token)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return err
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return err
+	}
+	var claims struct {
+		Iss string `json:"iss"`
+		Iat int64  `json:"iat"`
+		Exp int64  `json:"exp"`
+	}
+	if err := json.Unmarshal(data, &claims); err != nil {
+		return err
+	}
+	if claims.Iss != iss || claims.Exp-claims.Iat > 600 {
+		return fmt.Errorf("claims = %+v", claims)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// Repository is a repository of a GitHub organization
+type Repository struct {
+	FullName      string   `json:"full_name"`
+	CloneURL      string   `json:"clone_url"`
+	DefaultBranch string   `json:"default_branch"`
+	Language      string   `json:"language"`
+	Topics        []string `json:"topics"`
+	Archived      bool     `json:"archived"`
+	Fork          bool     `json:"fork"`
+}
+
+// GitHubOrg lists the repositories of a GitHub organization
+type GitHubOrg struct {
+	*client
+	org string
+}
+
+// NewGitHubOrg returns a client of the GitHub organization org,
+// authenticated like New. A GitHub App uses its installation on the
+// organization.
+func NewGitHubOrg(opts Options, org string) (*GitHubOrg, error) {
+	if org == "" {
+		return nil, fmt.Errorf("no GitHub organization given")
+	}
+	c, _, err := newGitHubClient(opts, "/orgs/"+url.PathEscape(org)+"/installation")
+	if err != nil {
+		return nil, err
+	}
+	return &GitHubOrg{client: c, org: org}, nil
+}
+
+// Repositories lists all repositories of the organization visible to the
+// credentials
+func (o *GitHubOrg) Repositories(ctx context.Context) ([]Repository, error) {
+	var repos []Repository
+	for page := 1; ; page++ {
+		query := url.Values{"type": {"all"}, "per_page": {"100"}, "page": {fmt.Sprint(page)}}
+		var batch []Repository
+		if err := o.do(ctx, "GET", "/orgs/"+url.PathEscape(o.org)+"/repos?"+query.Encode(), nil, &batch); err != nil {
+			return nil, err
+		}
+		repos = append(repos, batch...)
+		if len(batch) < 100 {
+			return repos, nil
+		}
+	}
+}
+
+// Languages returns the bytes of code per language of the repository
+// fullName ("owner/name")
+func (o *GitHubOrg) Languages(ctx context.Context, fullName string) (map[string]int64, error) {
+	languages := make(map[string]int64)
+	if err := o.do(ctx, "GET", "/repos/"+fullName+"/languages", nil, &languages); err != nil {
+		return nil, err
+	}
+	return languages, nil
+}
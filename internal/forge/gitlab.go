@@ -0,0 +1,139 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// gitlab implements Provider with the GitLab REST API (v4)
+type gitlab struct {
+	*client
+	project string
+}
+
+// gitlabIssue is an issue as returned by the GitLab API
+type gitlabIssue struct {
+	IID         int    `json:"iid"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	WebURL      string `json:"web_url"`
+	State       string `json:"state"`
+}
+
+func (i gitlabIssue) issue() Issue {
+	return Issue{Number: i.IID, Title: i.Title, Body: i.Description, URL: i.WebURL, Open: i.State == "opened"}
+}
+
+func (g *gitlab) Issues(ctx context.Context, label string) ([]Issue, error) {
+	var issues []Issue
+	for page := 1; ; page++ {
+		query := url.Values{"state": {"opened"}, "labels": {label}, "per_page": {"100"}, "page": {fmt.Sprint(page)}}
+		var batch []gitlabIssue
+		if err := g.do(ctx, "GET", g.path("/issues?"+query.Encode()), nil, &batch); err != nil {
+			return nil, err
+		}
+		for _, i := range batch {
+			issues = append(issues, i.issue())
+		}
+		if len(batch) < 100 {
+			return issues, nil
+		}
+	}
+}
+
+func (g *gitlab) CreateIssue(ctx context.Context, title, body string, labels []string) (*Issue, error) {
+	in := map[string]string{"title": title, "description": body, "labels": strings.Join(labels, ",")}
+	var created gitlabIssue
+	if err := g.do(ctx, "POST", g.path("/issues"), in, &created); err != nil {
+		return nil, err
+	}
+	issue := created.issue()
+	return &issue, nil
+}
+
+func (g *gitlab) CommentIssue(ctx context.Context, number int, body string) error {
+	return g.do(ctx, "POST", g.path(fmt.Sprintf("/issues/%d/notes", number)), map[string]string{"body": body}, nil)
+}
+
+func (g *gitlab) CloseIssue(ctx context.Context, number int, comment string) error {
+	if comment != "" {
+		if err := g.CommentIssue(ctx, number, comment); err != nil {
+			return err
+		}
+	}
+	return g.do(ctx, "PUT", g.path(fmt.Sprintf("/issues/%d", number)), map[string]string{"state_event": "close"}, nil)
+}
+
+func (g *gitlab) CommentPullRequest(ctx context.Context, number int, body string) error {
+	return g.do(ctx, "POST", g.path(fmt.Sprintf("/merge_requests/%d/notes", number)), map[string]string{"body": body}, nil)
+}
+
+func (g *gitlab) PullRequestComments(ctx context.Context, number int) ([]Comment, error) {
+	var comments []Comment
+	for page := 1; ; page++ {
+		query := url.Values{"per_page": {"100"}, "page": {fmt.Sprint(page)}}
+		var batch []struct {
+			ID     int64  `json:"id"`
+			Body   string `json:"body"`
+			Author struct {
+				Username string `json:"username"`
+			} `json:"author"`
+		}
+		if err := g.do(ctx, "GET", g.path(fmt.Sprintf("/merge_requests/%d/notes?%s", number, query.Encode())), nil, &batch); err != nil {
+			return nil, err
+		}
+		for _, c := range batch {
+			comments = append(comments, Comment{ID: c.ID, Body: c.Body, Author: c.Author.Username})
+		}
+		if len(batch) < 100 {
+			return comments, nil
+		}
+	}
+}
+
+func (g *gitlab) EditPullRequestComment(ctx context.Context, number int, id int64, body string) error {
+	return g.do(ctx, "PUT", g.path(fmt.Sprintf("/merge_requests/%d/notes/%d", number, id)), map[string]string{"body": body}, nil)
+}
+
+// SetCommitStatus sets an external commit status, whose failed state GitLab
+// calls "failed"
+func (g *gitlab) SetCommitStatus(ctx context.Context, sha string, status CommitStatus) error {
+	state := status.State
+	if state == StatusFailure {
+		state = "failed"
+	}
+	in := map[string]string{
+		"state":       state,
+		"name":        status.Context,
+		"description": truncate(status.Description, 255),
+	}
+	if status.TargetURL != "" {
+		in["target_url"] = status.TargetURL
+	}
+	return g.do(ctx, "POST", g.path("/statuses/"+sha), in, nil)
+}
+
+func (g *gitlab) User(ctx context.Context) (string, error) {
+	var user struct {
+		Username string `json:"username"`
+	}
+	if err := g.do(ctx, "GET", "/user", nil, &user); err != nil {
+		return "", err
+	}
+	return user.Username, nil
+}
+
+func (g *gitlab) CheckAccess(ctx context.Context) error {
+	if g.project == "" {
+		return fmt.Errorf("no project configured (set forge.repo or CI_PROJECT_PATH)")
+	}
+	return g.do(ctx, "GET", g.path(""), nil, nil)
+}
+
+// path returns the API path of a project resource; the project path is
+// URL-encoded as GitLab requires
+func (g *gitlab) path(resource string) string {
+	return "/projects/" + url.PathEscape(g.project) + resource
+}
@@ -0,0 +1,92 @@
+package forge
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// maxRateLimitWait bounds the wait for a rate-limited request to be retried
+const maxRateLimitWait = time.Minute
+
+// throttles paces the requests to each API base URL, shared by all clients
+// of the process
+var (
+	throttlesMu sync.Mutex
+	throttles   = map[string]*throttle{}
+)
+
+// throttle spaces requests at least interval apart
+type throttle struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// throttleFor returns the throttle of baseURL allowing perSecond requests
+// per second, or nil if perSecond is not positive
+func throttleFor(baseURL string, perSecond float64) *throttle {
+	if perSecond <= 0 {
+		return nil
+	}
+	throttlesMu.Lock()
+	defer throttlesMu.Unlock()
+	t, ok := throttles[baseURL]
+	if !ok {
+		t = &throttle{}
+		throttles[baseURL] = t
+	}
+	t.mu.Lock()
+	t.interval = time.Duration(float64(time.Second) / perSecond)
+	t.mu.Unlock()
+	return t
+}
+
+// wait blocks until the next request may be sent or ctx ends
+func (t *throttle) wait(ctx context.Context) error {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	at := time.Now()
+	if t.next.After(at) {
+		at = t.next
+	}
+	t.next = at.Add(t.interval)
+	t.mu.Unlock()
+
+	wait := time.Until(at)
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// rateLimitWait reports whether resp was rejected by a rate limit, and how
+// long until it resets
+func rateLimitWait(resp *http.Response, now time.Time) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") != "0" {
+		return 0, false
+	}
+	reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return max(time.Unix(reset, 0).Sub(now), 0), true
+}
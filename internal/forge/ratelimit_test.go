@@ -0,0 +1,66 @@
+package forge
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClientRateLimit(t *testing.T) {
+	var requests []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, time.Now())
+		if len(requests) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	c := newClient(server.URL, headerAuth("Authorization", "Bearer ", "token"))
+	c.throttle = throttleFor(server.URL, 20)
+	for range 3 {
+		if err := c.do(context.Background(), "GET", "/", nil, nil); err != nil {
+			t.Fatalf("do() error = %v", err)
+		}
+	}
+
+	// The rate-limited first request is retried, and all are 50ms apart
+	if len(requests) != 4 {
+		t.Fatalf("%d requests, want 4", len(requests))
+	}
+	if elapsed := requests[3].Sub(requests[0]); elapsed < 140*time.Millisecond {
+		t.Errorf("4 requests at 20/s took %s", elapsed)
+	}
+}
+
+func TestRateLimitWait(t *testing.T) {
+	now := time.Unix(1000, 0)
+	tests := []struct {
+		status      int
+		header      map[string]string
+		wantWait    time.Duration
+		wantLimited bool
+	}{
+		{http.StatusForbidden, map[string]string{"Retry-After": "30"}, 30 * time.Second, true},
+		{http.StatusForbidden, map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "1020"}, 20 * time.Second, true},
+		{http.StatusTooManyRequests, map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "900"}, 0, true},
+		{http.StatusForbidden, map[string]string{"X-RateLimit-Remaining": "12"}, 0, false},
+		{http.StatusOK, map[string]string{"Retry-After": "30"}, 0, false},
+	}
+
+	for _, tt := range tests {
+		resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+		for k, v := range tt.header {
+			resp.Header.Set(k, v)
+		}
+		wait, limited := rateLimitWait(resp, now)
+		if wait != tt.wantWait || limited != tt.wantLimited {
+			t.Errorf("rateLimitWait(%d, %v) = %s, %v, want %s, %v", tt.status, tt.header, wait, limited, tt.wantWait, tt.wantLimited)
+		}
+	}
+}
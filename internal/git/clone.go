@@ -0,0 +1,139 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// scpLikeURL matches remote URLs in scp syntax, e.g. git@github.com:org/repo.git
+var scpLikeURL = regexp.MustCompile(`^[\w.-]+@[\w.-]+:`)
+
+// IsRemoteURL reports whether repo refers to a remote repository rather than
+// a local path
+func IsRemoteURL(repo string) bool {
+	return strings.Contains(repo, "://") || scpLikeURL.MatchString(repo)
+}
+
+// RedactURL returns repo without the userinfo of a remote URL, which may
+// hold credentials such as a token, so that it can be printed
+func RedactURL(repo string) string {
+	if scpLikeURL.MatchString(repo) {
+		return repo[strings.Index(repo, "@")+1:]
+	}
+	u, err := url.Parse(repo)
+	if err != nil || u.User == nil {
+		return repo
+	}
+	u.User = nil
+	return u.String()
+}
+
+// SparsePatterns are the files a sparse clone checks out wherever they are:
+// those of Go modules and workspaces. The directories of Go packages are
+// checked out in full besides.
+var SparsePatterns = []string{"go.mod", "go.sum", "go.work", "go.work.sum", "modules.txt"}
+
+// CloneOptions configures Clone
+type CloneOptions struct {
+	// Ref is the branch or tag to check out (default: the default branch)
+	Ref string
+	// Sparse checks out only the files matching SparsePatterns and
+	// SparsePaths and the directories of Go packages, fetching no other
+	// file contents
+	Sparse bool
+	// SparsePaths are further files or directories a sparse clone checks
+	// out, as .gitignore patterns
+	SparsePaths []string
+}
+
+// Clone makes a shallow clone of repo into a new temporary directory and returns
+// its path. The caller is responsible for removing the directory.
+func Clone(repo string, opts CloneOptions) (string, error) {
+	dir, err := os.MkdirTemp("", "go-autobump-clone-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create clone directory: %w", err)
+	}
+
+	args := []string{"clone", "--depth", "1"}
+	if opts.Sparse {
+		// A partial clone fetches only the contents of checked out files;
+		// servers without support send everything instead
+		args = append(args, "--filter=blob:none", "--no-checkout")
+	}
+	if opts.Ref != "" {
+		args = append(args, "--branch", opts.Ref)
+	}
+	args = append(args, repo, dir)
+
+	if _, err := run("", args...); err != nil {
+		_ = os.RemoveAll(dir)
+		// git may repeat the URL, credentials included, in its errors
+		return "", errors.New(strings.ReplaceAll(err.Error(), repo, RedactURL(repo)))
+	}
+
+	if opts.Sparse {
+		// Listing the tree needs no file contents
+		files, err := run(dir, "ls-tree", "-r", "-z", "--name-only", "HEAD")
+		if err != nil {
+			_ = os.RemoveAll(dir)
+			return "", err
+		}
+		patterns := append(slices.Clone(SparsePatterns), packageDirPatterns(strings.Split(files, "\x00"))...)
+		patterns = append(patterns, opts.SparsePaths...)
+		if _, err := run(dir, append([]string{"sparse-checkout", "set", "--no-cone"}, patterns...)...); err != nil {
+			_ = os.RemoveAll(dir)
+			return "", err
+		}
+		if _, err := run(dir, "checkout"); err != nil {
+			_ = os.RemoveAll(dir)
+			return "", err
+		}
+	}
+	return dir, nil
+}
+
+// packageDirPatterns returns .gitignore patterns checking out the directories
+// of the Go files among files with everything below them, so that a build
+// finds the assembly, cgo and syso files, go:embed targets and test data of
+// its packages. Directories below another one are covered by its pattern.
+func packageDirPatterns(files []string) []string {
+	var dirs []string
+	for _, file := range files {
+		if strings.HasSuffix(file, ".go") {
+			dirs = append(dirs, path.Dir(file))
+		}
+	}
+	slices.Sort(dirs)
+	dirs = slices.Compact(dirs)
+
+	var patterns []string
+	covered := map[string]bool{}
+	for _, dir := range dirs {
+		if dir == "." {
+			return []string{"/*"}
+		}
+		// Parents sort before their subdirectories
+		nested := false
+		for parent := path.Dir(dir); parent != "."; parent = path.Dir(parent) {
+			if covered[parent] {
+				nested = true
+				break
+			}
+		}
+		if nested {
+			continue
+		}
+		covered[dir] = true
+		patterns = append(patterns, "/"+escapePattern.Replace(dir)+"/")
+	}
+	return patterns
+}
+
+// escapePattern escapes the characters .gitignore patterns treat specially
+var escapePattern = strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`)
@@ -0,0 +1,116 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Identity is the author or committer of a commit
+type Identity struct {
+	Name  string
+	Email string
+}
+
+// ParseIdentity parses an identity written as "Name <email>"
+func ParseIdentity(s string) (Identity, error) {
+	name, rest, ok := strings.Cut(s, "<")
+	email, tail, closed := strings.Cut(rest, ">")
+	name, email = strings.TrimSpace(name), strings.TrimSpace(email)
+	if !ok || !closed || strings.TrimSpace(tail) != "" || name == "" || email == "" {
+		return Identity{}, fmt.Errorf("invalid identity %q, want \"Name <email>\"", s)
+	}
+	return Identity{Name: name, Email: email}, nil
+}
+
+// String returns the identity as "Name <email>"
+func (i Identity) String() string {
+	return i.Name + " <" + i.Email + ">"
+}
+
+// CommitOptions controls how Commit records a commit
+type CommitOptions struct {
+	Message string
+
+	// Author and Committer default to git's user.name and user.email; a
+	// Committer left empty defaults to the Author
+	Author    Identity
+	Committer Identity
+
+	// SigningKey, if set, signs the commit: a GPG key ID, or an SSH key
+	// file or "key::" literal with SigningFormat ssh
+	SigningKey string
+	// SigningFormat is git's gpg.format: openpgp, ssh or x509
+	// (default: openpgp)
+	SigningFormat string
+}
+
+// Commit commits the changes to the files matching pathspecs, relative to
+// dir, leaving other staged changes alone. It returns the hash of the
+// commit, or empty if none of the files changed.
+func Commit(dir string, pathspecs []string, opts CommitOptions) (string, error) {
+	root, err := RepoRoot(dir)
+	if err != nil {
+		return "", err
+	}
+
+	// Porcelain paths are relative to the repository root
+	args := append([]string{"status", "--porcelain", "-z", "--untracked-files=all", "--"}, pathspecs...)
+	out, err := run(dir, args...)
+	if err != nil {
+		return "", err
+	}
+	files := changedFiles(out)
+	if len(files) == 0 {
+		return "", nil
+	}
+
+	if _, err := run(root, append([]string{"add", "--all", "--"}, files...)...); err != nil {
+		return "", err
+	}
+
+	committer := opts.Committer
+	if committer == (Identity{}) {
+		committer = opts.Author
+	}
+	var config []string
+	if committer != (Identity{}) {
+		config = append(config, "-c", "user.name="+committer.Name, "-c", "user.email="+committer.Email)
+	}
+	if opts.SigningKey != "" {
+		config = append(config, "-c", "user.signingkey="+opts.SigningKey)
+		if opts.SigningFormat != "" {
+			config = append(config, "-c", "gpg.format="+opts.SigningFormat)
+		}
+	}
+
+	args = append(config, "commit", "--no-verify", "-m", opts.Message)
+	if opts.Author != (Identity{}) {
+		args = append(args, "--author", opts.Author.String())
+	}
+	if opts.SigningKey != "" {
+		args = append(args, "--gpg-sign")
+	}
+	args = append(args, "--")
+	if _, err := run(root, append(args, files...)...); err != nil {
+		return "", err
+	}
+	return HeadCommit(root)
+}
+
+// changedFiles returns the paths of "git status --porcelain -z" output
+func changedFiles(out string) []string {
+	var files []string
+	entries := strings.Split(out, "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		files = append(files, entry[3:])
+		// Renames and copies are followed by their source path
+		if entry[0] == 'R' || entry[0] == 'C' {
+			i++
+		}
+	}
+	return files
+}
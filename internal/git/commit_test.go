@@ -0,0 +1,95 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseIdentity(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Identity
+		wantErr bool
+	}{
+		{"go-autobump[bot] <bot@example.com>", Identity{"go-autobump[bot]", "bot@example.com"}, false},
+		{"  Jane Doe<jane@example.com> ", Identity{"Jane Doe", "jane@example.com"}, false},
+		{"bot@example.com", Identity{}, true},
+		{"<bot@example.com>", Identity{}, true},
+		{"Bot <bot@example.com", Identity{}, true},
+		{"Bot <bot@example.com> extra", Identity{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseIdentity(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseIdentity(%q) = %+v, %v, want %+v (error: %v)", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestCommit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	gitCmd := func(args ...string) string {
+		t.Helper()
+		out, err := run(dir, args...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.TrimSpace(out)
+	}
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	gitCmd("init", "--quiet")
+	gitCmd("config", "user.name", "Jane Doe")
+	gitCmd("config", "user.email", "jane@example.com")
+	gitCmd("config", "commit.gpgsign", "false")
+	write("go.mod", "module example.com/app\n")
+	write("main.go", "package main\n")
+	gitCmd("add", "--all")
+	gitCmd("commit", "--quiet", "-m", "initial")
+
+	pathspecs := []string{":(glob)**/go.mod", ":(glob)**/go.sum"}
+	if hash, err := Commit(dir, pathspecs, CommitOptions{Message: "nothing"}); err != nil || hash != "" {
+		t.Fatalf("Commit() without changes = %q, %v, want no commit", hash, err)
+	}
+
+	write("go.mod", "module example.com/app\n\nrequire example.com/vuln v1.0.1\n")
+	write("go.sum", "example.com/vuln v1.0.1 h1:x\n")
+	write("tools/go.mod", "module example.com/tools\n")
+	write("main.go", "package main\n\nfunc main() {}\n")
+
+	bot := Identity{Name: "go-autobump[bot]", Email: "bot@example.com"}
+	hash, err := Commit(dir, pathspecs, CommitOptions{Message: "fix(deps): update", Author: bot})
+	if err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if hash == "" || hash != gitCmd("rev-parse", "HEAD") {
+		t.Errorf("Commit() = %q, want the hash of HEAD", hash)
+	}
+
+	if got := gitCmd("log", "-1", "--format=%an <%ae>|%cn <%ce>|%s"); got != "go-autobump[bot] <bot@example.com>|go-autobump[bot] <bot@example.com>|fix(deps): update" {
+		t.Errorf("commit = %q", got)
+	}
+	if got := gitCmd("show", "--name-only", "--format=", "HEAD"); got != "go.mod\ngo.sum\ntools/go.mod" {
+		t.Errorf("committed files = %q", got)
+	}
+	if got := gitCmd("status", "--porcelain"); got != "M main.go" {
+		t.Errorf("status after commit = %q, want main.go left alone", got)
+	}
+}
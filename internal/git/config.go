@@ -0,0 +1,13 @@
+package git
+
+import "strings"
+
+// UserName returns the configured git user.name for the repository in dir,
+// or "" if none is set
+func UserName(dir string) string {
+	out, err := run(dir, "config", "user.name")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(out)
+}
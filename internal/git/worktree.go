@@ -0,0 +1,85 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/tamcore/go-autobump/internal/runner"
+)
+
+// Worktree is a temporary, detached git worktree
+type Worktree struct {
+	RepoRoot string
+	Path     string
+}
+
+// RepoRoot returns the top-level directory of the git repository containing dir
+func RepoRoot(dir string) (string, error) {
+	out, err := run(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// HeadCommit returns the commit hash of HEAD in the repository containing dir
+func HeadCommit(dir string) (string, error) {
+	out, err := run(dir, "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// AddWorktree creates a detached worktree of HEAD in a new temporary directory
+func AddWorktree(repoRoot string) (*Worktree, error) {
+	dir, err := os.MkdirTemp("", "go-autobump-worktree-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create worktree directory: %w", err)
+	}
+
+	// git refuses to add a worktree into an existing directory that is not empty,
+	// but an empty one is fine
+	if _, err := run(repoRoot, "worktree", "add", "--detach", dir, "HEAD"); err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, err
+	}
+
+	return &Worktree{RepoRoot: repoRoot, Path: dir}, nil
+}
+
+// Remove deletes the worktree and its directory
+func (w *Worktree) Remove() error {
+	_, err := run(w.RepoRoot, "worktree", "remove", "--force", w.Path)
+	if rmErr := os.RemoveAll(w.Path); err == nil && rmErr != nil {
+		err = rmErr
+	}
+	return err
+}
+
+// run executes a git command in dir and returns its stdout
+func run(dir string, args ...string) (string, error) {
+	gitArgs := args
+	if runtime.GOOS == "windows" {
+		// Worktrees and clones of deep module trees exceed MAX_PATH without it
+		gitArgs = append([]string{"-c", "core.longpaths=true"}, args...)
+	}
+
+	stdout, stderr, err := runner.Run(dir, runner.Git, gitArgs...)
+	if err != nil {
+		return "", fmt.Errorf("git %s failed: %v\nstderr: %s", subcommand(args), err, stderr)
+	}
+
+	return string(stdout), nil
+}
+
+// subcommand returns the git subcommand of args, skipping "-c name=value"
+// options before it
+func subcommand(args []string) string {
+	for len(args) > 2 && args[0] == "-c" {
+		args = args[2:]
+	}
+	return args[0]
+}
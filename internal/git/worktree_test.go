@@ -0,0 +1,60 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWorktree(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	gitCmd := func(args ...string) string {
+		t.Helper()
+		out, err := run(dir, args...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "sub", "go.mod"), []byte("module example.com/sub\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitCmd("init", "--quiet")
+	gitCmd("add", "-A")
+	gitCmd("-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "initial")
+
+	root, err := RepoRoot(filepath.Join(dir, "sub"))
+	if err != nil || root != dir {
+		t.Fatalf("RepoRoot() = %q, %v; want %q", root, err, dir)
+	}
+
+	wt, err := AddWorktree(root)
+	if err != nil {
+		t.Fatalf("AddWorktree() error: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(wt.Path, "sub", "go.mod")); err != nil || string(data) != "module example.com/sub\n" {
+		t.Errorf("worktree go.mod = %q, %v; want the committed one", data, err)
+	}
+
+	if err := wt.Remove(); err != nil {
+		t.Fatalf("Remove() error: %v", err)
+	}
+	if _, err := os.Stat(wt.Path); !os.IsNotExist(err) {
+		t.Errorf("worktree directory still exists after Remove(): %v", err)
+	}
+	if worktrees := strings.Count(gitCmd("worktree", "list", "--porcelain"), "worktree "); worktrees != 1 {
+		t.Errorf("%d worktrees after Remove(), want only the main checkout", worktrees)
+	}
+}
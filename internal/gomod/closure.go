@@ -0,0 +1,133 @@
+package gomod
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"golang.org/x/mod/modfile"
+)
+
+// VersionChange is a change of the version minimal version selection picks
+// for a module. From is empty for modules added to the build list, To for
+// modules dropped from it.
+type VersionChange struct {
+	Path string `json:"module"`
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+}
+
+// String describes the change, e.g. "example.com/a v1.0.0 -> v1.1.0"
+func (c VersionChange) String() string {
+	switch {
+	case c.From == "":
+		return c.Path + " " + c.To + " (added)"
+	case c.To == "":
+		return c.Path + " " + c.From + " (removed)"
+	}
+	return c.Path + " " + c.From + " -> " + c.To
+}
+
+// Selected returns the version selected for each module of the graph, the
+// highest version it is required at, leaving out the main module
+func (g *Graph) Selected() map[string]string {
+	selected := make(map[string]string, len(g.versions))
+	for path, versions := range g.versions {
+		if path != g.main && len(versions) > 0 {
+			selected[path] = versions[len(versions)-1]
+		}
+	}
+	return selected
+}
+
+// DiffSelected returns the modules whose selected version differs between
+// two graphs of the same main module, sorted by path
+func DiffSelected(before, after *Graph) []VersionChange {
+	from, to := before.Selected(), after.Selected()
+
+	var changes []VersionChange
+	for path, version := range from {
+		if to[path] != version {
+			changes = append(changes, VersionChange{Path: path, From: version, To: to[path]})
+		}
+	}
+	for path, version := range to {
+		if _, ok := from[path]; !ok {
+			changes = append(changes, VersionChange{Path: path, To: version})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// Preview returns the version changes GoGet of pkgPath at version would
+// cause, without touching the module: the update is made in a temporary
+// copy of go.mod and go.sum, and the module graphs before and after are
+// compared. Like GoGet, it includes the members of lockstep groups.
+func (s *Session) Preview(pkgPath, version string) ([]VersionChange, error) {
+	before, err := s.Graph()
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "autobump-preview-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create preview directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	if err := s.copyModule(dir); err != nil {
+		return nil, err
+	}
+
+	members := map[string]string{pkgPath: version}
+	if len(s.Lockstep) > 0 {
+		if parser, err := s.Parser(); err == nil {
+			for member, memberVersion := range lockstepTargets(s.Lockstep, parser, pkgPath, version) {
+				members[member] = memberVersion
+			}
+		}
+	}
+	if err := GoGetAll(dir, members); err != nil {
+		return nil, err
+	}
+
+	edges, err := ModGraph(dir)
+	if err != nil {
+		return nil, err
+	}
+	return DiffSelected(before, NewGraph(edges)), nil
+}
+
+// copyModule writes the module's go.mod and go.sum to dir, with local
+// replacements pointing at their absolute path so they still resolve
+func (s *Session) copyModule(dir string) error {
+	snap, err := s.Snapshot()
+	if err != nil {
+		return err
+	}
+
+	f, err := modfile.Parse(s.GoModPath, snap.GoMod, nil)
+	if err != nil {
+		return fmt.Errorf("failed to parse go.mod: %w", err)
+	}
+	for _, rep := range f.Replace {
+		if !modfile.IsDirectoryPath(rep.New.Path) || filepath.IsAbs(rep.New.Path) {
+			continue
+		}
+		abs, err := filepath.Abs(filepath.Join(s.Dir, rep.New.Path))
+		if err != nil {
+			return fmt.Errorf("failed to resolve replacement %s: %w", rep.New.Path, err)
+		}
+		if err := f.AddReplace(rep.Old.Path, rep.Old.Version, abs, ""); err != nil {
+			return fmt.Errorf("failed to rewrite replacement %s: %w", rep.New.Path, err)
+		}
+	}
+	if snap.GoMod, err = f.Format(); err != nil {
+		return fmt.Errorf("failed to format go.mod: %w", err)
+	}
+
+	return snap.WriteTo(filepath.Join(dir, "go.mod"))
+}
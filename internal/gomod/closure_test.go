@@ -0,0 +1,86 @@
+package gomod
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDiffSelected(t *testing.T) {
+	edge := func(from, to string) GraphEdge {
+		return GraphEdge{From: parseModuleVersion(from), To: parseModuleVersion(to)}
+	}
+	before := NewGraph([]GraphEdge{
+		edge("example.com/app", "example.com/a@v1.0.0"),
+		edge("example.com/app", "example.com/old@v1.0.0"),
+		edge("example.com/a@v1.0.0", "example.com/b@v1.0.0"),
+		edge("example.com/a@v1.0.0", "example.com/c@v1.2.0"),
+	})
+	after := NewGraph([]GraphEdge{
+		edge("example.com/app", "example.com/a@v1.1.0"),
+		edge("example.com/a@v1.1.0", "example.com/b@v1.3.0"),
+		edge("example.com/a@v1.1.0", "example.com/c@v1.2.0"),
+		edge("example.com/a@v1.1.0", "example.com/new@v0.1.0"),
+	})
+
+	want := []VersionChange{
+		{Path: "example.com/a", From: "v1.0.0", To: "v1.1.0"},
+		{Path: "example.com/b", From: "v1.0.0", To: "v1.3.0"},
+		{Path: "example.com/new", To: "v0.1.0"},
+		{Path: "example.com/old", From: "v1.0.0"},
+	}
+	if got := DiffSelected(before, after); !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffSelected() = %+v, want %+v", got, want)
+	}
+}
+
+func TestPreview(t *testing.T) {
+	t.Setenv("GOPROXY", "off")
+	t.Setenv("GOFLAGS", "-mod=mod")
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod": `module example.com/app
+
+go 1.21
+
+require (
+	example.com/a v1.0.0
+	example.com/b v1.0.0
+)
+
+replace (
+	example.com/a v1.0.0 => ./a10
+	example.com/a v1.1.0 => ./a11
+	example.com/b v1.0.0 => ./b10
+	example.com/b v1.1.0 => ./b11
+)
+`,
+		"a10/go.mod": "module example.com/a\n\ngo 1.21\n",
+		"a11/go.mod": "module example.com/a\n\ngo 1.21\n\nrequire example.com/b v1.1.0\n",
+		"b10/go.mod": "module example.com/b\n\ngo 1.21\n",
+		"b11/go.mod": "module example.com/b\n\ngo 1.21\n",
+	})
+	goMod := filepath.Join(dir, "go.mod")
+	original, err := os.ReadFile(goMod)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := NewSession(goMod).Preview("example.com/a", "v1.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []VersionChange{
+		{Path: "example.com/a", From: "v1.0.0", To: "v1.1.0"},
+		{Path: "example.com/b", From: "v1.0.0", To: "v1.1.0"},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("Preview() = %+v, want %+v", changes, want)
+	}
+
+	if current, _ := os.ReadFile(goMod); string(current) != string(original) {
+		t.Errorf("Preview() changed go.mod:\n%s", current)
+	}
+}
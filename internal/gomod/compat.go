@@ -0,0 +1,166 @@
+package gomod
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// Compatibility rule matches
+const (
+	// MatchMinor requires the members to share major and minor version
+	MatchMinor = "minor"
+	// MatchExact requires the members to share the exact version
+	MatchExact = "exact"
+)
+
+// CompatibilityRule describes modules whose versions must stay coherent,
+// e.g. k8s.io/client-go and k8s.io/api of the same minor release. Only
+// members of the same major version are compared, so modules of a release
+// train that are still at v0 are not held to its v1 modules.
+type CompatibilityRule struct {
+	Name string
+	// Patterns match module paths like LockstepGroup patterns
+	Patterns []string
+	// Match is MatchMinor or MatchExact
+	Match string
+	// StableOnly leaves out the members still at v0, for release trains
+	// whose experimental modules are versioned on their own
+	StableOnly bool
+}
+
+// BuiltinCompatibilityRules are the rules of well-known ecosystems
+var BuiltinCompatibilityRules = []CompatibilityRule{
+	{
+		Name: "kubernetes",
+		Patterns: []string{
+			"k8s.io/api", "k8s.io/apimachinery", "k8s.io/client-go",
+			"k8s.io/apiserver", "k8s.io/apiextensions-apiserver",
+			"k8s.io/component-base", "k8s.io/component-helpers",
+			"k8s.io/cli-runtime", "k8s.io/kubectl", "k8s.io/kubelet",
+			"k8s.io/kube-aggregator", "k8s.io/code-generator", "k8s.io/metrics",
+			"k8s.io/cloud-provider", "k8s.io/controller-manager", "k8s.io/cri-api",
+		},
+		Match: MatchMinor,
+	},
+	{
+		Name:       "opentelemetry",
+		Patterns:   []string{"go.opentelemetry.io/otel", "go.opentelemetry.io/otel/*"},
+		Match:      MatchExact,
+		StableOnly: true,
+	},
+	{
+		Name:       "opentelemetry-contrib",
+		Patterns:   []string{"go.opentelemetry.io/contrib/*"},
+		Match:      MatchExact,
+		StableOnly: true,
+	},
+}
+
+// Matches reports whether modulePath is a member of the rule
+func (r CompatibilityRule) Matches(modulePath string) bool {
+	return LockstepGroup{Patterns: r.Patterns}.Matches(modulePath)
+}
+
+// Incompatibility is a rule broken by the versions of its members
+type Incompatibility struct {
+	Rule string
+	// Versions maps each member required at that major version to its version
+	Versions map[string]string
+}
+
+func (i Incompatibility) String() string {
+	members := make([]string, 0, len(i.Versions))
+	for _, module := range sortedTargets(i.Versions) {
+		members = append(members, module+"@"+i.Versions[module])
+	}
+	return fmt.Sprintf("%s (%s)", i.Rule, strings.Join(members, ", "))
+}
+
+// key identifies the rule and major version an incompatibility is about
+func (i Incompatibility) key() string {
+	for _, version := range i.Versions {
+		return i.Rule + "@" + semver.Major(version)
+	}
+	return i.Rule
+}
+
+// CheckCompatibility returns the rules broken by the requirements of p
+func CheckCompatibility(rules []CompatibilityRule, p *Parser) []Incompatibility {
+	var broken []Incompatibility
+	for _, rule := range rules {
+		byMajor := make(map[string]map[string]string)
+		for _, req := range p.ModFile.Require {
+			if !rule.Matches(req.Mod.Path) || !semver.IsValid(req.Mod.Version) {
+				continue
+			}
+			major := semver.Major(req.Mod.Version)
+			if rule.StableOnly && major == "v0" {
+				continue
+			}
+			if byMajor[major] == nil {
+				byMajor[major] = make(map[string]string)
+			}
+			byMajor[major][req.Mod.Path] = req.Mod.Version
+		}
+
+		majors := make([]string, 0, len(byMajor))
+		for major := range byMajor {
+			majors = append(majors, major)
+		}
+		sort.Strings(majors)
+
+		for _, major := range majors {
+			seen := make(map[string]bool)
+			for _, version := range byMajor[major] {
+				seen[compatibilityKey(rule.Match, version)] = true
+			}
+			if len(seen) > 1 {
+				broken = append(broken, Incompatibility{Rule: rule.Name, Versions: byMajor[major]})
+			}
+		}
+	}
+	return broken
+}
+
+// compatibilityKey returns the part of version members must share
+func compatibilityKey(match, version string) string {
+	if match == MatchExact {
+		return semver.Canonical(version)
+	}
+	return semver.MajorMinor(version)
+}
+
+// IncompatibleError reports an update that would break compatibility rules
+type IncompatibleError struct {
+	Module, Version string
+	Broken          []Incompatibility
+}
+
+func (e *IncompatibleError) Error() string {
+	broken := make([]string, 0, len(e.Broken))
+	for _, b := range e.Broken {
+		broken = append(broken, b.String())
+	}
+	return fmt.Sprintf("updating %s to %s breaks version compatibility: %s",
+		e.Module, e.Version, strings.Join(broken, "; "))
+}
+
+// newIncompatibilities returns the incompatibilities in after that are not
+// in before, e.g. were introduced by an update
+func newIncompatibilities(before, after []Incompatibility) []Incompatibility {
+	existing := make(map[string]bool, len(before))
+	for _, b := range before {
+		existing[b.key()] = true
+	}
+
+	var introduced []Incompatibility
+	for _, a := range after {
+		if !existing[a.key()] {
+			introduced = append(introduced, a)
+		}
+	}
+	return introduced
+}
@@ -0,0 +1,80 @@
+package gomod
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tamcore/go-autobump/internal/runner"
+)
+
+func TestCheckCompatibility(t *testing.T) {
+	tests := []struct {
+		name     string
+		requires string
+		want     int
+	}{
+		{"same minor", "k8s.io/api v0.29.1\n\tk8s.io/client-go v0.29.4\n", 0},
+		{"minor mismatch", "k8s.io/api v0.30.0\n\tk8s.io/client-go v0.29.4\n", 1},
+		{"otel same version", "go.opentelemetry.io/otel v1.24.0\n\tgo.opentelemetry.io/otel/trace v1.24.0\n", 0},
+		{"otel patch mismatch", "go.opentelemetry.io/otel v1.24.0\n\tgo.opentelemetry.io/otel/trace v1.24.1\n", 1},
+		{"otel other major", "go.opentelemetry.io/otel v1.24.0\n\tgo.opentelemetry.io/otel/log v0.1.0\n", 0},
+		{"otel experimental modules", "go.opentelemetry.io/otel/log v0.8.0\n\tgo.opentelemetry.io/otel/exporters/prometheus v0.51.0\n", 0},
+		{"contrib v0 modules", "go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp v0.49.0\n\tgo.opentelemetry.io/contrib/samplers/jaegerremote v0.18.0\n", 0},
+		{"contrib stable mismatch", "go.opentelemetry.io/contrib/detectors/gcp v1.24.0\n\tgo.opentelemetry.io/contrib/propagators/b3 v1.23.0\n", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goModPath := filepath.Join(t.TempDir(), "go.mod")
+			goMod := "module example.com/app\n\nrequire (\n\t" + tt.requires + ")\n"
+			if err := os.WriteFile(goModPath, []byte(goMod), 0644); err != nil {
+				t.Fatal(err)
+			}
+			p, err := NewParser(goModPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := CheckCompatibility(BuiltinCompatibilityRules, p); len(got) != tt.want {
+				t.Errorf("CheckCompatibility() = %v, want %d incompatibilities", got, tt.want)
+			}
+		})
+	}
+}
+
+// bumpingRunner answers go get by writing goMod to the module's go.mod
+type bumpingRunner struct {
+	goModPath, goMod string
+}
+
+func (r *bumpingRunner) Run(_ context.Context, _, _ string, _ ...string) ([]byte, []byte, error) {
+	return nil, nil, os.WriteFile(r.goModPath, []byte(r.goMod), 0644)
+}
+
+func TestSessionGoGetIncompatible(t *testing.T) {
+	goModPath := filepath.Join(t.TempDir(), "go.mod")
+	original := "module example.com/app\n\nrequire (\n\tk8s.io/api v0.29.1\n\tk8s.io/client-go v0.29.1\n)\n"
+	if err := os.WriteFile(goModPath, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	defer runner.Set(runner.Default())
+	runner.Set(&bumpingRunner{
+		goModPath: goModPath,
+		goMod:     "module example.com/app\n\nrequire (\n\tk8s.io/api v0.29.1\n\tk8s.io/client-go v0.30.2\n)\n",
+	})
+
+	sess := NewSession(goModPath)
+	sess.Compatibility = BuiltinCompatibilityRules
+	err := sess.GoGet("k8s.io/client-go", "v0.30.2")
+
+	var incompatibleErr *IncompatibleError
+	if !errors.As(err, &incompatibleErr) {
+		t.Fatalf("GoGet() error = %v, want *IncompatibleError", err)
+	}
+	if data, _ := os.ReadFile(goModPath); string(data) != original {
+		t.Errorf("go.mod was not restored:\n%s", data)
+	}
+}
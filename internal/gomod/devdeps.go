@@ -0,0 +1,28 @@
+package gomod
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tamcore/go-autobump/internal/runner"
+)
+
+// ProductionModules returns the modules providing packages that the
+// non-test packages of the module in moduleDir import, directly or
+// indirectly. Required modules missing from the result are only reachable
+// from _test.go files or from files behind build tags that are not set by
+// default, such as tools.go files with a "tools" tag.
+func ProductionModules(moduleDir string) (map[string]bool, error) {
+	stdout, stderr, err := runner.Run(moduleDir, runner.Go, "list", "-deps", "-f", "{{with .Module}}{{.Path}}{{end}}", "./...")
+	if err != nil {
+		return nil, fmt.Errorf("go list -deps failed: %w\nstderr: %s", err, stderr)
+	}
+
+	modules := make(map[string]bool)
+	for _, line := range strings.Split(string(stdout), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			modules[line] = true
+		}
+	}
+	return modules, nil
+}
@@ -0,0 +1,59 @@
+package gomod
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProductionModules(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": `module example.com/app
+
+go 1.21
+
+require (
+	example.com/lib v0.0.0
+	example.com/testlib v0.0.0
+)
+
+replace (
+	example.com/lib => ./lib
+	example.com/testlib => ./testlib
+)
+`,
+		"main.go":            "package main\n\nimport _ \"example.com/lib\"\n\nfunc main() {}\n",
+		"main_test.go":       "package main\n\nimport _ \"example.com/testlib\"\n",
+		"lib/go.mod":         "module example.com/lib\n\ngo 1.21\n",
+		"lib/lib.go":         "package lib\n",
+		"testlib/go.mod":     "module example.com/testlib\n\ngo 1.21\n",
+		"testlib/testlib.go": "package testlib\n",
+	}
+	writeFiles(t, dir, files)
+
+	modules, err := ProductionModules(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !modules["example.com/app"] || !modules["example.com/lib"] {
+		t.Errorf("modules = %v, want example.com/app and example.com/lib", modules)
+	}
+	if modules["example.com/testlib"] {
+		t.Errorf("test-only module example.com/testlib reported as production dependency")
+	}
+}
+
+// writeFiles writes files, by path relative to dir, creating directories
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
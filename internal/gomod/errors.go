@@ -0,0 +1,161 @@
+package gomod
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrFixNotPublished is wrapped by the error of a go command that failed
+// because the module proxy or origin does not serve the requested version
+// (yet)
+var ErrFixNotPublished = errors.New("fix not published")
+
+// ErrBuildBroken is wrapped by the error of a go command that failed because
+// packages of the module no longer load, e.g. when an update dropped a
+// package the module imports
+var ErrBuildBroken = errors.New("build broken")
+
+// notPublishedMarkers are go command errors for versions the module proxy
+// or origin does not serve (yet)
+var notPublishedMarkers = []string{
+	"unknown revision",
+	"invalid version",
+	"no matching versions",
+	"410 Gone",
+	"404 Not Found",
+}
+
+// buildBrokenMarkers are go command errors for imports that no longer
+// resolve to exactly one package
+var buildBrokenMarkers = []string{
+	"does not contain package",
+	"no required module provides package",
+	"ambiguous import",
+}
+
+// kindError attaches the kind of a go command failure to its error, keeping
+// the message
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+// ChecksumMismatchError is returned for a go command that refused a module
+// download whose content does not match the checksum recorded in go.sum or
+// the checksum database. It points to a tampered module proxy, origin or
+// network, or to a rewritten tag, and must not be worked around.
+type ChecksumMismatchError struct {
+	Module  string
+	Version string
+	// GoMod is set if the go.mod file of the module mismatched rather than
+	// its zip
+	GoMod bool
+	// Downloaded is the hash of the content that was served
+	Downloaded string
+	// Expected is the hash recorded by Source, "go.sum" or the checksum
+	// database, e.g. "sum.golang.org"
+	Expected string
+	Source   string
+
+	err error
+}
+
+func (e *ChecksumMismatchError) Error() string {
+	return e.err.Error()
+}
+
+func (e *ChecksumMismatchError) Unwrap() error {
+	return e.err
+}
+
+// goError returns err of a go command, as a *ChecksumMismatchError if the
+// go command refused a download, or matching ErrFixNotPublished or
+// ErrBuildBroken with errors.Is if its stderr shows either
+func goError(err error, stderr []byte) error {
+	if mismatch := parseChecksumMismatch(string(stderr)); mismatch != nil {
+		mismatch.err = err
+		return mismatch
+	}
+	switch msg := string(stderr); {
+	case containsAny(msg, notPublishedMarkers):
+		return &kindError{kind: ErrFixNotPublished, err: err}
+	case containsAny(msg, buildBrokenMarkers):
+		return &kindError{kind: ErrBuildBroken, err: err}
+	}
+	return err
+}
+
+// parseChecksumMismatch returns the first checksum mismatch the go command
+// reported in stderr, e.g.
+//
+//	verifying example.com/dep@v1.0.1/go.mod: checksum mismatch
+//		downloaded: h1:...
+//		sum.golang.org: h1:...
+//
+// or nil if there is none
+func parseChecksumMismatch(stderr string) *ChecksumMismatchError {
+	lines := strings.Split(stderr, "\n")
+	for i, line := range lines {
+		head, _, ok := strings.Cut(line, ": checksum mismatch")
+		if !ok {
+			continue
+		}
+		head = strings.TrimPrefix(strings.TrimSpace(head), "go: ")
+
+		// "verifying <module>@<version>[/go.mod]" from go get and go mod
+		// download, "<module>@<version>: verifying <module|go.mod>" from
+		// go mod verify and builds
+		var ref string
+		var goMod bool
+		if after, found := strings.CutPrefix(head, "verifying "); found {
+			ref = after
+		} else if before, what, found := strings.Cut(head, ": verifying "); found {
+			ref, goMod = before, what == "go.mod"
+		} else {
+			continue
+		}
+		if before, found := strings.CutSuffix(ref, "/go.mod"); found {
+			ref, goMod = before, true
+		}
+		at := strings.LastIndex(ref, "@")
+		if at < 0 {
+			continue
+		}
+
+		e := &ChecksumMismatchError{Module: ref[:at], Version: ref[at+1:], GoMod: goMod}
+		for _, detail := range lines[i+1:] {
+			key, value, ok := strings.Cut(strings.TrimSpace(detail), ":")
+			if !ok {
+				break
+			}
+			if key == "downloaded" {
+				e.Downloaded = strings.TrimSpace(value)
+			} else {
+				e.Source, e.Expected = key, strings.TrimSpace(value)
+			}
+			if e.Downloaded != "" && e.Expected != "" {
+				break
+			}
+		}
+		return e
+	}
+	return nil
+}
+
+// containsAny reports whether s contains any of substrs
+func containsAny(s string, substrs []string) bool {
+	for _, sub := range substrs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package gomod
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestGoError(t *testing.T) {
+	exit := errors.New("exit status 1")
+
+	tests := []struct {
+		name   string
+		stderr string
+		want   error
+	}{
+		{"not published", "go: example.com/dep@v1.0.1: invalid version: unknown revision v1.0.1", ErrFixNotPublished},
+		{"build broken", "go: finding module for package example.com/dep/old\nexample.com/app imports\n\texample.com/dep/old: module example.com/dep@latest found (v1.1.0), but does not contain package example.com/dep/old", ErrBuildBroken},
+		{"other", "go: example.com/dep@v1.0.1: reading go.mod: unexpected EOF", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := fmt.Errorf("go get failed: %w", goError(exit, []byte(tt.stderr)))
+			if !errors.Is(err, exit) {
+				t.Error("error no longer wraps the go command's error")
+			}
+			for _, kind := range []error{ErrFixNotPublished, ErrBuildBroken} {
+				if got := errors.Is(err, kind); got != (kind == tt.want) {
+					t.Errorf("errors.Is(err, %v) = %v", kind, got)
+				}
+			}
+			if err.Error() != "go get failed: exit status 1" {
+				t.Errorf("message = %q", err.Error())
+			}
+		})
+	}
+}
+
+func TestGoErrorChecksumMismatch(t *testing.T) {
+	exit := errors.New("exit status 1")
+
+	tests := []struct {
+		name   string
+		stderr string
+		want   ChecksumMismatchError
+	}{
+		{
+			"checksum database",
+			"go: downloading example.com/dep v1.0.1\n" +
+				"verifying example.com/dep@v1.0.1/go.mod: checksum mismatch\n" +
+				"\tdownloaded: h1:AAAA=\n" +
+				"\tsum.golang.org: h1:BBBB=\n\n" +
+				"SECURITY ERROR\nThis download does NOT match the one reported by the checksum server.\n",
+			ChecksumMismatchError{Module: "example.com/dep", Version: "v1.0.1", GoMod: true,
+				Downloaded: "h1:AAAA=", Expected: "h1:BBBB=", Source: "sum.golang.org"},
+		},
+		{
+			"go.sum",
+			"go: example.com/dep@v1.0.1: verifying module: checksum mismatch\n" +
+				"\tdownloaded: h1:AAAA=\n" +
+				"\tgo.sum:     h1:CCCC=\n\n" +
+				"SECURITY ERROR\n",
+			ChecksumMismatchError{Module: "example.com/dep", Version: "v1.0.1",
+				Downloaded: "h1:AAAA=", Expected: "h1:CCCC=", Source: "go.sum"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := fmt.Errorf("go get failed: %w", goError(exit, []byte(tt.stderr)))
+			var mismatch *ChecksumMismatchError
+			if !errors.As(err, &mismatch) {
+				t.Fatalf("error %v is not a checksum mismatch", err)
+			}
+			if !errors.Is(err, exit) {
+				t.Error("error no longer wraps the go command's error")
+			}
+			mismatch.err = nil
+			if *mismatch != tt.want {
+				t.Errorf("mismatch = %+v, want %+v", *mismatch, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package gomod
+
+import (
+	"sort"
+
+	"golang.org/x/mod/semver"
+)
+
+// RankedDependency is a requirement of the main module that transitively
+// requires some other module, along with its distance in the module graph
+type RankedDependency struct {
+	Path     string
+	Distance int
+}
+
+// Graph is the module requirement graph of a main module, built once from
+// "go mod graph" and queried in memory. Nodes are module paths; the versions
+// a module appears with are tracked separately.
+type Graph struct {
+	edges []GraphEdge
+	main  string
+
+	// requires and requiredBy hold the edges between non-main modules
+	requires   map[string][]string
+	requiredBy map[string][]string
+	// direct holds the main module's requirements
+	direct   map[string]bool
+	versions map[string][]string
+}
+
+// NewGraph indexes the edges of a module graph
+func NewGraph(edges []GraphEdge) *Graph {
+	g := &Graph{
+		edges:      edges,
+		requires:   make(map[string][]string),
+		requiredBy: make(map[string][]string),
+		direct:     make(map[string]bool),
+		versions:   make(map[string][]string),
+	}
+
+	seenEdge := make(map[[2]string]bool)
+	seenVersion := make(map[ModuleVersion]bool)
+	for _, edge := range edges {
+		for _, mv := range []ModuleVersion{edge.From, edge.To} {
+			if mv.Version != "" && !seenVersion[mv] {
+				seenVersion[mv] = true
+				g.versions[mv.Path] = append(g.versions[mv.Path], mv.Version)
+			}
+		}
+
+		// The main module is the only node listed without a version
+		if edge.From.Version == "" {
+			g.main = edge.From.Path
+			g.direct[edge.To.Path] = true
+			continue
+		}
+		key := [2]string{edge.From.Path, edge.To.Path}
+		if !seenEdge[key] {
+			seenEdge[key] = true
+			g.requires[edge.From.Path] = append(g.requires[edge.From.Path], edge.To.Path)
+			g.requiredBy[edge.To.Path] = append(g.requiredBy[edge.To.Path], edge.From.Path)
+		}
+	}
+
+	for _, versions := range g.versions {
+		semver.Sort(versions)
+	}
+	return g
+}
+
+// Edges returns the edges the graph was built from
+func (g *Graph) Edges() []GraphEdge {
+	return g.edges
+}
+
+// Main returns the path of the main module
+func (g *Graph) Main() string {
+	return g.main
+}
+
+// VersionsOf returns every version of module appearing in the graph, in
+// semver order. The last one is the version selected by MVS.
+func (g *Graph) VersionsOf(module string) []string {
+	return g.versions[module]
+}
+
+// DirectAncestors returns every module required by the main module that
+// transitively requires module. Results are ordered by graph distance
+// (closest first), then by path. A distance of 1 means the module requires
+// module directly.
+func (g *Graph) DirectAncestors(module string) []RankedDependency {
+	distance := g.distancesTo(module)
+
+	var ranked []RankedDependency
+	for path := range g.direct {
+		if path == module {
+			continue
+		}
+		if d, ok := distance[path]; ok {
+			ranked = append(ranked, RankedDependency{Path: path, Distance: d})
+		}
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Distance != ranked[j].Distance {
+			return ranked[i].Distance < ranked[j].Distance
+		}
+		return ranked[i].Path < ranked[j].Path
+	})
+
+	return ranked
+}
+
+// PathsTo returns, for each requirement of the main module leading to
+// module, the shortest requirement chain from the main module to module,
+// ordered like DirectAncestors. A direct requirement yields [main, module].
+func (g *Graph) PathsTo(module string) [][]string {
+	distance := g.distancesTo(module)
+
+	var starts []string
+	if g.direct[module] {
+		starts = append(starts, module)
+	}
+	for _, dep := range g.DirectAncestors(module) {
+		starts = append(starts, dep.Path)
+	}
+
+	var paths [][]string
+	for _, start := range starts {
+		// Follow requirements that get strictly closer to module, picking
+		// the smallest path on ties so results are stable
+		path := []string{g.main, start}
+		for current := start; current != module; {
+			next := ""
+			for _, child := range g.requires[current] {
+				d, ok := distance[child]
+				if ok && d == distance[current]-1 && (next == "" || child < next) {
+					next = child
+				}
+			}
+			if next == "" {
+				break
+			}
+			path = append(path, next)
+			current = next
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// distancesTo walks the reversed requirements from module breadth-first,
+// yielding the shortest distance from each non-main module to it
+func (g *Graph) distancesTo(module string) map[string]int {
+	distance := map[string]int{module: 0}
+	queue := []string{module}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, parent := range g.requiredBy[current] {
+			if _, seen := distance[parent]; seen {
+				continue
+			}
+			distance[parent] = distance[current] + 1
+			queue = append(queue, parent)
+		}
+	}
+	return distance
+}
+
+// DependentsOf returns every module required by the main module that
+// transitively requires target; see Graph.DirectAncestors
+func DependentsOf(edges []GraphEdge, target string) []RankedDependency {
+	return NewGraph(edges).DirectAncestors(target)
+}
+
+// FilterToTargets keeps only the edges that lie on some path from the main
+// module to one of the target modules. Versions are ignored when matching.
+func FilterToTargets(edges []GraphEdge, targets []string) []GraphEdge {
+	reverse := make(map[string][]string)
+	for _, edge := range edges {
+		reverse[edge.To.Path] = append(reverse[edge.To.Path], edge.From.Path)
+	}
+
+	// Everything that can reach a target, including the targets themselves
+	reaches := make(map[string]bool)
+	queue := append([]string(nil), targets...)
+	for _, target := range targets {
+		reaches[target] = true
+	}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, parent := range reverse[current] {
+			if !reaches[parent] {
+				reaches[parent] = true
+				queue = append(queue, parent)
+			}
+		}
+	}
+
+	var filtered []GraphEdge
+	for _, edge := range edges {
+		if reaches[edge.From.Path] && reaches[edge.To.Path] {
+			filtered = append(filtered, edge)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,89 @@
+package gomod
+
+import (
+	"reflect"
+	"testing"
+)
+
+func edge(from, to string) GraphEdge {
+	return GraphEdge{From: parseModuleVersion(from), To: parseModuleVersion(to)}
+}
+
+func TestDependentsOf(t *testing.T) {
+	edges := []GraphEdge{
+		edge("example.com/app", "github.com/a/one@v1.0.0"),
+		edge("example.com/app", "github.com/b/two@v1.0.0"),
+		edge("example.com/app", "github.com/c/three@v1.0.0"),
+		edge("example.com/app", "golang.org/x/net@v0.1.0"),
+		edge("github.com/a/one@v1.0.0", "golang.org/x/net@v0.1.0"),
+		edge("github.com/b/two@v1.0.0", "github.com/d/four@v1.0.0"),
+		edge("github.com/d/four@v1.0.0", "golang.org/x/net@v0.0.9"),
+	}
+
+	got := DependentsOf(edges, "golang.org/x/net")
+	want := []RankedDependency{
+		{Path: "github.com/a/one", Distance: 1},
+		{Path: "github.com/b/two", Distance: 2},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DependentsOf() = %+v, want %+v", got, want)
+	}
+}
+
+func TestGraph(t *testing.T) {
+	g := NewGraph([]GraphEdge{
+		edge("example.com/app", "github.com/a/one@v1.0.0"),
+		edge("example.com/app", "github.com/b/two@v1.0.0"),
+		edge("example.com/app", "golang.org/x/net@v0.1.0"),
+		edge("github.com/a/one@v1.0.0", "golang.org/x/net@v0.1.0"),
+		edge("github.com/b/two@v1.0.0", "github.com/d/four@v1.0.0"),
+		edge("github.com/d/four@v1.0.0", "golang.org/x/net@v0.0.9"),
+		edge("github.com/d/four@v1.0.0", "golang.org/x/net@v0.0.10"),
+	})
+
+	if g.Main() != "example.com/app" {
+		t.Errorf("Main() = %q", g.Main())
+	}
+
+	if got, want := g.VersionsOf("golang.org/x/net"), []string{"v0.0.9", "v0.0.10", "v0.1.0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("VersionsOf() = %v, want %v", got, want)
+	}
+
+	wantAncestors := []RankedDependency{
+		{Path: "github.com/a/one", Distance: 1},
+		{Path: "github.com/b/two", Distance: 2},
+	}
+	if got := g.DirectAncestors("golang.org/x/net"); !reflect.DeepEqual(got, wantAncestors) {
+		t.Errorf("DirectAncestors() = %+v, want %+v", got, wantAncestors)
+	}
+
+	wantPaths := [][]string{
+		{"example.com/app", "golang.org/x/net"},
+		{"example.com/app", "github.com/a/one", "golang.org/x/net"},
+		{"example.com/app", "github.com/b/two", "github.com/d/four", "golang.org/x/net"},
+	}
+	if got := g.PathsTo("golang.org/x/net"); !reflect.DeepEqual(got, wantPaths) {
+		t.Errorf("PathsTo() = %v, want %v", got, wantPaths)
+	}
+
+	if got := g.PathsTo("example.com/unknown"); got != nil {
+		t.Errorf("PathsTo(unknown) = %v, want nil", got)
+	}
+}
+
+func TestFilterToTargets(t *testing.T) {
+	edges := []GraphEdge{
+		edge("example.com/app", "github.com/a/one@v1.0.0"),
+		edge("example.com/app", "github.com/b/two@v1.0.0"),
+		edge("github.com/a/one@v1.0.0", "golang.org/x/net@v0.1.0"),
+		edge("github.com/b/two@v1.0.0", "github.com/d/four@v1.0.0"),
+		edge("golang.org/x/net@v0.1.0", "golang.org/x/text@v0.3.0"),
+	}
+
+	got := FilterToTargets(edges, []string{"golang.org/x/net"})
+	want := []GraphEdge{edges[0], edges[2]}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FilterToTargets() = %+v, want %+v", got, want)
+	}
+}
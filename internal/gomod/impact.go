@@ -0,0 +1,75 @@
+package gomod
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tamcore/go-autobump/internal/runner"
+)
+
+// importersFormat prints, per package, whether it belongs to the main
+// module, its module and import path, and for main module packages the
+// packages they import directly or indirectly
+const importersFormat = `{{with .Module}}{{.Main}} {{.Path}} {{$.ImportPath}}{{if .Main}} {{join $.Deps " "}}{{end}}{{end}}`
+
+// ImportingPackages returns, for each module required by the module in
+// moduleDir, the packages of that module importing one of its packages,
+// directly or indirectly. Packages are given relative to the module path,
+// "." being the package at its root, and are sorted. Tests are not
+// considered, as "go list -deps ./..." leaves them out.
+func ImportingPackages(moduleDir string) (map[string][]string, error) {
+	stdout, stderr, err := runner.Run(moduleDir, runner.Go, "list", "-deps", "-f", importersFormat, "./...")
+	if err != nil {
+		return nil, fmt.Errorf("go list -deps failed: %w\nstderr: %s", err, stderr)
+	}
+
+	packageModules := make(map[string]string)
+	type mainPackage struct {
+		path string
+		deps []string
+	}
+	var mains []mainPackage
+	for _, line := range strings.Split(string(stdout), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		modulePath, importPath := fields[1], fields[2]
+		packageModules[importPath] = modulePath
+		if fields[0] == "true" {
+			mains = append(mains, mainPackage{path: relativePackage(modulePath, importPath), deps: fields[3:]})
+		}
+	}
+
+	importers := make(map[string]map[string]bool)
+	for _, pkg := range mains {
+		for _, dep := range pkg.deps {
+			modulePath, ok := packageModules[dep]
+			if !ok {
+				continue
+			}
+			if importers[modulePath] == nil {
+				importers[modulePath] = make(map[string]bool)
+			}
+			importers[modulePath][pkg.path] = true
+		}
+	}
+
+	result := make(map[string][]string, len(importers))
+	for modulePath, pkgs := range importers {
+		for pkg := range pkgs {
+			result[modulePath] = append(result[modulePath], pkg)
+		}
+		sort.Strings(result[modulePath])
+	}
+	return result, nil
+}
+
+// relativePackage returns importPath relative to modulePath
+func relativePackage(modulePath, importPath string) string {
+	if importPath == modulePath {
+		return "."
+	}
+	return strings.TrimPrefix(importPath, modulePath+"/")
+}
@@ -0,0 +1,46 @@
+package gomod
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestImportingPackages(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod": `module example.com/app
+
+go 1.21
+
+require (
+	example.com/lib v0.0.0
+	example.com/vuln v0.0.0
+)
+
+replace (
+	example.com/lib => ./lib
+	example.com/vuln => ./vuln
+)
+`,
+		"main.go":               "package main\n\nimport _ \"example.com/app/internal/auth\"\n\nfunc main() {}\n",
+		"internal/auth/auth.go": "package auth\n\nimport _ \"example.com/lib\"\n",
+		"cmd/api/main.go":       "package main\n\nimport _ \"example.com/vuln/sub\"\n\nfunc main() {}\n",
+		"cmd/api/main_test.go":  "package main\n\nimport _ \"example.com/lib\"\n",
+		"lib/go.mod":            "module example.com/lib\n\ngo 1.21\n\nrequire example.com/vuln v0.0.0\n\nreplace example.com/vuln => ../vuln\n",
+		"lib/lib.go":            "package lib\n\nimport _ \"example.com/vuln\"\n",
+		"vuln/go.mod":           "module example.com/vuln\n\ngo 1.21\n",
+		"vuln/vuln.go":          "package vuln\n",
+		"vuln/sub/sub.go":       "package sub\n",
+	})
+
+	importers, err := ImportingPackages(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := importers["example.com/vuln"], []string{".", "cmd/api", "internal/auth"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("importers of example.com/vuln = %v, want %v", got, want)
+	}
+	if got, want := importers["example.com/lib"], []string{".", "internal/auth"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("importers of example.com/lib = %v, want %v (tests are not considered)", got, want)
+	}
+}
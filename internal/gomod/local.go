@@ -0,0 +1,98 @@
+package gomod
+
+import (
+	"path/filepath"
+
+	"golang.org/x/mod/modfile"
+)
+
+// LocalDependency is a module of the same repository that another module
+// depends on
+type LocalDependency struct {
+	// GoModPath is the go.mod of the sibling module
+	GoModPath string
+	// Replaced is set if the dependent module replaces the sibling with its
+	// directory, so it builds against the sibling's working tree
+	Replaced bool
+}
+
+// LocalDependencies returns, for each of the given go.mod files, the other
+// given modules it requires or replaces with a local directory. go.mod
+// files that cannot be parsed have no dependencies.
+func LocalDependencies(goModFiles []string) map[string][]LocalDependency {
+	byPath := make(map[string]string, len(goModFiles))
+	byDir := make(map[string]string, len(goModFiles))
+	parsed := make(map[string]*modfile.File, len(goModFiles))
+	for _, goModFile := range goModFiles {
+		parser, err := NewParser(goModFile)
+		if err != nil {
+			continue
+		}
+		parsed[goModFile] = parser.ModFile
+		if path := parser.ModulePath(); path != "" {
+			byPath[path] = goModFile
+		}
+		byDir[filepath.Clean(GetModuleDir(goModFile))] = goModFile
+	}
+
+	deps := make(map[string][]LocalDependency, len(goModFiles))
+	for _, goModFile := range goModFiles {
+		f := parsed[goModFile]
+		if f == nil {
+			continue
+		}
+
+		seen := make(map[string]int)
+		add := func(dep string, replaced bool) {
+			if dep == "" || dep == goModFile {
+				return
+			}
+			if i, ok := seen[dep]; ok {
+				deps[goModFile][i].Replaced = deps[goModFile][i].Replaced || replaced
+				return
+			}
+			seen[dep] = len(deps[goModFile])
+			deps[goModFile] = append(deps[goModFile], LocalDependency{GoModPath: dep, Replaced: replaced})
+		}
+
+		for _, req := range f.Require {
+			add(byPath[req.Mod.Path], false)
+		}
+		for _, rep := range f.Replace {
+			if !modfile.IsDirectoryPath(rep.New.Path) {
+				continue
+			}
+			dir := rep.New.Path
+			if !filepath.IsAbs(dir) {
+				dir = filepath.Join(GetModuleDir(goModFile), dir)
+			}
+			add(byDir[filepath.Clean(dir)], true)
+		}
+	}
+	return deps
+}
+
+// OrderByLocalDependencies sorts goModFiles so that every module comes after
+// the sibling modules it depends on, keeping the given order otherwise.
+// A dependency cycle is broken at the module of the cycle given first.
+func OrderByLocalDependencies(goModFiles []string, deps map[string][]LocalDependency) []string {
+	ordered := make([]string, 0, len(goModFiles))
+	visited := make(map[string]bool, len(goModFiles))
+
+	var visit func(goModFile string)
+	visit = func(goModFile string) {
+		if visited[goModFile] {
+			return
+		}
+		visited[goModFile] = true
+		for _, dep := range deps[goModFile] {
+			visit(dep.GoModPath)
+		}
+		ordered = append(ordered, goModFile)
+	}
+
+	for _, goModFile := range goModFiles {
+		visit(goModFile)
+	}
+	return ordered
+}
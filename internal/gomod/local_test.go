@@ -0,0 +1,44 @@
+package gomod
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestOrderByLocalDependencies(t *testing.T) {
+	root := t.TempDir()
+	modules := map[string]string{
+		"app":    "module example.com/app\n\nrequire example.com/lib v0.0.0\n\nreplace example.com/lib => ../lib\n",
+		"cli":    "module example.com/cli\n\nrequire example.com/app v1.2.0\n",
+		"lib":    "module example.com/lib\n\nrequire golang.org/x/net v0.20.0\n",
+		"sample": "module example.com/sample\n",
+	}
+
+	goMod := func(name string) string { return filepath.Join(root, name, "go.mod") }
+	for name, content := range modules {
+		if err := os.MkdirAll(filepath.Join(root, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(goMod(name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	goModFiles := []string{goMod("app"), goMod("cli"), goMod("lib"), goMod("sample")}
+	deps := LocalDependencies(goModFiles)
+
+	if want := []LocalDependency{{GoModPath: goMod("lib"), Replaced: true}}; !slices.Equal(deps[goMod("app")], want) {
+		t.Errorf("dependencies of app = %v, want %v", deps[goMod("app")], want)
+	}
+	if want := []LocalDependency{{GoModPath: goMod("app")}}; !slices.Equal(deps[goMod("cli")], want) {
+		t.Errorf("dependencies of cli = %v, want %v", deps[goMod("cli")], want)
+	}
+
+	got := OrderByLocalDependencies(goModFiles, deps)
+	want := []string{goMod("lib"), goMod("app"), goMod("cli"), goMod("sample")}
+	if !slices.Equal(got, want) {
+		t.Errorf("OrderByLocalDependencies() = %v, want %v", got, want)
+	}
+}
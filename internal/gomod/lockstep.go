@@ -0,0 +1,72 @@
+package gomod
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+// LockstepGroup is a set of modules that must move together, e.g. all
+// k8s.io/* modules, which are released with the same version numbers
+type LockstepGroup struct {
+	Name string
+	// Patterns match module paths; a trailing "*" matches any suffix,
+	// including further path elements, other patterns follow path.Match
+	Patterns []string
+}
+
+// Matches reports whether modulePath is a member of the group
+func (g LockstepGroup) Matches(modulePath string) bool {
+	for _, pattern := range g.Patterns {
+		if MatchPattern(pattern, modulePath) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchPattern reports whether modulePath matches pattern. A trailing "*"
+// matches any suffix, including further path elements; other patterns
+// follow path.Match.
+func MatchPattern(pattern, modulePath string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok && !strings.ContainsAny(prefix, "*?[") {
+		return strings.HasPrefix(modulePath, prefix)
+	}
+	ok, _ := path.Match(pattern, modulePath)
+	return ok
+}
+
+// lockstepTargets returns the versions to update the members of pkgPath's
+// lockstep groups required by p to, when pkgPath is updated to version.
+// Members at the same version as pkgPath move to version as well; members
+// with their own version numbering are left to the version resolution of
+// the go command.
+func lockstepTargets(groups []LockstepGroup, p *Parser, pkgPath, version string) map[string]string {
+	current := p.GetVersion(pkgPath)
+	if current == "" {
+		return nil
+	}
+
+	targets := make(map[string]string)
+	for _, group := range groups {
+		if !group.Matches(pkgPath) {
+			continue
+		}
+		for _, req := range p.ModFile.Require {
+			if req.Mod.Path != pkgPath && req.Mod.Version == current && group.Matches(req.Mod.Path) {
+				targets[req.Mod.Path] = version
+			}
+		}
+	}
+	return targets
+}
+
+// sortedTargets returns the keys of targets in order
+func sortedTargets(targets map[string]string) []string {
+	keys := make([]string, 0, len(targets))
+	for key := range targets {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
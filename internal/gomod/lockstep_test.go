@@ -0,0 +1,62 @@
+package gomod
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/tamcore/go-autobump/internal/runner"
+)
+
+func TestLockstepGroupMatches(t *testing.T) {
+	group := LockstepGroup{Patterns: []string{"k8s.io/*", "google.golang.org/genproto*"}}
+	tests := []struct {
+		module string
+		want   bool
+	}{
+		{"k8s.io/api", true},
+		{"k8s.io/client-go", true},
+		{"google.golang.org/genproto", true},
+		{"google.golang.org/genproto/googleapis/rpc", true},
+		{"google.golang.org/grpc", false},
+		{"sigs.k8s.io/yaml", false},
+	}
+
+	for _, tt := range tests {
+		if got := group.Matches(tt.module); got != tt.want {
+			t.Errorf("Matches(%q) = %v, want %v", tt.module, got, tt.want)
+		}
+	}
+}
+
+func TestSessionGoGetLockstep(t *testing.T) {
+	stub := &countingRunner{}
+	defer runner.Set(runner.Default())
+	runner.Set(stub)
+
+	goModPath := filepath.Join(t.TempDir(), "go.mod")
+	goMod := `module example.com/app
+
+require (
+	k8s.io/api v0.29.1
+	k8s.io/apimachinery v0.29.1
+	k8s.io/klog/v2 v2.110.1
+	golang.org/x/net v0.20.0
+)
+`
+	if err := os.WriteFile(goModPath, []byte(goMod), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sess := NewSession(goModPath)
+	sess.Lockstep = []LockstepGroup{{Name: "kubernetes", Patterns: []string{"k8s.io/*"}}}
+	if err := sess.GoGet("k8s.io/apimachinery", "v0.29.4"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"get", "k8s.io/api@v0.29.4", "k8s.io/apimachinery@v0.29.4"}
+	if len(stub.calls) != 1 || !slices.Equal(stub.calls[0], want) {
+		t.Errorf("go invocations = %v, want [%v]", stub.calls, want)
+	}
+}
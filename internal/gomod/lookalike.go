@@ -0,0 +1,125 @@
+package gomod
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// lookalikeDistance is the largest edit distance at which a module path is
+// considered a lookalike of another
+const lookalikeDistance = 2
+
+// SuspiciousPath returns why adding the module at path, while updating the
+// module advisory names, looks like a mistake such as a typosquatted or
+// rewritten import path, or "" if it does not. Modules already required are
+// trusted. The advisory's module, the module of the package it names and
+// their major version lines are expected, unless they differ from a required
+// module only in case. Any other module is suspicious; the reason names the
+// module it resembles, if any.
+func SuspiciousPath(path, advisory string, required []string) string {
+	if slices.Contains(required, path) {
+		return ""
+	}
+
+	known := append([]string{advisory}, required...)
+	if reason := misspelled(path, known); reason != "" {
+		return reason
+	}
+	if stripMajorVersionSuffix(path) == stripMajorVersionSuffix(advisory) || strings.HasPrefix(advisory, path+"/") {
+		return ""
+	}
+	if reason := resembles(path, known); reason != "" {
+		return reason
+	}
+	return fmt.Sprintf("differs from the advisory's module %s", advisory)
+}
+
+// LookalikePath returns why the module at path looks like a lookalike of
+// one of the known modules, or "" if it does not. Unlike SuspiciousPath, a
+// path resembling none of them is not suspicious, as updates routinely add
+// new dependencies of the updated modules. Nor is a path resembling a
+// module of the same owner, such as golang.org/x/sync and golang.org/x/sys.
+func LookalikePath(path string, known []string) string {
+	if slices.Contains(known, path) {
+		return ""
+	}
+	if reason := misspelled(path, known); reason != "" {
+		return reason
+	}
+	others := slices.DeleteFunc(slices.Clone(known), func(k string) bool { return sameOwner(k, path) })
+	return resembles(path, others)
+}
+
+// misspelled returns why path is a known module spelled differently, or ""
+func misspelled(path string, known []string) string {
+	for _, k := range known {
+		if k != path && strings.EqualFold(k, path) {
+			return fmt.Sprintf("differs only in case from %s", k)
+		}
+	}
+	for _, c := range path {
+		if c > unicode.MaxASCII {
+			return "contains non-ASCII characters"
+		}
+	}
+	return ""
+}
+
+// resembles returns which known module path looks like, or ""
+func resembles(path string, known []string) string {
+	for _, k := range known {
+		if d := editDistance(k, path); d <= lookalikeDistance {
+			return fmt.Sprintf("looks like %s (%d character(s) apart)", k, d)
+		}
+	}
+	for _, k := range known {
+		if sameNameOtherOwner(k, path) {
+			return fmt.Sprintf("has the name of %s under another owner", k)
+		}
+	}
+	return ""
+}
+
+// sameNameOtherOwner reports whether the paths share their host and
+// repository name but not their owner, e.g. github.com/a/lib and
+// github.com/b/lib
+func sameNameOtherOwner(a, b string) bool {
+	pa := strings.Split(stripMajorVersionSuffix(a), "/")
+	pb := strings.Split(stripMajorVersionSuffix(b), "/")
+	if len(pa) < 3 || len(pb) < 3 {
+		return false
+	}
+	return pa[0] == pb[0] && pa[1] != pb[1] && strings.Join(pa[2:], "/") == strings.Join(pb[2:], "/")
+}
+
+// sameOwner reports whether the paths share their host and owner, e.g.
+// golang.org/x/sync and golang.org/x/sys
+func sameOwner(a, b string) bool {
+	pa := strings.Split(a, "/")
+	pb := strings.Split(b, "/")
+	return len(pa) >= 3 && len(pb) >= 3 && pa[0] == pb[0] && pa[1] == pb[1]
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,74 @@
+package gomod
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSuspiciousPath(t *testing.T) {
+	required := []string{"github.com/sirupsen/logrus", "golang.org/x/net", "github.com/spf13/cobra"}
+
+	tests := []struct {
+		name     string
+		path     string
+		advisory string
+		want     string
+	}{
+		{"advisory module", "golang.org/x/net", "golang.org/x/net", ""},
+		{"required direct dependency", "github.com/spf13/cobra", "golang.org/x/net", ""},
+		{"advisory not required yet", "golang.org/x/crypto", "golang.org/x/crypto", ""},
+		{"major version line", "github.com/foo/bar/v2", "github.com/foo/bar", ""},
+		{"case variant", "github.com/Sirupsen/logrus", "github.com/Sirupsen/logrus", "differs only in case from github.com/sirupsen/logrus"},
+		{"homoglyph", "golang.org/x/nеt", "golang.org/x/net", "non-ASCII"},
+		{"typo", "github.com/sirupsen/logrsu", "github.com/sirupsen/logrus", "looks like github.com/sirupsen/logrus (2 character(s) apart)"},
+		{"other owner", "github.com/evil/cobra", "golang.org/x/net", "has the name of github.com/spf13/cobra under another owner"},
+		{"module of the advisory's package", "golang.org/x/crypto", "golang.org/x/crypto/ssh", ""},
+		{"rewritten import", "example.com/net", "golang.org/x/net", "differs from the advisory's module golang.org/x/net"},
+	}
+	for _, tt := range tests {
+		got := SuspiciousPath(tt.path, tt.advisory, required)
+		if (tt.want == "") != (got == "") || !strings.Contains(got, tt.want) {
+			t.Errorf("%s: SuspiciousPath(%q, %q) = %q, want %q", tt.name, tt.path, tt.advisory, got, tt.want)
+		}
+	}
+}
+
+func TestLookalikePath(t *testing.T) {
+	known := []string{"golang.org/x/net", "github.com/sirupsen/logrus", "github.com/spf13/cobra"}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"golang.org/x/net", ""},
+		{"github.com/google/uuid", ""},
+		{"golang.org/x/text", ""},
+		{"github.com/Sirupsen/logrus", "differs only in case from github.com/sirupsen/logrus"},
+		{"github.com/slrupsen/logrus", "looks like github.com/sirupsen/logrus"},
+		{"github.com/evil/cobra", "has the name of github.com/spf13/cobra under another owner"},
+	}
+	for _, tt := range tests {
+		got := LookalikePath(tt.path, known)
+		if (tt.want == "") != (got == "") || !strings.Contains(got, tt.want) {
+			t.Errorf("LookalikePath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "abc", 0},
+		{"abc", "abd", 1},
+		{"logrus", "logrsu", 2},
+		{"kitten", "sitting", 3},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package gomod
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/tamcore/go-autobump/internal/runner"
+)
+
+// OutdatedModule is a direct dependency with a newer release
+type OutdatedModule struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+	// Latest is the newest release of the same major version
+	Latest string `json:"latest"`
+	// Time and LatestTime are the release times of Version and Latest, if
+	// the module proxy reports them
+	Time       *time.Time `json:"time,omitempty"`
+	LatestTime *time.Time `json:"latest_time,omitempty"`
+}
+
+// listedModule is the subset of "go list -m -u -json" output Outdated uses
+type listedModule struct {
+	Path     string
+	Version  string
+	Time     *time.Time
+	Main     bool
+	Indirect bool
+	Update   *struct {
+		Version string
+		Time    *time.Time
+	}
+	Replace *struct {
+		Version string
+	}
+}
+
+// Outdated runs "go list -m -u all" in moduleDir and returns the direct
+// dependencies that are behind their latest release. Dependencies replaced
+// by a local directory are skipped, as their requirement is not used.
+func Outdated(moduleDir string) ([]OutdatedModule, error) {
+	stdout, stderr, err := runner.Run(moduleDir, runner.Go, "list", "-m", "-u", "-json", "all")
+	if err != nil {
+		return nil, fmt.Errorf("go list -m -u failed: %w\nstderr: %s", goError(err, stderr), stderr)
+	}
+	return parseOutdated(stdout)
+}
+
+// parseOutdated reads the stream of JSON objects "go list -m -u -json" prints
+func parseOutdated(data []byte) ([]OutdatedModule, error) {
+	var outdated []OutdatedModule
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var m listedModule
+		if err := dec.Decode(&m); errors.Is(err, io.EOF) {
+			return outdated, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to parse go list output: %w", err)
+		}
+
+		if m.Main || m.Indirect || m.Update == nil || (m.Replace != nil && m.Replace.Version == "") {
+			continue
+		}
+		outdated = append(outdated, OutdatedModule{
+			Path:       m.Path,
+			Version:    m.Version,
+			Latest:     m.Update.Version,
+			Time:       m.Time,
+			LatestTime: m.Update.Time,
+		})
+	}
+}
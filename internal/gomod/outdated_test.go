@@ -0,0 +1,55 @@
+package gomod
+
+import "testing"
+
+func TestParseOutdated(t *testing.T) {
+	data := []byte(`{
+	"Path": "example.com/app",
+	"Main": true
+}
+{
+	"Path": "example.com/current",
+	"Version": "v1.4.0"
+}
+{
+	"Path": "example.com/stale",
+	"Version": "v1.2.0",
+	"Time": "2024-01-02T00:00:00Z",
+	"Update": {"Path": "example.com/stale", "Version": "v1.5.1", "Time": "2025-03-04T00:00:00Z"}
+}
+{
+	"Path": "example.com/transitive",
+	"Version": "v0.1.0",
+	"Indirect": true,
+	"Update": {"Path": "example.com/transitive", "Version": "v0.2.0"}
+}
+{
+	"Path": "example.com/local",
+	"Version": "v1.0.0",
+	"Update": {"Path": "example.com/local", "Version": "v1.1.0"},
+	"Replace": {"Path": "../local"}
+}
+{
+	"Path": "example.com/forked",
+	"Version": "v1.0.0",
+	"Update": {"Path": "example.com/forked", "Version": "v1.1.0"},
+	"Replace": {"Path": "example.com/fork", "Version": "v1.0.1"}
+}
+`)
+
+	got, err := parseOutdated(data)
+	if err != nil {
+		t.Fatalf("parseOutdated() error = %v", err)
+	}
+	if len(got) != 2 || got[0].Path != "example.com/stale" || got[1].Path != "example.com/forked" {
+		t.Fatalf("parseOutdated() = %+v, want stale and forked", got)
+	}
+	stale := got[0]
+	if stale.Version != "v1.2.0" || stale.Latest != "v1.5.1" || stale.LatestTime == nil || stale.LatestTime.Year() != 2025 {
+		t.Errorf("stale = %+v", stale)
+	}
+
+	if _, err := parseOutdated([]byte(`{"Path": `)); err == nil {
+		t.Error("parseOutdated() of truncated output succeeded, want error")
+	}
+}
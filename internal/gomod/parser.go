@@ -1,14 +1,16 @@
 package gomod
 
 import (
-	"bytes"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 
+	"github.com/tamcore/go-autobump/internal/runner"
 	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
 )
 
 // Parser handles go.mod file parsing and manipulation
@@ -43,6 +45,18 @@ func (p *Parser) ModulePath() string {
 	return ""
 }
 
+// Toolchain returns the Go version the module builds with at least, e.g.
+// "1.22.5": that of its toolchain directive, or else of its go directive
+func (p *Parser) Toolchain() string {
+	if p.ModFile.Toolchain != nil && p.ModFile.Toolchain.Name != "default" {
+		return strings.TrimPrefix(p.ModFile.Toolchain.Name, "go")
+	}
+	if p.ModFile.Go != nil {
+		return p.ModFile.Go.Version
+	}
+	return ""
+}
+
 // IsDirectDependency checks if a package is a direct dependency
 func (p *Parser) IsDirectDependency(pkgPath string) bool {
 	for _, req := range p.ModFile.Require {
@@ -63,6 +77,17 @@ func (p *Parser) GetVersion(pkgPath string) string {
 	return ""
 }
 
+// Replacement returns the target of a replace directive for pkgPath, e.g.
+// "../fork" or "github.com/fork/pkg v1.2.3", and whether one exists
+func (p *Parser) Replacement(pkgPath string) (string, bool) {
+	for _, rep := range p.ModFile.Replace {
+		if rep.Old.Path == pkgPath {
+			return strings.TrimSpace(rep.New.Path + " " + rep.New.Version), true
+		}
+	}
+	return "", false
+}
+
 // GetDirectDependencies returns all direct dependencies
 func (p *Parser) GetDirectDependencies() []Dependency {
 	var deps []Dependency
@@ -97,39 +122,16 @@ type Dependency struct {
 	Version string
 }
 
-// ModWhy runs "go mod why -m" to find why a module is needed
-// Returns the import chain explaining why the module is required
-func ModWhy(moduleDir, pkgPath string) (string, error) {
-	cmd := exec.Command("go", "mod", "why", "-m", pkgPath)
-	cmd.Dir = moduleDir
-
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	if err := cmd.Run(); err != nil {
-		return "", fmt.Errorf("go mod why failed: %v\nstderr: %s", err, stderr.String())
-	}
-
-	return stdout.String(), nil
-}
-
 // ModGraph runs "go mod graph" and returns the dependency graph
 // Each line is "module@version dependency@version"
 func ModGraph(moduleDir string) ([]GraphEdge, error) {
-	cmd := exec.Command("go", "mod", "graph")
-	cmd.Dir = moduleDir
-
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("go mod graph failed: %v\nstderr: %s", err, stderr.String())
+	stdout, stderr, err := runner.Run(moduleDir, runner.Go, "mod", "graph")
+	if err != nil {
+		return nil, fmt.Errorf("go mod graph failed: %w\nstderr: %s", err, stderr)
 	}
 
 	var edges []GraphEdge
-	for _, line := range strings.Split(stdout.String(), "\n") {
+	for _, line := range strings.Split(string(stdout), "\n") {
 		line = strings.TrimSpace(line)
 		if line == "" {
 			continue
@@ -178,6 +180,11 @@ func FindDirectDependencyFor(moduleDir, indirectPkg string) ([]string, error) {
 		return nil, err
 	}
 
+	return parseWhyDirectDeps(whyOutput), nil
+}
+
+// parseWhyDirectDeps extracts the direct dependency from "go mod why -m" output
+func parseWhyDirectDeps(whyOutput string) []string {
 	// Parse the output to find direct dependencies in the chain
 	// Format:
 	// # github.com/indirect/pkg
@@ -207,19 +214,24 @@ func FindDirectDependencyFor(moduleDir, indirectPkg string) ([]string, error) {
 		break
 	}
 
-	return directDeps, nil
+	return directDeps
 }
 
 // ModTidy runs "go mod tidy" in the module directory
 func ModTidy(moduleDir string) error {
-	cmd := exec.Command("go", "mod", "tidy")
-	cmd.Dir = moduleDir
+	defer InvalidateWhy(moduleDir)
+	if _, stderr, err := runner.Run(moduleDir, runner.Go, "mod", "tidy"); err != nil {
+		return fmt.Errorf("go mod tidy failed: %w\nstderr: %s", goError(err, stderr), stderr)
+	}
 
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
+	return nil
+}
 
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("go mod tidy failed: %v\nstderr: %s", err, stderr.String())
+// Download downloads the dependencies of the module in moduleDir to the
+// module cache
+func Download(moduleDir string) error {
+	if _, stderr, err := runner.Run(moduleDir, runner.Go, "mod", "download"); err != nil {
+		return fmt.Errorf("go mod download failed: %w\nstderr: %s", err, stderr)
 	}
 
 	return nil
@@ -236,30 +248,51 @@ func GoGet(moduleDir, pkgPath, version string) error {
 	version = NormalizeVersion(version)
 
 	target := pkgPath + "@" + version
-	cmd := exec.Command("go", "get", target)
-	cmd.Dir = moduleDir
+	defer InvalidateWhy(moduleDir)
+	if _, stderr, err := runner.Run(moduleDir, runner.Go, "get", target); err != nil {
+		return fmt.Errorf("go get %s failed: %w\nstderr: %s", target, goError(err, stderr), stderr)
+	}
+
+	return nil
+}
 
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
+// GoGetAll updates several dependencies with a single go get, so that they
+// are resolved together, e.g. the members of a lockstep group
+func GoGetAll(moduleDir string, versions map[string]string) error {
+	targets := make([]string, 0, len(versions))
+	for _, pkgPath := range sortedTargets(versions) {
+		targets = append(targets, pkgPath+"@"+NormalizeVersion(versions[pkgPath]))
+	}
 
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("go get %s failed: %v\nstderr: %s", target, err, stderr.String())
+	defer InvalidateWhy(moduleDir)
+	args := append([]string{"get"}, targets...)
+	if _, stderr, err := runner.Run(moduleDir, runner.Go, args...); err != nil {
+		return fmt.Errorf("go get %s failed: %w\nstderr: %s", strings.Join(targets, " "), goError(err, stderr), stderr)
 	}
 
 	return nil
 }
 
 // IsMajorVersionBump checks if updating from oldVersion to newVersion is a major version bump
-// This includes cases where the module path would need to change (e.g., /v2)
+// This includes cases where the module path would need to change (e.g., /v2).
+// Build metadata such as +incompatible is ignored, and a pseudo-version of a
+// commit before the first release (v0.0.0-...) may move to v1 without a bump.
 func IsMajorVersionBump(oldVersion, newVersion string) bool {
 	oldMajor := extractMajor(oldVersion)
 	newMajor := extractMajor(newVersion)
 
+	if oldMajor == 0 && newMajor == 1 && isUntaggedPseudoVersion(NormalizeVersion(oldVersion)) {
+		return false
+	}
 	return newMajor > oldMajor
 }
 
 // extractMajor extracts the major version number from a semver string
 func extractMajor(version string) int {
+	if v := NormalizeVersion(version); semver.IsValid(v) {
+		version = semver.Major(v)
+	}
+
 	// Strip v prefix
 	version = strings.TrimPrefix(version, "v")
 
@@ -273,10 +306,23 @@ func extractMajor(version string) int {
 	return major
 }
 
+// isUntaggedPseudoVersion reports whether version is a pseudo-version of a
+// commit with no release tag before it, e.g. v0.0.0-20230101000000-abcdef123456
+func isUntaggedPseudoVersion(version string) bool {
+	if !module.IsPseudoVersion(version) {
+		return false
+	}
+	base, err := module.PseudoVersionBase(version)
+	return err == nil && base == ""
+}
+
 // NormalizeVersion ensures the version string has the proper format for Go modules.
 // It adds a 'v' prefix if missing and the version looks like semver (e.g., "1.2.3" -> "v1.2.3").
-// Special versions like "latest" are returned unchanged.
+// Pseudo-versions and +incompatible versions are kept, and special versions like
+// "latest" are returned unchanged.
 func NormalizeVersion(version string) string {
+	version = strings.TrimSpace(version)
+
 	// Don't modify special versions
 	if version == "latest" || version == "" {
 		return version
@@ -295,6 +341,20 @@ func NormalizeVersion(version string) string {
 	return version
 }
 
+// MatchIncompatible returns version normalized for a module installed at
+// installed. If installed is a +incompatible version, i.e. of a v2+ module
+// without a go.mod declaring its major version, its versions from v2 on only
+// exist with that suffix, so version gets it too (e.g. "2.1.0" ->
+// "v2.1.0+incompatible").
+func MatchIncompatible(installed, version string) string {
+	version = NormalizeVersion(version)
+	if !strings.HasSuffix(NormalizeVersion(installed), "+incompatible") ||
+		!semver.IsValid(version) || semver.Build(version) != "" || extractMajor(version) < 2 {
+		return version
+	}
+	return version + "+incompatible"
+}
+
 // HasMajorVersionModule checks if the go.mod already has a major version variant of the module.
 // For example, if vulnPkg is "github.com/foo/bar" (v1) and fixedVersion is "2.0.0",
 // this checks if "github.com/foo/bar/v2" exists in go.mod.
@@ -330,6 +390,46 @@ func (p *Parser) HasMajorVersionModule(vulnPkg, fixedVersion string) (bool, stri
 	return hasMajorVersion, majorVersionVersion, vulnModuleStillPresent
 }
 
+// PathMajor returns the major version encoded in a module path: N for a
+// /vN suffix, 1 for paths without one (which hold v0 and v1)
+func PathMajor(path string) int {
+	if base := stripMajorVersionSuffix(path); base != path {
+		return extractMajor(path[len(base)+1:])
+	}
+	return 1
+}
+
+// VersionMajor returns the major version of a semver version, counting v0 as 1
+// since v0 and v1 share a module path
+func VersionMajor(version string) int {
+	return max(extractMajor(version), 1)
+}
+
+// MajorVariantPath returns the path of the given major version line of the
+// module at path, e.g. github.com/foo/bar/v2 for github.com/foo/bar and 2
+func MajorVariantPath(path string, major int) string {
+	base := stripMajorVersionSuffix(path)
+	if major < 2 {
+		return base
+	}
+	return fmt.Sprintf("%s/v%d", base, major)
+}
+
+// MajorVariants returns the requirements that are major version lines of the
+// module at path, including path itself if required, ordered by major version
+func (p *Parser) MajorVariants(path string) []Dependency {
+	base := stripMajorVersionSuffix(path)
+
+	var variants []Dependency
+	for _, req := range p.ModFile.Require {
+		if stripMajorVersionSuffix(req.Mod.Path) == base {
+			variants = append(variants, Dependency{Path: req.Mod.Path, Version: req.Mod.Version})
+		}
+	}
+	sort.Slice(variants, func(i, j int) bool { return PathMajor(variants[i].Path) < PathMajor(variants[j].Path) })
+	return variants
+}
+
 // stripMajorVersionSuffix removes /v2, /v3, etc. from a module path
 func stripMajorVersionSuffix(path string) string {
 	// Check for /vN suffix where N >= 2
@@ -339,7 +439,7 @@ func stripMajorVersionSuffix(path string) string {
 	}
 
 	suffix := path[lastSlash+1:]
-	if len(suffix) >= 2 && suffix[0] == 'v' && suffix[1] >= '2' && suffix[1] <= '9' {
+	if len(suffix) >= 2 && suffix != "v1" && suffix[0] == 'v' && suffix[1] >= '1' && suffix[1] <= '9' {
 		// Verify it's just digits after 'v'
 		allDigits := true
 		for _, c := range suffix[1:] {
@@ -1,6 +1,10 @@
 package gomod
 
-import "testing"
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
 
 func TestNormalizeVersion(t *testing.T) {
 	tests := []struct {
@@ -25,6 +29,12 @@ func TestNormalizeVersion(t *testing.T) {
 		// Edge cases
 		{"v1.0.0-alpha", "v1.0.0-alpha"},
 		{"1.0.0-beta.1", "v1.0.0-beta.1"},
+		{" 1.2.3 ", "v1.2.3"},
+
+		// Pseudo-versions and +incompatible versions
+		{"0.0.0-20230101000000-abcdef123456", "v0.0.0-20230101000000-abcdef123456"},
+		{"v1.2.4-0.20230101000000-abcdef123456", "v1.2.4-0.20230101000000-abcdef123456"},
+		{"2.0.0+incompatible", "v2.0.0+incompatible"},
 	}
 
 	for _, tt := range tests {
@@ -49,6 +59,18 @@ func TestIsMajorVersionBump(t *testing.T) {
 		{"1.2.5", "2.0.3", true},
 		{"v0.1.0", "v1.0.0", true},
 		{"v2.0.0", "v2.1.0", false},
+
+		// +incompatible versions
+		{"v2.0.0+incompatible", "2.1.0", false},
+		{"v2.0.0+incompatible", "v3.0.0+incompatible", true},
+		{"v1.5.0", "v2.0.0+incompatible", true},
+
+		// Pseudo-versions
+		{"v0.0.0-20230101000000-abcdef123456", "v1.0.0", false},
+		{"v0.0.0-20230101000000-abcdef123456", "v2.0.0", true},
+		{"v0.1.1-0.20230101000000-abcdef123456", "v1.0.0", true},
+		{"v1.2.4-0.20230101000000-abcdef123456", "1.2.4", false},
+		{"v1.2.4-0.20230101000000-abcdef123456", "v2.0.0", true},
 	}
 
 	for _, tt := range tests {
@@ -61,3 +83,93 @@ func TestIsMajorVersionBump(t *testing.T) {
 		})
 	}
 }
+
+func TestMatchIncompatible(t *testing.T) {
+	tests := []struct {
+		installed string
+		version   string
+		expected  string
+	}{
+		{"v2.0.0+incompatible", "2.1.0", "v2.1.0+incompatible"},
+		{"v2.0.0+incompatible", "v3.0.0+incompatible", "v3.0.0+incompatible"},
+		{"v2.0.1-0.20230101000000-abcdef123456+incompatible", "2.1.0", "v2.1.0+incompatible"},
+		{"v1.5.0", "2.0.0", "v2.0.0"},
+		{"v2.0.0", "2.1.0", "v2.1.0"},
+		{"v2.0.0+incompatible", "latest", "latest"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.installed+"->"+tt.version, func(t *testing.T) {
+			if got := MatchIncompatible(tt.installed, tt.version); got != tt.expected {
+				t.Errorf("MatchIncompatible(%q, %q) = %q, want %q", tt.installed, tt.version, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMajorVariants(t *testing.T) {
+	goModPath := filepath.Join(t.TempDir(), "go.mod")
+	goMod := "module example.com/app\n\ngo 1.22\n\nrequire (\n\texample.com/dep/v3 v3.1.0\n\texample.com/dep v1.4.0\n\texample.com/dep/v2 v2.0.1\n\texample.com/other v1.0.0\n)\n"
+	if err := os.WriteFile(goModPath, []byte(goMod), 0644); err != nil {
+		t.Fatal(err)
+	}
+	parser, err := NewParser(goModPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	variants := parser.MajorVariants("example.com/dep/v2")
+	want := []string{"example.com/dep", "example.com/dep/v2", "example.com/dep/v3"}
+	if len(variants) != len(want) {
+		t.Fatalf("MajorVariants() = %v, want %v", variants, want)
+	}
+	for i, v := range variants {
+		if v.Path != want[i] {
+			t.Errorf("MajorVariants()[%d] = %s, want %s", i, v.Path, want[i])
+		}
+	}
+
+	pathTests := []struct {
+		path    string
+		major   int
+		variant string
+	}{
+		{"example.com/dep", 1, "example.com/dep/v3"},
+		{"example.com/dep/v2", 2, "example.com/dep/v3"},
+		{"example.com/dep/v12", 12, "example.com/dep/v3"},
+		{"example.com/v2tools", 1, "example.com/v2tools/v3"},
+	}
+	for _, tt := range pathTests {
+		if got := PathMajor(tt.path); got != tt.major {
+			t.Errorf("PathMajor(%q) = %d, want %d", tt.path, got, tt.major)
+		}
+		if got := MajorVariantPath(tt.path, 3); got != tt.variant {
+			t.Errorf("MajorVariantPath(%q, 3) = %s, want %s", tt.path, got, tt.variant)
+		}
+	}
+	if got := MajorVariantPath("example.com/dep/v2", 1); got != "example.com/dep" {
+		t.Errorf("MajorVariantPath(v2, 1) = %s, want example.com/dep", got)
+	}
+}
+
+func TestToolchain(t *testing.T) {
+	tests := map[string]string{
+		"module example.com/app\n\ngo 1.22.3\n":                     "1.22.3",
+		"module example.com/app\n\ngo 1.22\n\ntoolchain go1.22.9\n": "1.22.9",
+		"module example.com/app\n\ngo 1.21\n\ntoolchain default\n":  "1.21",
+		"module example.com/app\n":                                  "",
+	}
+	for goMod, want := range tests {
+		goModPath := filepath.Join(t.TempDir(), "go.mod")
+		if err := os.WriteFile(goModPath, []byte(goMod), 0644); err != nil {
+			t.Fatal(err)
+		}
+		parser, err := NewParser(goModPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := parser.Toolchain(); got != want {
+			t.Errorf("Toolchain() of %q = %q, want %q", goMod, got, want)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package gomod
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Supported graph output formats
+const (
+	FormatDOT     = "dot"
+	FormatMermaid = "mermaid"
+)
+
+// RenderGraph renders the module graph in the given format. Modules are
+// collapsed to their paths so each appears once regardless of how many
+// versions the graph mentions. Highlighted modules are drawn in red.
+func RenderGraph(edges []GraphEdge, format string, highlight map[string]bool) (string, error) {
+	nodes, links := collapseGraph(edges)
+
+	switch format {
+	case FormatDOT:
+		return renderDOT(nodes, links, highlight), nil
+	case FormatMermaid:
+		return renderMermaid(nodes, links, highlight), nil
+	default:
+		return "", fmt.Errorf("unsupported graph format %q (valid: dot, mermaid)", format)
+	}
+}
+
+// graphLink is an edge between two module paths
+type graphLink struct {
+	From string
+	To   string
+}
+
+// collapseGraph deduplicates nodes and edges by module path, in stable order
+func collapseGraph(edges []GraphEdge) ([]string, []graphLink) {
+	nodeSet := make(map[string]bool)
+	linkSet := make(map[graphLink]bool)
+	for _, edge := range edges {
+		nodeSet[edge.From.Path] = true
+		nodeSet[edge.To.Path] = true
+		linkSet[graphLink{From: edge.From.Path, To: edge.To.Path}] = true
+	}
+
+	nodes := make([]string, 0, len(nodeSet))
+	for node := range nodeSet {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+
+	links := make([]graphLink, 0, len(linkSet))
+	for link := range linkSet {
+		links = append(links, link)
+	}
+	sort.Slice(links, func(i, j int) bool {
+		if links[i].From != links[j].From {
+			return links[i].From < links[j].From
+		}
+		return links[i].To < links[j].To
+	})
+
+	return nodes, links
+}
+
+func renderDOT(nodes []string, links []graphLink, highlight map[string]bool) string {
+	var b strings.Builder
+	b.WriteString("digraph modules {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box];\n")
+
+	for _, node := range nodes {
+		if highlight[node] {
+			fmt.Fprintf(&b, "  %q [color=red, fontcolor=red];\n", node)
+		}
+	}
+	for _, link := range links {
+		fmt.Fprintf(&b, "  %q -> %q;\n", link.From, link.To)
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func renderMermaid(nodes []string, links []graphLink, highlight map[string]bool) string {
+	// Mermaid node IDs cannot contain most punctuation, so use short IDs with labels
+	ids := make(map[string]string, len(nodes))
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+
+	for i, node := range nodes {
+		id := fmt.Sprintf("n%d", i)
+		ids[node] = id
+		fmt.Fprintf(&b, "  %s[\"%s\"]\n", id, node)
+	}
+	for _, link := range links {
+		fmt.Fprintf(&b, "  %s --> %s\n", ids[link.From], ids[link.To])
+	}
+
+	var highlighted []string
+	for _, node := range nodes {
+		if highlight[node] {
+			highlighted = append(highlighted, ids[node])
+		}
+	}
+	if len(highlighted) > 0 {
+		b.WriteString("  classDef highlight stroke:#d00,color:#d00\n")
+		fmt.Fprintf(&b, "  class %s highlight\n", strings.Join(highlighted, ","))
+	}
+
+	return b.String()
+}
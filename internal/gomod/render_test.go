@@ -0,0 +1,50 @@
+package gomod
+
+import "testing"
+
+func TestRenderGraph(t *testing.T) {
+	edges := []GraphEdge{
+		edge("example.com/app", "golang.org/x/net@v0.1.0"),
+		edge("example.com/app", "github.com/a/one@v1.0.0"),
+		edge("github.com/a/one@v1.0.0", "golang.org/x/net@v0.0.9"),
+	}
+	highlight := map[string]bool{"golang.org/x/net": true}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{FormatDOT, `digraph modules {
+  rankdir=LR;
+  node [shape=box];
+  "golang.org/x/net" [color=red, fontcolor=red];
+  "example.com/app" -> "github.com/a/one";
+  "example.com/app" -> "golang.org/x/net";
+  "github.com/a/one" -> "golang.org/x/net";
+}
+`},
+		{FormatMermaid, `graph LR
+  n0["example.com/app"]
+  n1["github.com/a/one"]
+  n2["golang.org/x/net"]
+  n0 --> n1
+  n0 --> n2
+  n1 --> n2
+  classDef highlight stroke:#d00,color:#d00
+  class n2 highlight
+`},
+	}
+	for _, tt := range tests {
+		got, err := RenderGraph(edges, tt.format, highlight)
+		if err != nil {
+			t.Fatalf("RenderGraph(%s) error = %v", tt.format, err)
+		}
+		if got != tt.want {
+			t.Errorf("RenderGraph(%s) =\n%s\nwant\n%s", tt.format, got, tt.want)
+		}
+	}
+
+	if _, err := RenderGraph(edges, "svg", nil); err == nil {
+		t.Error("RenderGraph(svg) succeeded")
+	}
+}
@@ -0,0 +1,54 @@
+package gomod
+
+import (
+	"fmt"
+
+	"github.com/tamcore/go-autobump/internal/runner"
+)
+
+// ResolveVersion resolves a version query of a module, such as a commit
+// hash or branch, to its version or pseudo-version
+func ResolveVersion(moduleDir, modulePath, query string) (string, error) {
+	info, err := listModule(moduleDir, modulePath+"@"+query)
+	if err != nil {
+		return "", err
+	}
+	return info.Version, nil
+}
+
+// SetToolchain sets the toolchain directive to the Go version, e.g. "1.22.9"
+func (s *Session) SetToolchain(version string) error {
+	defer s.Invalidate()
+
+	toolchain := "-toolchain=go" + version
+	if _, stderr, err := runner.Run(s.Dir, runner.Go, "mod", "edit", toolchain); err != nil {
+		return fmt.Errorf("go mod edit %s failed: %w\nstderr: %s", toolchain, err, stderr)
+	}
+	return nil
+}
+
+// DropReplace removes the replace directive of every version of oldPath
+func (s *Session) DropReplace(oldPath string) error {
+	defer s.Invalidate()
+
+	drop := "-dropreplace=" + oldPath
+	if _, stderr, err := runner.Run(s.Dir, runner.Go, "mod", "edit", drop); err != nil {
+		return fmt.Errorf("go mod edit %s failed: %w\nstderr: %s", drop, err, stderr)
+	}
+	return nil
+}
+
+// Replace replaces every version of oldPath by newPath at version and
+// records the checksums of the replacement in go.sum
+func (s *Session) Replace(oldPath, newPath, version string) error {
+	defer s.Invalidate()
+
+	replace := fmt.Sprintf("-replace=%s=%s@%s", oldPath, newPath, version)
+	if _, stderr, err := runner.Run(s.Dir, runner.Go, "mod", "edit", replace); err != nil {
+		return fmt.Errorf("go mod edit %s failed: %w\nstderr: %s", replace, err, stderr)
+	}
+	if _, stderr, err := runner.Run(s.Dir, runner.Go, "mod", "download", oldPath); err != nil {
+		return fmt.Errorf("go mod download %s failed: %w\nstderr: %s", oldPath, goError(err, stderr), stderr)
+	}
+	return nil
+}
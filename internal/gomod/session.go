@@ -0,0 +1,219 @@
+package gomod
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Session caches the parsed go.mod and module graph for a single module
+// during a run; "go mod why" results are cached by ModWhy itself. Operations
+// that mutate go.mod or go.sum through the session invalidate the cache
+// automatically; callers that modify the module by other means must call
+// Invalidate themselves.
+type Session struct {
+	GoModPath string
+	Dir       string
+
+	// Policy, if set, is consulted before every GoGet
+	Policy VersionPolicy
+
+	// Lockstep groups are updated together: GoGet of a member also updates
+	// the other members required at the same version
+	Lockstep []LockstepGroup
+
+	// Compatibility rules, if set, are checked after every GoGet, which is
+	// undone if it breaks a rule that held before
+	Compatibility []CompatibilityRule
+
+	parser *Parser
+	graph  *Graph
+}
+
+// VersionPolicy decides whether a module may be updated to a version, e.g.
+// based on the repository's dependency bot configuration
+type VersionPolicy interface {
+	// Check returns an error if module must not be updated from current to version
+	Check(module, current, version string) error
+}
+
+// Policies combines version policies: an update must pass each of them
+type Policies []VersionPolicy
+
+// Check returns the error of the first policy refusing the update
+func (p Policies) Check(module, current, version string) error {
+	for _, policy := range p {
+		if err := policy.Check(module, current, version); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewSession creates a new Session for the given go.mod file path
+func NewSession(goModPath string) *Session {
+	return &Session{
+		GoModPath: goModPath,
+		Dir:       GetModuleDir(goModPath),
+	}
+}
+
+// Parser returns the parsed go.mod, reading it on first use
+func (s *Session) Parser() (*Parser, error) {
+	if s.parser != nil {
+		return s.parser, nil
+	}
+
+	parser, err := NewParser(s.GoModPath)
+	if err != nil {
+		return nil, err
+	}
+	s.parser = parser
+	return parser, nil
+}
+
+// Graph returns the module dependency graph, computing it on first use
+func (s *Session) Graph() (*Graph, error) {
+	if s.graph != nil {
+		return s.graph, nil
+	}
+
+	edges, err := ModGraph(s.Dir)
+	if err != nil {
+		return nil, err
+	}
+	s.graph = NewGraph(edges)
+	return s.graph, nil
+}
+
+// Why returns the "go mod why -m" output for pkgPath, running it on first use
+func (s *Session) Why(pkgPath string) (string, error) {
+	return ModWhy(s.Dir, pkgPath)
+}
+
+// Unneeded reports whether "go mod why -m" finds that the main module does
+// not need pkgPath at all, so go mod tidy would remove it
+func (s *Session) Unneeded(pkgPath string) bool {
+	why, err := s.Why(pkgPath)
+	return err == nil && strings.Contains(why, "does not need")
+}
+
+// FindDirectDependencyFor finds which direct dependency imports the given indirect package
+func (s *Session) FindDirectDependencyFor(indirectPkg string) ([]string, error) {
+	whyOutput, err := s.Why(indirectPkg)
+	if err != nil {
+		return nil, err
+	}
+	return parseWhyDirectDeps(whyOutput), nil
+}
+
+// Invalidate drops all cached state so it is recomputed on next access
+func (s *Session) Invalidate() {
+	s.parser = nil
+	s.graph = nil
+	InvalidateWhy(s.Dir)
+}
+
+// GoGet updates a dependency to a specific version and invalidates the cache.
+// The members of its lockstep groups are updated in the same go get. Updates
+// forbidden by the session's policy are refused.
+func (s *Session) GoGet(pkgPath, version string) error {
+	if err := s.CheckPolicy(pkgPath, version); err != nil {
+		return err
+	}
+
+	var members map[string]string
+	if len(s.Lockstep) > 0 {
+		if parser, err := s.Parser(); err == nil {
+			members = lockstepTargets(s.Lockstep, parser, pkgPath, version)
+		}
+	}
+	for member, memberVersion := range members {
+		if err := s.CheckPolicy(member, memberVersion); err != nil {
+			return fmt.Errorf("lockstep member %s: %w", member, err)
+		}
+	}
+
+	var before []Incompatibility
+	var snap *Snapshot
+	if len(s.Compatibility) > 0 {
+		if parser, err := s.Parser(); err == nil {
+			before = CheckCompatibility(s.Compatibility, parser)
+			snap, _ = s.Snapshot()
+		}
+	}
+
+	defer s.Invalidate()
+	var err error
+	if len(members) == 0 {
+		err = GoGet(s.Dir, pkgPath, version)
+	} else {
+		members[pkgPath] = version
+		err = GoGetAll(s.Dir, members)
+	}
+	if err != nil || snap == nil {
+		return err
+	}
+	return s.checkCompatibility(snap, before, pkgPath, version)
+}
+
+// checkCompatibility restores snap if the module now breaks compatibility
+// rules that held before, and returns an *IncompatibleError then
+func (s *Session) checkCompatibility(snap *Snapshot, before []Incompatibility, pkgPath, version string) error {
+	s.Invalidate()
+	parser, err := s.Parser()
+	if err != nil {
+		return nil
+	}
+
+	broken := newIncompatibilities(before, CheckCompatibility(s.Compatibility, parser))
+	if len(broken) == 0 {
+		return nil
+	}
+	if err := snap.Restore(); err != nil {
+		return fmt.Errorf("failed to undo incompatible update: %w", err)
+	}
+	return &IncompatibleError{Module: pkgPath, Version: version, Broken: broken}
+}
+
+// PolicyError reports an update refused by the session's VersionPolicy
+type PolicyError struct {
+	Err error
+}
+
+func (e *PolicyError) Error() string { return e.Err.Error() }
+
+func (e *PolicyError) Unwrap() error { return e.Err }
+
+// CheckPolicy returns a *PolicyError if the session's policy forbids
+// updating pkgPath to version
+func (s *Session) CheckPolicy(pkgPath, version string) error {
+	if s.Policy == nil {
+		return nil
+	}
+
+	var current string
+	if parser, err := s.Parser(); err == nil {
+		current = parser.GetVersion(pkgPath)
+	}
+	if err := s.Policy.Check(pkgPath, current, version); err != nil {
+		return &PolicyError{Err: err}
+	}
+	return nil
+}
+
+// Tidy runs "go mod tidy" and invalidates the cache
+func (s *Session) Tidy() error {
+	defer s.Invalidate()
+	return ModTidy(s.Dir)
+}
+
+// Snapshot records the module's current go.mod and go.sum contents
+func (s *Session) Snapshot() (*Snapshot, error) {
+	return TakeSnapshot(s.GoModPath)
+}
+
+// Restore writes a snapshot back to disk and invalidates the cache
+func (s *Session) Restore(snap *Snapshot) error {
+	defer s.Invalidate()
+	return snap.Restore()
+}
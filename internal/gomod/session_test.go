@@ -0,0 +1,136 @@
+package gomod
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tamcore/go-autobump/internal/runner"
+)
+
+// sessionRunner answers "go mod graph" from the go.mod in dir, emulates
+// "go get" by rewriting the required version and counts the graph runs
+type sessionRunner struct {
+	graphs int
+}
+
+func (r *sessionRunner) Run(_ context.Context, dir, _ string, args ...string) ([]byte, []byte, error) {
+	goModPath := filepath.Join(dir, "go.mod")
+	switch args[0] {
+	case "mod":
+		r.graphs++
+		p, err := NewParser(goModPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		return []byte("example.com/app example.com/dep@" + p.GetVersion("example.com/dep") + "\n"), nil, nil
+	case "get":
+		_, version, _ := strings.Cut(args[1], "@")
+		data, err := os.ReadFile(goModPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		updated := strings.Replace(string(data), "example.com/dep v1.0.0", "example.com/dep "+version, 1)
+		return nil, nil, os.WriteFile(goModPath, []byte(updated), 0644)
+	}
+	return nil, nil, nil
+}
+
+func TestSessionCache(t *testing.T) {
+	stub := &sessionRunner{}
+	defer runner.Set(runner.Default())
+	runner.Set(stub)
+
+	goModPath := filepath.Join(t.TempDir(), "go.mod")
+	if err := os.WriteFile(goModPath, []byte("module example.com/app\n\ngo 1.22\n\nrequire example.com/dep v1.0.0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sess := NewSession(goModPath)
+
+	first, err := sess.Parser()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second, _ := sess.Parser(); second != first {
+		t.Error("Parser() parsed go.mod again, want the cached parser")
+	}
+	for range 2 {
+		if _, err := sess.Graph(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if stub.graphs != 1 {
+		t.Errorf("go mod graph ran %d times, want the graph cached", stub.graphs)
+	}
+
+	// Changes through the session drop the cache
+	if err := sess.GoGet("example.com/dep", "v1.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	parser, err := sess.Parser()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := parser.GetVersion("example.com/dep"); got != "v1.0.1" {
+		t.Errorf("Parser() after GoGet() has example.com/dep %s, want v1.0.1", got)
+	}
+	graph, err := sess.Graph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stub.graphs != 2 || graph.Edges()[0].To.Version != "v1.0.1" {
+		t.Errorf("Graph() after GoGet() = %+v after %d runs, want it recomputed", graph.Edges(), stub.graphs)
+	}
+}
+
+// TestSessionRestore covers rolling a module back over several updates, as
+// --atomic does once one of them fails
+func TestSessionRestore(t *testing.T) {
+	const goMod = "module example.com/app\n\ngo 1.22\n\nrequire example.com/dep v1.0.0\n"
+	const goSum = "example.com/dep v1.0.0 h1:abc=\n"
+	dir := t.TempDir()
+	goModPath := filepath.Join(dir, "go.mod")
+	goSumPath := filepath.Join(dir, "go.sum")
+	for path, content := range map[string]string{goModPath: goMod, goSumPath: goSum} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sess := NewSession(goModPath)
+
+	snap, err := sess.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, version := range []string{"v1.0.1", "v1.0.2"} {
+		if err := os.WriteFile(goModPath, []byte("module example.com/app\n\ngo 1.22\n\nrequire example.com/dep "+version+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(goSumPath, []byte(goSum+"example.com/dep "+version+" h1:def=\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		sess.Invalidate()
+		if _, err := sess.Parser(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := sess.Restore(snap); err != nil {
+		t.Fatalf("Restore() error: %v", err)
+	}
+	if data, _ := os.ReadFile(goModPath); string(data) != goMod {
+		t.Errorf("go.mod after Restore() =\n%s\nwant\n%s", data, goMod)
+	}
+	if data, _ := os.ReadFile(goSumPath); string(data) != goSum {
+		t.Errorf("go.sum after Restore() =\n%s\nwant\n%s", data, goSum)
+	}
+	parser, err := sess.Parser()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := parser.GetVersion("example.com/dep"); got != "v1.0.0" {
+		t.Errorf("Parser() after Restore() has example.com/dep %s, want v1.0.0", got)
+	}
+}
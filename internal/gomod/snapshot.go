@@ -0,0 +1,82 @@
+package gomod
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Snapshot holds the contents of a module's go.mod and go.sum at a point in time
+type Snapshot struct {
+	GoModPath string
+	GoMod     []byte
+	GoSum     []byte
+	hasGoSum  bool
+}
+
+// TakeSnapshot records the current contents of go.mod and go.sum
+func TakeSnapshot(goModPath string) (*Snapshot, error) {
+	goMod, err := os.ReadFile(goModPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read go.mod: %w", err)
+	}
+
+	snap := &Snapshot{GoModPath: goModPath, GoMod: goMod}
+
+	goSum, err := os.ReadFile(goSumPath(goModPath))
+	switch {
+	case err == nil:
+		snap.GoSum = goSum
+		snap.hasGoSum = true
+	case !os.IsNotExist(err):
+		return nil, fmt.Errorf("failed to read go.sum: %w", err)
+	}
+
+	return snap, nil
+}
+
+// Restore writes the recorded go.mod and go.sum back to disk.
+// If go.sum did not exist when the snapshot was taken, it is removed.
+func (s *Snapshot) Restore() error {
+	return s.WriteTo(s.GoModPath)
+}
+
+// WriteTo writes the recorded go.mod and go.sum to the module at goModPath,
+// which may differ from the module the snapshot was taken from
+func (s *Snapshot) WriteTo(goModPath string) error {
+	defer InvalidateWhy(GetModuleDir(goModPath))
+	if err := os.WriteFile(goModPath, s.GoMod, 0644); err != nil {
+		return fmt.Errorf("failed to restore go.mod: %w", err)
+	}
+
+	sumPath := goSumPath(goModPath)
+	if !s.hasGoSum {
+		if err := os.Remove(sumPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove go.sum: %w", err)
+		}
+		return nil
+	}
+
+	if err := os.WriteFile(sumPath, s.GoSum, 0644); err != nil {
+		return fmt.Errorf("failed to restore go.sum: %w", err)
+	}
+	return nil
+}
+
+// Changed reports whether go.mod or go.sum on disk differ from the snapshot
+func (s *Snapshot) Changed() (bool, error) {
+	current, err := TakeSnapshot(s.GoModPath)
+	if err != nil {
+		return false, err
+	}
+
+	return !bytes.Equal(current.GoMod, s.GoMod) ||
+		current.hasGoSum != s.hasGoSum ||
+		!bytes.Equal(current.GoSum, s.GoSum), nil
+}
+
+// goSumPath returns the go.sum path belonging to a go.mod path
+func goSumPath(goModPath string) string {
+	return filepath.Join(filepath.Dir(goModPath), "go.sum")
+}
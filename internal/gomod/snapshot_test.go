@@ -0,0 +1,39 @@
+package gomod
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSnapshotChanged(t *testing.T) {
+	dir := t.TempDir()
+	goModPath := filepath.Join(dir, "go.mod")
+	if err := os.WriteFile(goModPath, []byte("module example.com/test\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	snap, err := TakeSnapshot(goModPath)
+	if err != nil {
+		t.Fatalf("TakeSnapshot() error: %v", err)
+	}
+
+	if changed, err := snap.Changed(); err != nil || changed {
+		t.Errorf("Changed() = %v, %v; want false", changed, err)
+	}
+
+	// Creating go.sum is a change even though go.mod is untouched
+	if err := os.WriteFile(filepath.Join(dir, "go.sum"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if changed, err := snap.Changed(); err != nil || !changed {
+		t.Errorf("Changed() after adding go.sum = %v, %v; want true", changed, err)
+	}
+
+	if err := snap.Restore(); err != nil {
+		t.Fatalf("Restore() error: %v", err)
+	}
+	if changed, err := snap.Changed(); err != nil || changed {
+		t.Errorf("Changed() after Restore = %v, %v; want false", changed, err)
+	}
+}
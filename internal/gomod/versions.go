@@ -0,0 +1,96 @@
+package gomod
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/tamcore/go-autobump/internal/runner"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// moduleInfo is the subset of "go list -m -json" output we care about
+type moduleInfo struct {
+	Path     string   `json:"Path"`
+	Version  string   `json:"Version"`
+	Versions []string `json:"Versions"`
+}
+
+// listModule runs "go list -m -json" with the given extra arguments
+func listModule(moduleDir string, args ...string) (moduleInfo, error) {
+	cmdArgs := append([]string{"list", "-m", "-json"}, args...)
+	stdout, stderr, err := runner.Run(moduleDir, runner.Go, cmdArgs...)
+	if err != nil {
+		return moduleInfo{}, fmt.Errorf("go list -m failed: %w\nstderr: %s", goError(err, stderr), stderr)
+	}
+
+	var info moduleInfo
+	if err := json.Unmarshal(stdout, &info); err != nil {
+		return moduleInfo{}, fmt.Errorf("failed to parse go list output: %w", err)
+	}
+	return info, nil
+}
+
+// ListVersions returns all published versions of a module, in semver order
+func ListVersions(moduleDir, modulePath string) ([]string, error) {
+	info, err := listModule(moduleDir, "-versions", modulePath)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := info.Versions
+	semver.Sort(versions)
+	return versions, nil
+}
+
+// SelectedVersion returns the version of a module selected by MVS in the build list
+func SelectedVersion(moduleDir, modulePath string) (string, error) {
+	info, err := listModule(moduleDir, modulePath)
+	if err != nil {
+		return "", err
+	}
+	return info.Version, nil
+}
+
+// VersionsAfter returns the versions strictly newer than current, keeping only
+// those with the same major version unless allowMajor is set. Pre-releases are
+// skipped unless current is itself a pre-release; a pseudo-version does not
+// count as one.
+// The input must be sorted; the output preserves that order.
+func VersionsAfter(versions []string, current string, allowMajor bool) []string {
+	current = NormalizeVersion(current)
+	onPrerelease := semver.Prerelease(current) != "" && !module.IsPseudoVersion(current)
+
+	var newer []string
+	for _, v := range versions {
+		if semver.Compare(v, current) <= 0 {
+			continue
+		}
+		// Skip pre-releases unless we are already on one
+		if semver.Prerelease(v) != "" && !onPrerelease {
+			continue
+		}
+		if !allowMajor && semver.Major(v) != semver.Major(current) {
+			continue
+		}
+		newer = append(newer, v)
+	}
+	return newer
+}
+
+// LatestPatch returns the newest version in the same major.minor line as current,
+// or an empty string if no newer patch release exists
+func LatestPatch(versions []string, current string) string {
+	current = NormalizeVersion(current)
+
+	var latest string
+	for _, v := range versions {
+		if semver.MajorMinor(v) != semver.MajorMinor(current) || semver.Prerelease(v) != "" {
+			continue
+		}
+		if semver.Compare(v, current) > 0 && (latest == "" || semver.Compare(v, latest) > 0) {
+			latest = v
+		}
+	}
+	return latest
+}
@@ -0,0 +1,56 @@
+package gomod
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestVersionsAfter(t *testing.T) {
+	versions := []string{"v1.0.0", "v1.1.0", "v1.2.0-rc.1", "v1.2.0", "v2.0.0+incompatible"}
+
+	got := VersionsAfter(versions, "1.0.0", false)
+	want := []string{"v1.1.0", "v1.2.0"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("VersionsAfter(allowMajor=false) = %v, want %v", got, want)
+	}
+
+	got = VersionsAfter(versions, "v1.1.0", true)
+	want = []string{"v1.2.0", "v2.0.0+incompatible"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("VersionsAfter(allowMajor=true) = %v, want %v", got, want)
+	}
+
+	got = VersionsAfter(versions, "v1.1.1-0.20230101000000-abcdef123456", false)
+	want = []string{"v1.2.0"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("VersionsAfter(pseudo-version) = %v, want %v", got, want)
+	}
+
+	got = VersionsAfter(versions, "v1.1.0-rc.1", false)
+	want = []string{"v1.1.0", "v1.2.0-rc.1", "v1.2.0"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("VersionsAfter(pre-release) = %v, want %v", got, want)
+	}
+}
+
+func TestLatestPatch(t *testing.T) {
+	versions := []string{"v1.2.0", "v1.2.3", "v1.2.4-rc.1", "v1.3.0"}
+
+	tests := []struct {
+		current  string
+		expected string
+	}{
+		{"v1.2.0", "v1.2.3"},
+		{"v1.2.3", ""},
+		{"v1.3.0", ""},
+		{"1.2.1", "v1.2.3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.current, func(t *testing.T) {
+			if got := LatestPatch(versions, tt.current); got != tt.expected {
+				t.Errorf("LatestPatch(%q) = %q, want %q", tt.current, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package gomod
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/tamcore/go-autobump/internal/runner"
+)
+
+// whyCache holds "go mod why -m" output per module directory and module
+// path, shared by the updater, VEX generation and issue trackers of a run
+var whyCache = struct {
+	sync.Mutex
+	entries map[string]map[string]string
+}{entries: make(map[string]map[string]string)}
+
+// ModWhy runs "go mod why -m" to find why a module is needed
+// Returns the import chain explaining why the module is required.
+// Results are cached until the module is changed through this package or
+// InvalidateWhy is called.
+func ModWhy(moduleDir, pkgPath string) (string, error) {
+	key := filepath.Clean(moduleDir)
+
+	whyCache.Lock()
+	out, ok := whyCache.entries[key][pkgPath]
+	whyCache.Unlock()
+	if ok {
+		return out, nil
+	}
+
+	args := []string{"mod", "why", "-m"}
+	var env map[string]string
+	if IsVendored(moduleDir) {
+		// Match what "go mod vendor" copies, which excludes tests of
+		// dependencies, and keep a -mod=vendor in GOFLAGS from applying
+		args = append(args, "-vendor")
+		var goflags string
+		if stdout, _, err := runner.Run(moduleDir, runner.Go, "env", "GOFLAGS"); err == nil {
+			goflags = string(stdout)
+		}
+		env = map[string]string{"GOFLAGS": withModMod(goflags)}
+	}
+	args = append(args, pkgPath)
+
+	stdout, stderr, err := runner.RunEnv(moduleDir, env, runner.Go, args...)
+	if err != nil {
+		return "", fmt.Errorf("go mod why failed: %w\nstderr: %s", err, stderr)
+	}
+
+	whyCache.Lock()
+	if whyCache.entries[key] == nil {
+		whyCache.entries[key] = make(map[string]string)
+	}
+	whyCache.entries[key][pkgPath] = string(stdout)
+	whyCache.Unlock()
+
+	return string(stdout), nil
+}
+
+// withModMod returns the GOFLAGS goflags with -mod=mod in place of any -mod
+// flag, keeping the other flags of the user
+func withModMod(goflags string) string {
+	var flags []string
+	for _, flag := range strings.Fields(goflags) {
+		if !strings.HasPrefix(strings.TrimLeft(flag, "-"), "mod=") {
+			flags = append(flags, flag)
+		}
+	}
+	return strings.Join(append(flags, "-mod=mod"), " ")
+}
+
+// InvalidateWhy drops the cached "go mod why" results of the module in
+// moduleDir, e.g. after its go.mod changed
+func InvalidateWhy(moduleDir string) {
+	whyCache.Lock()
+	delete(whyCache.entries, filepath.Clean(moduleDir))
+	whyCache.Unlock()
+}
+
+// IsVendored reports whether the module in moduleDir is vendored, in which
+// case the go command defaults to -mod=vendor
+func IsVendored(moduleDir string) bool {
+	_, err := os.Stat(filepath.Join(moduleDir, "vendor", "modules.txt"))
+	return err == nil
+}
@@ -0,0 +1,83 @@
+package gomod
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/tamcore/go-autobump/internal/runner"
+)
+
+// countingRunner records go invocations and answers "go mod why" from a fixed output
+type countingRunner struct {
+	calls [][]string
+}
+
+func (r *countingRunner) Run(_ context.Context, _, _ string, args ...string) ([]byte, []byte, error) {
+	r.calls = append(r.calls, args)
+	return []byte("# example.com/dep\nexample.com/main\nexample.com/dep\n"), nil, nil
+}
+
+func TestModWhyCache(t *testing.T) {
+	stub := &countingRunner{}
+	defer runner.Set(runner.Default())
+	runner.Set(stub)
+
+	dir := t.TempDir()
+	for range 3 {
+		if _, err := ModWhy(dir, "example.com/dep"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(stub.calls) != 1 {
+		t.Fatalf("go mod why ran %d times, want 1", len(stub.calls))
+	}
+
+	InvalidateWhy(dir)
+	if _, err := ModWhy(dir, "example.com/dep"); err != nil {
+		t.Fatal(err)
+	}
+	if len(stub.calls) != 2 {
+		t.Errorf("go mod why ran %d times after invalidation, want 2", len(stub.calls))
+	}
+}
+
+func TestModWhyVendored(t *testing.T) {
+	stub := &countingRunner{}
+	defer runner.Set(runner.Default())
+	runner.Set(stub)
+
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "vendor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "vendor", "modules.txt"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ModWhy(dir, "example.com/dep"); err != nil {
+		t.Fatal(err)
+	}
+	if last := stub.calls[len(stub.calls)-1]; !slices.Contains(last, "-vendor") {
+		t.Errorf("args = %v, want -vendor for a vendored module", last)
+	}
+}
+
+func TestWithModMod(t *testing.T) {
+	tests := []struct {
+		goflags string
+		want    string
+	}{
+		{"", "-mod=mod"},
+		{"\n", "-mod=mod"},
+		{"-mod=vendor -modcacherw", "-modcacherw -mod=mod"},
+		{"-tags=integration --mod=readonly -trimpath\n", "-tags=integration -trimpath -mod=mod"},
+	}
+	for _, tt := range tests {
+		if got := withModMod(tt.goflags); got != tt.want {
+			t.Errorf("withModMod(%q) = %q, want %q", tt.goflags, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package gorelease
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Kinds of files declaring a Go version
+const (
+	// KindGoMod is the toolchain or go directive of a go.mod, which is set
+	// with go mod edit rather than Rewrite
+	KindGoMod        = "go.mod"
+	KindGoVersion    = ".go-version"
+	KindToolVersions = ".tool-versions"
+	KindWorkflow     = "workflow"
+	KindImage        = "image"
+	// KindBuilder is the golang image of a Dockerfile FROM line, whose tag
+	// may name a minor line only, e.g. "golang:1.22-alpine"
+	KindBuilder = "builder"
+)
+
+// versionPatterns match a pinned Go version (submatch 2) in a line of a file
+// of each kind. Versions without a patch release, e.g. "1.22" or "1.22.x",
+// already follow the latest patch release and are not matched.
+var versionPatterns = map[string]*regexp.Regexp{
+	KindGoVersion:    regexp.MustCompile(`^(\s*(?:go)?)(\d+\.\d+\.\d+)\s*$`),
+	KindToolVersions: regexp.MustCompile(`^(\s*golang\s+)(\d+\.\d+\.\d+)\b`),
+	KindWorkflow:     regexp.MustCompile(`^(\s*(?:-\s*)?go-version:\s*['"]?)(\d+\.\d+\.\d+)\b`),
+	KindImage:        regexp.MustCompile(`(\bgolang:)(\d+\.\d+\.\d+)\b`),
+	KindBuilder:      regexp.MustCompile(`(?i)^(\s*FROM\s+(?:--platform=\S+\s+)?(?:\S+/)?golang:)(\d+\.\d+(?:\.\d+)?)\b`),
+}
+
+// Declaration is a Go version pinned in a file of the repository
+type Declaration struct {
+	// File is the path of the file, relative to the repository root
+	File string `json:"file"`
+	// Line is the 1-based line number of the version
+	Line    int    `json:"line"`
+	Kind    string `json:"kind"`
+	Version string `json:"version"`
+}
+
+// FindDeclarations returns the Go versions pinned below root in
+// .go-version and .tool-versions files, setup-go steps of GitHub Actions
+// workflows, and golang images of Dockerfiles and .gitlab-ci.yml
+func FindDeclarations(root string) ([]Declaration, error) {
+	return find(root, fileKind)
+}
+
+// FindBuilderImages returns the golang images Dockerfiles below root build
+// with, including tags of a minor line such as "golang:1.22"
+func FindBuilderImages(root string) ([]Declaration, error) {
+	return find(root, func(rel string) string {
+		if fileKind(rel) == KindImage && filepath.Base(rel) != ".gitlab-ci.yml" {
+			return KindBuilder
+		}
+		return ""
+	})
+}
+
+// find returns the Go versions declared below root by the files kindOf
+// returns a kind for, given their slash-separated path relative to root
+func find(root string, kindOf func(rel string) string) ([]Declaration, error) {
+	var decls []Declaration
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if name := d.Name(); path != root && (name == ".git" || name == "vendor" || name == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		kind := kindOf(filepath.ToSlash(rel))
+		if kind == "" {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for i, line := range strings.Split(string(data), "\n") {
+			if m := versionPatterns[kind].FindStringSubmatch(line); m != nil {
+				decls = append(decls, Declaration{File: rel, Line: i + 1, Kind: kind, Version: m[2]})
+			}
+		}
+		return nil
+	})
+	return decls, err
+}
+
+// fileKind returns the kind of the file at the slash-separated path rel, or
+// "" if it declares no Go version
+func fileKind(rel string) string {
+	name := filepath.Base(rel)
+	switch {
+	case name == KindGoVersion:
+		return KindGoVersion
+	case name == KindToolVersions:
+		return KindToolVersions
+	case strings.HasPrefix(rel, ".github/workflows/") && (strings.HasSuffix(name, ".yml") || strings.HasSuffix(name, ".yaml")):
+		return KindWorkflow
+	case strings.HasPrefix(name, "Dockerfile"), strings.HasSuffix(name, ".dockerfile"), name == ".gitlab-ci.yml":
+		return KindImage
+	}
+	return ""
+}
+
+// Rewrite replaces the version of decl below root with version
+func Rewrite(root string, decl Declaration, version string) error {
+	path := filepath.Join(root, decl.File)
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	lines := strings.Split(string(data), "\n")
+	if decl.Line < 1 || decl.Line > len(lines) {
+		return fmt.Errorf("%s has no line %d", decl.File, decl.Line)
+	}
+	pattern := versionPatterns[decl.Kind]
+	line := lines[decl.Line-1]
+	m := pattern.FindStringSubmatchIndex(line)
+	if m == nil || line[m[4]:m[5]] != decl.Version {
+		return fmt.Errorf("%s:%d no longer declares Go %s", decl.File, decl.Line, decl.Version)
+	}
+	lines[decl.Line-1] = line[:m[4]] + version + line[m[5]:]
+
+	return os.WriteFile(path, []byte(strings.Join(lines, "\n")), info.Mode().Perm())
+}
@@ -0,0 +1,105 @@
+package gorelease
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFindDeclarations(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		".go-version":                 "1.22.3\n",
+		".tool-versions":              "nodejs 20.1.0\ngolang 1.22.3\n",
+		".github/workflows/ci.yml":    "steps:\n  - uses: actions/setup-go@v5\n    with:\n      go-version: '1.22.3'\n  - uses: actions/setup-go@v5\n    with:\n      go-version: 1.22.x\n",
+		"build/Dockerfile":            "FROM golang:1.22.3-alpine AS build\nFROM alpine:3.20\n",
+		".gitlab-ci.yml":              "image: docker.io/library/golang:1.21.5\n",
+		"docs/go-version.txt":         "1.22.3\n",
+		"vendor/example/.go-version":  "1.20.1\n",
+		".github/workflows/README.md": "go-version: 1.22.3\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	decls, err := FindDeclarations(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Declaration{
+		{File: ".github/workflows/ci.yml", Line: 4, Kind: KindWorkflow, Version: "1.22.3"},
+		{File: ".gitlab-ci.yml", Line: 1, Kind: KindImage, Version: "1.21.5"},
+		{File: ".go-version", Line: 1, Kind: KindGoVersion, Version: "1.22.3"},
+		{File: ".tool-versions", Line: 2, Kind: KindToolVersions, Version: "1.22.3"},
+		{File: "build/Dockerfile", Line: 1, Kind: KindImage, Version: "1.22.3"},
+	}
+	if !reflect.DeepEqual(decls, want) {
+		t.Fatalf("FindDeclarations() = %+v, want %+v", decls, want)
+	}
+
+	for _, decl := range decls {
+		if err := Rewrite(root, decl, "1.22.9"); err != nil {
+			t.Fatalf("Rewrite(%s) error = %v", decl.File, err)
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(root, "build/Dockerfile"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); got != "FROM golang:1.22.9-alpine AS build\nFROM alpine:3.20\n" {
+		t.Errorf("Dockerfile after Rewrite = %q", got)
+	}
+	data, err = os.ReadFile(filepath.Join(root, ".github/workflows/ci.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), "steps:\n  - uses: actions/setup-go@v5\n    with:\n      go-version: '1.22.9'\n  - uses: actions/setup-go@v5\n    with:\n      go-version: 1.22.x\n"; got != want {
+		t.Errorf("workflow after Rewrite = %q", got)
+	}
+
+	if err := Rewrite(root, decls[0], "1.23.3"); err == nil {
+		t.Error("Rewrite() of a changed declaration succeeded, want an error")
+	}
+}
+
+func TestFindBuilderImages(t *testing.T) {
+	root := t.TempDir()
+	dockerfile := "FROM --platform=$BUILDPLATFORM golang:1.22-alpine AS build\nRUN go build ./...\nFROM registry.example.com:5000/library/golang:1.21.5\nFROM gcr.io/distroless/static\nCOPY --from=golang:1.20 /usr/local/go /go\n"
+	if err := os.WriteFile(filepath.Join(root, "Dockerfile"), []byte(dockerfile), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".gitlab-ci.yml"), []byte("image: golang:1.21\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	decls, err := FindBuilderImages(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Declaration{
+		{File: "Dockerfile", Line: 1, Kind: KindBuilder, Version: "1.22"},
+		{File: "Dockerfile", Line: 3, Kind: KindBuilder, Version: "1.21.5"},
+	}
+	if !reflect.DeepEqual(decls, want) {
+		t.Fatalf("FindBuilderImages() = %+v, want %+v", decls, want)
+	}
+
+	if err := Rewrite(root, decls[0], "1.23"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(root, "Dockerfile"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); !strings.HasPrefix(got, "FROM --platform=$BUILDPLATFORM golang:1.23-alpine AS build\n") {
+		t.Errorf("Dockerfile after Rewrite = %q", got)
+	}
+}
@@ -0,0 +1,343 @@
+// Package gorelease checks Go versions against the security fixes of Go
+// releases, from the Go release feed and the Go vulnerability database
+package gorelease
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tamcore/go-autobump/internal/httpclient"
+	"golang.org/x/mod/semver"
+)
+
+const (
+	// DefaultReleaseFeed lists all Go releases
+	DefaultReleaseFeed = "https://go.dev/dl/?mode=json&include=all"
+	// DefaultVulnDB is the Go vulnerability database
+	DefaultVulnDB = "https://vuln.go.dev"
+)
+
+// goModules are the modules of the Go vulnerability database covering Go
+// itself: the standard library and the go command
+var goModules = map[string]bool{"stdlib": true, "toolchain": true}
+
+// Client fetches Go releases and their vulnerabilities
+type Client struct {
+	ReleaseFeed string
+	VulnDB      string
+	HTTPClient  *http.Client
+}
+
+// NewClient creates a client of the Go release feed and vulnerability database
+func NewClient() *Client {
+	return &Client{
+		ReleaseFeed: DefaultReleaseFeed,
+		VulnDB:      DefaultVulnDB,
+		HTTPClient:  httpclient.New(60 * time.Second),
+	}
+}
+
+// Vulnerability is a vulnerability of the Go standard library or go command
+type Vulnerability struct {
+	ID      string   `json:"id"`
+	Aliases []string `json:"aliases,omitempty"`
+	Summary string   `json:"summary,omitempty"`
+
+	ranges [][]event
+}
+
+// event is an OSV range event; versions are semver without the "v" prefix
+type event struct {
+	Introduced string `json:"introduced,omitempty"`
+	Fixed      string `json:"fixed,omitempty"`
+}
+
+// Affects reports whether the Go version (e.g. "1.22.3" or "go1.22.3") is
+// affected by the vulnerability
+func (v Vulnerability) Affects(version string) bool {
+	sv := Semver(version)
+	for _, events := range v.ranges {
+		affected := false
+		for _, e := range events {
+			switch {
+			case e.Introduced == "0":
+				affected = true
+			case e.Introduced != "" && semver.Compare(sv, "v"+e.Introduced) >= 0:
+				affected = true
+			case e.Fixed != "" && semver.Compare(sv, "v"+e.Fixed) >= 0:
+				affected = false
+			}
+		}
+		if affected {
+			return true
+		}
+	}
+	return false
+}
+
+// Checker answers which Go versions are affected, and which release fixes them
+type Checker struct {
+	// Releases are the stable Go releases, e.g. "1.22.9"
+	Releases        []string
+	Vulnerabilities []Vulnerability
+}
+
+// Affecting returns the vulnerabilities affecting the Go version
+func (c *Checker) Affecting(version string) []Vulnerability {
+	var vulns []Vulnerability
+	for _, v := range c.Vulnerabilities {
+		if v.Affects(version) {
+			vulns = append(vulns, v)
+		}
+	}
+	return vulns
+}
+
+// Target returns the release an affected Go version is bumped to: the latest
+// patch release of its minor line, or of the next minor line whose latest
+// patch release is not affected by any known vulnerability. It is empty if
+// no release qualifies.
+func (c *Checker) Target(version string) string {
+	return c.target(version, false)
+}
+
+// target returns the oldest latest patch release of a minor line that is
+// newer than version and not affected by any known vulnerability, only
+// considering supported minor lines if so requested
+func (c *Checker) target(version string, supported bool) string {
+	current := Semver(version)
+	var target string
+	for _, r := range c.latestPatches() {
+		sv := Semver(r)
+		if semver.Compare(sv, current) <= 0 || (supported && !c.Supported(r)) || len(c.Affecting(r)) > 0 {
+			continue
+		}
+		if target == "" || semver.Compare(sv, Semver(target)) < 0 {
+			target = r
+		}
+	}
+	return target
+}
+
+// latestPatches returns the latest patch release of each minor line, keyed
+// by the line, e.g. "v1.22"
+func (c *Checker) latestPatches() map[string]string {
+	latest := make(map[string]string)
+	for _, r := range c.Releases {
+		sv := Semver(r)
+		line := semver.MajorMinor(sv)
+		if semver.Compare(sv, Semver(latest[line])) > 0 {
+			latest[line] = r
+		}
+	}
+	return latest
+}
+
+// supportedLines is the number of minor lines of Go receiving security
+// fixes: each is supported until two newer ones are released
+const supportedLines = 2
+
+// Supported reports whether the minor line of the Go version still receives
+// security fixes
+func (c *Checker) Supported(version string) bool {
+	line := semver.MajorMinor(Semver(version))
+	newer := 0
+	for l := range c.latestPatches() {
+		if semver.Compare(l, line) > 0 {
+			newer++
+		}
+	}
+	return newer < supportedLines
+}
+
+// BuilderCheck is the result of checking the tag of a golang image
+type BuilderCheck struct {
+	// Version is the Go release the tag stands for
+	Version         string
+	Vulnerabilities []Vulnerability
+	// EOL is set when the minor line of the tag no longer receives
+	// security fixes
+	EOL bool
+	// Target is the tag an affected or end-of-life tag is bumped to, empty
+	// if none qualifies
+	Target string
+}
+
+// CheckBuilder checks the tag of a golang image, e.g. "1.22.3" or "1.22";
+// a tag of a minor line stands for its latest patch release. An affected or
+// end-of-life tag is bumped to a tag of the same form naming the oldest
+// supported minor line, or latest patch release of it, that is newer and
+// not affected by any known vulnerability.
+func (c *Checker) CheckBuilder(tag string) BuilderCheck {
+	minorLine := strings.Count(tag, ".") == 1
+	check := BuilderCheck{Version: tag}
+	if minorLine {
+		if latest := c.latestPatches()[semver.MajorMinor(Semver(tag))]; latest != "" {
+			check.Version = latest
+		}
+	}
+	check.Vulnerabilities = c.Affecting(check.Version)
+	check.EOL = !c.Supported(check.Version)
+	if len(check.Vulnerabilities) == 0 && !check.EOL {
+		return check
+	}
+
+	check.Target = c.target(check.Version, true)
+	if minorLine && check.Target != "" {
+		check.Target = strings.TrimPrefix(semver.MajorMinor(Semver(check.Target)), "v")
+	}
+	return check
+}
+
+// Load fetches the stable Go releases and the vulnerabilities of Go that may
+// affect any of versions
+func (c *Client) Load(ctx context.Context, versions []string) (*Checker, error) {
+	releases, err := c.releases(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Entries whose latest fix is no newer than the oldest of versions affect
+	// neither versions nor the newer releases they are bumped to
+	oldest := ""
+	for _, v := range versions {
+		if oldest == "" || semver.Compare(Semver(v), Semver(oldest)) < 0 {
+			oldest = v
+		}
+	}
+
+	var index []struct {
+		Path  string `json:"path"`
+		Vulns []struct {
+			ID    string `json:"id"`
+			Fixed string `json:"fixed"`
+		} `json:"vulns"`
+	}
+	if err := c.get(ctx, strings.TrimSuffix(c.VulnDB, "/")+"/index/modules.json", &index); err != nil {
+		return nil, fmt.Errorf("failed to fetch the Go vulnerability database index: %w", err)
+	}
+
+	checker := &Checker{Releases: releases}
+	seen := make(map[string]bool)
+	for _, module := range index {
+		if !goModules[module.Path] {
+			continue
+		}
+		for _, entry := range module.Vulns {
+			if seen[entry.ID] || (entry.Fixed != "" && oldest != "" && semver.Compare(Semver(oldest), "v"+strings.TrimPrefix(entry.Fixed, "v")) >= 0) {
+				continue
+			}
+			seen[entry.ID] = true
+
+			vuln, err := c.vulnerability(ctx, entry.ID)
+			if err != nil {
+				return nil, err
+			}
+			checker.Vulnerabilities = append(checker.Vulnerabilities, vuln)
+		}
+	}
+	return checker, nil
+}
+
+// releases returns the stable Go releases, without the "go" prefix
+func (c *Client) releases(ctx context.Context) ([]string, error) {
+	var feed []struct {
+		Version string `json:"version"`
+		Stable  bool   `json:"stable"`
+	}
+	if err := c.get(ctx, c.ReleaseFeed, &feed); err != nil {
+		return nil, fmt.Errorf("failed to fetch the Go release feed: %w", err)
+	}
+
+	var releases []string
+	for _, r := range feed {
+		if r.Stable {
+			releases = append(releases, strings.TrimPrefix(r.Version, "go"))
+		}
+	}
+	return releases, nil
+}
+
+// vulnerability fetches the OSV entry id and keeps the ranges of Go itself
+func (c *Client) vulnerability(ctx context.Context, id string) (Vulnerability, error) {
+	var entry struct {
+		Vulnerability
+		Affected []struct {
+			Package struct {
+				Name string `json:"name"`
+			} `json:"package"`
+			Ranges []struct {
+				Type   string  `json:"type"`
+				Events []event `json:"events"`
+			} `json:"ranges"`
+		} `json:"affected"`
+	}
+	if err := c.get(ctx, strings.TrimSuffix(c.VulnDB, "/")+"/ID/"+id+".json", &entry); err != nil {
+		return Vulnerability{}, fmt.Errorf("failed to fetch %s from the Go vulnerability database: %w", id, err)
+	}
+
+	vuln := entry.Vulnerability
+	for _, affected := range entry.Affected {
+		if !goModules[affected.Package.Name] {
+			continue
+		}
+		for _, r := range affected.Ranges {
+			if r.Type == "SEMVER" {
+				vuln.ranges = append(vuln.ranges, r.Events)
+			}
+		}
+	}
+	return vuln, nil
+}
+
+// get fetches url and decodes its JSON body into out
+func (c *Client) get(ctx context.Context, url string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned status %d", url, resp.StatusCode)
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// Semver converts a Go version, e.g. "go1.22.3", "1.22" or "1.21rc2", to
+// semver ("v1.22.3", "v1.22.0", "v1.21.0-rc.2")
+func Semver(version string) string {
+	version = strings.TrimPrefix(version, "go")
+	if version == "" {
+		return ""
+	}
+
+	prerelease := ""
+	for _, kind := range []string{"rc", "beta"} {
+		if i := strings.Index(version, kind); i > 0 {
+			version, prerelease = version[:i], "-"+kind+"."+version[i+len(kind):]
+			break
+		}
+	}
+	if strings.Count(version, ".") == 1 {
+		version += ".0"
+	}
+	return "v" + version + prerelease
+}
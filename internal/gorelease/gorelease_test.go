@@ -0,0 +1,132 @@
+package gorelease
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	var fetched []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/dl/":
+			_, _ = w.Write([]byte(`[
+				{"version": "go1.23.3", "stable": true},
+				{"version": "go1.23.2", "stable": true},
+				{"version": "go1.24rc1", "stable": false},
+				{"version": "go1.22.9", "stable": true},
+				{"version": "go1.22.8", "stable": true},
+				{"version": "go1.21.13", "stable": true}
+			]`))
+		case "/index/modules.json":
+			_, _ = w.Write([]byte(`[
+				{"path": "stdlib", "vulns": [
+					{"id": "GO-2023-0001", "fixed": "1.20.5"},
+					{"id": "GO-2024-0002", "fixed": "1.23.2"}
+				]},
+				{"path": "toolchain", "vulns": [{"id": "GO-2024-0003", "fixed": "1.22.9"}]},
+				{"path": "golang.org/x/net", "vulns": [{"id": "GO-2024-0004", "fixed": "0.23.0"}]}
+			]`))
+		case "/ID/GO-2024-0002.json":
+			fetched = append(fetched, r.URL.Path)
+			_, _ = w.Write([]byte(`{"id": "GO-2024-0002", "aliases": ["CVE-2024-0002"], "affected": [
+				{"package": {"name": "stdlib", "ecosystem": "Go"}, "ranges": [{"type": "SEMVER", "events": [
+					{"introduced": "0"}, {"fixed": "1.22.8"}, {"introduced": "1.23.0-0"}, {"fixed": "1.23.2"}
+				]}]}
+			]}`))
+		case "/ID/GO-2024-0003.json":
+			fetched = append(fetched, r.URL.Path)
+			_, _ = w.Write([]byte(`{"id": "GO-2024-0003", "affected": [
+				{"package": {"name": "toolchain", "ecosystem": "Go"}, "ranges": [{"type": "SEMVER", "events": [
+					{"introduced": "1.22.0-0"}, {"fixed": "1.22.9"}
+				]}]}
+			]}`))
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.ReleaseFeed = server.URL + "/dl/"
+	client.VulnDB = server.URL
+
+	checker, err := client.Load(context.Background(), []string{"1.22.3", "go1.23.0"})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(fetched) != 2 {
+		t.Errorf("fetched %v, want only the entries that may affect the versions", fetched)
+	}
+
+	tests := []struct {
+		version  string
+		affected int
+		target   string
+	}{
+		{"1.22.3", 2, "1.22.9"},
+		{"1.22.8", 1, "1.22.9"},
+		{"go1.22.9", 0, "1.23.3"},
+		{"1.23.0", 1, "1.23.3"},
+		{"1.23", 1, "1.23.3"},
+		{"1.21.2", 1, "1.22.9"},
+		{"1.23.3", 0, ""},
+	}
+	for _, tt := range tests {
+		if got := checker.Affecting(tt.version); len(got) != tt.affected {
+			t.Errorf("Affecting(%q) = %+v, want %d vulnerabilities", tt.version, got, tt.affected)
+		}
+		if got := checker.Target(tt.version); got != tt.target {
+			t.Errorf("Target(%q) = %q, want %q", tt.version, got, tt.target)
+		}
+	}
+}
+
+func TestSemver(t *testing.T) {
+	tests := map[string]string{
+		"go1.22.3": "v1.22.3",
+		"1.22":     "v1.22.0",
+		"1.21rc2":  "v1.21.0-rc.2",
+		"go1.20":   "v1.20.0",
+		"":         "",
+	}
+	for in, want := range tests {
+		if got := Semver(in); got != want {
+			t.Errorf("Semver(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestCheckBuilder(t *testing.T) {
+	checker := &Checker{
+		Releases: []string{"1.21.13", "1.22.8", "1.22.9", "1.23.2", "1.23.3"},
+		Vulnerabilities: []Vulnerability{{ID: "GO-2024-0001", ranges: [][]event{{
+			{Introduced: "0"}, {Fixed: "1.22.9"}, {Introduced: "1.23.0-0"}, {Fixed: "1.23.3"},
+		}}}},
+	}
+
+	tests := []struct {
+		tag      string
+		affected int
+		eol      bool
+		target   string
+	}{
+		{"1.22", 0, false, ""},
+		{"1.22.9", 0, false, ""},
+		{"1.22.8", 1, false, "1.22.9"},
+		{"1.21", 1, true, "1.22"},
+		{"1.21.13", 1, true, "1.22.9"},
+		{"1.20", 1, true, "1.22"},
+		{"1.23.2", 1, false, "1.23.3"},
+	}
+	for _, tt := range tests {
+		got := checker.CheckBuilder(tt.tag)
+		if len(got.Vulnerabilities) != tt.affected || got.EOL != tt.eol || got.Target != tt.target {
+			t.Errorf("CheckBuilder(%q) = %d vulnerabilities, EOL %v, target %q; want %d, %v, %q",
+				tt.tag, len(got.Vulnerabilities), got.EOL, got.Target, tt.affected, tt.eol, tt.target)
+		}
+	}
+}
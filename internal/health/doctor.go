@@ -0,0 +1,289 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/tamcore/go-autobump/internal/ai"
+	"github.com/tamcore/go-autobump/internal/forge"
+	"github.com/tamcore/go-autobump/internal/httpclient"
+	"github.com/tamcore/go-autobump/internal/runner"
+	"github.com/tamcore/go-autobump/internal/trivy"
+	"golang.org/x/mod/semver"
+)
+
+// minGoVersion is the oldest go command that understands GOTOOLCHAIN and
+// toolchain directives
+const minGoVersion = "go1.21"
+
+// DefaultMaxDBAge is the age above which the Trivy DB counts as stale
+const DefaultMaxDBAge = 72 * time.Hour
+
+// DoctorOptions selects the checks of Doctor beyond those of Check
+type DoctorOptions struct {
+	Options
+
+	// MaxDBAge is the age above which the Trivy DB counts as stale
+	// (default: DefaultMaxDBAge)
+	MaxDBAge time.Duration
+	// AI, if set, is checked to accept its API key
+	AI *ai.Client
+	// Forge, if set, is checked to give access to its repository
+	Forge forge.Provider
+}
+
+// Doctor validates the whole environment of a run: the tools of Check, the
+// freshness of the Trivy DB, the module proxy, credentials for private
+// modules, git and, if configured, the AI endpoint and forge. Failed results
+// carry a remediation tip.
+func Doctor(ctx context.Context, opts DoctorOptions) []Result {
+	if opts.MaxDBAge == 0 {
+		opts.MaxDBAge = DefaultMaxDBAge
+	}
+
+	goResult, trivyResult := checkGo(), checkTrivy()
+	goUsable := goResult.Err == nil
+	results := []Result{checkGoVersion(goResult), trivyResult}
+	if trivyResult.Err == nil {
+		results = append(results, checkTrivyDB(opts.Scan), checkTrivyDBAge(opts.MaxDBAge, time.Now()))
+	} else {
+		results = append(results, skipped("trivy-db", "trivy is not usable"), skipped("trivy-db-age", "trivy is not usable"))
+	}
+	if goUsable {
+		env, err := goEnv("GOPROXY", "GOPRIVATE", "GONOPROXY", "GOAUTH")
+		results = append(results, checkProxy(ctx, env, err), checkPrivateModules(env, err))
+	} else {
+		results = append(results, skipped("go-proxy", "go is not usable"), skipped("private-modules", "go is not usable"))
+	}
+	results = append(results, checkGit(), checkAI(ctx, opts.AI), checkForge(ctx, opts.Forge))
+
+	for i, r := range results {
+		if r.Err != nil && r.Tip == "" {
+			results[i].Tip = tips[r.Name]
+		}
+	}
+	return results
+}
+
+// tips are the default remediation tips of failed checks
+var tips = map[string]string{
+	"go":       "install Go from https://go.dev/dl/ or point --go-binary at it",
+	"git":      "install git, which is needed for cloning, worktrees and private modules",
+	"trivy":    "install Trivy (https://trivy.dev/latest/getting-started/installation/) or point --trivy-binary at it",
+	"trivy-db": "check network access to the Trivy DB repository (ghcr.io/aquasecurity/trivy-db), or pre-download it with \"trivy image --download-db-only\"",
+	"ai":       "check ai.endpoint and ai.api-key (AUTOBUMP_AI_API_KEY)",
+	"forge":    "check forge.repo and that forge.token (GITHUB_TOKEN, GITLAB_TOKEN), or the GitHub App of forge.app-id, may read the repository",
+}
+
+// skipped returns the result of a check skipped for reason
+func skipped(name, reason string) Result {
+	return Result{Name: name, Detail: reason, Skipped: true}
+}
+
+// checkGoVersion checks that the go of a passed checkGo result is recent enough
+func checkGoVersion(r Result) Result {
+	if r.Err != nil {
+		return r
+	}
+	// "go version go1.22.5 linux/amd64"
+	if fields := strings.Fields(r.Detail); len(fields) >= 3 {
+		version := "v" + strings.TrimPrefix(fields[2], "go")
+		if semver.IsValid(version) && semver.Compare(version, "v"+strings.TrimPrefix(minGoVersion, "go")) < 0 {
+			r.Err = fmt.Errorf("%s is older than %s", fields[2], minGoVersion)
+			r.Tip = "upgrade Go to " + minGoVersion + " or later, which go-toolchain and toolchain directives need"
+		}
+	}
+	return r
+}
+
+// checkTrivyDBAge checks that the downloaded Trivy DB is at most maxAge old
+func checkTrivyDBAge(maxAge time.Duration, now time.Time) Result {
+	r := Result{Name: "trivy-db-age"}
+	info, err := trivy.InstalledDB()
+	switch {
+	case err != nil:
+		r.Err = err
+		r.Tip = tips["trivy"]
+	case info == nil:
+		return skipped(r.Name, "no vulnerability database downloaded yet")
+	default:
+		age := now.Sub(info.UpdatedAt).Truncate(time.Hour)
+		r.Detail = fmt.Sprintf("vulnerability database built %s ago", age)
+		if age > maxAge {
+			r.Err = fmt.Errorf("vulnerability database is stale: built %s ago (at %s)", age, info.UpdatedAt.Format(time.RFC3339))
+			r.Tip = "run without --skip-trivy-db-update, or refresh the pre-downloaded DB with \"trivy image --download-db-only\""
+		}
+	}
+	return r
+}
+
+// checkProxy checks that the first module proxy of GOPROXY answers
+func checkProxy(ctx context.Context, env map[string]string, envErr error) Result {
+	r := Result{Name: "go-proxy"}
+	if envErr != nil {
+		r.Err, r.Tip = envErr, tips["go"]
+		return r
+	}
+
+	proxy := firstProxy(env["GOPROXY"])
+	switch proxy {
+	case "off":
+		r.Detail = "module downloads disabled (GOPROXY=off)"
+		return r
+	case "direct":
+		r.Detail = "modules are fetched directly from their origin (GOPROXY=direct)"
+		return r
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	// Any module will do; a proxy without it answers 404 or 410
+	req, err := http.NewRequestWithContext(ctx, "GET", strings.TrimSuffix(proxy, "/")+"/golang.org/x/mod/@latest", nil)
+	if err != nil {
+		r.Err = fmt.Errorf("invalid GOPROXY %q: %w", proxy, err)
+		r.Tip = "set GOPROXY to a comma-separated list of proxy URLs, \"direct\" or \"off\""
+		return r
+	}
+	resp, err := httpclient.New(0).Do(req)
+	if err != nil {
+		r.Err = fmt.Errorf("module proxy %s is not reachable: %w", proxy, err)
+		r.Tip = "check network access to the proxy (HTTPS_PROXY, NO_PROXY) and, for a mirror with a private CA, tls.ca-file"
+		return r
+	}
+	_ = resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		r.Detail = fmt.Sprintf("%s reachable, requires authentication (status %d)", proxy, resp.StatusCode)
+	case resp.StatusCode >= 500:
+		r.Err = fmt.Errorf("module proxy %s returned status %d", proxy, resp.StatusCode)
+		r.Tip = "the proxy is unhealthy; retry later or set GOPROXY to another mirror"
+	default:
+		r.Detail = proxy + " reachable"
+	}
+	return r
+}
+
+// checkPrivateModules checks that credentials exist for fetching the private
+// modules of GOPRIVATE and GONOPROXY from their origin
+func checkPrivateModules(env map[string]string, envErr error) Result {
+	r := Result{Name: "private-modules"}
+	if envErr != nil {
+		r.Err, r.Tip = envErr, tips["go"]
+		return r
+	}
+
+	patterns := firstNonEmpty(env["GONOPROXY"], env["GOPRIVATE"])
+	if patterns == "" {
+		return skipped(r.Name, "no private modules configured (GOPRIVATE)")
+	}
+
+	if source := credentialSource(env); source != "" {
+		r.Detail = fmt.Sprintf("%s fetched with credentials from %s", patterns, source)
+		return r
+	}
+	r.Err = fmt.Errorf("no credentials found for the private modules %s", patterns)
+	r.Tip = "add a .netrc entry for their hosts, configure a git credential helper, or rewrite their URLs to SSH with git config url.<ssh-url>.insteadOf <https-url>"
+	return r
+}
+
+// credentialSource returns where the go command can get credentials for
+// private modules from, or empty if there is none
+func credentialSource(env map[string]string) string {
+	// GOAUTH defaults to netrc, which the .netrc lookup below covers
+	if auth := env["GOAUTH"]; auth != "" && auth != "off" && auth != "netrc" {
+		return "GOAUTH"
+	}
+
+	netrc := os.Getenv("NETRC")
+	if netrc == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			name := ".netrc"
+			if runtime.GOOS == "windows" {
+				name = "_netrc"
+			}
+			netrc = filepath.Join(home, name)
+		}
+	}
+	if info, err := os.Stat(netrc); err == nil && info.Size() > 0 {
+		return netrc
+	}
+
+	stdout, _, err := runner.Run("", runner.Git, "config", "--get-regexp", `^(credential\..*helper|url\..*\.insteadof)$`)
+	if err == nil && len(strings.TrimSpace(string(stdout))) > 0 {
+		return "git config"
+	}
+	return ""
+}
+
+// checkAI checks that the AI endpoint accepts the API key
+func checkAI(ctx context.Context, client *ai.Client) Result {
+	if client == nil {
+		return skipped("ai", "no AI API key configured")
+	}
+
+	r := Result{Name: "ai"}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	if r.Err = client.Ping(ctx); r.Err == nil {
+		r.Detail = "endpoint accepts the API key"
+	}
+	return r
+}
+
+// checkForge checks that the forge gives access to the repository
+func checkForge(ctx context.Context, provider forge.Provider) Result {
+	if provider == nil {
+		return skipped("forge", "no forge provider configured")
+	}
+
+	r := Result{Name: "forge"}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	if r.Err = provider.CheckAccess(ctx); r.Err == nil {
+		r.Detail = "repository accessible"
+	}
+	return r
+}
+
+// goEnv returns the values of the go environment variables names
+func goEnv(names ...string) (map[string]string, error) {
+	args := append([]string{"env", "-json"}, names...)
+	stdout, stderr, err := runner.Run("", runner.Go, args...)
+	if err != nil {
+		return nil, fmt.Errorf("go env failed: %v\nstderr: %s", err, stderr)
+	}
+	env := make(map[string]string)
+	if err := json.Unmarshal(stdout, &env); err != nil {
+		return nil, fmt.Errorf("failed to parse go env output: %w", err)
+	}
+	return env, nil
+}
+
+// firstProxy returns the first entry of a GOPROXY list, whose entries are
+// separated by "," or "|"
+func firstProxy(goproxy string) string {
+	first, _, _ := strings.Cut(goproxy, ",")
+	first, _, _ = strings.Cut(first, "|")
+	if first = strings.TrimSpace(first); first == "" {
+		return "https://proxy.golang.org"
+	}
+	return first
+}
+
+// firstNonEmpty returns the first non-empty value
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,116 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tamcore/go-autobump/internal/ai/aitest"
+	"github.com/tamcore/go-autobump/internal/runner"
+)
+
+// doctorRunner answers the commands of Doctor
+type doctorRunner struct {
+	goVersion string
+	goproxy   string
+	goprivate string
+	dbUpdated time.Time
+	gitConfig string
+}
+
+func (r *doctorRunner) Run(ctx context.Context, dir, name string, args ...string) ([]byte, []byte, error) {
+	command := name + " " + strings.Join(args, " ")
+	switch {
+	case strings.HasPrefix(command, "go version"):
+		return []byte("go version " + r.goVersion + " linux/amd64\n"), nil, nil
+	case strings.HasPrefix(command, "go env -json"):
+		return []byte(fmt.Sprintf(`{"GOPROXY":%q,"GOPRIVATE":%q,"GONOPROXY":%q,"GOAUTH":""}`, r.goproxy, r.goprivate, r.goprivate)), nil, nil
+	case strings.HasPrefix(command, "trivy --version"):
+		return []byte(fmt.Sprintf(`{"Version":"0.50.1","VulnerabilityDB":{"Version":2,"UpdatedAt":%q}}`, r.dbUpdated.Format(time.RFC3339))), nil, nil
+	case name == runner.Trivy:
+		return []byte(`{"Results":[]}`), nil, nil
+	case strings.HasPrefix(command, "git config"):
+		if r.gitConfig == "" {
+			return nil, nil, fmt.Errorf("exit status 1")
+		}
+		return []byte(r.gitConfig), nil, nil
+	case name == runner.Git:
+		return []byte("git version 2.45.0\n"), nil, nil
+	}
+	return nil, nil, fmt.Errorf("unexpected command %q", command)
+}
+
+func TestDoctor(t *testing.T) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}))
+	defer proxy.Close()
+	aiServer := aitest.NewServer()
+	defer aiServer.Close()
+	t.Setenv("NETRC", filepath.Join(t.TempDir(), "missing"))
+
+	healthy := &doctorRunner{
+		goVersion: "go1.22.5",
+		goproxy:   proxy.URL + ",direct",
+		goprivate: "example.com/private",
+		dbUpdated: time.Now().Add(-6 * time.Hour),
+		gitConfig: "credential.helper store\n",
+	}
+
+	tests := []struct {
+		name   string
+		modify func(r *doctorRunner)
+		failed map[string]bool
+	}{
+		{"healthy", func(r *doctorRunner) {}, nil},
+		{"old go", func(r *doctorRunner) { r.goVersion = "go1.20.14" }, map[string]bool{"go": true}},
+		{"stale db", func(r *doctorRunner) { r.dbUpdated = time.Now().Add(-10 * 24 * time.Hour) }, map[string]bool{"trivy-db-age": true}},
+		{"no credentials", func(r *doctorRunner) { r.gitConfig = "" }, map[string]bool{"private-modules": true}},
+		{"proxy down", func(r *doctorRunner) { r.goproxy = "http://127.0.0.1:1" }, map[string]bool{"go-proxy": true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := *healthy
+			tt.modify(&stub)
+			runner.Set(&stub)
+			defer runner.Set(runner.Default())
+
+			results := Doctor(context.Background(), DoctorOptions{AI: aiServer.AIClient()})
+			for _, r := range results {
+				if failed := r.Err != nil; failed != tt.failed[r.Name] {
+					t.Errorf("%s: err = %v, want failed = %v", r.Name, r.Err, tt.failed[r.Name])
+				}
+				if r.Err != nil && r.Tip == "" {
+					t.Errorf("%s failed without a tip", r.Name)
+				}
+				if r.Skipped != (r.Name == "forge") {
+					t.Errorf("%s: skipped = %v", r.Name, r.Skipped)
+				}
+			}
+		})
+	}
+}
+
+func TestFirstProxy(t *testing.T) {
+	tests := []struct {
+		goproxy string
+		want    string
+	}{
+		{"", "https://proxy.golang.org"},
+		{"https://proxy.golang.org,direct", "https://proxy.golang.org"},
+		{"https://mirror.example.com|https://proxy.golang.org", "https://mirror.example.com"},
+		{"off", "off"},
+	}
+
+	for _, tt := range tests {
+		if got := firstProxy(tt.goproxy); got != tt.want {
+			t.Errorf("firstProxy(%q) = %q, want %q", tt.goproxy, got, tt.want)
+		}
+	}
+}
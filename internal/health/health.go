@@ -0,0 +1,86 @@
+// Package health implements self-checks of the external tools go-autobump
+// depends on, used to validate a deployment before doing any work.
+package health
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tamcore/go-autobump/internal/runner"
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+// Result is the outcome of a single check
+type Result struct {
+	Name   string
+	Detail string
+	Err    error
+	// Tip suggests how to fix a failed check
+	Tip string
+	// Skipped is set for checks of something not configured or not present
+	Skipped bool
+}
+
+// Options selects which checks to run
+type Options struct {
+	// Scan are the options of Trivy scans, e.g. to expect a pre-downloaded
+	// DB instead of downloading it
+	Scan trivy.ScanOptions
+	// Git also checks that git is available, e.g. for cloning or worktrees
+	Git bool
+}
+
+// Check runs all checks and returns their results in a stable order
+func Check(opts Options) []Result {
+	results := []Result{
+		checkGo(),
+		checkTrivy(),
+		checkTrivyDB(opts.Scan),
+	}
+	if opts.Git {
+		results = append(results, checkGit())
+	}
+	return results
+}
+
+// Healthy reports whether all results passed
+func Healthy(results []Result) bool {
+	for _, r := range results {
+		if r.Err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func checkGo() Result {
+	return commandResult("go", runner.Go, "version")
+}
+
+func checkGit() Result {
+	return commandResult("git", runner.Git, "--version")
+}
+
+func checkTrivy() Result {
+	version, err := trivy.CheckVersion()
+	return Result{Name: "trivy", Detail: version, Err: err}
+}
+
+func checkTrivyDB(opts trivy.ScanOptions) Result {
+	r := Result{Name: "trivy-db", Detail: "vulnerability database accessible"}
+	if opts.SkipDBUpdate {
+		r.Detail = "pre-downloaded vulnerability database usable"
+	}
+	r.Err = trivy.CheckDB(opts)
+	return r
+}
+
+// commandResult runs a tool and reports the first line of its output
+func commandResult(name, tool string, args ...string) Result {
+	stdout, stderr, err := runner.Run("", tool, args...)
+	if err != nil {
+		return Result{Name: name, Err: fmt.Errorf("%s is not usable: %v\nstderr: %s", tool, err, stderr)}
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(string(stdout)), "\n")
+	return Result{Name: name, Detail: line}
+}
@@ -0,0 +1,63 @@
+// Package httpclient provides the HTTP clients of all outbound requests,
+// sharing one transport with the configured proxies and certificate
+// authorities, and helpers for the JSON APIs they talk to.
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Options configure the transport of all outbound HTTP clients (AI, forges,
+// Jira, exporters, EPSS and KEV feeds)
+type Options struct {
+	// CAFile is a PEM bundle of certificate authorities trusted in addition
+	// to the system roots, e.g. of a TLS-intercepting corporate proxy
+	CAFile string
+	// InsecureSkipVerify disables certificate verification
+	InsecureSkipVerify bool
+}
+
+// transport is shared by the clients returned by New
+var transport http.RoundTripper = http.DefaultTransport
+
+// Configure sets the transport of the clients returned by New. Proxies are
+// taken from HTTPS_PROXY, HTTP_PROXY and NO_PROXY.
+func Configure(o Options) error {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = http.ProxyFromEnvironment
+
+	if o.CAFile != "" || o.InsecureSkipVerify {
+		t.TLSClientConfig = &tls.Config{
+			MinVersion:         tls.VersionTLS12,
+			InsecureSkipVerify: o.InsecureSkipVerify,
+		}
+	}
+	if o.CAFile != "" {
+		pem, err := os.ReadFile(o.CAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no PEM certificates found in CA file %s", o.CAFile)
+		}
+		t.TLSClientConfig.RootCAs = pool
+	}
+
+	transport = t
+	return nil
+}
+
+// New returns an HTTP client with the configured transport and the given
+// overall timeout
+func New(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
@@ -0,0 +1,50 @@
+package httpclient
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestConfigureCAFile(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	defer func() { transport = http.DefaultTransport }()
+
+	if err := Configure(Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(5 * time.Second).Get(server.URL); err == nil {
+		t.Error("request to a server with an untrusted certificate succeeded")
+	}
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, certPEM, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Configure(Options{CAFile: caFile}); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := New(5 * time.Second).Get(server.URL)
+	if err != nil {
+		t.Fatalf("request with the CA file configured: %v", err)
+	}
+	_ = resp.Body.Close()
+}
+
+func TestConfigureInvalidCAFile(t *testing.T) {
+	defer func() { transport = http.DefaultTransport }()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, []byte("not a certificate"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Configure(Options{CAFile: caFile}); err == nil {
+		t.Error("Configure() accepted a CA file without certificates")
+	}
+}
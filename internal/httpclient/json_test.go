@@ -0,0 +1,50 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDoJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var in map[string]string
+		if r.Header.Get("Content-Type") != "application/json" || json.NewDecoder(r.Body).Decode(&in) != nil {
+			http.Error(w, "expected a JSON body", http.StatusBadRequest)
+			return
+		}
+		if in["name"] == "missing" {
+			http.Error(w, "no such item", http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"greeting":"hello ` + in["name"] + `"}`))
+	}))
+	defer server.Close()
+
+	do := func(name string, out any) (*http.Response, error) {
+		req, err := NewJSONRequest(context.Background(), "POST", server.URL+"/items", map[string]string{"name": name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return DoJSON(New(5*time.Second), req, out)
+	}
+
+	var out struct {
+		Greeting string `json:"greeting"`
+	}
+	if _, err := do("world", &out); err != nil || out.Greeting != "hello world" {
+		t.Errorf("DoJSON() = %+v, %v, want the decoded response", out, err)
+	}
+
+	resp, err := do("missing", nil)
+	if err == nil || !strings.Contains(err.Error(), "POST /items returned status 404: no such item") {
+		t.Errorf("DoJSON() of a missing item = %v, want the status and response", err)
+	}
+	if resp == nil || resp.StatusCode != http.StatusNotFound {
+		t.Errorf("DoJSON() of a missing item returned response %v, want the 404", resp)
+	}
+}
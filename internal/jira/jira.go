@@ -0,0 +1,157 @@
+// Package jira creates and updates remediation tickets in Jira through its
+// REST API (v2, supported by Jira Cloud and Data Center)
+package jira
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/tamcore/go-autobump/internal/httpclient"
+)
+
+// Label is attached to every ticket go-autobump creates, to find them again
+const Label = "go-autobump"
+
+// Issue is a Jira issue
+type Issue struct {
+	Key         string
+	Summary     string
+	Description string
+	URL         string
+}
+
+// Client talks to a Jira instance
+type Client struct {
+	// BaseURL is the Jira site, e.g. https://example.atlassian.net
+	BaseURL string
+	// User is the account email for Jira Cloud API tokens; empty sends Token
+	// as a personal access token (Data Center)
+	User  string
+	Token string
+
+	HTTPClient *http.Client
+}
+
+// NewClient creates a Jira client
+func NewClient(baseURL, user, token string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		User:       user,
+		Token:      token,
+		HTTPClient: httpclient.New(30 * time.Second),
+	}
+}
+
+// Marker returns the line identifying the subject of a ticket, so later runs
+// can find it again
+func Marker(key string) string {
+	return "go-autobump-id: " + key
+}
+
+// issueFields is the subset of issue fields go-autobump reads
+type issueFields struct {
+	Summary     string `json:"summary"`
+	Description string `json:"description"`
+}
+
+type apiIssue struct {
+	Key    string      `json:"key"`
+	Fields issueFields `json:"fields"`
+}
+
+// Issues lists the unresolved issues of project carrying label
+func (c *Client) Issues(ctx context.Context, project, label string) ([]Issue, error) {
+	jql := fmt.Sprintf("project = %q AND labels = %q AND statusCategory != Done", project, label)
+
+	var issues []Issue
+	for start := 0; ; {
+		query := url.Values{
+			"jql":        {jql},
+			"fields":     {"summary,description"},
+			"startAt":    {fmt.Sprint(start)},
+			"maxResults": {"100"},
+		}
+		var page struct {
+			Total  int        `json:"total"`
+			Issues []apiIssue `json:"issues"`
+		}
+		if err := c.do(ctx, "GET", "/rest/api/2/search?"+query.Encode(), nil, &page); err != nil {
+			return nil, err
+		}
+		for _, i := range page.Issues {
+			issues = append(issues, Issue{
+				Key:         i.Key,
+				Summary:     i.Fields.Summary,
+				Description: i.Fields.Description,
+				URL:         c.browseURL(i.Key),
+			})
+		}
+		start += len(page.Issues)
+		if len(page.Issues) == 0 || start >= page.Total {
+			return issues, nil
+		}
+	}
+}
+
+// FindIssue returns the issue whose description contains the marker for key,
+// or nil if there is none
+func FindIssue(issues []Issue, key string) *Issue {
+	marker := Marker(key)
+	for i := range issues {
+		if strings.Contains(issues[i].Description, marker) {
+			return &issues[i]
+		}
+	}
+	return nil
+}
+
+// CreateIssue creates an issue of issueType in project
+func (c *Client) CreateIssue(ctx context.Context, project, issueType, summary, description string, labels []string) (*Issue, error) {
+	in := map[string]any{
+		"fields": map[string]any{
+			"project":     map[string]string{"key": project},
+			"issuetype":   map[string]string{"name": issueType},
+			"summary":     summary,
+			"description": description,
+			"labels":      labels,
+		},
+	}
+	var created struct {
+		Key string `json:"key"`
+	}
+	if err := c.do(ctx, "POST", "/rest/api/2/issue", in, &created); err != nil {
+		return nil, err
+	}
+	return &Issue{Key: created.Key, Summary: summary, Description: description, URL: c.browseURL(created.Key)}, nil
+}
+
+// UpdateDescription replaces the description of an issue
+func (c *Client) UpdateDescription(ctx context.Context, key, description string) error {
+	in := map[string]any{"fields": map[string]string{"description": description}}
+	return c.do(ctx, "PUT", "/rest/api/2/issue/"+url.PathEscape(key), in, nil)
+}
+
+// browseURL returns the web URL of an issue
+func (c *Client) browseURL(key string) string {
+	return c.BaseURL + "/browse/" + key
+}
+
+// do sends a request with an optional JSON body and decodes the JSON
+// response into out, if out is not nil
+func (c *Client) do(ctx context.Context, method, path string, in, out any) error {
+	req, err := httpclient.NewJSONRequest(ctx, method, c.BaseURL+path, in)
+	if err != nil {
+		return err
+	}
+	if c.User != "" {
+		req.SetBasicAuth(c.User, c.Token)
+	} else if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	_, err = httpclient.DoJSON(c.HTTPClient, req, out)
+	return err
+}
@@ -0,0 +1,72 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIssuesPaginates(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if got := r.Header.Get("Authorization"); got != "Bearer pat" {
+			t.Errorf("Authorization = %q", got)
+		}
+		if r.URL.Query().Get("jql") != `project = "SEC" AND labels = "go-autobump" AND statusCategory != Done` {
+			t.Errorf("jql = %q", r.URL.Query().Get("jql"))
+		}
+		body := `{"total": 2, "issues": [{"key": "SEC-1", "fields": {"summary": "a", "description": "x\n` + Marker("k1") + `"}}]}`
+		if r.URL.Query().Get("startAt") == "1" {
+			body = `{"total": 2, "issues": [{"key": "SEC-2", "fields": {"summary": "b", "description": "` + Marker("k2") + `"}}]}`
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	issues, err := NewClient(server.URL, "", "pat").Issues(context.Background(), "SEC", Label)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 2 || calls != 2 {
+		t.Fatalf("got %d issues in %d calls, want 2 in 2", len(issues), calls)
+	}
+	if got := FindIssue(issues, "k2"); got == nil || got.Key != "SEC-2" || got.URL != server.URL+"/browse/SEC-2" {
+		t.Errorf("FindIssue(k2) = %+v", got)
+	}
+	if got := FindIssue(issues, "k3"); got != nil {
+		t.Errorf("FindIssue(k3) = %+v, want nil", got)
+	}
+}
+
+func TestCreateIssue(t *testing.T) {
+	var got map[string]map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "me@example.com" || pass != "token" {
+			t.Errorf("basic auth = %q, %q, %v", user, pass, ok)
+		}
+		if r.Method != "POST" || r.URL.Path != "/rest/api/2/issue" {
+			t.Errorf("request = %s %s", r.Method, r.URL.Path)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_, _ = w.Write([]byte(`{"id": "10001", "key": "SEC-3"}`))
+	}))
+	defer server.Close()
+
+	issue, err := NewClient(server.URL, "me@example.com", "token").
+		CreateIssue(context.Background(), "SEC", "Bug", "summary", "description", []string{Label})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if issue.Key != "SEC-3" {
+		t.Errorf("Key = %q, want SEC-3", issue.Key)
+	}
+	fields := got["fields"]
+	if fields["summary"] != "summary" || fields["issuetype"].(map[string]any)["name"] != "Bug" ||
+		fields["project"].(map[string]any)["key"] != "SEC" {
+		t.Errorf("fields = %v", fields)
+	}
+}
@@ -0,0 +1,95 @@
+// Package kev fetches the CISA Known Exploited Vulnerabilities catalog
+package kev
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/tamcore/go-autobump/internal/alias"
+	"github.com/tamcore/go-autobump/internal/httpclient"
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+// DefaultEndpoint is the JSON feed of the CISA KEV catalog
+const DefaultEndpoint = "https://www.cisa.gov/sites/default/files/feeds/known_exploited_vulnerabilities.json"
+
+// Client fetches a KEV catalog
+type Client struct {
+	Endpoint   string
+	HTTPClient *http.Client
+}
+
+// NewClient creates a client for the CISA KEV catalog
+func NewClient() *Client {
+	return &Client{
+		Endpoint:   DefaultEndpoint,
+		HTTPClient: httpclient.New(60 * time.Second),
+	}
+}
+
+// catalog is the part of the KEV feed go-autobump uses
+type catalog struct {
+	Vulnerabilities []struct {
+		CVE string `json:"cveID"`
+	} `json:"vulnerabilities"`
+}
+
+// Catalog returns the set of CVEs known to be exploited
+func (c *Client) Catalog(ctx context.Context) (map[string]bool, error) {
+	endpoint := c.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("KEV request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read KEV catalog: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("KEV catalog returned status %d", resp.StatusCode)
+	}
+
+	var result catalog
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse KEV catalog: %w", err)
+	}
+
+	known := make(map[string]bool, len(result.Vulnerabilities))
+	for _, vuln := range result.Vulnerabilities {
+		known[vuln.CVE] = true
+	}
+	return known, nil
+}
+
+// Enrich marks every vulnerability in results that is in the catalog under
+// its ID or a known alias, as the catalog lists CVE IDs only
+func (c *Client) Enrich(ctx context.Context, results []trivy.ScanResult) error {
+	known, err := c.Catalog(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i := range results {
+		for j := range results[i].Vulnerabilities {
+			vuln := &results[i].Vulnerabilities[j]
+			vuln.KEV = slices.ContainsFunc(alias.IDs(vuln.VulnerabilityID), func(id string) bool { return known[id] })
+		}
+	}
+	return nil
+}
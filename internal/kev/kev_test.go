@@ -0,0 +1,40 @@
+package kev
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+func TestEnrich(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"title":"CISA Catalog of Known Exploited Vulnerabilities","vulnerabilities":[
+			{"cveID":"CVE-2024-0001","vendorProject":"Example"},
+			{"cveID":"CVE-2023-9999","vendorProject":"Other"}
+		]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.Endpoint = server.URL
+
+	results := []trivy.ScanResult{{Target: "go.mod", Vulnerabilities: []trivy.Vulnerability{
+		{VulnerabilityID: "CVE-2024-0001"},
+		{VulnerabilityID: "CVE-2024-0002"},
+		{VulnerabilityID: "GHSA-xxxx-yyyy-zzzz"},
+	}}}
+
+	if err := client.Enrich(context.Background(), results); err != nil {
+		t.Fatalf("Enrich() error = %v", err)
+	}
+
+	want := []bool{true, false, false}
+	for i, vuln := range results[0].Vulnerabilities {
+		if vuln.KEV != want[i] {
+			t.Errorf("%s KEV = %v, want %v", vuln.VulnerabilityID, vuln.KEV, want[i])
+		}
+	}
+}
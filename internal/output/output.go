@@ -0,0 +1,207 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Icon identifies the decoration of a status line
+type Icon int
+
+const (
+	IconNone Icon = iota
+	IconModule
+	IconSuccess
+	IconWarning
+	IconFailure
+	IconDryRun
+	IconDocument
+	IconUpdate
+	IconInfo
+	IconPackage
+	IconRollback
+)
+
+// decoration describes how an icon is rendered
+type decoration struct {
+	emoji string
+	text  string
+	color string
+}
+
+// ANSI color codes
+const (
+	colorReset  = "\033[0m"
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+	colorCyan   = "\033[36m"
+	colorBold   = "\033[1m"
+)
+
+var decorations = map[Icon]decoration{
+	IconModule:   {emoji: "📁", text: "==>", color: colorBold},
+	IconSuccess:  {emoji: "✅", text: "[ok]", color: colorGreen},
+	IconWarning:  {emoji: "⚠️ ", text: "[warn]", color: colorYellow},
+	IconFailure:  {emoji: "❌", text: "[fail]", color: colorRed},
+	IconDryRun:   {emoji: "🔍 [dry-run]", text: "[dry-run]", color: colorCyan},
+	IconDocument: {emoji: "📝", text: "[doc]"},
+	IconUpdate:   {emoji: "🔄", text: "[update]"},
+	IconInfo:     {emoji: "ℹ️ ", text: "[info]"},
+	IconPackage:  {emoji: "📦", text: "[pkg]"},
+	IconRollback: {emoji: "↩️ ", text: "[rollback]", color: colorYellow},
+}
+
+// Options configures how status output is rendered
+type Options struct {
+	// Quiet suppresses everything except warnings and failures
+	Quiet bool
+	// NoEmoji replaces emoji with plain-text markers
+	NoEmoji bool
+	// NoColor disables ANSI colors
+	NoColor bool
+	// JSON writes every line as a JSON object with time, level and message,
+	// for log collectors; implies no emoji, no colors and no spinner
+	JSON bool
+}
+
+var (
+	opts   Options
+	writer io.Writer = os.Stderr
+	color  bool
+)
+
+// Configure sets the output options. Colors are only used when writing to a
+// terminal and neither NoColor nor the NO_COLOR environment variable is set.
+func Configure(o Options) {
+	opts = o
+	if o.JSON {
+		opts.NoEmoji = true
+		opts.NoColor = true
+	}
+	color = !opts.NoColor && os.Getenv("NO_COLOR") == "" && IsTerminal(os.Stderr)
+}
+
+// SetWriter redirects status output, e.g. for tests
+func SetWriter(w io.Writer) {
+	writer = w
+}
+
+// Quiet reports whether quiet mode is enabled
+func Quiet() bool {
+	return opts.Quiet
+}
+
+// Status prints a decorated status line to stderr. Leading whitespace and
+// newlines in format are kept in front of the icon so callers can indent.
+// In quiet mode only warnings and failures are printed.
+func Status(icon Icon, format string, args ...any) {
+	if opts.Quiet && icon != IconWarning && icon != IconFailure {
+		return
+	}
+
+	msg := fmt.Sprintf(format, args...)
+	if opts.JSON {
+		writeJSON(level(icon), strings.TrimSpace(msg))
+		return
+	}
+
+	rest := strings.TrimLeft(msg, " \t\n")
+	prefix := msg[:len(msg)-len(rest)]
+
+	if symbol := Symbol(icon); symbol != "" {
+		rest = symbol + " " + rest
+	}
+	if c := decorations[icon].color; color && c != "" {
+		rest = c + rest + colorReset
+	}
+
+	writeLine(prefix + rest)
+}
+
+// Infof prints an undecorated informational line, suppressed in quiet mode
+func Infof(format string, args ...any) {
+	Status(IconNone, format, args...)
+}
+
+// Warnf prints a "Warning:" line, which is shown even in quiet mode
+func Warnf(format string, args ...any) {
+	if opts.JSON {
+		writeJSON("warn", fmt.Sprintf(format, args...))
+		return
+	}
+
+	msg := "Warning: " + fmt.Sprintf(format, args...)
+	if color {
+		msg = colorYellow + msg + colorReset
+	}
+	writeLine(msg)
+}
+
+// writeLine writes a line, clearing any progress spinner first so it is
+// redrawn below the line on its next tick
+func writeLine(line string) {
+	mu.Lock()
+	defer mu.Unlock()
+	clearLine()
+	_, _ = fmt.Fprintln(writer, line)
+}
+
+// logEntry is a line of JSON output
+type logEntry struct {
+	Time    string `json:"time"`
+	Level   string `json:"level"`
+	Message string `json:"msg"`
+}
+
+// writeJSON writes a single JSON log line
+func writeJSON(level, msg string) {
+	writeLine(formatJSON(level, msg))
+}
+
+// formatJSON renders a JSON log line
+func formatJSON(level, msg string) string {
+	line, _ := json.Marshal(logEntry{
+		Time:    time.Now().UTC().Format(time.RFC3339),
+		Level:   level,
+		Message: msg,
+	})
+	return string(line)
+}
+
+// level maps an icon to a log level
+func level(icon Icon) string {
+	switch icon {
+	case IconWarning, IconRollback:
+		return "warn"
+	case IconFailure:
+		return "error"
+	default:
+		return "info"
+	}
+}
+
+// Symbol returns the rendered icon, honoring the no-emoji option
+func Symbol(icon Icon) string {
+	d, ok := decorations[icon]
+	if !ok {
+		return ""
+	}
+	if opts.NoEmoji {
+		return d.text
+	}
+	return d.emoji
+}
+
+// IsTerminal reports whether f is connected to a terminal
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
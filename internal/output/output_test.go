@@ -0,0 +1,72 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestStatus(t *testing.T) {
+	var buf bytes.Buffer
+	SetWriter(&buf)
+	defer SetWriter(os.Stderr)
+
+	tests := []struct {
+		name     string
+		opts     Options
+		icon     Icon
+		format   string
+		expected string
+	}{
+		{"emoji", Options{}, IconSuccess, "  Updated %s", "  ✅ Updated x\n"},
+		{"no emoji", Options{NoEmoji: true}, IconFailure, "  Failed %s", "  [fail] Failed x\n"},
+		{"leading newline", Options{NoEmoji: true}, IconModule, "\nProcessing %s", "\n==> Processing x\n"},
+		{"quiet hides success", Options{Quiet: true}, IconSuccess, "  Updated %s", ""},
+		{"quiet keeps warnings", Options{Quiet: true, NoEmoji: true}, IconWarning, "  Careful %s", "  [warn] Careful x\n"},
+		{"no icon", Options{}, IconNone, "Found %s", "Found x\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			Configure(tt.opts)
+			Status(tt.icon, tt.format, "x")
+			if got := buf.String(); got != tt.expected {
+				t.Errorf("Status() wrote %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStatusJSON(t *testing.T) {
+	var buf bytes.Buffer
+	SetWriter(&buf)
+	defer SetWriter(os.Stderr)
+
+	Configure(Options{JSON: true})
+	defer Configure(Options{})
+
+	Status(IconFailure, "  Failed to update %s", "x")
+	Warnf("careful")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2: %q", len(lines), buf.String())
+	}
+
+	want := []logEntry{
+		{Level: "error", Message: "Failed to update x"},
+		{Level: "warn", Message: "careful"},
+	}
+	for i, line := range lines {
+		var got logEntry
+		if err := json.Unmarshal([]byte(line), &got); err != nil {
+			t.Fatalf("line %d is not JSON: %q", i, line)
+		}
+		if got.Level != want[i].Level || got.Message != want[i].Message || got.Time == "" {
+			t.Errorf("line %d = %+v, want level %q msg %q", i, got, want[i].Level, want[i].Message)
+		}
+	}
+}
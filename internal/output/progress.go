@@ -0,0 +1,148 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Progress reports the progress of a long-running operation. On a terminal it
+// draws a spinner line that is redrawn below regular status output; otherwise
+// it periodically logs a progress line so long runs don't look hung.
+type Progress struct {
+	label   string
+	total   int
+	current int
+	detail  string
+	started time.Time
+
+	tty  bool
+	stop chan struct{}
+	done chan struct{}
+}
+
+// LogInterval is how often progress is logged when not writing to a terminal
+var LogInterval = 30 * time.Second
+
+var (
+	// mu serializes writes so status lines and the spinner don't interleave
+	mu     sync.Mutex
+	active *Progress
+)
+
+var (
+	spinnerFrames      = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+	plainSpinnerFrames = []string{"|", "/", "-", "\\"}
+)
+
+// StartProgress begins reporting progress for label. A total of zero means
+// the number of steps is unknown. In quiet mode nothing is displayed.
+func StartProgress(label string, total int) *Progress {
+	p := &Progress{
+		label:   label,
+		total:   total,
+		started: time.Now(),
+		tty:     writer == os.Stderr && IsTerminal(os.Stderr) && !opts.JSON,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	if opts.Quiet {
+		close(p.done)
+		return p
+	}
+
+	mu.Lock()
+	active = p
+	mu.Unlock()
+
+	go p.run()
+	return p
+}
+
+// Step advances the progress by one and sets the current item
+func (p *Progress) Step(item string) {
+	mu.Lock()
+	defer mu.Unlock()
+	p.current++
+	p.detail = item
+}
+
+// SetDetail updates the current item without advancing
+func (p *Progress) SetDetail(detail string) {
+	mu.Lock()
+	defer mu.Unlock()
+	p.detail = detail
+}
+
+// Done stops the progress display
+func (p *Progress) Done() {
+	select {
+	case <-p.done:
+		return
+	default:
+	}
+
+	close(p.stop)
+	<-p.done
+}
+
+func (p *Progress) run() {
+	defer close(p.done)
+
+	interval := LogInterval
+	if p.tty {
+		interval = 100 * time.Millisecond
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	frame := 0
+	for {
+		select {
+		case <-p.stop:
+			mu.Lock()
+			if p.tty {
+				clearLine()
+			}
+			active = nil
+			mu.Unlock()
+			return
+		case <-ticker.C:
+			mu.Lock()
+			if p.tty {
+				frames := spinnerFrames
+				if opts.NoEmoji {
+					frames = plainSpinnerFrames
+				}
+				frame = (frame + 1) % len(frames)
+				_, _ = fmt.Fprintf(writer, "\r\033[K%s %s", frames[frame], p.line())
+			} else if opts.JSON {
+				_, _ = fmt.Fprintln(writer, formatJSON("info", "Progress: "+p.line()))
+			} else {
+				_, _ = fmt.Fprintf(writer, "Progress: %s\n", p.line())
+			}
+			mu.Unlock()
+		}
+	}
+}
+
+// line renders the current progress state; callers must hold mu
+func (p *Progress) line() string {
+	s := p.label
+	if p.total > 0 {
+		s += fmt.Sprintf(" [%d/%d]", p.current, p.total)
+	}
+	if p.detail != "" {
+		s += " " + p.detail
+	}
+	return s + fmt.Sprintf(" (%s)", time.Since(p.started).Round(time.Second))
+}
+
+// clearLine erases the spinner line if one is being drawn; callers must hold mu
+func clearLine() {
+	if active != nil && active.tty {
+		_, _ = fmt.Fprint(writer, "\r\033[K")
+	}
+}
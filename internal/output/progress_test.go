@@ -0,0 +1,40 @@
+package output
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgress(t *testing.T) {
+	var buf bytes.Buffer
+	SetWriter(&buf)
+	defer SetWriter(os.Stderr)
+	defer func(interval time.Duration) { LogInterval = interval }(LogInterval)
+	LogInterval = 10 * time.Millisecond
+
+	Configure(Options{})
+	p := StartProgress("Scanning", 3)
+	p.Step("go.mod")
+	time.Sleep(50 * time.Millisecond)
+	p.Done()
+	p.Done()
+
+	// Without a terminal, progress is logged as plain lines
+	first, _, _ := strings.Cut(buf.String(), "\n")
+	if !strings.HasPrefix(first, "Progress: Scanning [1/3] go.mod (") {
+		t.Errorf("progress logged %q, want the label, count and item", buf.String())
+	}
+
+	buf.Reset()
+	Configure(Options{Quiet: true})
+	defer Configure(Options{})
+	p = StartProgress("Scanning", 0)
+	time.Sleep(50 * time.Millisecond)
+	p.Done()
+	if buf.Len() > 0 {
+		t.Errorf("quiet progress logged %q", buf.String())
+	}
+}
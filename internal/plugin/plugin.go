@@ -0,0 +1,244 @@
+// Package plugin runs user-provided commands at hook points of a run. Each
+// plugin receives the run context as JSON on stdin, which lets teams add
+// custom scanners, notifiers and post-update steps without forking.
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/gomod"
+	"github.com/tamcore/go-autobump/internal/output"
+	"github.com/tamcore/go-autobump/internal/risk"
+	"github.com/tamcore/go-autobump/internal/runner"
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+// Hook points plugins can subscribe to
+const (
+	// HookScan runs once per module after the Trivy scan. The plugin may print
+	// a Trivy JSON report to stdout; its vulnerabilities are added to the module.
+	HookScan = "scan"
+	// HookPostUpdate runs after the updates of a module have been applied
+	HookPostUpdate = "post-update"
+	// HookComplete runs at the end of a scan or update run. The plugin may
+	// print a CompleteOutput JSON object to stdout.
+	HookComplete = "complete"
+	// HookDrift runs when a scan of the watch command finds vulnerabilities
+	// the previous scan did not; the context holds only the new ones
+	HookDrift = "drift"
+)
+
+// DefaultTimeout bounds a plugin invocation unless configured otherwise
+const DefaultTimeout = 5 * time.Minute
+
+// ValidHook reports whether h is a known hook point
+func ValidHook(h string) bool {
+	switch h {
+	case HookScan, HookPostUpdate, HookComplete, HookDrift:
+		return true
+	}
+	return false
+}
+
+// Context is the JSON document passed to plugins on stdin
+type Context struct {
+	Hook    string `json:"hook"`
+	Command string `json:"command"`
+	Path    string `json:"path"`
+	DryRun  bool   `json:"dry_run"`
+
+	// Module is the go.mod path for per-module hooks
+	Module string `json:"module,omitempty"`
+
+	// Results holds the scan results above the CVSS threshold
+	Results []trivy.ScanResult `json:"results,omitempty"`
+
+	// Updates lists the update attempts made so far
+	Updates []Update `json:"updates,omitempty"`
+
+	// Risk is the risk score before and after an update run, with
+	// --risk-score on the complete hook
+	Risk *risk.Delta `json:"risk,omitempty"`
+}
+
+// Update describes a single update attempt
+type Update struct {
+	Module           string `json:"module"`
+	VulnerabilityID  string `json:"vulnerability"`
+	Package          string `json:"package"`
+	InstalledVersion string `json:"installed_version"`
+	FixedVersion     string `json:"fixed_version"`
+	Error            string `json:"error,omitempty"`
+
+	// Advisory is the URL of the advisory of the vulnerability, if known
+	Advisory string `json:"advisory,omitempty"`
+
+	// Eliminated is set when the vulnerable module was removed because
+	// nothing needs it anymore, instead of being updated
+	Eliminated bool `json:"eliminated,omitempty"`
+
+	// Replacement is the patched fork or commit ("path version") the
+	// vulnerable module was replaced with, as no fixed release exists
+	Replacement string `json:"replacement,omitempty"`
+
+	// Proactive is set for a patch bump of a direct dependency without a
+	// known vulnerability (update --all-patch); VulnerabilityID is empty
+	Proactive bool `json:"proactive,omitempty"`
+
+	// Failure classifies a failed update, with a hint on how to resolve it
+	Failure string `json:"failure,omitempty"`
+	Hint    string `json:"hint,omitempty"`
+
+	// Suggestion is an AI-suggested remediation of a failed update
+	Suggestion string `json:"suggestion,omitempty"`
+
+	// ModuleChanges lists every module version the update changed,
+	// including modules moved along by minimal version selection
+	ModuleChanges []gomod.VersionChange `json:"module_changes,omitempty"`
+}
+
+// Run executes a single plugin with ctx on stdin and returns its stdout. The
+// plugin runs like the go and trivy commands of the run: it is stopped when
+// the run times out or is aborted, and gets the configured environment and
+// resource limits.
+func Run(p config.PluginConfig, ctx Context) ([]byte, error) {
+	input, err := json.Marshal(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode plugin context: %w", err)
+	}
+
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	runCtx, cancel := context.WithTimeout(runner.Context(), timeout)
+	defer cancel()
+	runCtx = runner.WithEnv(runner.WithStdin(runCtx, input), map[string]string{"AUTOBUMP_HOOK": ctx.Hook})
+
+	stdout, stderr, err := runner.Default().Run(runCtx, "", p.Command, p.Args...)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) && runner.Context().Err() == nil {
+			return nil, fmt.Errorf("plugin %s timed out after %s", name(p), timeout)
+		}
+		return nil, fmt.Errorf("plugin %s failed: %v\nstderr: %s", name(p), err, stderr)
+	}
+	return stdout, nil
+}
+
+// Dispatch runs every plugin subscribed to ctx.Hook. Failures of optional
+// plugins are reported as warnings; the first failure of a required plugin
+// is returned.
+func Dispatch(plugins []config.PluginConfig, ctx Context) error {
+	for _, p := range Subscribed(plugins, ctx.Hook) {
+		if _, err := Run(p, ctx); err != nil {
+			if p.Required {
+				return err
+			}
+			output.Warnf("%v", err)
+		}
+	}
+	return nil
+}
+
+// CompleteOutput is the optional JSON output of complete plugins
+type CompleteOutput struct {
+	// PullRequests are the URLs of the pull/merge requests the plugin
+	// opened or updated with the changes of the run
+	PullRequests []string `json:"pull_requests"`
+}
+
+// Complete runs the complete plugins and returns the pull requests they
+// report. Output that is not a JSON object is ignored, like that of other
+// notifiers.
+func Complete(plugins []config.PluginConfig, ctx Context) ([]string, error) {
+	ctx.Hook = HookComplete
+
+	var pullRequests []string
+	for _, p := range Subscribed(plugins, HookComplete) {
+		out, err := Run(p, ctx)
+		if err != nil {
+			if p.Required {
+				return nil, err
+			}
+			output.Warnf("%v", err)
+			continue
+		}
+		var result CompleteOutput
+		if trimmed := bytes.TrimSpace(out); bytes.HasPrefix(trimmed, []byte("{")) && json.Unmarshal(trimmed, &result) == nil {
+			pullRequests = append(pullRequests, result.PullRequests...)
+		}
+	}
+	return pullRequests, nil
+}
+
+// Scan runs the scan plugins for a module and returns the vulnerabilities
+// they report
+func Scan(plugins []config.PluginConfig, ctx Context) ([]trivy.Vulnerability, error) {
+	ctx.Hook = HookScan
+
+	var vulns []trivy.Vulnerability
+	for _, p := range Subscribed(plugins, HookScan) {
+		out, err := Run(p, ctx)
+		if err == nil && len(bytes.TrimSpace(out)) > 0 {
+			var result trivy.ScanResult
+			result, err = trivy.ParseReport(out, ctx.Module)
+			if err != nil {
+				err = fmt.Errorf("plugin %s: %w", name(p), err)
+			}
+			vulns = append(vulns, result.Vulnerabilities...)
+		}
+		if err != nil {
+			if p.Required {
+				return nil, err
+			}
+			output.Warnf("%v", err)
+		}
+	}
+	return vulns, nil
+}
+
+// Subscribed returns the plugins registered for hook
+func Subscribed(plugins []config.PluginConfig, hook string) []config.PluginConfig {
+	var subscribed []config.PluginConfig
+	for _, p := range plugins {
+		for _, h := range p.Hooks {
+			if h == hook {
+				subscribed = append(subscribed, p)
+				break
+			}
+		}
+	}
+	return subscribed
+}
+
+// Validate checks that every plugin has a command and only known hooks
+func Validate(plugins []config.PluginConfig) error {
+	for i, p := range plugins {
+		if p.Command == "" {
+			return fmt.Errorf("plugins[%d] has no command", i)
+		}
+		if len(p.Hooks) == 0 {
+			return fmt.Errorf("plugin %s has no hooks", name(p))
+		}
+		for _, h := range p.Hooks {
+			if !ValidHook(h) {
+				return fmt.Errorf("plugin %s: unknown hook %q (valid: scan, post-update, complete, drift)", name(p), h)
+			}
+		}
+	}
+	return nil
+}
+
+// name returns the plugin's display name, defaulting to its command
+func name(p config.PluginConfig) string {
+	if p.Name != "" {
+		return p.Name
+	}
+	return p.Command
+}
@@ -0,0 +1,96 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/runner"
+)
+
+func TestRunPassesContextOnStdin(t *testing.T) {
+	p := config.PluginConfig{Name: "echo", Command: "cat", Hooks: []string{HookComplete}}
+	ctx := Context{Hook: HookComplete, Command: "update", Path: ".", Updates: []Update{
+		{Module: "go.mod", VulnerabilityID: "CVE-2024-0001", Package: "example.com/mod"},
+	}}
+
+	out, err := Run(p, ctx)
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	var got Context
+	if err := json.Unmarshal(out, &got); err != nil {
+		t.Fatalf("plugin did not receive JSON: %v", err)
+	}
+	if got.Hook != HookComplete || len(got.Updates) != 1 || got.Updates[0].VulnerabilityID != "CVE-2024-0001" {
+		t.Errorf("unexpected context: %+v", got)
+	}
+}
+
+func TestRunWithRunnerSettings(t *testing.T) {
+	defer runner.Set(runner.Default())
+	defer runner.SetContext(runner.Context())
+	runner.Configure(runner.Options{Env: map[string]string{"team": "security"}})
+
+	p := config.PluginConfig{Command: "sh", Args: []string{"-c", "echo $AUTOBUMP_HOOK $TEAM"}, Hooks: []string{HookPostUpdate}}
+	out, err := Run(p, Context{Hook: HookPostUpdate})
+	if err != nil || strings.TrimSpace(string(out)) != "post-update security" {
+		t.Errorf("Run() = %q, %v, want the hook and the configured environment", out, err)
+	}
+
+	slow := config.PluginConfig{Name: "slow", Command: "sleep", Args: []string{"10"}, Timeout: 50 * time.Millisecond, Hooks: []string{HookComplete}}
+	if _, err := Run(slow, Context{}); err == nil || !strings.Contains(err.Error(), "timed out after 50ms") {
+		t.Errorf("Run() of a slow plugin = %v, want its timeout", err)
+	}
+
+	// An aborted run stops its plugins
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	runner.SetContext(ctx)
+	slow.Timeout = time.Minute
+	if _, err := Run(slow, Context{}); err == nil || !strings.Contains(err.Error(), "context canceled") {
+		t.Errorf("Run() in an aborted run = %v, want it stopped", err)
+	}
+}
+
+func TestScanParsesTrivyReport(t *testing.T) {
+	report := `{"Results":[{"Target":"go.mod","Type":"gomod",` +
+		`"Packages":[{"Name":"example.com/mod","Relationship":"indirect"}],` +
+		`"Vulnerabilities":[{"VulnerabilityID":"CVE-2024-0002","PkgName":"example.com/mod",` +
+		`"InstalledVersion":"v1.0.0","FixedVersion":"v1.0.1","CVSS":{"nvd":{"V3Score":9.1}}}]}]}`
+	plugins := []config.PluginConfig{
+		{Name: "custom", Command: "echo", Args: []string{report}, Hooks: []string{HookScan}},
+		{Name: "notifier", Command: "false", Hooks: []string{HookComplete}},
+	}
+
+	vulns, err := Scan(plugins, Context{Module: "go.mod"})
+	if err != nil {
+		t.Fatalf("Scan() error: %v", err)
+	}
+	if len(vulns) != 1 {
+		t.Fatalf("got %d vulnerabilities, want 1", len(vulns))
+	}
+	if v := vulns[0]; v.VulnerabilityID != "CVE-2024-0002" || !v.Indirect || v.CVSSScore != 9.1 {
+		t.Errorf("unexpected vulnerability: %+v", v)
+	}
+}
+
+func TestCompleteCollectsPullRequests(t *testing.T) {
+	plugins := []config.PluginConfig{
+		{Name: "open-pr", Command: "echo", Args: []string{`{"pull_requests": ["https://github.com/org/repo/pull/7"]}`}, Hooks: []string{HookComplete}},
+		{Name: "notifier", Command: "echo", Args: []string{"sent to #security"}, Hooks: []string{HookComplete}},
+		{Name: "scanner", Command: "echo", Args: []string{`{"pull_requests": ["https://example.com/ignored"]}`}, Hooks: []string{HookScan}},
+	}
+
+	pullRequests, err := Complete(plugins, Context{Command: "update"})
+	if err != nil {
+		t.Fatalf("Complete() error: %v", err)
+	}
+	if len(pullRequests) != 1 || pullRequests[0] != "https://github.com/org/repo/pull/7" {
+		t.Errorf("Complete() = %v, want the pull request of open-pr", pullRequests)
+	}
+}
@@ -0,0 +1,68 @@
+// Package purl builds package URLs (https://github.com/package-url/purl-spec)
+// for Go modules, as used by VEX documents and SBOMs
+package purl
+
+import (
+	"strings"
+)
+
+// TypeGolang is the purl type of Go modules
+const TypeGolang = "golang"
+
+// Builder builds package URLs
+type Builder struct {
+	// PreserveCase keeps the case of module paths. The purl spec asks for
+	// lowercase golang namespaces and names, which is also what Trivy emits.
+	PreserveCase bool
+}
+
+// Golang returns the package URL of a Go module version. All path elements
+// but the last form the namespace; a major version suffix (/v2) or gopkg.in
+// selector (yaml.v3) stays part of the name, so each major version gets a
+// distinct purl. Path elements and version are percent-encoded, e.g. the
+// "+" of "+incompatible" versions.
+func (b Builder) Golang(modulePath, version string) string {
+	if !b.PreserveCase {
+		modulePath = strings.ToLower(modulePath)
+	}
+
+	var s strings.Builder
+	s.WriteString("pkg:" + TypeGolang + "/")
+	for i, segment := range strings.Split(strings.Trim(modulePath, "/"), "/") {
+		if i > 0 {
+			s.WriteByte('/')
+		}
+		s.WriteString(escape(segment))
+	}
+	if version != "" {
+		s.WriteString("@" + escape(version))
+	}
+	return s.String()
+}
+
+// Golang returns the package URL of a Go module version with the default Builder
+func Golang(modulePath, version string) string {
+	return Builder{}.Golang(modulePath, version)
+}
+
+// escape percent-encodes all but the unreserved characters of RFC 3986
+func escape(s string) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if isUnreserved(c) {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hex[c>>4])
+		b.WriteByte(hex[c&15])
+	}
+	return b.String()
+}
+
+func isUnreserved(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
@@ -0,0 +1,31 @@
+package purl
+
+import "testing"
+
+func TestGolang(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder Builder
+		path    string
+		version string
+		want    string
+	}{
+		{"simple", Builder{}, "golang.org/x/net", "v0.17.0", "pkg:golang/golang.org/x/net@v0.17.0"},
+		{"major version suffix", Builder{}, "github.com/go-chi/chi/v5", "v5.0.10", "pkg:golang/github.com/go-chi/chi/v5@v5.0.10"},
+		{"gopkg.in selector", Builder{}, "gopkg.in/yaml.v3", "v3.0.1", "pkg:golang/gopkg.in/yaml.v3@v3.0.1"},
+		{"incompatible", Builder{}, "github.com/docker/docker", "v24.0.7+incompatible", "pkg:golang/github.com/docker/docker@v24.0.7%2Bincompatible"},
+		{"lowercased", Builder{}, "github.com/Azure/go-autorest", "v14.2.0+incompatible", "pkg:golang/github.com/azure/go-autorest@v14.2.0%2Bincompatible"},
+		{"preserve case", Builder{PreserveCase: true}, "github.com/Azure/go-autorest", "v1.0.0", "pkg:golang/github.com/Azure/go-autorest@v1.0.0"},
+		{"special characters", Builder{}, "example.com/a b/c@d", "v1.0.0", "pkg:golang/example.com/a%20b/c%40d@v1.0.0"},
+		{"no version", Builder{}, "golang.org/x/net", "", "pkg:golang/golang.org/x/net"},
+		{"pseudo-version", Builder{}, "golang.org/x/crypto", "v0.0.0-20220214200702-86341886e292", "pkg:golang/golang.org/x/crypto@v0.0.0-20220214200702-86341886e292"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.builder.Golang(tt.path, tt.version); got != tt.want {
+				t.Errorf("Golang(%q, %q) = %q, want %q", tt.path, tt.version, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+// Package redact removes credentials from text that is printed or published
+package redact
+
+import "regexp"
+
+// Mask replaces the credentials removed
+const Mask = "********"
+
+// urlUserinfo matches the scheme and userinfo of URLs, e.g.
+// "https://user:password@" or "https://token@"
+var urlUserinfo = regexp.MustCompile(`\b([a-zA-Z][a-zA-Z0-9+.-]*://)[^/\s@]+@`)
+
+// URLs returns s with the userinfo of every URL in it, which may be a
+// username and password or a token, replaced by Mask
+func URLs(s string) string {
+	return urlUserinfo.ReplaceAllString(s, "${1}"+Mask+"@")
+}
@@ -0,0 +1,132 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// Fleet job statuses
+const (
+	FleetOK       = "ok"
+	FleetFindings = "findings"
+	FleetFailed   = "failed"
+)
+
+// Fleet aggregates the reports of the jobs of a fleet run into one report
+type Fleet struct {
+	GeneratedAt  time.Time         `json:"generated_at"`
+	Mode         string            `json:"mode"`
+	Summary      FleetSummary      `json:"summary"`
+	Repositories []FleetRepository `json:"repositories"`
+}
+
+// FleetSummary rolls up the jobs of a fleet run
+type FleetSummary struct {
+	Repositories int `json:"repositories"`
+	// Failed counts jobs that failed; their findings are missing
+	Failed int `json:"failed"`
+	// WithFindings counts jobs that found vulnerabilities (scan) or could
+	// not apply all updates (update)
+	WithFindings    int             `json:"with_findings"`
+	Vulnerabilities int             `json:"vulnerabilities"`
+	Fixed           int             `json:"fixed"`
+	Unfixed         int             `json:"unfixed"`
+	BySeverity      []SeverityCount `json:"by_severity"`
+	PullRequests    int             `json:"pull_requests"`
+}
+
+// FleetRepository is the outcome of the job of one repository
+type FleetRepository struct {
+	Repo     string `json:"repo"`
+	Status   string `json:"status"`
+	ExitCode int    `json:"exit_code"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+
+	// Vulnerabilities counts the vulnerabilities found above the CVSS
+	// threshold, Fixed those an update run fixed, eliminated or mitigated,
+	// and Unfixed those without a fixed version
+	Vulnerabilities int      `json:"vulnerabilities"`
+	Fixed           int      `json:"fixed"`
+	Unfixed         int      `json:"unfixed"`
+	PullRequests    []string `json:"pull_requests,omitempty"`
+
+	// Report is the report of the job, missing if it failed before writing one
+	Report *Report `json:"report,omitempty"`
+}
+
+// NewFleet returns an empty fleet report of a run in mode (scan or update)
+func NewFleet(mode string) *Fleet {
+	f := &Fleet{GeneratedAt: time.Now().UTC(), Mode: mode, Repositories: []FleetRepository{}}
+	for _, severity := range Severities {
+		f.Summary.BySeverity = append(f.Summary.BySeverity, SeverityCount{Severity: severity})
+	}
+	return f
+}
+
+// Add adds the job of a repository, taking its counts from its report
+func (f *Fleet) Add(repo FleetRepository) {
+	if r := repo.Report; r != nil {
+		repo.Vulnerabilities = r.Summary.Vulnerabilities
+		repo.Unfixed = r.Summary.Unfixed
+		if r.Run != nil {
+			repo.Fixed = r.Run.Fixed + r.Run.Eliminated + r.Run.Mitigated
+		}
+		repo.PullRequests = r.PullRequests
+		for i, s := range r.Summary.BySeverity {
+			if i < len(f.Summary.BySeverity) && f.Summary.BySeverity[i].Severity == s.Severity {
+				f.Summary.BySeverity[i].Count += s.Count
+			}
+		}
+	}
+
+	f.Summary.Repositories++
+	switch repo.Status {
+	case FleetFailed:
+		f.Summary.Failed++
+	case FleetFindings:
+		f.Summary.WithFindings++
+	}
+	f.Summary.Vulnerabilities += repo.Vulnerabilities
+	f.Summary.Fixed += repo.Fixed
+	f.Summary.Unfixed += repo.Unfixed
+	f.Summary.PullRequests += len(repo.PullRequests)
+	f.Repositories = append(f.Repositories, repo)
+}
+
+// Write renders the fleet report in the given format
+func (f *Fleet) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(f)
+	case FormatHTML:
+		tmpl, err := template.New("fleet.html.tmpl").Funcs(template.FuncMap{
+			"severityClass": func(s string) string { return strings.ToLower(Severity(s)) },
+		}).ParseFS(templates, "templates/fleet.html.tmpl")
+		if err != nil {
+			return fmt.Errorf("failed to parse fleet report template: %w", err)
+		}
+		if err := tmpl.Execute(w, f); err != nil {
+			return fmt.Errorf("failed to render fleet report: %w", err)
+		}
+		return nil
+	case FormatMarkdown:
+		tmpl, err := texttemplate.New("fleet.md.tmpl").Funcs(templateFuncs).ParseFS(templates, "templates/fleet.md.tmpl")
+		if err != nil {
+			return fmt.Errorf("failed to parse fleet summary template: %w", err)
+		}
+		if err := tmpl.Execute(w, f); err != nil {
+			return fmt.Errorf("failed to render fleet summary: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown report format %q (valid: json, html, markdown)", format)
+	}
+}
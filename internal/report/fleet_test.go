@@ -0,0 +1,66 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestFleet(t *testing.T) {
+	updated := New(".", 7.0, testResults())
+	updated.Run = &RunSummary{Fixed: 2, Eliminated: 1}
+	updated.PullRequests = []string{"https://github.com/org/api/pull/7"}
+
+	f := NewFleet("update")
+	f.Add(FleetRepository{Repo: "https://github.com/org/api.git", Status: FleetFindings, ExitCode: 2, Report: updated})
+	f.Add(FleetRepository{Repo: "https://github.com/org/web.git", Status: FleetOK, Report: New(".", 7.0, nil)})
+	f.Add(FleetRepository{Repo: "https://github.com/org/old.git", Status: FleetFailed, ExitCode: 1, Error: "clone | failed"})
+
+	want := FleetSummary{Repositories: 3, Failed: 1, WithFindings: 1, Vulnerabilities: 3, Fixed: 3, Unfixed: 1, PullRequests: 1}
+	got := f.Summary
+	got.BySeverity = nil
+	if got.Repositories != want.Repositories || got.Failed != want.Failed || got.WithFindings != want.WithFindings ||
+		got.Vulnerabilities != want.Vulnerabilities || got.Fixed != want.Fixed || got.Unfixed != want.Unfixed ||
+		got.PullRequests != want.PullRequests {
+		t.Errorf("summary = %+v, want %+v", got, want)
+	}
+	if f.Summary.BySeverity[0] != (SeverityCount{Severity: "CRITICAL", Count: 1}) {
+		t.Errorf("by severity = %+v", f.Summary.BySeverity)
+	}
+
+	var md bytes.Buffer
+	if err := f.Write(&md, FormatMarkdown); err != nil {
+		t.Fatalf("Write(markdown) error = %v", err)
+	}
+	for _, want := range []string{
+		"### go-autobump fleet update results",
+		"**3** repositories: 1 failed, 1 with findings. Fixed **3** vulnerabilities, 1 without a fix remain, 1 pull request(s).",
+		"| https://github.com/org/api.git | findings | 3 | 3 | 1 | https://github.com/org/api/pull/7 |",
+		"| https://github.com/org/old.git | failed (clone \\| failed) | 0 | 0 | 0 |  |",
+	} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("Markdown fleet report does not contain %q:\n%s", want, md.String())
+		}
+	}
+
+	var html bytes.Buffer
+	if err := f.Write(&html, FormatHTML); err != nil {
+		t.Fatalf("Write(html) error = %v", err)
+	}
+	if !strings.Contains(html.String(), `<a href="https://github.com/org/api/pull/7">`) {
+		t.Error("HTML fleet report does not link the pull request")
+	}
+
+	var data bytes.Buffer
+	if err := f.Write(&data, FormatJSON); err != nil {
+		t.Fatalf("Write(json) error = %v", err)
+	}
+	var decoded Fleet
+	if err := json.Unmarshal(data.Bytes(), &decoded); err != nil {
+		t.Fatalf("JSON fleet report does not parse: %v", err)
+	}
+	if len(decoded.Repositories) != 3 || decoded.Repositories[0].Report == nil {
+		t.Errorf("JSON fleet report repositories = %+v", decoded.Repositories)
+	}
+}
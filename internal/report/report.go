@@ -0,0 +1,359 @@
+// Package report builds shareable reports from scan results
+package report
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/tamcore/go-autobump/internal/gomod"
+	"github.com/tamcore/go-autobump/internal/risk"
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+// Report formats
+const (
+	FormatJSON     = "json"
+	FormatHTML     = "html"
+	FormatMarkdown = "markdown"
+)
+
+// Severities lists Trivy severities from most to least severe
+var Severities = []string{"CRITICAL", "HIGH", "MEDIUM", "LOW", "UNKNOWN"}
+
+//go:embed templates/*.tmpl
+var templates embed.FS
+
+// Report is the result model shared by all report formats
+type Report struct {
+	GeneratedAt   time.Time `json:"generated_at"`
+	Path          string    `json:"path"`
+	CVSSThreshold float64   `json:"cvss_threshold"`
+	Summary       Summary   `json:"summary"`
+	Modules       []Module  `json:"modules"`
+
+	// Updates lists the update attempts of an update run
+	Updates []Update `json:"updates,omitempty"`
+
+	// Approvals lists major version bumps awaiting a human decision
+	Approvals []Approval `json:"approvals_required,omitempty"`
+
+	// Run rolls up an update run
+	Run *RunSummary `json:"run,omitempty"`
+
+	// PullRequests are the URLs of the pull/merge requests complete plugins
+	// opened or updated with the changes of the run
+	PullRequests []string `json:"pull_requests,omitempty"`
+
+	// BuilderImages lists the vulnerable or end-of-life golang images
+	// Dockerfiles build with
+	BuilderImages []BuilderImage `json:"builder_images,omitempty"`
+
+	// Incidents lists the module downloads the go command refused because
+	// they do not match their recorded checksum, which abort the run
+	Incidents []Incident `json:"incidents,omitempty"`
+}
+
+// Incident is a module download that does not match the checksum recorded
+// in go.sum or the checksum database, with the details needed to
+// investigate it
+type Incident struct {
+	// Module is the go.mod file being updated
+	Module string `json:"module"`
+	// VulnerabilityID is the vulnerability being fixed, if known
+	VulnerabilityID string `json:"vulnerability,omitempty"`
+	// Dependency and Version identify the refused download
+	Dependency string `json:"dependency"`
+	Version    string `json:"version"`
+	// GoMod is set if the go.mod file of Dependency mismatched rather than
+	// its zip
+	GoMod bool `json:"go_mod,omitempty"`
+	// Downloaded is the hash of the content that was served, Expected the
+	// hash Source ("go.sum" or the checksum database) records
+	Downloaded string `json:"downloaded"`
+	Expected   string `json:"expected"`
+	Source     string `json:"source"`
+	// Env holds the go environment deciding where modules and checksums
+	// are fetched from, e.g. GOPROXY and GOSUMDB
+	Env        map[string]string `json:"env,omitempty"`
+	DetectedAt time.Time         `json:"detected_at"`
+	// Error is the full error of the go command
+	Error string `json:"error"`
+}
+
+// BuilderImage is a golang image of a Dockerfile FROM line whose Go version
+// is vulnerable or no longer supported
+type BuilderImage struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+	// Tag is the Go version of the image tag, e.g. "1.22" or "1.22.3"
+	Tag string `json:"tag"`
+	// Version is the Go release the tag stands for
+	Version         string   `json:"version"`
+	Vulnerabilities []string `json:"vulnerabilities,omitempty"`
+	EOL             bool     `json:"eol,omitempty"`
+	// Target is the tag the image is bumped to, empty if no release fixes it
+	Target string `json:"target,omitempty"`
+	Bumped bool   `json:"bumped,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Approval is a major version bump that needs a human decision
+type Approval struct {
+	Module          string `json:"module"`
+	VulnerabilityID string `json:"vulnerability"`
+	Package         string `json:"package"`
+	// Dependency is the module to bump, the direct dependency pulling in
+	// the fix for indirect vulnerabilities
+	Dependency string `json:"dependency"`
+	From       string `json:"from"`
+	To         string `json:"to"`
+}
+
+// RunSummary rolls up the outcome of an update run across all modules
+type RunSummary struct {
+	// Modules is the number of go.mod files processed
+	Modules int `json:"modules"`
+	// ModulesEmpty counts go.mod files skipped because their module has
+	// no packages
+	ModulesEmpty int `json:"modules_empty,omitempty"`
+	// Fixed counts vulnerabilities updated to their fixed version
+	Fixed int `json:"fixed"`
+	// Eliminated counts vulnerabilities fixed by removing a module that is no
+	// longer needed
+	Eliminated int `json:"eliminated,omitempty"`
+	// Mitigated counts vulnerabilities without a fixed release mitigated by
+	// replacing their module with a patched fork or commit
+	Mitigated int `json:"mitigated,omitempty"`
+	// Aligned counts requirements raised to match the version another
+	// module was updated to
+	Aligned int `json:"aligned,omitempty"`
+	// Bumped counts direct dependencies bumped to their latest patch release
+	// without a vulnerability calling for it
+	Bumped int `json:"bumped,omitempty"`
+	// Failed counts updates that failed or were rolled back
+	Failed int `json:"failed"`
+	// Unfixed counts vulnerabilities without a fixed version
+	Unfixed int `json:"unfixed"`
+	// MajorsSkipped counts updates skipped because they need a major version bump
+	MajorsSkipped int `json:"majors_skipped"`
+	// ApprovalsRequired counts major bumps recorded for a human decision
+	ApprovalsRequired int `json:"approvals_required,omitempty"`
+	// Planned counts updates a dry run would apply
+	Planned int `json:"planned,omitempty"`
+	// Duration is the wall time of the run, e.g. "1m12s"
+	Duration string `json:"duration"`
+	// Stopped is set when the run stopped early ("timed out",
+	// "interrupted" or "aborted on a checksum mismatch"), leaving ModulesSkipped modules unprocessed
+	Stopped        string `json:"stopped,omitempty"`
+	ModulesSkipped int    `json:"modules_skipped,omitempty"`
+	// Incidents counts checksum mismatches; the first one aborts the run
+	Incidents int `json:"incidents,omitempty"`
+	// Risk is the risk score before and after the run, if requested
+	Risk *risk.Delta `json:"risk,omitempty"`
+}
+
+// Print writes the summary as human-readable text
+func (s *RunSummary) Print(w io.Writer) {
+	_, _ = fmt.Fprintf(w, "\nSummary: %d module(s) processed in %s\n", s.Modules, s.Duration)
+	if s.ModulesEmpty > 0 {
+		_, _ = fmt.Fprintf(w, "  Empty skipped:  %d\n", s.ModulesEmpty)
+	}
+	if s.Planned > 0 {
+		_, _ = fmt.Fprintf(w, "  Would update:   %d\n", s.Planned)
+	}
+	_, _ = fmt.Fprintf(w, "  Fixed:          %d\n", s.Fixed)
+	if s.Eliminated > 0 {
+		_, _ = fmt.Fprintf(w, "  Eliminated:     %d\n", s.Eliminated)
+	}
+	if s.Mitigated > 0 {
+		_, _ = fmt.Fprintf(w, "  Mitigated:      %d\n", s.Mitigated)
+	}
+	if s.Aligned > 0 {
+		_, _ = fmt.Fprintf(w, "  Aligned:        %d\n", s.Aligned)
+	}
+	if s.Bumped > 0 {
+		_, _ = fmt.Fprintf(w, "  Bumped:         %d\n", s.Bumped)
+	}
+	_, _ = fmt.Fprintf(w, "  Failed:         %d\n", s.Failed)
+	if s.Incidents > 0 {
+		_, _ = fmt.Fprintf(w, "  Incidents:      %d (checksum mismatch)\n", s.Incidents)
+	}
+	_, _ = fmt.Fprintf(w, "  No fix:         %d\n", s.Unfixed)
+	_, _ = fmt.Fprintf(w, "  Major skipped:  %d\n", s.MajorsSkipped)
+	if s.ApprovalsRequired > 0 {
+		_, _ = fmt.Fprintf(w, "  Needs approval: %d\n", s.ApprovalsRequired)
+	}
+	if s.Stopped != "" {
+		_, _ = fmt.Fprintf(w, "  Stopped early:  %s, %d module(s) not processed\n", s.Stopped, s.ModulesSkipped)
+	}
+	if s.Risk != nil {
+		_, _ = fmt.Fprintf(w, "  Risk score:     %.1f -> %.1f (%+.1f)\n", s.Risk.Before, s.Risk.After, s.Risk.Change)
+	}
+}
+
+// Update describes a single update attempt
+type Update struct {
+	Module           string `json:"module"`
+	VulnerabilityID  string `json:"vulnerability"`
+	Package          string `json:"package"`
+	InstalledVersion string `json:"installed_version"`
+	FixedVersion     string `json:"fixed_version"`
+	Error            string `json:"error,omitempty"`
+
+	// Advisory is the URL of the advisory of the vulnerability, if known
+	Advisory string `json:"advisory,omitempty"`
+
+	// Eliminated is set when the vulnerable module was removed because
+	// nothing needs it anymore, instead of being updated
+	Eliminated bool `json:"eliminated,omitempty"`
+
+	// Replacement is the patched fork or commit ("path version") the
+	// vulnerable module was replaced with, as no fixed release exists
+	Replacement string `json:"replacement,omitempty"`
+
+	// Proactive is set for a patch bump of a direct dependency without a
+	// known vulnerability (update --all-patch); VulnerabilityID is empty
+	Proactive bool `json:"proactive,omitempty"`
+
+	// Failure classifies a failed update, with a hint on how to resolve it
+	Failure string `json:"failure,omitempty"`
+	Hint    string `json:"hint,omitempty"`
+
+	// Suggestion is an AI-suggested remediation of a failed update
+	Suggestion string `json:"suggestion,omitempty"`
+
+	// ModuleChanges lists every module version the update changed,
+	// including modules moved along by minimal version selection
+	ModuleChanges []gomod.VersionChange `json:"module_changes,omitempty"`
+}
+
+// Summary aggregates the findings of all modules
+type Summary struct {
+	Modules         int             `json:"modules"`
+	Vulnerabilities int             `json:"vulnerabilities"`
+	Fixable         int             `json:"fixable"`
+	Unfixed         int             `json:"unfixed"`
+	BySeverity      []SeverityCount `json:"by_severity"`
+}
+
+// SeverityCount is the number of vulnerabilities of one severity
+type SeverityCount struct {
+	Severity string `json:"severity"`
+	Count    int    `json:"count"`
+}
+
+// Module holds the findings of a single go.mod file
+type Module struct {
+	Path            string                `json:"path"`
+	Fixable         int                   `json:"fixable"`
+	Unfixed         int                   `json:"unfixed"`
+	Vulnerabilities []trivy.Vulnerability `json:"vulnerabilities"`
+}
+
+// New builds a report from scan results
+func New(path string, threshold float64, results []trivy.ScanResult) *Report {
+	r := &Report{
+		GeneratedAt:   time.Now().UTC(),
+		Path:          path,
+		CVSSThreshold: threshold,
+		Modules:       []Module{},
+	}
+
+	counts := make(map[string]int)
+	for _, result := range results {
+		module := Module{Path: result.Target, Vulnerabilities: result.Vulnerabilities}
+		for _, vuln := range result.Vulnerabilities {
+			if trivy.HasFixedVersion(vuln) {
+				module.Fixable++
+			} else {
+				module.Unfixed++
+			}
+			counts[Severity(vuln.Severity)]++
+		}
+
+		r.Modules = append(r.Modules, module)
+		r.Summary.Modules++
+		r.Summary.Vulnerabilities += len(result.Vulnerabilities)
+		r.Summary.Fixable += module.Fixable
+		r.Summary.Unfixed += module.Unfixed
+	}
+
+	for _, severity := range Severities {
+		r.Summary.BySeverity = append(r.Summary.BySeverity, SeverityCount{Severity: severity, Count: counts[severity]})
+	}
+
+	return r
+}
+
+// Severity normalizes a Trivy severity to one of Severities
+func Severity(severity string) string {
+	severity = strings.ToUpper(severity)
+	for _, s := range Severities {
+		if s == severity {
+			return s
+		}
+	}
+	return "UNKNOWN"
+}
+
+// Write renders the report in the given format
+func (r *Report) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	case FormatHTML:
+		return r.writeHTML(w)
+	case FormatMarkdown:
+		return r.writeMarkdown(w)
+	default:
+		return fmt.Errorf("unknown report format %q (valid: json, html, markdown)", format)
+	}
+}
+
+// writeHTML renders a standalone HTML page; charts are inline SVG so the
+// file has no external dependencies and can be archived as audit evidence
+func (r *Report) writeHTML(w io.Writer) error {
+	tmpl, err := template.New("report.html.tmpl").Funcs(template.FuncMap{
+		"percent":       percent,
+		"barWidth":      func(count, total int) float64 { return percent(count, total) * barMaxWidth / 100 },
+		"barY":          func(i int) int { return i * barHeight },
+		"add":           func(a, b int) int { return a + b },
+		"severityClass": func(s string) string { return strings.ToLower(Severity(s)) },
+		"join":          strings.Join,
+		"date":          date,
+		"cweURL":        cweURL,
+		"host":          host,
+		"fixed": func(v trivy.Vulnerability) bool {
+			return trivy.HasFixedVersion(v)
+		},
+	}).ParseFS(templates, "templates/report.html.tmpl")
+	if err != nil {
+		return fmt.Errorf("failed to parse report template: %w", err)
+	}
+
+	if err := tmpl.Execute(w, r); err != nil {
+		return fmt.Errorf("failed to render report: %w", err)
+	}
+	return nil
+}
+
+// Severity chart geometry in pixels
+const (
+	barHeight   = 24
+	barMaxWidth = 280
+)
+
+// percent returns part as a percentage of total, for chart dimensions
+func percent(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) * 100 / float64(total)
+}
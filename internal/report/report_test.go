@@ -0,0 +1,185 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+func testResults() []trivy.ScanResult {
+	published := time.Date(2024, 3, 5, 12, 0, 0, 0, time.UTC)
+	return []trivy.ScanResult{
+		{Target: "go.mod", Vulnerabilities: []trivy.Vulnerability{
+			{VulnerabilityID: "CVE-1", PkgName: "a", Severity: "CRITICAL", FixedVersion: "v1.2.3", PrimaryURL: "https://avd.aquasec.com/nvd/cve-1",
+				PublishedDate: &published, CweIDs: []string{"CWE-400"}, Exploit: trivy.ExploitWeaponized, References: []string{"https://github.com/advisories/GHSA-1"}},
+			{VulnerabilityID: "CVE-2", PkgName: "b", Severity: "high", ImportedBy: []string{"cmd/api", "internal/auth"}},
+		}},
+		{Target: "sub/go.mod", Vulnerabilities: []trivy.Vulnerability{
+			{VulnerabilityID: "CVE-3", PkgName: "<c>", Severity: "BOGUS", FixedVersion: "v2.0.0"},
+		}},
+	}
+}
+
+func TestNew(t *testing.T) {
+	r := New(".", 7.0, testResults())
+
+	if r.Summary.Modules != 2 || r.Summary.Vulnerabilities != 3 {
+		t.Errorf("summary = %+v", r.Summary)
+	}
+	if r.Summary.Fixable != 2 || r.Summary.Unfixed != 1 {
+		t.Errorf("fixable/unfixed = %d/%d, want 2/1", r.Summary.Fixable, r.Summary.Unfixed)
+	}
+
+	want := map[string]int{"CRITICAL": 1, "HIGH": 1, "UNKNOWN": 1}
+	for _, sc := range r.Summary.BySeverity {
+		if sc.Count != want[sc.Severity] {
+			t.Errorf("%s count = %d, want %d", sc.Severity, sc.Count, want[sc.Severity])
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	r := New(".", 7.0, testResults())
+
+	var html bytes.Buffer
+	if err := r.Write(&html, FormatHTML); err != nil {
+		t.Fatalf("Write(html) error = %v", err)
+	}
+	for _, want := range []string{
+		`<a href="https://avd.aquasec.com/nvd/cve-1">CVE-1</a>`,
+		"sub/go.mod",
+		"&lt;c&gt;",
+		"imported by cmd/api, internal/auth",
+		"<svg",
+	} {
+		if !strings.Contains(html.String(), want) {
+			t.Errorf("HTML report does not contain %q", want)
+		}
+	}
+
+	var data bytes.Buffer
+	if err := r.Write(&data, FormatJSON); err != nil {
+		t.Fatalf("Write(json) error = %v", err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(data.Bytes(), &decoded); err != nil {
+		t.Fatalf("JSON report does not parse: %v", err)
+	}
+	if len(decoded.Modules) != 2 {
+		t.Errorf("JSON report has %d modules, want 2", len(decoded.Modules))
+	}
+
+	if err := r.Write(&data, "pdf"); err == nil {
+		t.Error("Write(pdf) succeeded, want error")
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	r := New(".", 7.0, testResults())
+	r.Updates = []Update{
+		{Module: "go.mod", VulnerabilityID: "CVE-1", Package: "a", InstalledVersion: "v1.0.0", FixedVersion: "v1.2.3", Advisory: "https://avd.aquasec.com/nvd/cve-1"},
+		{Module: "go.mod", VulnerabilityID: "CVE-2", Package: "b", InstalledVersion: "v1.0.0", FixedVersion: "v2.0.0", Error: "major", Failure: "major-bump-required"},
+	}
+	r.Run = &RunSummary{Modules: 2, Fixed: 1, Failed: 1}
+	r.BuilderImages = []BuilderImage{
+		{File: "Dockerfile", Line: 1, Tag: "1.21", Version: "1.21.13", Vulnerabilities: []string{"GO-1"}, EOL: true, Target: "1.22", Bumped: true},
+	}
+	r.Incidents = []Incident{
+		{Module: "go.mod", Dependency: "example.com/dep", Version: "v1.0.1", GoMod: true, Downloaded: "h1:AAAA=", Expected: "h1:BBBB=", Source: "sum.golang.org"},
+	}
+
+	var out bytes.Buffer
+	if err := r.Write(&out, FormatMarkdown); err != nil {
+		t.Fatalf("Write(markdown) error = %v", err)
+	}
+	for _, want := range []string{
+		"### go-autobump update results",
+		"**3** vulnerabilities above CVSS 7 in 2 module(s)",
+		"| CRITICAL | 1 |",
+		"| CRITICAL, exploit weaponized | 2024-03-05 | [CWE-400](https://cwe.mitre.org/data/definitions/400.html) | [github.com](https://github.com/advisories/GHSA-1) |",
+		"| go.mod | [CVE-1](https://avd.aquasec.com/nvd/cve-1) | `a` | v1.0.0 | v1.2.3 | fixed |",
+		"| go.mod | CVE-2 | `b` | v1.0.0 | v2.0.0 | failed (major-bump-required) |",
+		"Fixed 1, failed 1, no fix 0, major bump skipped 0.",
+		"| Dockerfile:1 | `golang:1.21` | 1.21.13 | end of life, 1 vulnerabilities | `golang:1.22` (bumped) |",
+		"#### Security incidents",
+		"| go.mod | `example.com/dep@v1.0.1/go.mod` | `h1:AAAA=` | `h1:BBBB=` (sum.golang.org) |",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Markdown report does not contain %q:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "| MEDIUM |") {
+		t.Error("Markdown report lists severities without findings")
+	}
+}
+
+func TestWriteTemplate(t *testing.T) {
+	r := New(".", 7.0, testResults())
+	r.Updates = []Update{{Module: "go.mod", VulnerabilityID: "CVE-1", Package: "a", FixedVersion: "v1.2.3"}}
+
+	path := filepath.Join(t.TempDir(), "report.tmpl")
+	tmpl := `{{.Summary.Vulnerabilities}} found
+{{- range .Modules}}{{range .Vulnerabilities}}
+{{severity .Severity | lower}} {{.VulnerabilityID}}{{end}}{{end}}
+{{- range .Updates}}
+updated {{.Package}} to {{.FixedVersion}}{{end}}
+`
+	if err := os.WriteFile(path, []byte(tmpl), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := r.WriteTemplate(&out, path); err != nil {
+		t.Fatalf("WriteTemplate() error = %v", err)
+	}
+
+	want := "3 found\ncritical CVE-1\nhigh CVE-2\nunknown CVE-3\nupdated a to v1.2.3\n"
+	if out.String() != want {
+		t.Errorf("WriteTemplate() = %q, want %q", out.String(), want)
+	}
+}
+
+func TestRunSummary(t *testing.T) {
+	s := &RunSummary{Modules: 2, Fixed: 3, Failed: 1, Unfixed: 4, MajorsSkipped: 1, Duration: "1m12s"}
+
+	var text bytes.Buffer
+	s.Print(&text)
+	want := `
+Summary: 2 module(s) processed in 1m12s
+  Fixed:          3
+  Failed:         1
+  No fix:         4
+  Major skipped:  1
+`
+	if text.String() != want {
+		t.Errorf("Print() =\n%s\nwant\n%s", text.String(), want)
+	}
+
+	// A dry run also reports what it would update
+	s.Planned = 5
+	text.Reset()
+	s.Print(&text)
+	if !strings.Contains(text.String(), "  Would update:   5\n") {
+		t.Errorf("Print() of a dry run =\n%s\nwant the planned updates", text.String())
+	}
+
+	r := New(".", 7.0, testResults())
+	r.Run = s
+	var data bytes.Buffer
+	if err := r.Write(&data, FormatJSON); err != nil {
+		t.Fatalf("Write(json) error = %v", err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(data.Bytes(), &decoded); err != nil {
+		t.Fatalf("JSON report does not parse: %v", err)
+	}
+	if decoded.Run == nil || *decoded.Run != *s {
+		t.Errorf("JSON report run = %+v, want %+v", decoded.Run, s)
+	}
+}
@@ -0,0 +1,94 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// templateFuncs are available to user-supplied templates in addition to the
+// text/template builtins
+var templateFuncs = template.FuncMap{
+	"upper":    strings.ToUpper,
+	"lower":    strings.ToLower,
+	"join":     strings.Join,
+	"repeat":   strings.Repeat,
+	"replace":  strings.ReplaceAll,
+	"severity": Severity,
+	"percent":  percent,
+	"date":     date,
+	"cweURL":   cweURL,
+	"host":     host,
+	"json": func(v any) (string, error) {
+		data, err := json.MarshalIndent(v, "", "  ")
+		return string(data), err
+	},
+}
+
+// date formats t as YYYY-MM-DD, or returns "" if it is unknown
+func date(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.Format(time.DateOnly)
+}
+
+// cweURL returns the URL of the MITRE definition of a CWE ID such as
+// "CWE-79", or "" if id is not one
+func cweURL(id string) string {
+	n, ok := strings.CutPrefix(strings.ToUpper(id), "CWE-")
+	if !ok || n == "" || strings.Trim(n, "0123456789") != "" {
+		return ""
+	}
+	return "https://cwe.mitre.org/data/definitions/" + n + ".html"
+}
+
+// host returns the host of a reference URL to label a link with, or the URL
+// itself if it cannot be parsed
+func host(ref string) string {
+	u, err := url.Parse(ref)
+	if err != nil || u.Host == "" {
+		return ref
+	}
+	return strings.TrimPrefix(u.Host, "www.")
+}
+
+// WriteTemplate renders the report with the Go text/template at path. The
+// template receives the Report as its data, so it can use the same fields
+// as the JSON format.
+func (r *Report) WriteTemplate(w io.Writer, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read template: %w", err)
+	}
+
+	tmpl, err := template.New(filepath.Base(path)).Funcs(templateFuncs).Parse(string(data))
+	if err != nil {
+		return fmt.Errorf("failed to parse template %s: %w", path, err)
+	}
+
+	if err := tmpl.Execute(w, r); err != nil {
+		return fmt.Errorf("failed to render template %s: %w", path, err)
+	}
+	return nil
+}
+
+// writeMarkdown renders a summary in GitHub-flavored Markdown, e.g. for a
+// pull request comment
+func (r *Report) writeMarkdown(w io.Writer) error {
+	tmpl, err := template.New("summary.md.tmpl").Funcs(templateFuncs).ParseFS(templates, "templates/summary.md.tmpl")
+	if err != nil {
+		return fmt.Errorf("failed to parse summary template: %w", err)
+	}
+
+	if err := tmpl.Execute(w, r); err != nil {
+		return fmt.Errorf("failed to render summary: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+// Package risk condenses vulnerabilities into a single risk score, so the
+// impact of a run can be reported as one number
+package risk
+
+import (
+	"math"
+	"strings"
+
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+// KEVFactor multiplies the risk of a vulnerability known to be exploited
+const KEVFactor = 2
+
+// PublicExploitFactor multiplies the risk of a vulnerability with published
+// exploit code that is not known to be exploited
+const PublicExploitFactor = 1.5
+
+// severityScores estimates a CVSS score for vulnerabilities without one
+var severityScores = map[string]float64{
+	"CRITICAL": 9.0,
+	"HIGH":     7.5,
+	"MEDIUM":   5.0,
+	"LOW":      2.5,
+}
+
+// Score returns the risk of a single vulnerability: its CVSS score (or an
+// estimate from its severity), increased by up to 100% by its EPSS
+// probability and multiplied by KEVFactor if it is known to be exploited, or
+// by PublicExploitFactor if exploit code is published
+func Score(vuln trivy.Vulnerability) float64 {
+	base := vuln.CVSSScore
+	if base == 0 {
+		base = severityScores[strings.ToUpper(vuln.Severity)]
+	}
+
+	score := base * (1 + vuln.EPSS)
+	switch {
+	case vuln.KEV || vuln.Exploit == trivy.ExploitWeaponized:
+		score *= KEVFactor
+	case vuln.Exploit == trivy.ExploitPublic:
+		score *= PublicExploitFactor
+	}
+	return score
+}
+
+// Total returns the summed risk of all vulnerabilities in result
+func Total(result trivy.ScanResult) float64 {
+	var total float64
+	for _, vuln := range result.Vulnerabilities {
+		total += Score(vuln)
+	}
+	return total
+}
+
+// ModuleDelta is the risk of one module before and after a run
+type ModuleDelta struct {
+	Module string  `json:"module"`
+	Before float64 `json:"before"`
+	After  float64 `json:"after"`
+}
+
+// Delta is the risk of all modules before and after a run
+type Delta struct {
+	Before  float64       `json:"before"`
+	After   float64       `json:"after"`
+	Change  float64       `json:"change"`
+	Modules []ModuleDelta `json:"modules"`
+}
+
+// Compare scores the modules in before and their remaining vulnerabilities
+// in after, which are matched by target. Scores are rounded to one decimal.
+func Compare(before, after []trivy.ScanResult) *Delta {
+	remaining := make(map[string]trivy.ScanResult, len(after))
+	for _, result := range after {
+		remaining[result.Target] = result
+	}
+
+	delta := &Delta{Modules: []ModuleDelta{}}
+	for _, result := range before {
+		module := ModuleDelta{
+			Module: result.Target,
+			Before: round(Total(result)),
+			After:  round(Total(remaining[result.Target])),
+		}
+		delta.Modules = append(delta.Modules, module)
+		delta.Before += module.Before
+		delta.After += module.After
+	}
+	delta.Before = round(delta.Before)
+	delta.After = round(delta.After)
+	delta.Change = round(delta.After - delta.Before)
+	return delta
+}
+
+func round(score float64) float64 {
+	return math.Round(score*10) / 10
+}
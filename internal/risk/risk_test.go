@@ -0,0 +1,49 @@
+package risk
+
+import (
+	"testing"
+
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name string
+		vuln trivy.Vulnerability
+		want float64
+	}{
+		{"cvss", trivy.Vulnerability{CVSSScore: 7.5}, 7.5},
+		{"severity fallback", trivy.Vulnerability{Severity: "critical"}, 9},
+		{"epss", trivy.Vulnerability{CVSSScore: 8, EPSS: 0.5}, 12},
+		{"kev", trivy.Vulnerability{CVSSScore: 8, EPSS: 0.5, KEV: true}, 24},
+		{"weaponized exploit", trivy.Vulnerability{CVSSScore: 8, Exploit: trivy.ExploitWeaponized}, 16},
+		{"public exploit", trivy.Vulnerability{CVSSScore: 8, Exploit: trivy.ExploitPublic}, 12},
+		{"unknown", trivy.Vulnerability{Severity: "UNKNOWN"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Score(tt.vuln); got != tt.want {
+				t.Errorf("Score() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	before := []trivy.ScanResult{
+		{Target: "go.mod", Vulnerabilities: []trivy.Vulnerability{{CVSSScore: 9.8}, {CVSSScore: 5.3}}},
+		{Target: "tools/go.mod", Vulnerabilities: []trivy.Vulnerability{{CVSSScore: 7.5, KEV: true}}},
+	}
+	after := []trivy.ScanResult{
+		{Target: "go.mod", Vulnerabilities: []trivy.Vulnerability{{CVSSScore: 5.3}}},
+	}
+
+	delta := Compare(before, after)
+	if delta.Before != 30.1 || delta.After != 5.3 || delta.Change != -24.8 {
+		t.Errorf("Compare() = %.1f -> %.1f (%.1f), want 30.1 -> 5.3 (-24.8)", delta.Before, delta.After, delta.Change)
+	}
+	if len(delta.Modules) != 2 || delta.Modules[1].Before != 15 || delta.Modules[1].After != 0 {
+		t.Errorf("Compare() modules = %+v", delta.Modules)
+	}
+}
@@ -0,0 +1,116 @@
+//go:build linux
+
+package runner
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+)
+
+// cpuPeriod is the cpu.max period in microseconds the CPU quota applies to
+const cpuPeriod = 100000
+
+// cgroupSeq numbers the cgroups created by this process
+var cgroupSeq atomic.Int64
+
+// cgroup is the cgroup v2 a single command runs in
+type cgroup struct {
+	dir string
+	fd  *os.File
+}
+
+// newCgroup creates a cgroup below parent enforcing limits. The memory and
+// cpu controllers are enabled for the children of parent if possible; that
+// fails if processes run in parent itself, so it should be a directory
+// dedicated to go-autobump.
+func newCgroup(parent string, limits Limits) (*cgroup, error) {
+	var controllers []string
+	if limits.Memory > 0 {
+		controllers = append(controllers, "+memory")
+	}
+	if limits.CPUs > 0 {
+		controllers = append(controllers, "+cpu")
+	}
+	if len(controllers) > 0 {
+		// Already enabled controllers are accepted again, and any other
+		// failure shows when setting the limits
+		_ = os.WriteFile(filepath.Join(parent, "cgroup.subtree_control"), []byte(strings.Join(controllers, " ")), 0)
+	}
+
+	dir := filepath.Join(parent, fmt.Sprintf("autobump-%d-%d", os.Getpid(), cgroupSeq.Add(1)))
+	if err := os.Mkdir(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cgroup: %w", err)
+	}
+	c := &cgroup{dir: dir}
+
+	if limits.Memory > 0 {
+		if err := c.set("memory.max", strconv.FormatInt(limits.Memory, 10)); err != nil {
+			c.remove()
+			return nil, err
+		}
+	}
+	if limits.CPUs > 0 {
+		quota := int64(limits.CPUs * cpuPeriod)
+		if err := c.set("cpu.max", fmt.Sprintf("%d %d", quota, cpuPeriod)); err != nil {
+			c.remove()
+			return nil, err
+		}
+	}
+
+	fd, err := os.Open(dir)
+	if err != nil {
+		c.remove()
+		return nil, fmt.Errorf("failed to open cgroup: %w", err)
+	}
+	c.fd = fd
+	return c, nil
+}
+
+// set writes a limit of the cgroup
+func (c *cgroup) set(file, value string) error {
+	if err := os.WriteFile(filepath.Join(c.dir, file), []byte(value), 0); err != nil {
+		return fmt.Errorf("failed to set %s of cgroup %s (is the controller enabled in cgroup.subtree_control of its parent?): %w",
+			file, filepath.Dir(c.dir), err)
+	}
+	return nil
+}
+
+// attach makes cmd start in the cgroup, so that none of its memory is
+// accounted elsewhere
+func (c *cgroup) attach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{UseCgroupFD: true, CgroupFD: int(c.fd.Fd())}
+}
+
+// oomKilled reports whether a process of the cgroup was killed for
+// exceeding its memory limit
+func (c *cgroup) oomKilled() bool {
+	data, err := os.ReadFile(filepath.Join(c.dir, "memory.events"))
+	if err != nil {
+		return false
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == "oom_kill" {
+			n, err := strconv.Atoi(fields[1])
+			return err == nil && n > 0
+		}
+	}
+	return false
+}
+
+// remove deletes the cgroup once its processes have exited
+func (c *cgroup) remove() {
+	if c.fd != nil {
+		_ = c.fd.Close()
+	}
+	_ = os.Remove(c.dir)
+}
@@ -0,0 +1,21 @@
+//go:build !linux
+
+package runner
+
+import (
+	"errors"
+	"os/exec"
+)
+
+// cgroup is unsupported outside Linux
+type cgroup struct{}
+
+func newCgroup(string, Limits) (*cgroup, error) {
+	return nil, errors.New("cgroup limits are only supported on Linux")
+}
+
+func (*cgroup) attach(*exec.Cmd) {}
+
+func (*cgroup) oomKilled() bool { return false }
+
+func (*cgroup) remove() {}
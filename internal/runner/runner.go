@@ -0,0 +1,338 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/modfile"
+)
+
+// Logical names of the tools go-autobump executes
+const (
+	Go    = "go"
+	Trivy = "trivy"
+	Git   = "git"
+)
+
+// Runner executes external commands. The name is one of the logical tool
+// names above, for which implementations decide which binary actually runs,
+// or another command such as a plugin.
+type Runner interface {
+	Run(ctx context.Context, dir, name string, args ...string) (stdout, stderr []byte, err error)
+}
+
+// Options configures the default exec-based runner
+type Options struct {
+	// GoBinary is the go command to run (default: "go" from PATH)
+	GoBinary string
+	// TrivyBinary is the trivy command to run (default: "trivy" from PATH)
+	TrivyBinary string
+	// GoToolchain sets GOTOOLCHAIN for go commands. ToolchainModule selects
+	// the toolchain directive of the go.mod in the command's directory; any
+	// other value (e.g. "go1.22.5" or "local") is passed as is. Empty leaves
+	// GOTOOLCHAIN to the environment.
+	GoToolchain string
+	// GoModCache sets GOMODCACHE for go commands, e.g. to a cache volume
+	// shared between runs. Empty leaves GOMODCACHE to the environment.
+	GoModCache string
+	// Timeouts bounds each invocation of a tool, by logical tool name
+	Timeouts map[string]time.Duration
+	// Limits bounds the resources of each invocation but of git
+	Limits Limits
+	// Env holds extra environment variables for every executed command
+	Env map[string]string
+}
+
+// Limits bounds the memory and CPUs of a command. go and trivy are Go
+// programs, so they are told about the limits via GOMEMLIMIT and GOMAXPROCS
+// and collect garbage more eagerly rather than grow beyond Memory. With
+// Cgroup set, each command also runs in a cgroup of its own below it, which
+// enforces the limits and kills the command if it exceeds Memory.
+type Limits struct {
+	// Memory is the memory limit in bytes; 0 means no limit
+	Memory int64
+	// CPUs is the number of CPUs, possibly fractional; 0 means no limit
+	CPUs float64
+	// Cgroup is a cgroup v2 directory delegated to the current user, e.g.
+	// "/sys/fs/cgroup/autobump" (Linux only)
+	Cgroup string
+}
+
+// ErrMemoryLimit is wrapped by the error of a command killed for exceeding
+// the memory limit of its cgroup
+var ErrMemoryLimit = errors.New("memory limit exceeded")
+
+// Exec runs commands using os/exec
+type Exec struct {
+	Options
+}
+
+// NewExec creates an exec-based runner
+func NewExec(opts Options) *Exec {
+	opts.GoBinary = absBinary(opts.GoBinary)
+	opts.TrivyBinary = absBinary(opts.TrivyBinary)
+	if opts.GoModCache != "" {
+		// go rejects a relative GOMODCACHE
+		if abs, err := filepath.Abs(opts.GoModCache); err == nil {
+			opts.GoModCache = abs
+		}
+	}
+	return &Exec{Options: opts}
+}
+
+// absBinary makes a relative binary path such as ./bin/trivy absolute, since
+// commands run in module directories where exec would resolve it relative to
+// the module. Bare names are left to the PATH lookup, which also finds .exe
+// files on Windows.
+func absBinary(binary string) string {
+	if binary == "" || filepath.Base(binary) == binary || filepath.IsAbs(binary) {
+		return binary
+	}
+	if abs, err := filepath.Abs(binary); err == nil {
+		return abs
+	}
+	return binary
+}
+
+// Run executes the named tool in dir and returns its output. If ctx ends
+// before the tool exits, the tool is killed and the error wraps ctx.Err().
+func (e *Exec) Run(ctx context.Context, dir, name string, args ...string) ([]byte, []byte, error) {
+	timeout := e.Timeouts[name]
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, e.binary(name), args...)
+	cmd.Dir = dir
+	cmd.Env = e.environ(ctx, e.toolEnv(dir, name, timeout))
+	if stdin, ok := ctx.Value(stdinKey{}).([]byte); ok {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+
+	var cg *cgroup
+	if e.Limits.Cgroup != "" && limited(name) {
+		var err error
+		if cg, err = newCgroup(e.Limits.Cgroup, e.Limits); err != nil {
+			return nil, nil, err
+		}
+		defer cg.remove()
+		cg.attach(cmd)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	switch {
+	case err != nil && ctx.Err() != nil:
+		err = fmt.Errorf("%w: %v", ctx.Err(), err)
+	case err != nil && cg != nil && cg.oomKilled():
+		err = fmt.Errorf("%w (%d bytes): %v", ErrMemoryLimit, e.Limits.Memory, err)
+	}
+	return stdout.Bytes(), stderr.Bytes(), err
+}
+
+// limited reports whether the resource limits apply to the named tool: to
+// all commands but the short-lived git commands
+func limited(name string) bool {
+	return name != Git
+}
+
+// binary maps a logical tool name to the configured binary
+func (e *Exec) binary(name string) string {
+	switch {
+	case name == Go && e.GoBinary != "":
+		return e.GoBinary
+	case name == Trivy && e.TrivyBinary != "":
+		return e.TrivyBinary
+	}
+	return name
+}
+
+// ToolchainModule makes go commands use the toolchain directive of the module
+// they run in
+const ToolchainModule = "module"
+
+// toolEnv returns the environment specific to running the named tool in dir
+// with timeout: GOTOOLCHAIN and GOMODCACHE for go, Trivy's own timeout,
+// which would otherwise stop scans after 5 minutes regardless of ours, and
+// the resource limits for all limited commands
+func (e *Exec) toolEnv(dir, name string, timeout time.Duration) map[string]string {
+	if !limited(name) {
+		return nil
+	}
+
+	env := e.limitsEnv()
+	if name == Trivy && timeout > 0 {
+		env["TRIVY_TIMEOUT"] = timeout.String()
+	}
+	if name != Go {
+		return env
+	}
+
+	if e.GoModCache != "" {
+		env["GOMODCACHE"] = e.GoModCache
+	}
+
+	toolchain := e.GoToolchain
+	if toolchain == ToolchainModule {
+		toolchain = moduleToolchain(dir)
+		if toolchain != "" {
+			// Still allow switching to a newer toolchain if an update
+			// raises the go directive beyond it
+			toolchain += "+auto"
+		}
+	}
+	if toolchain != "" {
+		env["GOTOOLCHAIN"] = toolchain
+	}
+	return env
+}
+
+// limitsEnv returns the Go runtime settings keeping a command within the
+// resource limits: a soft memory limit leaving headroom below Memory for
+// memory the runtime does not manage, and as many threads as CPUs
+func (e *Exec) limitsEnv() map[string]string {
+	env := make(map[string]string)
+	if e.Limits.Memory > 0 {
+		env["GOMEMLIMIT"] = strconv.FormatInt(e.Limits.Memory/10*9, 10)
+	}
+	if e.Limits.CPUs > 0 {
+		env["GOMAXPROCS"] = strconv.Itoa(int(math.Ceil(e.Limits.CPUs)))
+	}
+	return env
+}
+
+// moduleToolchain returns the toolchain directive of dir/go.mod, e.g.
+// "go1.22.5", or empty if there is none
+func moduleToolchain(dir string) string {
+	path := filepath.Join(dir, "go.mod")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	f, err := modfile.Parse(path, data, nil)
+	if err != nil || f.Toolchain == nil || f.Toolchain.Name == "default" {
+		return ""
+	}
+	return f.Toolchain.Name
+}
+
+// environ returns the process environment with base applied, followed by
+// the configured variables and those added to ctx with WithEnv
+func (e *Exec) environ(ctx context.Context, base map[string]string) []string {
+	extra, _ := ctx.Value(envKey{}).(map[string]string)
+	if len(base) == 0 && len(e.Env) == 0 && len(extra) == 0 {
+		return nil // inherit the process environment
+	}
+
+	env := os.Environ()
+	for _, vars := range []map[string]string{base, e.Env, extra} {
+		keys := make([]string, 0, len(vars))
+		for key := range vars {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			env = append(env, key+"="+vars[key])
+		}
+	}
+	return env
+}
+
+type envKey struct{}
+
+// WithEnv returns a context that adds env to the environment of the commands
+// run with it, taking precedence over the configured variables
+func WithEnv(ctx context.Context, env map[string]string) context.Context {
+	return context.WithValue(ctx, envKey{}, env)
+}
+
+type stdinKey struct{}
+
+// WithStdin returns a context that passes stdin to the standard input of the
+// commands run with it
+func WithStdin(ctx context.Context, stdin []byte) context.Context {
+	return context.WithValue(ctx, stdinKey{}, stdin)
+}
+
+var current Runner = NewExec(Options{})
+
+// base is the context of the commands run by the package-level helpers
+var base = context.Background()
+
+// SetContext makes the package-level helpers run commands with ctx, so that
+// cancelling it, e.g. when the run times out, kills running commands
+func SetContext(ctx context.Context) {
+	base = ctx
+}
+
+// Context returns the context of the commands run by the package-level helpers
+func Context() context.Context {
+	return base
+}
+
+// Scope bounds the commands run by the package-level helpers to timeout from
+// now, e.g. for all go commands of one update, until the returned function
+// restores the previous bound. A zero timeout adds no bound.
+func Scope(timeout time.Duration) (end func()) {
+	if timeout <= 0 {
+		return func() {}
+	}
+	previous := base
+	ctx, cancel := context.WithTimeout(previous, timeout)
+	base = ctx
+	return func() {
+		cancel()
+		base = previous
+	}
+}
+
+// Configure replaces the default runner with an exec runner using opts.
+// Environment variable names are upper-cased, since config keys are
+// case-insensitive.
+func Configure(opts Options) {
+	if len(opts.Env) > 0 {
+		env := make(map[string]string, len(opts.Env))
+		for key, value := range opts.Env {
+			env[strings.ToUpper(key)] = value
+		}
+		opts.Env = env
+	}
+	current = NewExec(opts)
+}
+
+// Set replaces the default runner, e.g. with a stub in tests
+func Set(r Runner) {
+	current = r
+}
+
+// Default returns the runner used by the package-level helpers
+func Default() Runner {
+	return current
+}
+
+// Run executes the named tool with the default runner
+func Run(dir, name string, args ...string) ([]byte, []byte, error) {
+	return current.Run(base, dir, name, args...)
+}
+
+// RunEnv executes the named tool with the default runner and env added to
+// its environment
+func RunEnv(dir string, env map[string]string, name string, args ...string) ([]byte, []byte, error) {
+	return current.Run(WithEnv(base, env), dir, name, args...)
+}
@@ -0,0 +1,163 @@
+package runner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExecBinary(t *testing.T) {
+	goBinary := filepath.Join(t.TempDir(), "go")
+	e := NewExec(Options{GoBinary: goBinary})
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{Go, goBinary},
+		{Trivy, "trivy"},
+		{Git, "git"},
+	}
+
+	for _, tt := range tests {
+		if got := e.binary(tt.name); got != tt.want {
+			t.Errorf("binary(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestExecBinaryRelative(t *testing.T) {
+	e := NewExec(Options{TrivyBinary: filepath.Join("bin", "trivy")})
+
+	want, err := filepath.Abs(filepath.Join("bin", "trivy"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := e.binary(Trivy); got != want {
+		t.Errorf("binary(%q) = %q, want %q", Trivy, got, want)
+	}
+}
+
+func TestConfigureUpperCasesEnv(t *testing.T) {
+	defer Set(NewExec(Options{}))
+
+	Configure(Options{Env: map[string]string{"gomodcache": "/cache"}})
+
+	env := Default().(*Exec).environ(context.Background(), nil)
+	if !slices.Contains(env, "GOMODCACHE=/cache") {
+		t.Errorf("environ() does not contain GOMODCACHE=/cache")
+	}
+}
+
+func TestWithEnvOverridesConfiguredEnv(t *testing.T) {
+	e := NewExec(Options{Env: map[string]string{"GOFLAGS": "-mod=vendor"}})
+
+	env := e.environ(WithEnv(context.Background(), map[string]string{"GOFLAGS": "-mod=mod"}), nil)
+	// The last occurrence of a variable wins
+	var last string
+	for _, v := range env {
+		if strings.HasPrefix(v, "GOFLAGS=") {
+			last = v
+		}
+	}
+	if last != "GOFLAGS=-mod=mod" {
+		t.Errorf("effective %s, want GOFLAGS=-mod=mod", last)
+	}
+}
+
+func TestToolchainEnv(t *testing.T) {
+	dir := t.TempDir()
+	goMod := "module example.com/app\n\ngo 1.22\n\ntoolchain go1.22.5\n"
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		toolchain string
+		tool      string
+		dir       string
+		want      string
+	}{
+		{"module", ToolchainModule, Go, dir, "go1.22.5+auto"},
+		{"module without directive", ToolchainModule, Go, t.TempDir(), ""},
+		{"explicit", "go1.23.1", Go, dir, "go1.23.1"},
+		{"not go", "go1.23.1", Trivy, dir, ""},
+		{"unset", "", Go, dir, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewExec(Options{GoToolchain: tt.toolchain})
+			if got := e.toolEnv(tt.dir, tt.tool, 0)["GOTOOLCHAIN"]; got != tt.want {
+				t.Errorf("GOTOOLCHAIN = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModCacheEnv(t *testing.T) {
+	e := NewExec(Options{GoModCache: "cache"})
+	want, err := filepath.Abs("cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := e.toolEnv("", Go, 0)["GOMODCACHE"]; got != want {
+		t.Errorf("GOMODCACHE = %q, want %q", got, want)
+	}
+	if got := e.toolEnv("", Trivy, 0)["GOMODCACHE"]; got != "" {
+		t.Errorf("GOMODCACHE for trivy = %q, want none", got)
+	}
+}
+
+func TestLimitsEnv(t *testing.T) {
+	e := NewExec(Options{Limits: Limits{Memory: 1000, CPUs: 1.5}})
+	for _, tool := range []string{Go, Trivy, "./plugin.sh"} {
+		env := e.toolEnv("", tool, 0)
+		if env["GOMEMLIMIT"] != "900" || env["GOMAXPROCS"] != "2" {
+			t.Errorf("%s: GOMEMLIMIT = %q, GOMAXPROCS = %q, want 900 and 2", tool, env["GOMEMLIMIT"], env["GOMAXPROCS"])
+		}
+	}
+	if env := e.toolEnv("", Git, 0); len(env) != 0 {
+		t.Errorf("git env = %v, want none", env)
+	}
+}
+
+// ctxRunner reports the context each command runs with
+type ctxRunner struct {
+	ctx context.Context
+}
+
+func (r *ctxRunner) Run(ctx context.Context, _, _ string, _ ...string) ([]byte, []byte, error) {
+	r.ctx = ctx
+	return nil, nil, nil
+}
+
+func TestScope(t *testing.T) {
+	stub := &ctxRunner{}
+	defer Set(Default())
+	Set(stub)
+
+	end := Scope(time.Minute)
+	_, _, _ = Run("", Go, "version")
+	if _, ok := stub.ctx.Deadline(); !ok {
+		t.Error("command in scope has no deadline")
+	}
+
+	end()
+	_, _, _ = Run("", Go, "version")
+	if _, ok := stub.ctx.Deadline(); ok {
+		t.Error("command after scope ended still has a deadline")
+	}
+}
+
+func TestExecTimeout(t *testing.T) {
+	e := NewExec(Options{Timeouts: map[string]time.Duration{Trivy: 10 * time.Minute}})
+	if got := e.toolEnv("", Trivy, e.Timeouts[Trivy])["TRIVY_TIMEOUT"]; got != "10m0s" {
+		t.Errorf("TRIVY_TIMEOUT = %q, want 10m0s", got)
+	}
+}
@@ -6,7 +6,8 @@ import (
 )
 
 // DiscoverGoModFiles recursively searches for all go.mod files under the given path
-// excludePatterns is a list of glob patterns to exclude (matched against relative paths)
+// excludePatterns is a list of glob patterns to exclude (matched against relative paths,
+// with "**" matching any number of directories)
 func DiscoverGoModFiles(root string, excludePatterns ...string) ([]string, error) {
 	var goModFiles []string
 
@@ -21,8 +22,9 @@ func DiscoverGoModFiles(root string, excludePatterns ...string) ([]string, error
 			return err
 		}
 
-		// Skip hidden directories and common non-project directories
-		if d.IsDir() {
+		// Skip hidden directories and common non-project directories below
+		// the root, which itself may be named anything
+		if d.IsDir() && path != absRoot {
 			name := d.Name()
 			if name == "vendor" || name == "node_modules" || name == ".git" || (len(name) > 0 && name[0] == '.') {
 				return filepath.SkipDir
@@ -31,29 +33,14 @@ func DiscoverGoModFiles(root string, excludePatterns ...string) ([]string, error
 		}
 
 		// Check for go.mod files
-		if d.Name() == "go.mod" {
+		if !d.IsDir() && d.Name() == "go.mod" {
 			// Get relative path for pattern matching
 			relPath, err := filepath.Rel(absRoot, path)
 			if err != nil {
 				relPath = path
 			}
 
-			// Check if path matches any exclude pattern
-			excluded := false
-			for _, pattern := range excludePatterns {
-				if matched, _ := filepath.Match(pattern, relPath); matched {
-					excluded = true
-					break
-				}
-				// Also try matching against the directory path
-				dirPath := filepath.Dir(relPath)
-				if matched, _ := filepath.Match(pattern, dirPath); matched {
-					excluded = true
-					break
-				}
-			}
-
-			if !excluded {
+			if !excluded(relPath, excludePatterns) {
 				goModFiles = append(goModFiles, path)
 			}
 		}
@@ -0,0 +1,58 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExcluded(t *testing.T) {
+	tests := []struct {
+		relPath  string
+		patterns []string
+		want     bool
+	}{
+		{"examples/a/go.mod", []string{"examples/*/go.mod"}, true},
+		{"examples/a/go.mod", []string{"examples/*"}, true},
+		{"examples/a/b/go.mod", []string{"examples/*"}, false},
+		{"examples/a/b/go.mod", []string{"examples/**"}, true},
+		{"testdata/x/y/z/go.mod", []string{"**/z"}, true},
+		{"tools/go.mod", []string{"./tools"}, true},
+		{"go.mod", []string{"examples/**"}, false},
+		{"cmd/go.mod", nil, false},
+	}
+
+	for _, tt := range tests {
+		// Walked paths use the OS separator, e.g. examples\a\go.mod on Windows
+		relPath := filepath.FromSlash(tt.relPath)
+		if got := excluded(relPath, tt.patterns); got != tt.want {
+			t.Errorf("excluded(%q, %q) = %v, want %v", relPath, tt.patterns, got, tt.want)
+		}
+	}
+}
+
+func TestDiscoverGoModFilesHiddenRoot(t *testing.T) {
+	root := filepath.Join(t.TempDir(), ".checkout")
+	for _, dir := range []string{"", "api", ".cache", "vendor/example.com/dep"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, dir, "go.mod"), []byte("module example.com/x\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := DiscoverGoModFiles(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(root, "api", "go.mod"), filepath.Join(root, "go.mod")}
+	if len(files) != len(want) {
+		t.Fatalf("DiscoverGoModFiles() = %v, want %v", files, want)
+	}
+	for i := range want {
+		if files[i] != want[i] {
+			t.Errorf("DiscoverGoModFiles()[%d] = %s, want %s", i, files[i], want[i])
+		}
+	}
+}
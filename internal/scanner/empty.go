@@ -0,0 +1,71 @@
+package scanner
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tamcore/go-autobump/internal/runner"
+)
+
+// SplitEmpty separates the modules that contain Go packages from empty
+// placeholder modules and tooling stubs, which have nothing to scan or update
+func SplitEmpty(goModFiles []string) (modules, empty []string) {
+	for _, goModFile := range goModFiles {
+		if HasPackages(goModFile) {
+			modules = append(modules, goModFile)
+		} else {
+			empty = append(empty, goModFile)
+		}
+	}
+	return modules, empty
+}
+
+// HasPackages reports whether the module of goModPath contains any package.
+// Modules without .go files are empty without running go; otherwise
+// "go list ./..." decides, which also excludes files that are never built,
+// e.g. tools.go files behind a "tools" build tag. If go list fails, the
+// module is assumed to have packages.
+func HasPackages(goModPath string) bool {
+	dir := GetModuleDir(goModPath)
+	if !hasGoFiles(dir) {
+		return false
+	}
+
+	stdout, _, err := runner.Run(dir, runner.Go, "list", "./...")
+	if err != nil {
+		return true
+	}
+	return strings.TrimSpace(string(stdout)) != ""
+}
+
+// hasGoFiles reports whether the module in dir has any .go file outside of
+// nested modules and the directories go ignores
+func hasGoFiles(dir string) bool {
+	found := false
+	_ = filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path == dir {
+				return nil
+			}
+			name := d.Name()
+			if name == "vendor" || name == "testdata" || name[0] == '.' || name[0] == '_' {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(d.Name(), ".go") {
+			found = true
+			return fs.SkipAll
+		}
+		return nil
+	})
+	return found
+}
@@ -0,0 +1,38 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHasGoFiles(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		want  bool
+	}{
+		{"empty", []string{"go.mod", "README.md"}, false},
+		{"package", []string{"go.mod", "internal/x/x.go"}, true},
+		{"only nested module", []string{"go.mod", "sub/go.mod", "sub/main.go"}, false},
+		{"only ignored dirs", []string{"go.mod", "testdata/x.go", "_examples/main.go", "vendor/a/a.go"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, file := range tt.files {
+				path := filepath.Join(dir, filepath.FromSlash(file))
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := hasGoFiles(dir); got != tt.want {
+				t.Errorf("hasGoFiles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
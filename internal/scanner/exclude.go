@@ -0,0 +1,50 @@
+package scanner
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// excluded reports whether the go.mod at relPath, relative to the scan root,
+// or its directory matches one of the exclude patterns. Paths and patterns
+// are compared with forward slashes, so patterns written for one OS also
+// match on Windows.
+func excluded(relPath string, patterns []string) bool {
+	relPath = filepath.ToSlash(relPath)
+	dir := path.Dir(relPath)
+	for _, pattern := range patterns {
+		pattern = strings.TrimPrefix(filepath.ToSlash(pattern), "./")
+		if matchGlob(pattern, relPath) || matchGlob(pattern, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob matches a slash-separated name against a glob pattern, in which
+// "**" matches any number of path segments
+func matchGlob(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if matched, _ := path.Match(pattern[0], name[0]); !matched {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
@@ -0,0 +1,229 @@
+// Package scorecard looks up the OpenSSF Scorecard scores of the source
+// repositories of Go modules from deps.dev, so that security updates do not
+// move to poorly maintained projects
+package scorecard
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tamcore/go-autobump/internal/httpclient"
+	"github.com/tamcore/go-autobump/internal/output"
+)
+
+// DefaultEndpoint is the deps.dev API
+const DefaultEndpoint = "https://api.deps.dev/v3"
+
+// errNotFound is returned by get for resources deps.dev does not know
+var errNotFound = errors.New("not found")
+
+// Score is the Scorecard result of the source repository of a module
+type Score struct {
+	// Project is the repository, e.g. "github.com/google/go-cmp"
+	Project string
+	// Overall is the aggregate score from 0 to 10
+	Overall float64
+	// Date is when Scorecard last checked the repository
+	Date string
+}
+
+// Client looks up scores from deps.dev. Each module and project is only
+// looked up once: the versions of a module are assumed to share their
+// source repository.
+type Client struct {
+	Endpoint   string
+	HTTPClient *http.Client
+
+	mu       sync.Mutex
+	projects map[string]string
+	scores   map[string]*Score
+}
+
+// NewClient creates a client of the deps.dev API
+func NewClient() *Client {
+	return &Client{
+		Endpoint:   DefaultEndpoint,
+		HTTPClient: httpclient.New(30 * time.Second),
+	}
+}
+
+// Lookup returns the score of the source repository of module at version,
+// or nil if deps.dev knows no repository or no score of it
+func (c *Client) Lookup(ctx context.Context, module, version string) (*Score, error) {
+	project, err := c.project(ctx, module, version)
+	if err != nil || project == "" {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	score, ok := c.scores[project]
+	c.mu.Unlock()
+	if ok {
+		return score, nil
+	}
+
+	var resp struct {
+		Scorecard *struct {
+			Date         string  `json:"date"`
+			OverallScore float64 `json:"overallScore"`
+		} `json:"scorecard"`
+	}
+	err = c.get(ctx, "/projects/"+url.PathEscape(project), &resp)
+	switch {
+	case errors.Is(err, errNotFound):
+	case err != nil:
+		return nil, fmt.Errorf("failed to look up the project %s on deps.dev: %w", project, err)
+	case resp.Scorecard != nil:
+		score = &Score{Project: project, Overall: resp.Scorecard.OverallScore, Date: resp.Scorecard.Date}
+	}
+
+	c.mu.Lock()
+	if c.scores == nil {
+		c.scores = make(map[string]*Score)
+	}
+	c.scores[project] = score
+	c.mu.Unlock()
+	return score, nil
+}
+
+// project returns the source repository deps.dev relates module at version
+// to, empty if there is none
+func (c *Client) project(ctx context.Context, module, version string) (string, error) {
+	c.mu.Lock()
+	project, ok := c.projects[module]
+	c.mu.Unlock()
+	if ok {
+		return project, nil
+	}
+
+	var resp struct {
+		RelatedProjects []struct {
+			ProjectKey struct {
+				ID string `json:"id"`
+			} `json:"projectKey"`
+			RelationType string `json:"relationType"`
+		} `json:"relatedProjects"`
+	}
+	err := c.get(ctx, "/systems/go/packages/"+url.PathEscape(module)+"/versions/"+url.PathEscape(version), &resp)
+	if err != nil && !errors.Is(err, errNotFound) {
+		return "", fmt.Errorf("failed to look up %s@%s on deps.dev: %w", module, version, err)
+	}
+	for _, p := range resp.RelatedProjects {
+		if p.RelationType == "SOURCE_REPO" {
+			project = p.ProjectKey.ID
+			break
+		}
+	}
+
+	c.mu.Lock()
+	if c.projects == nil {
+		c.projects = make(map[string]string)
+	}
+	c.projects[module] = project
+	c.mu.Unlock()
+	return project, nil
+}
+
+// get fetches path below the endpoint and decodes its JSON body into out
+func (c *Client) get(ctx context.Context, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", strings.TrimSuffix(c.Endpoint, "/")+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return errNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned status %d", path, resp.StatusCode)
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// LowScoreError reports a version whose source repository scores below the
+// floor
+type LowScoreError struct {
+	Module   string
+	Version  string
+	Score    Score
+	MinScore float64
+}
+
+func (e *LowScoreError) Error() string {
+	return fmt.Sprintf("OpenSSF Scorecard score %.1f of %s (source of %s@%s) is below the floor of %.1f",
+		e.Score.Overall, e.Score.Project, e.Module, e.Version, e.MinScore)
+}
+
+// Gate is a version policy holding updates to a version whose source
+// repository scores below MinScore: it refuses them if Block is set and
+// warns about them otherwise. Versions without a known score, and failed
+// lookups, are allowed with a warning.
+type Gate struct {
+	Client   *Client
+	MinScore float64
+	Block    bool
+
+	mu     sync.Mutex
+	warned map[string]bool
+}
+
+// Check returns a *LowScoreError if Block is set and module at version
+// scores below the floor
+func (g *Gate) Check(module, current, version string) error {
+	score, err := g.Client.Lookup(context.Background(), module, version)
+	if err != nil {
+		g.warnOnce(module, "cannot check the OpenSSF Scorecard score of %s: %v", module, err)
+		return nil
+	}
+	if score == nil {
+		g.warnOnce(module, "no OpenSSF Scorecard score of %s on deps.dev", module)
+		return nil
+	}
+	if score.Overall >= g.MinScore {
+		return nil
+	}
+
+	err = &LowScoreError{Module: module, Version: version, Score: *score, MinScore: g.MinScore}
+	if g.Block {
+		return err
+	}
+	g.warnOnce(module, "%v", err)
+	return nil
+}
+
+// warnOnce warns about module once per run, as a policy is consulted for
+// each candidate version of a module
+func (g *Gate) warnOnce(module, format string, args ...any) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.warned[module] {
+		return
+	}
+	if g.warned == nil {
+		g.warned = make(map[string]bool)
+	}
+	g.warned[module] = true
+	output.Warnf(format, args...)
+}
@@ -0,0 +1,62 @@
+package scorecard
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGate(t *testing.T) {
+	requests := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.EscapedPath()]++
+		switch r.URL.EscapedPath() {
+		case "/systems/go/packages/github.com%2Fgood%2Flib/versions/v1.2.0",
+			"/systems/go/packages/github.com%2Fgood%2Flib/versions/v1.3.0":
+			_, _ = w.Write([]byte(`{"relatedProjects": [
+				{"projectKey": {"id": "github.com/good/lib"}, "relationType": "ISSUE_TRACKER"},
+				{"projectKey": {"id": "github.com/good/lib"}, "relationType": "SOURCE_REPO"}
+			]}`))
+		case "/systems/go/packages/example.com%2Ffork/versions/v0.1.0":
+			_, _ = w.Write([]byte(`{"relatedProjects": [{"projectKey": {"id": "github.com/someone/fork"}, "relationType": "SOURCE_REPO"}]}`))
+		case "/projects/github.com%2Fgood%2Flib":
+			_, _ = w.Write([]byte(`{"scorecard": {"date": "2024-11-04T00:00:00Z", "overallScore": 7.4}}`))
+		case "/projects/github.com%2Fsomeone%2Ffork":
+			_, _ = w.Write([]byte(`{"scorecard": {"date": "2024-11-04T00:00:00Z", "overallScore": 2.1}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.Endpoint = server.URL
+	gate := &Gate{Client: client, MinScore: 5, Block: true}
+
+	if err := gate.Check("github.com/good/lib", "v1.1.0", "v1.2.0"); err != nil {
+		t.Errorf("Check(good) error = %v", err)
+	}
+	if err := gate.Check("github.com/good/lib", "v1.1.0", "v1.3.0"); err != nil {
+		t.Errorf("Check(good) error = %v", err)
+	}
+	err := gate.Check("example.com/fork", "", "v0.1.0")
+	if err == nil || !strings.Contains(err.Error(), "2.1 of github.com/someone/fork") {
+		t.Errorf("Check(fork) error = %v, want the score below the floor", err)
+	}
+	if err := gate.Check("example.com/unknown", "v1.0.0", "v1.0.1"); err != nil {
+		t.Errorf("Check(unknown) error = %v, want unknown modules allowed", err)
+	}
+
+	gate.Block = false
+	if err := gate.Check("example.com/fork", "", "v0.1.0"); err != nil {
+		t.Errorf("Check(fork) without Block error = %v", err)
+	}
+
+	if n := requests["/projects/github.com%2Fgood%2Flib"]; n != 1 {
+		t.Errorf("looked up the project %d times, want once", n)
+	}
+	if n := requests["/systems/go/packages/github.com%2Fgood%2Flib/versions/v1.3.0"]; n != 0 {
+		t.Errorf("looked up a second version of a module %d times, want the first version's project reused", n)
+	}
+}
@@ -0,0 +1,403 @@
+// Package snyk imports the results of "snyk test --json" for Go modules, so
+// that the vulnerabilities Snyk found can be remediated without running Trivy
+package snyk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tamcore/go-autobump/internal/gomod"
+	"github.com/tamcore/go-autobump/internal/trivy"
+	"golang.org/x/mod/semver"
+)
+
+// packageManager is how Snyk names Go modules projects
+const packageManager = "gomodules"
+
+// advisoryURL is where Snyk publishes its advisories, by Snyk ID
+const advisoryURL = "https://security.snyk.io/vuln/"
+
+// project is the part of a tested project in "snyk test --json" output
+// go-autobump uses. --all-projects outputs an array of them.
+type project struct {
+	Error             string          `json:"error"`
+	Path              string          `json:"path"`
+	PackageManager    string          `json:"packageManager"`
+	TargetFile        string          `json:"targetFile"`
+	DisplayTargetFile string          `json:"displayTargetFile"`
+	Vulnerabilities   []vulnerability `json:"vulnerabilities"`
+}
+
+// vulnerability is a Snyk finding, listed once per dependency path
+type vulnerability struct {
+	ID          string  `json:"id"`
+	Title       string  `json:"title"`
+	Description string  `json:"description"`
+	Severity    string  `json:"severity"`
+	CVSSScore   float64 `json:"cvssScore"`
+	CVSSv3      string  `json:"CVSSv3"`
+	Identifiers struct {
+		CVE  []string `json:"CVE"`
+		GHSA []string `json:"GHSA"`
+		CWE  []string `json:"CWE"`
+	} `json:"identifiers"`
+	References []struct {
+		URL string `json:"url"`
+	} `json:"references"`
+	// PackageName is the vulnerable package, ModuleName its module
+	PackageName      string     `json:"packageName"`
+	ModuleName       string     `json:"moduleName"`
+	Version          string     `json:"version"`
+	FixedIn          []string   `json:"fixedIn"`
+	PublicationTime  *time.Time `json:"publicationTime"`
+	ModificationTime *time.Time `json:"modificationTime"`
+	Semver           struct {
+		Vulnerable []string `json:"vulnerable"`
+	} `json:"semver"`
+}
+
+// finding is an imported vulnerability with the version ranges Snyk
+// considers affected
+type finding struct {
+	vuln       trivy.Vulnerability
+	vulnerable []string
+}
+
+// Report is an imported Snyk report. It implements trivy.Source: each scan
+// of a go.mod file returns the findings Snyk reported for it that still
+// affect the versions the module selects now, so that updates are verified
+// against the report.
+type Report struct {
+	root string
+	// findings holds the findings of each project by the path of its
+	// go.mod file relative to root, slash-separated
+	findings map[string][]finding
+}
+
+// Load reads the "snyk test --json" output at path. The projects in it are
+// matched to go.mod files by their target file relative to root, the
+// directory Snyk tested.
+func Load(path, root string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Snyk results: %w", err)
+	}
+	report, err := Parse(data, root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Snyk results %s: %w", path, err)
+	}
+	return report, nil
+}
+
+// Parse converts "snyk test --json" output, a single project or the array
+// --all-projects outputs, into a Report. Projects other than Go modules are
+// ignored; a project Snyk failed to test is an error, as its vulnerabilities
+// would silently be missing.
+func Parse(data []byte, root string) (*Report, error) {
+	var projects []project
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		if err := json.Unmarshal(data, &projects); err != nil {
+			return nil, err
+		}
+	} else {
+		var p project
+		if err := json.Unmarshal(data, &p); err != nil {
+			return nil, err
+		}
+		projects = []project{p}
+	}
+
+	r := &Report{root: root, findings: make(map[string][]finding)}
+	for _, p := range projects {
+		if p.Error != "" {
+			return nil, fmt.Errorf("snyk failed to test %s: %s", p.Path, p.Error)
+		}
+		if p.PackageManager != packageManager {
+			continue
+		}
+
+		target := p.DisplayTargetFile
+		if target == "" {
+			target = p.TargetFile
+		}
+		if target == "" {
+			target = "go.mod"
+		}
+		target = filepath.ToSlash(filepath.Clean(target))
+		if _, ok := r.findings[target]; !ok {
+			r.findings[target] = nil
+		}
+
+		seen := make(map[string]bool)
+		for _, v := range p.Vulnerabilities {
+			key := v.ID + "\x00" + v.PackageName
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			r.findings[target] = append(r.findings[target], convert(v))
+		}
+	}
+	return r, nil
+}
+
+// convert maps a Snyk vulnerability onto the Trivy model. It is identified
+// by its CVE or GHSA ID, if any, like Trivy does; its other IDs, including
+// the Snyk ID, become aliases.
+func convert(v vulnerability) finding {
+	ids := append(append(append([]string(nil), v.Identifiers.CVE...), v.Identifiers.GHSA...), v.ID)
+	id, aliases := ids[0], ids[1:]
+
+	pkg := v.ModuleName
+	if pkg == "" {
+		pkg = v.PackageName
+	}
+
+	var fixed []string
+	for _, f := range v.FixedIn {
+		fixed = append(fixed, strings.TrimPrefix(f, "v"))
+	}
+
+	var references []string
+	for _, ref := range v.References {
+		if ref.URL != "" {
+			references = append(references, ref.URL)
+		}
+	}
+
+	vuln := trivy.Vulnerability{
+		VulnerabilityID:  id,
+		Aliases:          aliases,
+		PkgName:          pkg,
+		InstalledVersion: v.Version,
+		FixedVersion:     strings.Join(fixed, ", "),
+		Severity:         strings.ToUpper(v.Severity),
+		Title:            v.Title,
+		Description:      v.Description,
+		PrimaryURL:       advisoryURL + v.ID,
+		References:       references,
+		CweIDs:           v.Identifiers.CWE,
+		PublishedDate:    v.PublicationTime,
+		LastModifiedDate: v.ModificationTime,
+		CVSSScore:        v.CVSSScore,
+	}
+	if v.CVSSScore > 0 || v.CVSSv3 != "" {
+		vuln.CVSS = map[string]trivy.CVSS{"snyk": {V3Score: v.CVSSScore, V3Vector: v.CVSSv3}}
+	}
+	return finding{vuln: vuln, vulnerable: v.Semver.Vulnerable}
+}
+
+// Scan returns the findings of goModPath that still affect the module:
+// those whose module is still required at a version Snyk considers
+// vulnerable. Snyk may report a package rather than its module; it is
+// attributed to the required module containing it. Of several fixed
+// versions, the one to update to is picked like for Trivy's findings.
+func (r *Report) Scan(goModPath string) (trivy.ScanResult, error) {
+	result := trivy.ScanResult{Target: goModPath}
+
+	rel, err := relativeTarget(r.root, goModPath)
+	if err != nil {
+		return result, err
+	}
+	findings := r.findings[rel]
+	if len(findings) == 0 {
+		return result, nil
+	}
+
+	moduleDir := gomod.GetModuleDir(goModPath)
+	edges, err := gomod.ModGraph(moduleDir)
+	if err != nil {
+		return result, err
+	}
+	selected := gomod.NewGraph(edges).Selected()
+	parser, err := gomod.NewParser(goModPath)
+	if err != nil {
+		return result, err
+	}
+
+	var vulns []trivy.Vulnerability
+	for _, f := range findings {
+		module := moduleOf(f.vuln.PkgName, selected)
+		version, ok := selected[module]
+		if !ok || !affected(version, f) {
+			continue
+		}
+
+		vuln := f.vuln
+		if module != f.vuln.PkgName {
+			vuln.Advisory = f.vuln.PkgName
+		}
+		vuln.PkgName = module
+		vuln.InstalledVersion = version
+		vuln.FixedVersion = trivy.SelectFixedVersion(version, f.vuln.FixedVersion)
+		vuln.Indirect = !parser.IsDirectDependency(module)
+		vulns = append(vulns, vuln)
+	}
+	// Findings of several packages of a module are one finding of the module
+	result.Vulnerabilities = trivy.Merge(vulns)
+	return result, nil
+}
+
+// Targets returns the go.mod files of the report's Go projects, relative
+// to the root, slash-separated and sorted
+func (r *Report) Targets() []string {
+	return slices.Sorted(maps.Keys(r.findings))
+}
+
+// relativeTarget returns the path of goModPath relative to root as Snyk
+// reports target files
+func relativeTarget(root, goModPath string) (string, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return "", err
+	}
+	// A single go.mod file was given as the root
+	if info, err := os.Stat(absRoot); err == nil && !info.IsDir() {
+		absRoot = filepath.Dir(absRoot)
+	}
+	absPath, err := filepath.Abs(goModPath)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(absRoot, absPath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// moduleOf returns the module of selected that pkg belongs to, the one with
+// the longest matching path, or pkg if none does
+func moduleOf(pkg string, selected map[string]string) string {
+	if _, ok := selected[pkg]; ok {
+		return pkg
+	}
+	module := pkg
+	longest := 0
+	for path := range selected {
+		if len(path) > longest && strings.HasPrefix(pkg, path+"/") {
+			module, longest = path, len(path)
+		}
+	}
+	return module
+}
+
+// affected reports whether version is affected by f: within one of the
+// vulnerable ranges Snyk reported, or, without usable ranges, below the fix
+// of its release line, or below the oldest fix if its line has none.
+// Without either, only the version Snyk tested is known to be affected.
+func affected(version string, f finding) bool {
+	if len(f.vulnerable) > 0 {
+		for _, r := range f.vulnerable {
+			if in, ok := inRange(version, r); !ok || in {
+				return true
+			}
+		}
+		return false
+	}
+
+	var fixes []string
+	for _, fix := range strings.Split(f.vuln.FixedVersion, ",") {
+		if fix = trivy.SemverOf(strings.TrimSpace(fix)); semver.IsValid(fix) {
+			fixes = append(fixes, fix)
+		}
+	}
+	if len(fixes) == 0 {
+		return semver.Compare(trivy.SemverOf(version), trivy.SemverOf(f.vuln.InstalledVersion)) == 0
+	}
+
+	v := trivy.SemverOf(version)
+	oldest := ""
+	for _, fix := range fixes {
+		if semver.MajorMinor(fix) == semver.MajorMinor(v) {
+			return semver.Compare(v, fix) < 0
+		}
+		if oldest == "" || semver.Compare(fix, oldest) < 0 {
+			oldest = fix
+		}
+	}
+	return semver.Compare(v, oldest) < 0
+}
+
+// inRange reports whether version is within the Snyk range r, e.g.
+// "<1.2.3", ">=1.0.0 <1.2.3", "[1.0.0,1.2.3)" or "*". ok is false if r
+// cannot be parsed.
+func inRange(version, r string) (in, ok bool) {
+	v := trivy.SemverOf(version)
+	r = strings.TrimSpace(r)
+	if r == "*" {
+		return true, true
+	}
+
+	// Interval notation
+	if strings.HasPrefix(r, "[") || strings.HasPrefix(r, "(") {
+		if len(r) < 3 {
+			return false, false
+		}
+		lower, upper, found := strings.Cut(r[1:len(r)-1], ",")
+		if !found {
+			return false, false
+		}
+		lower, upper = strings.TrimSpace(lower), strings.TrimSpace(upper)
+		if lower != "" {
+			op := ">"
+			if r[0] == '[' {
+				op = ">="
+			}
+			if in, ok := satisfies(v, op, lower); !ok || !in {
+				return in, ok
+			}
+		}
+		if upper != "" {
+			op := "<"
+			if strings.HasSuffix(r, "]") {
+				op = "<="
+			}
+			return satisfies(v, op, upper)
+		}
+		return true, true
+	}
+
+	// Constraints separated by spaces or commas, which must all hold
+	constraints := strings.FieldsFunc(r, func(c rune) bool { return c == ' ' || c == ',' })
+	if len(constraints) == 0 {
+		return false, false
+	}
+	for _, c := range constraints {
+		op := strings.TrimRight(c, "0123456789.v-+abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
+		if in, ok := satisfies(v, op, c[len(op):]); !ok || !in {
+			return in, ok
+		}
+	}
+	return true, true
+}
+
+// satisfies reports whether v compares to bound as op requires
+func satisfies(v, op, bound string) (in, ok bool) {
+	bound = trivy.SemverOf(bound)
+	if !semver.IsValid(bound) {
+		return false, false
+	}
+	c := semver.Compare(v, bound)
+	switch op {
+	case "<":
+		return c < 0, true
+	case "<=":
+		return c <= 0, true
+	case ">":
+		return c > 0, true
+	case ">=":
+		return c >= 0, true
+	case "=", "==", "":
+		return c == 0, true
+	}
+	return false, false
+}
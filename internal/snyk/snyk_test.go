@@ -0,0 +1,195 @@
+package snyk
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/tamcore/go-autobump/internal/runner"
+)
+
+const allProjects = `[
+  {
+    "ok": false,
+    "path": "/src/app",
+    "packageManager": "gomodules",
+    "displayTargetFile": "go.mod",
+    "vulnerabilities": [
+      {
+        "id": "SNYK-GOLANG-GOLANGORGXNETHTTP2-1",
+        "title": "Denial of Service (DoS)",
+        "severity": "high",
+        "cvssScore": 7.5,
+        "CVSSv3": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H",
+        "identifiers": {"CVE": ["CVE-2023-39325"], "GHSA": ["GHSA-4374-p667-p6c8"], "CWE": ["CWE-770"]},
+        "references": [{"title": "Go issue", "url": "https://go.dev/issue/63417"}],
+        "packageName": "golang.org/x/net/http2",
+        "moduleName": "golang.org/x/net",
+        "version": "v0.15.0",
+        "fixedIn": ["0.17.0"],
+        "from": ["example.com/app@0.0.0", "golang.org/x/net/http2@v0.15.0"],
+        "semver": {"vulnerable": ["<0.17.0"]},
+        "publicationTime": "2023-10-11T00:00:00Z"
+      },
+      {
+        "id": "SNYK-GOLANG-GOLANGORGXNETHTTP2-1",
+        "packageName": "golang.org/x/net/http2",
+        "moduleName": "golang.org/x/net",
+        "version": "v0.15.0",
+        "from": ["example.com/app@0.0.0", "example.com/lib@v1.0.0", "golang.org/x/net/http2@v0.15.0"]
+      },
+      {
+        "id": "SNYK-GOLANG-EXAMPLECOMLIB-2",
+        "title": "Path Traversal",
+        "severity": "medium",
+        "packageName": "example.com/lib/fs",
+        "version": "v1.0.0",
+        "fixedIn": ["v1.0.3", "v1.1.1"]
+      }
+    ]
+  },
+  {"ok": true, "packageManager": "npm", "displayTargetFile": "web/package-lock.json", "vulnerabilities": [{"id": "SNYK-JS-X-1"}]},
+  {"ok": true, "packageManager": "gomodules", "displayTargetFile": "tools/go.mod", "vulnerabilities": []}
+]`
+
+func TestParse(t *testing.T) {
+	r, err := Parse([]byte(allProjects), "/src/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := strings.Join(r.Targets(), " "); got != "go.mod tools/go.mod" {
+		t.Errorf("Targets() = %s, want the Go projects go.mod tools/go.mod", got)
+	}
+
+	findings := r.findings["go.mod"]
+	if len(findings) != 2 {
+		t.Fatalf("got %d findings, want 2 (one per vulnerability and package)", len(findings))
+	}
+	vuln := findings[0].vuln
+	if vuln.VulnerabilityID != "CVE-2023-39325" || vuln.PkgName != "golang.org/x/net" || vuln.FixedVersion != "0.17.0" ||
+		vuln.Severity != "HIGH" || vuln.CVSSScore != 7.5 || vuln.CVSS["snyk"].V3Score != 7.5 || vuln.PublishedDate == nil ||
+		vuln.PrimaryURL != "https://security.snyk.io/vuln/SNYK-GOLANG-GOLANGORGXNETHTTP2-1" ||
+		!slices.Equal(vuln.CweIDs, []string{"CWE-770"}) || !slices.Equal(vuln.References, []string{"https://go.dev/issue/63417"}) {
+		t.Errorf("unexpected conversion: %+v", vuln)
+	}
+	if vuln := findings[1].vuln; vuln.VulnerabilityID != "SNYK-GOLANG-EXAMPLECOMLIB-2" || vuln.PkgName != "example.com/lib/fs" ||
+		vuln.FixedVersion != "1.0.3, 1.1.1" {
+		t.Errorf("unexpected conversion without identifiers or module: %+v", vuln)
+	}
+}
+
+func TestParseSingleProject(t *testing.T) {
+	r, err := Parse([]byte(`{"packageManager": "gomodules", "targetFile": "go.mod", "vulnerabilities": [{"id": "SNYK-1", "packageName": "example.com/dep"}]}`), ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.findings["go.mod"]) != 1 {
+		t.Errorf("findings = %v, want one for go.mod", r.findings)
+	}
+}
+
+func TestParseFailedProject(t *testing.T) {
+	_, err := Parse([]byte(`{"ok": false, "error": "Could not find go.sum", "path": "/src/app"}`), ".")
+	if err == nil || !strings.Contains(err.Error(), "Could not find go.sum") {
+		t.Errorf("Parse() error = %v, want Snyk's error", err)
+	}
+}
+
+// graphRunner answers "go mod graph" from a fixed output
+type graphRunner struct {
+	graph string
+}
+
+func (r *graphRunner) Run(_ context.Context, _, _ string, _ ...string) ([]byte, []byte, error) {
+	return []byte(r.graph), nil, nil
+}
+
+func TestScan(t *testing.T) {
+	root := t.TempDir()
+	goMod := filepath.Join(root, "go.mod")
+	content := "module example.com/app\n\ngo 1.22\n\nrequire example.com/lib v1.1.0\n\nrequire golang.org/x/net v0.17.0 // indirect\n"
+	if err := os.WriteFile(goMod, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := Parse([]byte(allProjects), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer runner.Set(runner.Default())
+	tests := []struct {
+		name  string
+		graph string
+		want  string
+	}{
+		{
+			name:  "unchanged",
+			graph: "example.com/app example.com/lib@v1.0.0\nexample.com/lib@v1.0.0 golang.org/x/net@v0.15.0\n",
+			want:  "CVE-2023-39325 golang.org/x/net@v0.15.0 indirect fixed in 0.17.0, SNYK-GOLANG-EXAMPLECOMLIB-2 example.com/lib@v1.0.0 direct fixed in 1.0.3",
+		},
+		{
+			name:  "one fixed",
+			graph: "example.com/app example.com/lib@v1.0.0\nexample.com/lib@v1.0.0 golang.org/x/net@v0.17.0\n",
+			want:  "SNYK-GOLANG-EXAMPLECOMLIB-2 example.com/lib@v1.0.0 direct fixed in 1.0.3",
+		},
+		{
+			name:  "fixed on another release line",
+			graph: "example.com/app example.com/lib@v1.1.1\nexample.com/lib@v1.1.1 golang.org/x/net@v0.15.0\n",
+			want:  "CVE-2023-39325 golang.org/x/net@v0.15.0 indirect fixed in 0.17.0",
+		},
+		{
+			name:  "dropped",
+			graph: "example.com/app example.com/lib@v1.0.3\n",
+			want:  "",
+		},
+	}
+	for _, tt := range tests {
+		runner.Set(&graphRunner{graph: tt.graph})
+		result, err := r.Scan(goMod)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		var got []string
+		for _, vuln := range result.Vulnerabilities {
+			relationship := "direct"
+			if vuln.Indirect {
+				relationship = "indirect"
+			}
+			got = append(got, vuln.VulnerabilityID+" "+vuln.PkgName+"@"+vuln.InstalledVersion+" "+relationship+" fixed in "+vuln.FixedVersion)
+		}
+		if strings.Join(got, ", ") != tt.want {
+			t.Errorf("%s: Scan() = %v, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestInRange(t *testing.T) {
+	tests := []struct {
+		version, r string
+		in, ok     bool
+	}{
+		{"v0.15.0", "<0.17.0", true, true},
+		{"v0.17.0", "<0.17.0", false, true},
+		{"v1.1.0", ">=1.0.0 <1.2.3", true, true},
+		{"v1.2.3", ">=1.0.0, <1.2.3", false, true},
+		{"v0.9.0", ">=1.0.0 <1.2.3", false, true},
+		{"v1.0.0", "[1.0.0,1.2.3)", true, true},
+		{"v1.0.0", "(1.0.0,1.2.3)", false, true},
+		{"v1.2.3", "[,1.2.3]", true, true},
+		{"v0.0.0-20230101000000-abcdefabcdef", "<0.1.0", true, true},
+		{"v9.9.9", "*", true, true},
+		{"v1.0.0", "~1.0", false, false},
+		{"v1.0.0", "[", false, false},
+	}
+	for _, tt := range tests {
+		in, ok := inRange(tt.version, tt.r)
+		if in != tt.in || ok != tt.ok {
+			t.Errorf("inRange(%q, %q) = %v, %v, want %v, %v", tt.version, tt.r, in, ok, tt.in, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// Package state persists what a run leaves in place for later runs to
+// follow up on, such as temporary mitigations
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"time"
+)
+
+// State is the content of the state file
+type State struct {
+	// Mitigations are the replace directives applied to vulnerabilities
+	// without a fixed release
+	Mitigations []Mitigation `json:"mitigations"`
+}
+
+// Mitigation is a vulnerable module replaced by a patched fork or commit.
+// GoMod is the go.mod path relative to the scan root.
+type Mitigation struct {
+	GoMod           string   `json:"go_mod"`
+	Module          string   `json:"module"`
+	Version         string   `json:"version"`
+	Replacement     string   `json:"replacement"`
+	Vulnerabilities []string `json:"vulnerabilities"`
+	// LatestRelease is the newest upstream release when the replacement
+	// was applied; a newer one may fix the vulnerabilities
+	LatestRelease string    `json:"latest_release,omitempty"`
+	Applied       time.Time `json:"applied"`
+}
+
+// Read reads the state file at path; a missing file is an empty state
+func Read(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &State{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	var s State
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse state file %s: %w", path, err)
+	}
+	return &s, nil
+}
+
+// Write saves the state to path
+func (s *State) Write(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	return nil
+}
+
+// AddMitigation records m. For a module already mitigated in the same
+// go.mod, the vulnerabilities are merged and the rest is replaced.
+func (s *State) AddMitigation(m Mitigation) {
+	for i, existing := range s.Mitigations {
+		if existing.GoMod != m.GoMod || existing.Module != m.Module {
+			continue
+		}
+		for _, id := range existing.Vulnerabilities {
+			if !slices.Contains(m.Vulnerabilities, id) {
+				m.Vulnerabilities = append(m.Vulnerabilities, id)
+			}
+		}
+		sort.Strings(m.Vulnerabilities)
+		if existing.Replacement == m.Replacement {
+			m.Applied = existing.Applied
+		}
+		s.Mitigations[i] = m
+		return
+	}
+	s.Mitigations = append(s.Mitigations, m)
+}
+
+// RemoveMitigation forgets the mitigation of module in goMod
+func (s *State) RemoveMitigation(goMod, module string) {
+	s.Mitigations = slices.DeleteFunc(s.Mitigations, func(m Mitigation) bool {
+		return m.GoMod == goMod && m.Module == module
+	})
+}
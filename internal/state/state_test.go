@@ -0,0 +1,43 @@
+package state
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMitigations(t *testing.T) {
+	applied := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	s := &State{}
+	s.AddMitigation(Mitigation{GoMod: "go.mod", Module: "example.com/a", Replacement: "example.com/fork v1.0.1", Vulnerabilities: []string{"CVE-2"}, Applied: applied})
+	s.AddMitigation(Mitigation{GoMod: "go.mod", Module: "example.com/a", Replacement: "example.com/fork v1.0.1", Vulnerabilities: []string{"CVE-1"}, Applied: applied.Add(time.Hour)})
+	s.AddMitigation(Mitigation{GoMod: "sub/go.mod", Module: "example.com/a", Replacement: "example.com/fork v1.0.1"})
+
+	if len(s.Mitigations) != 2 {
+		t.Fatalf("mitigations = %+v, want one per go.mod", s.Mitigations)
+	}
+	if got := s.Mitigations[0].Vulnerabilities; !reflect.DeepEqual(got, []string{"CVE-1", "CVE-2"}) {
+		t.Errorf("vulnerabilities = %v, want both merged", got)
+	}
+	if !s.Mitigations[0].Applied.Equal(applied) {
+		t.Errorf("applied = %v, want the time of the first replacement", s.Mitigations[0].Applied)
+	}
+
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := s.Write(path); err != nil {
+		t.Fatal(err)
+	}
+	read, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	read.RemoveMitigation("sub/go.mod", "example.com/a")
+	if len(read.Mitigations) != 1 || read.Mitigations[0].GoMod != "go.mod" {
+		t.Errorf("mitigations after removal = %+v", read.Mitigations)
+	}
+
+	if missing, err := Read(filepath.Join(t.TempDir(), "missing.json")); err != nil || len(missing.Mitigations) != 0 {
+		t.Errorf("Read(missing) = %+v, %v, want an empty state", missing, err)
+	}
+}
@@ -0,0 +1,114 @@
+package trivy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+
+	"github.com/tamcore/go-autobump/internal/alias"
+)
+
+// Finding is a vulnerability together with the go.mod it was found in
+type Finding struct {
+	Target string `json:"Target"`
+	Vulnerability
+}
+
+// DiffResult categorizes vulnerabilities between two scans
+type DiffResult struct {
+	New        []Finding `json:"new"`
+	Fixed      []Finding `json:"fixed"`
+	Persisting []Finding `json:"persisting"`
+}
+
+// findingKey identifies a vulnerability across scans
+type findingKey struct {
+	Target          string
+	VulnerabilityID string
+	PkgName         string
+}
+
+// Diff compares two sets of scan results and reports which vulnerabilities
+// were newly introduced, fixed, or are still present. Vulnerabilities are
+// matched by go.mod target, vulnerability ID, and package name; an ID
+// matches its aliases.
+func Diff(oldResults, newResults []ScanResult) DiffResult {
+	for _, result := range append(slices.Clone(oldResults), newResults...) {
+		for _, vuln := range result.Vulnerabilities {
+			learnAliases(vuln)
+		}
+	}
+	oldFindings := indexFindings(oldResults)
+	newFindings := indexFindings(newResults)
+
+	var diff DiffResult
+	for key, finding := range newFindings {
+		if _, ok := oldFindings[key]; ok {
+			diff.Persisting = append(diff.Persisting, finding)
+		} else {
+			diff.New = append(diff.New, finding)
+		}
+	}
+	for key, finding := range oldFindings {
+		if _, ok := newFindings[key]; !ok {
+			diff.Fixed = append(diff.Fixed, finding)
+		}
+	}
+
+	sortFindings(diff.New)
+	sortFindings(diff.Fixed)
+	sortFindings(diff.Persisting)
+	return diff
+}
+
+// LoadResults reads scan results previously written by "scan --json"
+func LoadResults(path string) ([]ScanResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scan results: %w", err)
+	}
+
+	var results []ScanResult
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, fmt.Errorf("failed to parse scan results %s: %w", path, err)
+	}
+
+	// The computed score is not serialized, so derive it again
+	for i := range results {
+		for j := range results[i].Vulnerabilities {
+			vuln := &results[i].Vulnerabilities[j]
+			vuln.CVSSScore = getHighestCVSSScore(vuln.CVSS)
+		}
+	}
+
+	return results, nil
+}
+
+func indexFindings(results []ScanResult) map[findingKey]Finding {
+	findings := make(map[findingKey]Finding)
+	for _, result := range results {
+		for _, vuln := range result.Vulnerabilities {
+			key := findingKey{
+				Target:          result.Target,
+				VulnerabilityID: alias.Canonical(vuln.VulnerabilityID),
+				PkgName:         vuln.PkgName,
+			}
+			findings[key] = Finding{Target: result.Target, Vulnerability: vuln}
+		}
+	}
+	return findings
+}
+
+func sortFindings(findings []Finding) {
+	sort.Slice(findings, func(i, j int) bool {
+		if findings[i].Target != findings[j].Target {
+			return findings[i].Target < findings[j].Target
+		}
+		if findings[i].PkgName != findings[j].PkgName {
+			return findings[i].PkgName < findings[j].PkgName
+		}
+		return findings[i].VulnerabilityID < findings[j].VulnerabilityID
+	})
+}
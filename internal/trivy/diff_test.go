@@ -0,0 +1,74 @@
+package trivy
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	oldResults := []ScanResult{
+		{Target: "go.mod", Vulnerabilities: []Vulnerability{
+			{VulnerabilityID: "CVE-1", PkgName: "a"},
+			{VulnerabilityID: "CVE-2", PkgName: "b"},
+		}},
+	}
+	newResults := []ScanResult{
+		{Target: "go.mod", Vulnerabilities: []Vulnerability{
+			{VulnerabilityID: "CVE-2", PkgName: "b"},
+			{VulnerabilityID: "CVE-3", PkgName: "c"},
+		}},
+		{Target: "sub/go.mod", Vulnerabilities: []Vulnerability{
+			{VulnerabilityID: "CVE-1", PkgName: "a"},
+		}},
+	}
+
+	diff := Diff(oldResults, newResults)
+
+	ids := func(findings []Finding) []string {
+		var out []string
+		for _, f := range findings {
+			out = append(out, f.Target+":"+f.VulnerabilityID)
+		}
+		return out
+	}
+
+	if got := ids(diff.New); len(got) != 2 || got[0] != "go.mod:CVE-3" || got[1] != "sub/go.mod:CVE-1" {
+		t.Errorf("New = %v", got)
+	}
+	if got := ids(diff.Fixed); len(got) != 1 || got[0] != "go.mod:CVE-1" {
+		t.Errorf("Fixed = %v", got)
+	}
+	if got := ids(diff.Persisting); len(got) != 1 || got[0] != "go.mod:CVE-2" {
+		t.Errorf("Persisting = %v", got)
+	}
+}
+
+func TestDiffJSONIndirect(t *testing.T) {
+	// A saved scan keeps whether a vulnerable module is required indirectly
+	path := filepath.Join(t.TempDir(), "scan.json")
+	saved, err := json.Marshal([]ScanResult{{Target: "go.mod", Vulnerabilities: []Vulnerability{
+		{VulnerabilityID: "CVE-1", PkgName: "a", Indirect: true},
+	}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, saved, 0644); err != nil {
+		t.Fatal(err)
+	}
+	oldResults, err := LoadResults(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diff := Diff(oldResults, nil)
+	data, err := json.Marshal(diff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"Indirect":true`) {
+		t.Errorf("diff JSON = %s, want the fixed finding marked indirect", data)
+	}
+}
@@ -1,5 +1,48 @@
 package trivy
 
+import (
+	"time"
+
+	"github.com/tamcore/go-autobump/internal/gomod"
+)
+
+// Thresholds are the minimum CVSS scores to act on: Default, unless an
+// override matches the vulnerable package
+type Thresholds struct {
+	Default   float64
+	Overrides []ThresholdOverride
+}
+
+// ThresholdOverride sets the threshold of the packages matching Patterns,
+// e.g. "github.com/example/auth*" (see gomod.MatchPattern)
+type ThresholdOverride struct {
+	Patterns  []string
+	Threshold float64
+}
+
+// For returns the threshold of pkgName. Of several matching overrides, the
+// one with the longest matching pattern wins, so a package can be excluded
+// from the override of its namespace.
+func (t Thresholds) For(pkgName string) float64 {
+	threshold, longest := t.Default, -1
+	for _, override := range t.Overrides {
+		for _, pattern := range override.Patterns {
+			if len(pattern) > longest && gomod.MatchPattern(pattern, pkgName) {
+				threshold, longest = override.Threshold, len(pattern)
+			}
+		}
+	}
+	return threshold
+}
+
+// FilterByThresholds keeps the vulnerabilities scoring at least the
+// threshold of their package
+func FilterByThresholds(result ScanResult, t Thresholds) ScanResult {
+	return Filter(result, func(vuln Vulnerability) bool {
+		return vuln.CVSSScore >= t.For(vuln.PkgName)
+	})
+}
+
 // FilterByCVSS filters vulnerabilities by minimum CVSS score threshold
 func FilterByCVSS(result ScanResult, threshold float64) ScanResult {
 	filtered := ScanResult{
@@ -15,6 +58,40 @@ func FilterByCVSS(result ScanResult, threshold float64) ScanResult {
 	return filtered
 }
 
+// Filter keeps the vulnerabilities for which keep returns true
+func Filter(result ScanResult, keep func(Vulnerability) bool) ScanResult {
+	filtered := ScanResult{
+		Target: result.Target,
+	}
+
+	for _, vuln := range result.Vulnerabilities {
+		if keep(vuln) {
+			filtered.Vulnerabilities = append(filtered.Vulnerabilities, vuln)
+		}
+	}
+
+	return filtered
+}
+
+// FilterByAge keeps vulnerabilities published at least minAge and at most
+// maxAge before now. A zero bound is not applied. Vulnerabilities without a
+// publication date are kept, as their age is unknown.
+func FilterByAge(result ScanResult, minAge, maxAge time.Duration, now time.Time) ScanResult {
+	return Filter(result, func(vuln Vulnerability) bool {
+		if vuln.PublishedDate == nil {
+			return true
+		}
+		age := now.Sub(*vuln.PublishedDate)
+		if minAge > 0 && age < minAge {
+			return false
+		}
+		if maxAge > 0 && age > maxAge {
+			return false
+		}
+		return true
+	})
+}
+
 // SplitByType separates vulnerabilities into direct and indirect dependencies
 func SplitByType(vulns []Vulnerability) (direct, indirect []Vulnerability) {
 	for _, vuln := range vulns {
@@ -0,0 +1,113 @@
+package trivy
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestFilterByAge(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	published := func(days int) *time.Time {
+		ts := now.Add(-time.Duration(days) * 24 * time.Hour)
+		return &ts
+	}
+	result := ScanResult{Target: "go.mod", Vulnerabilities: []Vulnerability{
+		{VulnerabilityID: "CVE-NEW", PublishedDate: published(1)},
+		{VulnerabilityID: "CVE-WEEKS", PublishedDate: published(30)},
+		{VulnerabilityID: "CVE-OLD", PublishedDate: published(400)},
+		{VulnerabilityID: "CVE-UNDATED"},
+	}}
+
+	tests := []struct {
+		name           string
+		minAge, maxAge time.Duration
+		want           []string
+	}{
+		{"no bounds", 0, 0, []string{"CVE-NEW", "CVE-WEEKS", "CVE-OLD", "CVE-UNDATED"}},
+		{"min age", 7 * 24 * time.Hour, 0, []string{"CVE-WEEKS", "CVE-OLD", "CVE-UNDATED"}},
+		{"max age", 0, 90 * 24 * time.Hour, []string{"CVE-NEW", "CVE-WEEKS", "CVE-UNDATED"}},
+		{"window", 7 * 24 * time.Hour, 90 * 24 * time.Hour, []string{"CVE-WEEKS", "CVE-UNDATED"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FilterByAge(result, tt.minAge, tt.maxAge, now)
+			if len(got.Vulnerabilities) != len(tt.want) {
+				t.Fatalf("got %d vulnerabilities, want %v", len(got.Vulnerabilities), tt.want)
+			}
+			for i, vuln := range got.Vulnerabilities {
+				if vuln.VulnerabilityID != tt.want[i] {
+					t.Errorf("vulnerability %d = %s, want %s", i, vuln.VulnerabilityID, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	result := ScanResult{Target: "go.mod", Vulnerabilities: []Vulnerability{
+		{VulnerabilityID: "CVE-FIXED", FixedVersion: "1.0.1"},
+		{VulnerabilityID: "CVE-UNFIXED"},
+		{VulnerabilityID: "CVE-BACKPORTED", FixedVersion: "1.1.3, 1.2.1"},
+	}}
+
+	tests := []struct {
+		name string
+		keep func(Vulnerability) bool
+		want []string
+	}{
+		{"only fixed", HasFixedVersion, []string{"CVE-FIXED", "CVE-BACKPORTED"}},
+		{"only unfixed", func(v Vulnerability) bool { return !HasFixedVersion(v) }, []string{"CVE-UNFIXED"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Filter(result, tt.keep)
+			if got.Target != result.Target {
+				t.Errorf("Target = %q, want %q", got.Target, result.Target)
+			}
+			var ids []string
+			for _, vuln := range got.Vulnerabilities {
+				ids = append(ids, vuln.VulnerabilityID)
+			}
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("Filter() kept %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
+
+func TestThresholds(t *testing.T) {
+	thresholds := Thresholds{
+		Default: 7.0,
+		Overrides: []ThresholdOverride{
+			{Patterns: []string{"github.com/example/auth*", "golang.org/x/crypto"}, Threshold: 4.0},
+			{Patterns: []string{"github.com/example/auth/testutil"}, Threshold: 9.0},
+		},
+	}
+
+	tests := []struct {
+		pkg  string
+		want float64
+	}{
+		{"golang.org/x/net", 7.0},
+		{"golang.org/x/crypto", 4.0},
+		{"github.com/example/auth/v2", 4.0},
+		{"github.com/example/auth/testutil", 9.0},
+	}
+	for _, tt := range tests {
+		if got := thresholds.For(tt.pkg); got != tt.want {
+			t.Errorf("For(%s) = %v, want %v", tt.pkg, got, tt.want)
+		}
+	}
+
+	result := ScanResult{Vulnerabilities: []Vulnerability{
+		{VulnerabilityID: "CVE-1", PkgName: "golang.org/x/crypto", CVSSScore: 5.0},
+		{VulnerabilityID: "CVE-2", PkgName: "golang.org/x/net", CVSSScore: 5.0},
+	}}
+	filtered := FilterByThresholds(result, thresholds)
+	if len(filtered.Vulnerabilities) != 1 || filtered.Vulnerabilities[0].VulnerabilityID != "CVE-1" {
+		t.Errorf("filtered = %+v", filtered.Vulnerabilities)
+	}
+}
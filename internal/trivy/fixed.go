@@ -0,0 +1,52 @@
+package trivy
+
+import (
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// SelectFixedVersion picks the fixed version to update to from Trivy's
+// FixedVersion, which lists one fix per release line when a vulnerability
+// was patched in several of them, e.g. "1.2.9, 1.3.4". It returns the oldest
+// candidate newer than installed, the one closest to the installed minor line,
+// or the newest candidate if none is newer. A single or unparsable fixed
+// version is returned as-is.
+func SelectFixedVersion(installed, fixed string) string {
+	if !strings.Contains(fixed, ",") {
+		return fixed
+	}
+
+	var candidates []string
+	for _, c := range strings.Split(fixed, ",") {
+		if c = strings.TrimSpace(c); semver.IsValid(SemverOf(c)) {
+			candidates = append(candidates, c)
+		}
+	}
+	if len(candidates) == 0 {
+		return fixed
+	}
+
+	var closest, newest string
+	for _, c := range candidates {
+		if newest == "" || semver.Compare(SemverOf(c), SemverOf(newest)) > 0 {
+			newest = c
+		}
+		if semver.Compare(SemverOf(c), SemverOf(installed)) > 0 &&
+			(closest == "" || semver.Compare(SemverOf(c), SemverOf(closest)) < 0) {
+			closest = c
+		}
+	}
+	if closest == "" {
+		return newest
+	}
+	return closest
+}
+
+// SemverOf returns version with the "v" prefix semver comparisons require
+func SemverOf(version string) string {
+	if strings.HasPrefix(version, "v") {
+		return version
+	}
+	return "v" + version
+}
@@ -0,0 +1,30 @@
+package trivy
+
+import "testing"
+
+func TestSelectFixedVersion(t *testing.T) {
+	tests := []struct {
+		installed string
+		fixed     string
+		want      string
+	}{
+		{"v1.2.5", "1.2.9", "1.2.9"},
+		{"v1.2.5", "", ""},
+		{"v1.2.5", "1.2.9, 1.3.4", "1.2.9"},
+		{"v1.3.0", "1.2.9, 1.3.4", "1.3.4"},
+		{"v1.1.0", "1.3.4, 1.2.9", "1.2.9"},
+		{"v0.0.0-20230101000000-abcdef123456", "0.17.0, 0.15.1", "0.15.1"},
+		{"v1.4.0", "1.2.9, 1.3.4", "1.3.4"},
+		{"v1.2.5", "v1.2.9,v1.3.4", "v1.2.9"},
+		{"v1.2.5", "1.2.9, unknown", "1.2.9"},
+		{"v1.2.5", "foo, bar", "foo, bar"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.installed+" "+tt.fixed, func(t *testing.T) {
+			if got := SelectFixedVersion(tt.installed, tt.fixed); got != tt.want {
+				t.Errorf("SelectFixedVersion(%q, %q) = %q, want %q", tt.installed, tt.fixed, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package trivy
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/tamcore/go-autobump/internal/alias"
+	"golang.org/x/mod/semver"
+)
+
+// severityRanks orders the severities, unknown ones ranking lowest
+var severityRanks = map[string]int{"LOW": 1, "MEDIUM": 2, "HIGH": 3, "CRITICAL": 4}
+
+// IDs returns the ID of vuln followed by its aliases
+func (v Vulnerability) IDs() []string {
+	return append([]string{v.VulnerabilityID}, v.Aliases...)
+}
+
+// Is reports whether v is the vulnerability id, by its ID, its aliases or
+// those in the alias table
+func (v Vulnerability) Is(id string) bool {
+	return slices.ContainsFunc(v.IDs(), func(own string) bool { return alias.Same(own, id) })
+}
+
+// Links returns the advisory URL of v followed by its other references
+func (v Vulnerability) Links() []string {
+	var links []string
+	if v.PrimaryURL != "" {
+		links = append(links, v.PrimaryURL)
+	}
+	return union(links, v.References)
+}
+
+// SameVulnerability reports whether a and b are the same vulnerability of
+// the same package, whichever of its IDs each was reported by
+func SameVulnerability(a, b Vulnerability) bool {
+	return a.PkgName == b.PkgName && slices.ContainsFunc(b.IDs(), a.Is)
+}
+
+// learnAliases adds the aliases a scanner reported for vuln to the alias
+// table
+func learnAliases(vuln Vulnerability) {
+	if len(vuln.Aliases) > 0 {
+		alias.Add(vuln.VulnerabilityID, vuln.Aliases...)
+	}
+}
+
+// Merge combines the findings of several scanners, e.g. Trivy and scan
+// plugins, into one finding per vulnerable package and vulnerability. Two
+// findings are the same vulnerability if they share their ID or an alias,
+// e.g. a CVE one scanner reports and the GHSA or GO ID another reports with
+// the CVE as alias, or the alias table relates their IDs. The first finding
+// keeps its ID and position; the others add their IDs as aliases. Of the
+// details, the highest severity and CVSS score, all CVSS sources, the
+// earliest publication and the highest fixed version of each release line
+// are kept.
+func Merge(vulns []Vulnerability) []Vulnerability {
+	// group[i] is the index of the first finding vulns[i] is the same
+	// vulnerability as, directly or through other findings
+	group := make([]int, len(vulns))
+	var first func(i int) int
+	first = func(i int) int {
+		if group[i] != i {
+			group[i] = first(group[i])
+		}
+		return group[i]
+	}
+	for i := range vulns {
+		learnAliases(vulns[i])
+	}
+	for i := range vulns {
+		group[i] = i
+		for j := range i {
+			if SameVulnerability(vulns[i], vulns[j]) {
+				a, b := first(i), first(j)
+				group[max(a, b)] = min(a, b)
+			}
+		}
+	}
+
+	var merged []Vulnerability
+	index := make(map[int]int)
+	for i, vuln := range vulns {
+		g := first(i)
+		if g == i {
+			index[i] = len(merged)
+			merged = append(merged, vuln)
+			continue
+		}
+		merged[index[g]] = mergeVulnerability(merged[index[g]], vuln)
+	}
+	return merged
+}
+
+// mergeVulnerability adds the details of other, the same vulnerability, to v
+func mergeVulnerability(v, other Vulnerability) Vulnerability {
+	for _, id := range other.IDs() {
+		if !slices.ContainsFunc(v.IDs(), func(known string) bool { return strings.EqualFold(id, known) }) {
+			v.Aliases = append(v.Aliases, id)
+		}
+	}
+
+	if severityRanks[other.Severity] > severityRanks[v.Severity] {
+		v.Severity = other.Severity
+	}
+	v.CVSSScore = max(v.CVSSScore, other.CVSSScore)
+	for source, cvss := range other.CVSS {
+		if _, ok := v.CVSS[source]; ok {
+			continue
+		}
+		if v.CVSS == nil {
+			v.CVSS = make(map[string]CVSS)
+		}
+		v.CVSS[source] = cvss
+	}
+	v.EPSS = max(v.EPSS, other.EPSS)
+	v.KEV = v.KEV || other.KEV
+
+	if other.PublishedDate != nil && (v.PublishedDate == nil || other.PublishedDate.Before(*v.PublishedDate)) {
+		v.PublishedDate = other.PublishedDate
+	}
+	if other.LastModifiedDate != nil && (v.LastModifiedDate == nil || other.LastModifiedDate.After(*v.LastModifiedDate)) {
+		v.LastModifiedDate = other.LastModifiedDate
+	}
+	v.FixedVersion = mergeFixedVersions(v.InstalledVersion, v.FixedVersion, other.FixedVersion)
+
+	if v.Title == "" {
+		v.Title = other.Title
+	}
+	if v.Description == "" {
+		v.Description = other.Description
+	}
+	if v.PrimaryURL == "" {
+		v.PrimaryURL = other.PrimaryURL
+	}
+	v.References = union(v.References, other.References)
+	v.CweIDs = union(v.CweIDs, other.CweIDs)
+	return v
+}
+
+// union returns a followed by the elements of b not in a, leaving a as is
+func union(a, b []string) []string {
+	a = slices.Clip(a)
+	for _, s := range b {
+		if !slices.Contains(a, s) {
+			a = append(a, s)
+		}
+	}
+	return a
+}
+
+// mergeFixedVersions combines the fixed versions two scanners report,
+// keeping the highest of each release line so that a fix one scanner
+// considers incomplete is not picked. Unparsable versions are only used if
+// a or b reports no other.
+func mergeFixedVersions(installed, a, b string) string {
+	highest := make(map[string]string)
+	var lines []string
+	for _, fixed := range []string{a, b} {
+		for _, c := range strings.Split(fixed, ",") {
+			c = strings.TrimSpace(c)
+			v := SemverOf(c)
+			if !semver.IsValid(v) {
+				continue
+			}
+			line := semver.MajorMinor(v)
+			current, ok := highest[line]
+			if !ok {
+				lines = append(lines, line)
+			}
+			if !ok || semver.Compare(v, SemverOf(current)) > 0 {
+				highest[line] = c
+			}
+		}
+	}
+	if len(lines) == 0 {
+		if a != "" {
+			return a
+		}
+		return b
+	}
+
+	var candidates []string
+	for _, line := range lines {
+		candidates = append(candidates, highest[line])
+	}
+	return SelectFixedVersion(installed, strings.Join(candidates, ", "))
+}
@@ -0,0 +1,72 @@
+package trivy
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestMerge(t *testing.T) {
+	older := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.AddDate(0, 1, 0)
+
+	vulns := []Vulnerability{
+		{VulnerabilityID: "CVE-2024-1", PkgName: "example.com/a", InstalledVersion: "v1.2.0", FixedVersion: "1.2.3",
+			Severity: "MEDIUM", CVSSScore: 5.3, CVSS: map[string]CVSS{"nvd": {V3Score: 5.3}}, PublishedDate: &newer,
+			PrimaryURL: "https://avd.aquasec.com/nvd/cve-2024-1", References: []string{"https://go.dev/issue/1"}, CweIDs: []string{"CWE-400"}},
+		{VulnerabilityID: "GHSA-xxxx-yyyy-zzzz", PkgName: "example.com/a", InstalledVersion: "v1.2.0"},
+		{VulnerabilityID: "CVE-2024-2", PkgName: "example.com/b", Severity: "LOW"},
+		// Joins the first two: the CVE as ID, the GHSA ID as alias
+		{VulnerabilityID: "GO-2024-0001", Aliases: []string{"cve-2024-1", "GHSA-xxxx-yyyy-zzzz"}, PkgName: "example.com/a",
+			InstalledVersion: "v1.2.0", FixedVersion: "1.2.5, 1.3.1", Severity: "HIGH", CVSSScore: 7.5,
+			CVSS: map[string]CVSS{"ghsa": {V3Score: 7.5}}, PublishedDate: &older, Title: "Title",
+			References: []string{"https://go.dev/issue/1", "https://pkg.go.dev/vuln/GO-2024-0001"}, CweIDs: []string{"CWE-770"}},
+		// Same ID, other package
+		{VulnerabilityID: "CVE-2024-1", PkgName: "example.com/c"},
+	}
+
+	merged := Merge(vulns)
+	if len(merged) != 3 {
+		t.Fatalf("Merge() returned %d findings, want 3: %+v", len(merged), merged)
+	}
+
+	a := merged[0]
+	if a.VulnerabilityID != "CVE-2024-1" || !slices.Equal(a.Aliases, []string{"GHSA-xxxx-yyyy-zzzz", "GO-2024-0001"}) {
+		t.Errorf("IDs = %s %v, want CVE-2024-1 aliased GHSA-xxxx-yyyy-zzzz, GO-2024-0001", a.VulnerabilityID, a.Aliases)
+	}
+	if a.Severity != "HIGH" || a.CVSSScore != 7.5 || len(a.CVSS) != 2 {
+		t.Errorf("severity %s, score %v, CVSS %v, want the highest of both and both CVSS sources", a.Severity, a.CVSSScore, a.CVSS)
+	}
+	if !a.PublishedDate.Equal(older) || a.Title != "Title" {
+		t.Errorf("published %v, title %q, want the earliest date and the first title", a.PublishedDate, a.Title)
+	}
+	wantLinks := []string{"https://avd.aquasec.com/nvd/cve-2024-1", "https://go.dev/issue/1", "https://pkg.go.dev/vuln/GO-2024-0001"}
+	if !slices.Equal(a.Links(), wantLinks) || !slices.Equal(a.CweIDs, []string{"CWE-400", "CWE-770"}) {
+		t.Errorf("links %v, CWE IDs %v, want those of all scanners once", a.Links(), a.CweIDs)
+	}
+	if a.FixedVersion != "1.2.5" {
+		t.Errorf("FixedVersion = %q, want the highest fix of the installed line", a.FixedVersion)
+	}
+	if merged[1].VulnerabilityID != "CVE-2024-2" || merged[2].PkgName != "example.com/c" {
+		t.Errorf("unrelated findings not kept in order: %+v", merged[1:])
+	}
+}
+
+func TestMergeFixedVersions(t *testing.T) {
+	tests := []struct {
+		installed, a, b string
+		want            string
+	}{
+		{"v1.0.0", "1.0.1", "", "1.0.1"},
+		{"v1.0.0", "", "v1.0.2", "v1.0.2"},
+		{"v1.0.0", "1.0.1", "1.0.2", "1.0.2"},
+		{"v1.0.0", "1.0.1", "1.1.0", "1.0.1"},
+		{"v1.0.0", "unknown", "", "unknown"},
+		{"v1.0.0", "", "", ""},
+	}
+	for _, tt := range tests {
+		if got := mergeFixedVersions(tt.installed, tt.a, tt.b); got != tt.want {
+			t.Errorf("mergeFixedVersions(%q, %q, %q) = %q, want %q", tt.installed, tt.a, tt.b, got, tt.want)
+		}
+	}
+}
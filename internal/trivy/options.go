@@ -0,0 +1,91 @@
+package trivy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tamcore/go-autobump/internal/config"
+)
+
+// AllowedExtraArgs are the flags of "trivy fs" that ScanOptions.ExtraArgs
+// may pass, and whether each takes a value. Flags that change the output
+// format or destination, the scanners or the scanned package types, or that
+// run code, are left out, since go-autobump relies on the JSON report of
+// vulnerabilities in Go modules.
+var AllowedExtraArgs = map[string]bool{
+	"--cache-dir":            true,
+	"--detection-priority":   true,
+	"--ignorefile":           true,
+	"--insecure":             false,
+	"--no-progress":          false,
+	"--offline-scan":         false,
+	"--severity":             true,
+	"--skip-dirs":            true,
+	"--skip-files":           true,
+	"--skip-version-check":   false,
+	"--timeout":              true,
+	"--vex":                  true,
+	"--vuln-severity-source": true,
+}
+
+// NewScanOptions returns the scan options configured in cfg
+func NewScanOptions(cfg *config.Config) ScanOptions {
+	return ScanOptions{
+		SkipDBUpdate:   cfg.SkipTrivyDBUpdate,
+		IgnoreUnfixed:  cfg.Trivy.IgnoreUnfixed,
+		DBRepositories: cfg.Trivy.DBRepositories,
+		Parallel:       cfg.Trivy.Parallel,
+		ExtraArgs:      cfg.Trivy.ExtraArgs,
+	}
+}
+
+// ValidateExtraArgs returns an error if args contain a flag not in
+// AllowedExtraArgs, or anything but flags and their values
+func ValidateExtraArgs(args []string) error {
+	for i := 0; i < len(args); i++ {
+		name, _, hasValue := strings.Cut(args[i], "=")
+		takesValue, ok := AllowedExtraArgs[name]
+		if !ok {
+			if !strings.HasPrefix(name, "-") {
+				return fmt.Errorf("trivy argument %q is not a flag", args[i])
+			}
+			return fmt.Errorf("trivy flag %s is not allowed", name)
+		}
+		if takesValue && !hasValue {
+			if i+1 == len(args) {
+				return fmt.Errorf("trivy flag %s needs a value", name)
+			}
+			i++
+		}
+		if !takesValue && hasValue {
+			return fmt.Errorf("trivy flag %s takes no value", name)
+		}
+	}
+	return nil
+}
+
+// args returns the trivy flags of the options
+func (o ScanOptions) args() ([]string, error) {
+	if err := ValidateExtraArgs(o.ExtraArgs); err != nil {
+		return nil, err
+	}
+	if o.Parallel < 0 {
+		return nil, fmt.Errorf("invalid trivy parallelism %d", o.Parallel)
+	}
+
+	var args []string
+	if o.SkipDBUpdate {
+		args = append(args, "--skip-db-update")
+	}
+	if o.IgnoreUnfixed {
+		args = append(args, "--ignore-unfixed")
+	}
+	for _, repo := range o.DBRepositories {
+		args = append(args, "--db-repository", repo)
+	}
+	if o.Parallel > 0 {
+		args = append(args, "--parallel", strconv.Itoa(o.Parallel))
+	}
+	return append(args, o.ExtraArgs...), nil
+}
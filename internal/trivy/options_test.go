@@ -0,0 +1,56 @@
+package trivy
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestValidateExtraArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+	}{
+		{"flag with value", []string{"--skip-dirs", "testdata", "--timeout=10m"}, false},
+		{"flag without value", []string{"--offline-scan", "--no-progress"}, false},
+		{"not allowed", []string{"--format", "table"}, true},
+		{"not allowed with value", []string{"--output=/etc/passwd"}, true},
+		{"extra target", []string{"--severity", "HIGH", "/"}, true},
+		{"missing value", []string{"--skip-files"}, true},
+		{"value of a switch", []string{"--insecure=false"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateExtraArgs(tt.args); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateExtraArgs(%v) = %v, wantErr %v", tt.args, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestScanOptionsArgs(t *testing.T) {
+	opts := ScanOptions{
+		SkipDBUpdate:   true,
+		IgnoreUnfixed:  true,
+		DBRepositories: []string{"mirror.example.com/trivy-db", "ghcr.io/aquasecurity/trivy-db"},
+		Parallel:       4,
+		ExtraArgs:      []string{"--skip-dirs", "testdata"},
+	}
+	got, err := opts.args()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"--skip-db-update", "--ignore-unfixed",
+		"--db-repository", "mirror.example.com/trivy-db", "--db-repository", "ghcr.io/aquasecurity/trivy-db",
+		"--parallel", "4", "--skip-dirs", "testdata",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("args() = %v, want %v", got, want)
+	}
+
+	if _, err := (ScanOptions{ExtraArgs: []string{"--config", "trivy.yaml"}}).args(); err == nil {
+		t.Error("args() of a flag not allowed succeeded")
+	}
+}
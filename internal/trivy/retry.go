@@ -0,0 +1,84 @@
+package trivy
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/tamcore/go-autobump/internal/output"
+	"github.com/tamcore/go-autobump/internal/runner"
+)
+
+// RetryPolicy controls how often a Trivy invocation that failed with a
+// transient error, such as a network error while downloading the DB, is
+// retried
+type RetryPolicy struct {
+	// Retries is the number of retries after the first attempt
+	Retries int
+	// Backoff is the wait before the first retry; it doubles with each retry
+	Backoff time.Duration
+}
+
+var retryPolicy = RetryPolicy{}
+
+// SetRetryPolicy sets the retry policy of all Trivy invocations
+func SetRetryPolicy(p RetryPolicy) {
+	retryPolicy = p
+}
+
+// transientMarkers are stderr fragments of failures that may succeed when
+// retried: network errors and overloaded or rate-limiting registries
+var transientMarkers = []string{
+	"connection reset",
+	"connection refused",
+	"i/o timeout",
+	"tls handshake timeout",
+	"temporary failure in name resolution",
+	"unexpected eof",
+	"too many requests",
+	"toomanyrequests",
+	"503 service unavailable",
+	"502 bad gateway",
+	"504 gateway timeout",
+	"db download error",
+	"oci artifact error",
+}
+
+// isTransient reports whether a failed Trivy invocation is worth retrying.
+// Everything else, e.g. an invalid flag or an unreadable target, fails the
+// same way again. Failures caused by our own timeouts are never retried.
+func isTransient(err error, stderr []byte) bool {
+	if err == nil || errors.Is(err, context.DeadlineExceeded) || runner.Context().Err() != nil {
+		return false
+	}
+	msg := strings.ToLower(string(stderr))
+	for _, marker := range transientMarkers {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// runWithRetry runs trivy with args, retrying transient failures as
+// configured. A run that produced output counts as successful, since Trivy
+// exits non-zero when it finds vulnerabilities.
+func runWithRetry(args ...string) (stdout, stderr []byte, err error) {
+	backoff := retryPolicy.Backoff
+	for attempt := 0; ; attempt++ {
+		stdout, stderr, err = runner.Run("", runner.Trivy, args...)
+		if err == nil || len(stdout) > 0 || attempt >= retryPolicy.Retries || !isTransient(err, stderr) {
+			return stdout, stderr, err
+		}
+
+		output.Warnf("trivy failed with a transient error, retrying in %s (%d/%d): %v",
+			backoff, attempt+1, retryPolicy.Retries, err)
+		select {
+		case <-time.After(backoff):
+		case <-runner.Context().Done():
+			return stdout, stderr, err
+		}
+		backoff *= 2
+	}
+}
@@ -0,0 +1,65 @@
+package trivy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/tamcore/go-autobump/internal/runner"
+)
+
+// flakyRunner fails with stderr until it has been run failures times
+type flakyRunner struct {
+	failures int
+	stderr   string
+	runs     int
+}
+
+func (r *flakyRunner) Run(_ context.Context, _, _ string, _ ...string) ([]byte, []byte, error) {
+	r.runs++
+	if r.runs <= r.failures {
+		return nil, []byte(r.stderr), errors.New("exit status 1")
+	}
+	return []byte(`{"Results":[]}`), nil, nil
+}
+
+func TestRunWithRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int
+		stderr   string
+		wantRuns int
+		wantErr  bool
+	}{
+		{"succeeds", 0, "", 1, false},
+		{"transient", 2, "FATAL db download error: dial tcp: i/o timeout", 3, false},
+		{"retries exhausted", 5, "GET https://ghcr.io: TOOMANYREQUESTS", 3, true},
+		{"deterministic", 2, "FATAL unknown flag: --bogus", 1, true},
+	}
+
+	defer runner.Set(runner.Default())
+	defer SetRetryPolicy(retryPolicy)
+	SetRetryPolicy(RetryPolicy{Retries: 2})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &flakyRunner{failures: tt.failures, stderr: tt.stderr}
+			runner.Set(stub)
+
+			_, _, err := runWithRetry("fs", ".")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("runWithRetry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if stub.runs != tt.wantRuns {
+				t.Errorf("trivy ran %d times, want %d", stub.runs, tt.wantRuns)
+			}
+		})
+	}
+}
+
+func TestIsTransientTimeout(t *testing.T) {
+	err := context.DeadlineExceeded
+	if isTransient(err, []byte("dial tcp: i/o timeout")) {
+		t.Error("a scan stopped by its timeout is retried")
+	}
+}
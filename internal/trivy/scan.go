@@ -1,20 +1,142 @@
 package trivy
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
-	"os/exec"
+	"os"
+	"path/filepath"
+	"strings"
 )
 
 // ScanOptions configures the trivy scan behavior
 type ScanOptions struct {
 	SkipDBUpdate bool
+	// IgnoreUnfixed drops vulnerabilities without a fixed version
+	IgnoreUnfixed bool
+	// DBRepositories are the OCI repositories to download the vulnerability
+	// database from, tried in order (default: Trivy's)
+	DBRepositories []string
+	// Parallel is the number of files Trivy analyzes in parallel (0: Trivy's
+	// default)
+	Parallel int
+	// ExtraArgs are further flags of "trivy fs", each of which must be in
+	// AllowedExtraArgs
+	ExtraArgs []string
 }
 
 // Scan runs Trivy against the go.mod file
-// and returns parsed vulnerability results
+// and returns parsed vulnerability results, or consults the Source set with
+// SetSource instead
 func Scan(goModPath string, opts ...ScanOptions) (ScanResult, error) {
+	if source != nil {
+		return source.Scan(goModPath)
+	}
+
+	// Scan the go.mod file directly, not the directory
+	// This prevents picking up vulnerabilities from nested go.mod files
+	output, err := runTrivy(goModPath, opts...)
+	if err != nil {
+		return ScanResult{}, err
+	}
+
+	// Convert to our internal format
+	result := ScanResult{Target: goModPath}
+	for _, trivyResult := range output.Results {
+		// Only process Go module results
+		if trivyResult.Type != "gomod" {
+			continue
+		}
+		result.Vulnerabilities = append(result.Vulnerabilities, convertTrivyResult(trivyResult)...)
+	}
+
+	return result, nil
+}
+
+// ScanRepo runs a single Trivy scan over the repository root and attributes
+// the results back to the owning go.mod files via each result's Target path.
+// This avoids loading the Trivy DB and parsing its output once per module.
+// The returned map contains an entry for every given go.mod path. With a
+// Source set, each go.mod file is looked up in it instead.
+func ScanRepo(root string, goModPaths []string, opts ...ScanOptions) (map[string]ScanResult, error) {
+	if source != nil {
+		results := make(map[string]ScanResult, len(goModPaths))
+		for _, goModPath := range goModPaths {
+			result, err := source.Scan(goModPath)
+			if err != nil {
+				return nil, err
+			}
+			results[goModPath] = result
+		}
+		return results, nil
+	}
+
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	// A single go.mod file was given as the target, nothing to attribute
+	if info, err := os.Stat(absRoot); err == nil && !info.IsDir() {
+		absRoot = filepath.Dir(absRoot)
+	}
+
+	// Index the go.mod files by their path relative to the scan root,
+	// which is how Trivy reports result targets
+	results := make(map[string]ScanResult, len(goModPaths))
+	byTarget := make(map[string]string, len(goModPaths))
+	for _, goModPath := range goModPaths {
+		results[goModPath] = ScanResult{Target: goModPath}
+
+		absPath, err := filepath.Abs(goModPath)
+		if err != nil {
+			return nil, err
+		}
+		relPath, err := filepath.Rel(absRoot, absPath)
+		if err != nil {
+			return nil, err
+		}
+		byTarget[filepath.ToSlash(relPath)] = goModPath
+	}
+
+	output, err := runTrivy(absRoot, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, trivyResult := range output.Results {
+		if trivyResult.Type != "gomod" {
+			continue
+		}
+
+		goModPath, ok := byTarget[normalizeTarget(trivyResult.Target)]
+		if !ok {
+			// Excluded or otherwise undiscovered module
+			continue
+		}
+
+		result := results[goModPath]
+		result.Vulnerabilities = append(result.Vulnerabilities, convertTrivyResult(trivyResult)...)
+		results[goModPath] = result
+	}
+
+	return results, nil
+}
+
+// normalizeTarget maps a Trivy result target onto the relative go.mod path.
+// Older Trivy versions report go.sum instead of go.mod as the target.
+func normalizeTarget(target string) string {
+	target = filepath.ToSlash(filepath.Clean(target))
+	if target == "go.sum" {
+		return "go.mod"
+	}
+	if strings.HasSuffix(target, "/go.sum") {
+		return strings.TrimSuffix(target, "go.sum") + "go.mod"
+	}
+	return target
+}
+
+// runTrivy executes a Trivy filesystem scan against target and parses the JSON output
+func runTrivy(target string, opts ...ScanOptions) (TrivyOutput, error) {
 	// Build trivy command arguments
 	args := []string{
 		"fs",
@@ -23,80 +145,68 @@ func Scan(goModPath string, opts ...ScanOptions) (ScanResult, error) {
 		"--pkg-types", "library",
 	}
 
-	// Check if we should skip DB update
-	if len(opts) > 0 && opts[0].SkipDBUpdate {
-		args = append(args, "--skip-db-update")
+	if len(opts) > 0 {
+		optionArgs, err := opts[0].args()
+		if err != nil {
+			return TrivyOutput{}, err
+		}
+		args = append(args, optionArgs...)
 	}
 
-	// Scan the go.mod file directly, not the directory
-	// This prevents picking up vulnerabilities from nested go.mod files
-	args = append(args, goModPath)
-
-	cmd := exec.Command("trivy", args...)
-
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+	args = append(args, target)
 
-	if err := cmd.Run(); err != nil {
+	stdout, stderr, err := runWithRetry(args...)
+	if err != nil {
 		// Trivy returns non-zero exit code when vulnerabilities are found
 		// So we only fail if there's no output
-		if stdout.Len() == 0 {
-			return ScanResult{}, fmt.Errorf("trivy scan failed: %v\nstderr: %s", err, stderr.String())
+		if len(stdout) == 0 {
+			return TrivyOutput{}, fmt.Errorf("trivy scan failed: %w\nstderr: %s", err, stderr)
 		}
 	}
 
 	// Parse JSON output
 	var output TrivyOutput
-	if err := json.Unmarshal(stdout.Bytes(), &output); err != nil {
-		return ScanResult{}, fmt.Errorf("failed to parse trivy output: %w", err)
+	if err := json.Unmarshal(stdout, &output); err != nil {
+		return TrivyOutput{}, fmt.Errorf("failed to parse trivy output: %w", err)
 	}
 
-	// Convert to our internal format
-	return convertTrivyOutput(output, goModPath)
+	return output, nil
 }
 
-// convertTrivyOutput transforms Trivy's JSON output into our internal ScanResult format
-func convertTrivyOutput(output TrivyOutput, goModPath string) (ScanResult, error) {
-	result := ScanResult{
-		Target: goModPath,
-	}
-
+// convertTrivyResult transforms a single Trivy result into our internal vulnerability format
+func convertTrivyResult(trivyResult TrivyResult) []Vulnerability {
 	// Build a map of package names to their indirect status
+	// Newer Trivy versions report Relationship and no longer set Indirect
 	packageIndirect := make(map[string]bool)
+	for _, pkg := range trivyResult.Packages {
+		packageIndirect[pkg.Name] = pkg.Indirect || pkg.Relationship == "indirect"
+	}
 
-	for _, trivyResult := range output.Results {
-		// Only process Go module results
-		if trivyResult.Type != "gomod" {
-			continue
-		}
-
-		// Map package indirect status
-		for _, pkg := range trivyResult.Packages {
-			packageIndirect[pkg.Name] = pkg.Indirect
-		}
-
-		// Convert vulnerabilities
-		for _, trivyVuln := range trivyResult.Vulnerabilities {
-			vuln := Vulnerability{
-				VulnerabilityID:  trivyVuln.VulnerabilityID,
-				PkgName:          trivyVuln.PkgName,
-				InstalledVersion: trivyVuln.InstalledVersion,
-				FixedVersion:     trivyVuln.FixedVersion,
-				Severity:         trivyVuln.Severity,
-				Title:            trivyVuln.Title,
-				Description:      trivyVuln.Description,
-				PrimaryURL:       trivyVuln.PrimaryURL,
-				CVSS:             trivyVuln.CVSS,
-				Indirect:         packageIndirect[trivyVuln.PkgName],
-				CVSSScore:        getHighestCVSSScore(trivyVuln.CVSS),
-			}
-
-			result.Vulnerabilities = append(result.Vulnerabilities, vuln)
+	var vulns []Vulnerability
+	for _, trivyVuln := range trivyResult.Vulnerabilities {
+		vuln := Vulnerability{
+			VulnerabilityID:  trivyVuln.VulnerabilityID,
+			Aliases:          trivyVuln.VendorIDs,
+			PkgName:          trivyVuln.PkgName,
+			InstalledVersion: trivyVuln.InstalledVersion,
+			FixedVersion:     SelectFixedVersion(trivyVuln.InstalledVersion, trivyVuln.FixedVersion),
+			Severity:         trivyVuln.Severity,
+			Title:            trivyVuln.Title,
+			Description:      trivyVuln.Description,
+			PrimaryURL:       trivyVuln.PrimaryURL,
+			References:       trivyVuln.References,
+			CweIDs:           trivyVuln.CweIDs,
+			CVSS:             trivyVuln.CVSS,
+			PublishedDate:    trivyVuln.PublishedDate,
+			LastModifiedDate: trivyVuln.LastModifiedDate,
+			Indirect:         packageIndirect[trivyVuln.PkgName],
+			CVSSScore:        getHighestCVSSScore(trivyVuln.CVSS),
 		}
+		learnAliases(vuln)
+		vulns = append(vulns, vuln)
 	}
 
-	return result, nil
+	return vulns
 }
 
 // getHighestCVSSScore extracts the highest CVSS v3 score from available sources
@@ -111,3 +221,22 @@ func getHighestCVSSScore(cvssMap map[string]CVSS) float64 {
 
 	return highest
 }
+
+// ParseReport converts a Trivy JSON report, e.g. produced by another scanner,
+// into a ScanResult for goModPath. Every gomod result in the report is
+// attributed to that module.
+func ParseReport(data []byte, goModPath string) (ScanResult, error) {
+	var output TrivyOutput
+	if err := json.Unmarshal(data, &output); err != nil {
+		return ScanResult{}, fmt.Errorf("failed to parse report: %w", err)
+	}
+
+	result := ScanResult{Target: goModPath}
+	for _, trivyResult := range output.Results {
+		if trivyResult.Type != "gomod" {
+			continue
+		}
+		result.Vulnerabilities = append(result.Vulnerabilities, convertTrivyResult(trivyResult)...)
+	}
+	return result, nil
+}
@@ -0,0 +1,64 @@
+package trivy
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/tamcore/go-autobump/internal/runner"
+)
+
+// repoRunner answers every Trivy run with output and counts the runs
+type repoRunner struct {
+	output string
+	runs   int
+}
+
+func (r *repoRunner) Run(_ context.Context, _, _ string, _ ...string) ([]byte, []byte, error) {
+	r.runs++
+	return []byte(r.output), nil, nil
+}
+
+func TestScanRepo(t *testing.T) {
+	stub := &repoRunner{output: `{"Results":[
+		{"Target":"go.mod","Type":"gomod","Vulnerabilities":[{"VulnerabilityID":"CVE-1","PkgName":"example.com/a","InstalledVersion":"v1.0.0"}]},
+		{"Target":"sub/go.sum","Type":"gomod","Vulnerabilities":[{"VulnerabilityID":"CVE-2","PkgName":"example.com/b","InstalledVersion":"v1.0.0"}]},
+		{"Target":"excluded/go.mod","Type":"gomod","Vulnerabilities":[{"VulnerabilityID":"CVE-3","PkgName":"example.com/c","InstalledVersion":"v1.0.0"}]},
+		{"Target":"Dockerfile","Type":"dockerfile","Vulnerabilities":[{"VulnerabilityID":"CVE-4","PkgName":"openssl","InstalledVersion":"3.0.0"}]}
+	]}`}
+	defer runner.Set(runner.Default())
+	runner.Set(stub)
+
+	root := t.TempDir()
+	rootMod := filepath.Join(root, "go.mod")
+	subMod := filepath.Join(root, "sub", "go.mod")
+	emptyMod := filepath.Join(root, "empty", "go.mod")
+	results, err := ScanRepo(root, []string{rootMod, subMod, emptyMod})
+	if err != nil {
+		t.Fatalf("ScanRepo() error = %v", err)
+	}
+
+	if stub.runs != 1 {
+		t.Errorf("trivy ran %d times, want once for the repository", stub.runs)
+	}
+	want := map[string][]string{rootMod: {"CVE-1"}, subMod: {"CVE-2"}, emptyMod: nil}
+	if len(results) != len(want) {
+		t.Errorf("ScanRepo() returned %d results, want one per go.mod: %+v", len(results), results)
+	}
+	for goModPath, ids := range want {
+		result, ok := results[goModPath]
+		if !ok {
+			t.Errorf("no result for %s", goModPath)
+			continue
+		}
+		if result.Target != goModPath || len(result.Vulnerabilities) != len(ids) {
+			t.Errorf("result for %s = %+v, want %v", goModPath, result, ids)
+			continue
+		}
+		for i, id := range ids {
+			if result.Vulnerabilities[i].VulnerabilityID != id {
+				t.Errorf("result for %s = %+v, want %v", goModPath, result.Vulnerabilities, ids)
+			}
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package trivy
+
+// Source produces the vulnerabilities of go.mod files in place of Trivy,
+// e.g. from the imported report of another scanner. Scan is called again
+// after each update to verify it, so a Source must reflect the current
+// state of the module rather than the state it was imported for.
+type Source interface {
+	Scan(goModPath string) (ScanResult, error)
+}
+
+// source replaces Trivy in Scan and ScanRepo if set
+var source Source
+
+// SetSource makes Scan and ScanRepo consult s instead of running Trivy;
+// nil restores Trivy
+func SetSource(s Source) {
+	source = s
+}
+
+// HasSource reports whether a Source replaces Trivy
+func HasSource() bool {
+	return source != nil
+}
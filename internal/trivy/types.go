@@ -1,5 +1,7 @@
 package trivy
 
+import "time"
+
 // ScanResult represents the result of scanning a single go.mod file
 type ScanResult struct {
 	Target          string          `json:"Target"`
@@ -9,16 +11,56 @@ type ScanResult struct {
 // Vulnerability represents a single vulnerability found by Trivy
 type Vulnerability struct {
 	VulnerabilityID  string          `json:"VulnerabilityID"`
+	Aliases          []string        `json:"Aliases,omitempty"` // Other IDs of the vulnerability, e.g. the GHSA ID of a CVE
 	PkgName          string          `json:"PkgName"`
+	Advisory         string          `json:"Advisory,omitempty"` // Module or package the advisory names, if not PkgName
 	InstalledVersion string          `json:"InstalledVersion"`
 	FixedVersion     string          `json:"FixedVersion"`
 	Severity         string          `json:"Severity"`
 	Title            string          `json:"Title"`
 	Description      string          `json:"Description"`
 	PrimaryURL       string          `json:"PrimaryURL"`
+	References       []string        `json:"References,omitempty"` // Further advisory, fix and report URLs
+	CweIDs           []string        `json:"CweIDs,omitempty"`     // Weaknesses, e.g. CWE-400
 	CVSS             map[string]CVSS `json:"CVSS"`
-	Indirect         bool            `json:"-"` // Populated from package relationship
-	CVSSScore        float64         `json:"-"` // Computed highest CVSS score
+	PublishedDate    *time.Time      `json:"PublishedDate,omitempty"`
+	LastModifiedDate *time.Time      `json:"LastModifiedDate,omitempty"`
+	EPSS             float64         `json:"EPSS,omitempty"`       // Exploit probability, populated on request
+	KEV              bool            `json:"KEV,omitempty"`        // Known exploited (CISA KEV), populated on request
+	Exploit          string          `json:"Exploit,omitempty"`    // Exploit maturity, populated on request
+	ImportedBy       []string        `json:"ImportedBy,omitempty"` // Own packages importing the module, populated on request
+	Indirect         bool            `json:"Indirect,omitempty"`   // Populated from package relationship
+	CVSSScore        float64         `json:"-"`                    // Computed highest CVSS score
+}
+
+// AdvisoryPath returns the module or package path the advisory of v names,
+// which the module v is reported for should be or contain
+func (v Vulnerability) AdvisoryPath() string {
+	if v.Advisory != "" {
+		return v.Advisory
+	}
+	return v.PkgName
+}
+
+// Exploit maturities, from the least mature
+const (
+	// ExploitPublic marks vulnerabilities with published exploit code
+	ExploitPublic = "public"
+	// ExploitWeaponized marks vulnerabilities exploited in the wild
+	ExploitWeaponized = "weaponized"
+)
+
+// ExploitRank orders exploit maturities: 0 for none known, 1 for public and
+// 2 for weaponized
+func ExploitRank(maturity string) int {
+	switch maturity {
+	case ExploitWeaponized:
+		return 2
+	case ExploitPublic:
+		return 1
+	default:
+		return 0
+	}
 }
 
 // CVSS represents CVSS scoring information
@@ -52,6 +94,7 @@ type TrivyPackage struct {
 // TrivyVulnerability represents the raw vulnerability from Trivy JSON
 type TrivyVulnerability struct {
 	VulnerabilityID  string          `json:"VulnerabilityID"`
+	VendorIDs        []string        `json:"VendorIDs"`
 	PkgName          string          `json:"PkgName"`
 	InstalledVersion string          `json:"InstalledVersion"`
 	FixedVersion     string          `json:"FixedVersion"`
@@ -59,5 +102,9 @@ type TrivyVulnerability struct {
 	Title            string          `json:"Title"`
 	Description      string          `json:"Description"`
 	PrimaryURL       string          `json:"PrimaryURL"`
+	References       []string        `json:"References"`
+	CweIDs           []string        `json:"CweIDs"`
 	CVSS             map[string]CVSS `json:"CVSS"`
+	PublishedDate    *time.Time      `json:"PublishedDate"`
+	LastModifiedDate *time.Time      `json:"LastModifiedDate"`
 }
@@ -0,0 +1,125 @@
+package trivy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/tamcore/go-autobump/internal/runner"
+	"golang.org/x/mod/semver"
+)
+
+// MinimumVersion is the oldest Trivy release that reports the package
+// Relationship field, which indirect dependency detection relies on
+const MinimumVersion = "0.49.0"
+
+// Version returns the installed Trivy version, e.g. "0.50.1"
+func Version() (string, error) {
+	stdout, stderr, err := runner.Run("", runner.Trivy, "--version", "--format", "json")
+	if err != nil {
+		return "", fmt.Errorf("failed to run trivy --version (is trivy installed and in PATH?): %v\nstderr: %s", err, stderr)
+	}
+
+	return parseVersionOutput(string(stdout))
+}
+
+// DBInfo describes the downloaded Trivy vulnerability database
+type DBInfo struct {
+	// UpdatedAt is when the database was built
+	UpdatedAt time.Time `json:"UpdatedAt"`
+	// NextUpdate is when a newer build is expected
+	NextUpdate time.Time `json:"NextUpdate"`
+	// DownloadedAt is when the database was downloaded
+	DownloadedAt time.Time `json:"DownloadedAt"`
+}
+
+// InstalledDB returns the metadata of the downloaded vulnerability database,
+// or nil if none is downloaded
+func InstalledDB() (*DBInfo, error) {
+	stdout, stderr, err := runner.Run("", runner.Trivy, "--version", "--format", "json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to run trivy --version (is trivy installed and in PATH?): %v\nstderr: %s", err, stderr)
+	}
+
+	return parseDBInfo(stdout)
+}
+
+// parseDBInfo extracts the vulnerability database metadata from the JSON
+// output of "trivy --version"
+func parseDBInfo(out []byte) (*DBInfo, error) {
+	var info struct {
+		VulnerabilityDB *DBInfo `json:"VulnerabilityDB"`
+	}
+	if err := json.Unmarshal(out, &info); err != nil {
+		return nil, fmt.Errorf("failed to parse trivy --version output: %w", err)
+	}
+	if info.VulnerabilityDB == nil || info.VulnerabilityDB.UpdatedAt.IsZero() {
+		return nil, nil
+	}
+	return info.VulnerabilityDB, nil
+}
+
+// parseVersionOutput extracts the version from "trivy --version" output,
+// accepting both the JSON format and the plain "Version: x.y.z" format
+func parseVersionOutput(out string) (string, error) {
+	var info struct {
+		Version string `json:"Version"`
+	}
+	if err := json.Unmarshal([]byte(out), &info); err == nil && info.Version != "" {
+		return info.Version, nil
+	}
+
+	for _, line := range strings.Split(out, "\n") {
+		if v, ok := strings.CutPrefix(strings.TrimSpace(line), "Version:"); ok {
+			return strings.TrimSpace(v), nil
+		}
+	}
+
+	return "", fmt.Errorf("could not determine trivy version from output: %q", out)
+}
+
+// CheckVersion verifies the installed Trivy is at least MinimumVersion.
+// It returns the detected version and an error describing the problem and
+// the suggested upgrade if it is too old.
+func CheckVersion() (string, error) {
+	version, err := Version()
+	if err != nil {
+		return "", err
+	}
+
+	if !IsVersionSupported(version) {
+		return version, fmt.Errorf("trivy %s is older than the minimum supported version %s; "+
+			"indirect dependency detection may be unreliable. Upgrade with your package manager "+
+			"or see https://trivy.dev/latest/getting-started/installation/", version, MinimumVersion)
+	}
+
+	return version, nil
+}
+
+// IsVersionSupported reports whether version is at least MinimumVersion.
+// Unparseable versions (e.g. development builds) are assumed to be supported.
+func IsVersionSupported(version string) bool {
+	v := "v" + strings.TrimPrefix(version, "v")
+	if !semver.IsValid(v) {
+		return true
+	}
+	return semver.Compare(v, "v"+MinimumVersion) >= 0
+}
+
+// CheckDB verifies that Trivy can load its vulnerability database by scanning
+// an empty directory. Unless opts skip the DB update, this also downloads the
+// database if it is missing or outdated.
+func CheckDB(opts ...ScanOptions) error {
+	dir, err := os.MkdirTemp("", "go-autobump-dbcheck-")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	if _, err := runTrivy(dir, opts...); err != nil {
+		return fmt.Errorf("trivy database is not accessible: %w", err)
+	}
+	return nil
+}
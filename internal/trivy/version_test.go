@@ -0,0 +1,64 @@
+package trivy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseVersionOutput(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		expected string
+	}{
+		{"json", `{"Version":"0.50.1","VulnerabilityDB":{"Version":2}}`, "0.50.1"},
+		{"text", "Version: 0.45.0\nVulnerability DB:\n  Version: 2\n", "0.45.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseVersionOutput(tt.output)
+			if err != nil {
+				t.Fatalf("parseVersionOutput() error = %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("parseVersionOutput() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIsVersionSupported(t *testing.T) {
+	tests := []struct {
+		version  string
+		expected bool
+	}{
+		{"0.48.3", false},
+		{"0.49.0", true},
+		{"v0.57.1", true},
+		{"dev", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			if got := IsVersionSupported(tt.version); got != tt.expected {
+				t.Errorf("IsVersionSupported(%q) = %v, want %v", tt.version, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseDBInfo(t *testing.T) {
+	info, err := parseDBInfo([]byte(`{"Version":"0.50.1","VulnerabilityDB":{"Version":2,"UpdatedAt":"2024-03-01T06:00:00Z","NextUpdate":"2024-03-01T12:00:00Z","DownloadedAt":"2024-03-01T07:00:00Z"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2024, 3, 1, 6, 0, 0, 0, time.UTC); info == nil || !info.UpdatedAt.Equal(want) {
+		t.Errorf("parseDBInfo() = %+v, want UpdatedAt %v", info, want)
+	}
+
+	info, err = parseDBInfo([]byte(`{"Version":"0.50.1"}`))
+	if err != nil || info != nil {
+		t.Errorf("parseDBInfo(no DB) = %+v, %v, want nil", info, err)
+	}
+}
@@ -0,0 +1,55 @@
+package updater
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/tamcore/go-autobump/internal/gomod"
+	"golang.org/x/mod/semver"
+)
+
+// Alignment raises the requirement of a module on a dependency to the
+// version another module of the repository requires
+type Alignment struct {
+	Module string
+	From   string
+	To     string
+}
+
+// AlignTargets returns the highest version of each of deps that any of
+// goModFiles requires, the version to align the modules requiring it to.
+// go.mod files that cannot be parsed are skipped.
+func AlignTargets(goModFiles, deps []string) map[string]string {
+	targets := make(map[string]string, len(deps))
+	for _, goModFile := range goModFiles {
+		parser, err := gomod.NewParser(goModFile)
+		if err != nil {
+			continue
+		}
+		for _, dep := range deps {
+			if version := parser.GetVersion(dep); version != "" && semver.Compare(version, targets[dep]) > 0 {
+				targets[dep] = version
+			}
+		}
+	}
+	return targets
+}
+
+// Alignments returns the requirements of the session's module that are
+// lower than their version in targets, ordered by module. Dependencies the
+// module does not require are left alone; versions are only ever raised.
+func Alignments(sess *gomod.Session, targets map[string]string) ([]Alignment, error) {
+	parser, err := sess.Parser()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse go.mod: %w", err)
+	}
+
+	var alignments []Alignment
+	for dep, target := range targets {
+		if current := parser.GetVersion(dep); current != "" && semver.Compare(current, target) < 0 {
+			alignments = append(alignments, Alignment{Module: dep, From: current, To: target})
+		}
+	}
+	sort.Slice(alignments, func(i, j int) bool { return alignments[i].Module < alignments[j].Module })
+	return alignments, nil
+}
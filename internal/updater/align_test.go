@@ -0,0 +1,52 @@
+package updater
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/tamcore/go-autobump/internal/gomod"
+)
+
+func TestAlignments(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, requires string) string {
+		path := filepath.Join(dir, name, "go.mod")
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		goMod := "module example.com/" + name + "\n\ngo 1.22\n\nrequire (\n" + requires + ")\n"
+		if err := os.WriteFile(path, []byte(goMod), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	api := write("api", "\texample.com/a v1.2.0\n\texample.com/b v1.0.0\n\texample.com/c v1.0.0\n")
+	cli := write("cli", "\texample.com/a v1.1.0\n\texample.com/b v1.1.0\n")
+	worker := write("worker", "\texample.com/a v1.3.0\n")
+
+	targets := AlignTargets([]string{api, cli, worker, filepath.Join(dir, "missing", "go.mod")},
+		[]string{"example.com/a", "example.com/b", "example.com/d"})
+	want := map[string]string{"example.com/a": "v1.3.0", "example.com/b": "v1.1.0"}
+	if !reflect.DeepEqual(targets, want) {
+		t.Fatalf("AlignTargets() = %v, want %v", targets, want)
+	}
+
+	for _, tt := range []struct {
+		goModFile string
+		want      []Alignment
+	}{
+		{api, []Alignment{{Module: "example.com/a", From: "v1.2.0", To: "v1.3.0"}, {Module: "example.com/b", From: "v1.0.0", To: "v1.1.0"}}},
+		{cli, []Alignment{{Module: "example.com/a", From: "v1.1.0", To: "v1.3.0"}}},
+		{worker, nil},
+	} {
+		got, err := Alignments(gomod.NewSession(tt.goModFile), targets)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Alignments(%s) = %v, want %v", tt.goModFile, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package updater
+
+import (
+	"fmt"
+
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/gomod"
+	"github.com/tamcore/go-autobump/internal/output"
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+// UpdateBatch applies the fixes of all vulns together, at the versions the
+// fix-version strategy picks like for single updates, and verifies them
+// with a single scan instead of one scan per update. If a group of
+// updates cannot be applied together, it is bisected to isolate the failing
+// ones. It returns the vulnerabilities the batch did not fix, which the
+// caller should retry individually with Update.
+func UpdateBatch(sess *gomod.Session, vulns []trivy.Vulnerability, cfg *config.Config) ([]trivy.Vulnerability, error) {
+	var batch, remaining []trivy.Vulnerability
+	for _, vuln := range vulns {
+		if (!cfg.AllowMajor && gomod.IsMajorVersionBump(vuln.InstalledVersion, vuln.FixedVersion)) ||
+			CheckAdvisoryPath(vuln, cfg) != nil {
+			// Leave the error reporting to the individual update
+			remaining = append(remaining, vuln)
+			continue
+		}
+		batch = append(batch, vuln)
+	}
+
+	if len(batch) == 0 {
+		return remaining, nil
+	}
+
+	output.Status(output.IconUpdate, "  Applying %d updates as a batch", len(batch))
+
+	failed, err := applyGroup(sess, batch, cfg)
+	if err != nil {
+		return nil, err
+	}
+	remaining = append(remaining, failed...)
+
+	if !cfg.SkipTidy {
+		if err := sess.Tidy(); err != nil {
+			return nil, fmt.Errorf("go mod tidy failed: %w", err)
+		}
+	}
+
+	// One scan verifies the whole batch
+	scanOpts := trivy.NewScanOptions(cfg)
+	result, err := trivy.Scan(sess.GoModPath, scanOpts)
+	if err != nil {
+		return nil, fmt.Errorf("verification scan failed: %w", err)
+	}
+
+	applied := 0
+	for _, vuln := range batch {
+		if contains(failed, vuln) {
+			continue
+		}
+		// The scan may report the vulnerability by an alias
+		if contains(result.Vulnerabilities, vuln) {
+			remaining = append(remaining, vuln)
+			continue
+		}
+		applied++
+	}
+
+	output.Status(output.IconInfo, "  Batch fixed %d of %d vulnerabilities", applied, len(batch))
+	return remaining, nil
+}
+
+// applyGroup runs go get for the fix of every vulnerability in group. If
+// resolving or applying any of them fails, the module is restored and the
+// group is split in half and retried, so conflicting updates are isolated
+// without giving up on the rest. An update adding a suspicious module fails
+// like one that cannot be applied.
+// It returns the vulnerabilities whose update could not be applied; a
+// checksum mismatch aborts the batch with an error instead.
+func applyGroup(sess *gomod.Session, group []trivy.Vulnerability, cfg *config.Config) ([]trivy.Vulnerability, error) {
+	snap, err := sess.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, vuln := range group {
+		required, err := requiredModules(sess)
+		if err != nil {
+			return nil, err
+		}
+		vuln.FixedVersion = gomod.MatchIncompatible(vuln.InstalledVersion, vuln.FixedVersion)
+		target, getErr := resolveFixVersion(sess, vuln, cfg)
+		if getErr == nil {
+			getErr = sess.GoGet(vuln.PkgName, target)
+		}
+		if getErr == nil {
+			getErr = CheckAddedModules(sess, required, vuln.AdvisoryPath(), cfg)
+		}
+		if getErr == nil {
+			continue
+		}
+
+		if err := sess.Restore(snap); err != nil {
+			return nil, fmt.Errorf("failed to restore module after failed batch: %w", err)
+		}
+		if isChecksumMismatch(getErr) {
+			return nil, fmt.Errorf("failed to update %s: %w", vuln.PkgName, getErr)
+		}
+		if len(group) == 1 {
+			return group, nil
+		}
+
+		mid := len(group) / 2
+		failedLeft, err := applyGroup(sess, group[:mid], cfg)
+		if err != nil {
+			return nil, err
+		}
+		failedRight, err := applyGroup(sess, group[mid:], cfg)
+		if err != nil {
+			return nil, err
+		}
+		return append(failedLeft, failedRight...), nil
+	}
+
+	return nil, nil
+}
+
+// contains reports whether vulns includes vuln
+func contains(vulns []trivy.Vulnerability, vuln trivy.Vulnerability) bool {
+	for _, v := range vulns {
+		if trivy.SameVulnerability(v, vuln) {
+			return true
+		}
+	}
+	return false
+}
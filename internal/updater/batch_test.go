@@ -0,0 +1,129 @@
+package updater
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/gomod"
+	"github.com/tamcore/go-autobump/internal/runner"
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+// batchRunner emulates "go get" by raising the versions in go.mod, failing
+// for the modules in failing, answers "go list -m -versions" with versions
+// and Trivy scans with trivyOutput. It records the go get targets.
+type batchRunner struct {
+	versions    string
+	failing     []string
+	trivyOutput string
+	gets        []string
+}
+
+func (r *batchRunner) Run(_ context.Context, dir, name string, args ...string) ([]byte, []byte, error) {
+	if name == runner.Trivy {
+		return []byte(r.trivyOutput), nil, nil
+	}
+	switch args[0] {
+	case "list":
+		return []byte(`{"Versions":[` + r.versions + `]}`), nil, nil
+	case "get":
+		goModPath := filepath.Join(dir, "go.mod")
+		data, err := os.ReadFile(goModPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, target := range args[1:] {
+			r.gets = append(r.gets, target)
+			module, version, _ := strings.Cut(target, "@")
+			if slices.Contains(r.failing, module) {
+				return nil, []byte("conflicting requirements"), errors.New("exit status 1")
+			}
+			data = regexp.MustCompile(regexp.QuoteMeta(module)+` v\S+`).ReplaceAll(data, []byte(module+" "+version))
+		}
+		return nil, nil, os.WriteFile(goModPath, data, 0644)
+	}
+	return nil, nil, nil
+}
+
+func TestUpdateBatch(t *testing.T) {
+	const goMod = "module example.com/app\n\ngo 1.22\n\nrequire (\n\texample.com/a v1.0.0\n\texample.com/b v1.0.0\n\texample.com/c v1.0.0\n)\n"
+	vulns := []trivy.Vulnerability{
+		{VulnerabilityID: "CVE-1", PkgName: "example.com/a", InstalledVersion: "v1.0.0", FixedVersion: "1.0.1"},
+		{VulnerabilityID: "CVE-2", PkgName: "example.com/b", InstalledVersion: "v1.0.0", FixedVersion: "1.0.1"},
+		{VulnerabilityID: "CVE-3", PkgName: "example.com/c", InstalledVersion: "v1.0.0", FixedVersion: "1.0.1", Aliases: []string{"GHSA-3"}},
+	}
+	const stillAffected = `{"Results":[{"Target":"go.mod","Type":"gomod","Vulnerabilities":[{"VulnerabilityID":"CVE-3","PkgName":"example.com/c","InstalledVersion":"v1.0.1"}]}]}`
+
+	defer runner.Set(runner.Default())
+	tests := []struct {
+		name          string
+		fixVersion    string
+		failing       []string
+		trivyOutput   string
+		wantRemaining []string
+		wantGoMod     []string
+	}{
+		{
+			name:        "fixed versions",
+			trivyOutput: `{"Results":[]}`,
+			wantGoMod:   []string{"example.com/a v1.0.1", "example.com/b v1.0.1", "example.com/c v1.0.1"},
+		},
+		{
+			name:        "fix-version strategy",
+			fixVersion:  config.FixVersionLatest,
+			trivyOutput: `{"Results":[]}`,
+			wantGoMod:   []string{"example.com/a v1.2.0", "example.com/b v1.2.0", "example.com/c v1.2.0"},
+		},
+		{
+			name:          "bisected around a failing update",
+			failing:       []string{"example.com/b"},
+			trivyOutput:   stillAffected,
+			wantRemaining: []string{"CVE-2", "CVE-3"},
+			wantGoMod:     []string{"example.com/a v1.0.1", "example.com/b v1.0.0", "example.com/c v1.0.1"},
+		},
+		{
+			name:          "still affected under an alias",
+			trivyOutput:   `{"Results":[{"Target":"go.mod","Type":"gomod","Vulnerabilities":[{"VulnerabilityID":"GHSA-3","PkgName":"example.com/c","InstalledVersion":"v1.0.1"}]}]}`,
+			wantRemaining: []string{"CVE-3"},
+			wantGoMod:     []string{"example.com/a v1.0.1", "example.com/b v1.0.1", "example.com/c v1.0.1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &batchRunner{versions: `"v1.0.0","v1.0.1","v1.2.0"`, failing: tt.failing, trivyOutput: tt.trivyOutput}
+			runner.Set(r)
+			goModPath := filepath.Join(t.TempDir(), "go.mod")
+			if err := os.WriteFile(goModPath, []byte(goMod), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg := &config.Config{SkipTidy: true, FixVersion: tt.fixVersion}
+			remaining, err := UpdateBatch(gomod.NewSession(goModPath), vulns, cfg)
+			if err != nil {
+				t.Fatalf("UpdateBatch() error = %v", err)
+			}
+
+			var ids []string
+			for _, vuln := range remaining {
+				ids = append(ids, vuln.VulnerabilityID)
+			}
+			slices.Sort(ids)
+			if !slices.Equal(ids, tt.wantRemaining) {
+				t.Errorf("UpdateBatch() left %v, want %v (go get %v)", ids, tt.wantRemaining, r.gets)
+			}
+			data, _ := os.ReadFile(goModPath)
+			for _, want := range tt.wantGoMod {
+				if !strings.Contains(string(data), want) {
+					t.Errorf("go.mod lacks %q:\n%s", want, data)
+				}
+			}
+		})
+	}
+}
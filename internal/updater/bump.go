@@ -0,0 +1,68 @@
+package updater
+
+import (
+	"fmt"
+
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/gomod"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// Bump is a newer patch release of a direct dependency, proposed without a
+// vulnerability calling for it
+type Bump struct {
+	Module string
+	From   string
+	To     string
+}
+
+// PatchBumps returns the direct dependencies of the session's module that
+// have a newer patch release within their minor line permitted by the
+// session's policy. Replaced dependencies and pseudo-versions, which pin a
+// commit on purpose, are left alone.
+func PatchBumps(sess *gomod.Session) ([]Bump, error) {
+	parser, err := sess.Parser()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse go.mod: %w", err)
+	}
+
+	var bumps []Bump
+	for _, dep := range parser.GetDirectDependencies() {
+		if _, replaced := parser.Replacement(dep.Path); replaced || module.IsPseudoVersion(dep.Version) {
+			continue
+		}
+		versions, err := gomod.ListVersions(sess.Dir, dep.Path)
+		if err != nil {
+			return nil, err
+		}
+		if patch := gomod.LatestPatch(allowedVersions(sess, dep.Path, versions), dep.Version); patch != "" {
+			bumps = append(bumps, Bump{Module: dep.Path, From: dep.Version, To: patch})
+		}
+	}
+	return bumps, nil
+}
+
+// ApplyBump updates the dependency of b to its patch release. It reports
+// whether the dependency was updated: an earlier bump of the same module may
+// have raised it that far already, or dropped it.
+func ApplyBump(sess *gomod.Session, b Bump, cfg *config.Config) (bool, error) {
+	parser, err := sess.Parser()
+	if err != nil {
+		return false, fmt.Errorf("failed to parse go.mod: %w", err)
+	}
+	if current := parser.GetVersion(b.Module); current == "" || semver.Compare(current, b.To) >= 0 {
+		return false, nil
+	}
+
+	if err := sess.GoGet(b.Module, b.To); err != nil {
+		return false, fmt.Errorf("failed to update %s: %w", b.Module, err)
+	}
+
+	if !cfg.SkipTidy {
+		if err := sess.Tidy(); err != nil {
+			return false, fmt.Errorf("go mod tidy failed: %w", err)
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,63 @@
+package updater
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/tamcore/go-autobump/internal/gomod"
+	"github.com/tamcore/go-autobump/internal/runner"
+)
+
+// versionsRunner answers "go list -m -json -versions" with the versions of
+// each module
+type versionsRunner map[string][]string
+
+func (r versionsRunner) Run(_ context.Context, _, _ string, args ...string) ([]byte, []byte, error) {
+	path := args[len(args)-1]
+	out, err := json.Marshal(map[string]any{"Path": path, "Versions": r[path]})
+	return out, nil, err
+}
+
+func TestPatchBumps(t *testing.T) {
+	const goMod = `module example.com/app
+
+go 1.22
+
+require (
+	example.com/current v1.2.3
+	example.com/patch v1.2.0
+	example.com/pseudo v1.2.1-0.20240101000000-abcdef123456
+	example.com/replaced v1.0.0
+	example.com/indirect v1.0.0 // indirect
+)
+
+replace example.com/replaced => ../replaced
+`
+
+	defer runner.Set(runner.Default())
+	runner.Set(versionsRunner{
+		"example.com/current":  {"v1.2.2", "v1.2.3", "v1.3.0"},
+		"example.com/patch":    {"v1.2.0", "v1.2.1", "v1.2.4", "v1.2.5-rc.1", "v1.3.0"},
+		"example.com/pseudo":   {"v1.2.0", "v1.2.1"},
+		"example.com/replaced": {"v1.0.0", "v1.0.1"},
+		"example.com/indirect": {"v1.0.0", "v1.0.1"},
+	})
+
+	goModPath := filepath.Join(t.TempDir(), "go.mod")
+	if err := os.WriteFile(goModPath, []byte(goMod), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := PatchBumps(gomod.NewSession(goModPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Bump{{Module: "example.com/patch", From: "v1.2.0", To: "v1.2.4"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("PatchBumps() = %+v, want %+v", got, want)
+	}
+}
@@ -5,30 +5,34 @@ import (
 
 	"github.com/tamcore/go-autobump/internal/config"
 	"github.com/tamcore/go-autobump/internal/gomod"
+	"github.com/tamcore/go-autobump/internal/output"
 	"github.com/tamcore/go-autobump/internal/trivy"
 )
 
-// UpdateDirect updates a direct dependency to its fixed version
-func UpdateDirect(goModPath string, vuln trivy.Vulnerability, cfg *config.Config) error {
-	moduleDir := gomod.GetModuleDir(goModPath)
+// UpdateDirect updates a direct dependency to its fixed version, or to a newer
+// release containing the fix if configured so
+func UpdateDirect(sess *gomod.Session, vuln trivy.Vulnerability, cfg *config.Config) error {
+	target, err := resolveFixVersion(sess, vuln, cfg)
+	if err != nil {
+		return err
+	}
 
 	// Check for major version bump
-	if gomod.IsMajorVersionBump(vuln.InstalledVersion, vuln.FixedVersion) {
+	if gomod.IsMajorVersionBump(vuln.InstalledVersion, target) {
 		if !cfg.AllowMajor {
-			return fmt.Errorf("major version bump required (%s -> %s), use --allow-major to permit",
-				vuln.InstalledVersion, vuln.FixedVersion)
+			return &MajorBumpError{Module: vuln.PkgName, From: vuln.InstalledVersion, To: target}
 		}
-		fmt.Printf("  ⚠️  Major version bump: %s -> %s\n", vuln.InstalledVersion, vuln.FixedVersion)
+		output.Status(output.IconWarning, "  Major version bump: %s -> %s", vuln.InstalledVersion, target)
 	}
 
 	// Run go get to update the dependency
-	if err := gomod.GoGet(moduleDir, vuln.PkgName, vuln.FixedVersion); err != nil {
+	if err := sess.GoGet(vuln.PkgName, target); err != nil {
 		return fmt.Errorf("failed to update %s: %w", vuln.PkgName, err)
 	}
 
 	// Run go mod tidy unless skipped
 	if !cfg.SkipTidy {
-		if err := gomod.ModTidy(moduleDir); err != nil {
+		if err := sess.Tidy(); err != nil {
 			return fmt.Errorf("go mod tidy failed: %w", err)
 		}
 	}
@@ -0,0 +1,38 @@
+package updater
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/gomod"
+	"github.com/tamcore/go-autobump/internal/runner"
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+func TestUpdateDirectMajorBump(t *testing.T) {
+	const goMod = "module example.com/app\n\ngo 1.22\n\nrequire example.com/a v1.0.0\n"
+	vuln := trivy.Vulnerability{VulnerabilityID: "CVE-1", PkgName: "example.com/a", InstalledVersion: "v1.0.0", FixedVersion: "2.0.0"}
+
+	defer runner.Set(runner.Default())
+	r := &batchRunner{}
+	runner.Set(r)
+	goModPath := filepath.Join(t.TempDir(), "go.mod")
+	if err := os.WriteFile(goModPath, []byte(goMod), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := UpdateDirect(gomod.NewSession(goModPath), vuln, &config.Config{SkipTidy: true})
+	if !errors.Is(err, ErrMajorBumpRequired) {
+		t.Fatalf("UpdateDirect() = %v, want ErrMajorBumpRequired", err)
+	}
+	if len(r.gets) > 0 {
+		t.Errorf("UpdateDirect() ran go get %v without --allow-major", r.gets)
+	}
+
+	if err := UpdateDirect(gomod.NewSession(goModPath), vuln, &config.Config{SkipTidy: true, AllowMajor: true}); err != nil {
+		t.Errorf("UpdateDirect() with --allow-major = %v", err)
+	}
+}
@@ -0,0 +1,29 @@
+package updater
+
+import (
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/gomod"
+	"github.com/tamcore/go-autobump/internal/output"
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+// Eliminate removes the vulnerable module with go mod tidy if the module no
+// longer needs it at all, e.g. after an earlier update dropped the last
+// dependency pulling it in. Removing it is preferred over bumping it. It
+// reports whether the module was removed from go.mod.
+func Eliminate(sess *gomod.Session, vuln trivy.Vulnerability, cfg *config.Config) (bool, error) {
+	if cfg.SkipTidy || !sess.Unneeded(vuln.PkgName) {
+		return false, nil
+	}
+
+	output.Status(output.IconInfo, "  %s is no longer needed, removing it with go mod tidy", vuln.PkgName)
+	if err := sess.Tidy(); err != nil {
+		return false, err
+	}
+
+	parser, err := sess.Parser()
+	if err != nil {
+		return false, err
+	}
+	return parser.GetVersion(vuln.PkgName) == "", nil
+}
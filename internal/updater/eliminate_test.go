@@ -0,0 +1,63 @@
+package updater
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/gomod"
+	"github.com/tamcore/go-autobump/internal/runner"
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+// tidyRunner answers "go mod why" with why and makes "go mod tidy" write tidied to go.mod
+type tidyRunner struct {
+	why    string
+	tidied string
+}
+
+func (r *tidyRunner) Run(_ context.Context, dir, _ string, args ...string) ([]byte, []byte, error) {
+	if len(args) > 1 && args[1] == "tidy" {
+		return nil, nil, os.WriteFile(filepath.Join(dir, "go.mod"), []byte(r.tidied), 0644)
+	}
+	return []byte(r.why), nil, nil
+}
+
+func TestEliminate(t *testing.T) {
+	const goMod = "module example.com/app\n\ngo 1.22\n\nrequire example.com/dep v1.0.0\n"
+	const tidied = "module example.com/app\n\ngo 1.22\n"
+
+	tests := []struct {
+		name     string
+		why      string
+		skipTidy bool
+		want     bool
+	}{
+		{"unneeded", "# example.com/dep\n(main module does not need module example.com/dep)\n", false, true},
+		{"needed", "# example.com/dep\nexample.com/app\nexample.com/dep\n", false, false},
+		{"skip tidy", "# example.com/dep\n(main module does not need module example.com/dep)\n", true, false},
+	}
+
+	defer runner.Set(runner.Default())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner.Set(&tidyRunner{why: tt.why, tidied: tidied})
+
+			goModPath := filepath.Join(t.TempDir(), "go.mod")
+			if err := os.WriteFile(goModPath, []byte(goMod), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			vuln := trivy.Vulnerability{VulnerabilityID: "CVE-1", PkgName: "example.com/dep", FixedVersion: "1.0.1"}
+			got, err := Eliminate(gomod.NewSession(goModPath), vuln, &config.Config{SkipTidy: tt.skipTidy})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Eliminate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package updater
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/tamcore/go-autobump/internal/gomod"
+)
+
+// ErrMajorBumpRequired is returned when the fix needs a major version bump
+// and --allow-major is not set
+var ErrMajorBumpRequired = errors.New("major version bump required")
+
+// ErrNoFixAvailable is returned for a vulnerability without a fixed version
+var ErrNoFixAvailable = errors.New("no fix available")
+
+// ErrNoUpgradePath is returned when no update of a direct dependency pulls
+// in the fix of an indirect vulnerability
+var ErrNoUpgradePath = errors.New("no upgrade path")
+
+// ErrStillVulnerable is returned when a vulnerability is still reported
+// after an update meant to fix it
+var ErrStillVulnerable = errors.New("still vulnerable")
+
+// ErrFixNotPublished matches updates failing because the fixed version is
+// not served by the module proxy yet
+var ErrFixNotPublished = gomod.ErrFixNotPublished
+
+// ErrBuildBroken matches updates failing because packages of the module no
+// longer load afterwards
+var ErrBuildBroken = gomod.ErrBuildBroken
+
+// isChecksumMismatch reports whether err is a download refused by the go
+// command's checksum verification. Such an update is aborted rather than
+// retried another way, which would fetch from the same source again.
+func isChecksumMismatch(err error) bool {
+	var mismatch *gomod.ChecksumMismatchError
+	return errors.As(err, &mismatch)
+}
+
+// MajorBumpError describes the major version bump a fix needs. It matches
+// ErrMajorBumpRequired with errors.Is.
+type MajorBumpError struct {
+	// Module is the module that needs the bump, which for indirect
+	// vulnerabilities is the direct dependency pulling in the fix
+	Module string
+	From   string
+	To     string
+}
+
+func (e *MajorBumpError) Error() string {
+	return fmt.Sprintf("%v for %s (%s -> %s), use --allow-major to permit", ErrMajorBumpRequired, e.Module, e.From, e.To)
+}
+
+func (e *MajorBumpError) Is(target error) bool {
+	return target == ErrMajorBumpRequired
+}
+
+// MajorVariantError reports a vulnerability in one major version line of a
+// module whose fix is only published for another line that go.mod already
+// requires, while the vulnerable line is still needed
+type MajorVariantError struct {
+	// Module is the vulnerable line, e.g. github.com/foo/bar
+	Module string
+	// Variant is the line holding the fix, e.g. github.com/foo/bar/v2
+	Variant        string
+	VariantVersion string
+	// NeededBy is the requirement chain that still pulls in Module, if known
+	NeededBy string
+}
+
+func (e *MajorVariantError) Error() string {
+	msg := fmt.Sprintf("%s is only fixed in %s, which is already required at %s", e.Module, e.Variant, e.VariantVersion)
+	if e.NeededBy != "" {
+		msg += fmt.Sprintf("; %s is still required through %s", e.Module, e.NeededBy)
+	}
+	return msg
+}
+
+// SuspiciousModuleError reports an update refused because it would update,
+// add or replace with a module whose path looks like a mistake, e.g. a
+// lookalike of the advisory's module, until a human confirms it
+type SuspiciousModuleError struct {
+	// Module is the module the update would update, add or replace with
+	Module string
+	// Advisory is the module or package the advisory names
+	Advisory string
+	Reason   string
+}
+
+func (e *SuspiciousModuleError) Error() string {
+	return fmt.Sprintf("refusing to use %s to fix %s: the path %s", e.Module, e.Advisory, e.Reason)
+}
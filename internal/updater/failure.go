@@ -0,0 +1,133 @@
+package updater
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/tamcore/go-autobump/internal/gomod"
+	"github.com/tamcore/go-autobump/internal/scorecard"
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+// Failure kinds of an update that could not be applied
+const (
+	FailureNoFix           = "no-fix-available"
+	FailureMajorBump       = "major-bump-required"
+	FailureMajorVariant    = "major-variant-in-use"
+	FailureFixNotPublished = "fix-not-published"
+	FailureReplaced        = "replaced"
+	FailurePolicy          = "blocked-by-policy"
+	FailureSuspicious      = "suspicious-module"
+	FailureChecksum        = "checksum-mismatch"
+	FailureIncompatible    = "incompatible-versions"
+	FailureNoUpgradePath   = "no-upgrade-path"
+	FailureStillVulnerable = "still-vulnerable"
+	FailureBuildBroken     = "build-broken"
+	FailureTimeout         = "timeout"
+	FailureUnknown         = "unknown"
+)
+
+// Failure classifies why an update failed and suggests how to resolve it
+type Failure struct {
+	Kind string
+	Hint string
+}
+
+// Classify determines the kind of an update failure for vuln. A replace
+// directive for the vulnerable module takes precedence over the error itself,
+// as it silently overrides whatever version go get selects.
+func Classify(sess *gomod.Session, vuln trivy.Vulnerability, err error) Failure {
+	if sess != nil {
+		if parser, perr := sess.Parser(); perr == nil {
+			if target, ok := parser.Replacement(vuln.PkgName); ok {
+				return Failure{
+					Kind: FailureReplaced,
+					Hint: fmt.Sprintf("go.mod replaces %s with %s; update or remove the replace directive to pick up %s",
+						vuln.PkgName, target, vuln.FixedVersion),
+				}
+			}
+		}
+	}
+
+	var mismatchErr *gomod.ChecksumMismatchError
+	var policyErr *gomod.PolicyError
+	var lowScoreErr *scorecard.LowScoreError
+	var suspiciousErr *SuspiciousModuleError
+	var incompatibleErr *gomod.IncompatibleError
+	var variantErr *MajorVariantError
+	switch {
+	case errors.As(err, &mismatchErr):
+		return Failure{
+			Kind: FailureChecksum,
+			Hint: fmt.Sprintf("%s@%s was served with a hash other than the one %s records; treat it as a security incident, "+
+				"check GOPROXY and whether the module's tag was moved, and never bypass it with GOSUMDB=off, GONOSUMDB or GOINSECURE",
+				mismatchErr.Module, mismatchErr.Version, mismatchErr.Source),
+		}
+	case errors.Is(err, ErrNoFixAvailable):
+		return Failure{
+			Kind: FailureNoFix,
+			Hint: "no fixed version is published; mitigate it with a replacement or document it in a VEX statement",
+		}
+	case errors.As(err, &variantErr):
+		return Failure{
+			Kind: FailureMajorVariant,
+			Hint: fmt.Sprintf("migrate the remaining imports of %s to %s; the old line is dropped once nothing needs it",
+				variantErr.Module, variantErr.Variant),
+		}
+	case errors.Is(err, ErrMajorBumpRequired):
+		return Failure{
+			Kind: FailureMajorBump,
+			Hint: "the fix is only available in a new major version; migrate the affected import paths manually or rerun with --allow-major",
+		}
+	case errors.As(err, &suspiciousErr):
+		return Failure{
+			Kind: FailureSuspicious,
+			Hint: fmt.Sprintf("check that %s is the intended module, then add it to module-check.confirmed", suspiciousErr.Module),
+		}
+	case errors.As(err, &lowScoreErr):
+		return Failure{
+			Kind: FailurePolicy,
+			Hint: fmt.Sprintf("%s scores %.1f on OpenSSF Scorecard, below scorecard.min-score; review the project and lower the floor or set scorecard.action to warn",
+				lowScoreErr.Score.Project, lowScoreErr.Score.Overall),
+		}
+	case errors.As(err, &policyErr):
+		return Failure{
+			Kind: FailurePolicy,
+			Hint: "the repository's Renovate/Dependabot rules forbid this version; adjust the rule or rerun with --respect-bot-config=false",
+		}
+	case errors.As(err, &incompatibleErr):
+		return Failure{
+			Kind: FailureIncompatible,
+			Hint: "the fix would leave modules that must match at incoherent versions; add them to a lockstep group so they are updated together",
+		}
+	case errors.Is(err, context.DeadlineExceeded):
+		return Failure{
+			Kind: FailureTimeout,
+			Hint: "the update did not finish in time; rerun with a higher --update-timeout or --timeout",
+		}
+	case errors.Is(err, ErrFixNotPublished):
+		return Failure{
+			Kind: FailureFixNotPublished,
+			Hint: fmt.Sprintf("%s@%s cannot be downloaded yet; retry once it reaches the module proxy, or check GOPROXY/GOPRIVATE",
+				vuln.PkgName, gomod.NormalizeVersion(vuln.FixedVersion)),
+		}
+	case errors.Is(err, ErrNoUpgradePath):
+		return Failure{
+			Kind: FailureNoUpgradePath,
+			Hint: fmt.Sprintf("no release of a direct dependency pulls in the fix; require %s@%s directly to override it",
+				vuln.PkgName, gomod.NormalizeVersion(vuln.FixedVersion)),
+		}
+	case errors.Is(err, ErrStillVulnerable):
+		return Failure{
+			Kind: FailureStillVulnerable,
+			Hint: fmt.Sprintf("another dependency keeps the vulnerable version; inspect it with 'go mod why -m %s'", vuln.PkgName),
+		}
+	case errors.Is(err, ErrBuildBroken):
+		return Failure{
+			Kind: FailureBuildBroken,
+			Hint: "the update leaves imports of the module unresolved; adapt the code to the new version or pin a compatible one",
+		}
+	}
+	return Failure{Kind: FailureUnknown}
+}
@@ -0,0 +1,65 @@
+package updater
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tamcore/go-autobump/internal/gomod"
+	"github.com/tamcore/go-autobump/internal/scorecard"
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+func TestClassify(t *testing.T) {
+	vuln := trivy.Vulnerability{PkgName: "example.com/dep", InstalledVersion: "v1.0.0", FixedVersion: "1.0.1"}
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"no fix", fmt.Errorf("%w for CVE-1 in example.com/dep", ErrNoFixAvailable), FailureNoFix},
+		{"major", fmt.Errorf("update failed: %w", &MajorBumpError{Module: "example.com/dep", From: "v1.0.0", To: "v2.0.0"}), FailureMajorBump},
+		{"major variant", &MajorVariantError{Module: "example.com/dep", Variant: "example.com/dep/v2", VariantVersion: "v2.1.0"}, FailureMajorVariant},
+		{"policy", &gomod.PolicyError{Err: errors.New("not allowed by renovate.json")}, FailurePolicy},
+		{"suspicious", fmt.Errorf("failed to update example.com/dep: %w", &SuspiciousModuleError{Module: "example.com/Dep", Advisory: "example.com/Dep", Reason: "differs only in case from example.com/dep"}), FailureSuspicious},
+		{"checksum mismatch", fmt.Errorf("failed to update example.com/dep: %w", &gomod.ChecksumMismatchError{Module: "example.com/dep", Version: "v1.0.1", Source: "sum.golang.org"}), FailureChecksum},
+		{"scorecard", fmt.Errorf("update failed: %w", &gomod.PolicyError{Err: &scorecard.LowScoreError{Module: "example.com/fork", Version: "v0.1.0", Score: scorecard.Score{Project: "github.com/someone/fork", Overall: 2.1}, MinScore: 5}}), FailurePolicy},
+		{"incompatible", fmt.Errorf("failed to update k8s.io/client-go: %w", &gomod.IncompatibleError{Module: "k8s.io/client-go", Version: "v0.30.1"}), FailureIncompatible},
+		{"not published", fmt.Errorf("failed to update example.com/dep: %w", ErrFixNotPublished), FailureFixNotPublished},
+		{"no path", fmt.Errorf("%w: could not find direct dependency that imports example.com/dep", ErrNoUpgradePath), FailureNoUpgradePath},
+		{"still present", fmt.Errorf("%w: CVE-1 still present after update in worktree, changes discarded", ErrStillVulnerable), FailureStillVulnerable},
+		{"build broken", fmt.Errorf("go mod tidy failed: %w", ErrBuildBroken), FailureBuildBroken},
+		{"timeout", fmt.Errorf("go get example.com/dep@v1.0.1 failed: %w", fmt.Errorf("%w: signal: killed", context.DeadlineExceeded)), FailureTimeout},
+		{"other", errors.New("go mod tidy failed"), FailureUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Classify(nil, vuln, tt.err)
+			if got.Kind != tt.want {
+				t.Errorf("Classify() = %s, want %s", got.Kind, tt.want)
+			}
+			if tt.want != FailureUnknown && got.Hint == "" {
+				t.Error("Classify() returned no hint")
+			}
+		})
+	}
+}
+
+func TestClassifyReplaced(t *testing.T) {
+	goModPath := filepath.Join(t.TempDir(), "go.mod")
+	goMod := "module example.com/app\n\ngo 1.22\n\nrequire example.com/dep v1.0.0\n\nreplace example.com/dep => ../dep\n"
+	if err := os.WriteFile(goModPath, []byte(goMod), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	vuln := trivy.Vulnerability{PkgName: "example.com/dep", FixedVersion: "v1.0.1"}
+	got := Classify(gomod.NewSession(goModPath), vuln, ErrStillVulnerable)
+	if got.Kind != FailureReplaced {
+		t.Errorf("Classify() = %s, want %s", got.Kind, FailureReplaced)
+	}
+}
@@ -0,0 +1,80 @@
+package updater
+
+import (
+	"slices"
+
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/gomod"
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+// CheckModulePath returns a *SuspiciousModuleError if updating module to fix
+// an advisory naming the advisory module or package would add a module
+// whose path looks like a mistake, such as a typosquatted lookalike, and no
+// human confirmed it
+func CheckModulePath(sess *gomod.Session, module, advisory string, cfg *config.Config) error {
+	if !cfg.ModuleCheck.Enabled || slices.Contains(cfg.ModuleCheck.Confirmed, module) {
+		return nil
+	}
+	required, err := requiredModules(sess)
+	if err != nil {
+		return nil
+	}
+	if reason := gomod.SuspiciousPath(module, advisory, required); reason != "" {
+		return &SuspiciousModuleError{Module: module, Advisory: advisory, Reason: reason}
+	}
+	return nil
+}
+
+// CheckAdvisoryPath returns a *SuspiciousModuleError if the module vuln is
+// reported for is neither the module its advisory names nor the module of
+// the package it names, e.g. because the scanner attributed it to a
+// rewritten import path, and no human confirmed it
+func CheckAdvisoryPath(vuln trivy.Vulnerability, cfg *config.Config) error {
+	if !cfg.ModuleCheck.Enabled || slices.Contains(cfg.ModuleCheck.Confirmed, vuln.PkgName) {
+		return nil
+	}
+	advisory := vuln.AdvisoryPath()
+	if reason := gomod.SuspiciousPath(vuln.PkgName, advisory, nil); reason != "" {
+		return &SuspiciousModuleError{Module: vuln.PkgName, Advisory: advisory, Reason: reason}
+	}
+	return nil
+}
+
+// CheckAddedModules returns a *SuspiciousModuleError for the first module
+// go.mod requires that it did not require before an update fixing an
+// advisory of advisory, if that module looks like a lookalike of the
+// advisory's or a previously required module and no human confirmed it
+func CheckAddedModules(sess *gomod.Session, before []string, advisory string, cfg *config.Config) error {
+	if !cfg.ModuleCheck.Enabled {
+		return nil
+	}
+	after, err := requiredModules(sess)
+	if err != nil {
+		return err
+	}
+
+	known := append([]string{advisory}, before...)
+	for _, module := range after {
+		if slices.Contains(before, module) || slices.Contains(cfg.ModuleCheck.Confirmed, module) {
+			continue
+		}
+		if reason := gomod.LookalikePath(module, known); reason != "" {
+			return &SuspiciousModuleError{Module: module, Advisory: advisory, Reason: reason}
+		}
+	}
+	return nil
+}
+
+// requiredModules returns the paths of the modules go.mod requires
+func requiredModules(sess *gomod.Session) ([]string, error) {
+	parser, err := sess.Parser()
+	if err != nil {
+		return nil, err
+	}
+	var required []string
+	for _, req := range parser.ModFile.Require {
+		required = append(required, req.Mod.Path)
+	}
+	return required, nil
+}
@@ -0,0 +1,123 @@
+package updater
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/gomod"
+	"github.com/tamcore/go-autobump/internal/runner"
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+// addRunner makes "go get" update example.com/dep and require the modules
+// of added too, like an update pulling in new dependencies
+type addRunner struct {
+	added []string
+}
+
+func (r *addRunner) Run(_ context.Context, dir, _ string, args ...string) ([]byte, []byte, error) {
+	if args[0] != "get" {
+		return nil, nil, nil
+	}
+	goModPath := filepath.Join(dir, "go.mod")
+	data, err := os.ReadFile(goModPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	updated := strings.Replace(string(data), "example.com/dep v1.0.0", "example.com/dep v1.0.1", 1)
+	for _, module := range r.added {
+		updated += "require " + module + " v1.0.0 // indirect\n"
+	}
+	return nil, nil, os.WriteFile(goModPath, []byte(updated), 0644)
+}
+
+func TestUpdateChecksModulePaths(t *testing.T) {
+	const goMod = "module example.com/app\n\ngo 1.22\n\nrequire example.com/dep v1.0.0\n"
+	vuln := trivy.Vulnerability{VulnerabilityID: "CVE-1", PkgName: "example.com/dep", InstalledVersion: "v1.0.0", FixedVersion: "v1.0.1"}
+
+	defer runner.Set(runner.Default())
+	tests := []struct {
+		name      string
+		advisory  string
+		added     []string
+		confirmed []string
+		want      string
+	}{
+		{"new dependency", "", []string{"example.com/other"}, nil, ""},
+		{"lookalike added", "", []string{"example.com/dpe"}, nil, "example.com/dpe"},
+		{"lookalike confirmed", "", []string{"example.com/dpe"}, []string{"example.com/dpe"}, ""},
+		{"package of the module", "example.com/dep/pkg", nil, nil, ""},
+		{"reported for another module", "example.org/dep", nil, nil, "example.com/dep"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner.Set(&addRunner{added: tt.added})
+			goModPath := filepath.Join(t.TempDir(), "go.mod")
+			if err := os.WriteFile(goModPath, []byte(goMod), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg := &config.Config{SkipTidy: true, ModuleCheck: config.ModuleCheckConfig{Enabled: true, Confirmed: tt.confirmed}}
+			vuln := vuln
+			vuln.Advisory = tt.advisory
+			err := Update(gomod.NewSession(goModPath), vuln, cfg)
+			data, _ := os.ReadFile(goModPath)
+
+			if tt.want == "" {
+				if err != nil || !strings.Contains(string(data), "example.com/dep v1.0.1") {
+					t.Errorf("Update() = %v with go.mod\n%s\nwant example.com/dep updated", err, data)
+				}
+				return
+			}
+			var suspiciousErr *SuspiciousModuleError
+			if !errors.As(err, &suspiciousErr) || suspiciousErr.Module != tt.want {
+				t.Fatalf("Update() = %v, want a *SuspiciousModuleError for %s", err, tt.want)
+			}
+			if string(data) != goMod {
+				t.Errorf("go.mod after a refused update =\n%s\nwant it unchanged", data)
+			}
+		})
+	}
+}
+
+func TestMitigateChecksFork(t *testing.T) {
+	const goMod = "module example.com/app\n\ngo 1.22\n\nrequire example.com/upstream/dep v1.0.0\n"
+	const pseudo = "v1.0.1-0.20260101000000-abcdef123456"
+	vuln := trivy.Vulnerability{VulnerabilityID: "CVE-1", PkgName: "example.com/upstream/dep", InstalledVersion: "v1.0.0"}
+	r := &config.ReplacementConfig{Module: "example.com/upstream/dep", With: "example.com/patches/dep", Version: "abcdef123456"}
+
+	defer runner.Set(runner.Default())
+	runner.Set(&replaceRunner{version: pseudo})
+
+	for _, confirmed := range []bool{false, true} {
+		goModPath := filepath.Join(t.TempDir(), "go.mod")
+		if err := os.WriteFile(goModPath, []byte(goMod), 0644); err != nil {
+			t.Fatal(err)
+		}
+		cfg := &config.Config{ModuleCheck: config.ModuleCheckConfig{Enabled: true}}
+		if confirmed {
+			cfg.ModuleCheck.Confirmed = []string{"example.com/patches/dep"}
+		}
+
+		_, err := Mitigate(gomod.NewSession(goModPath), vuln, r, cfg)
+		data, _ := os.ReadFile(goModPath)
+		if confirmed {
+			if err != nil {
+				t.Errorf("Mitigate() with a confirmed fork = %v", err)
+			}
+			continue
+		}
+		var suspiciousErr *SuspiciousModuleError
+		if !errors.As(err, &suspiciousErr) || !strings.Contains(err.Error(), "under another owner") {
+			t.Errorf("Mitigate() with an unconfirmed fork = %v, want a *SuspiciousModuleError", err)
+		}
+		if string(data) != goMod {
+			t.Errorf("go.mod after a refused replacement =\n%s\nwant it unchanged", data)
+		}
+	}
+}
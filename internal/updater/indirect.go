@@ -1,11 +1,13 @@
 package updater
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/tamcore/go-autobump/internal/config"
 	"github.com/tamcore/go-autobump/internal/gomod"
+	"github.com/tamcore/go-autobump/internal/output"
 	"github.com/tamcore/go-autobump/internal/trivy"
 )
 
@@ -15,39 +17,51 @@ import (
 // 2. Run go mod tidy
 // 3. Rescan to check if CVE persists
 // 4. If CVE persists, find which direct dep imports it and update that
-func UpdateIndirect(goModPath string, vuln trivy.Vulnerability, cfg *config.Config) error {
-	moduleDir := gomod.GetModuleDir(goModPath)
-
+func UpdateIndirect(sess *gomod.Session, vuln trivy.Vulnerability, cfg *config.Config) error {
 	// Step 1: Try direct update of the indirect dependency
-	fmt.Printf("  🔄 Attempting to update indirect dependency %s@%s -> %s\n",
+	output.Status(output.IconUpdate, "  Attempting to update indirect dependency %s@%s -> %s",
 		vuln.PkgName, vuln.InstalledVersion, vuln.FixedVersion)
 
-	if err := gomod.GoGet(moduleDir, vuln.PkgName, vuln.FixedVersion); err != nil {
+	before, err := sess.Snapshot()
+	if err != nil {
+		return err
+	}
+
+	if err := sess.GoGet(vuln.PkgName, vuln.FixedVersion); err != nil {
+		if isChecksumMismatch(err) {
+			return fmt.Errorf("failed to update %s: %w", vuln.PkgName, err)
+		}
 		// Direct update of indirect failed, need to go through direct deps
-		fmt.Printf("  ℹ️  Direct update failed, tracing dependency chain...\n")
-		return updateThroughDirectDep(goModPath, vuln, cfg)
+		output.Status(output.IconInfo, "  Direct update failed, tracing dependency chain...")
+		return updateThroughDirectDep(sess, vuln, cfg)
 	}
 
 	// Step 2: Run go mod tidy
 	if !cfg.SkipTidy {
-		if err := gomod.ModTidy(moduleDir); err != nil {
+		if err := sess.Tidy(); err != nil {
 			return fmt.Errorf("go mod tidy failed: %w", err)
 		}
 	}
 
+	// Nothing changed, so the CVE is still there; skip the rescan
+	if changed, err := before.Changed(); err == nil && !changed {
+		output.Status(output.IconInfo, "  Update was a no-op, tracing dependency chain...")
+		return updateThroughDirectDep(sess, vuln, cfg)
+	}
+
 	// Step 3: Verify the CVE is fixed by rescanning
-	scanOpts := trivy.ScanOptions{SkipDBUpdate: cfg.SkipTrivyDBUpdate}
-	result, err := trivy.Scan(goModPath, scanOpts)
+	scanOpts := trivy.NewScanOptions(cfg)
+	result, err := trivy.Scan(sess.GoModPath, scanOpts)
 	if err != nil {
 		return fmt.Errorf("verification scan failed: %w", err)
 	}
 
 	// Check if the same CVE still exists
 	for _, v := range result.Vulnerabilities {
-		if v.VulnerabilityID == vuln.VulnerabilityID && v.PkgName == vuln.PkgName {
+		if trivy.SameVulnerability(v, vuln) {
 			// CVE still present, need to update through direct dep
-			fmt.Printf("  ℹ️  CVE still present after update, tracing dependency chain...\n")
-			return updateThroughDirectDep(goModPath, vuln, cfg)
+			output.Status(output.IconInfo, "  CVE still present after update, tracing dependency chain...")
+			return updateThroughDirectDep(sess, vuln, cfg)
 		}
 	}
 
@@ -55,29 +69,48 @@ func UpdateIndirect(goModPath string, vuln trivy.Vulnerability, cfg *config.Conf
 }
 
 // updateThroughDirectDep finds and updates the direct dependency that imports the vulnerable indirect dep
-func updateThroughDirectDep(goModPath string, vuln trivy.Vulnerability, cfg *config.Config) error {
-	moduleDir := gomod.GetModuleDir(goModPath)
-	scanOpts := trivy.ScanOptions{SkipDBUpdate: cfg.SkipTrivyDBUpdate}
+func updateThroughDirectDep(sess *gomod.Session, vuln trivy.Vulnerability, cfg *config.Config) error {
+	scanOpts := trivy.NewScanOptions(cfg)
+
+	// Find every direct dependency that leads to the vulnerable module,
+	// ranked by distance in the module graph
+	graphDeps, graphErr := findDirectDependentsInGraph(sess, vuln.PkgName)
+
+	// Without a usable module graph, fall back to the chain reported by go mod why
+	var directDeps []string
+	if graphErr != nil || len(graphDeps) == 0 {
+		if graphErr != nil {
+			output.Status(output.IconWarning, "  Could not walk module graph: %v", graphErr)
+		}
 
-	// Find which direct dependency imports this indirect one
-	directDeps, err := gomod.FindDirectDependencyFor(moduleDir, vuln.PkgName)
-	if err != nil {
-		return fmt.Errorf("failed to trace dependency chain: %w", err)
+		var err error
+		directDeps, err = sess.FindDirectDependencyFor(vuln.PkgName)
+		if err != nil {
+			return fmt.Errorf("%w: failed to trace dependency chain: %w", ErrNoUpgradePath, err)
+		}
 	}
 
 	// Also find related packages from the same org (since multiple deps might pull in the vuln)
-	relatedDeps, err := findRelatedDirectDependencies(goModPath, vuln.PkgName)
+	relatedDeps, err := findRelatedDirectDependencies(sess, vuln.PkgName)
 	if err != nil {
-		fmt.Printf("  ⚠️  Could not find related dependencies: %v\n", err)
+		output.Status(output.IconWarning, "  Could not find related dependencies: %v", err)
 	}
 
 	// Merge and deduplicate: convert import paths to module paths first
 	seenModules := make(map[string]bool)
 	var allDeps []string
 
-	// Add deps from go mod why first (these are most directly related)
+	// Add deps from the module graph first, closest to the vulnerable module first
+	for _, dep := range graphDeps {
+		if !seenModules[dep] {
+			seenModules[dep] = true
+			allDeps = append(allDeps, dep)
+		}
+	}
+
+	// Then add deps from go mod why, if the graph did not help
 	for _, dep := range directDeps {
-		modulePath := importPathToModulePath(goModPath, dep)
+		modulePath := importPathToModulePath(sess, dep)
 		if !seenModules[modulePath] {
 			seenModules[modulePath] = true
 			allDeps = append(allDeps, modulePath)
@@ -86,7 +119,7 @@ func updateThroughDirectDep(goModPath string, vuln trivy.Vulnerability, cfg *con
 
 	// Then add related deps from same namespace
 	for _, dep := range relatedDeps {
-		modulePath := importPathToModulePath(goModPath, dep)
+		modulePath := importPathToModulePath(sess, dep)
 		if !seenModules[modulePath] {
 			seenModules[modulePath] = true
 			allDeps = append(allDeps, modulePath)
@@ -94,53 +127,66 @@ func updateThroughDirectDep(goModPath string, vuln trivy.Vulnerability, cfg *con
 	}
 
 	if len(allDeps) == 0 {
-		return fmt.Errorf("could not find direct dependency that imports %s", vuln.PkgName)
+		return fmt.Errorf("%w: could not find direct dependency that imports %s", ErrNoUpgradePath, vuln.PkgName)
 	}
 
 	// Try updating each related direct dependency until one succeeds in fixing the CVE
 	for _, directDep := range allDeps {
-		fmt.Printf("  📦 Trying to update related direct dep: %s\n", directDep)
+		output.Status(output.IconPackage, "  Trying to update related direct dep: %s", directDep)
+
+		before, err := sess.Snapshot()
+		if err != nil {
+			return err
+		}
 
-		if err := updateDirectDepAndVerify(goModPath, directDep, vuln, cfg); err != nil {
-			fmt.Printf("  ⚠️  Update via %s did not fix CVE: %v\n", directDep, err)
+		if err := updateDirectDepAndVerify(sess, directDep, vuln, cfg); err != nil {
+			var suspiciousErr *SuspiciousModuleError
+			if isChecksumMismatch(err) || errors.As(err, &suspiciousErr) {
+				return err
+			}
+			output.Status(output.IconWarning, "  Update via %s did not fix CVE: %v", directDep, err)
+			continue
+		}
+
+		// A no-op update (version already satisfied) cannot have fixed the CVE
+		if changed, err := before.Changed(); err == nil && !changed {
+			output.Status(output.IconInfo, "  %s is already up to date, skipping rescan", directDep)
 			continue
 		}
 
 		// Check if the CVE is fixed
-		result, err := trivy.Scan(goModPath, scanOpts)
+		result, err := trivy.Scan(sess.GoModPath, scanOpts)
 		if err != nil {
 			continue
 		}
 
 		cveFixed := true
 		for _, v := range result.Vulnerabilities {
-			if v.VulnerabilityID == vuln.VulnerabilityID && v.PkgName == vuln.PkgName {
+			if trivy.SameVulnerability(v, vuln) {
 				cveFixed = false
 				break
 			}
 		}
 
 		if cveFixed {
-			fmt.Printf("  ✅ CVE fixed by updating %s\n", directDep)
+			output.Status(output.IconSuccess, "  CVE fixed by updating %s", directDep)
 			return nil
 		}
 	}
 
-	// If we have at least one direct dep, use the first one for the error message
-	directDep := directDeps[0]
-	fmt.Printf("  📦 Indirect dep %s is imported by direct dep: %s\n", vuln.PkgName, directDep)
+	// Fall back to the closest candidate and try to pin a version containing the fix
+	directDep := allDeps[0]
+	output.Status(output.IconPackage, "  Indirect dep %s is imported by direct dep: %s", vuln.PkgName, directDep)
 
 	// Find which version of the direct dep includes the fixed indirect version
 	// This is done by checking the module graph
-	targetVersion, err := findDirectDepVersionWithFix(moduleDir, directDep, vuln)
+	targetVersion, err := resolveDirectDepVersion(sess, directDep, vuln, cfg)
 	if err != nil {
-		// If we can't find a specific version, try updating to latest
-		fmt.Printf("  ℹ️  Could not determine specific version, trying latest...\n")
-		targetVersion = "latest"
+		return fmt.Errorf("%w: could not determine version of %s to update to: %w", ErrNoUpgradePath, directDep, err)
 	}
 
 	// Check for major version bump on the direct dep
-	parser, err := gomod.NewParser(goModPath)
+	parser, err := sess.Parser()
 	if err != nil {
 		return fmt.Errorf("failed to parse go.mod: %w", err)
 	}
@@ -148,66 +194,65 @@ func updateThroughDirectDep(goModPath string, vuln trivy.Vulnerability, cfg *con
 	currentVersion := parser.GetVersion(directDep)
 	if targetVersion != "latest" && gomod.IsMajorVersionBump(currentVersion, targetVersion) {
 		if !cfg.AllowMajor {
-			return fmt.Errorf("major version bump required for %s (%s -> %s), use --allow-major to permit",
-				directDep, currentVersion, targetVersion)
+			return &MajorBumpError{Module: directDep, From: currentVersion, To: targetVersion}
 		}
 	}
 
+	if err := CheckModulePath(sess, directDep, vuln.AdvisoryPath(), cfg); err != nil {
+		return err
+	}
+
 	// Update the direct dependency
-	fmt.Printf("  🔄 Updating direct dependency %s to %s\n", directDep, targetVersion)
-	if err := gomod.GoGet(moduleDir, directDep, targetVersion); err != nil {
+	output.Status(output.IconUpdate, "  Updating direct dependency %s to %s", directDep, targetVersion)
+	if err := sess.GoGet(directDep, targetVersion); err != nil {
 		return fmt.Errorf("failed to update %s: %w", directDep, err)
 	}
 
 	// Run go mod tidy
 	if !cfg.SkipTidy {
-		if err := gomod.ModTidy(moduleDir); err != nil {
+		if err := sess.Tidy(); err != nil {
 			return fmt.Errorf("go mod tidy failed: %w", err)
 		}
 	}
 
+	// Verify the CVE is fixed by rescanning
+	result, err := trivy.Scan(sess.GoModPath, scanOpts)
+	if err != nil {
+		return fmt.Errorf("verification scan failed: %w", err)
+	}
+	for _, v := range result.Vulnerabilities {
+		if trivy.SameVulnerability(v, vuln) {
+			return fmt.Errorf("%w: %s still present after updating %s to %s", ErrStillVulnerable, vuln.VulnerabilityID, directDep, targetVersion)
+		}
+	}
+
 	return nil
 }
 
-// findDirectDepVersionWithFix analyzes the module graph to find which version of a direct
-// dependency includes the fixed version of the indirect dependency
-func findDirectDepVersionWithFix(moduleDir, directDep string, vuln trivy.Vulnerability) (string, error) {
-	// Get the module graph
-	edges, err := gomod.ModGraph(moduleDir)
+// findDirectDependentsInGraph returns the direct dependencies of the module that
+// transitively require pkgName, ordered by their distance to it in the module graph
+func findDirectDependentsInGraph(sess *gomod.Session, pkgName string) ([]string, error) {
+	graph, err := sess.Graph()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	// Find all versions of the direct dep that exist in the graph
-	// and check which ones depend on a fixed version of the indirect
-	var directDepVersions []string
-	directDepBase := getBasePath(directDep)
-
-	for _, edge := range edges {
-		fromBase := getBasePath(edge.From.Path)
-		if fromBase == directDepBase && edge.From.Version != "" {
-			directDepVersions = append(directDepVersions, edge.From.Version)
-		}
+	parser, err := sess.Parser()
+	if err != nil {
+		return nil, err
 	}
 
-	// For now, we'll return "latest" and let Go's MVS handle it
-	// A more sophisticated approach would query the module proxy for versions
-	// and check which ones include the fixed indirect version
-	_ = directDepVersions // silence unused warning
-	return "latest", nil
-}
+	if paths := graph.PathsTo(pkgName); len(paths) > 0 {
+		output.Status(output.IconInfo, "  Required through %s", strings.Join(paths[0], " -> "))
+	}
 
-// getBasePath returns the module path without version suffix
-func getBasePath(path string) string {
-	// Handle paths like github.com/foo/bar/v2
-	parts := strings.Split(path, "/")
-	if len(parts) > 0 {
-		last := parts[len(parts)-1]
-		if len(last) > 1 && last[0] == 'v' && last[1] >= '0' && last[1] <= '9' {
-			return strings.Join(parts[:len(parts)-1], "/")
+	var deps []string
+	for _, dep := range graph.DirectAncestors(pkgName) {
+		if parser.IsDirectDependency(dep.Path) {
+			deps = append(deps, dep.Path)
 		}
 	}
-	return path
+	return deps, nil
 }
 
 // findRelatedDirectDependencies finds direct dependencies from the same org/namespace
@@ -215,8 +260,8 @@ func getBasePath(path string) string {
 // the import chain but we can infer that related packages might pull in the fix.
 // If no direct deps are found in the namespace, it falls back to updating indirect deps
 // from the same namespace.
-func findRelatedDirectDependencies(goModPath, indirectPkg string) ([]string, error) {
-	parser, err := gomod.NewParser(goModPath)
+func findRelatedDirectDependencies(sess *gomod.Session, indirectPkg string) ([]string, error) {
+	parser, err := sess.Parser()
 	if err != nil {
 		return nil, err
 	}
@@ -280,8 +325,8 @@ func extractNamespace(modulePath string) string {
 
 // importPathToModulePath converts an import path (e.g., github.com/sigstore/sigstore-go/pkg/root)
 // to its module path (e.g., github.com/sigstore/sigstore-go) by matching against modules in go.mod
-func importPathToModulePath(goModPath, importPath string) string {
-	parser, err := gomod.NewParser(goModPath)
+func importPathToModulePath(sess *gomod.Session, importPath string) string {
+	parser, err := sess.Parser()
 	if err != nil {
 		return importPath // Fallback to original
 	}
@@ -308,23 +353,33 @@ func importPathToModulePath(goModPath, importPath string) string {
 	return importPath // Fallback to original
 }
 
-// updateDirectDepAndVerify updates a direct dependency to latest and runs tidy
-func updateDirectDepAndVerify(goModPath, directDep string, vuln trivy.Vulnerability, cfg *config.Config) error {
-	moduleDir := gomod.GetModuleDir(goModPath)
-
+// updateDirectDepAndVerify updates a direct dependency according to the configured strategy and runs tidy
+func updateDirectDepAndVerify(sess *gomod.Session, directDep string, vuln trivy.Vulnerability, cfg *config.Config) error {
 	// Convert import path to module path if needed
 	// e.g., github.com/sigstore/sigstore-go/pkg/root -> github.com/sigstore/sigstore-go
-	modulePath := importPathToModulePath(goModPath, directDep)
+	modulePath := importPathToModulePath(sess, directDep)
+
+	if err := CheckModulePath(sess, modulePath, vuln.AdvisoryPath(), cfg); err != nil {
+		return err
+	}
 
-	// Update the direct dependency to latest
+	targetVersion, err := resolveDirectDepVersion(sess, modulePath, vuln, cfg)
+	if err != nil {
+		return err
+	}
+
+	// Update the direct dependency to the target version
 	// Note: go get might return an error even when the main package is updated,
 	// if unrelated transitive dependencies have issues (e.g., broken versioning).
 	// We'll attempt the update and let the caller verify if the CVE is actually fixed.
-	goGetErr := gomod.GoGet(moduleDir, modulePath, "latest")
+	goGetErr := sess.GoGet(modulePath, targetVersion)
+	if isChecksumMismatch(goGetErr) {
+		return fmt.Errorf("failed to update %s: %w", modulePath, goGetErr)
+	}
 
 	// Run go mod tidy regardless of go get result to clean up the module state
 	if !cfg.SkipTidy {
-		if err := gomod.ModTidy(moduleDir); err != nil {
+		if err := sess.Tidy(); err != nil {
 			// If go get failed and tidy also failed, return the go get error
 			if goGetErr != nil {
 				return fmt.Errorf("failed to update %s: %w", modulePath, goGetErr)
@@ -0,0 +1,83 @@
+package updater
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/gomod"
+	"github.com/tamcore/go-autobump/internal/runner"
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+// chainRunner answers "go mod graph" with example.com/dep requiring
+// example.com/vuln, emulates "go get" by raising the versions in go.mod,
+// latest being v1.1.0, and answers Trivy scans with trivyOutput
+type chainRunner struct {
+	trivyOutput string
+	gets        []string
+}
+
+func (r *chainRunner) Run(_ context.Context, dir, name string, args ...string) ([]byte, []byte, error) {
+	if name == runner.Trivy {
+		return []byte(r.trivyOutput), nil, nil
+	}
+	switch args[0] {
+	case "mod":
+		if args[1] == "graph" {
+			return []byte("example.com/app example.com/dep@v1.0.0\nexample.com/dep@v1.0.0 example.com/vuln@v1.0.0\n"), nil, nil
+		}
+	case "get":
+		goModPath := filepath.Join(dir, "go.mod")
+		data, err := os.ReadFile(goModPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, target := range args[1:] {
+			r.gets = append(r.gets, target)
+			module, version, _ := strings.Cut(target, "@")
+			if version == "latest" {
+				version = "v1.1.0"
+			}
+			data = regexp.MustCompile(regexp.QuoteMeta(module)+` v\S+`).ReplaceAll(data, []byte(module+" "+version))
+		}
+		return nil, nil, os.WriteFile(goModPath, data, 0644)
+	}
+	return nil, nil, nil
+}
+
+func TestUpdateIndirectStillVulnerable(t *testing.T) {
+	const goMod = "module example.com/app\n\ngo 1.22\n\nrequire example.com/dep v1.0.0\n\nrequire example.com/vuln v1.0.0 // indirect\n"
+	vuln := trivy.Vulnerability{VulnerabilityID: "CVE-1", PkgName: "example.com/vuln", InstalledVersion: "v1.0.0", FixedVersion: "1.0.1"}
+	const stillAffected = `{"Results":[{"Target":"go.mod","Type":"gomod","Vulnerabilities":[{"VulnerabilityID":"CVE-1","PkgName":"example.com/vuln","InstalledVersion":"v1.0.1"}]}]}`
+
+	defer runner.Set(runner.Default())
+	tests := []struct {
+		name        string
+		trivyOutput string
+		wantErr     error
+	}{
+		{"fixed", `{"Results":[]}`, nil},
+		{"still vulnerable after the fallback", stillAffected, ErrStillVulnerable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &chainRunner{trivyOutput: tt.trivyOutput}
+			runner.Set(r)
+			goModPath := filepath.Join(t.TempDir(), "go.mod")
+			if err := os.WriteFile(goModPath, []byte(goMod), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			err := UpdateIndirect(gomod.NewSession(goModPath), vuln, &config.Config{SkipTidy: true})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("UpdateIndirect() = %v, want %v (go get %v)", err, tt.wantErr, r.gets)
+			}
+		})
+	}
+}
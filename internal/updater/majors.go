@@ -0,0 +1,49 @@
+package updater
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tamcore/go-autobump/internal/gomod"
+	"github.com/tamcore/go-autobump/internal/output"
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+// resolveMajorVariant handles a vulnerability in one major version line of a
+// module (e.g. github.com/foo/bar) whose fix is only published for another
+// line that go.mod already requires (github.com/foo/bar/v2). Updating the
+// vulnerable line would need a major version bump of its own import path, so
+// instead it is reported with what still pulls it in. handled is false if the
+// vulnerability is not such a case.
+func resolveMajorVariant(sess *gomod.Session, vuln trivy.Vulnerability) (handled bool, err error) {
+	fixedMajor := gomod.VersionMajor(vuln.FixedVersion)
+	if vuln.FixedVersion == "" || fixedMajor == gomod.PathMajor(vuln.PkgName) {
+		return false, nil
+	}
+
+	parser, err := sess.Parser()
+	if err != nil {
+		return false, nil
+	}
+	variant := gomod.MajorVariantPath(vuln.PkgName, fixedMajor)
+	variantVersion := parser.GetVersion(variant)
+	if variantVersion == "" {
+		return false, nil
+	}
+
+	output.Status(output.IconInfo, "  %s is fixed in %s, which go.mod already requires at %s",
+		vuln.PkgName, variant, variantVersion)
+
+	// A line nothing imports anymore is left to Eliminate, which needs tidy
+	if sess.Unneeded(vuln.PkgName) {
+		return true, fmt.Errorf("%s is no longer needed next to %s; run go mod tidy to drop it", vuln.PkgName, variant)
+	}
+
+	variantErr := &MajorVariantError{Module: vuln.PkgName, Variant: variant, VariantVersion: variantVersion}
+	if graph, err := sess.Graph(); err == nil {
+		if paths := graph.PathsTo(vuln.PkgName); len(paths) > 0 {
+			variantErr.NeededBy = strings.Join(paths[0], " -> ")
+		}
+	}
+	return true, variantErr
+}
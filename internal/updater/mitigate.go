@@ -0,0 +1,142 @@
+package updater
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/gomod"
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+// FindReplacement returns the patched fork or commit configured for vuln,
+// or nil if there is none
+func FindReplacement(cfg *config.Config, vuln trivy.Vulnerability) *config.ReplacementConfig {
+	for i, r := range cfg.Mitigations.Replacements {
+		if r.Module != vuln.PkgName {
+			continue
+		}
+		if len(r.Vulnerabilities) == 0 || slices.Contains(r.Vulnerabilities, vuln.VulnerabilityID) {
+			return &cfg.Mitigations.Replacements[i]
+		}
+	}
+	return nil
+}
+
+// ReplacementPath returns the module path r replaces its module with
+func ReplacementPath(r *config.ReplacementConfig) string {
+	if r.With != "" {
+		return r.With
+	}
+	return r.Module
+}
+
+// Mitigate replaces the module of a vulnerability without a fixed release
+// by the patched fork or commit r, and returns the replacement as "path
+// version". A module already replaced by r's fork is left as is. If the
+// replacement cannot be applied, go.mod and go.sum are restored.
+func Mitigate(sess *gomod.Session, vuln trivy.Vulnerability, r *config.ReplacementConfig, cfg *config.Config) (string, error) {
+	path := ReplacementPath(r)
+
+	parser, err := sess.Parser()
+	if err != nil {
+		return "", err
+	}
+	if current, ok := parser.Replacement(vuln.PkgName); ok && strings.HasPrefix(current, path+" ") {
+		return current, nil
+	}
+
+	version, err := gomod.ResolveVersion(sess.Dir, path, r.Version)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s@%s: %w", path, r.Version, err)
+	}
+
+	snap, err := sess.Snapshot()
+	if err != nil {
+		return "", err
+	}
+	if err := applyReplacement(sess, vuln.PkgName, path, version, cfg); err != nil {
+		if restoreErr := sess.Restore(snap); restoreErr != nil {
+			return "", fmt.Errorf("%w (restoring go.mod failed: %v)", err, restoreErr)
+		}
+		return "", err
+	}
+	return path + " " + version, nil
+}
+
+// applyReplacement adds the replace directive and tidies the module. A fork
+// or added module whose path looks like a mistake is refused.
+func applyReplacement(sess *gomod.Session, module, path, version string, cfg *config.Config) error {
+	required, err := requiredModules(sess)
+	if err != nil {
+		return err
+	}
+	if path != module {
+		if err := CheckModulePath(sess, path, module, cfg); err != nil {
+			return err
+		}
+	}
+
+	if err := sess.Replace(module, path, version); err != nil {
+		return err
+	}
+	if !cfg.SkipTidy {
+		if err := sess.Tidy(); err != nil {
+			return fmt.Errorf("go mod tidy failed: %w", err)
+		}
+	}
+	return CheckAddedModules(sess, required, module, cfg)
+}
+
+// Unmitigate removes the replace directive of module and updates it to
+// release, an upstream release expected to fix vulnIDs, the vulnerabilities
+// the replacement mitigated. If Trivy still reports one of them, go.mod and
+// go.sum are restored and an error is returned.
+func Unmitigate(sess *gomod.Session, module, release string, vulnIDs []string, cfg *config.Config) error {
+	snap, err := sess.Snapshot()
+	if err != nil {
+		return err
+	}
+	if err := removeReplacement(sess, module, release, vulnIDs, cfg); err != nil {
+		if restoreErr := sess.Restore(snap); restoreErr != nil {
+			return fmt.Errorf("%w (restoring go.mod failed: %v)", err, restoreErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// removeReplacement drops the replace directive, updates the module and
+// verifies the mitigated vulnerabilities are gone
+func removeReplacement(sess *gomod.Session, module, release string, vulnIDs []string, cfg *config.Config) error {
+	if err := sess.DropReplace(module); err != nil {
+		return err
+	}
+	required, err := requiredModules(sess)
+	if err != nil {
+		return err
+	}
+	if err := sess.GoGet(module, release); err != nil {
+		return fmt.Errorf("failed to update %s: %w", module, err)
+	}
+	if !cfg.SkipTidy {
+		if err := sess.Tidy(); err != nil {
+			return fmt.Errorf("go mod tidy failed: %w", err)
+		}
+	}
+	if err := CheckAddedModules(sess, required, module, cfg); err != nil {
+		return err
+	}
+
+	result, err := trivy.Scan(sess.GoModPath, trivy.NewScanOptions(cfg))
+	if err != nil {
+		return fmt.Errorf("verification scan failed: %w", err)
+	}
+	for _, vuln := range result.Vulnerabilities {
+		if vuln.PkgName == module && slices.Contains(vulnIDs, vuln.VulnerabilityID) {
+			return fmt.Errorf("%w: %s %s is still affected by %s", ErrStillVulnerable, module, release, vuln.VulnerabilityID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package updater
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/gomod"
+	"github.com/tamcore/go-autobump/internal/runner"
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+// replaceRunner resolves versions to version, emulates "go mod edit
+// -replace" and fails "go mod tidy" if tidyErr is set
+type replaceRunner struct {
+	version string
+	tidyErr error
+}
+
+func (r *replaceRunner) Run(_ context.Context, dir, _ string, args ...string) ([]byte, []byte, error) {
+	switch strings.Join(args[:2], " ") {
+	case "list -m":
+		return []byte(`{"Version":"` + r.version + `"}`), nil, nil
+	case "mod edit":
+		f, err := os.OpenFile(filepath.Join(dir, "go.mod"), os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer func() { _ = f.Close() }()
+		directive := strings.Replace(strings.TrimPrefix(args[2], "-replace="), "=", " => ", 1)
+		_, err = f.WriteString("\nreplace " + strings.Replace(directive, "@", " ", 1) + "\n")
+		return nil, nil, err
+	case "mod tidy":
+		return nil, []byte("tidy failed"), r.tidyErr
+	}
+	return nil, nil, nil
+}
+
+func TestMitigate(t *testing.T) {
+	const goMod = "module example.com/app\n\ngo 1.22\n\nrequire example.com/dep v1.0.0\n"
+	const pseudo = "v1.0.1-0.20260101000000-abcdef123456"
+
+	cfg := &config.Config{Mitigations: config.MitigationsConfig{Replacements: []config.ReplacementConfig{
+		{Module: "example.com/dep", Vulnerabilities: []string{"CVE-2"}, Version: "main"},
+		{Module: "example.com/dep", With: "example.com/fork", Version: "abcdef123456"},
+	}}}
+	vuln := trivy.Vulnerability{VulnerabilityID: "CVE-1", PkgName: "example.com/dep", InstalledVersion: "v1.0.0"}
+
+	r := FindReplacement(cfg, vuln)
+	if r == nil || ReplacementPath(r) != "example.com/fork" {
+		t.Fatalf("FindReplacement() = %+v, want the fork", r)
+	}
+	if FindReplacement(cfg, trivy.Vulnerability{VulnerabilityID: "CVE-1", PkgName: "example.com/other"}) != nil {
+		t.Error("FindReplacement() matched another module")
+	}
+
+	defer runner.Set(runner.Default())
+	for _, tt := range []struct {
+		name    string
+		tidyErr error
+	}{
+		{"applied", nil},
+		{"tidy fails", errors.New("exit status 1")},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			runner.Set(&replaceRunner{version: pseudo, tidyErr: tt.tidyErr})
+
+			goModPath := filepath.Join(t.TempDir(), "go.mod")
+			if err := os.WriteFile(goModPath, []byte(goMod), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			replacement, err := Mitigate(gomod.NewSession(goModPath), vuln, r, cfg)
+			data, _ := os.ReadFile(goModPath)
+			if tt.tidyErr != nil {
+				if err == nil || string(data) != goMod {
+					t.Errorf("Mitigate() = %q, %v with go.mod\n%s\nwant an error and go.mod restored", replacement, err, data)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := "example.com/fork " + pseudo; replacement != want {
+				t.Errorf("Mitigate() = %q, want %q", replacement, want)
+			}
+			if !strings.Contains(string(data), "replace example.com/dep => example.com/fork "+pseudo) {
+				t.Errorf("go.mod has no replace directive:\n%s", data)
+			}
+
+			// A second vulnerability of the module keeps the replacement
+			again, err := Mitigate(gomod.NewSession(goModPath), vuln, r, cfg)
+			if err != nil || again != replacement {
+				t.Errorf("second Mitigate() = %q, %v, want %q", again, err, replacement)
+			}
+		})
+	}
+}
+
+// unmitigateRunner emulates "go mod edit -dropreplace" and answers Trivy
+// scans with trivyOutput
+type unmitigateRunner struct {
+	trivyOutput string
+}
+
+func (r *unmitigateRunner) Run(_ context.Context, dir, name string, args ...string) ([]byte, []byte, error) {
+	if name == runner.Trivy {
+		return []byte(r.trivyOutput), nil, nil
+	}
+	if len(args) > 2 && strings.HasPrefix(args[2], "-dropreplace=") {
+		goModPath := filepath.Join(dir, "go.mod")
+		data, err := os.ReadFile(goModPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		kept := strings.Split(string(data), "\nreplace ")[0] + "\n"
+		return nil, nil, os.WriteFile(goModPath, []byte(kept), 0644)
+	}
+	return nil, nil, nil
+}
+
+func TestUnmitigate(t *testing.T) {
+	const goMod = "module example.com/app\n\ngo 1.22\n\nrequire example.com/dep v1.0.0\n\nreplace example.com/dep => example.com/fork v1.0.1\n"
+	const stillAffected = `{"Results":[{"Target":"go.mod","Type":"gomod","Vulnerabilities":[{"VulnerabilityID":"CVE-1","PkgName":"example.com/dep","InstalledVersion":"v1.1.0"}]}]}`
+
+	defer runner.Set(runner.Default())
+	for _, tt := range []struct {
+		name        string
+		trivyOutput string
+		wantErr     bool
+	}{
+		{"fixed", `{"Results":[]}`, false},
+		{"still affected", stillAffected, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			runner.Set(&unmitigateRunner{trivyOutput: tt.trivyOutput})
+
+			goModPath := filepath.Join(t.TempDir(), "go.mod")
+			if err := os.WriteFile(goModPath, []byte(goMod), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			err := Unmitigate(gomod.NewSession(goModPath), "example.com/dep", "v1.1.0", []string{"CVE-1"}, &config.Config{})
+			data, _ := os.ReadFile(goModPath)
+			if tt.wantErr {
+				if err == nil || string(data) != goMod {
+					t.Errorf("Unmitigate() = %v with go.mod\n%s\nwant an error and the replacement kept", err, data)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(string(data), "replace") {
+				t.Errorf("go.mod still has the replace directive:\n%s", data)
+			}
+		})
+	}
+}
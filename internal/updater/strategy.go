@@ -0,0 +1,192 @@
+package updater
+
+import (
+	"fmt"
+
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/gomod"
+	"github.com/tamcore/go-autobump/internal/output"
+	"github.com/tamcore/go-autobump/internal/trivy"
+	"golang.org/x/mod/semver"
+)
+
+// resolveDirectDepVersion picks the version a direct dependency should be updated to
+// in order to pull in the fix for an indirect vulnerability, based on the configured strategy
+func resolveDirectDepVersion(sess *gomod.Session, directDep string, vuln trivy.Vulnerability, cfg *config.Config) (string, error) {
+	switch cfg.Strategy {
+	case config.StrategyMinimal:
+		return findDirectDepVersionWithFix(sess, directDep, vuln, cfg)
+	case config.StrategyPatchOnly:
+		return findLatestPatchVersion(sess, directDep)
+	default:
+		if sess.Policy != nil {
+			return findLatestAllowedVersion(sess, directDep, cfg)
+		}
+		return "latest", nil
+	}
+}
+
+// resolveFixVersion picks the version a vulnerable direct dependency is updated
+// to, based on the configured fix version selection. It falls back to the
+// fixed version reported by Trivy when no newer release qualifies.
+func resolveFixVersion(sess *gomod.Session, vuln trivy.Vulnerability, cfg *config.Config) (string, error) {
+	fixedVersion := gomod.NormalizeVersion(vuln.FixedVersion)
+	if cfg.FixVersion == "" || cfg.FixVersion == config.FixVersionFixed || !semver.IsValid(fixedVersion) {
+		return vuln.FixedVersion, nil
+	}
+
+	versions, err := gomod.ListVersions(sess.Dir, vuln.PkgName)
+	if err != nil {
+		return "", err
+	}
+	versions = allowedVersions(sess, vuln.PkgName, versions)
+
+	var target string
+	switch cfg.FixVersion {
+	case config.FixVersionLatestPatch:
+		// The installed minor line only helps if it received the fix
+		if patch := gomod.LatestPatch(versions, vuln.InstalledVersion); semver.Compare(patch, fixedVersion) >= 0 {
+			target = patch
+		}
+	case config.FixVersionLatest:
+		if newer := gomod.VersionsAfter(versions, fixedVersion, cfg.AllowMajor); len(newer) > 0 {
+			target = newer[len(newer)-1]
+		}
+	}
+	if target == "" {
+		return vuln.FixedVersion, nil
+	}
+
+	output.Status(output.IconInfo, "  Updating %s to %s (fixed in %s)", vuln.PkgName, target, fixedVersion)
+	return target, nil
+}
+
+// findLatestAllowedVersion returns the newest version of directDep permitted by
+// the session's policy, since "latest" may resolve to a version it forbids
+func findLatestAllowedVersion(sess *gomod.Session, directDep string, cfg *config.Config) (string, error) {
+	parser, err := sess.Parser()
+	if err != nil {
+		return "", fmt.Errorf("failed to parse go.mod: %w", err)
+	}
+	currentVersion := parser.GetVersion(directDep)
+
+	versions, err := gomod.ListVersions(sess.Dir, directDep)
+	if err != nil {
+		return "", err
+	}
+
+	candidates := allowedVersions(sess, directDep, gomod.VersionsAfter(versions, currentVersion, cfg.AllowMajor))
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("no newer version of %s@%s is allowed by the update bot configuration or Scorecard floor",
+			directDep, currentVersion)
+	}
+	return candidates[len(candidates)-1], nil
+}
+
+// allowedVersions filters versions of module down to those permitted by the session's policy
+func allowedVersions(sess *gomod.Session, module string, versions []string) []string {
+	if sess.Policy == nil {
+		return versions
+	}
+
+	var allowed []string
+	for _, v := range versions {
+		if sess.CheckPolicy(module, v) == nil {
+			allowed = append(allowed, v)
+		}
+	}
+	return allowed
+}
+
+// findLatestPatchVersion returns the newest patch release of directDep within its current minor line
+func findLatestPatchVersion(sess *gomod.Session, directDep string) (string, error) {
+	parser, err := sess.Parser()
+	if err != nil {
+		return "", fmt.Errorf("failed to parse go.mod: %w", err)
+	}
+	currentVersion := parser.GetVersion(directDep)
+
+	versions, err := gomod.ListVersions(sess.Dir, directDep)
+	if err != nil {
+		return "", err
+	}
+
+	patch := gomod.LatestPatch(allowedVersions(sess, directDep, versions), currentVersion)
+	if patch == "" {
+		return "", fmt.Errorf("no newer patch release of %s@%s available", directDep, currentVersion)
+	}
+	return patch, nil
+}
+
+// findDirectDepVersionWithFix finds the smallest version of a direct dependency whose
+// MVS resolution raises the vulnerable indirect dependency to at least its fixed version.
+// Each candidate is probed by applying it and asking the go command for the selected
+// version of the vulnerable module; go.mod and go.sum are restored after every probe.
+func findDirectDepVersionWithFix(sess *gomod.Session, directDep string, vuln trivy.Vulnerability, cfg *config.Config) (string, error) {
+	fixedVersion := gomod.NormalizeVersion(vuln.FixedVersion)
+	if !semver.IsValid(fixedVersion) {
+		return "", fmt.Errorf("cannot compare against fixed version %q", vuln.FixedVersion)
+	}
+
+	parser, err := sess.Parser()
+	if err != nil {
+		return "", fmt.Errorf("failed to parse go.mod: %w", err)
+	}
+	currentVersion := parser.GetVersion(directDep)
+
+	versions, err := gomod.ListVersions(sess.Dir, directDep)
+	if err != nil {
+		return "", err
+	}
+
+	candidates := allowedVersions(sess, directDep, gomod.VersionsAfter(versions, currentVersion, cfg.AllowMajor))
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("no newer versions of %s@%s available", directDep, currentVersion)
+	}
+
+	snap, err := sess.Snapshot()
+	if err != nil {
+		return "", err
+	}
+
+	// A checksum mismatch stops the search instead of probing further
+	// versions from the same source
+	var mismatch error
+	satisfies := func(version string) bool {
+		defer func() { _ = sess.Restore(snap) }()
+
+		if err := sess.GoGet(directDep, version); err != nil {
+			if isChecksumMismatch(err) {
+				mismatch = err
+			}
+			return false
+		}
+		selected, err := gomod.SelectedVersion(sess.Dir, vuln.PkgName)
+		if err != nil {
+			return false
+		}
+		return semver.Compare(selected, fixedVersion) >= 0
+	}
+
+	// Binary search for the smallest satisfying version, assuming newer
+	// releases never lower their requirement on the vulnerable module
+	lo, hi := 0, len(candidates)
+	for lo < hi && mismatch == nil {
+		mid := (lo + hi) / 2
+		if satisfies(candidates[mid]) {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	if mismatch != nil {
+		return "", mismatch
+	}
+
+	if lo == len(candidates) {
+		return "", fmt.Errorf("no version of %s raises %s to %s", directDep, vuln.PkgName, fixedVersion)
+	}
+
+	output.Status(output.IconInfo, "  Minimal version of %s containing the fix: %s", directDep, candidates[lo])
+	return candidates[lo], nil
+}
@@ -0,0 +1,121 @@
+package updater
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/git"
+	"github.com/tamcore/go-autobump/internal/gomod"
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+// Update fixes a single vulnerability, dispatching to the direct or indirect
+// update flow and optionally isolating the work in a temporary git worktree
+func Update(sess *gomod.Session, vuln trivy.Vulnerability, cfg *config.Config) error {
+	if cfg.Worktree {
+		return updateInWorktree(sess, vuln, cfg)
+	}
+	return update(sess, vuln, cfg)
+}
+
+// update runs the direct or indirect update flow in place. An update that
+// adds a suspicious module to go.mod is rolled back.
+func update(sess *gomod.Session, vuln trivy.Vulnerability, cfg *config.Config) error {
+	if vuln.FixedVersion == "" {
+		return fmt.Errorf("%w for %s in %s", ErrNoFixAvailable, vuln.VulnerabilityID, vuln.PkgName)
+	}
+	vuln.FixedVersion = gomod.MatchIncompatible(vuln.InstalledVersion, vuln.FixedVersion)
+	if handled, err := resolveMajorVariant(sess, vuln); handled {
+		return err
+	}
+	if err := CheckAdvisoryPath(vuln, cfg); err != nil {
+		return err
+	}
+
+	required, err := requiredModules(sess)
+	if err != nil {
+		return err
+	}
+	snap, err := sess.Snapshot()
+	if err != nil {
+		return err
+	}
+	if vuln.Indirect {
+		err = UpdateIndirect(sess, vuln, cfg)
+	} else {
+		err = UpdateDirect(sess, vuln, cfg)
+	}
+	if err != nil {
+		return err
+	}
+	if err := CheckAddedModules(sess, required, vuln.AdvisoryPath(), cfg); err != nil {
+		if restoreErr := sess.Restore(snap); restoreErr != nil {
+			return fmt.Errorf("%w (restoring go.mod failed: %v)", err, restoreErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// updateInWorktree performs an update in a temporary git worktree, verifies the
+// vulnerability is gone there, and only then copies go.mod and go.sum back into
+// the main checkout. The main checkout is never touched by failed attempts.
+func updateInWorktree(sess *gomod.Session, vuln trivy.Vulnerability, cfg *config.Config) error {
+	absGoModPath, err := filepath.Abs(sess.GoModPath)
+	if err != nil {
+		return err
+	}
+
+	repoRoot, err := git.RepoRoot(sess.Dir)
+	if err != nil {
+		return fmt.Errorf("worktree isolation requires a git repository: %w", err)
+	}
+
+	relGoModPath, err := filepath.Rel(repoRoot, absGoModPath)
+	if err != nil {
+		return err
+	}
+
+	wt, err := git.AddWorktree(repoRoot)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = wt.Remove() }()
+
+	// Start from the main checkout's current go.mod/go.sum, which may already
+	// contain earlier updates from this run
+	current, err := sess.Snapshot()
+	if err != nil {
+		return err
+	}
+	wtGoModPath := filepath.Join(wt.Path, relGoModPath)
+	if err := current.WriteTo(wtGoModPath); err != nil {
+		return err
+	}
+
+	wtSess := gomod.NewSession(wtGoModPath)
+	wtSess.Policy = sess.Policy
+	wtSess.Lockstep = sess.Lockstep
+	wtSess.Compatibility = sess.Compatibility
+	if err := update(wtSess, vuln, cfg); err != nil {
+		return err
+	}
+
+	scanOpts := trivy.NewScanOptions(cfg)
+	fixed, err := VerifyVulnerabilityFixed(wtGoModPath, vuln.VulnerabilityID, vuln.PkgName, 0, scanOpts)
+	if err != nil {
+		return err
+	}
+	if !fixed {
+		return fmt.Errorf("%w: %s still present after update in worktree, changes discarded", ErrStillVulnerable, vuln.VulnerabilityID)
+	}
+
+	// Merge the verified go.mod/go.sum back into the main checkout
+	updated, err := wtSess.Snapshot()
+	if err != nil {
+		return err
+	}
+	defer sess.Invalidate()
+	return updated.WriteTo(sess.GoModPath)
+}
@@ -2,31 +2,34 @@ package updater
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/tamcore/go-autobump/internal/config"
 	"github.com/tamcore/go-autobump/internal/gomod"
+	"github.com/tamcore/go-autobump/internal/output"
 	"github.com/tamcore/go-autobump/internal/trivy"
 )
 
-// Verify rescans the module after updates and reports remaining vulnerabilities
-func Verify(goModPath string, cfg *config.Config) error {
+// Verify rescans the module after updates and reports remaining
+// vulnerabilities above their CVSS thresholds
+func Verify(sess *gomod.Session, cfg *config.Config, thresholds trivy.Thresholds) error {
 	// Rescan with Trivy
-	scanOpts := trivy.ScanOptions{SkipDBUpdate: cfg.SkipTrivyDBUpdate}
-	result, err := trivy.Scan(goModPath, scanOpts)
+	scanOpts := trivy.NewScanOptions(cfg)
+	result, err := trivy.Scan(sess.GoModPath, scanOpts)
 	if err != nil {
 		return fmt.Errorf("verification scan failed: %w", err)
 	}
 
 	// Filter by CVSS threshold
-	filtered := trivy.FilterByCVSS(result, cfg.CVSSThreshold)
+	filtered := trivy.FilterByThresholds(result, thresholds)
 
 	if len(filtered.Vulnerabilities) == 0 {
-		fmt.Printf("  ✅ Verification passed: no vulnerabilities above CVSS %.1f\n", cfg.CVSSThreshold)
+		output.Status(output.IconSuccess, "  Verification passed: no vulnerabilities above CVSS %.1f", cfg.CVSSThreshold)
 		return nil
 	}
 
 	// Parse go.mod to check for major version modules
-	parser, _ := gomod.NewParser(goModPath)
+	parser, _ := sess.Parser()
 
 	// Filter out vulnerabilities where the major version module already exists
 	// AND the vulnerable v1 module is no longer present
@@ -42,20 +45,22 @@ func Verify(goModPath string, cfg *config.Config) error {
 	}
 
 	if len(remaining) == 0 {
-		fmt.Printf("  ✅ Verification passed: no vulnerabilities above CVSS %.1f\n", cfg.CVSSThreshold)
+		output.Status(output.IconSuccess, "  Verification passed: no vulnerabilities above CVSS %.1f", cfg.CVSSThreshold)
 		return nil
 	}
 
 	// Report remaining vulnerabilities
-	fmt.Printf("  ⚠️  %d vulnerabilities still present after updates:\n", len(remaining))
+	var report strings.Builder
+	fmt.Fprintf(&report, "  %d vulnerabilities still present after updates:", len(remaining))
 	for _, vuln := range remaining {
 		status := "fixable"
 		if vuln.FixedVersion == "" {
 			status = "no fix available"
 		}
-		fmt.Printf("      - %s in %s@%s (CVSS: %.1f, %s)\n",
+		fmt.Fprintf(&report, "\n      - %s in %s@%s (CVSS: %.1f, %s)",
 			vuln.VulnerabilityID, vuln.PkgName, vuln.InstalledVersion, vuln.CVSSScore, status)
 	}
+	output.Status(output.IconWarning, "%s", report.String())
 
 	return nil
 }
@@ -70,7 +75,7 @@ func VerifyVulnerabilityFixed(goModPath string, vulnID, pkgName string, threshol
 	filtered := trivy.FilterByCVSS(result, threshold)
 
 	for _, vuln := range filtered.Vulnerabilities {
-		if vuln.VulnerabilityID == vulnID && vuln.PkgName == pkgName {
+		if vuln.Is(vulnID) && vuln.PkgName == pkgName {
 			return false, nil // Still present
 		}
 	}
@@ -3,13 +3,18 @@ package vex
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/tamcore/go-autobump/internal/ai"
 	"github.com/tamcore/go-autobump/internal/config"
 	"github.com/tamcore/go-autobump/internal/gomod"
+	"github.com/tamcore/go-autobump/internal/output"
+	"github.com/tamcore/go-autobump/internal/purl"
+	"github.com/tamcore/go-autobump/internal/runner"
 	"github.com/tamcore/go-autobump/internal/trivy"
 )
 
@@ -19,6 +24,7 @@ type OpenVEXDocument struct {
 	Context    string      `json:"@context"`
 	ID         string      `json:"@id"`
 	Author     string      `json:"author"`
+	Role       string      `json:"role,omitempty"`
 	Timestamp  string      `json:"timestamp"`
 	Version    int         `json:"version"`
 	Tooling    string      `json:"tooling"`
@@ -30,15 +36,24 @@ type Statement struct {
 	VulnerabilityID string    `json:"vulnerability"`
 	Products        []Product `json:"products"`
 	Status          string    `json:"status"`
+	Supplier        string    `json:"supplier,omitempty"`
 	Justification   string    `json:"justification,omitempty"`
 	ImpactStatement string    `json:"impact_statement,omitempty"`
 	Timestamp       string    `json:"timestamp"`
+	// Reviewer is the person who approved an AI-generated statement
+	Reviewer string `json:"reviewer,omitempty"`
+
+	// aiGenerated marks statements whose status was determined by AI
+	aiGenerated bool
 }
 
-// Product represents a product affected by a vulnerability
+// Product represents a product affected by a vulnerability. With a
+// configured product hierarchy, the vulnerable module is a subcomponent of
+// the application or image product.
 type Product struct {
-	ID          string      `json:"@id"`
-	Identifiers Identifiers `json:"identifiers,omitempty"`
+	ID            string      `json:"@id"`
+	Identifiers   Identifiers `json:"identifiers,omitempty"`
+	Subcomponents []Product   `json:"subcomponents,omitempty"`
 }
 
 // Identifiers holds product identification information
@@ -51,91 +66,318 @@ type AIGeneratedJustification struct {
 	Status          string `json:"status"`
 	Justification   string `json:"justification,omitempty"`
 	ImpactStatement string `json:"impact_statement"`
+	// Confidence is the model's certainty of the status, from 0 to 1
+	Confidence float64 `json:"confidence,omitempty"`
 }
 
-// Generate creates a VEX document for unfixed vulnerabilities
-func Generate(vulns []trivy.Vulnerability, cfg *config.Config) error {
+// Generate creates a VEX document for unfixed vulnerabilities and returns
+// its statements. With VEX review enabled, AI-generated statements are
+// queued for review instead and the document states the vulnerabilities
+// as under investigation until they are approved.
+func Generate(vulns []trivy.Vulnerability, cfg *config.Config) ([]Statement, error) {
 	if len(vulns) == 0 {
-		return nil
+		return nil, nil
+	}
+
+	statements := Statements(vulns, cfg)
+	if cfg.VEXReview {
+		var err error
+		if statements, err = queueForReview(cfg.VEXReviewQueue, statements); err != nil {
+			return nil, err
+		}
+	}
+
+	doc := NewDocument(cfg)
+	doc.Statements = statements
+	if err := doc.Write(cfg.VEXOutput); err != nil {
+		return nil, err
+	}
+
+	// Let osv-scanner know about vulnerabilities triaged as not affected
+	if err := updateOSVScannerConfig(cfg, doc.Statements); err != nil {
+		return nil, err
 	}
 
-	doc := OpenVEXDocument{
+	return doc.Statements, nil
+}
+
+// GenerateDraft creates a draft VEX document for unfixed vulnerabilities in
+// the temporary directory and returns its path and statements, to preview
+// the statements of a dry run. The VEX document, the review queue and the
+// osv-scanner config are left untouched, so AI-generated statements appear
+// as generated even with VEX review enabled.
+func GenerateDraft(vulns []trivy.Vulnerability, cfg *config.Config) (string, []Statement, error) {
+	doc := NewDocument(cfg)
+	doc.ID = fmt.Sprintf("%sdraft-%d", cfg.VEXMetadata.IDPrefix, time.Now().Unix())
+	doc.Statements = Statements(vulns, cfg)
+
+	f, err := os.CreateTemp("", "autobump-*.draft.openvex.json")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create draft VEX document: %w", err)
+	}
+	_ = f.Close()
+	if err := doc.Write(f.Name()); err != nil {
+		return "", nil, err
+	}
+	return f.Name(), doc.Statements, nil
+}
+
+// NewDocument returns an empty VEX document with the configured metadata
+func NewDocument(cfg *config.Config) *OpenVEXDocument {
+	meta := cfg.VEXMetadata
+	return &OpenVEXDocument{
 		Context:   "https://openvex.dev/ns/v0.2.0",
-		ID:        fmt.Sprintf("https://go-autobump/vex/%d", time.Now().Unix()),
-		Author:    "go-autobump",
+		ID:        fmt.Sprintf("%s%d", meta.IDPrefix, time.Now().Unix()),
+		Author:    meta.Author,
+		Role:      meta.Role,
 		Timestamp: time.Now().UTC().Format(time.RFC3339),
 		Version:   1,
-		Tooling:   "go-autobump",
+		Tooling:   meta.Tooling,
 	}
+}
+
+// ReadDocument reads the VEX document at path
+func ReadDocument(path string) (*OpenVEXDocument, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc OpenVEXDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse VEX document %s: %w", path, err)
+	}
+	return &doc, nil
+}
+
+// Write writes the document to path
+func (d *OpenVEXDocument) Write(path string) error {
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal VEX document: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write VEX document: %w", err)
+	}
+	return nil
+}
 
-	var aiClient *ai.Client
-	if cfg.AI.APIKey != "" {
+// updateOSVScannerConfig adds the not_affected statements to the configured
+// osv-scanner config, if any
+func updateOSVScannerConfig(cfg *config.Config, statements []Statement) error {
+	if cfg.OSVScannerConfig == "" {
+		return nil
+	}
+	added, err := UpdateOSVScannerConfig(cfg.OSVScannerConfig, statements)
+	if err != nil {
+		return err
+	}
+	if added > 0 {
+		output.Status(output.IconDocument, "  Added %d ignore rule(s) to %s", added, cfg.OSVScannerConfig)
+	}
+	return nil
+}
+
+// Statements builds a VEX statement for each vulnerability, with an
+// AI-generated justification if an AI API key is configured. Statements a
+// reviewer approved in the existing VEX document are kept as they are.
+func Statements(vulns []trivy.Vulnerability, cfg *config.Config) []Statement {
+	reviewed := reviewedStatements(cfg.VEXOutput)
+
+	var aiClient, consensusClient *ai.Client
+	if err := config.ResolveSecret("ai.api-key", &cfg.AI.APIKey); err != nil {
+		output.Warnf("generating VEX statements without AI: %v", err)
+	}
+	if cfg.AI.APIKey != "" || cfg.AI.DryRun {
+		prompts, err := ai.LoadPrompts(cfg.AI.Prompts.VEXSystem, cfg.AI.Prompts.VEXUser)
+		if err != nil {
+			output.Warnf("using the built-in AI prompts: %v", err)
+			prompts = ai.DefaultPrompts()
+		}
 		aiClient = ai.NewClient(cfg.AI.APIKey, cfg.AI.Endpoint, cfg.AI.Model)
+		aiClient.Prompts = prompts
+		aiClient.Stream = cfg.AI.Stream
+		aiClient.DryRun = cfg.AI.DryRun
+
+		if cfg.AI.ConsensusModel != "" {
+			endpoint := cfg.AI.ConsensusEndpoint
+			if endpoint == "" {
+				endpoint = cfg.AI.Endpoint
+			}
+			consensusClient = ai.NewClient(cfg.AI.APIKey, endpoint, cfg.AI.ConsensusModel)
+			consensusClient.Prompts = prompts
+			consensusClient.Stream = cfg.AI.Stream
+			consensusClient.DryRun = cfg.AI.DryRun
+		}
 	}
 
-	for _, vuln := range vulns {
+	builder := purl.Builder{PreserveCase: cfg.PURLPreserveCase}
+
+	statements := make([]Statement, 0, len(vulns))
+	var pending []int
+	for i, vuln := range vulns {
+		module := Product{
+			ID: vuln.PkgName,
+			Identifiers: Identifiers{
+				PURL: builder.Golang(vuln.PkgName, vuln.InstalledVersion),
+			},
+		}
 		stmt := Statement{
 			VulnerabilityID: vuln.VulnerabilityID,
-			Products: []Product{
-				{
-					ID: vuln.PkgName,
-					Identifiers: Identifiers{
-						PURL: fmt.Sprintf("pkg:golang/%s@%s", vuln.PkgName, vuln.InstalledVersion),
-					},
-				},
-			},
-			Timestamp: time.Now().UTC().Format(time.RFC3339),
+			Products:        products(cfg.VEXProducts, module),
+			Supplier:        cfg.VEXMetadata.Supplier,
+			Timestamp:       time.Now().UTC().Format(time.RFC3339),
+		}
+		if approved, ok := reviewed[stmt.Key()]; ok {
+			statements = append(statements, approved)
+			continue
 		}
 
-		// Try to generate AI justification if configured
-		if aiClient != nil {
-			justification, err := generateAIJustification(aiClient, vuln, cfg.Path)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "  ⚠️  AI justification failed for %s: %v\n", vuln.VulnerabilityID, err)
-				// Fall back to under_investigation
-				stmt.Status = "under_investigation"
-				stmt.ImpactStatement = "No fix available. Requires manual analysis."
-			} else {
-				stmt.Status = justification.Status
-				stmt.Justification = justification.Justification
-				stmt.ImpactStatement = justification.ImpactStatement
-			}
-		} else {
+		if aiClient == nil {
 			// No AI configured, mark as under_investigation
 			stmt.Status = "under_investigation"
 			stmt.ImpactStatement = fmt.Sprintf("No fix available for %s in %s@%s. Requires manual analysis.",
 				vuln.VulnerabilityID, vuln.PkgName, vuln.InstalledVersion)
+		} else {
+			pending = append(pending, i)
 		}
+		statements = append(statements, stmt)
+	}
+	if len(pending) == 0 {
+		return statements
+	}
 
-		doc.Statements = append(doc.Statements, stmt)
+	// Generate AI justifications for the statements not reviewed yet
+	pendingVulns := make([]trivy.Vulnerability, 0, len(pending))
+	for _, i := range pending {
+		pendingVulns = append(pendingVulns, vulns[i])
 	}
+	progress := output.StartProgress("Generating AI justifications", len(pendingVulns))
+	aiClient.OnProgress = func(received int) {
+		progress.SetDetail(fmt.Sprintf("(%d characters received)", received))
+	}
+	justifications, errs := justify(aiClient, pendingVulns, cfg.Path, cfg.AI.BatchSize, progress)
+	progress.Done()
 
-	// Write VEX document
-	output, err := json.MarshalIndent(doc, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal VEX document: %w", err)
+	for n, i := range pending {
+		vuln, stmt := vulns[i], &statements[i]
+		justification, err := justifications[n], errs[n]
+		if err != nil {
+			if !errors.Is(err, ai.ErrDryRun) {
+				output.Status(output.IconWarning, "  AI justification failed for %s: %v", vuln.VulnerabilityID, err)
+			}
+			// Fall back to under_investigation
+			stmt.Status = "under_investigation"
+			stmt.ImpactStatement = "No fix available. Requires manual analysis."
+			continue
+		}
+
+		requireConfidence(justification, cfg.AI.MinConfidenceForNotAffected)
+		if consensusClient != nil && vuln.Severity == "CRITICAL" && justification.Status == StatusNotAffected {
+			second, err := generateAIJustification(consensusClient, vuln, cfg.Path)
+			if err == nil {
+				requireConfidence(second, cfg.AI.MinConfidenceForNotAffected)
+			}
+			requireConsensus(justification, second, err, cfg.AI.ConsensusModel)
+		}
+		stmt.aiGenerated = true
+		stmt.Status = justification.Status
+		stmt.Justification = justification.Justification
+		stmt.ImpactStatement = justification.ImpactStatement
 	}
+	return statements
+}
 
-	if err := os.WriteFile(cfg.VEXOutput, output, 0644); err != nil {
-		return fmt.Errorf("failed to write VEX document: %w", err)
+// requireConfidence downgrades a not_affected justification the model is less
+// than minConfidence certain of to under_investigation, since a wrong
+// not_affected hides an exploitable vulnerability. A missing confidence
+// counts as 0.
+func requireConfidence(j *AIGeneratedJustification, minConfidence float64) {
+	if j.Status != "not_affected" || minConfidence <= 0 || j.Confidence >= minConfidence {
+		return
 	}
+	j.ImpactStatement = fmt.Sprintf("AI analysis suggested not_affected (%s) with confidence %.2f, below the required %.2f; requires manual analysis. %s",
+		j.Justification, j.Confidence, minConfidence, j.ImpactStatement)
+	j.Status = "under_investigation"
+	j.Justification = ""
+}
+
+// requireConsensus downgrades a not_affected justification to
+// under_investigation unless the consensus model, asked about the same
+// vulnerability, answered not_affected too
+func requireConsensus(j, second *AIGeneratedJustification, err error, model string) {
+	if j.Status != StatusNotAffected {
+		return
+	}
+	var disagreement string
+	switch {
+	case err != nil:
+		disagreement = fmt.Sprintf("could not be confirmed by %s (%v)", model, err)
+	case second.Status != StatusNotAffected:
+		disagreement = fmt.Sprintf("was not confirmed by %s, which answered %s", model, second.Status)
+	default:
+		return
+	}
+	j.ImpactStatement = fmt.Sprintf("AI analysis suggested not_affected (%s), but it %s; requires manual analysis. %s",
+		j.Justification, disagreement, j.ImpactStatement)
+	j.Status = StatusUnderInvestigation
+	j.Justification = ""
+}
+
+// products returns the products of a statement about module: module itself,
+// or module as subcomponent of each configured application/image product
+func products(ids []string, module Product) []Product {
+	if len(ids) == 0 {
+		return []Product{module}
+	}
+	products := make([]Product, 0, len(ids))
+	for _, id := range ids {
+		product := Product{ID: id, Subcomponents: []Product{module}}
+		if strings.HasPrefix(id, "pkg:") {
+			product.Identifiers.PURL = id
+		}
+		products = append(products, product)
+	}
+	return products
+}
+
+// Modules returns the vulnerable modules a statement is about: the
+// subcomponents of its products, or the products themselves if they have none
+func (s Statement) Modules() []Product {
+	var modules []Product
+	for _, product := range s.Products {
+		if len(product.Subcomponents) == 0 {
+			modules = append(modules, product)
+			continue
+		}
+		modules = append(modules, product.Subcomponents...)
+	}
+	return modules
+}
 
+// FindStatement returns the statement about vuln, if any, made under its ID
+// or an alias of it
+func FindStatement(statements []Statement, vuln trivy.Vulnerability) *Statement {
+	for i, stmt := range statements {
+		if !vuln.Is(stmt.VulnerabilityID) {
+			continue
+		}
+		for _, module := range stmt.Modules() {
+			if module.ID == vuln.PkgName {
+				return &statements[i]
+			}
+		}
+	}
 	return nil
 }
 
 // generateAIJustification uses AI to generate a VEX justification
 func generateAIJustification(client *ai.Client, vuln trivy.Vulnerability, modulePath string) (*AIGeneratedJustification, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	ctx, cancel := context.WithTimeout(runner.Context(), 60*time.Second)
 	defer cancel()
 
-	// Get dependency chain using go mod why
-	modWhyOutput, err := gomod.ModWhy(modulePath, vuln.PkgName)
-	if err != nil {
-		modWhyOutput = "Unable to determine dependency chain"
-	}
-
 	// Generate justification using AI
-	response, err := client.GenerateVEXJustification(ctx, vuln.VulnerabilityID, vuln.PkgName, vuln.Description, modWhyOutput)
+	response, err := client.GenerateVEXJustification(ctx, promptData(vuln, modulePath))
 	if err != nil {
 		return nil, err
 	}
@@ -146,17 +388,122 @@ func generateAIJustification(client *ai.Client, vuln trivy.Vulnerability, module
 		// If parsing fails, try to extract from the response
 		return nil, fmt.Errorf("failed to parse AI response: %w", err)
 	}
+	validateStatus(&justification)
+
+	return &justification, nil
+}
+
+// promptData describes vuln for the VEX prompt templates, with the
+// dependency chain from "go mod why"
+func promptData(vuln trivy.Vulnerability, modulePath string) ai.VEXPromptData {
+	modWhyOutput, err := gomod.ModWhy(modulePath, vuln.PkgName)
+	if err != nil {
+		modWhyOutput = "Unable to determine dependency chain"
+	}
+	return ai.VEXPromptData{
+		VulnerabilityID: vuln.VulnerabilityID,
+		Package:         vuln.PkgName,
+		Description:     vuln.Description,
+		DependencyChain: modWhyOutput,
+		ImportedBy:      vuln.ImportedBy,
+		Exploit:         vuln.Exploit,
+	}
+}
+
+// justify generates an AI justification of each vulnerability, returning
+// the justification or the error of each. With a batch size above 1, the
+// vulnerabilities are sent in chunks of that size; vulnerabilities a batch
+// request does not answer for are requested one by one.
+func justify(client *ai.Client, vulns []trivy.Vulnerability, modulePath string, batchSize int, progress *output.Progress) ([]*AIGeneratedJustification, []error) {
+	justifications := make([]*AIGeneratedJustification, len(vulns))
+	errs := make([]error, len(vulns))
+
+	if batchSize > 1 && len(vulns) > 1 {
+		for start := 0; start < len(vulns) && runner.Context().Err() == nil; start += batchSize {
+			end := min(start+batchSize, len(vulns))
+			progress.SetDetail(fmt.Sprintf("batch of %d", end-start))
+			batch, err := generateAIJustifications(client, vulns[start:end], modulePath)
+			if errors.Is(err, ai.ErrDryRun) {
+				for i := start; i < end; i++ {
+					errs[i] = err
+				}
+				continue
+			}
+			if err != nil {
+				output.Warnf("batch AI justification failed, requesting one by one: %v", err)
+				continue
+			}
+			copy(justifications[start:end], batch)
+		}
+	}
+
+	for i, vuln := range vulns {
+		if justifications[i] != nil || errs[i] != nil {
+			progress.Step(vuln.VulnerabilityID)
+			continue
+		}
+		// Once interrupted, the remaining vulnerabilities stay under investigation
+		if err := runner.Context().Err(); err != nil {
+			errs[i] = err
+			continue
+		}
+		progress.Step(vuln.VulnerabilityID)
+		justifications[i], errs[i] = generateAIJustification(client, vuln, modulePath)
+	}
+	return justifications, errs
+}
+
+// generateAIJustifications uses AI to generate the VEX justifications of
+// several vulnerabilities in one request. The result is in the order of
+// vulns, with nil for vulnerabilities the response has no answer for.
+func generateAIJustifications(client *ai.Client, vulns []trivy.Vulnerability, modulePath string) ([]*AIGeneratedJustification, error) {
+	ctx, cancel := context.WithTimeout(runner.Context(), 120*time.Second)
+	defer cancel()
+
+	data := make([]ai.VEXPromptData, 0, len(vulns))
+	for _, vuln := range vulns {
+		data = append(data, promptData(vuln, modulePath))
+	}
+
+	response, err := client.GenerateVEXJustifications(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+
+	var answers []struct {
+		Vulnerability string `json:"vulnerability"`
+		Package       string `json:"package"`
+		AIGeneratedJustification
+	}
+	if err := json.Unmarshal([]byte(response), &answers); err != nil {
+		return nil, fmt.Errorf("failed to parse AI response: %w", err)
+	}
+
+	justifications := make([]*AIGeneratedJustification, len(vulns))
+	for _, answer := range answers {
+		for i, vuln := range vulns {
+			if justifications[i] != nil || answer.Vulnerability != vuln.VulnerabilityID ||
+				(answer.Package != "" && answer.Package != vuln.PkgName) {
+				continue
+			}
+			justification := answer.AIGeneratedJustification
+			validateStatus(&justification)
+			justifications[i] = &justification
+			break
+		}
+	}
+	return justifications, nil
+}
 
-	// Validate status
+// validateStatus replaces an unknown status with under_investigation
+func validateStatus(j *AIGeneratedJustification) {
 	validStatuses := map[string]bool{
 		"not_affected":        true,
 		"affected":            true,
 		"fixed":               true,
 		"under_investigation": true,
 	}
-	if !validStatuses[justification.Status] {
-		justification.Status = "under_investigation"
+	if !validStatuses[j.Status] {
+		j.Status = "under_investigation"
 	}
-
-	return &justification, nil
 }
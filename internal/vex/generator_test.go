@@ -0,0 +1,317 @@
+package vex
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tamcore/go-autobump/internal/ai"
+	"github.com/tamcore/go-autobump/internal/ai/aitest"
+	"github.com/tamcore/go-autobump/internal/alias"
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+func TestStatementsPURL(t *testing.T) {
+	vulns := []trivy.Vulnerability{
+		{VulnerabilityID: "CVE-1", PkgName: "github.com/Docker/docker", InstalledVersion: "v24.0.7+incompatible"},
+	}
+
+	tests := []struct {
+		name         string
+		preserveCase bool
+		want         string
+	}{
+		{"lowercased", false, "pkg:golang/github.com/docker/docker@v24.0.7%2Bincompatible"},
+		{"preserve case", true, "pkg:golang/github.com/Docker/docker@v24.0.7%2Bincompatible"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.PURLPreserveCase = tt.preserveCase
+
+			statements := Statements(vulns, cfg)
+			if len(statements) != 1 {
+				t.Fatalf("got %d statements, want 1", len(statements))
+			}
+			if got := statements[0].Products[0].Identifiers.PURL; got != tt.want {
+				t.Errorf("PURL = %q, want %q", got, tt.want)
+			}
+			if statements[0].Status != "under_investigation" {
+				t.Errorf("Status = %q, want under_investigation", statements[0].Status)
+			}
+		})
+	}
+}
+
+func TestStatementsProductHierarchy(t *testing.T) {
+	vuln := trivy.Vulnerability{VulnerabilityID: "CVE-1", PkgName: "golang.org/x/net", InstalledVersion: "v0.1.0"}
+	cfg := config.Default()
+	cfg.VEXProducts = []string{"pkg:oci/app?repository_url=ghcr.io/org/app", "app"}
+
+	statements := Statements([]trivy.Vulnerability{vuln}, cfg)
+	if len(statements) != 1 || len(statements[0].Products) != 2 {
+		t.Fatalf("statements = %+v", statements)
+	}
+
+	image := statements[0].Products[0]
+	if image.ID != cfg.VEXProducts[0] || image.Identifiers.PURL != cfg.VEXProducts[0] {
+		t.Errorf("product = %+v", image)
+	}
+	if len(image.Subcomponents) != 1 || image.Subcomponents[0].Identifiers.PURL != "pkg:golang/golang.org/x/net@v0.1.0" {
+		t.Errorf("subcomponents = %+v", image.Subcomponents)
+	}
+	if app := statements[0].Products[1]; app.Identifiers.PURL != "" {
+		t.Errorf("non-purl product got purl identifier %q", app.Identifiers.PURL)
+	}
+
+	if len(statements[0].Modules()) != 2 {
+		t.Errorf("Modules() = %+v, want the module once per product", statements[0].Modules())
+	}
+	if FindStatement(statements, vuln) == nil {
+		t.Error("FindStatement() did not find the statement through its subcomponents")
+	}
+}
+
+func TestFindStatementAlias(t *testing.T) {
+	defer alias.Reset()
+	alias.Reset()
+
+	statements := []Statement{{VulnerabilityID: "GHSA-abcd-efgh-ijkl", Products: []Product{{ID: "golang.org/x/net"}}}}
+	vuln := trivy.Vulnerability{VulnerabilityID: "CVE-2024-1", PkgName: "golang.org/x/net"}
+	if FindStatement(statements, vuln) != nil {
+		t.Fatal("FindStatement() matched an unrelated ID")
+	}
+
+	alias.Add("CVE-2024-1", "GHSA-abcd-efgh-ijkl")
+	if FindStatement(statements, vuln) == nil {
+		t.Error("FindStatement() did not find the statement made under an alias")
+	}
+	if statements[0].Key() != (Statement{VulnerabilityID: "CVE-2024-1", Products: statements[0].Products}).Key() {
+		t.Error("statements about aliases have different keys")
+	}
+}
+
+func TestGenerateMetadata(t *testing.T) {
+	cfg := config.Default()
+	cfg.VEXOutput = filepath.Join(t.TempDir(), "vex.json")
+	cfg.VEXMetadata = config.VEXMetadataConfig{
+		Author:   "Example Corp",
+		Role:     "Vendor",
+		Tooling:  "go-autobump in CI",
+		IDPrefix: "https://example.com/vex/",
+		Supplier: "Example Corp",
+	}
+
+	vulns := []trivy.Vulnerability{{VulnerabilityID: "CVE-1", PkgName: "golang.org/x/net", InstalledVersion: "v0.1.0"}}
+	if _, err := Generate(vulns, cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(cfg.VEXOutput)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc OpenVEXDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	if doc.Author != "Example Corp" || doc.Role != "Vendor" || doc.Tooling != "go-autobump in CI" {
+		t.Errorf("document metadata = %q, %q, %q", doc.Author, doc.Role, doc.Tooling)
+	}
+	if !strings.HasPrefix(doc.ID, "https://example.com/vex/") {
+		t.Errorf("ID = %q, want prefix https://example.com/vex/", doc.ID)
+	}
+	if doc.Statements[0].Supplier != "Example Corp" {
+		t.Errorf("Supplier = %q", doc.Statements[0].Supplier)
+	}
+}
+
+func TestRequireConfidence(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     string
+		confidence float64
+		min        float64
+		want       string
+	}{
+		{"confident", "not_affected", 0.9, 0.8, "not_affected"},
+		{"unsure", "not_affected", 0.6, 0.8, "under_investigation"},
+		{"missing confidence", "not_affected", 0, 0.8, "under_investigation"},
+		{"no threshold", "not_affected", 0.1, 0, "not_affected"},
+		{"affected", "affected", 0.2, 0.8, "affected"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j := &AIGeneratedJustification{Status: tt.status, Justification: "vulnerable_code_not_reachable", Confidence: tt.confidence}
+			requireConfidence(j, tt.min)
+			if j.Status != tt.want {
+				t.Errorf("status = %q, want %q", j.Status, tt.want)
+			}
+			if j.Status == "under_investigation" && j.Justification != "" {
+				t.Errorf("downgraded statement keeps justification %q", j.Justification)
+			}
+		})
+	}
+}
+
+func TestRequireConsensus(t *testing.T) {
+	tests := []struct {
+		name   string
+		status string
+		second *AIGeneratedJustification
+		err    error
+		want   string
+	}{
+		{"agree", "not_affected", &AIGeneratedJustification{Status: "not_affected"}, nil, "not_affected"},
+		{"disagree", "not_affected", &AIGeneratedJustification{Status: "affected"}, nil, "under_investigation"},
+		{"second failed", "not_affected", nil, errors.New("timeout"), "under_investigation"},
+		{"affected", "affected", nil, nil, "affected"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j := &AIGeneratedJustification{Status: tt.status, Justification: "vulnerable_code_not_reachable"}
+			requireConsensus(j, tt.second, tt.err, "other-model")
+			if j.Status != tt.want {
+				t.Errorf("status = %q, want %q", j.Status, tt.want)
+			}
+			if j.Status == "under_investigation" && !strings.Contains(j.ImpactStatement, "other-model") {
+				t.Errorf("impact statement %q does not name the consensus model", j.ImpactStatement)
+			}
+		})
+	}
+}
+
+func TestStatementsBatch(t *testing.T) {
+	var requests []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ai.ChatCompletionRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		user := req.Messages[len(req.Messages)-1].Content
+		requests = append(requests, strings.Count(user, "Vulnerability ID:"))
+
+		content := `{"status":"affected","impact_statement":"single"}`
+		if strings.Contains(user, "---") {
+			// Answers for CVE-1 only, CVE-2 is requested again
+			content = `[{"vulnerability":"CVE-1","package":"golang.org/x/net","status":"not_affected","justification":"vulnerable_code_not_present","impact_statement":"batch"}]`
+		}
+		data, _ := json.Marshal(content)
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":` + string(data) + `}}]}`))
+	}))
+	defer server.Close()
+
+	cfg := config.Default()
+	cfg.Path = t.TempDir()
+	cfg.AI.APIKey = "test"
+	cfg.AI.Endpoint = server.URL
+	cfg.AI.BatchSize = 10
+
+	vulns := []trivy.Vulnerability{
+		{VulnerabilityID: "CVE-1", PkgName: "golang.org/x/net", InstalledVersion: "v0.1.0"},
+		{VulnerabilityID: "CVE-2", PkgName: "golang.org/x/text", InstalledVersion: "v0.1.0"},
+	}
+	statements := Statements(vulns, cfg)
+
+	if len(requests) != 2 || requests[0] != 2 || requests[1] != 1 {
+		t.Errorf("vulnerabilities per request = %v, want [2 1]", requests)
+	}
+	if statements[0].Status != "not_affected" || statements[0].ImpactStatement != "batch" {
+		t.Errorf("statement 0 = %+v", statements[0])
+	}
+	if statements[1].Status != "affected" || statements[1].ImpactStatement != "single" {
+		t.Errorf("statement 1 = %+v", statements[1])
+	}
+}
+
+func TestStatementsStubServer(t *testing.T) {
+	server := aitest.NewServer()
+	defer server.Close()
+
+	cfg := config.Default()
+	cfg.Path = t.TempDir()
+	cfg.AI.APIKey = "test"
+	cfg.AI.Endpoint = server.URL
+	cfg.AI.Stream = true
+	cfg.AI.BatchSize = 10
+
+	vulns := []trivy.Vulnerability{
+		{VulnerabilityID: "CVE-1", PkgName: "golang.org/x/net", InstalledVersion: "v0.1.0"},
+		{VulnerabilityID: "CVE-2", PkgName: "golang.org/x/text", InstalledVersion: "v0.1.0"},
+	}
+	statements := Statements(vulns, cfg)
+
+	if n := len(server.Requests()); n != 1 {
+		t.Errorf("requests = %d, want one batch", n)
+	}
+	for _, stmt := range statements {
+		if !stmt.aiGenerated || stmt.ImpactStatement != "Canned analysis of the test AI server." {
+			t.Errorf("statement = %+v, want the stub's justification", stmt)
+		}
+	}
+}
+
+func TestStatementsDryRun(t *testing.T) {
+	server := aitest.NewServer()
+	defer server.Close()
+
+	cfg := config.Default()
+	cfg.Path = t.TempDir()
+	cfg.AI.Endpoint = server.URL
+	cfg.AI.DryRun = true
+	cfg.AI.BatchSize = 10
+
+	vulns := []trivy.Vulnerability{
+		{VulnerabilityID: "CVE-1", PkgName: "golang.org/x/net", InstalledVersion: "v0.1.0"},
+		{VulnerabilityID: "CVE-2", PkgName: "golang.org/x/text", InstalledVersion: "v0.1.0"},
+	}
+	statements := Statements(vulns, cfg)
+
+	if n := len(server.Requests()); n != 0 {
+		t.Errorf("dry run sent %d requests", n)
+	}
+	for _, stmt := range statements {
+		if stmt.Status != StatusUnderInvestigation || stmt.aiGenerated {
+			t.Errorf("statement = %+v, want under_investigation", stmt)
+		}
+	}
+}
+
+func TestGenerateDraft(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("TMPDIR", dir)
+	cfg := config.Default()
+	cfg.VEXOutput = filepath.Join(dir, ".vex.openvex.json")
+	cfg.VEXReview = true
+	cfg.VEXReviewQueue = filepath.Join(dir, ".vex.pending.json")
+	cfg.OSVScannerConfig = filepath.Join(dir, "osv-scanner.toml")
+
+	vulns := []trivy.Vulnerability{{VulnerabilityID: "CVE-1", PkgName: "golang.org/x/net", InstalledVersion: "v0.1.0"}}
+	path, statements, err := GenerateDraft(vulns, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := ReadDocument(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(doc.ID, "draft") || !strings.HasSuffix(path, ".draft.openvex.json") {
+		t.Errorf("document %s at %s is not marked draft", doc.ID, path)
+	}
+	if len(statements) != 1 || len(doc.Statements) != 1 {
+		t.Errorf("got %d statements, %d in the document, want 1", len(statements), len(doc.Statements))
+	}
+	for _, file := range []string{cfg.VEXOutput, cfg.VEXReviewQueue, cfg.OSVScannerConfig} {
+		if _, err := os.Stat(file); !os.IsNotExist(err) {
+			t.Errorf("%s was written by a draft", file)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package vex
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/tamcore/go-autobump/internal/alias"
+)
+
+// StatusNotAffected is the VEX status of vulnerabilities that do not affect the product
+const StatusNotAffected = "not_affected"
+
+// UpdateOSVScannerConfig adds an [[IgnoredVulns]] entry to the osv-scanner
+// config at path for every not_affected statement that is not ignored there
+// yet, under its ID or an alias, so osv-scanner stops reporting
+// vulnerabilities already triaged in VEX.
+// Existing content, including comments, is preserved; the file is created if
+// missing. It returns the number of entries added.
+func UpdateOSVScannerConfig(path string, statements []Statement) (int, error) {
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return 0, fmt.Errorf("failed to read osv-scanner config: %w", err)
+	}
+
+	ignored := make(map[string]bool)
+	for id := range ignoredOSVIDs(existing) {
+		ignored[alias.Canonical(id)] = true
+	}
+
+	var buf bytes.Buffer
+	buf.Write(existing)
+	added := 0
+	for _, stmt := range statements {
+		if stmt.Status != StatusNotAffected || ignored[alias.Canonical(stmt.VulnerabilityID)] {
+			continue
+		}
+		ignored[alias.Canonical(stmt.VulnerabilityID)] = true
+
+		if buf.Len() > 0 && !bytes.HasSuffix(buf.Bytes(), []byte("\n\n")) {
+			if !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+				buf.WriteString("\n")
+			}
+			buf.WriteString("\n")
+		}
+		buf.WriteString("[[IgnoredVulns]]\n")
+		fmt.Fprintf(&buf, "id = %s\n", tomlString(stmt.VulnerabilityID))
+		fmt.Fprintf(&buf, "reason = %s\n", tomlString(ignoreReason(stmt)))
+		added++
+	}
+
+	if added == 0 {
+		return 0, nil
+	}
+
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return 0, fmt.Errorf("failed to write osv-scanner config: %w", err)
+	}
+	return added, nil
+}
+
+// ignoredOSVIDs returns the ids of all IgnoredVulns entries in an osv-scanner config
+func ignoredOSVIDs(data []byte) map[string]bool {
+	ids := make(map[string]bool)
+	inIgnored := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			inIgnored = line == "[[IgnoredVulns]]"
+			continue
+		}
+		if !inIgnored {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || strings.TrimSpace(key) != "id" {
+			continue
+		}
+		ids[strings.Trim(strings.TrimSpace(value), `"'`)] = true
+	}
+	return ids
+}
+
+// ignoreReason describes why a vulnerability is ignored, based on its VEX statement
+func ignoreReason(stmt Statement) string {
+	reason := "not affected according to VEX"
+	if stmt.Justification != "" {
+		reason += " (" + stmt.Justification + ")"
+	}
+	if stmt.ImpactStatement != "" {
+		reason += ": " + stmt.ImpactStatement
+	}
+	return reason
+}
+
+// tomlString quotes s as a TOML basic string
+func tomlString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,51 @@
+package vex
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUpdateOSVScannerConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "osv-scanner.toml")
+	existing := "# triaged manually\n[[IgnoredVulns]]\nid = \"CVE-2024-0001\"\nreason = \"test only\"\n"
+	if err := os.WriteFile(path, []byte(existing), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	statements := []Statement{
+		{VulnerabilityID: "CVE-2024-0001", Status: StatusNotAffected},
+		{VulnerabilityID: "CVE-2024-0002", Status: StatusNotAffected,
+			Justification: "vulnerable_code_not_in_execute_path", ImpactStatement: `"parse" is never called`},
+		{VulnerabilityID: "CVE-2024-0003", Status: "under_investigation"},
+	}
+
+	added, err := UpdateOSVScannerConfig(path, statements)
+	if err != nil {
+		t.Fatalf("UpdateOSVScannerConfig() error: %v", err)
+	}
+	if added != 1 {
+		t.Errorf("added = %d, want 1", added)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(data)
+
+	if !strings.HasPrefix(got, existing) {
+		t.Errorf("existing content not preserved:\n%s", got)
+	}
+	want := "\n[[IgnoredVulns]]\nid = \"CVE-2024-0002\"\n" +
+		"reason = \"not affected according to VEX (vulnerable_code_not_in_execute_path): \\\"parse\\\" is never called\"\n"
+	if !strings.HasSuffix(got, want) {
+		t.Errorf("missing ignore entry, got:\n%s", got)
+	}
+
+	// Running again must not duplicate entries
+	if added, err := UpdateOSVScannerConfig(path, statements); err != nil || added != 0 {
+		t.Errorf("second run added %d (err %v), want 0", added, err)
+	}
+}
@@ -0,0 +1,189 @@
+package vex
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tamcore/go-autobump/internal/alias"
+	"github.com/tamcore/go-autobump/internal/config"
+)
+
+// StatusUnderInvestigation is the VEX status of vulnerabilities not yet triaged
+const StatusUnderInvestigation = "under_investigation"
+
+// ReviewQueue holds AI-generated statements awaiting human review
+type ReviewQueue struct {
+	Statements []Statement `json:"statements"`
+}
+
+// Key identifies the vulnerability and products a statement is about. The
+// vulnerability is identified by its preferred ID, so statements made under
+// different aliases have the same key.
+func (s Statement) Key() string {
+	ids := make([]string, 0, len(s.Products))
+	for _, product := range s.Products {
+		id := product.ID
+		for _, sub := range product.Subcomponents {
+			id += ">" + sub.ID
+		}
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return alias.Canonical(s.VulnerabilityID) + "|" + strings.Join(ids, ",")
+}
+
+// ReadReviewQueue reads the review queue at path; a missing file is an
+// empty queue
+func ReadReviewQueue(path string) (*ReviewQueue, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &ReviewQueue{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read VEX review queue: %w", err)
+	}
+	var q ReviewQueue
+	if err := json.Unmarshal(data, &q); err != nil {
+		return nil, fmt.Errorf("failed to parse VEX review queue %s: %w", path, err)
+	}
+	return &q, nil
+}
+
+// Write writes the queue to path, removing the file once the queue is empty
+func (q *ReviewQueue) Write(path string) error {
+	if len(q.Statements) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove VEX review queue: %w", err)
+		}
+		return nil
+	}
+
+	data, err := json.MarshalIndent(q, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal VEX review queue: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write VEX review queue: %w", err)
+	}
+	return nil
+}
+
+// Add queues stmt, replacing a pending statement about the same
+// vulnerability and products
+func (q *ReviewQueue) Add(stmt Statement) {
+	q.Statements = upsert(q.Statements, stmt)
+}
+
+// Remove drops the pending statement with the given key
+func (q *ReviewQueue) Remove(key string) {
+	kept := q.Statements[:0]
+	for _, stmt := range q.Statements {
+		if stmt.Key() != key {
+			kept = append(kept, stmt)
+		}
+	}
+	q.Statements = kept
+}
+
+// Approve publishes stmt, as approved (and possibly edited) by reviewer, in
+// the VEX document of cfg and removes it from the review queue. The
+// document is created if missing.
+func Approve(cfg *config.Config, q *ReviewQueue, stmt Statement, reviewer string) error {
+	doc, err := ReadDocument(cfg.VEXOutput)
+	if os.IsNotExist(err) {
+		doc, err = NewDocument(cfg), nil
+	}
+	if err != nil {
+		return err
+	}
+
+	stmt.Reviewer = reviewer
+	stmt.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	doc.Statements = upsert(doc.Statements, stmt)
+	doc.Timestamp = stmt.Timestamp
+	doc.Version++
+	if err := doc.Write(cfg.VEXOutput); err != nil {
+		return err
+	}
+	if err := updateOSVScannerConfig(cfg, []Statement{stmt}); err != nil {
+		return err
+	}
+
+	q.Remove(stmt.Key())
+	return q.Write(cfg.VEXReviewQueue)
+}
+
+// Reject discards the pending statement with the given key, leaving the
+// vulnerability under investigation in the published document
+func Reject(cfg *config.Config, q *ReviewQueue, key string) error {
+	q.Remove(key)
+	return q.Write(cfg.VEXReviewQueue)
+}
+
+// queueForReview adds the AI-generated statements to the review queue at
+// path and returns the statements to publish meanwhile, with the
+// AI-generated ones replaced by under_investigation placeholders
+func queueForReview(path string, statements []Statement) ([]Statement, error) {
+	q, err := ReadReviewQueue(path)
+	if err != nil {
+		return nil, err
+	}
+
+	published := make([]Statement, 0, len(statements))
+	queued := 0
+	for _, stmt := range statements {
+		if !stmt.aiGenerated {
+			published = append(published, stmt)
+			continue
+		}
+		q.Add(stmt)
+		queued++
+
+		stmt.aiGenerated = false
+		stmt.Status = StatusUnderInvestigation
+		stmt.Justification = ""
+		stmt.ImpactStatement = "AI-generated statement pending human review."
+		published = append(published, stmt)
+	}
+
+	if queued == 0 {
+		return published, nil
+	}
+	if err := q.Write(path); err != nil {
+		return nil, err
+	}
+	return published, nil
+}
+
+// reviewedStatements returns the reviewer-approved statements of the VEX
+// document at path by key. A missing or unreadable document has none.
+func reviewedStatements(path string) map[string]Statement {
+	reviewed := make(map[string]Statement)
+	doc, err := ReadDocument(path)
+	if err != nil {
+		return reviewed
+	}
+	for _, stmt := range doc.Statements {
+		if stmt.Reviewer != "" {
+			reviewed[stmt.Key()] = stmt
+		}
+	}
+	return reviewed
+}
+
+// upsert replaces the statement about the same vulnerability and products
+// as stmt, or appends stmt
+func upsert(statements []Statement, stmt Statement) []Statement {
+	key := stmt.Key()
+	for i := range statements {
+		if statements[i].Key() == key {
+			statements[i] = stmt
+			return statements
+		}
+	}
+	return append(statements, stmt)
+}
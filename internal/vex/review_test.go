@@ -0,0 +1,83 @@
+package vex
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/tamcore/go-autobump/internal/config"
+	"github.com/tamcore/go-autobump/internal/trivy"
+)
+
+func TestQueueForReview(t *testing.T) {
+	queue := filepath.Join(t.TempDir(), "pending.json")
+	statements := []Statement{
+		{VulnerabilityID: "CVE-1", Products: []Product{{ID: "a"}}, Status: "not_affected", Justification: "vulnerable_code_not_in_execute_path", aiGenerated: true},
+		{VulnerabilityID: "CVE-2", Products: []Product{{ID: "b"}}, Status: "under_investigation"},
+	}
+
+	published, err := queueForReview(queue, statements)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(published) != 2 || published[0].Status != StatusUnderInvestigation || published[0].Justification != "" {
+		t.Errorf("published = %+v", published)
+	}
+	if published[1].Status != "under_investigation" || published[1].ImpactStatement != "" {
+		t.Errorf("non-AI statement changed: %+v", published[1])
+	}
+
+	q, err := ReadReviewQueue(queue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(q.Statements) != 1 || q.Statements[0].Status != "not_affected" {
+		t.Fatalf("queue = %+v", q.Statements)
+	}
+
+	// Queueing the same statement again replaces it
+	if _, err := queueForReview(queue, statements[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if q, _ = ReadReviewQueue(queue); len(q.Statements) != 1 {
+		t.Errorf("queue = %+v", q.Statements)
+	}
+}
+
+func TestApprove(t *testing.T) {
+	dir := t.TempDir()
+	cfg := config.Default()
+	cfg.VEXOutput = filepath.Join(dir, "vex.json")
+	cfg.VEXReviewQueue = filepath.Join(dir, "pending.json")
+
+	vuln := trivy.Vulnerability{VulnerabilityID: "CVE-1", PkgName: "golang.org/x/net", InstalledVersion: "v0.1.0"}
+	stmt := Statements([]trivy.Vulnerability{vuln}, cfg)[0]
+	stmt.Status = "not_affected"
+	stmt.Justification = "vulnerable_code_not_present"
+
+	q := &ReviewQueue{}
+	q.Add(stmt)
+	if err := q.Write(cfg.VEXReviewQueue); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Approve(cfg, q, stmt, "Jane Doe"); err != nil {
+		t.Fatal(err)
+	}
+	if len(q.Statements) != 0 {
+		t.Errorf("queue = %+v", q.Statements)
+	}
+
+	doc, err := ReadDocument(cfg.VEXOutput)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Statements) != 1 || doc.Statements[0].Reviewer != "Jane Doe" {
+		t.Fatalf("statements = %+v", doc.Statements)
+	}
+
+	// Later runs keep the reviewed statement instead of regenerating it
+	again := Statements([]trivy.Vulnerability{vuln}, cfg)
+	if again[0].Status != "not_affected" || again[0].Reviewer != "Jane Doe" {
+		t.Errorf("statement = %+v", again[0])
+	}
+}
@@ -0,0 +1,24 @@
+package vex
+
+import "github.com/tamcore/go-autobump/internal/trivy"
+
+// Unfixed returns the vulnerabilities of result that an update leaves
+// unfixed, which a VEX document has to cover: those the update ignores,
+// i.e. that are missing from actedOn, those without a fixed version and
+// those whose fix ruledOut rejects
+func Unfixed(result, actedOn trivy.ScanResult, ruledOut func(trivy.Vulnerability) error) []trivy.Vulnerability {
+	type finding struct{ id, pkg, version string }
+	acted := make(map[finding]bool, len(actedOn.Vulnerabilities))
+	for _, vuln := range actedOn.Vulnerabilities {
+		acted[finding{vuln.VulnerabilityID, vuln.PkgName, vuln.InstalledVersion}] = true
+	}
+
+	var vulns []trivy.Vulnerability
+	for _, vuln := range result.Vulnerabilities {
+		if !acted[finding{vuln.VulnerabilityID, vuln.PkgName, vuln.InstalledVersion}] ||
+			!trivy.HasFixedVersion(vuln) || ruledOut(vuln) != nil {
+			vulns = append(vulns, vuln)
+		}
+	}
+	return vulns
+}
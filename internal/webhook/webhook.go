@@ -0,0 +1,66 @@
+// Package webhook posts alerts as JSON to HTTP endpoints, such as Slack or
+// Mattermost incoming webhooks or a custom receiver
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tamcore/go-autobump/internal/httpclient"
+)
+
+// maxErrorBody bounds the response body quoted in errors
+const maxErrorBody = 512
+
+// Client posts to a webhook. The URL of chat webhooks is a secret, so it is
+// never part of errors.
+type Client struct {
+	URL        string
+	HTTPClient *http.Client
+}
+
+// NewClient creates a client of the webhook at url
+func NewClient(url string) *Client {
+	return &Client{
+		URL:        url,
+		HTTPClient: httpclient.New(30 * time.Second),
+	}
+}
+
+// Post sends payload as JSON; any 2xx status is a success
+func (c *Client) Post(ctx context.Context, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.URL, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		// The error of the client quotes the URL
+		return fmt.Errorf("webhook request failed: %w", redact(err, c.URL))
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return fmt.Errorf("webhook returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// redact replaces url in the message of err
+func redact(err error, url string) error {
+	return fmt.Errorf("%s", strings.ReplaceAll(err.Error(), url, "<webhook URL>"))
+}
@@ -0,0 +1,49 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPost(t *testing.T) {
+	var got map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Content-Type = %q", r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		if r.URL.Path == "/fail" {
+			http.Error(w, "invalid_token", http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+
+	if err := NewClient(server.URL+"/hook").Post(context.Background(), map[string]string{"text": "hello"}); err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	if got["text"] != "hello" {
+		t.Errorf("payload = %v", got)
+	}
+
+	err := NewClient(server.URL+"/fail").Post(context.Background(), map[string]string{"text": "hello"})
+	if err == nil || !strings.Contains(err.Error(), "status 403: invalid_token") {
+		t.Errorf("Post() error = %v, want the status and body", err)
+	}
+}
+
+func TestPostRedactsURL(t *testing.T) {
+	url := "http://127.0.0.1:1/services/T000/B000/secret"
+	err := NewClient(url).Post(context.Background(), map[string]string{})
+	if err == nil {
+		t.Fatal("Post() succeeded without a server")
+	}
+	if strings.Contains(err.Error(), "secret") {
+		t.Errorf("error %q quotes the webhook URL", err)
+	}
+}